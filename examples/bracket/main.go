@@ -0,0 +1,147 @@
+//go:build examples
+
+// Command bracket places a limit entry and, once it fills, a target and a stop-loss
+// order that cancel each other: whichever exit completes first cancels the other.
+//
+//	go run -tags examples ./examples/bracket -token 3045 -symbol SBIN-EQ -qty 1 -entry 750 -target 760 -stop 745
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"time"
+
+	"github.com/Abhi13027/go-tiqs/examples/demo"
+	"github.com/Abhi13027/go-tiqs/tiqs"
+)
+
+const variety = "regular"
+
+func main() {
+	token := flag.String("token", "3045", "instrument token")
+	symbol := flag.String("symbol", "SBIN-EQ", "trading symbol")
+	qty := flag.Int("qty", 1, "quantity")
+	entry := flag.Float64("entry", 0, "entry limit price")
+	target := flag.Float64("target", 0, "target price")
+	stop := flag.Float64("stop", 0, "stop-loss trigger price")
+	flag.Parse()
+
+	client, err := demo.Login()
+	if err != nil {
+		demo.Exit(err)
+	}
+	client.SetDuplicateGuard(tiqs.NewDuplicateGuard(5 * time.Second))
+	client.SetOrderRateLimiter(tiqs.NewRateLimiter(5, 5))
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	session := tiqs.NewSession(client, nil)
+	tracker := tiqs.NewOrderTracker(client, 64)
+	go poll(ctx, tracker)
+
+	order := func(side tiqs.TransactionType, orderType tiqs.OrderType, price, trigger float64) tiqs.OrderRequest {
+		return tiqs.OrderRequest{
+			Exchange:        tiqs.ExchangeNSE,
+			Token:           *token,
+			Symbol:          *symbol,
+			Product:         tiqs.ProductMIS,
+			Quantity:        strconv.Itoa(*qty),
+			TransactionType: side,
+			OrderType:       orderType,
+			Price:           strconv.FormatFloat(price, 'f', 2, 64),
+			TriggerPrice:    strconv.FormatFloat(trigger, 'f', 2, 64),
+			Validity:        tiqs.ValidityDay,
+		}
+	}
+
+	entryNo, err := place(client, session, tracker, order(tiqs.TransactionBuy, tiqs.OrderTypeLimit, *entry, 0))
+	if err != nil {
+		demo.Exit(err)
+	}
+	fmt.Println("Entry placed:", entryNo)
+
+	exits := tiqs.NewOrderGroup(client, "exits")
+	for event := range events(ctx, tracker) {
+		fmt.Printf("%s %s %s -> %s\n", event.OrderNo, event.Type, event.From, event.To)
+
+		switch {
+		case event.OrderNo == entryNo && event.Type == tiqs.EventFullyFilled:
+			targetNo, err := place(client, session, tracker, order(tiqs.TransactionSell, tiqs.OrderTypeLimit, *target, 0))
+			if err != nil {
+				demo.Exit(err)
+			}
+			stopNo, err := place(client, session, tracker, order(tiqs.TransactionSell, tiqs.OrderTypeStopLoss, *stop, *stop))
+			if err != nil {
+				demo.Exit(err)
+			}
+			exits.Add(variety, targetNo)
+			exits.Add(variety, stopNo)
+
+		case event.OrderNo != entryNo && event.Type == tiqs.EventFullyFilled:
+			// One exit filled: cancel the other and stop.
+			if err := exits.CancelAll(); err != nil {
+				fmt.Println("Error:", err)
+			}
+			fmt.Println("Bracket closed")
+			return
+		}
+	}
+
+	// Interrupted: cancel whatever is still working.
+	report, _ := session.Shutdown(context.Background())
+	fmt.Printf("Shutdown: cancelled %v\n", report.CancelledOrders)
+}
+
+// place places an order and tracks it with the session and the tracker.
+func place(client *tiqs.Client, session *tiqs.Session, tracker *tiqs.OrderTracker, order tiqs.OrderRequest) (string, error) {
+	resp, err := client.PlaceOrder(variety, order)
+	if err != nil {
+		return "", err
+	}
+	session.TrackOrder(variety, resp.Data.OrderNo)
+	tracker.Track(resp.Data.OrderNo)
+	return resp.Data.OrderNo, nil
+}
+
+// poll refreshes the tracker from the order book every second.
+func poll(ctx context.Context, tracker *tiqs.OrderTracker) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := tracker.Poll(); err != nil {
+				fmt.Println("Error:", err)
+			}
+		}
+	}
+}
+
+// events forwards tracker events until the context is done.
+func events(ctx context.Context, tracker *tiqs.OrderTracker) <-chan tiqs.OrderEvent {
+	out := make(chan tiqs.OrderEvent)
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event := <-tracker.Events():
+				select {
+				case out <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out
+}
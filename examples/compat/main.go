@@ -0,0 +1,40 @@
+//go:build examples
+
+// Command compat prints the compatibility matrix of recorded API responses against the
+// decoders of every registered API version, e.g., before switching Config.APIVersion.
+//
+//	go run -tags examples ./examples/compat -fixtures tiqs/testdata/compat
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Abhi13027/go-tiqs/examples/demo"
+	"github.com/Abhi13027/go-tiqs/tiqs"
+)
+
+func main() {
+	dir := flag.String("fixtures", "tiqs/testdata/compat", "directory of <version>/<endpoint>.json fixtures")
+	flag.Parse()
+
+	results, err := tiqs.CheckCompatibility(os.DirFS(*dir))
+	if err != nil {
+		demo.Exit(err)
+	}
+	fmt.Printf("registered versions: %v\n", tiqs.APIVersions())
+
+	failed := 0
+	for _, r := range results {
+		status := "ok"
+		if r.Err != nil {
+			status = "FAIL: " + r.Err.Error()
+			failed++
+		}
+		fmt.Printf("%-4s %-28s %s\n", r.Version, r.Endpoint, status)
+	}
+	if failed > 0 {
+		demo.Exit(fmt.Errorf("%d of %d fixtures failed", failed, len(results)))
+	}
+}
@@ -0,0 +1,86 @@
+//go:build examples
+
+// Command dashboard streams live prices and depth for a few tokens and prints a
+// refreshing table, together with broker health and websocket compression stats.
+//
+//	go run -tags examples ./examples/dashboard
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/Abhi13027/go-tiqs/examples/demo"
+	"github.com/Abhi13027/go-tiqs/ticks"
+	"github.com/Abhi13027/go-tiqs/tiqs"
+)
+
+var tokens = []int{3045, 2885, 1594} // SBIN, RELIANCE, INFY
+
+func main() {
+	client, err := demo.Login()
+	if err != nil {
+		demo.Exit(err)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	health := tiqs.NewHealthMonitor(3, time.Minute)
+	client.SetHealthMonitor(health)
+	stale := tiqs.NewStaleGuard(30 * time.Second)
+	client.SetStaleGuard(stale)
+
+	ws := ticks.NewWS(client.Config.AppID, client.Config.Token)
+	ws.EnableCompression = true
+	health.WatchWS(ws)
+
+	if err := ws.Connect(); err != nil {
+		demo.Exit(err)
+	}
+	if err := ws.Subscribe(tokens, "full"); err != nil {
+		demo.Exit(err)
+	}
+
+	book := ticks.NewDepthBook()
+	go func() {
+		for tick := range ws.GetDataChannel() {
+			book.Update(tick)
+			stale.ObserveTick(tick)
+		}
+	}()
+
+	refresh := time.NewTicker(time.Second)
+	defer refresh.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			ws.Close()
+			return
+		case <-refresh.C:
+			render(book, health, stale, ws.CompressionStats())
+		}
+	}
+}
+
+// render prints the current state of all subscribed tokens.
+func render(book *ticks.DepthBook, health *tiqs.HealthMonitor, stale *tiqs.StaleGuard, stats ticks.CompressionStats) {
+	fmt.Print("\033[H\033[2J")
+	fmt.Printf("Broker: %s   Compression: %v (ratio %.2f)\n\n", health.State(), stats.Negotiated, stats.Ratio)
+	fmt.Printf("%-8s %10s %10s %10s %6s\n", "TOKEN", "LTP", "BID", "ASK", "STALE")
+
+	for _, token := range tokens {
+		snapshot, ok := book.Get(int32(token))
+		if !ok {
+			fmt.Printf("%-8d %10s\n", token, "-")
+			continue
+		}
+		bid := float64(snapshot.Depth.Bids[0].Price) / 100
+		ask := float64(snapshot.Depth.Asks[0].Price) / 100
+		fmt.Printf("%-8d %10.2f %10.2f %10.2f %6v\n", token, float64(snapshot.LTP)/100, bid, ask, stale.IsStale(int64(token)))
+	}
+}
@@ -0,0 +1,29 @@
+//go:build examples
+
+// Package demo holds the login boilerplate shared by the example programs.
+package demo
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Abhi13027/go-tiqs/tiqs"
+	"github.com/joho/godotenv"
+)
+
+// Login creates a client from the credentials in the environment (or a .env file) and logs in.
+func Login() (*tiqs.Client, error) {
+	godotenv.Load()
+
+	client := tiqs.NewClient(os.Getenv("APP_ID"), os.Getenv("APP_SECRET"))
+	if err := client.AutoLogin(os.Getenv("USER_ID"), os.Getenv("PASSWORD"), os.Getenv("TOTP_KEY")); err != nil {
+		return nil, fmt.Errorf("login failed: %w", err)
+	}
+	return client, nil
+}
+
+// Exit prints the error and exits with a non-zero status.
+func Exit(err error) {
+	fmt.Println("Error:", err)
+	os.Exit(1)
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/Abhi13027/go-tiqs/ticks"
 	"github.com/Abhi13027/go-tiqs/tiqs"
@@ -101,7 +102,12 @@ func main() {
 
 	fmt.Println("Option Chain Symbol:", optionChainSymbol)
 
-	optionChain, err := client.GetOptionChain("26000", "INDEX", "2", "06-MAR-2025")
+	optionChain, err := client.GetOptionChain(tiqs.OptionChainParams{
+		Token:    26000,
+		Exchange: "INDEX",
+		Count:    2,
+		Expiry:   time.Date(2025, time.March, 6, 0, 0, 0, 0, tiqs.IST),
+	})
 	if err != nil {
 		fmt.Println("Error:", err)
 	}
@@ -0,0 +1,77 @@
+//go:build examples
+
+// Command fixtures checks the decoders against the fixture corpus: captured WebSocket
+// frames and recorded REST responses, each with a golden file of its expected decoding.
+//
+//	go run -tags examples ./examples/fixtures
+//
+// Add captures of your own to the corpus directories, or point the flags at another
+// directory, and run with -update to write their golden files.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Abhi13027/go-tiqs/examples/demo"
+	"github.com/Abhi13027/go-tiqs/ticks"
+	"github.com/Abhi13027/go-tiqs/tiqs"
+)
+
+func main() {
+	frames := flag.String("frames", "ticks/testdata/frames", "directory of <name>.bin frames")
+	responses := flag.String("responses", "tiqs/testdata/compat", "directory of <version>/<endpoint>.json responses")
+	update := flag.Bool("update", false, "write the golden files from the current decoders")
+	flag.Parse()
+
+	if *update {
+		n, err := ticks.UpdateFrameGoldens(*frames)
+		if err != nil {
+			demo.Exit(err)
+		}
+		m, err := tiqs.UpdateGoldenResponses(*responses)
+		if err != nil {
+			demo.Exit(err)
+		}
+		fmt.Printf("wrote %d frame and %d response golden files; review them before committing\n", n, m)
+		return
+	}
+
+	failed, total := 0, 0
+	frameResults, err := ticks.CheckFrames(os.DirFS(*frames))
+	if err != nil {
+		demo.Exit(err)
+	}
+	for _, r := range frameResults {
+		total++
+		if !report("frame", r.File, r.Err) {
+			failed++
+		}
+	}
+
+	responseResults, err := tiqs.CheckGoldenResponses(os.DirFS(*responses))
+	if err != nil {
+		demo.Exit(err)
+	}
+	for _, r := range responseResults {
+		total++
+		if !report("response", r.File, r.Err) {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		demo.Exit(fmt.Errorf("%d of %d fixtures failed", failed, total))
+	}
+}
+
+// report prints the outcome of a fixture and reports whether it passed.
+func report(kind, file string, err error) bool {
+	if err != nil {
+		fmt.Printf("%-8s %-28s FAIL: %v\n", kind, file, err)
+		return false
+	}
+	fmt.Printf("%-8s %-28s ok\n", kind, file)
+	return true
+}
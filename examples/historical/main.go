@@ -0,0 +1,75 @@
+//go:build examples
+
+// Command historical downloads gap-filled candles for an instrument into a CSV file,
+// reporting download progress and leaving out the pre-open session.
+//
+//	go run -tags examples ./examples/historical -token 3045 -from 2025-01-01 -to 2025-01-31
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/Abhi13027/go-tiqs/examples/demo"
+	"github.com/Abhi13027/go-tiqs/tiqs"
+)
+
+func main() {
+	exchange := flag.String("exchange", "NSE", "exchange of the instrument")
+	token := flag.String("token", "3045", "instrument token")
+	interval := flag.String("interval", "1m", "candle interval")
+	from := flag.String("from", "", "start date")
+	to := flag.String("to", "", "end date")
+	out := flag.String("out", "candles.csv", "output file")
+	flag.Parse()
+
+	client, err := demo.Login()
+	if err != nil {
+		demo.Exit(err)
+	}
+	client.SetDataRateLimiter(tiqs.NewRateLimiter(3, 1))
+	client.SetIncludePreOpen(false)
+	client.SetDownloadProgress(func(endpoint string, read, total int64) {
+		fmt.Printf("\rdownloaded %d / %d bytes", read, total)
+	})
+
+	candles, err := client.GetHistoricalData(*exchange, *token, *interval, *from, *to, false)
+	if err != nil {
+		demo.Exit(err)
+	}
+	fmt.Println()
+
+	candles, err = tiqs.FillGaps(candles, *interval, tiqs.GapFillFlat, tiqs.NewMarketClock())
+	if err != nil {
+		demo.Exit(err)
+	}
+
+	file, err := os.Create(*out)
+	if err != nil {
+		demo.Exit(err)
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	w.Write([]string{"time", "open", "high", "low", "close", "volume", "filled"})
+	for _, c := range candles {
+		w.Write([]string{
+			c.Time,
+			strconv.FormatInt(c.Open, 10),
+			strconv.FormatInt(c.High, 10),
+			strconv.FormatInt(c.Low, 10),
+			strconv.FormatInt(c.Close, 10),
+			strconv.FormatInt(c.Volume, 10),
+			strconv.FormatBool(c.Filled),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		demo.Exit(err)
+	}
+
+	fmt.Printf("Wrote %d candles to %s\n", len(candles), *out)
+}
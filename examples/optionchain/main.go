@@ -0,0 +1,90 @@
+//go:build examples
+
+// Command optionchain prints the option chain of NIFTY around the money with live LTPs.
+//
+//	go run -tags examples ./examples/optionchain -expiry 2025-03-06
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Abhi13027/go-tiqs/examples/demo"
+	"github.com/Abhi13027/go-tiqs/tiqs"
+)
+
+func main() {
+	token := flag.Int64("token", 26000, "underlying token (26000 is NIFTY 50)")
+	expiry := flag.String("expiry", "", "expiry date, e.g., 2025-03-06")
+	count := flag.Int("count", 5, "strikes on each side of the money")
+	flag.Parse()
+
+	expiryDate, err := time.ParseInLocation(time.DateOnly, *expiry, tiqs.IST)
+	if err != nil {
+		demo.Exit(err)
+	}
+
+	client, err := demo.Login()
+	if err != nil {
+		demo.Exit(err)
+	}
+	client.SetDataRateLimiter(tiqs.NewRateLimiter(5, 5))
+
+	chain, err := client.GetOptionChain(tiqs.OptionChainParams{
+		Token:    *token,
+		Exchange: "INDEX",
+		Count:    *count,
+		Expiry:   expiryDate,
+	})
+	if err != nil {
+		demo.Exit(err)
+	}
+
+	type row struct{ call, put float64 }
+	rows := make(map[string]*row)
+	var quoteTokens []int64
+	for _, option := range chain.Data {
+		t, _ := strconv.ParseInt(option.Token, 10, 64)
+		quoteTokens = append(quoteTokens, t)
+	}
+
+	quotes, err := client.GetMarketQuotesDecimal(quoteTokens, "ltp")
+	if err != nil {
+		demo.Exit(err)
+	}
+	ltp := make(map[int64]float64, len(quotes))
+	for _, quote := range quotes {
+		ltp[quote.Token] = quote.LTP
+	}
+
+	for i, option := range chain.Data {
+		r, ok := rows[option.StrikePrice]
+		if !ok {
+			r = &row{}
+			rows[option.StrikePrice] = r
+		}
+		if option.OptionType == "CE" {
+			r.call = ltp[quoteTokens[i]]
+		} else {
+			r.put = ltp[quoteTokens[i]]
+		}
+	}
+
+	strikes := make([]string, 0, len(rows))
+	for strike := range rows {
+		strikes = append(strikes, strike)
+	}
+	sort.Slice(strikes, func(i, j int) bool {
+		a, _ := strconv.ParseFloat(strikes[i], 64)
+		b, _ := strconv.ParseFloat(strikes[j], 64)
+		return a < b
+	})
+
+	fmt.Printf("%10s %10s %10s\n", "CALL", "STRIKE", "PUT")
+	for _, strike := range strikes {
+		fmt.Printf("%10.2f %10s %10.2f\n", rows[strike].call, strike, rows[strike].put)
+	}
+}
@@ -0,0 +1,77 @@
+//go:build examples
+
+// Command setup walks through the first-run setup: it asks for the app and account
+// credentials, verifies the TOTP secret and the login, and writes an encrypted
+// credentials file that unattended processes log in with (see tiqs.LoginWithCredentialsFile).
+//
+//	go run -tags examples ./examples/setup -out tiqs.credentials
+//
+// Values found in the environment (APP_ID, APP_SECRET, USER_ID, PASSWORD, TOTP_KEY and
+// TIQS_CREDENTIALS_PASSPHRASE) are used instead of prompting.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Abhi13027/go-tiqs/examples/demo"
+	"github.com/Abhi13027/go-tiqs/tiqs"
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	out := flag.String("out", "tiqs.credentials", "path of the encrypted credentials file")
+	skipLogin := flag.Bool("skip-login", false, "save the credentials without logging in")
+	flag.Parse()
+	godotenv.Load()
+
+	in := bufio.NewReader(os.Stdin)
+	ask := func(env, prompt string) string {
+		if v := os.Getenv(env); v != "" {
+			return v
+		}
+		fmt.Printf("%s: ", prompt)
+		line, _ := in.ReadString('\n')
+		return strings.TrimSpace(line)
+	}
+
+	creds := tiqs.LoginCredentials{
+		AppID:      ask("APP_ID", "App ID"),
+		AppSecret:  ask("APP_SECRET", "App secret"),
+		UserID:     ask("USER_ID", "User ID"),
+		Password:   ask("PASSWORD", "Password"),
+		TOTPSecret: ask("TOTP_KEY", "TOTP secret (as shown when enabling 2FA)"),
+	}
+	passphrase := ask("TIQS_CREDENTIALS_PASSPHRASE", "Passphrase for the credentials file")
+
+	if _, err := os.Stat(*out); err == nil {
+		if answer := ask("", fmt.Sprintf("%s exists, overwrite? [y/N]", *out)); !strings.EqualFold(answer, "y") {
+			demo.Exit(fmt.Errorf("aborted"))
+		}
+	}
+
+	result, err := tiqs.RunSetup(tiqs.SetupOptions{
+		Credentials: creds,
+		Path:        *out,
+		Passphrase:  passphrase,
+		SkipLogin:   *skipLogin,
+		Progress: func(step tiqs.SetupStep, err error) {
+			status := "ok"
+			if err != nil {
+				status = "FAILED"
+			}
+			fmt.Printf("  %-8s %s\n", step, status)
+		},
+	})
+	if err != nil {
+		demo.Exit(err)
+	}
+
+	if result.UserName != "" {
+		fmt.Printf("Logged in as %s.\n", result.UserName)
+	}
+	fmt.Printf("Credentials saved to %s. Keep the passphrase; it cannot be recovered.\n", result.Path)
+}
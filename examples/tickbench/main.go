@@ -0,0 +1,116 @@
+//go:build examples
+
+// Command tickbench measures the websocket read → parse → fan-out path against a local
+// server streaming synthetic full-mode packets, so tuning options can be compared without
+// a broker connection. The parser alone is benchmarked by BenchmarkParseTick and
+// BenchmarkParseTickInto in package ticks.
+//
+//	go run -tags examples ./examples/tickbench -ticks 500000 -workers -1 -batch 256
+package main
+
+import (
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/Abhi13027/go-tiqs/examples/demo"
+	"github.com/Abhi13027/go-tiqs/ticks"
+	"github.com/gorilla/websocket"
+)
+
+func main() {
+	total := flag.Int("ticks", 200000, "number of ticks to stream")
+	tokens := flag.Int("tokens", 2000, "number of distinct tokens, e.g., a full option chain")
+	workers := flag.Int("workers", 0, "parsing goroutines; 0 parses inline, -1 uses GOMAXPROCS")
+	batch := flag.Int("batch", 0, "ticks per batch; 0 delivers single ticks")
+	chanSize := flag.Int("chan", ticks.DefaultDataChanSize, "capacity of the delivery channel")
+	flag.Parse()
+
+	server := httptest.NewServer(streamHandler(*total, *tokens))
+	defer server.Close()
+
+	ws := ticks.NewWS("bench", "bench")
+	ws.URL = "ws" + strings.TrimPrefix(server.URL, "http")
+	ws.MaxRetries = 1
+	ws.Tune(ticks.TuningOptions{
+		DataChanSize: *chanSize,
+		ParseWorkers: *workers,
+		BatchSize:    *batch,
+	})
+
+	var before runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	if err := ws.Connect(); err != nil {
+		demo.Exit(err)
+	}
+
+	received := 0
+	last := start
+	idle := time.NewTimer(time.Second)
+wait:
+	for received < *total {
+		idle.Reset(time.Second)
+		select {
+		case <-ws.GetDataChannel():
+			received++
+		case b := <-ws.GetBatchChannel():
+			received += len(b)
+		case <-idle.C:
+			break wait
+		}
+		last = time.Now()
+	}
+	elapsed := last.Sub(start)
+
+	var after runtime.MemStats
+	runtime.ReadMemStats(&after)
+	if dropped := *total - received; dropped > 0 {
+		fmt.Printf("dropped:  %d ticks, consider a larger channel or batching\n", dropped)
+	}
+
+	fmt.Printf("fan-out:  %d ticks in %s, %.0f ticks/s, %.0f B/tick, %d GCs\n",
+		received, elapsed.Round(time.Millisecond), float64(received)/elapsed.Seconds(),
+		float64(after.TotalAlloc-before.TotalAlloc)/float64(max(received, 1)), after.NumGC-before.NumGC)
+}
+
+// streamHandler upgrades the connection and writes total full-mode packets
+func streamHandler(total, tokens int) http.Handler {
+	upgrader := websocket.Upgrader{}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		packets := make([][]byte, tokens)
+		for i := range packets {
+			packets[i] = fullPacket(int32(i + 1))
+		}
+		for i := 0; i < total; i++ {
+			if err := conn.WriteMessage(websocket.BinaryMessage, packets[i%tokens]); err != nil {
+				return
+			}
+		}
+		// Keep the connection open until the client hangs up
+		conn.ReadMessage()
+	})
+}
+
+// fullPacket builds a full-mode packet for a token
+func fullPacket(token int32) []byte {
+	packet := make([]byte, ticks.FullPacketLength)
+	binary.BigEndian.PutUint32(packet[0:4], uint32(token))
+	binary.BigEndian.PutUint32(packet[4:8], 12345)
+	for offset := 89; offset+14 <= ticks.FullPacketLength; offset += 14 {
+		binary.BigEndian.PutUint32(packet[offset:offset+4], 100)
+		binary.BigEndian.PutUint32(packet[offset+8:offset+12], 12340)
+	}
+	return packet
+}
@@ -0,0 +1,71 @@
+//go:build examples
+
+// Command webhook receives TradingView alerts and places them as orders.
+//
+//	go run -tags examples ./examples/webhook -addr :8080 -dry-run
+//
+// Set the alert message in TradingView to, e.g.:
+//
+//	{"passphrase": "<WEBHOOK_SECRET>", "ticker": "{{exchange}}:{{ticker}}",
+//	 "action": "{{strategy.order.action}}", "quantity": "{{strategy.order.contracts}}",
+//	 "price": "{{close}}", "alert_id": "{{timenow}}"}
+//
+// TradingView only calls ports 80 and 443, so expose the receiver through a TLS proxy.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/Abhi13027/go-tiqs/examples/demo"
+	"github.com/Abhi13027/go-tiqs/tiqs"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "address to listen on")
+	dryRun := flag.Bool("dry-run", true, "validate alerts without placing orders")
+	maxValue := flag.Float64("max-value", 50000, "largest order value in rupees")
+	flag.Parse()
+
+	client, err := demo.Login()
+	if err != nil {
+		demo.Exit(err)
+	}
+	if _, err := client.LoadInstruments(false); err != nil {
+		demo.Exit(err)
+	}
+	client.SetDuplicateGuard(tiqs.NewDuplicateGuard(5 * time.Second))
+
+	secret := os.Getenv("WEBHOOK_SECRET")
+	if secret == "" {
+		demo.Exit(fmt.Errorf("WEBHOOK_SECRET is not set"))
+	}
+
+	receiver := tiqs.NewWebhookReceiver(client, secret)
+	receiver.DryRun = *dryRun
+	receiver.Rules = tiqs.WebhookRules{
+		Exchanges:       []tiqs.Exchange{tiqs.ExchangeNSE, tiqs.ExchangeNFO},
+		OrderValue:      *maxValue / 2,
+		MaxOrderValue:   *maxValue,
+		MarketHoursOnly: true,
+	}
+	receiver.OnResult = func(r tiqs.WebhookResult) {
+		switch {
+		case r.Error != "":
+			fmt.Printf("Rejected %s: %s\n", r.AlertID, r.Error)
+		case r.Order != nil:
+			fmt.Printf("%s %s %s x %s (order %q, dry run %v)\n", r.AlertID, r.Order.TransactionType, r.Order.Symbol, r.Order.Quantity, r.OrderNo, r.DryRun)
+		}
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	if err := receiver.ListenAndServe(ctx, *addr); err != nil {
+		demo.Exit(err)
+	}
+}
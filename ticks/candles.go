@@ -0,0 +1,335 @@
+package ticks
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Candle is an OHLCV bar built from ticks, prices are in paise like tick prices
+type Candle struct {
+	Token    int32         `json:"token"`
+	Interval time.Duration `json:"interval"`
+	Start    time.Time     `json:"start"`
+	End      time.Time     `json:"end"`
+	Open     int32         `json:"open"`
+	High     int32         `json:"high"`
+	Low      int32         `json:"low"`
+	Close    int32         `json:"close"`
+	Volume   int64         `json:"volume"`
+	OI       int32         `json:"oi"`
+	Ticks    int           `json:"ticks"`
+
+	lastAt time.Time // time of the tick that set Close
+}
+
+// TradingSession is the daily window bars are aligned to, as offsets from midnight in Location.
+// Bars start at Open, the last bar of the day ends at Close and ticks outside the window are ignored
+type TradingSession struct {
+	Open     time.Duration
+	Close    time.Duration
+	Location *time.Location
+}
+
+// NSESession is the equity and F&O session of NSE and BSE, 09:15 to 15:30 IST
+var NSESession = TradingSession{
+	Open:     9*time.Hour + 15*time.Minute,
+	Close:    15*time.Hour + 30*time.Minute,
+	Location: istLocation,
+}
+
+// MCXSession is the commodity session of MCX, 09:00 to 23:55 IST
+var MCXSession = TradingSession{
+	Open:     9 * time.Hour,
+	Close:    23*time.Hour + 55*time.Minute,
+	Location: istLocation,
+}
+
+// Default settings of a CandleAggregator
+const (
+	DefaultCandleChanSize = 1000
+	DefaultCandleLateness = 2 * time.Second
+)
+
+// CandleAggregator builds bars of several intervals per token from ticks.
+//
+// Ticks are bucketed by their exchange timestamp (LTT), bars are emitted once the newest
+// tick of the token or the wall clock is Lateness past their end, so slightly late ticks
+// still land in the right bar. Ticks older than an emitted bar are dropped and counted
+type CandleAggregator struct {
+	Intervals []time.Duration
+	Session   TradingSession
+	Lateness  time.Duration
+
+	mu        sync.Mutex
+	series    map[int32]*candleSeries
+	out       chan Candle
+	lateTicks int64
+}
+
+// candleSeries holds the open bars of a token
+type candleSeries struct {
+	lastVolume int64
+	watermark  time.Time
+	bars       map[time.Duration]map[int64]*Candle // keyed by interval, then bar start
+	emitted    map[time.Duration]time.Time         // end of the last emitted bar per interval
+}
+
+// NewCandleAggregator creates an aggregator for the given intervals (e.g., time.Second, time.Minute,
+// 5*time.Minute) aligned to the NSE session
+func NewCandleAggregator(intervals ...time.Duration) *CandleAggregator {
+	sorted := append([]time.Duration(nil), intervals...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	return &CandleAggregator{
+		Intervals: sorted,
+		Session:   NSESession,
+		Lateness:  DefaultCandleLateness,
+		series:    make(map[int32]*candleSeries),
+		out:       make(chan Candle, DefaultCandleChanSize),
+	}
+}
+
+// GetCandleChannel returns the channel Run emits bars on, closed when Run returns
+func (a *CandleAggregator) GetCandleChannel() <-chan Candle {
+	return a.out
+}
+
+// LateTicks returns the number of ticks dropped because their bar was already emitted
+func (a *CandleAggregator) LateTicks() int64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.lateTicks
+}
+
+// Run aggregates the ticks of in until it is closed or ctx is done, flushing bars of quiet
+// tokens every second. Remaining bars are emitted before the candle channel is closed
+func (a *CandleAggregator) Run(ctx context.Context, in <-chan TickData) error {
+	for _, interval := range a.Intervals {
+		if interval <= 0 {
+			close(a.out)
+			return fmt.Errorf("invalid candle interval: %s", interval)
+		}
+	}
+	defer close(a.out)
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			a.emit(context.Background(), a.FlushAll())
+			return ctx.Err()
+		case tick, ok := <-in:
+			if !ok {
+				a.emit(context.Background(), a.FlushAll())
+				return nil
+			}
+			a.emit(ctx, a.Update(tick))
+		case now := <-ticker.C:
+			a.emit(ctx, a.Flush(now))
+		}
+	}
+}
+
+// emit sends bars on the candle channel, giving up when ctx is done
+func (a *CandleAggregator) emit(ctx context.Context, candles []Candle) {
+	for _, c := range candles {
+		select {
+		case a.out <- c:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Update adds a tick to the bars of its token and returns the bars it completed, oldest first
+func (a *CandleAggregator) Update(tick TickData) []Candle {
+	if tick.Token < 0 || tick.LTP <= 0 {
+		return nil
+	}
+	at := tickTime(tick)
+	if !a.inSession(at) {
+		return nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	s, ok := a.series[tick.Token]
+	if !ok {
+		s = &candleSeries{
+			bars:    make(map[time.Duration]map[int64]*Candle),
+			emitted: make(map[time.Duration]time.Time),
+		}
+		a.series[tick.Token] = s
+	}
+
+	var traded int64
+	switch {
+	case tick.Volume > 0:
+		// The first tick only establishes the baseline of the cumulative volume
+		if s.lastVolume > 0 && tick.Volume > s.lastVolume {
+			traded = tick.Volume - s.lastVolume
+		}
+		if tick.Volume > s.lastVolume || a.newSession(s.watermark, at) {
+			s.lastVolume = tick.Volume
+		}
+	case tick.LTQ > 0:
+		traded = int64(tick.LTQ)
+	}
+
+	late := false
+	for _, interval := range a.Intervals {
+		start, end := a.bucket(at, interval)
+		if !end.After(s.emitted[interval]) {
+			late = true
+			continue
+		}
+
+		bars := s.bars[interval]
+		if bars == nil {
+			bars = make(map[int64]*Candle)
+			s.bars[interval] = bars
+		}
+		bar, ok := bars[start.UnixNano()]
+		if !ok {
+			bar = &Candle{Token: tick.Token, Interval: interval, Start: start, End: end, Open: tick.LTP, High: tick.LTP, Low: tick.LTP}
+			bars[start.UnixNano()] = bar
+		}
+		bar.High = max(bar.High, tick.LTP)
+		bar.Low = min(bar.Low, tick.LTP)
+		if !at.Before(bar.lastAt) {
+			bar.Close, bar.lastAt = tick.LTP, at
+		}
+		bar.Volume += traded
+		bar.OI = tick.OI
+		bar.Ticks++
+	}
+	if late {
+		a.lateTicks++
+	}
+
+	if at.After(s.watermark) {
+		s.watermark = at
+	}
+	return a.completeLocked(s, s.watermark.Add(-a.Lateness), false)
+}
+
+// Flush returns the bars of every token whose end is Lateness before now, e.g., bars of
+// illiquid tokens that no newer tick will close
+func (a *CandleAggregator) Flush(now time.Time) []Candle {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var candles []Candle
+	for _, s := range a.series {
+		candles = append(candles, a.completeLocked(s, now.Add(-a.Lateness), false)...)
+	}
+	sortCandles(candles)
+	return candles
+}
+
+// FlushAll returns every open bar, e.g., at the end of the session
+func (a *CandleAggregator) FlushAll() []Candle {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var candles []Candle
+	for _, s := range a.series {
+		candles = append(candles, a.completeLocked(s, time.Time{}, true)...)
+	}
+	sortCandles(candles)
+	return candles
+}
+
+// completeLocked removes and returns the bars of a series ending by cutoff, or all bars,
+// the caller must hold a.mu
+func (a *CandleAggregator) completeLocked(s *candleSeries, cutoff time.Time, all bool) []Candle {
+	var candles []Candle
+	for interval, bars := range s.bars {
+		for key, bar := range bars {
+			if !all && bar.End.After(cutoff) {
+				continue
+			}
+			candles = append(candles, *bar)
+			delete(bars, key)
+			if bar.End.After(s.emitted[interval]) {
+				s.emitted[interval] = bar.End
+			}
+		}
+	}
+	sortCandles(candles)
+	return candles
+}
+
+// bucket returns the bar of an interval containing t, aligned to the session open and
+// clipped to the session close
+func (a *CandleAggregator) bucket(t time.Time, interval time.Duration) (time.Time, time.Time) {
+	open, close := a.sessionBounds(t)
+	start := open.Add(t.Sub(open) / interval * interval)
+	end := start.Add(interval)
+	if end.After(close) {
+		end = close
+	}
+	return start, end
+}
+
+// sessionBounds returns the open and close of the session on the day of t
+func (a *CandleAggregator) sessionBounds(t time.Time) (time.Time, time.Time) {
+	loc := a.Session.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	local := t.In(loc)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	if a.Session.Close <= a.Session.Open {
+		return midnight, midnight.Add(24 * time.Hour)
+	}
+	return midnight.Add(a.Session.Open), midnight.Add(a.Session.Close)
+}
+
+// newSession reports whether t falls in a later session than the watermark, after which
+// cumulative volumes restart from zero
+func (a *CandleAggregator) newSession(watermark, t time.Time) bool {
+	if watermark.IsZero() {
+		return false
+	}
+	open, _ := a.sessionBounds(t)
+	return watermark.Before(open)
+}
+
+// inSession reports whether t falls within the trading session
+func (a *CandleAggregator) inSession(t time.Time) bool {
+	open, close := a.sessionBounds(t)
+	return !t.Before(open) && t.Before(close)
+}
+
+// tickTime returns the exchange time of a tick, falling back to the feed time and the clock
+func tickTime(tick TickData) time.Time {
+	switch {
+	case tick.LTT > 0:
+		return time.Unix(int64(tick.LTT), 0)
+	case tick.Time > 0:
+		return time.Unix(int64(tick.Time), 0)
+	default:
+		return time.Now()
+	}
+}
+
+// sortCandles orders bars by end, then interval and token
+func sortCandles(candles []Candle) {
+	sort.Slice(candles, func(i, j int) bool {
+		ci, cj := candles[i], candles[j]
+		if !ci.End.Equal(cj.End) {
+			return ci.End.Before(cj.End)
+		}
+		if ci.Interval != cj.Interval {
+			return ci.Interval < cj.Interval
+		}
+		return ci.Token < cj.Token
+	})
+}
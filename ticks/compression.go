@@ -0,0 +1,91 @@
+package ticks
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gorilla/websocket"
+)
+
+// CompressionStats reports how much data was received over the WebSocket connection
+type CompressionStats struct {
+	Negotiated   bool    `json:"negotiated"`   // Whether the server accepted permessage-deflate
+	Messages     int64   `json:"messages"`     // Messages received
+	WireBytes    int64   `json:"wireBytes"`    // Bytes received on the network connection, including framing and TLS
+	PayloadBytes int64   `json:"payloadBytes"` // Bytes of the decompressed message payloads
+	Ratio        float64 `json:"ratio"`        // WireBytes divided by PayloadBytes, 0 if nothing was received
+}
+
+// wireStats holds the counters behind CompressionStats
+type wireStats struct {
+	negotiated   atomic.Bool
+	messages     atomic.Int64
+	wireBytes    atomic.Int64
+	payloadBytes atomic.Int64
+}
+
+// countingConn counts the bytes read from the underlying connection
+type countingConn struct {
+	net.Conn
+	stats *wireStats
+}
+
+// Read implements net.Conn
+func (c *countingConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	c.stats.wireBytes.Add(int64(n))
+	return n, err
+}
+
+// CompressionStats returns the bytes received on the wire and after decompression
+// since the client was created
+func (ws *WS) CompressionStats() CompressionStats {
+	stats := CompressionStats{
+		Negotiated:   ws.stats.negotiated.Load(),
+		Messages:     ws.stats.messages.Load(),
+		WireBytes:    ws.stats.wireBytes.Load(),
+		PayloadBytes: ws.stats.payloadBytes.Load(),
+	}
+	if stats.PayloadBytes > 0 {
+		stats.Ratio = float64(stats.WireBytes) / float64(stats.PayloadBytes)
+	}
+	return stats
+}
+
+// dialer returns a dialer that counts received bytes and requests compression if enabled,
+// onDial is called with every connection opened
+func (ws *WS) dialer(onDial func(net.Conn)) *websocket.Dialer {
+	dialer := *websocket.DefaultDialer
+	dialer.EnableCompression = ws.EnableCompression
+
+	var netDialer net.Dialer
+	dialer.NetDialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := netDialer.DialContext(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		onDial(conn)
+		return &countingConn{Conn: conn, stats: &ws.stats}, nil
+	}
+	return &dialer
+}
+
+// recordHandshake records whether the server accepted permessage-deflate
+func (ws *WS) recordHandshake(resp *http.Response) {
+	negotiated := false
+	if resp != nil {
+		for _, ext := range resp.Header.Values("Sec-WebSocket-Extensions") {
+			if strings.Contains(ext, "permessage-deflate") {
+				negotiated = true
+			}
+		}
+	}
+	ws.stats.negotiated.Store(negotiated)
+
+	if ws.EnableCompression && !negotiated {
+		ws.logger.Warn().Msg("WebSocket compression requested but not negotiated by the server")
+	}
+}
@@ -0,0 +1,87 @@
+package ticks
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultCredentialsTimeout bounds a single call to a CredentialsProvider
+const DefaultCredentialsTimeout = 10 * time.Second
+
+// CredentialsProvider supplies the app ID and access token used to authenticate sockets.
+// It is called before every dial, reconnects included, so a provider backed by an
+// external secret service picks up refreshed tokens without restarting the client
+type CredentialsProvider interface {
+	Credentials(ctx context.Context) (appID, token string, err error)
+}
+
+// CredentialsFunc adapts a function to a CredentialsProvider
+type CredentialsFunc func(ctx context.Context) (appID, token string, err error)
+
+// Credentials calls f
+func (f CredentialsFunc) Credentials(ctx context.Context) (string, string, error) {
+	return f(ctx)
+}
+
+// StaticCredentials is a CredentialsProvider returning fixed credentials
+type StaticCredentials struct {
+	AppID string
+	Token string
+}
+
+// Credentials returns the fixed credentials
+func (s StaticCredentials) Credentials(context.Context) (string, string, error) {
+	return s.AppID, s.Token, nil
+}
+
+// FileCredentials is a CredentialsProvider reading the token from a file on every dial,
+// e.g., a secret mounted by a secret manager agent that rewrites it on rotation
+type FileCredentials struct {
+	AppID     string
+	TokenPath string
+}
+
+// Credentials returns the app ID and the trimmed content of the token file
+func (f FileCredentials) Credentials(context.Context) (string, string, error) {
+	token, err := os.ReadFile(f.TokenPath)
+	if err != nil {
+		return "", "", fmt.Errorf("error reading token file: %w", err)
+	}
+	return f.AppID, strings.TrimSpace(string(token)), nil
+}
+
+// NewWSWithCredentials creates a market data client authenticating with provider,
+// for data-only deployments that do not hold a REST client
+func NewWSWithCredentials(provider CredentialsProvider) *WS {
+	ws := NewWS("", "")
+	ws.Credentials = provider
+	return ws
+}
+
+// NewOrderSocketWithCredentials creates an order update socket authenticating with provider
+func NewOrderSocketWithCredentials(provider CredentialsProvider) *OrderSocket {
+	s := NewOrderSocket("", "")
+	s.Credentials = provider
+	return s
+}
+
+// dialURL builds the URL of a socket, asking provider for credentials when set
+func dialURL(ctx context.Context, base string, provider CredentialsProvider, appID, token string) (string, error) {
+	if provider != nil {
+		ctx, cancel := context.WithTimeout(ctx, DefaultCredentialsTimeout)
+		defer cancel()
+
+		var err error
+		if appID, token, err = provider.Credentials(ctx); err != nil {
+			return "", fmt.Errorf("error fetching credentials: %w", err)
+		}
+	}
+	if appID == "" || token == "" {
+		return "", fmt.Errorf("missing credentials: app ID and token are required")
+	}
+	return fmt.Sprintf("%s?appId=%s&token=%s", base, url.QueryEscape(appID), url.QueryEscape(token)), nil
+}
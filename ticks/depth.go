@@ -0,0 +1,72 @@
+package ticks
+
+import (
+	"sync"
+	"time"
+)
+
+// DepthSnapshot represents the latest known market depth for a token
+type DepthSnapshot struct {
+	Token     int32       `json:"token"`
+	LTP       int32       `json:"ltp"`
+	Depth     MarketDepth `json:"market_depth"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// DepthBook maintains the latest market depth per token from full-mode ticks
+type DepthBook struct {
+	mu    sync.RWMutex
+	books map[int32]DepthSnapshot
+}
+
+// NewDepthBook creates an empty depth book
+func NewDepthBook() *DepthBook {
+	return &DepthBook{books: make(map[int32]DepthSnapshot)}
+}
+
+// Update applies a tick to the book. Ticks without depth information are ignored.
+func (b *DepthBook) Update(tick TickData) {
+	if tick.Token < 0 || !tick.MarketDepth.hasLevels() {
+		return
+	}
+
+	b.mu.Lock()
+	b.books[tick.Token] = DepthSnapshot{
+		Token:     tick.Token,
+		LTP:       tick.LTP,
+		Depth:     tick.MarketDepth,
+		UpdatedAt: time.Now(),
+	}
+	b.mu.Unlock()
+}
+
+// Get returns the latest depth snapshot for a token
+func (b *DepthBook) Get(token int32) (DepthSnapshot, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	snapshot, ok := b.books[token]
+	return snapshot, ok
+}
+
+// Tokens returns the tokens currently present in the book
+func (b *DepthBook) Tokens() []int32 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	tokens := make([]int32, 0, len(b.books))
+	for token := range b.books {
+		tokens = append(tokens, token)
+	}
+	return tokens
+}
+
+// hasLevels reports whether any depth level carries a quantity
+func (d MarketDepth) hasLevels() bool {
+	for i := range d.Bids {
+		if d.Bids[i].Quantity != 0 || d.Asks[i].Quantity != 0 {
+			return true
+		}
+	}
+	return false
+}
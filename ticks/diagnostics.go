@@ -0,0 +1,48 @@
+package ticks
+
+// Diagnostics reports the sizes of the client's channels and internal state, to spot
+// consumers falling behind and state growing without bound over long sessions
+type Diagnostics struct {
+	DataChanLen   int   `json:"dataChanLen"`   // Ticks waiting on DataChan
+	DataChanCap   int   `json:"dataChanCap"`   // Capacity of DataChan
+	BatchChanLen  int   `json:"batchChanLen"`  // Batches waiting on BatchChan, 0 without batching
+	ErrChanLen    int   `json:"errChanLen"`    // Errors waiting on the error channel
+	Subscriptions int   `json:"subscriptions"` // Tokens currently subscribed
+	TokenList     int   `json:"tokenList"`     // Entries in TokenList, one per subscribed token
+	Handlers      int   `json:"handlers"`      // Handlers registered with SubscribeWithHandler, per token
+	TokenChannels int   `json:"tokenChannels"` // Open channels returned by TokenChannel
+	Messages      int64 `json:"messages"`      // Messages received since the client was created
+	Stale         int64 `json:"stale"`         // Connections dropped by the heartbeat as stale
+}
+
+// Diagnostics returns a snapshot of the client's channel depths and state sizes
+func (ws *WS) Diagnostics() Diagnostics {
+	d := Diagnostics{
+		DataChanLen: len(ws.DataChan),
+		DataChanCap: cap(ws.DataChan),
+		ErrChanLen:  len(ws.errChan),
+		Messages:    ws.stats.messages.Load(),
+		Stale:       ws.stale.Load(),
+	}
+	if ws.BatchChan != nil {
+		d.BatchChanLen = len(ws.BatchChan)
+	}
+	ws.mu.RLock()
+	d.TokenList = len(ws.TokenList)
+	ws.mu.RUnlock()
+
+	ws.subscriptions.Range(func(any, any) bool {
+		d.Subscriptions++
+		return true
+	})
+
+	if r := ws.routes.Load(); r != nil {
+		r.mu.RLock()
+		for _, handlers := range r.handlers {
+			d.Handlers += len(handlers)
+		}
+		d.TokenChannels = len(r.channels)
+		r.mu.RUnlock()
+	}
+	return d
+}
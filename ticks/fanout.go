@@ -0,0 +1,217 @@
+package ticks
+
+import (
+	"runtime"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultDataChanSize is the capacity of the tick channel created by NewWS
+	DefaultDataChanSize = 1000
+	// DefaultBatchInterval is the longest a partial batch waits before delivery
+	DefaultBatchInterval = 50 * time.Millisecond
+)
+
+// TuningOptions controls how received frames are parsed and delivered.
+//
+// The defaults parse every frame on the read goroutine and deliver ticks one by one,
+// which is enough for a few hundred tokens. Subscribers to full option chains, who
+// receive tens of thousands of ticks per second, can parse on a worker pool and take
+// ticks in batches to cut channel operations and scheduler wake-ups.
+type TuningOptions struct {
+	// Capacity of DataChan, or of BatchChan when batching; zero keeps DefaultDataChanSize
+	DataChanSize int
+
+	// Number of parsing goroutines. Zero parses on the read goroutine, a negative value
+	// uses one worker per GOMAXPROCS. Frames are sharded by token, so the ticks of a
+	// token are still delivered in the order they were received.
+	ParseWorkers int
+
+	// Capacity of the queue in front of each parsing goroutine
+	ParseQueueSize int
+
+	// Number of ticks per batch on BatchChan. Zero delivers single ticks on DataChan.
+	BatchSize int
+
+	// Longest a partial batch waits before delivery, zero uses DefaultBatchInterval
+	BatchInterval time.Duration
+}
+
+// fanOut holds the parsing workers and the batcher started from TuningOptions
+type fanOut struct {
+	opts    TuningOptions
+	queues  []chan *frame
+	batchIn chan TickData
+	once    sync.Once
+}
+
+// Tune applies tuning options. It must be called before Connect and replaces
+// DataChan, so channels obtained earlier from GetDataChannel must be fetched again.
+// When batching is enabled ticks are delivered on GetBatchChannel instead of DataChan.
+func (ws *WS) Tune(opts TuningOptions) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	if opts.ParseWorkers < 0 {
+		opts.ParseWorkers = runtime.GOMAXPROCS(0)
+	}
+	if opts.ParseQueueSize <= 0 {
+		opts.ParseQueueSize = 256
+	}
+	if opts.BatchInterval <= 0 {
+		opts.BatchInterval = DefaultBatchInterval
+	}
+	size := opts.DataChanSize
+	if size <= 0 {
+		size = DefaultDataChanSize
+	}
+
+	ws.DataChan = make(chan TickData, size)
+	if opts.BatchSize > 0 {
+		ws.BatchChan = make(chan []TickData, size)
+	}
+	ws.fanOut = &fanOut{opts: opts}
+}
+
+// GetBatchChannel returns the channel receiving batches of ticks when batching is enabled
+func (ws *WS) GetBatchChannel() <-chan []TickData {
+	return ws.BatchChan
+}
+
+// start launches the parsing workers and the batcher once
+func (f *fanOut) start(ws *WS) {
+	f.once.Do(func() {
+		if f.opts.BatchSize > 0 {
+			f.batchIn = make(chan TickData, f.opts.BatchSize*4)
+			ws.goTracked(func() { ws.runBatcher(f.batchIn) })
+		}
+		for i := 0; i < f.opts.ParseWorkers; i++ {
+			queue := make(chan *frame, f.opts.ParseQueueSize)
+			f.queues = append(f.queues, queue)
+			ws.goTracked(func() { ws.runParser(queue) })
+		}
+	})
+}
+
+// dispatchFrame parses a binary frame, on a worker if a pool is configured, and delivers the tick.
+// A full parse queue blocks the read goroutine, leaving flow control to TCP. The frame is
+// released once parsed
+func (ws *WS) dispatchFrame(message *frame) {
+	if f := ws.fanOut; f != nil && len(f.queues) > 0 {
+		select {
+		case f.queues[shardOf(message.data, len(f.queues))] <- message:
+		case <-ws.ctx.Done():
+			message.release()
+		}
+		return
+	}
+	ws.parseAndDeliver(message.data)
+	message.release()
+}
+
+// parseAndDeliver parses a binary frame and delivers the resulting tick. The full book of
+// a 20-level depth packet is also sent on Depth20Chan without blocking
+func (ws *WS) parseAndDeliver(message []byte) {
+	var tick TickData
+	if err := ParseTickInto(message, &tick); err != nil {
+		ws.logger.Error().Err(err).Msg("Error parsing binary data")
+		return
+	}
+	if len(message) == Depth20PacketLength {
+		ws.deliverDepth20(message)
+	}
+	ws.deliver(tick)
+}
+
+// deliverDepth20 sends the 20-level depth of a packet on Depth20Chan without blocking
+func (ws *WS) deliverDepth20(message []byte) {
+	var depth Depth20Tick
+	if err := ParseDepth20Into(message, &depth); err != nil {
+		ws.logger.Error().Err(err).Msg("Error parsing depth20 data")
+		return
+	}
+	select {
+	case ws.Depth20Chan <- depth:
+	default:
+		ws.logger.Warn().Int32("token", depth.Token).Msg("Depth20 channel is full, skipping message")
+	}
+}
+
+// deliver sends a tick to the handlers or channel of its token, if any, and otherwise to
+// the batcher or to DataChan without blocking
+func (ws *WS) deliver(tick TickData) {
+	if ws.route(tick) {
+		return
+	}
+
+	if f := ws.fanOut; f != nil && f.batchIn != nil {
+		select {
+		case f.batchIn <- tick:
+		case <-ws.ctx.Done():
+		}
+		return
+	}
+
+	select {
+	case ws.DataChan <- tick:
+	default:
+		ws.logger.Warn().Msg("Data channel is full, skipping message")
+	}
+}
+
+// runParser parses the frames of one shard until the client is closed
+func (ws *WS) runParser(queue <-chan *frame) {
+	for {
+		select {
+		case <-ws.ctx.Done():
+			return
+		case message := <-queue:
+			ws.parseAndDeliver(message.data)
+			message.release()
+		}
+	}
+}
+
+// runBatcher groups ticks into batches of BatchSize, flushing partial batches every BatchInterval
+func (ws *WS) runBatcher(in <-chan TickData) {
+	size := ws.fanOut.opts.BatchSize
+	ticker := time.NewTicker(ws.fanOut.opts.BatchInterval)
+	defer ticker.Stop()
+
+	batch := make([]TickData, 0, size)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		select {
+		case ws.BatchChan <- batch:
+		default:
+			ws.logger.Warn().Int("ticks", len(batch)).Msg("Batch channel is full, skipping batch")
+		}
+		batch = make([]TickData, 0, size)
+	}
+
+	for {
+		select {
+		case <-ws.ctx.Done():
+			return
+		case tick := <-in:
+			batch = append(batch, tick)
+			if len(batch) >= size {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// shardOf picks the worker of a frame from its token, so per-token ordering is kept
+func shardOf(message []byte, shards int) int {
+	if len(message) < 4 {
+		return 0
+	}
+	token := uint32(message[0])<<24 | uint32(message[1])<<16 | uint32(message[2])<<8 | uint32(message[3])
+	return int(token % uint32(shards))
+}
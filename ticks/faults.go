@@ -0,0 +1,99 @@
+package ticks
+
+import (
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ErrInjectedDisconnect is reported when a FaultInjector drops the connection
+var ErrInjectedDisconnect = errors.New("injected fault: connection dropped")
+
+// FaultConfig sets the per-message probabilities of the faults injected into the stream
+type FaultConfig struct {
+	DisconnectRate float64       // Probability that the connection is dropped after a message
+	MalformedRate  float64       // Probability that a binary frame is truncated before parsing
+	DelayRate      float64       // Probability that a message is delayed
+	MaxDelay       time.Duration // Upper bound of an injected delay
+	Seed           int64         // Seed of the random source, zero seeds from the current time
+}
+
+// FaultStats counts the faults injected so far
+type FaultStats struct {
+	Messages    int64 `json:"messages"`
+	Disconnects int64 `json:"disconnects"`
+	Malformed   int64 `json:"malformed"`
+	Delays      int64 `json:"delays"`
+}
+
+// FaultInjector drops the connection, truncates frames and delays messages at random,
+// so that reconnect, resubscribe and parsing paths can be exercised in tests.
+// Attach one to WS.Faults before calling Connect.
+type FaultInjector struct {
+	mu      sync.Mutex
+	config  FaultConfig
+	rng     *rand.Rand
+	stats   FaultStats
+	enabled bool
+}
+
+// NewFaultInjector creates an enabled injector
+func NewFaultInjector(config FaultConfig) *FaultInjector {
+	seed := config.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &FaultInjector{
+		config:  config,
+		rng:     rand.New(rand.NewSource(seed)),
+		enabled: true,
+	}
+}
+
+// SetEnabled turns fault injection on or off
+func (f *FaultInjector) SetEnabled(enabled bool) {
+	f.mu.Lock()
+	f.enabled = enabled
+	f.mu.Unlock()
+}
+
+// Stats returns the number of faults injected so far
+func (f *FaultInjector) Stats() FaultStats {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.stats
+}
+
+// apply injects faults into a received message. It returns the message to process,
+// possibly truncated, and ErrInjectedDisconnect if the connection must be dropped.
+func (f *FaultInjector) apply(message []byte) ([]byte, error) {
+	f.mu.Lock()
+	if !f.enabled {
+		f.mu.Unlock()
+		return message, nil
+	}
+	f.stats.Messages++
+
+	var delay time.Duration
+	if f.config.MaxDelay > 0 && f.rng.Float64() < f.config.DelayRate {
+		delay = time.Duration(f.rng.Int63n(int64(f.config.MaxDelay)))
+		f.stats.Delays++
+	}
+	disconnect := f.rng.Float64() < f.config.DisconnectRate
+	if disconnect {
+		f.stats.Disconnects++
+	} else if len(message) > 1 && f.rng.Float64() < f.config.MalformedRate {
+		message = message[:f.rng.Intn(len(message))]
+		f.stats.Malformed++
+	}
+	f.mu.Unlock()
+
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+	if disconnect {
+		return nil, ErrInjectedDisconnect
+	}
+	return message, nil
+}
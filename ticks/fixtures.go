@@ -0,0 +1,135 @@
+package ticks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// GoldenSuffix is appended to the name of a captured frame, without its .bin extension, to
+// name the file holding its expected decoding
+const GoldenSuffix = ".golden.json"
+
+// FrameResult is the outcome of decoding one captured frame against its golden file
+type FrameResult struct {
+	File string // Path of the frame
+	Got  []byte // Decoding of the frame, see DecodeFrame
+	Err  error  // Mismatch or read error, or nil if the decoding equals the golden file
+}
+
+// frameGolden is the golden representation of a frame: the decoded tick, or the error
+// the frame must be rejected with
+type frameGolden struct {
+	Tick  *TickData `json:"tick,omitempty"`
+	Error string    `json:"error,omitempty"`
+}
+
+// DecodeFrame decodes a binary frame with ParseTick and returns the result as indented
+// JSON, the format of golden files: {"tick": {...}} or {"error": "..."}
+func DecodeFrame(frame []byte) ([]byte, error) {
+	var golden frameGolden
+	if tick, err := ParseTick(frame); err != nil {
+		golden.Error = err.Error()
+	} else {
+		golden.Tick = &tick
+	}
+	out, err := json.MarshalIndent(golden, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+// CheckFrames decodes every captured frame of a corpus and compares it with its golden
+// file, so that parser changes can be verified against real packets.
+//
+// Frames are raw packets as received on the WebSocket, stored as "<name>.bin" with the
+// expected decoding in "<name>.golden.json" next to them (see DecodeFrame). Files are
+// compared as JSON, so formatting differences do not matter. A frame without golden file
+// fails; create it with UpdateFrameGoldens once the decoding was checked by hand
+func CheckFrames(fixtures fs.FS) ([]FrameResult, error) {
+	files, err := fs.Glob(fixtures, "*.bin")
+	if err != nil {
+		return nil, fmt.Errorf("error listing frames: %w", err)
+	}
+	sort.Strings(files)
+
+	results := make([]FrameResult, 0, len(files))
+	for _, file := range files {
+		results = append(results, checkFrame(fixtures, file))
+	}
+	return results, nil
+}
+
+// checkFrame decodes one frame and compares it with its golden file
+func checkFrame(fixtures fs.FS, file string) FrameResult {
+	result := FrameResult{File: file}
+	frame, err := fs.ReadFile(fixtures, file)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	if result.Got, err = DecodeFrame(frame); err != nil {
+		result.Err = err
+		return result
+	}
+	want, err := fs.ReadFile(fixtures, goldenName(file))
+	if err != nil {
+		result.Err = fmt.Errorf("missing golden file: %w", err)
+		return result
+	}
+	result.Err = CompareJSON(want, result.Got)
+	return result
+}
+
+// UpdateFrameGoldens writes the golden file of every frame in dir from the current
+// decoder, e.g., after adding captures or after a deliberate change of the decoding.
+// Review the differences before committing them
+func UpdateFrameGoldens(dir string) (int, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.bin"))
+	if err != nil {
+		return 0, err
+	}
+	for i, file := range files {
+		frame, err := os.ReadFile(file)
+		if err != nil {
+			return i, err
+		}
+		golden, err := DecodeFrame(frame)
+		if err != nil {
+			return i, err
+		}
+		if err := os.WriteFile(goldenName(file), golden, 0o644); err != nil {
+			return i, err
+		}
+	}
+	return len(files), nil
+}
+
+// CompareJSON returns an error if two JSON documents differ, ignoring formatting and the
+// order of object keys
+func CompareJSON(want, got []byte) error {
+	var w, g any
+	if err := json.Unmarshal(want, &w); err != nil {
+		return fmt.Errorf("invalid golden file: %w", err)
+	}
+	if err := json.Unmarshal(got, &g); err != nil {
+		return fmt.Errorf("invalid decoding: %w", err)
+	}
+	wb, _ := json.Marshal(w)
+	gb, _ := json.Marshal(g)
+	if !bytes.Equal(wb, gb) {
+		return fmt.Errorf("decoding differs from golden file:\n want %s\n got  %s", wb, gb)
+	}
+	return nil
+}
+
+// goldenName returns the golden file of a frame
+func goldenName(file string) string {
+	return strings.TrimSuffix(file, ".bin") + GoldenSuffix
+}
@@ -0,0 +1,167 @@
+package ticks
+
+import (
+	"encoding/binary"
+	"math"
+	"sync"
+)
+
+// GreeksSource tells where the Greeks of a tick come from
+type GreeksSource string
+
+const (
+	GreeksFromPacket GreeksSource = "PACKET"   // Parsed from an extended binary packet
+	GreeksComputed   GreeksSource = "COMPUTED" // Computed locally from prices with Black-Scholes
+)
+
+// Greeks holds the implied volatility and sensitivities of an option
+type Greeks struct {
+	IV     float64      `json:"iv"`     // Implied volatility as a fraction (0.15 for 15%)
+	Delta  float64      `json:"delta"`  // Change in option price per unit change in the underlying
+	Gamma  float64      `json:"gamma"`  // Change in delta per unit change in the underlying
+	Theta  float64      `json:"theta"`  // Change in option price per calendar day
+	Vega   float64      `json:"vega"`   // Change in option price per one point of volatility
+	Rho    float64      `json:"rho"`    // Change in option price per one point of the risk-free rate
+	Source GreeksSource `json:"source"` // Where the values come from
+}
+
+// GreeksLayout describes where Greeks are located in an extended binary packet.
+// Offsets point at big endian int32 fields and are negative for absent fields;
+// raw values are divided by Scale.
+type GreeksLayout struct {
+	IV    int
+	Delta int
+	Gamma int
+	Theta int
+	Vega  int
+	Scale float64
+}
+
+var (
+	greeksLayoutsMu sync.RWMutex
+	greeksLayouts   = map[int]GreeksLayout{}
+)
+
+// RegisterGreeksLayout registers the Greeks layout of packets of the given length, so
+// that ticks parsed from such packets carry Greeks. The standard packets do not include
+// Greeks, so no layout is registered by default.
+func RegisterGreeksLayout(packetLength int, layout GreeksLayout) {
+	greeksLayoutsMu.Lock()
+	greeksLayouts[packetLength] = layout
+	greeksLayoutsMu.Unlock()
+}
+
+// parseGreeks extracts Greeks from a packet whose length has a registered layout
+func parseGreeks(data []byte) *Greeks {
+	greeksLayoutsMu.RLock()
+	layout, ok := greeksLayouts[len(data)]
+	greeksLayoutsMu.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	scale := layout.Scale
+	if scale == 0 {
+		scale = 1
+	}
+	field := func(offset int) float64 {
+		if offset < 0 || offset+4 > len(data) {
+			return 0
+		}
+		return float64(int32(binary.BigEndian.Uint32(data[offset:offset+4]))) / scale
+	}
+
+	return &Greeks{
+		IV:     field(layout.IV),
+		Delta:  field(layout.Delta),
+		Gamma:  field(layout.Gamma),
+		Theta:  field(layout.Theta),
+		Vega:   field(layout.Vega),
+		Source: GreeksFromPacket,
+	}
+}
+
+// BlackScholesGreeks computes the Greeks of a European option.
+//
+// spot and strike are in the same currency unit, years is the time to expiry in years,
+// rate the continuously compounded risk-free rate and iv the volatility, both as fractions.
+func BlackScholesGreeks(call bool, spot, strike, years, rate, iv float64) Greeks {
+	greeks := Greeks{IV: iv, Source: GreeksComputed}
+	if spot <= 0 || strike <= 0 || years <= 0 || iv <= 0 {
+		return greeks
+	}
+
+	sqrtT := math.Sqrt(years)
+	d1 := (math.Log(spot/strike) + (rate+iv*iv/2)*years) / (iv * sqrtT)
+	d2 := d1 - iv*sqrtT
+	pdf := normPDF(d1)
+	discount := math.Exp(-rate * years)
+
+	greeks.Gamma = pdf / (spot * iv * sqrtT)
+	greeks.Vega = spot * pdf * sqrtT / 100
+
+	if call {
+		greeks.Delta = normCDF(d1)
+		greeks.Theta = (-spot*pdf*iv/(2*sqrtT) - rate*strike*discount*normCDF(d2)) / 365
+		greeks.Rho = strike * years * discount * normCDF(d2) / 100
+	} else {
+		greeks.Delta = normCDF(d1) - 1
+		greeks.Theta = (-spot*pdf*iv/(2*sqrtT) + rate*strike*discount*normCDF(-d2)) / 365
+		greeks.Rho = -strike * years * discount * normCDF(-d2) / 100
+	}
+	return greeks
+}
+
+// BlackScholesPrice returns the price of a European option
+func BlackScholesPrice(call bool, spot, strike, years, rate, iv float64) float64 {
+	if years <= 0 || iv <= 0 {
+		if call {
+			return math.Max(0, spot-strike)
+		}
+		return math.Max(0, strike-spot)
+	}
+
+	sqrtT := math.Sqrt(years)
+	d1 := (math.Log(spot/strike) + (rate+iv*iv/2)*years) / (iv * sqrtT)
+	d2 := d1 - iv*sqrtT
+	discount := math.Exp(-rate * years)
+
+	if call {
+		return spot*normCDF(d1) - strike*discount*normCDF(d2)
+	}
+	return strike*discount*normCDF(-d2) - spot*normCDF(-d1)
+}
+
+// ImpliedVolatility solves for the volatility at which the Black-Scholes price equals
+// price, by bisection between 0.1% and 500%. It reports false if no such volatility exists.
+func ImpliedVolatility(call bool, price, spot, strike, years, rate float64) (float64, bool) {
+	if price <= 0 || spot <= 0 || strike <= 0 || years <= 0 {
+		return 0, false
+	}
+
+	low, high := 0.001, 5.0
+	if price < BlackScholesPrice(call, spot, strike, years, rate, low) ||
+		price > BlackScholesPrice(call, spot, strike, years, rate, high) {
+		return 0, false
+	}
+
+	for i := 0; i < 100 && high-low > 1e-6; i++ {
+		mid := (low + high) / 2
+		if BlackScholesPrice(call, spot, strike, years, rate, mid) < price {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+	return (low + high) / 2, true
+}
+
+// normCDF is the standard normal cumulative distribution function
+func normCDF(x float64) float64 {
+	return 0.5 * math.Erfc(-x/math.Sqrt2)
+}
+
+// normPDF is the standard normal probability density function
+func normPDF(x float64) float64 {
+	return math.Exp(-x*x/2) / math.Sqrt(2*math.Pi)
+}
@@ -0,0 +1,87 @@
+package ticks
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// DefaultPingInterval is the delay between two pings sent by NewWS clients
+	DefaultPingInterval = 20 * time.Second
+	// DefaultPongTimeout is how long NewWS clients wait for traffic after a ping
+	DefaultPongTimeout = 10 * time.Second
+
+	// pingWriteTimeout bounds the time spent writing a ping
+	pingWriteTimeout = 5 * time.Second
+)
+
+// ErrStale is reported when the connection received nothing, not even a pong, within its
+// read timeout, typically a half-open connection that silently stopped delivering ticks
+var ErrStale = errors.New("websocket connection is stale")
+
+// readTimeout returns how long the read loop waits for a message, or 0 for no deadline.
+// With pings enabled the server has PingInterval plus PongTimeout to send anything.
+func (ws *WS) readTimeout() time.Duration {
+	if ws.ReadTimeout > 0 {
+		return ws.ReadTimeout
+	}
+	if ws.PingInterval > 0 {
+		return ws.PingInterval + ws.PongTimeout
+	}
+	return 0
+}
+
+// extendDeadline pushes the read deadline of a connection after traffic was received
+func (ws *WS) extendDeadline(conn *websocket.Conn) {
+	if timeout := ws.readTimeout(); timeout > 0 {
+		conn.SetReadDeadline(time.Now().Add(timeout))
+	}
+}
+
+// startHeartbeat arms the read deadline of a new connection and starts pinging it every
+// PingInterval until stop is closed. Pongs extend the deadline like any other message
+func (ws *WS) startHeartbeat(conn *websocket.Conn, stop <-chan struct{}) {
+	ws.extendDeadline(conn)
+	conn.SetPongHandler(func(string) error {
+		ws.extendDeadline(conn)
+		return nil
+	})
+	if ws.PingInterval <= 0 {
+		return
+	}
+
+	ws.goTracked(func() {
+		ticker := time.NewTicker(ws.PingInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ws.ctx.Done():
+				return
+			case <-stop:
+				return
+			case <-ticker.C:
+				// WriteControl may be called concurrently with the other write methods
+				if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(pingWriteTimeout)); err != nil {
+					ws.logger.Warn().Err(err).Msg("Failed to send ping")
+					return
+				}
+			}
+		}
+	})
+}
+
+// staleError converts a read deadline expiry into ErrStale, counting it, and returns
+// other errors unchanged
+func (ws *WS) staleError(err error) error {
+	var netErr net.Error
+	if !errors.As(err, &netErr) || !netErr.Timeout() {
+		return err
+	}
+	ws.stale.Add(1)
+	return fmt.Errorf("%w: nothing received for %s", ErrStale, ws.readTimeout())
+}
@@ -0,0 +1,93 @@
+package ticks
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrClosed is returned by operations on a client that was closed
+var ErrClosed = errors.New("websocket client closed")
+
+// ConnState is the lifecycle state of a WS client
+type ConnState int32
+
+const (
+	StateDisconnected ConnState = iota // Not connected yet, or the connection dropped
+	StateConnecting                    // Dialing, first connection or reconnect
+	StateConnected                     // Connected and reading messages
+	StateClosed                        // Closed, the client cannot be reused
+)
+
+// String returns the name of the state
+func (s ConnState) String() string {
+	switch s {
+	case StateDisconnected:
+		return "disconnected"
+	case StateConnecting:
+		return "connecting"
+	case StateConnected:
+		return "connected"
+	case StateClosed:
+		return "closed"
+	}
+	return "unknown"
+}
+
+// State returns the current lifecycle state
+func (ws *WS) State() ConnState {
+	return ConnState(ws.state.Load())
+}
+
+// Done returns a channel closed once Close has stopped every goroutine and closed
+// the data and error channels
+func (ws *WS) Done() <-chan struct{} {
+	return ws.done
+}
+
+// setState moves to a new state unless the client is closed, Closed is final
+func (ws *WS) setState(state ConnState) bool {
+	for {
+		current := ws.state.Load()
+		if ConnState(current) == StateClosed {
+			return false
+		}
+		if ws.state.CompareAndSwap(current, int32(state)) {
+			return true
+		}
+	}
+}
+
+// closed reports whether Close was called
+func (ws *WS) closed() bool {
+	return ws.ctx.Err() != nil
+}
+
+// goTracked runs f on a goroutine that Close waits for before closing the channels
+func (ws *WS) goTracked(f func()) {
+	ws.wg.Add(1)
+	go func() {
+		defer ws.wg.Done()
+		f()
+	}()
+}
+
+// reportError sends an error on the error channel unless the client is closing
+func (ws *WS) reportError(err error) {
+	select {
+	case ws.errChan <- err:
+	case <-ws.ctx.Done():
+	}
+}
+
+// sleep waits for d and reports false if the client was closed meanwhile
+func (ws *WS) sleep(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ws.ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,104 @@
+package ticks
+
+import (
+	"encoding/json"
+
+	"github.com/rs/zerolog"
+)
+
+// Logger receives the log events of the SDK. The default is a zerolog logger writing JSON
+// to stderr, implement Logger to route the events to another library, e.g., log/slog
+type Logger interface {
+	// Log records an event. Fields hold its context, such as "error" or "token", with
+	// the values decoded from JSON
+	Log(level zerolog.Level, msg string, fields map[string]any)
+}
+
+// zerologLogger is a Logger writing to a zerolog logger
+type zerologLogger struct {
+	logger zerolog.Logger
+}
+
+// NewZerologLogger returns a Logger writing to a zerolog logger. The SDK logs to it
+// directly, without the decoding of other Logger implementations
+func NewZerologLogger(logger zerolog.Logger) Logger {
+	return zerologLogger{logger: logger}
+}
+
+// Log writes the event to the zerolog logger
+func (l zerologLogger) Log(level zerolog.Level, msg string, fields map[string]any) {
+	l.logger.WithLevel(level).Fields(fields).Msg(msg)
+}
+
+// AsZerolog returns a zerolog logger whose events are passed to logger, the form in which
+// the SDK logs. A nil logger discards the events
+func AsZerolog(logger Logger) zerolog.Logger {
+	switch l := logger.(type) {
+	case nil:
+		return zerolog.Nop()
+	case zerologLogger:
+		return l.logger
+	}
+	return zerolog.New(eventWriter{logger: logger})
+}
+
+// eventWriter decodes the JSON events of a zerolog logger and passes them to a Logger
+type eventWriter struct {
+	logger Logger
+}
+
+// Write passes an event without level
+func (w eventWriter) Write(p []byte) (int, error) {
+	return w.WriteLevel(zerolog.NoLevel, p)
+}
+
+// WriteLevel passes an event at the given level
+func (w eventWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	var fields map[string]any
+	if err := json.Unmarshal(p, &fields); err != nil {
+		return 0, err
+	}
+	msg, _ := fields[zerolog.MessageFieldName].(string)
+	delete(fields, zerolog.MessageFieldName)
+	delete(fields, zerolog.LevelFieldName)
+	w.logger.Log(level, msg, fields)
+	return len(p), nil
+}
+
+// SetLogger replaces the logger of the client, which writes JSON to stderr by default.
+// Call it before Connect
+func (ws *WS) SetLogger(logger Logger) {
+	l := AsZerolog(logger)
+	ws.logger = &l
+}
+
+// SetLogLevel sets the minimum level of the events logged by the client, zerolog.Disabled
+// silences it. Heartbeats are logged at debug level. Call it before Connect
+func (ws *WS) SetLogLevel(level zerolog.Level) {
+	logger := ws.logger.Level(level)
+	ws.logger = &logger
+}
+
+// DisableLogging silences the client. Call it before Connect
+func (ws *WS) DisableLogging() {
+	ws.SetLogger(nil)
+}
+
+// SetLogger replaces the logger of the socket, which writes JSON to stderr by default.
+// Call it before Connect
+func (s *OrderSocket) SetLogger(logger Logger) {
+	l := AsZerolog(logger)
+	s.logger = &l
+}
+
+// SetLogLevel sets the minimum level of the events logged by the socket, zerolog.Disabled
+// silences it. Call it before Connect
+func (s *OrderSocket) SetLogLevel(level zerolog.Level) {
+	logger := s.logger.Level(level)
+	s.logger = &logger
+}
+
+// DisableLogging silences the socket. Call it before Connect
+func (s *OrderSocket) DisableLogging() {
+	s.SetLogger(nil)
+}
@@ -0,0 +1,150 @@
+package ticks
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// LastPrice is the latest known price of a token. Prices are in paise, as in TickData
+type LastPrice struct {
+	Token     int32     `json:"token"`
+	LTP       int32     `json:"ltp"`
+	Bid       int32     `json:"bid"`
+	BidQty    int64     `json:"bid_qty"`
+	Ask       int32     `json:"ask"`
+	AskQty    int64     `json:"ask_qty"`
+	Close     int32     `json:"close"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// LTPCache maintains the latest price and best bid and ask per token, so strategies can
+// look up current prices at any time without consuming the tick stream themselves.
+//
+// Feed it with Subscribe, which takes the ticks of its tokens off DataChan, or with Run or
+// Update alongside other consumers. Bid and ask are only streamed in full mode; ticks
+// without depth keep the last known bid and ask
+type LTPCache struct {
+	mu     sync.RWMutex
+	prices map[int32]LastPrice
+}
+
+// NewLTPCache creates an empty cache
+func NewLTPCache() *LTPCache {
+	return &LTPCache{prices: make(map[int32]LastPrice)}
+}
+
+// Subscribe subscribes to tokens and feeds the cache with their ticks, which are no longer
+// sent on DataChan or BatchChan (see SubscribeWithHandler). The returned function stops
+// feeding the cache without unsubscribing
+func (c *LTPCache) Subscribe(ws *WS, tokens []int, mode string) (func(), error) {
+	return ws.SubscribeWithHandler(tokens, mode, c.Update)
+}
+
+// Run feeds the cache with the ticks of in until it is closed or ctx is done
+func (c *LTPCache) Run(ctx context.Context, in <-chan TickData) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case tick, ok := <-in:
+			if !ok {
+				return nil
+			}
+			c.Update(tick)
+		}
+	}
+}
+
+// Update records a tick. Heartbeats and ticks without a price are ignored
+func (c *LTPCache) Update(tick TickData) {
+	if tick.Token < 0 || tick.LTP == 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	price := c.prices[tick.Token]
+	price.Token = tick.Token
+	price.LTP = tick.LTP
+	price.UpdatedAt = time.Now()
+	if tick.Close != 0 {
+		price.Close = tick.Close
+	}
+	if tick.MarketDepth.hasLevels() {
+		bid, ask := tick.MarketDepth.Bids[0], tick.MarketDepth.Asks[0]
+		price.Bid, price.BidQty = bid.Price, bid.Quantity
+		price.Ask, price.AskQty = ask.Price, ask.Quantity
+	}
+	c.prices[tick.Token] = price
+}
+
+// Get returns the latest price of a token
+func (c *LTPCache) Get(token int32) (LastPrice, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	price, ok := c.prices[token]
+	return price, ok
+}
+
+// LTP returns the last traded price of a token, in paise
+func (c *LTPCache) LTP(token int32) (int32, bool) {
+	price, ok := c.Get(token)
+	return price.LTP, ok
+}
+
+// Snapshot returns the latest prices of the given tokens, or of every cached token when
+// none are given. Tokens not in the cache are left out
+func (c *LTPCache) Snapshot(tokens ...int32) map[int32]LastPrice {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if len(tokens) == 0 {
+		snapshot := make(map[int32]LastPrice, len(c.prices))
+		for token, price := range c.prices {
+			snapshot[token] = price
+		}
+		return snapshot
+	}
+
+	snapshot := make(map[int32]LastPrice, len(tokens))
+	for _, token := range tokens {
+		if price, ok := c.prices[token]; ok {
+			snapshot[token] = price
+		}
+	}
+	return snapshot
+}
+
+// Tokens returns the cached tokens in ascending order
+func (c *LTPCache) Tokens() []int32 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	tokens := make([]int32, 0, len(c.prices))
+	for token := range c.prices {
+		tokens = append(tokens, token)
+	}
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i] < tokens[j] })
+	return tokens
+}
+
+// Stale returns the cached tokens not updated for longer than maxAge, e.g., to detect
+// tokens whose subscription was lost
+func (c *LTPCache) Stale(maxAge time.Duration) []int32 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	cutoff := time.Now().Add(-maxAge)
+	var stale []int32
+	for token, price := range c.prices {
+		if price.UpdatedAt.Before(cutoff) {
+			stale = append(stale, token)
+		}
+	}
+	sort.Slice(stale, func(i, j int) bool { return stale[i] < stale[j] })
+	return stale
+}
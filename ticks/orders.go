@@ -0,0 +1,409 @@
+package ticks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/rs/zerolog"
+)
+
+// ORDER_WSS_URL is the endpoint of the order update socket
+const ORDER_WSS_URL = "wss://wss.tiqs.trading/order"
+
+// DefaultOrderChanSize is the default capacity of the order update channel
+const DefaultOrderChanSize = 256
+
+// OrderUpdate is an order status event pushed by the order update socket.
+// Statuses are passed through as sent by the broker, use tiqs.ParseOrderStatus to normalize them
+type OrderUpdate struct {
+	OrderNo         string    `json:"orderNo"`
+	ExchangeOrderID string    `json:"exchangeOrderId"`
+	Status          string    `json:"status"`
+	ReportType      string    `json:"reportType"`
+	Exchange        string    `json:"exchange"`
+	Symbol          string    `json:"symbol"`
+	Token           int32     `json:"token"`
+	TransactionType string    `json:"transactionType"`
+	Product         string    `json:"product"`
+	OrderType       string    `json:"orderType"`
+	Quantity        int64     `json:"quantity"`
+	FilledQuantity  int64     `json:"filledQuantity"`
+	CancelQuantity  int64     `json:"cancelQuantity"`
+	Price           float64   `json:"price"`
+	TriggerPrice    float64   `json:"triggerPrice"`
+	AveragePrice    float64   `json:"averagePrice"`
+	FillID          string    `json:"fillId,omitempty"`
+	FillQuantity    int64     `json:"fillQuantity,omitempty"`
+	FillPrice       float64   `json:"fillPrice,omitempty"`
+	RejectReason    string    `json:"rejectReason,omitempty"`
+	Tags            string    `json:"tags,omitempty"`
+	Time            time.Time `json:"time"`
+}
+
+// PendingQuantity returns the quantity still open on the order
+func (u OrderUpdate) PendingQuantity() int64 {
+	return max(u.Quantity-u.FilledQuantity-u.CancelQuantity, 0)
+}
+
+// IsFill reports whether the update carries a trade
+func (u OrderUpdate) IsFill() bool {
+	return u.FillQuantity > 0
+}
+
+// orderUpdateWire is an order event as sent on the wire, numbers may come as strings
+type orderUpdateWire struct {
+	OrderNo         string     `json:"id"`
+	ExchangeOrderID string     `json:"exchangeOrderID"`
+	Status          string     `json:"orderStatus"`
+	ReportType      string     `json:"reportType"`
+	Exchange        string     `json:"exchange"`
+	Symbol          string     `json:"symbol"`
+	Token           wireNumber `json:"token"`
+	TransactionType string     `json:"transactionType"`
+	Product         string     `json:"product"`
+	OrderType       string     `json:"order"`
+	Quantity        wireNumber `json:"quantity"`
+	FillShares      wireNumber `json:"fillShares"`
+	CancelQuantity  wireNumber `json:"cancelQuantity"`
+	Price           wireNumber `json:"price"`
+	TriggerPrice    wireNumber `json:"orderTriggerPrice"`
+	AveragePrice    wireNumber `json:"averagePrice"`
+	FillID          string     `json:"fillId"`
+	FillQuantity    wireNumber `json:"fillQuantity"`
+	FillPrice       wireNumber `json:"fillPrice"`
+	RejectReason    string     `json:"rejectReason"`
+	Remarks         string     `json:"remarks"`
+	UpdateTime      wireNumber `json:"exchangeUpdateTime"`
+	Type            string     `json:"type"`
+}
+
+// wireNumber accepts a JSON number or a string holding one
+type wireNumber string
+
+func (n *wireNumber) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*n = wireNumber(strings.TrimSpace(s))
+		return nil
+	}
+	if string(data) == "null" {
+		*n = ""
+		return nil
+	}
+	*n = wireNumber(data)
+	return nil
+}
+
+func (n wireNumber) int() int64 {
+	v, err := strconv.ParseInt(string(n), 10, 64)
+	if err != nil {
+		return int64(n.float())
+	}
+	return v
+}
+
+func (n wireNumber) float() float64 {
+	v, _ := strconv.ParseFloat(string(n), 64)
+	return v
+}
+
+// time parses epoch seconds or milliseconds, or one of the broker's date layouts
+func (n wireNumber) time() time.Time {
+	s := string(n)
+	if s == "" {
+		return time.Time{}
+	}
+	if v, err := strconv.ParseInt(s, 10, 64); err == nil {
+		if v > 1e12 {
+			return time.UnixMilli(v)
+		}
+		return time.Unix(v, 0)
+	}
+	for _, layout := range []string{time.RFC3339, "02-01-2006 15:04:05", "2006-01-02 15:04:05", "15:04:05 02-01-2006"} {
+		if t, err := time.ParseInLocation(layout, s, istLocation); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// istLocation is the exchange time zone, used for timestamps without an offset
+var istLocation = time.FixedZone("IST", 5*3600+1800)
+
+func (w orderUpdateWire) update() OrderUpdate {
+	return OrderUpdate{
+		OrderNo:         w.OrderNo,
+		ExchangeOrderID: w.ExchangeOrderID,
+		Status:          w.Status,
+		ReportType:      w.ReportType,
+		Exchange:        w.Exchange,
+		Symbol:          w.Symbol,
+		Token:           int32(w.Token.int()),
+		TransactionType: w.TransactionType,
+		Product:         w.Product,
+		OrderType:       w.OrderType,
+		Quantity:        w.Quantity.int(),
+		FilledQuantity:  w.FillShares.int(),
+		CancelQuantity:  w.CancelQuantity.int(),
+		Price:           w.Price.float(),
+		TriggerPrice:    w.TriggerPrice.float(),
+		AveragePrice:    w.AveragePrice.float(),
+		FillID:          w.FillID,
+		FillQuantity:    w.FillQuantity.int(),
+		FillPrice:       w.FillPrice.float(),
+		RejectReason:    w.RejectReason,
+		Tags:            w.Remarks,
+		Time:            w.UpdateTime.time(),
+	}
+}
+
+// ParseOrderUpdates decodes an order socket message holding one event or an array of events
+func ParseOrderUpdates(message []byte) ([]OrderUpdate, error) {
+	message = bytes.TrimSpace(message)
+	var wire []orderUpdateWire
+	if len(message) > 0 && message[0] == '[' {
+		if err := json.Unmarshal(message, &wire); err != nil {
+			return nil, fmt.Errorf("error decoding order updates: %w", err)
+		}
+	} else {
+		var single orderUpdateWire
+		if err := json.Unmarshal(message, &single); err != nil {
+			return nil, fmt.Errorf("error decoding order update: %w", err)
+		}
+		wire = append(wire, single)
+	}
+
+	updates := make([]OrderUpdate, 0, len(wire))
+	for _, w := range wire {
+		// Acknowledgements and heartbeats carry no order
+		if w.OrderNo == "" {
+			continue
+		}
+		updates = append(updates, w.update())
+	}
+	return updates, nil
+}
+
+// OrderSocket streams order status events, so fills can be tracked without polling
+type OrderSocket struct {
+	AppID      string
+	Token      string
+	URL        string
+	RetryDelay time.Duration
+	MaxRetries int
+
+	// Optional connection lifecycle hooks
+	OnConnect    func()
+	OnDisconnect func(error)
+
+	// Optional source of credentials queried before every dial, overriding AppID and Token
+	Credentials CredentialsProvider
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	logger  *zerolog.Logger
+	updates chan OrderUpdate
+	errChan chan error
+	mu      sync.Mutex
+	conn    *websocket.Conn
+	started bool
+	done    chan struct{}
+}
+
+// NewOrderSocket creates a new order update socket client
+func NewOrderSocket(appId, token string) *OrderSocket {
+	ctx, cancel := context.WithCancel(context.Background())
+	logger := zerolog.New(os.Stderr).With().Timestamp().Logger()
+
+	return &OrderSocket{
+		AppID:      appId,
+		Token:      token,
+		URL:        ORDER_WSS_URL,
+		RetryDelay: 5 * time.Second,
+		MaxRetries: 25,
+
+		ctx:     ctx,
+		cancel:  cancel,
+		logger:  &logger,
+		updates: make(chan OrderUpdate, DefaultOrderChanSize),
+		errChan: make(chan error, 100),
+		done:    make(chan struct{}),
+	}
+}
+
+// Connect dials the order socket, subscribes to order events and starts delivering them.
+// The socket reconnects on its own after a disconnect until Close is called
+func (s *OrderSocket) Connect() error {
+	s.mu.Lock()
+	if s.started {
+		s.mu.Unlock()
+		return fmt.Errorf("order socket already connected")
+	}
+	s.started = true
+	s.mu.Unlock()
+
+	if err := s.dial(); err != nil {
+		close(s.done)
+		close(s.updates)
+		close(s.errChan)
+		return err
+	}
+	go s.run()
+	return nil
+}
+
+// GetUpdateChannel returns the channel of order updates, closed after Close
+func (s *OrderSocket) GetUpdateChannel() <-chan OrderUpdate {
+	return s.updates
+}
+
+// GetErrorChannel returns the channel for receiving errors, closed after Close
+func (s *OrderSocket) GetErrorChannel() <-chan error {
+	return s.errChan
+}
+
+// Close stops the socket and waits until the update channel is closed
+func (s *OrderSocket) Close() error {
+	s.cancel()
+
+	s.mu.Lock()
+	conn, started := s.conn, s.started
+	s.mu.Unlock()
+
+	var err error
+	if conn != nil {
+		s.logger.Info().Msg("Closing order socket")
+		err = conn.Close()
+	}
+	if started {
+		<-s.done
+	}
+	return err
+}
+
+// dial connects with retries and sends the subscription message
+func (s *OrderSocket) dial() error {
+	var err error
+	for attempt := 1; attempt <= s.MaxRetries; attempt++ {
+		if s.ctx.Err() != nil {
+			return s.ctx.Err()
+		}
+		s.logger.Info().Msgf("Attempting to connect to order socket (attempt %d/%d)", attempt, s.MaxRetries)
+
+		var url string
+		var conn *websocket.Conn
+		url, err = dialURL(s.ctx, s.URL, s.Credentials, s.AppID, s.Token)
+		if err == nil {
+			conn, _, err = websocket.DefaultDialer.DialContext(s.ctx, url, nil)
+		}
+		if err == nil {
+			err = conn.WriteJSON(map[string]string{"code": "sub", "mode": "orders"})
+		}
+		if err == nil {
+			s.mu.Lock()
+			s.conn = conn
+			s.mu.Unlock()
+
+			s.logger.Info().Msg("Connected to order socket")
+			if s.OnConnect != nil {
+				s.OnConnect()
+			}
+			return nil
+		}
+		if conn != nil {
+			conn.Close()
+		}
+
+		s.logger.Error().Err(err).Msgf("Failed to connect to order socket. Retrying in %s...", s.RetryDelay)
+		select {
+		case <-s.ctx.Done():
+			return s.ctx.Err()
+		case <-time.After(s.RetryDelay):
+		}
+	}
+	return fmt.Errorf("failed to connect after %d attempts: %w", s.MaxRetries, err)
+}
+
+// run reads messages until Close, reconnecting after read errors. It is the only sender
+// on the channels, so it closes them once it returns
+func (s *OrderSocket) run() {
+	defer func() {
+		close(s.updates)
+		close(s.errChan)
+		close(s.done)
+	}()
+
+	for {
+		s.read()
+		if s.ctx.Err() != nil {
+			return
+		}
+		if err := s.dial(); err != nil {
+			if s.ctx.Err() == nil {
+				s.report(fmt.Errorf("order socket reconnection failed: %w", err))
+			}
+			return
+		}
+	}
+}
+
+// read delivers updates from the current connection until it fails
+func (s *OrderSocket) read() {
+	s.mu.Lock()
+	conn := s.conn
+	s.mu.Unlock()
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			conn.Close()
+			if s.ctx.Err() != nil {
+				return
+			}
+			s.logger.Error().Err(err).Msg("Error reading order socket message")
+			s.report(err)
+			if s.OnDisconnect != nil {
+				s.OnDisconnect(err)
+			}
+			return
+		}
+
+		// Heartbeats are a single byte
+		if len(message) <= 1 {
+			continue
+		}
+
+		updates, err := ParseOrderUpdates(message)
+		if err != nil {
+			s.logger.Warn().Err(err).Msg("Skipping malformed order update")
+			continue
+		}
+		for _, u := range updates {
+			// Order updates must not be dropped, block until delivered or closed
+			select {
+			case s.updates <- u:
+			case <-s.ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// report sends an error without blocking
+func (s *OrderSocket) report(err error) {
+	select {
+	case s.errChan <- err:
+	default:
+	}
+}
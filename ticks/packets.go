@@ -0,0 +1,161 @@
+package ticks
+
+import "fmt"
+
+// ModeDepth20 subscribes to the 20-level market depth of tokens, see Depth20Tick
+const ModeDepth20 = "depth20"
+
+// Lengths of the index and 20-level depth packets, see testdata/frames/README.md
+const (
+	IndexPacketLength   = 32
+	Depth20PacketLength = 572
+)
+
+// Depth20Levels is the number of bid and ask levels of a 20-level depth packet
+const Depth20Levels = 20
+
+// DefaultDepth20ChanSize is the capacity of Depth20Chan
+const DefaultDepth20ChanSize = 64
+
+// PacketType identifies the layout of a binary packet
+type PacketType string
+
+const (
+	PacketLTP     PacketType = "LTP"
+	PacketQuote   PacketType = "QUOTE"
+	PacketFull    PacketType = "FULL"
+	PacketIndex   PacketType = "INDEX"
+	PacketDepth20 PacketType = "DEPTH20"
+)
+
+// PacketTypeOf returns the layout of a packet, told apart by its length. Packets longer
+// than a full packet without a layout of their own, such as packets carrying Greeks, are
+// reported as full packets
+func PacketTypeOf(data []byte) (PacketType, bool) {
+	switch n := len(data); {
+	case n == LTPPacketLength:
+		return PacketLTP, true
+	case n == IndexPacketLength:
+		return PacketIndex, true
+	case n == QuotePacketLength:
+		return PacketQuote, true
+	case n == Depth20PacketLength:
+		return PacketDepth20, true
+	case n >= FullPacketLength:
+		return PacketFull, true
+	}
+	return "", false
+}
+
+// IndexTick is the market data of an index, which has no depth, volume or open interest.
+// Prices are in paise, as in TickData
+type IndexTick struct {
+	Token     int32 `json:"token"`
+	LTP       int32 `json:"ltp"`
+	High      int32 `json:"high"`
+	Low       int32 `json:"low"`
+	Open      int32 `json:"open"`
+	Close     int32 `json:"close"`
+	NetChange int32 `json:"net_change"` // Change from the previous close, in paise
+	Time      int32 `json:"time"`       // Exchange timestamp in Unix seconds
+}
+
+// ParseIndexTick decodes an index packet
+func ParseIndexTick(data []byte) (IndexTick, error) {
+	if len(data) != IndexPacketLength {
+		return IndexTick{}, fmt.Errorf("invalid index packet length: %d", len(data))
+	}
+	return IndexTick{
+		Token:     int32At(data, 0),
+		LTP:       int32At(data, 4),
+		High:      int32At(data, 8),
+		Low:       int32At(data, 12),
+		Open:      int32At(data, 16),
+		Close:     int32At(data, 20),
+		NetChange: int32At(data, 24),
+		Time:      int32At(data, 28),
+	}, nil
+}
+
+// TickData returns the index tick as a TickData, as delivered on DataChan. NetChange is
+// the percent change and NetChangeIndicator its sign, as in LTP packets
+func (t IndexTick) TickData() TickData {
+	tick := TickData{
+		Token: t.Token,
+		LTP:   t.LTP,
+		High:  t.High,
+		Low:   t.Low,
+		Open:  t.Open,
+		Close: t.Close,
+		LTT:   t.Time,
+		Time:  t.Time,
+	}
+	setNetChange(&tick)
+	return tick
+}
+
+// Depth20Tick is the 20-level market depth of a token, streamed in ModeDepth20. Prices
+// are in paise, as in TickData
+type Depth20Tick struct {
+	Token int32                     `json:"token"`
+	LTP   int32                     `json:"ltp"`
+	Time  int32                     `json:"time"` // Exchange timestamp in Unix seconds
+	Bids  [Depth20Levels]DepthLevel `json:"bids"`
+	Asks  [Depth20Levels]DepthLevel `json:"asks"`
+}
+
+// ParseDepth20 decodes a 20-level depth packet
+func ParseDepth20(data []byte) (Depth20Tick, error) {
+	var depth Depth20Tick
+	err := ParseDepth20Into(data, &depth)
+	return depth, err
+}
+
+// ParseDepth20Into decodes a 20-level depth packet into depth, overwriting every field,
+// like ParseTickInto
+func ParseDepth20Into(data []byte, depth *Depth20Tick) error {
+	if len(data) != Depth20PacketLength {
+		*depth = Depth20Tick{}
+		return fmt.Errorf("invalid depth20 packet length: %d", len(data))
+	}
+
+	depth.Token = int32At(data, 0)
+	depth.LTP = int32At(data, 4)
+	depth.Time = int32At(data, 8)
+
+	// 20 bids followed by 20 asks
+	offset := 12
+	for i := range depth.Bids {
+		depth.Bids[i] = depthLevelAt(data, offset)
+		offset += depthLevelLength
+	}
+	for i := range depth.Asks {
+		depth.Asks[i] = depthLevelAt(data, offset)
+		offset += depthLevelLength
+	}
+	return nil
+}
+
+// TickData returns the depth tick as a TickData with the 5 best levels, as delivered on
+// DataChan
+func (d *Depth20Tick) TickData() TickData {
+	tick := TickData{Token: d.Token, LTP: d.LTP, LTT: d.Time, Time: d.Time}
+	copy(tick.MarketDepth.Bids[:], d.Bids[:])
+	copy(tick.MarketDepth.Asks[:], d.Asks[:])
+	return tick
+}
+
+// setNetChange sets the percent change of a tick from its close and its sign
+func setNetChange(tick *TickData) {
+	if tick.Close != 0 {
+		tick.NetChange = int32((float64(tick.LTP-tick.Close) / float64(tick.Close)) * 100)
+	}
+
+	if tick.LTP > tick.Close {
+		tick.NetChangeIndicator = 43 // '+'
+	} else if tick.LTP < tick.Close {
+		tick.NetChangeIndicator = 45 // '-'
+	} else {
+		tick.NetChangeIndicator = 32 // ' '
+	}
+}
@@ -0,0 +1,161 @@
+package ticks
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Lengths of the binary packets, see testdata/frames/README.md for their layout and
+// packets.go for the index and 20-level depth packets
+const (
+	LTPPacketLength   = 17
+	QuotePacketLength = 81
+	FullPacketLength  = 229
+)
+
+// depthLevelLength is the length of one level of the market depth in a full packet
+const depthLevelLength = 14
+
+// ParseTick decodes a binary tick packet as received on the WebSocket. Index packets are
+// decoded with their OHLC, and 20-level depth packets with their 5 best levels, see
+// ParseIndexTick and ParseDepth20 for their full decoding
+func ParseTick(data []byte) (TickData, error) {
+	var tick TickData
+	err := ParseTickInto(data, &tick)
+	return tick, err
+}
+
+// ParseTickInto decodes a binary tick packet into tick, overwriting every field, so a
+// consumer decoding frames in a loop can reuse one TickData instead of allocating.
+//
+// Fields are read straight from the packet, and decoding allocates nothing unless a
+// Greeks layout is registered for the packet's length
+func ParseTickInto(data []byte, tick *TickData) error {
+	*tick = TickData{}
+
+	if len(data) < LTPPacketLength {
+		return fmt.Errorf("invalid data length: %d", len(data))
+	}
+
+	// Index and 20-level depth packets have layouts of their own
+	switch len(data) {
+	case IndexPacketLength:
+		index, err := ParseIndexTick(data)
+		*tick = index.TickData()
+		return err
+	case Depth20PacketLength:
+		var depth Depth20Tick
+		err := ParseDepth20Into(data, &depth)
+		*tick = depth.TickData()
+		return err
+	}
+
+	// Parse basic fields
+	tick.Token = int32At(data, 0)
+	tick.LTP = int32At(data, 4)
+
+	if len(data) == LTPPacketLength {
+		tick.Close = int32At(data, 13)
+		setNetChange(tick)
+	}
+
+	if len(data) >= QuotePacketLength {
+		tick.AvgPrice = int32At(data, 17)
+		tick.TotalBuyQty = int64At(data, 21)
+		tick.TotalSellQty = int64At(data, 29)
+		tick.Open = int32At(data, 37)
+		tick.High = int32At(data, 41)
+		tick.Close = int32At(data, 45)
+		tick.Low = int32At(data, 49)
+		tick.Volume = int64At(data, 53)
+		tick.LTT = int32At(data, 61)
+		tick.Time = int32At(data, 65)
+		tick.OI = int32At(data, 69)
+		tick.OIDayHigh = int32At(data, 73)
+		tick.OIDayLow = int32At(data, 77)
+	}
+
+	if len(data) >= FullPacketLength {
+		tick.LowerLimit = int32At(data, 81)
+		tick.UpperLimit = int32At(data, 85)
+
+		// Parse market depth, 5 bids followed by 5 asks
+		offset := 89
+		for i := range tick.MarketDepth.Bids {
+			tick.MarketDepth.Bids[i] = depthLevelAt(data, offset)
+			offset += depthLevelLength
+		}
+		for i := range tick.MarketDepth.Asks {
+			tick.MarketDepth.Asks[i] = depthLevelAt(data, offset)
+			offset += depthLevelLength
+		}
+	}
+
+	// Extended packets may carry Greeks, see RegisterGreeksLayout
+	tick.Greeks = parseGreeks(data)
+
+	return nil
+}
+
+// int32At reads a big endian int32 at offset
+func int32At(data []byte, offset int) int32 {
+	return int32(binary.BigEndian.Uint32(data[offset : offset+4]))
+}
+
+// int64At reads a big endian int64 at offset
+func int64At(data []byte, offset int) int64 {
+	return int64(binary.BigEndian.Uint64(data[offset : offset+8]))
+}
+
+// depthLevelAt reads a level of the market depth at offset: quantity int64, price int32
+// and orders int16
+func depthLevelAt(data []byte, offset int) DepthLevel {
+	return DepthLevel{
+		Quantity: int64At(data, offset),
+		Price:    int32At(data, offset+8),
+		Orders:   int16(binary.BigEndian.Uint16(data[offset+12 : offset+14])),
+	}
+}
+
+// frame is a message read from the connection into a pooled buffer
+type frame struct {
+	data []byte
+}
+
+// framePool recycles the buffers of binary frames once they are parsed, so reading does
+// not allocate a buffer per message
+var framePool = sync.Pool{
+	New: func() any { return &frame{data: make([]byte, 0, FullPacketLength)} },
+}
+
+// readFrame reads the next message of a connection into a pooled frame, which must be
+// handed back with release once the message is no longer used
+func readFrame(r io.Reader) (*frame, error) {
+	f := framePool.Get().(*frame)
+	f.data = f.data[:0]
+	for {
+		if len(f.data) == cap(f.data) {
+			f.data = append(f.data, 0)[:len(f.data)]
+		}
+		n, err := r.Read(f.data[len(f.data):cap(f.data)])
+		f.data = f.data[:len(f.data)+n]
+		if err == io.EOF {
+			return f, nil
+		}
+		if err != nil {
+			f.release()
+			return nil, err
+		}
+	}
+}
+
+// release hands the frame back to the pool
+func (f *frame) release() {
+	// Keep unusually large buffers out of the pool
+	if cap(f.data) > 64<<10 {
+		return
+	}
+	framePool.Put(f)
+}
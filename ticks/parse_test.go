@@ -0,0 +1,98 @@
+package ticks
+
+import (
+	"encoding/binary"
+	"os"
+	"testing"
+)
+
+// readFrameFile reads a frame of the corpus in testdata/frames
+func readFrameFile(t testing.TB, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile("testdata/frames/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestFrameCorpus(t *testing.T) {
+	results, err := CheckFrames(os.DirFS("testdata/frames"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) == 0 {
+		t.Fatal("no frames in testdata/frames")
+	}
+	for _, r := range results {
+		if r.Err != nil {
+			t.Errorf("%s: %v", r.File, r.Err)
+		}
+	}
+}
+
+// TestParseTickInt64Fields checks that the 8-byte quantity fields are decoded whole. The
+// original parser read them as an int32 of their upper half, which is zero for any
+// quantity below 2^32, so these fields were always zero
+func TestParseTickInt64Fields(t *testing.T) {
+	frame := readFrameFile(t, "full.bin")
+
+	tick, err := ParseTick(frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tick.TotalBuyQty != 234567 || tick.TotalSellQty != 345678 || tick.Volume != 5123456 {
+		t.Errorf("buy, sell, volume = %d, %d, %d, want 234567, 345678, 5123456",
+			tick.TotalBuyQty, tick.TotalSellQty, tick.Volume)
+	}
+	if q := tick.MarketDepth.Bids[0].Quantity; q != 100 {
+		t.Errorf("best bid quantity = %d, want 100", q)
+	}
+
+	// Values beyond the range of an int32, which only an int64 read decodes
+	large := append([]byte(nil), frame...)
+	binary.BigEndian.PutUint64(large[21:29], 1<<32+5)
+	binary.BigEndian.PutUint64(large[53:61], 3_000_000_000)
+	binary.BigEndian.PutUint64(large[89:97], 1<<40)
+	if tick, err = ParseTick(large); err != nil {
+		t.Fatal(err)
+	}
+	if tick.TotalBuyQty != 1<<32+5 || tick.Volume != 3_000_000_000 || tick.MarketDepth.Bids[0].Quantity != 1<<40 {
+		t.Errorf("buy, volume, best bid quantity = %d, %d, %d, want %d, %d, %d",
+			tick.TotalBuyQty, tick.Volume, tick.MarketDepth.Bids[0].Quantity, int64(1<<32+5), 3_000_000_000, int64(1<<40))
+	}
+}
+
+// benchFrames are the packets of every mode the parser benchmarks decode
+var benchFrames = []string{"ltp", "quote", "full", "index", "depth20"}
+
+func BenchmarkParseTick(b *testing.B) {
+	for _, name := range benchFrames {
+		frame := readFrameFile(b, name+".bin")
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(frame)))
+			for range b.N {
+				if _, err := ParseTick(frame); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkParseTickInto(b *testing.B) {
+	for _, name := range benchFrames {
+		frame := readFrameFile(b, name+".bin")
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(frame)))
+			var tick TickData
+			for range b.N {
+				if err := ParseTickInto(frame, &tick); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package ticks
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// VolumeBin represents the traded volume within a single price bin
+type VolumeBin struct {
+	Price  int32 `json:"price"` // lower bound of the bin, in paise
+	Volume int64 `json:"volume"`
+}
+
+// VolumeProfile builds an intraday price → traded volume histogram per token.
+//
+// Traded volume is taken from the change in cumulative volume between consecutive
+// full-mode ticks, which stays correct when the feed conflates trades. When a tick
+// carries no cumulative volume, its LTQ is used instead.
+type VolumeProfile struct {
+	BinSize int32 // bin width in paise
+
+	mu       sync.RWMutex
+	profiles map[int32]*tokenProfile
+}
+
+type tokenProfile struct {
+	bins       map[int32]int64
+	lastVolume int64
+}
+
+// NewVolumeProfile creates a volume profile with the given bin width in paise
+func NewVolumeProfile(binSize int32) *VolumeProfile {
+	if binSize <= 0 {
+		binSize = 1
+	}
+	return &VolumeProfile{
+		BinSize:  binSize,
+		profiles: make(map[int32]*tokenProfile),
+	}
+}
+
+// Update adds the volume traded since the previous tick of the token to the bin of its LTP
+func (vp *VolumeProfile) Update(tick TickData) {
+	if tick.Token < 0 || tick.LTP <= 0 {
+		return
+	}
+
+	vp.mu.Lock()
+	defer vp.mu.Unlock()
+
+	profile, ok := vp.profiles[tick.Token]
+	if !ok {
+		profile = &tokenProfile{bins: make(map[int32]int64)}
+		vp.profiles[tick.Token] = profile
+	}
+
+	var traded int64
+	switch {
+	case tick.Volume > 0:
+		// The first tick only establishes the baseline of the cumulative volume
+		if profile.lastVolume > 0 && tick.Volume > profile.lastVolume {
+			traded = tick.Volume - profile.lastVolume
+		}
+		profile.lastVolume = tick.Volume
+	case tick.LTQ > 0:
+		traded = int64(tick.LTQ)
+	}
+
+	if traded > 0 {
+		profile.bins[vp.binOf(tick.LTP)] += traded
+	}
+}
+
+// Profile returns the histogram of a token ordered by price
+func (vp *VolumeProfile) Profile(token int32) []VolumeBin {
+	vp.mu.RLock()
+	defer vp.mu.RUnlock()
+
+	profile, ok := vp.profiles[token]
+	if !ok {
+		return nil
+	}
+
+	bins := make([]VolumeBin, 0, len(profile.bins))
+	for price, volume := range profile.bins {
+		bins = append(bins, VolumeBin{Price: price, Volume: volume})
+	}
+	sort.Slice(bins, func(i, j int) bool { return bins[i].Price < bins[j].Price })
+	return bins
+}
+
+// PointOfControl returns the bin with the highest traded volume for a token
+func (vp *VolumeProfile) PointOfControl(token int32) (VolumeBin, bool) {
+	var poc VolumeBin
+	found := false
+	for _, bin := range vp.Profile(token) {
+		if !found || bin.Volume > poc.Volume {
+			poc = bin
+			found = true
+		}
+	}
+	return poc, found
+}
+
+// Reset clears the profile of every token, e.g. at the start of a new session
+func (vp *VolumeProfile) Reset() {
+	vp.mu.Lock()
+	vp.profiles = make(map[int32]*tokenProfile)
+	vp.mu.Unlock()
+}
+
+// WriteCSV exports the histogram of the given tokens as token,price,volume rows.
+// When no tokens are given every tracked token is exported.
+func (vp *VolumeProfile) WriteCSV(w io.Writer, tokens ...int32) error {
+	if len(tokens) == 0 {
+		vp.mu.RLock()
+		for token := range vp.profiles {
+			tokens = append(tokens, token)
+		}
+		vp.mu.RUnlock()
+		sort.Slice(tokens, func(i, j int) bool { return tokens[i] < tokens[j] })
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"token", "price", "volume"}); err != nil {
+		return fmt.Errorf("error writing volume profile: %w", err)
+	}
+
+	for _, token := range tokens {
+		for _, bin := range vp.Profile(token) {
+			record := []string{
+				strconv.Itoa(int(token)),
+				strconv.Itoa(int(bin.Price)),
+				strconv.FormatInt(bin.Volume, 10),
+			}
+			if err := writer.Write(record); err != nil {
+				return fmt.Errorf("error writing volume profile: %w", err)
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// binOf returns the lower bound of the bin containing price
+func (vp *VolumeProfile) binOf(price int32) int32 {
+	return price - price%vp.BinSize
+}
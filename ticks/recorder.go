@@ -0,0 +1,302 @@
+package ticks
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// recordingMagic starts every recording, see Recorder for the layout
+var recordingMagic = []byte("TIQSREC1")
+
+// maxRecordedFrame bounds the length of a recorded frame, longer lengths mean a corrupt file
+const maxRecordedFrame = 1 << 20
+
+// Recorder appends the binary frames received by a WS to a recording, with the time they
+// were received, for replay with a Replayer or offline analysis.
+//
+// A recording starts with the 8 bytes "TIQSREC1" followed by one record per frame: the
+// receive time in Unix nanoseconds as a big-endian int64, the frame length as a big-endian
+// uint32 and the frame itself. Frames are recorded as received, before parsing, so a
+// replay goes through the same decoding as live data. Heartbeats are not recorded.
+//
+// Attach a recorder to WS.Recorder before Connect. Writes are buffered; Close the
+// recorder after the WS to flush them
+type Recorder struct {
+	mu     sync.Mutex
+	w      *bufio.Writer
+	closer io.Closer
+	header []byte
+	frames int64
+	err    error
+}
+
+// NewRecorder creates a recorder writing a new recording to w
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{w: bufio.NewWriter(w), header: recordingMagic}
+}
+
+// OpenRecorder opens the recording at path for appending, creating it if needed
+func OpenRecorder(path string) (*Recorder, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("error opening recording: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("error opening recording: %w", err)
+	}
+
+	r := &Recorder{w: bufio.NewWriter(file), closer: file}
+	if info.Size() == 0 {
+		r.header = recordingMagic
+	}
+	return r, nil
+}
+
+// Record appends a frame received at the given time. Once a write fails, the recorder
+// stops and returns that error from every call
+func (r *Recorder) Record(at time.Time, frame []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err != nil {
+		return r.err
+	}
+	if r.header != nil {
+		if _, r.err = r.w.Write(r.header); r.err != nil {
+			return r.err
+		}
+		r.header = nil
+	}
+
+	var prefix [12]byte
+	binary.BigEndian.PutUint64(prefix[0:8], uint64(at.UnixNano()))
+	binary.BigEndian.PutUint32(prefix[8:12], uint32(len(frame)))
+	if _, r.err = r.w.Write(prefix[:]); r.err == nil {
+		_, r.err = r.w.Write(frame)
+	}
+	if r.err != nil {
+		r.err = fmt.Errorf("error writing recording: %w", r.err)
+		return r.err
+	}
+	r.frames++
+	return nil
+}
+
+// Frames returns the number of frames recorded
+func (r *Recorder) Frames() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.frames
+}
+
+// Flush writes the buffered frames
+func (r *Recorder) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err != nil {
+		return r.err
+	}
+	if err := r.w.Flush(); err != nil {
+		r.err = fmt.Errorf("error writing recording: %w", err)
+	}
+	return r.err
+}
+
+// Close flushes the recording and closes its file if it was opened with OpenRecorder
+func (r *Recorder) Close() error {
+	err := r.Flush()
+	if r.closer != nil {
+		if cerr := r.closer.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// RecordedFrame is a frame read from a recording
+type RecordedFrame struct {
+	Time time.Time // When the frame was received
+	Data []byte    // The binary frame
+}
+
+// Tick decodes the frame with ParseTick
+func (f RecordedFrame) Tick() (TickData, error) {
+	return ParseTick(f.Data)
+}
+
+// Replayer reads a recording made by a Recorder and plays it back through a WS, so that
+// strategies consume recorded data through DataChan, Depth20Chan, GetBatchChannel and
+// the handlers of SubscribeWithHandler exactly as they consume live data
+type Replayer struct {
+	// Playback speed relative to the recording: 1 replays in real time, 10 ten times
+	// faster. Zero or less replays as fast as the frames are delivered
+	Speed float64
+
+	r      *bufio.Reader
+	closer io.Closer
+	header bool
+	buf    []byte
+}
+
+// NewReplayer creates a replayer reading a recording from r
+func NewReplayer(r io.Reader) *Replayer {
+	return &Replayer{Speed: 1, r: bufio.NewReader(r)}
+}
+
+// OpenReplayer opens the recording at path, close the replayer when done
+func OpenReplayer(path string) (*Replayer, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening recording: %w", err)
+	}
+	p := NewReplayer(file)
+	p.closer = file
+	return p, nil
+}
+
+// Close closes the recording if it was opened with OpenReplayer
+func (p *Replayer) Close() error {
+	if p.closer != nil {
+		return p.closer.Close()
+	}
+	return nil
+}
+
+// Next reads the next frame of the recording, it returns io.EOF at the end. The data of
+// the frame is only valid until the next call
+func (p *Replayer) Next() (RecordedFrame, error) {
+	if !p.header {
+		magic := make([]byte, len(recordingMagic))
+		if _, err := io.ReadFull(p.r, magic); err != nil {
+			if err == io.ErrUnexpectedEOF {
+				err = fmt.Errorf("invalid recording: %w", err)
+			}
+			return RecordedFrame{}, err
+		}
+		if !bytes.Equal(magic, recordingMagic) {
+			return RecordedFrame{}, fmt.Errorf("invalid recording: bad header %q", magic)
+		}
+		p.header = true
+	}
+
+	var prefix [12]byte
+	if _, err := io.ReadFull(p.r, prefix[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			err = fmt.Errorf("truncated recording: %w", err)
+		}
+		return RecordedFrame{}, err
+	}
+	at := int64(binary.BigEndian.Uint64(prefix[0:8]))
+	n := binary.BigEndian.Uint32(prefix[8:12])
+	if n > maxRecordedFrame {
+		return RecordedFrame{}, fmt.Errorf("invalid recording: frame of %d bytes", n)
+	}
+
+	if cap(p.buf) < int(n) {
+		p.buf = make([]byte, n)
+	}
+	p.buf = p.buf[:n]
+	if _, err := io.ReadFull(p.r, p.buf); err != nil {
+		return RecordedFrame{}, fmt.Errorf("truncated recording: %w", noEOF(err))
+	}
+	return RecordedFrame{Time: time.Unix(0, at), Data: p.buf}, nil
+}
+
+// Run plays the recording through ws until its end, ctx is cancelled or ws is closed.
+//
+// The frames are parsed and delivered as if ws had received them, so the WS needs no
+// connection; call Tune and register handlers before Run, and Close the WS afterwards to
+// close its channels. Every recorded frame is delivered, whatever the subscriptions of ws.
+// Frames are paced by their recorded receive times scaled by Speed, and delivered with
+// the same non-blocking sends as live data, so a slow consumer drops ticks as it would live
+func (p *Replayer) Run(ctx context.Context, ws *WS) error {
+	if err := ws.startReplay(); err != nil {
+		return err
+	}
+	defer ws.wg.Done()
+
+	var first time.Time
+	start := time.Now()
+	for {
+		recorded, err := p.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var wait time.Duration
+		if p.Speed > 0 {
+			if first.IsZero() {
+				first = recorded.Time
+			}
+			wait = time.Until(start.Add(time.Duration(float64(recorded.Time.Sub(first)) / p.Speed)))
+		}
+		if err := ws.waitReplay(ctx, wait); err != nil {
+			return err
+		}
+
+		f := framePool.Get().(*frame)
+		f.data = append(f.data[:0], recorded.Data...)
+		ws.dispatchFrame(f)
+	}
+}
+
+// startReplay starts the parsing workers and batcher of a WS fed by a Replayer, and
+// registers the replay with the goroutines Close waits for
+func (ws *WS) startReplay() error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	if ws.closed() {
+		return ErrClosed
+	}
+	ws.wg.Add(1)
+	if ws.fanOut != nil {
+		ws.fanOut.start(ws)
+	}
+	return nil
+}
+
+// waitReplay waits for d, it returns the error of ctx or ErrClosed if ctx was cancelled
+// or the WS closed before
+func (ws *WS) waitReplay(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		if ws.closed() {
+			return ErrClosed
+		}
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-ws.ctx.Done():
+		return ErrClosed
+	}
+}
+
+// noEOF turns the io.EOF of a record cut short into io.ErrUnexpectedEOF
+func noEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,148 @@
+package ticks
+
+import "sync"
+
+// DefaultTokenChanSize is the capacity of the channels returned by TokenChannel
+const DefaultTokenChanSize = 100
+
+// tokenRouter delivers the ticks of selected tokens to handlers and per-token channels
+// instead of DataChan
+type tokenRouter struct {
+	mu       sync.RWMutex
+	nextID   int
+	handlers map[int32]map[int]func(TickData)
+	channels map[int32]chan TickData
+}
+
+// router returns the router of the client, creating it on first use
+func (ws *WS) router() *tokenRouter {
+	if r := ws.routes.Load(); r != nil {
+		return r
+	}
+	ws.routes.CompareAndSwap(nil, &tokenRouter{
+		handlers: make(map[int32]map[int]func(TickData)),
+		channels: make(map[int32]chan TickData),
+	})
+	return ws.routes.Load()
+}
+
+// SubscribeWithHandler subscribes to tokens and calls handler with each of their ticks.
+//
+// Ticks of tokens with a handler or a token channel are no longer sent on DataChan or
+// BatchChan. Handlers run on the goroutine that parsed the tick, so the ticks of a token
+// arrive in order, but a slow handler holds up every token parsed by the same goroutine;
+// hand heavy work off to another goroutine. The returned function removes the handler
+// without unsubscribing
+func (ws *WS) SubscribeWithHandler(tokens []int, mode string, handler func(TickData)) (func(), error) {
+	r := ws.router()
+
+	r.mu.Lock()
+	r.nextID++
+	id := r.nextID
+	for _, token := range tokens {
+		t := int32(token)
+		if r.handlers[t] == nil {
+			r.handlers[t] = make(map[int]func(TickData))
+		}
+		r.handlers[t][id] = handler
+	}
+	r.mu.Unlock()
+
+	remove := func() {
+		r.mu.Lock()
+		for _, token := range tokens {
+			t := int32(token)
+			delete(r.handlers[t], id)
+			if len(r.handlers[t]) == 0 {
+				delete(r.handlers, t)
+			}
+		}
+		r.mu.Unlock()
+	}
+
+	if err := ws.Subscribe(tokens, mode); err != nil {
+		remove()
+		return nil, err
+	}
+	return remove, nil
+}
+
+// TokenChannel returns a channel receiving the ticks of a single token, creating it with
+// DefaultTokenChanSize on first use. The token must be subscribed separately.
+//
+// Ticks of the token are no longer sent on DataChan or BatchChan, and are dropped when the
+// channel is full. The channel is closed by ReleaseTokenChannel or Close
+func (ws *WS) TokenChannel(token int) <-chan TickData {
+	r := ws.router()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ch, ok := r.channels[int32(token)]
+	if !ok {
+		ch = make(chan TickData, DefaultTokenChanSize)
+		if ws.closed() {
+			close(ch)
+			return ch
+		}
+		r.channels[int32(token)] = ch
+	}
+	return ch
+}
+
+// ReleaseTokenChannel closes the channel of a token, returning its ticks to DataChan
+func (ws *WS) ReleaseTokenChannel(token int) {
+	r := ws.router()
+
+	r.mu.Lock()
+	if ch, ok := r.channels[int32(token)]; ok {
+		delete(r.channels, int32(token))
+		close(ch)
+	}
+	r.mu.Unlock()
+}
+
+// route hands a tick to the handlers and channel of its token and reports whether any
+// took it
+func (ws *WS) route(tick TickData) bool {
+	r := ws.routes.Load()
+	if r == nil {
+		return false
+	}
+
+	r.mu.RLock()
+	handlers := r.handlers[tick.Token]
+	ch, hasChan := r.channels[tick.Token]
+	if hasChan {
+		select {
+		case ch <- tick:
+		default:
+			ws.logger.Warn().Int32("token", tick.Token).Msg("Token channel is full, skipping message")
+		}
+	}
+	callbacks := make([]func(TickData), 0, len(handlers))
+	for _, handler := range handlers {
+		callbacks = append(callbacks, handler)
+	}
+	r.mu.RUnlock()
+
+	for _, handler := range callbacks {
+		handler(tick)
+	}
+	return hasChan || len(callbacks) > 0
+}
+
+// closeRoutes closes every token channel once no goroutine delivers ticks anymore
+func (ws *WS) closeRoutes() {
+	r := ws.routes.Load()
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	for token, ch := range r.channels {
+		delete(r.channels, token)
+		close(ch)
+	}
+	r.mu.Unlock()
+}
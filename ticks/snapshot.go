@@ -0,0 +1,91 @@
+package ticks
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// DepthRecord is a single sampled depth snapshot as written to disk
+type DepthRecord struct {
+	SampledAt time.Time `json:"sampled_at"`
+	DepthSnapshot
+}
+
+// DepthSnapshotter samples a DepthBook at a fixed interval and writes the
+// snapshots of the selected tokens as JSON lines to a file
+type DepthSnapshotter struct {
+	Book     *DepthBook
+	Tokens   []int32
+	Interval time.Duration
+	Path     string
+}
+
+// NewDepthSnapshotter creates a snapshotter sampling the given tokens every interval
+func NewDepthSnapshotter(book *DepthBook, path string, interval time.Duration, tokens []int32) *DepthSnapshotter {
+	return &DepthSnapshotter{
+		Book:     book,
+		Tokens:   tokens,
+		Interval: interval,
+		Path:     path,
+	}
+}
+
+// Run samples the book until the context is cancelled. Snapshots are appended to Path.
+// Tokens without depth data at the sampling instant are skipped. When Tokens is empty
+// every token present in the book is sampled.
+func (s *DepthSnapshotter) Run(ctx context.Context) error {
+	if s.Interval <= 0 {
+		return fmt.Errorf("invalid sampling interval: %s", s.Interval)
+	}
+
+	file, err := os.OpenFile(s.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("error opening snapshot file: %w", err)
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	defer writer.Flush()
+	encoder := json.NewEncoder(writer)
+
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case now := <-ticker.C:
+			if err := s.sample(encoder, now); err != nil {
+				return err
+			}
+			if err := writer.Flush(); err != nil {
+				return fmt.Errorf("error writing snapshot file: %w", err)
+			}
+		}
+	}
+}
+
+// sample writes one record per selected token present in the book
+func (s *DepthSnapshotter) sample(encoder *json.Encoder, now time.Time) error {
+	tokens := s.Tokens
+	if len(tokens) == 0 {
+		tokens = s.Book.Tokens()
+	}
+
+	for _, token := range tokens {
+		snapshot, ok := s.Book.Get(token)
+		if !ok {
+			continue
+		}
+
+		if err := encoder.Encode(DepthRecord{SampledAt: now, DepthSnapshot: snapshot}); err != nil {
+			return fmt.Errorf("error encoding snapshot: %w", err)
+		}
+	}
+	return nil
+}
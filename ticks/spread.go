@@ -0,0 +1,289 @@
+package ticks
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// SpreadLeg is one instrument of a spread, a positive ratio is bought and a negative ratio sold
+// when the spread is bought
+type SpreadLeg struct {
+	Token int32
+	Ratio int32
+}
+
+// Spread defines a synthetic instrument combining legs, quoted under a virtual token.
+// Virtual tokens must not collide with exchange tokens, negative values below -1 are safe
+type Spread struct {
+	Token int32
+	Name  string
+	Legs  []SpreadLeg
+}
+
+// VerticalSpread buys long and sells short in equal size, e.g., a NIFTY 19000/19100 call vertical
+func VerticalSpread(token int32, name string, long, short int32) Spread {
+	return Spread{Token: token, Name: name, Legs: []SpreadLeg{{Token: long, Ratio: 1}, {Token: short, Ratio: -1}}}
+}
+
+// CalendarSpread buys the far expiry and sells the near expiry in equal size
+func CalendarSpread(token int32, name string, near, far int32) Spread {
+	return Spread{Token: token, Name: name, Legs: []SpreadLeg{{Token: far, Ratio: 1}, {Token: near, Ratio: -1}}}
+}
+
+// SpreadQuote is the synthetic quote of a spread, prices are in paise like tick prices
+type SpreadQuote struct {
+	Token     int32     `json:"token"`
+	Name      string    `json:"name"`
+	Bid       int32     `json:"bid"`
+	Ask       int32     `json:"ask"`
+	Mid       int32     `json:"mid"`
+	Last      int32     `json:"last"`
+	BidQty    int64     `json:"bid_qty"`
+	AskQty    int64     `json:"ask_qty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// legQuote is the top of book of a leg
+type legQuote struct {
+	bid, ask, ltp, close int32
+	bidQty, askQty       int64
+	ltt                  int32
+}
+
+// SpreadEngine combines leg ticks into spread quotes in real time
+type SpreadEngine struct {
+	mu      sync.RWMutex
+	spreads map[int32]Spread
+	byLeg   map[int32][]int32
+	legs    map[int32]legQuote
+	quotes  map[int32]SpreadQuote
+}
+
+// NewSpreadEngine creates an engine without spreads
+func NewSpreadEngine() *SpreadEngine {
+	return &SpreadEngine{
+		spreads: make(map[int32]Spread),
+		byLeg:   make(map[int32][]int32),
+		legs:    make(map[int32]legQuote),
+		quotes:  make(map[int32]SpreadQuote),
+	}
+}
+
+// Add registers a spread, replacing any spread with the same virtual token
+func (e *SpreadEngine) Add(spread Spread) error {
+	if len(spread.Legs) == 0 {
+		return fmt.Errorf("spread %d has no legs", spread.Token)
+	}
+	for _, leg := range spread.Legs {
+		if leg.Ratio == 0 {
+			return fmt.Errorf("spread %d: leg %d has a zero ratio", spread.Token, leg.Token)
+		}
+		if leg.Token == spread.Token {
+			return fmt.Errorf("spread %d: virtual token collides with a leg", spread.Token)
+		}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.removeLocked(spread.Token)
+	e.spreads[spread.Token] = spread
+	for _, leg := range spread.Legs {
+		if !slices.Contains(e.byLeg[leg.Token], spread.Token) {
+			e.byLeg[leg.Token] = append(e.byLeg[leg.Token], spread.Token)
+		}
+	}
+	return nil
+}
+
+// Remove unregisters the spread quoted under token
+func (e *SpreadEngine) Remove(token int32) {
+	e.mu.Lock()
+	e.removeLocked(token)
+	e.mu.Unlock()
+}
+
+// LegTokens returns the tokens of every leg, to be subscribed in a mode carrying depth
+func (e *SpreadEngine) LegTokens() []int {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	tokens := make([]int, 0, len(e.byLeg))
+	for token := range e.byLeg {
+		tokens = append(tokens, int(token))
+	}
+	return tokens
+}
+
+// Quote returns the latest quote of a spread
+func (e *SpreadEngine) Quote(token int32) (SpreadQuote, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	quote, ok := e.quotes[token]
+	return quote, ok
+}
+
+// Update applies a leg tick and returns the virtual ticks of the spreads it changed.
+// A spread is quoted only once every leg has ticked
+func (e *SpreadEngine) Update(tick TickData) []TickData {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	spreads := e.byLeg[tick.Token]
+	if len(spreads) == 0 {
+		return nil
+	}
+	e.legs[tick.Token] = topOfBook(tick)
+
+	var virtual []TickData
+	now := time.Now()
+	for _, token := range spreads {
+		spread := e.spreads[token]
+		quote, ltt, close, ok := e.price(spread)
+		if !ok {
+			continue
+		}
+		quote.UpdatedAt = now
+		e.quotes[token] = quote
+		virtual = append(virtual, quote.tick(ltt, close))
+	}
+	return virtual
+}
+
+// Pipe forwards the ticks of in and emits the virtual ticks of spreads right after the
+// leg tick that changed them. The returned channel is closed when in is closed or ctx is done
+func (e *SpreadEngine) Pipe(ctx context.Context, in <-chan TickData) <-chan TickData {
+	out := make(chan TickData, cap(in))
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case tick, ok := <-in:
+				if !ok {
+					return
+				}
+				for _, t := range append([]TickData{tick}, e.Update(tick)...) {
+					select {
+					case out <- t:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}
+	}()
+	return out
+}
+
+// price combines the legs of a spread. Buying the spread lifts the asks of bought legs and
+// hits the bids of sold legs, selling it does the opposite
+func (e *SpreadEngine) price(spread Spread) (SpreadQuote, int32, int32, bool) {
+	quote := SpreadQuote{Token: spread.Token, Name: spread.Name, BidQty: -1, AskQty: -1}
+	var ltt, close int32
+
+	for _, leg := range spread.Legs {
+		q, ok := e.legs[leg.Token]
+		if !ok {
+			return SpreadQuote{}, 0, 0, false
+		}
+
+		ratio := leg.Ratio
+		size := int64(abs32(ratio))
+		bidQty, askQty := q.bidQty/size, q.askQty/size
+		if ratio > 0 {
+			quote.Bid += ratio * q.bid
+			quote.Ask += ratio * q.ask
+		} else {
+			quote.Bid += ratio * q.ask
+			quote.Ask += ratio * q.bid
+			bidQty, askQty = q.askQty/size, q.bidQty/size
+		}
+		quote.Last += ratio * q.ltp
+		close += ratio * q.close
+		quote.BidQty = minQty(quote.BidQty, bidQty)
+		quote.AskQty = minQty(quote.AskQty, askQty)
+		ltt = max(ltt, q.ltt)
+	}
+	quote.Mid = (quote.Bid + quote.Ask) / 2
+	return quote, ltt, close, true
+}
+
+// tick converts a quote into a virtual tick carrying the spread prices as best bid and ask
+func (q SpreadQuote) tick(ltt, close int32) TickData {
+	tick := TickData{
+		Token: q.Token,
+		LTP:   q.Last,
+		Close: close,
+		LTT:   ltt,
+		Time:  int32(q.UpdatedAt.Unix()),
+	}
+	tick.NetChange = q.Last - close
+	tick.MarketDepth.Bids[0] = DepthLevel{Quantity: q.BidQty, Price: q.Bid, Orders: 1}
+	tick.MarketDepth.Asks[0] = DepthLevel{Quantity: q.AskQty, Price: q.Ask, Orders: 1}
+	return tick
+}
+
+// topOfBook extracts the best prices of a tick, falling back to the last traded price
+// for ticks without depth
+func topOfBook(tick TickData) legQuote {
+	q := legQuote{
+		bid:   tick.LTP,
+		ask:   tick.LTP,
+		ltp:   tick.LTP,
+		close: tick.Close,
+		ltt:   tick.LTT,
+	}
+	if best := tick.MarketDepth.Bids[0]; best.Price > 0 {
+		q.bid, q.bidQty = best.Price, best.Quantity
+	}
+	if best := tick.MarketDepth.Asks[0]; best.Price > 0 {
+		q.ask, q.askQty = best.Price, best.Quantity
+	}
+	return q
+}
+
+// removeLocked unregisters a spread, the caller must hold e.mu
+func (e *SpreadEngine) removeLocked(token int32) {
+	spread, ok := e.spreads[token]
+	if !ok {
+		return
+	}
+	delete(e.spreads, token)
+	delete(e.quotes, token)
+
+	for _, leg := range spread.Legs {
+		remaining := e.byLeg[leg.Token][:0]
+		for _, t := range e.byLeg[leg.Token] {
+			if t != token {
+				remaining = append(remaining, t)
+			}
+		}
+		if len(remaining) == 0 {
+			delete(e.byLeg, leg.Token)
+			delete(e.legs, leg.Token)
+		} else {
+			e.byLeg[leg.Token] = remaining
+		}
+	}
+}
+
+// minQty returns the smaller quantity, treating -1 as unset
+func minQty(current, qty int64) int64 {
+	if current < 0 {
+		return qty
+	}
+	return min(current, qty)
+}
+
+func abs32(v int32) int32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
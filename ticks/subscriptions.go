@@ -0,0 +1,194 @@
+package ticks
+
+import (
+	"errors"
+	"slices"
+	"sort"
+)
+
+// Subscription modes, from the lightest to the richest, see also ModeDepth20
+const (
+	ModeLTP   = "ltp"
+	ModeQuote = "quote"
+	ModeFull  = "full"
+)
+
+// Subscription is the mode a token is subscribed in
+type Subscription struct {
+	Token int    `json:"token"`
+	Mode  string `json:"mode"`
+}
+
+// modeRank orders modes by the data they carry, unknown modes rank lowest
+func modeRank(mode string) int {
+	switch mode {
+	case ModeLTP:
+		return 1
+	case ModeQuote:
+		return 2
+	case ModeFull:
+		return 3
+	case ModeDepth20:
+		return 4
+	}
+	return 0
+}
+
+// Subscribe subscribes to market data for given tokens.
+// Large token lists are split into batches of SubscribeBatchSize tokens.
+//
+// Subscriptions are tracked per token: tokens already subscribed in the same or a richer
+// mode are not sent again, and tokens subscribed in a lighter mode are upgraded, e.g.,
+// from ltp to full. Use SetMode to move tokens to a lighter mode. Subscriptions made
+// before Connect are sent once connected, and all are restored on every reconnect
+func (ws *WS) Subscribe(tokens []int, mode string) error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	if ws.closed() {
+		return ErrClosed
+	}
+
+	var send []int
+	for _, token := range dedupeTokens(tokens) {
+		current, ok := ws.subscriptions.Load(token)
+		switch {
+		case !ok:
+			ws.TokenList = append(ws.TokenList, token)
+		case current.(string) == mode || modeRank(mode) < modeRank(current.(string)):
+			continue
+		}
+		ws.subscriptions.Store(token, mode)
+		send = append(send, token)
+	}
+
+	if len(send) == 0 || !ws.online() {
+		return nil
+	}
+	return ws.sendControlMessages("sub", send, mode)
+}
+
+// SetMode moves tokens to a mode, lighter or richer, subscribing those not subscribed yet.
+// Tokens changing mode are unsubscribed from their previous mode first
+func (ws *WS) SetMode(tokens []int, mode string) error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	if ws.closed() {
+		return ErrClosed
+	}
+
+	previous := make(map[string][]int)
+	var send []int
+	for _, token := range dedupeTokens(tokens) {
+		current, ok := ws.subscriptions.Load(token)
+		switch {
+		case !ok:
+			ws.TokenList = append(ws.TokenList, token)
+		case current.(string) == mode:
+			continue
+		default:
+			previous[current.(string)] = append(previous[current.(string)], token)
+		}
+		ws.subscriptions.Store(token, mode)
+		send = append(send, token)
+	}
+
+	if len(send) == 0 || !ws.online() {
+		return nil
+	}
+	for old, changed := range previous {
+		if err := ws.sendControlMessages("unsub", changed, old); err != nil {
+			return err
+		}
+	}
+	return ws.sendControlMessages("sub", send, mode)
+}
+
+// Unsubscribe removes subscription for given tokens.
+// Large token lists are split into batches of SubscribeBatchSize tokens.
+//
+// Tokens are unsubscribed from the mode they are subscribed in; mode only applies to
+// tokens the client has no subscription of
+func (ws *WS) Unsubscribe(tokens []int, mode string) error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	if ws.closed() {
+		return ErrClosed
+	}
+
+	byMode := make(map[string][]int)
+	for _, token := range dedupeTokens(tokens) {
+		current, ok := ws.subscriptions.LoadAndDelete(token)
+		if ok {
+			byMode[current.(string)] = append(byMode[current.(string)], token)
+		} else {
+			byMode[mode] = append(byMode[mode], token)
+		}
+	}
+	ws.TokenList = slices.DeleteFunc(ws.TokenList, func(token int) bool {
+		_, ok := ws.subscriptions.Load(token)
+		return !ok
+	})
+
+	if !ws.online() {
+		return nil
+	}
+	for m, removed := range byMode {
+		if err := ws.sendControlMessages("unsub", removed, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Subscriptions returns the subscribed tokens and their modes, ordered by token
+func (ws *WS) Subscriptions() []Subscription {
+	var subs []Subscription
+	ws.subscriptions.Range(func(key, value any) bool {
+		subs = append(subs, Subscription{Token: key.(int), Mode: value.(string)})
+		return true
+	})
+	sort.Slice(subs, func(i, j int) bool { return subs[i].Token < subs[j].Token })
+	return subs
+}
+
+// online reports whether control messages can be sent, the caller must hold ws.mu.
+// Subscriptions changed while offline are sent by resubscribeAll once connected
+func (ws *WS) online() bool {
+	return ws.Conn != nil && ws.State() == StateConnected
+}
+
+// resubscribeAll sends every stored subscription on a new connection, the caller must
+// hold ws.mu
+func (ws *WS) resubscribeAll() {
+	tokensByMode := make(map[string][]int)
+	for _, sub := range ws.Subscriptions() {
+		tokensByMode[sub.Mode] = append(tokensByMode[sub.Mode], sub.Token)
+	}
+
+	for mode, tokens := range tokensByMode {
+		if err := ws.sendControlMessages("sub", tokens, mode); errors.Is(err, ErrClosed) {
+			return
+		} else if err != nil {
+			ws.logger.Error().Err(err).
+				Str("mode", mode).
+				Interface("tokens", tokens).
+				Msg("Failed to resubscribe")
+		}
+	}
+}
+
+// dedupeTokens returns tokens without repeats, in their first order
+func dedupeTokens(tokens []int) []int {
+	seen := make(map[int]bool, len(tokens))
+	unique := make([]int, 0, len(tokens))
+	for _, token := range tokens {
+		if !seen[token] {
+			seen[token] = true
+			unique = append(unique, token)
+		}
+	}
+	return unique
+}
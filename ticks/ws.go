@@ -1,13 +1,15 @@
 package ticks
 
 import (
-	"bytes"
 	"context"
-	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
 	"os"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -16,6 +18,11 @@ import (
 
 const (
 	WSS_URL = "wss://wss.tiqs.trading"
+
+	// DefaultSubscribeBatchSize is the maximum number of tokens sent in a single sub/unsub message
+	DefaultSubscribeBatchSize = 500
+	// DefaultControlInterval is the minimum delay between consecutive sub/unsub messages
+	DefaultControlInterval = 100 * time.Millisecond
 )
 
 // DepthLevel represents a single level in the market depth
@@ -54,24 +61,67 @@ type TickData struct {
 	LowerLimit         int32       `json:"lower_limit"`
 	UpperLimit         int32       `json:"upper_limit"`
 	MarketDepth        MarketDepth `json:"market_depth"`
+	Greeks             *Greeks     `json:"greeks,omitempty"`
 }
 
 // WS represents the WebSocket client
 type WS struct {
-	AppID         string
-	Token         string
-	TokenList     []int
-	Conn          *websocket.Conn
-	URL           string
-	RetryDelay    time.Duration
-	MaxRetries    int
+	AppID      string
+	Token      string
+	TokenList  []int
+	Conn       *websocket.Conn
+	URL        string
+	RetryDelay time.Duration
+	MaxRetries int
+
+	// Batching and pacing of sub/unsub messages, a batch size of 0 disables batching
+	SubscribeBatchSize int
+	ControlInterval    time.Duration
+
+	// Optional connection lifecycle hooks
+	OnConnect    func()
+	OnDisconnect func(error)
+
+	// Request permessage-deflate compression when dialing, see CompressionStats
+	EnableCompression bool
+
+	// Optional fault injection for resilience testing, see FaultInjector
+	Faults *FaultInjector
+
+	// Optional recorder of the binary frames received, see Recorder and Replayer
+	Recorder *Recorder
+
+	// Optional source of credentials queried before every dial, overriding AppID and Token
+	Credentials CredentialsProvider
+
+	// Heartbeat: a ping is sent every PingInterval and the connection is dropped as stale,
+	// then reconnected, when nothing arrives within ReadTimeout, which defaults to
+	// PingInterval plus PongTimeout. A zero PingInterval disables pings and, without a
+	// ReadTimeout, read deadlines
+	PingInterval time.Duration
+	PongTimeout  time.Duration
+	ReadTimeout  time.Duration
+
 	ctx           context.Context
 	cancel        context.CancelFunc
+	state         atomic.Int32
+	done          chan struct{}
+	closeOnce     sync.Once
+	wg            sync.WaitGroup // goroutines sending on the channels
 	logger        *zerolog.Logger
 	DataChan      chan TickData
+	BatchChan     chan []TickData
+	Depth20Chan   chan Depth20Tick // 20-level depth of the tokens subscribed in ModeDepth20
+	fanOut        *fanOut
+	routes        atomic.Pointer[tokenRouter]
 	errChan       chan error
 	subscriptions sync.Map
-	mu            sync.RWMutex
+	connMu        sync.Mutex   // serializes Connect, taken before mu
+	mu            sync.RWMutex // guards Conn, TokenList and the sending of control messages
+	lastControl   time.Time
+	stats         wireStats
+	stale         atomic.Int64 // connections dropped by the heartbeat
+	recordFailed  atomic.Bool  // the Recorder failed, reported once
 }
 
 // NewWS creates a new WebSocket client instance
@@ -86,81 +136,114 @@ func NewWS(appId, token string) *WS {
 		URL:        WSS_URL,
 		RetryDelay: 5 * time.Second,
 		MaxRetries: 25,
-		ctx:        ctx,
-		cancel:     cancel,
-		logger:     &logger,
-		DataChan:   make(chan TickData, 1000),
-		errChan:    make(chan error, 100),
+
+		SubscribeBatchSize: DefaultSubscribeBatchSize,
+		ControlInterval:    DefaultControlInterval,
+		PingInterval:       DefaultPingInterval,
+		PongTimeout:        DefaultPongTimeout,
+
+		ctx:         ctx,
+		cancel:      cancel,
+		done:        make(chan struct{}),
+		logger:      &logger,
+		DataChan:    make(chan TickData, DefaultDataChanSize),
+		Depth20Chan: make(chan Depth20Tick, DefaultDepth20ChanSize),
+		errChan:     make(chan error, 100),
 	}
 }
 
-// Connect establishes a WebSocket connection
+// Connect establishes a WebSocket connection, retrying up to MaxRetries times.
+// It returns ErrClosed once Close was called, also while it is retrying.
+//
+// Stored subscriptions are sent before the connection starts reading, then OnConnect is
+// called. Subscribe and the other methods of the client do not wait for a retrying
+// Connect and may be called from OnConnect, which must not call Connect
 func (ws *WS) Connect() error {
-	ws.mu.Lock()
-	defer ws.mu.Unlock()
+	// Lock order: connMu, then mu. Only connMu is held while dialing and retrying
+	ws.connMu.Lock()
+	defer ws.connMu.Unlock()
+
+	if !ws.setState(StateConnecting) {
+		return ErrClosed
+	}
 
 	var err error
 	for attempt := 1; attempt <= ws.MaxRetries; attempt++ {
 		ws.logger.Info().Msgf("Attempting to connect to WebSocket (attempt %d/%d)", attempt, ws.MaxRetries)
 
-		url := fmt.Sprintf("%s?appId=%s&token=%s", ws.URL, ws.AppID, ws.Token)
-		ws.Conn, _, err = websocket.DefaultDialer.Dial(url, nil)
-
+		var url string
+		var conn *websocket.Conn
+		url, err = dialURL(ws.ctx, ws.URL, ws.Credentials, ws.AppID, ws.Token)
+		var resp *http.Response
 		if err == nil {
-			ws.logger.Info().Msg("Connected to WebSocket")
-
-			// Resubscribe to existing subscriptions
-			ws.resubscribeAll()
+			conn, resp, err = ws.dial(url)
+		}
 
-			// Start message handler
-			go ws.handleMessages()
+		if err == nil {
+			if !ws.start(conn) {
+				conn.Close()
+				return ErrClosed
+			}
+			ws.recordHandshake(resp)
+			ws.logger.Info().Bool("compression", ws.stats.negotiated.Load()).Msg("Connected to WebSocket")
+			if ws.OnConnect != nil {
+				ws.OnConnect()
+			}
 			return nil
 		}
 
+		if ws.closed() {
+			return ErrClosed
+		}
 		ws.logger.Error().Err(err).Msgf("Failed to connect. Retrying in %s...", ws.RetryDelay)
-		time.Sleep(ws.RetryDelay)
+		if !ws.sleep(ws.RetryDelay) {
+			return ErrClosed
+		}
 	}
 
+	ws.setState(StateDisconnected)
 	return fmt.Errorf("failed to connect after %d attempts: %w", ws.MaxRetries, err)
 }
 
-// Subscribe subscribes to market data for given tokens
-func (ws *WS) Subscribe(tokens []int, mode string) error {
-	ws.mu.Lock()
-	defer ws.mu.Unlock()
-
-	message := map[string]interface{}{
-		"code": "sub",
-		"mode": mode,
-		mode:   tokens,
-	}
-
-	// Store subscription
-	for _, token := range tokens {
-		ws.subscriptions.Store(token, mode)
+// dial opens a connection to url. The dialer only applies the deadline of its context to
+// the handshake, so the connection is closed by hand if the client is closed meanwhile
+func (ws *WS) dial(url string) (*websocket.Conn, *http.Response, error) {
+	var stop func() bool
+	dialer := ws.dialer(func(conn net.Conn) {
+		stop = context.AfterFunc(ws.ctx, func() { conn.Close() })
+	})
+	conn, resp, err := dialer.DialContext(ws.ctx, url, nil)
+	if stop != nil {
+		stop()
 	}
-
-	ws.TokenList = append(ws.TokenList, tokens...)
-	return ws.sendJSONMessage(message)
+	return conn, resp, err
 }
 
-// Unsubscribe removes subscription for given tokens
-func (ws *WS) Unsubscribe(tokens []int, mode string) error {
+// start makes conn the connection of the client, restores the stored subscriptions on it
+// and starts reading, or reports false if the client was closed meanwhile.
+//
+// It holds ws.mu throughout, so subscriptions made concurrently are either restored or
+// sent on the new connection, and Close either sees conn or is seen by start
+func (ws *WS) start(conn *websocket.Conn) bool {
 	ws.mu.Lock()
 	defer ws.mu.Unlock()
 
-	message := map[string]interface{}{
-		"code": "unsub",
-		"mode": mode,
-		mode:   tokens,
+	if !ws.setState(StateConnected) {
+		return false
 	}
+	ws.Conn = conn
 
-	// Remove subscription
-	for _, token := range tokens {
-		ws.subscriptions.Delete(token)
-	}
+	// Resubscribe to existing subscriptions
+	ws.resubscribeAll()
 
-	return ws.sendJSONMessage(message)
+	// Start parsing workers, heartbeat and message handler
+	if ws.fanOut != nil {
+		ws.fanOut.start(ws)
+	}
+	stop := make(chan struct{})
+	ws.startHeartbeat(conn, stop)
+	ws.goTracked(func() { ws.handleMessages(conn, stop) })
+	return true
 }
 
 // GetDataChannel returns the channel for receiving market data
@@ -173,46 +256,83 @@ func (ws *WS) GetErrorChannel() <-chan error {
 	return ws.errChan
 }
 
-// Close closes the WebSocket connection and cleanup
+// Close closes the WebSocket connection and stops every goroutine of the client.
+//
+// It is safe to call at any point, including while connecting or reconnecting, and more
+// than once. The data, batch, depth20 and error channels are closed only after every
+// goroutine that sends on them has returned, then Done is closed
 func (ws *WS) Close() error {
-	ws.mu.Lock()
-	defer ws.mu.Unlock()
-
-	ws.cancel() // Stop all goroutines
-
-	// Close channels
-	close(ws.DataChan)
-	close(ws.errChan)
-
-	if ws.Conn != nil {
-		ws.logger.Info().Msg("Closing WebSocket connection")
-		return ws.Conn.Close()
-	}
-	return nil
+	var err error
+	ws.closeOnce.Do(func() {
+		ws.state.Store(int32(StateClosed))
+		ws.cancel() // Abort dials, retry delays and blocked sends
+
+		ws.mu.Lock()
+		if ws.Conn != nil {
+			ws.logger.Info().Msg("Closing WebSocket connection")
+			err = ws.Conn.Close() // Unblock the read loop
+		}
+		ws.mu.Unlock()
+
+		ws.wg.Wait()
+		ws.closeRoutes()
+		close(ws.DataChan)
+		close(ws.Depth20Chan)
+		close(ws.errChan)
+		if ws.BatchChan != nil {
+			close(ws.BatchChan)
+		}
+		close(ws.done)
+	})
+	return err
 }
 
-// handleMessages processes incoming WebSocket messages
-func (ws *WS) handleMessages() {
+// handleMessages processes incoming WebSocket messages of a connection, and closes stop
+// once the connection is lost
+func (ws *WS) handleMessages(conn *websocket.Conn, stop chan struct{}) {
 	for {
 		select {
 		case <-ws.ctx.Done():
 			return
 		default:
-			if ws.Conn == nil {
-				return
+			messageType, reader, err := conn.NextReader()
+			var f *frame
+			if err == nil {
+				f, err = readFrame(reader)
+			}
+			if err == nil && ws.Faults != nil {
+				if f.data, err = ws.Faults.apply(f.data); err != nil {
+					f.release()
+					conn.Close()
+				}
 			}
-
-			messageType, message, err := ws.Conn.ReadMessage()
 			if err != nil {
+				if ws.closed() {
+					return
+				}
+				close(stop)
+				err = ws.staleError(err)
+				if errors.Is(err, ErrStale) {
+					conn.Close()
+				}
+				ws.setState(StateDisconnected)
 				ws.logger.Error().Err(err).Msg("Error reading message")
-				ws.errChan <- err
+				ws.reportError(err)
+				if ws.OnDisconnect != nil {
+					ws.OnDisconnect(err)
+				}
 				ws.reconnect()
 				return
 			}
+			message := f.data
+			ws.extendDeadline(conn)
+			ws.stats.messages.Add(1)
+			ws.stats.payloadBytes.Add(int64(len(message)))
 
 			// Handle Heartbeat (Message Length 1)
 			if len(message) == 1 {
-				ws.logger.Info().Msg("Received heartbeat, sending as JSON")
+				f.release()
+				ws.logger.Debug().Msg("Received heartbeat, sending as JSON")
 
 				// Prepare JSON heartbeat message
 				heartbeatJSON, err := json.Marshal(map[string]interface{}{
@@ -228,7 +348,7 @@ func (ws *WS) handleMessages() {
 				// Send the JSON heartbeat message as a TickData wrapper
 				select {
 				case ws.DataChan <- TickData{Token: -1, LTT: int32(time.Now().Unix())}: // Use -1 as special token
-					ws.logger.Info().Msgf("Sent heartbeat: %s", string(heartbeatJSON))
+					ws.logger.Debug().Msgf("Sent heartbeat: %s", string(heartbeatJSON))
 				default:
 					ws.logger.Warn().Msg("Data channel is full, skipping heartbeat")
 				}
@@ -237,100 +357,24 @@ func (ws *WS) handleMessages() {
 
 			// Process market data if it's a binary message
 			if messageType == websocket.BinaryMessage {
-				tickData, err := ws.parseBinaryToTickData(message)
-				if err != nil {
-					ws.logger.Error().Err(err).Msg("Error parsing binary data")
-					continue
-				}
-
-				// Send data to channel (non-blocking)
-				select {
-				case ws.DataChan <- tickData:
-				default:
-					ws.logger.Warn().Msg("Data channel is full, skipping message")
-				}
+				ws.record(message)
+				ws.dispatchFrame(f)
+			} else {
+				f.release()
 			}
 		}
 	}
 }
 
-// parseBinaryToTickData converts binary message to TickData struct
-func (ws *WS) parseBinaryToTickData(data []byte) (TickData, error) {
-	var tick TickData
-
-	if len(data) < 17 {
-		return tick, fmt.Errorf("invalid data length: %d", len(data))
+// record appends a frame to the Recorder, if any, reporting its first failure
+func (ws *WS) record(message []byte) {
+	if ws.Recorder == nil {
+		return
 	}
-
-	// Parse basic fields
-	tick.Token = bigEndianToInt(data[:4])
-	tick.LTP = bigEndianToInt(data[4:8])
-
-	if len(data) == 17 {
-		tick.Close = bigEndianToInt(data[13:17])
-		tick.NetChange = int32((float64(tick.LTP-tick.Close) / float64(tick.Close)) * 100)
-
-		if tick.LTP > tick.Close {
-			tick.NetChangeIndicator = 43 // '+'
-		} else if tick.LTP < tick.Close {
-			tick.NetChangeIndicator = 45 // '-'
-		} else {
-			tick.NetChangeIndicator = 32 // ' '
-		}
+	if err := ws.Recorder.Record(time.Now(), message); err != nil && ws.recordFailed.CompareAndSwap(false, true) {
+		ws.logger.Error().Err(err).Msg("Failed to record frame, recording stopped")
+		ws.reportError(err)
 	}
-
-	if len(data) >= 81 {
-		tick.AvgPrice = bigEndianToInt(data[17:21])
-		tick.TotalBuyQty = int64(bigEndianToInt(data[21:29]))
-		tick.TotalSellQty = int64(bigEndianToInt(data[29:37]))
-		tick.Open = bigEndianToInt(data[37:41])
-		tick.High = bigEndianToInt(data[41:45])
-		tick.Close = bigEndianToInt(data[45:49])
-		tick.Low = bigEndianToInt(data[49:53])
-		tick.Volume = int64(bigEndianToInt(data[53:61]))
-		tick.LTT = bigEndianToInt(data[61:65])
-		tick.Time = bigEndianToInt(data[65:69])
-		tick.OI = bigEndianToInt(data[69:73])
-		tick.OIDayHigh = bigEndianToInt(data[73:77])
-		tick.OIDayLow = bigEndianToInt(data[77:81])
-	}
-
-	if len(data) == 229 {
-		tick.LowerLimit = bigEndianToInt(data[81:85])
-		tick.UpperLimit = bigEndianToInt(data[85:89])
-
-		// Parse market depth
-		offset := 89
-		for i := 0; i < 5; i++ {
-			// Parse bids
-			tick.MarketDepth.Bids[i] = DepthLevel{
-				Quantity: int64(bigEndianToInt(data[offset : offset+8])),
-				Price:    bigEndianToInt(data[offset+8 : offset+12]),
-				Orders:   int16(bigEndianToInt(data[offset+12 : offset+14])),
-			}
-			offset += 14
-		}
-
-		for i := 0; i < 5; i++ {
-			// Parse asks
-			tick.MarketDepth.Asks[i] = DepthLevel{
-				Quantity: int64(bigEndianToInt(data[offset : offset+8])),
-				Price:    bigEndianToInt(data[offset+8 : offset+12]),
-				Orders:   int16(bigEndianToInt(data[offset+12 : offset+14])),
-			}
-			offset += 14
-		}
-	}
-
-	return tick, nil
-}
-
-// Helper function to convert big endian bytes to int32
-func bigEndianToInt(data []byte) int32 {
-	var value int32
-	buffer := bytes.NewReader(data)
-	binary.Read(buffer, binary.BigEndian, &value)
-	return value
 }
 
 // sendJSONMessage sends a JSON message through the WebSocket connection
@@ -347,33 +391,50 @@ func (ws *WS) sendJSONMessage(data interface{}) error {
 	return ws.Conn.WriteMessage(websocket.TextMessage, jsonData)
 }
 
-// reconnect attempts to reconnect to the WebSocket server
-func (ws *WS) reconnect() {
-	ws.logger.Info().Msg("Attempting to reconnect...")
+// sendControlMessages sends sub/unsub messages for tokens in batches, pacing
+// consecutive messages by ControlInterval to avoid control-message floods. The callers
+// hold ws.mu, so the pacing stops as soon as the client is closed
+func (ws *WS) sendControlMessages(code string, tokens []int, mode string) error {
+	for _, batch := range batchTokens(tokens, ws.SubscribeBatchSize) {
+		if wait := ws.ControlInterval - time.Since(ws.lastControl); wait > 0 && !ws.sleep(wait) {
+			return ErrClosed
+		}
 
-	if err := ws.Connect(); err != nil {
-		ws.logger.Error().Err(err).Msg("Failed to reconnect")
-		ws.errChan <- fmt.Errorf("reconnection failed: %w", err)
+		message := map[string]interface{}{
+			"code": code,
+			"mode": mode,
+			mode:   batch,
+		}
+
+		err := ws.sendJSONMessage(message)
+		ws.lastControl = time.Now()
+		if err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
-// resubscribeAll resubscribes to all stored subscriptions
-func (ws *WS) resubscribeAll() {
-	tokensByMode := make(map[string][]int)
+// batchTokens splits tokens into chunks of at most size tokens
+func batchTokens(tokens []int, size int) [][]int {
+	if size <= 0 || len(tokens) <= size {
+		return [][]int{tokens}
+	}
 
-	ws.subscriptions.Range(func(key, value interface{}) bool {
-		token := key.(int)
-		mode := value.(string)
-		tokensByMode[mode] = append(tokensByMode[mode], token)
-		return true
-	})
+	batches := make([][]int, 0, (len(tokens)+size-1)/size)
+	for start := 0; start < len(tokens); start += size {
+		end := min(start+size, len(tokens))
+		batches = append(batches, tokens[start:end])
+	}
+	return batches
+}
 
-	for mode, tokens := range tokensByMode {
-		if err := ws.Subscribe(tokens, mode); err != nil {
-			ws.logger.Error().Err(err).
-				Str("mode", mode).
-				Interface("tokens", tokens).
-				Msg("Failed to resubscribe")
-		}
+// reconnect attempts to reconnect to the WebSocket server
+func (ws *WS) reconnect() {
+	ws.logger.Info().Msg("Attempting to reconnect...")
+
+	if err := ws.Connect(); err != nil && !errors.Is(err, ErrClosed) {
+		ws.logger.Error().Err(err).Msg("Failed to reconnect")
+		ws.reportError(fmt.Errorf("reconnection failed: %w", err))
 	}
 }
@@ -0,0 +1,456 @@
+package ticks
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/rs/zerolog"
+)
+
+// testTimeout bounds every wait of the tests, a deadlock fails instead of hanging
+const testTimeout = 5 * time.Second
+
+// control is a sub or unsub message received by a testServer
+type control struct {
+	Conn   int
+	Code   string
+	Mode   string
+	Tokens []int
+}
+
+// testServer is a WebSocket server handing its connections to the test and recording the
+// control messages received on them
+type testServer struct {
+	*httptest.Server
+	conns    chan *websocket.Conn
+	controls chan control
+	requests chan struct{} // receives a value when a request arrives, before the upgrade
+	hold     chan struct{} // when set, requests wait until it is closed before upgrading
+	refuse   atomic.Bool   // when set, requests are answered with 503
+	count    atomic.Int32  // connections upgraded
+	upgrader websocket.Upgrader
+}
+
+func newTestServer(t *testing.T, hold chan struct{}) *testServer {
+	s := &testServer{
+		conns:    make(chan *websocket.Conn, 16),
+		controls: make(chan control, 64),
+		requests: make(chan struct{}, 64),
+		hold:     hold,
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *testServer) serve(w http.ResponseWriter, r *http.Request) {
+	select {
+	case s.requests <- struct{}{}:
+	default:
+	}
+	if s.hold != nil {
+		select {
+		case <-s.hold:
+		case <-r.Context().Done():
+			return
+		}
+	}
+	if s.refuse.Load() {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	n := int(s.count.Add(1))
+	s.conns <- conn
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		var message map[string]json.RawMessage
+		if err := json.Unmarshal(data, &message); err != nil {
+			continue
+		}
+		c := control{Conn: n}
+		json.Unmarshal(message["code"], &c.Code)
+		json.Unmarshal(message["mode"], &c.Mode)
+		json.Unmarshal(message[c.Mode], &c.Tokens)
+		s.controls <- c
+	}
+}
+
+// drain discards the connections and control messages not taken by the test
+func (s *testServer) drain() {
+	for {
+		select {
+		case <-s.conns:
+		case <-s.controls:
+		default:
+			return
+		}
+	}
+}
+
+// url returns the WebSocket URL of the server
+func (s *testServer) url() string {
+	return "ws" + strings.TrimPrefix(s.URL, "http")
+}
+
+// nextConn returns the next connection upgraded by the server
+func (s *testServer) nextConn(t *testing.T) *websocket.Conn {
+	t.Helper()
+	select {
+	case conn := <-s.conns:
+		return conn
+	case <-time.After(testTimeout):
+		t.Fatal("timed out waiting for a connection")
+		return nil
+	}
+}
+
+// subscriptions collects n control messages and returns the tokens subscribed by mode,
+// failing if one of them is not a sub on connection conn
+func (s *testServer) subscriptions(t *testing.T, conn, n int) map[string][]int {
+	t.Helper()
+	subs := make(map[string][]int)
+	for range n {
+		select {
+		case c := <-s.controls:
+			if c.Conn != conn || c.Code != "sub" {
+				t.Fatalf("got %s of %v on connection %d, want sub on connection %d", c.Code, c.Tokens, c.Conn, conn)
+			}
+			subs[c.Mode] = append(subs[c.Mode], c.Tokens...)
+		case <-time.After(testTimeout):
+			t.Fatalf("timed out waiting for control messages, got %v", subs)
+		}
+	}
+	for _, tokens := range subs {
+		slices.Sort(tokens)
+	}
+	return subs
+}
+
+// newTestWS creates a client of s that retries quickly and sends neither pings nor paced
+// control messages, it is closed when the test ends
+func newTestWS(t *testing.T, s *testServer) *WS {
+	ws := NewWS("app", "token")
+	ws.URL = s.url()
+	ws.RetryDelay = 10 * time.Millisecond
+	ws.MaxRetries = 3
+	ws.PingInterval = 0
+	ws.ControlInterval = 0
+	ws.DisableLogging()
+	t.Cleanup(func() { ws.Close() })
+	return ws
+}
+
+// connectWithin runs Connect and fails the test if it does not return in time
+func connectWithin(t *testing.T, ws *WS) error {
+	t.Helper()
+	result := make(chan error, 1)
+	go func() { result <- ws.Connect() }()
+	select {
+	case err := <-result:
+		return err
+	case <-time.After(testTimeout):
+		t.Fatal("Connect did not return, deadlocked?")
+		return nil
+	}
+}
+
+func equalSubscriptions(got, want map[string][]int) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for mode, tokens := range want {
+		if !slices.Equal(got[mode], tokens) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestWSReconnectResubscribes(t *testing.T) {
+	s := newTestServer(t, nil)
+	ws := newTestWS(t, s)
+
+	disconnects := make(chan error, 4)
+	ws.OnDisconnect = func(err error) { disconnects <- err }
+
+	// Subscriptions made before Connect are sent once connected
+	if err := ws.Subscribe([]int{3, 1}, ModeFull); err != nil {
+		t.Fatal(err)
+	}
+	if err := ws.Subscribe([]int{2}, ModeLTP); err != nil {
+		t.Fatal(err)
+	}
+	if err := connectWithin(t, ws); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string][]int{ModeFull: {1, 3}, ModeLTP: {2}}
+	first := s.nextConn(t)
+	if got := s.subscriptions(t, 1, 2); !equalSubscriptions(got, want) {
+		t.Fatalf("subscriptions on connect = %v, want %v", got, want)
+	}
+
+	// Drop the connection from the server side
+	first.Close()
+	select {
+	case <-disconnects:
+	case <-time.After(testTimeout):
+		t.Fatal("OnDisconnect not called after the connection dropped")
+	}
+
+	second := s.nextConn(t)
+	if got := s.subscriptions(t, 2, 2); !equalSubscriptions(got, want) {
+		t.Fatalf("subscriptions on reconnect = %v, want %v", got, want)
+	}
+
+	// Data flows on the new connection
+	frame := readFrameFile(t, "ltp.bin")
+	expected, err := ParseTick(frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := second.WriteMessage(websocket.BinaryMessage, frame); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case tick := <-ws.DataChan:
+		if tick != expected {
+			t.Fatalf("tick after reconnect = %+v, want %+v", tick, expected)
+		}
+	case <-time.After(testTimeout):
+		t.Fatal("no tick received after reconnect")
+	}
+	if state := ws.State(); state != StateConnected {
+		t.Fatalf("state after reconnect = %s, want connected", state)
+	}
+}
+
+func TestWSSubscribeFromOnConnect(t *testing.T) {
+	s := newTestServer(t, nil)
+	ws := newTestWS(t, s)
+
+	// OnConnect runs without ws.mu held, so it may subscribe
+	results := make(chan error, 4)
+	ws.OnConnect = func() {
+		err := ws.Subscribe([]int{7}, ModeQuote)
+		if err == nil {
+			ws.Diagnostics()
+		}
+		results <- err
+	}
+
+	if err := connectWithin(t, ws); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-results; err != nil {
+		t.Fatalf("Subscribe from OnConnect: %v", err)
+	}
+	first := s.nextConn(t)
+	want := map[string][]int{ModeQuote: {7}}
+	if got := s.subscriptions(t, 1, 1); !equalSubscriptions(got, want) {
+		t.Fatalf("subscriptions from OnConnect = %v, want %v", got, want)
+	}
+
+	// OnConnect runs again on reconnect, from the read goroutine of the lost connection
+	first.Close()
+	select {
+	case err := <-results:
+		if err != nil {
+			t.Fatalf("Subscribe from OnConnect on reconnect: %v", err)
+		}
+	case <-time.After(testTimeout):
+		t.Fatal("OnConnect not called on reconnect, deadlocked?")
+	}
+	s.nextConn(t)
+	if got := s.subscriptions(t, 2, 1); !equalSubscriptions(got, want) {
+		t.Fatalf("subscriptions on reconnect = %v, want %v", got, want)
+	}
+}
+
+// waitDone fails the test unless ws is closed in time
+func waitDone(t *testing.T, ws *WS) {
+	t.Helper()
+	select {
+	case <-ws.Done():
+	case <-time.After(testTimeout):
+		t.Fatal("Close did not stop the client")
+	}
+}
+
+func TestWSCloseWhileDialing(t *testing.T) {
+	hold := make(chan struct{})
+	defer close(hold)
+	s := newTestServer(t, hold)
+	ws := newTestWS(t, s)
+
+	result := make(chan error, 1)
+	go func() { result <- ws.Connect() }()
+
+	// The handshake is blocked in the server
+	select {
+	case <-s.requests:
+	case <-time.After(testTimeout):
+		t.Fatal("no connection attempt")
+	}
+	ws.Close()
+
+	select {
+	case err := <-result:
+		if !errors.Is(err, ErrClosed) {
+			t.Fatalf("Connect = %v, want ErrClosed", err)
+		}
+	case <-time.After(testTimeout):
+		t.Fatal("Connect did not return after Close")
+	}
+	waitDone(t, ws)
+	if state := ws.State(); state != StateClosed {
+		t.Fatalf("state = %s, want closed", state)
+	}
+}
+
+func TestWSCloseWhileRetrying(t *testing.T) {
+	s := newTestServer(t, nil)
+	s.refuse.Store(true)
+	ws := newTestWS(t, s)
+	ws.RetryDelay = time.Hour
+
+	result := make(chan error, 1)
+	go func() { result <- ws.Connect() }()
+
+	select {
+	case <-s.requests:
+	case <-time.After(testTimeout):
+		t.Fatal("no connection attempt")
+	}
+	ws.Close()
+
+	select {
+	case err := <-result:
+		if !errors.Is(err, ErrClosed) {
+			t.Fatalf("Connect = %v, want ErrClosed", err)
+		}
+	case <-time.After(testTimeout):
+		t.Fatal("Connect kept retrying after Close")
+	}
+	waitDone(t, ws)
+}
+
+// TestWSConcurrentClose closes clients at various points of Connect while subscribing
+// concurrently, run it with -race
+func TestWSConcurrentClose(t *testing.T) {
+	s := newTestServer(t, nil)
+
+	for i := range 20 {
+		ws := newTestWS(t, s)
+		ws.OnConnect = func() { ws.Subscribe([]int{i + 1}, ModeLTP) }
+
+		result := make(chan error, 1)
+		go func() { result <- ws.Connect() }()
+		subscribed := make(chan error, 1)
+		go func() { subscribed <- ws.Subscribe([]int{100}, ModeFull) }()
+
+		time.Sleep(time.Duration(i) * 200 * time.Microsecond)
+		ws.Close()
+
+		select {
+		case err := <-result:
+			if err != nil && !errors.Is(err, ErrClosed) {
+				t.Fatalf("Connect = %v, want nil or ErrClosed", err)
+			}
+		case <-time.After(testTimeout):
+			t.Fatal("Connect did not return after Close")
+		}
+		if err := <-subscribed; err != nil && !errors.Is(err, ErrClosed) {
+			t.Fatalf("Subscribe = %v, want nil or ErrClosed", err)
+		}
+		waitDone(t, ws)
+		if err := ws.Subscribe([]int{1}, ModeLTP); !errors.Is(err, ErrClosed) {
+			t.Fatalf("Subscribe after Close = %v, want ErrClosed", err)
+		}
+		s.drain()
+	}
+}
+
+// recordingLogger is a Logger that is not backed by zerolog
+type recordingLogger struct {
+	mu     sync.Mutex
+	events map[string]map[string]any
+}
+
+func (l *recordingLogger) Log(level zerolog.Level, msg string, fields map[string]any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	fields["level"] = level
+	l.events[msg] = fields
+}
+
+func TestWSSetLogger(t *testing.T) {
+	s := newTestServer(t, nil)
+	ws := newTestWS(t, s)
+	logger := &recordingLogger{events: make(map[string]map[string]any)}
+	ws.SetLogger(logger)
+
+	if err := connectWithin(t, ws); err != nil {
+		t.Fatal(err)
+	}
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	connected, ok := logger.events["Connected to WebSocket"]
+	if !ok {
+		t.Fatalf("no connection event in %v", logger.events)
+	}
+	if connected["level"] != zerolog.InfoLevel || connected["compression"] != false {
+		t.Fatalf("connection event = %v, want info with compression false", connected)
+	}
+}
+
+// TestWSCloseWhilePacing closes the client while Subscribe waits between the batches of a
+// large subscription, holding ws.mu
+func TestWSCloseWhilePacing(t *testing.T) {
+	s := newTestServer(t, nil)
+	ws := newTestWS(t, s)
+	ws.SubscribeBatchSize = 1
+	ws.ControlInterval = time.Hour
+
+	if err := connectWithin(t, ws); err != nil {
+		t.Fatal(err)
+	}
+	s.nextConn(t)
+
+	subscribed := make(chan error, 1)
+	go func() { subscribed <- ws.Subscribe([]int{1, 2}, ModeLTP) }()
+	s.subscriptions(t, 1, 1)
+
+	closed := make(chan struct{})
+	go func() {
+		ws.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(testTimeout):
+		t.Fatal("Close blocked by the pacing of Subscribe")
+	}
+	if err := <-subscribed; !errors.Is(err, ErrClosed) {
+		t.Fatalf("Subscribe = %v, want ErrClosed", err)
+	}
+	waitDone(t, ws)
+}
@@ -0,0 +1,372 @@
+package tiqs
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Abhi13027/go-tiqs/ticks"
+)
+
+// Default settings of an AlertEngine.
+const (
+	DefaultAlertVolumeWindow = time.Minute // Window over which volume spikes are measured.
+	DefaultAlertEventsSize   = 256         // Capacity of the Events channel.
+)
+
+// alertVolumeWindows is the number of windows the average volume of a volume spike rule
+// spans, and alertVolumeWarmup the number of windows seen before a spike can be raised.
+const (
+	alertVolumeWindows = 20
+	alertVolumeWarmup  = 3
+)
+
+// AlertCondition is the condition of an AlertRule.
+type AlertCondition string
+
+const (
+	AlertPriceAbove  AlertCondition = "PRICE_ABOVE"  // The LTP crosses above Value, in rupees.
+	AlertPriceBelow  AlertCondition = "PRICE_BELOW"  // The LTP crosses below Value, in rupees.
+	AlertOIChange    AlertCondition = "OI_CHANGE"    // The open interest changes by Value percent, up or down, since the rule was added or last raised.
+	AlertVolumeSpike AlertCondition = "VOLUME_SPIKE" // The volume traded in Window reaches Value times the average of the previous windows.
+	AlertSpreadAbove AlertCondition = "SPREAD_ABOVE" // The best ask exceeds the best bid by more than Value, in rupees.
+)
+
+// AlertRule is a condition watched on the ticks of one instrument.
+type AlertRule struct {
+	ID        string         `json:"id"`                 // Identifier assigned by the engine.
+	Token     int64          `json:"token"`              // Instrument whose ticks are watched.
+	Condition AlertCondition `json:"condition"`          // The condition.
+	Value     float64        `json:"value"`              // Threshold of the condition, in its unit.
+	Window    time.Duration  `json:"window,omitempty"`   // Window of AlertVolumeSpike; zero uses DefaultAlertVolumeWindow.
+	Cooldown  time.Duration  `json:"cooldown,omitempty"` // Minimum delay before the rule is raised again.
+	Once      bool           `json:"once,omitempty"`     // Whether the rule is removed once raised.
+	Note      string         `json:"note,omitempty"`     // Free text carried by the events of the rule.
+}
+
+// AlertEvent is raised when the condition of a rule is met.
+type AlertEvent struct {
+	Rule     AlertRule `json:"rule"`     // The rule raised.
+	Observed float64   `json:"observed"` // The value that met the condition: price, percent change, volume ratio or spread.
+	LTP      float64   `json:"ltp"`      // Last traded price of the instrument, in rupees.
+	Time     time.Time `json:"time"`     // Time of the event.
+}
+
+// alertState is a rule with what the engine remembers of its instrument.
+type alertState struct {
+	rule     AlertRule
+	primed   bool      // Whether a tick was seen, so crossings can be detected.
+	active   bool      // Whether the condition held on the previous tick.
+	last     time.Time // Time the rule was last raised.
+	refOI    int32     // Reference open interest of AlertOIChange.
+	winStart time.Time // Start of the current volume window.
+	winVol   int64     // Cumulative volume at the start of the current window.
+	avgVol   float64   // Average volume per window.
+	windows  int       // Completed volume windows.
+	raised   bool      // Whether a spike was raised in the current window.
+}
+
+// AlertEngine evaluates alert rules against live ticks and raises an event when a rule's
+// condition is met.
+//
+// Rules are indexed by token, so each tick is checked against the rules of its own
+// instrument only. Price rules raise on crossings, when the LTP moves from one side of
+// the level to the other; spread rules raise when the spread widens past the level, not
+// on every tick it stays wide. OI rules need quote or full mode ticks, and spread rules
+// full mode ticks, which carry depth.
+//
+// Events are passed to OnAlert and sent on Events; events are dropped, with a warning,
+// while the channel is full.
+type AlertEngine struct {
+	OnAlert func(AlertEvent) // Optional callback for every event, called from Update.
+
+	prices *PriceConverter
+	mu     sync.Mutex
+	rules  map[int64][]*alertState
+	nextID int
+	events chan AlertEvent
+}
+
+// NewAlertEngine creates an engine without rules.
+//
+// Parameters:
+//   - prices: The converter of tick prices into rupees, e.g., Client.PriceConverter();
+//     nil assumes paise.
+//
+// Returns:
+//   - A pointer to a newly created AlertEngine.
+func NewAlertEngine(prices *PriceConverter) *AlertEngine {
+	if prices == nil {
+		prices = NewPriceConverter(nil)
+	}
+	return &AlertEngine{
+		prices: prices,
+		rules:  make(map[int64][]*alertState),
+		events: make(chan AlertEvent, DefaultAlertEventsSize),
+	}
+}
+
+// AlertEngine returns an engine without rules, converting prices with the instrument store
+// attached to the client.
+func (c *Client) AlertEngine() *AlertEngine {
+	return NewAlertEngine(c.PriceConverter())
+}
+
+// Events returns the channel on which events are sent.
+func (e *AlertEngine) Events() <-chan AlertEvent {
+	return e.events
+}
+
+// Add registers a rule.
+//
+// Parameters:
+//   - rule: The rule; its ID is assigned by the engine.
+//
+// Returns:
+//   - The ID of the rule.
+//   - An error if the rule is invalid.
+func (e *AlertEngine) Add(rule AlertRule) (string, error) {
+	switch rule.Condition {
+	case AlertPriceAbove, AlertPriceBelow, AlertSpreadAbove:
+		if rule.Value <= 0 {
+			return "", fmt.Errorf("invalid %s level: %v", rule.Condition, rule.Value)
+		}
+	case AlertOIChange:
+		if rule.Value <= 0 {
+			return "", fmt.Errorf("invalid OI change percent: %v", rule.Value)
+		}
+	case AlertVolumeSpike:
+		if rule.Value <= 1 {
+			return "", fmt.Errorf("volume spike ratio must be above 1: %v", rule.Value)
+		}
+		if rule.Window <= 0 {
+			rule.Window = DefaultAlertVolumeWindow
+		}
+	default:
+		return "", fmt.Errorf("unknown alert condition %q", rule.Condition)
+	}
+	if rule.Token <= 0 {
+		return "", fmt.Errorf("invalid alert token: %d", rule.Token)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.nextID++
+	rule.ID = strconv.Itoa(e.nextID)
+	e.rules[rule.Token] = append(e.rules[rule.Token], &alertState{rule: rule})
+	return rule.ID, nil
+}
+
+// Remove unregisters a rule.
+//
+// Returns:
+//   - True if the rule was registered.
+func (e *AlertEngine) Remove(id string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.removeLocked(id)
+}
+
+// removeLocked unregisters a rule. The caller must hold e.mu.
+func (e *AlertEngine) removeLocked(id string) bool {
+	for token, states := range e.rules {
+		for i, s := range states {
+			if s.rule.ID != id {
+				continue
+			}
+			states = append(states[:i], states[i+1:]...)
+			if len(states) == 0 {
+				delete(e.rules, token)
+			} else {
+				e.rules[token] = states
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// Rules returns the registered rules, ordered by ID.
+func (e *AlertEngine) Rules() []AlertRule {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var rules []AlertRule
+	for _, states := range e.rules {
+		for _, s := range states {
+			rules = append(rules, s.rule)
+		}
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		a, _ := strconv.Atoi(rules[i].ID)
+		b, _ := strconv.Atoi(rules[j].ID)
+		return a < b
+	})
+	return rules
+}
+
+// Tokens returns the tokens with rules, to subscribe them on the websocket.
+func (e *AlertEngine) Tokens() []int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	tokens := make([]int, 0, len(e.rules))
+	for token := range e.rules {
+		tokens = append(tokens, int(token))
+	}
+	sort.Ints(tokens)
+	return tokens
+}
+
+// Update evaluates the rules of the tick's instrument and returns the events raised.
+func (e *AlertEngine) Update(tick ticks.TickData) []AlertEvent {
+	token := int64(tick.Token)
+	divisor := e.prices.Divisor(token)
+	ltp := float64(tick.LTP) / divisor
+	now := time.Now()
+
+	e.mu.Lock()
+	var events []AlertEvent
+	for _, s := range e.rules[token] {
+		observed, ok := s.evaluate(tick, ltp, divisor, now)
+		if !ok || (s.rule.Cooldown > 0 && now.Sub(s.last) < s.rule.Cooldown) {
+			continue
+		}
+		s.last = now
+		events = append(events, AlertEvent{Rule: s.rule, Observed: observed, LTP: ltp, Time: now})
+	}
+	for _, event := range events {
+		if event.Rule.Once {
+			e.removeLocked(event.Rule.ID)
+		}
+	}
+	e.mu.Unlock()
+
+	for _, event := range events {
+		defaultLog().Info().
+			Str("id", event.Rule.ID).
+			Str("condition", string(event.Rule.Condition)).
+			Int64("token", token).
+			Float64("observed", event.Observed).
+			Msg("Alert raised")
+		if e.OnAlert != nil {
+			e.OnAlert(event)
+		}
+		select {
+		case e.events <- event:
+		default:
+			defaultLog().Warn().Str("id", event.Rule.ID).Msg("Alert events channel is full, dropping event")
+		}
+	}
+	return events
+}
+
+// Run feeds ticks from source into Update until the context is cancelled or source closes.
+func (e *AlertEngine) Run(ctx context.Context, source <-chan ticks.TickData) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case tick, ok := <-source:
+			if !ok {
+				return nil
+			}
+			e.Update(tick)
+		}
+	}
+}
+
+// evaluate updates the state of a rule with a tick and reports whether it is raised,
+// with the observed value.
+func (s *alertState) evaluate(tick ticks.TickData, ltp, divisor float64, now time.Time) (float64, bool) {
+	switch s.rule.Condition {
+	case AlertPriceAbove, AlertPriceBelow:
+		if ltp <= 0 {
+			return 0, false
+		}
+		above := ltp > s.rule.Value
+		if s.rule.Condition == AlertPriceBelow {
+			above = ltp < s.rule.Value
+		}
+		return ltp, s.transition(above, true)
+
+	case AlertSpreadAbove:
+		bid, ask := tick.MarketDepth.Bids[0], tick.MarketDepth.Asks[0]
+		if bid.Quantity == 0 || ask.Quantity == 0 {
+			return 0, false
+		}
+		spread := float64(ask.Price-bid.Price) / divisor
+		return spread, s.transition(spread > s.rule.Value, false)
+
+	case AlertOIChange:
+		if tick.OI <= 0 {
+			return 0, false
+		}
+		if s.refOI <= 0 {
+			s.refOI = tick.OI
+			return 0, false
+		}
+		change := float64(tick.OI-s.refOI) / float64(s.refOI) * 100
+		if math.Abs(change) < s.rule.Value {
+			return change, false
+		}
+		s.refOI = tick.OI
+		return change, true
+
+	case AlertVolumeSpike:
+		return s.volumeSpike(tick.Volume, now)
+	}
+	return 0, false
+}
+
+// transition records whether the condition holds and reports whether it just started to.
+// With needPrime, the first tick only records the state, so a level already crossed when
+// the rule was added is not raised.
+func (s *alertState) transition(holds, needPrime bool) bool {
+	primed := s.primed
+	was := s.active
+	s.primed, s.active = true, holds
+	if needPrime && !primed {
+		return false
+	}
+	return holds && !was
+}
+
+// volumeSpike tracks the volume traded per window and reports whether the current window
+// has reached the spike ratio, at most once per window.
+func (s *alertState) volumeSpike(volume int64, now time.Time) (float64, bool) {
+	if volume <= 0 {
+		return 0, false
+	}
+	if s.winStart.IsZero() {
+		s.winStart, s.winVol = now, volume
+		return 0, false
+	}
+
+	// Close the windows that elapsed; windows without ticks count as empty.
+	for now.Sub(s.winStart) >= s.rule.Window {
+		traded := float64(volume - s.winVol)
+		if s.windows > 0 || traded > 0 {
+			n := float64(min(s.windows, alertVolumeWindows-1))
+			s.avgVol = (s.avgVol*n + traded) / (n + 1)
+			s.windows++
+		}
+		s.winStart = s.winStart.Add(s.rule.Window)
+		s.winVol = volume
+		s.raised = false
+	}
+
+	if s.windows < alertVolumeWarmup || s.avgVol <= 0 || s.raised {
+		return 0, false
+	}
+	ratio := float64(volume-s.winVol) / s.avgVol
+	if ratio < s.rule.Value {
+		return ratio, false
+	}
+	s.raised = true
+	return ratio, true
+}
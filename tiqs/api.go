@@ -0,0 +1,55 @@
+package tiqs
+
+// OrderAPI is the part of the API placing and tracking orders.
+type OrderAPI interface {
+	PlaceOrder(orderType string, order OrderRequest) (*OrderResponse, error)
+	ModifyOrder(orderType, orderID string, order OrderRequest) (*OrderResponse, error)
+	CancelOrder(orderType, orderID string) error
+	GetOrder(orderID string) (*OrderDetailsResponse, error)
+	GetOrderBook() ([]OrderBookEntry, error)
+	GetTradeBook() ([]Trade, error)
+}
+
+// PortfolioAPI is the part of the API reporting the account: positions, holdings, funds
+// and margins.
+type PortfolioAPI interface {
+	GetUserDetails() (*User, error)
+	GetPositions() ([]Position, error)
+	ConvertPosition(req ConvertPositionRequest) (*ConvertPositionResponse, error)
+	GetHoldings() ([]Holding, error)
+	GetLimits() (*Limits, error)
+	GetMargin(order MarginRequest) (*OrderMargin, error)
+	GetBasketMargin(order BasketMarginRequest) (*BasketOrderMargin, error)
+}
+
+// MarketDataAPI is the part of the API serving quotes, candles and reference data.
+type MarketDataAPI interface {
+	GetMarketQuote(token int64, mode string) (*MarketQuote, error)
+	GetMarketQuotes(tokens []int64, mode string) ([]MarketQuote, error)
+	GetLTPQuote(token int64) (*LTPQuote, error)
+	GetLTPQuotes(tokens []int64) ([]LTPQuote, error)
+	GetFullQuote(token int64) (*FullQuote, error)
+	GetFullQuotes(tokens []int64) ([]FullQuote, error)
+	GetHistoricalData(exchange, token, interval, from, to string, includeOI bool) ([]HistoricalCandle, error)
+	GetHistoricalCandles(exchange, token, interval, from, to string, includeOI bool) ([]DecimalCandle, error)
+	GetOptionChain(params OptionChainParams) (*OptionChainResponse, error)
+	GetOptionChainSymbol() (*OptionChainSymbolResponse, error)
+	GetIndexList() (*IndexListResponse, error)
+	GetHolidays() (*HolidaysResponse, error)
+	GetInstrumentList() ([]Instrument, error)
+}
+
+// API is the REST API of Tiqs as implemented by Client.
+//
+// Strategies depending on API, or on the narrower OrderAPI, PortfolioAPI and
+// MarketDataAPI, can be tested against a fake implementation, or against a Client
+// pointed at the mock server of package tiqstest.
+type API interface {
+	OrderAPI
+	PortfolioAPI
+	MarketDataAPI
+
+	Authenticate(requestToken string) (string, error)
+}
+
+var _ API = (*Client)(nil)
@@ -0,0 +1,286 @@
+package tiqs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// APIVersion identifies a version of the Tiqs REST API.
+type APIVersion string
+
+const (
+	APIVersion1 APIVersion = "v1" // The original, unprefixed API; used when Config.APIVersion is empty.
+)
+
+// EndpointName identifies an API operation independently of its path in a given version.
+type EndpointName string
+
+const (
+	EndpointAuthenticate       EndpointName = "auth.authenticate"
+	EndpointHoldings           EndpointName = "user.holdings"
+	EndpointLimits             EndpointName = "user.limits"
+	EndpointPositions          EndpointName = "user.positions"
+	EndpointConvertPosition    EndpointName = "position.convert"
+	EndpointTrades             EndpointName = "user.trades"
+	EndpointUserDetails        EndpointName = "user.details"
+	EndpointOrderBook          EndpointName = "user.orders"
+	EndpointLedger             EndpointName = "user.ledger"
+	EndpointPayin              EndpointName = "funds.payin"
+	EndpointPayout             EndpointName = "funds.payout"
+	EndpointFundTransactions   EndpointName = "funds.transactions"
+	EndpointPlaceOrder         EndpointName = "order.place"
+	EndpointModifyOrder        EndpointName = "order.modify"
+	EndpointCancelOrder        EndpointName = "order.cancel"
+	EndpointOrderHistory       EndpointName = "order.history"
+	EndpointHolidays           EndpointName = "info.holidays"
+	EndpointIndexList          EndpointName = "info.indexList"
+	EndpointOptionChainSymbols EndpointName = "info.optionChainSymbols"
+	EndpointOptionChain        EndpointName = "info.optionChain"
+	EndpointQuote              EndpointName = "info.quote"
+	EndpointQuotes             EndpointName = "info.quotes"
+	EndpointOrderMargin        EndpointName = "margin.order"
+	EndpointBasketMargin       EndpointName = "margin.basket"
+	EndpointInstruments        EndpointName = "instruments"
+	EndpointCandles            EndpointName = "candles"
+)
+
+// ResponseDecoder decodes the body of a response into v, adapting the response shape of
+// an API version to the types of the SDK.
+type ResponseDecoder func(body []byte, v any) error
+
+// EndpointSpec describes an endpoint in a given API version.
+type EndpointSpec struct {
+	Path   string          // Path relative to BaseURL, as a fmt format receiving the path arguments in order.
+	Decode ResponseDecoder // Decoder of the response body; nil decodes plain JSON. Unused by streamed endpoints.
+}
+
+// endpointRegistry maps every API version to the specs of its endpoints.
+var endpointRegistry = struct {
+	sync.RWMutex
+	specs map[APIVersion]map[EndpointName]EndpointSpec
+}{specs: map[APIVersion]map[EndpointName]EndpointSpec{
+	APIVersion1: v1Endpoints,
+}}
+
+// v1Endpoints are the endpoints of the original API.
+var v1Endpoints = map[EndpointName]EndpointSpec{
+	EndpointAuthenticate:       {Path: "/auth/app/authenticate-token"},
+	EndpointHoldings:           {Path: "/user/holdings"},
+	EndpointLimits:             {Path: "/user/limits"},
+	EndpointPositions:          {Path: "/user/positions"},
+	EndpointConvertPosition:    {Path: "/position/convert"},
+	EndpointTrades:             {Path: "/user/trades"},
+	EndpointUserDetails:        {Path: "/user/details"},
+	EndpointOrderBook:          {Path: "/user/orders"},
+	EndpointLedger:             {Path: "/user/ledger?from=%s&to=%s"},
+	EndpointPayin:              {Path: "/funds/payin"},
+	EndpointPayout:             {Path: "/funds/payout"},
+	EndpointFundTransactions:   {Path: "/funds/transactions?from=%s&to=%s"},
+	EndpointPlaceOrder:         {Path: "/order/%s"},
+	EndpointModifyOrder:        {Path: "/order/%s/%s"},
+	EndpointCancelOrder:        {Path: "/order/%s/%s"},
+	EndpointOrderHistory:       {Path: "/order/%s"},
+	EndpointHolidays:           {Path: "/info/holidays"},
+	EndpointIndexList:          {Path: "/info/index-list"},
+	EndpointOptionChainSymbols: {Path: "/info/option-chain-symbols"},
+	EndpointOptionChain:        {Path: "/info/option-chain"},
+	EndpointQuote:              {Path: "/info/quote/%s"},
+	EndpointQuotes:             {Path: "/info/quotes/%s"},
+	EndpointOrderMargin:        {Path: "/margin/order"},
+	EndpointBasketMargin:       {Path: "/margin/basket"},
+	EndpointInstruments:        {Path: "/all"},
+	EndpointCandles:            {Path: "/candle/%s/%s/%s?from=%s&to=%s"},
+}
+
+// RegisterEndpoint adds or replaces an endpoint of an API version, e.g., to follow a
+// broker change before the SDK catches up or to add a new version side by side.
+//
+// Endpoints missing from a version fall back to their APIVersion1 spec, so a new version
+// only needs to register what changed.
+//
+// Parameters:
+//   - version: The API version.
+//   - name: The endpoint.
+//   - spec: The path and decoder of the endpoint in that version.
+func RegisterEndpoint(version APIVersion, name EndpointName, spec EndpointSpec) {
+	endpointRegistry.Lock()
+	defer endpointRegistry.Unlock()
+
+	specs, ok := endpointRegistry.specs[version]
+	if !ok {
+		specs = make(map[EndpointName]EndpointSpec)
+		endpointRegistry.specs[version] = specs
+	}
+	specs[name] = spec
+}
+
+// APIVersions returns the registered API versions in ascending order.
+func APIVersions() []APIVersion {
+	endpointRegistry.RLock()
+	defer endpointRegistry.RUnlock()
+
+	versions := make([]APIVersion, 0, len(endpointRegistry.specs))
+	for version := range endpointRegistry.specs {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+	return versions
+}
+
+// LookupEndpoint returns the spec of an endpoint in an API version, falling back to
+// APIVersion1 for endpoints the version does not override.
+//
+// Returns:
+//   - The spec and true if the endpoint is known.
+func LookupEndpoint(version APIVersion, name EndpointName) (EndpointSpec, bool) {
+	endpointRegistry.RLock()
+	defer endpointRegistry.RUnlock()
+
+	if spec, ok := endpointRegistry.specs[version][name]; ok {
+		return spec, true
+	}
+	spec, ok := endpointRegistry.specs[APIVersion1][name]
+	return spec, ok
+}
+
+// apiVersion returns the API version selected by the configuration.
+func (c *Client) apiVersion() APIVersion {
+	if c.Config.APIVersion == "" {
+		return APIVersion1
+	}
+	return c.Config.APIVersion
+}
+
+// endpoint returns the path of an endpoint in the configured API version.
+func (c *Client) endpoint(name EndpointName, args ...any) string {
+	spec, ok := LookupEndpoint(c.apiVersion(), name)
+	if !ok {
+		// Every name used by the SDK is registered for APIVersion1.
+		panic(fmt.Sprintf("tiqs: unknown endpoint %q", name))
+	}
+	if len(args) == 0 {
+		return spec.Path
+	}
+	return fmt.Sprintf(spec.Path, args...)
+}
+
+// decode decodes a response body of an endpoint with the decoder of the configured API version.
+func (c *Client) decode(name EndpointName, body []byte, v any) error {
+	spec, _ := LookupEndpoint(c.apiVersion(), name)
+	if spec.Decode != nil {
+		return spec.Decode(body, v)
+	}
+	return json.Unmarshal(body, v)
+}
+
+// CompatibilityResult is the outcome of decoding one recorded response.
+type CompatibilityResult struct {
+	Version  APIVersion   // API version of the fixture.
+	Endpoint EndpointName // Endpoint the response was recorded from.
+	File     string       // Path of the fixture.
+	Err      error        // Decoding error, or nil if the response decodes and reports success.
+}
+
+// apiResponse is the envelope of responses decoded into anonymous structs by the client.
+type apiResponse[T any] struct {
+	Status string `json:"status"`
+	Data   T      `json:"data"`
+}
+
+// compatibilityTargets returns a fresh value of the response type of every endpoint
+// with a buffered JSON response.
+var compatibilityTargets = map[EndpointName]func() any{
+	EndpointAuthenticate:       func() any { return new(AuthResponse) },
+	EndpointHoldings:           func() any { return new(HoldingsResponse) },
+	EndpointLimits:             func() any { return new(Limits) },
+	EndpointPositions:          func() any { return new(PositionsResponse) },
+	EndpointConvertPosition:    func() any { return new(ConvertPositionResponse) },
+	EndpointTrades:             func() any { return new(TradeBookResponse) },
+	EndpointUserDetails:        func() any { return new(User) },
+	EndpointOrderBook:          func() any { return new(OrderDetailsResponse) },
+	EndpointLedger:             func() any { return new(LedgerResponse) },
+	EndpointPayin:              func() any { return new(PayinResponse) },
+	EndpointPayout:             func() any { return new(PayoutResponse) },
+	EndpointFundTransactions:   func() any { return new(FundTransactionsResponse) },
+	EndpointPlaceOrder:         func() any { return new(OrderResponse) },
+	EndpointModifyOrder:        func() any { return new(OrderResponse) },
+	EndpointCancelOrder:        func() any { return new(apiResponse[json.RawMessage]) },
+	EndpointOrderHistory:       func() any { return new(OrderDetailsResponse) },
+	EndpointHolidays:           func() any { return new(HolidaysResponse) },
+	EndpointIndexList:          func() any { return new(IndexListResponse) },
+	EndpointOptionChainSymbols: func() any { return new(OptionChainSymbolResponse) },
+	EndpointOptionChain:        func() any { return new(OptionChainResponse) },
+	EndpointQuote:              func() any { return new(apiResponse[MarketQuote]) },
+	EndpointQuotes:             func() any { return new(apiResponse[[]MarketQuote]) },
+	EndpointOrderMargin:        func() any { return new(OrderMargin) },
+	EndpointBasketMargin:       func() any { return new(BasketOrderMargin) },
+}
+
+// CheckCompatibility decodes recorded responses with the decoders of every API version,
+// forming a compatibility matrix that shows which versions the SDK types still understand.
+//
+// Fixtures are laid out as "<version>/<endpoint>.json" (e.g., "v1/user.holdings.json"),
+// one recorded response body per file; golden files of CheckGoldenResponses are skipped.
+// A fixture fails if it cannot be decoded or if its status is not "success".
+//
+// Parameters:
+//   - fixtures: The file system holding the fixtures, e.g., os.DirFS("testdata/compat").
+//
+// Returns:
+//   - One result per fixture, sorted by version and endpoint.
+//   - An error if the fixtures cannot be listed.
+func CheckCompatibility(fixtures fs.FS) ([]CompatibilityResult, error) {
+	files, err := goldenInputs(fixtures)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]CompatibilityResult, 0, len(files))
+	for _, file := range files {
+		version := APIVersion(path.Dir(file))
+		name := EndpointName(strings.TrimSuffix(path.Base(file), ".json"))
+		result := CompatibilityResult{Version: version, Endpoint: name, File: file}
+
+		target, ok := compatibilityTargets[name]
+		if !ok {
+			result.Err = fmt.Errorf("unknown endpoint %q", name)
+			results = append(results, result)
+			continue
+		}
+		body, err := fs.ReadFile(fixtures, file)
+		if err != nil {
+			result.Err = err
+			results = append(results, result)
+			continue
+		}
+
+		client := &Client{Config: Config{APIVersion: version}}
+		v := target()
+		if err := client.decode(name, body, v); err != nil {
+			result.Err = fmt.Errorf("error decoding response: %w", err)
+		} else if status := responseStatus(v); status != "success" {
+			result.Err = fmt.Errorf("unexpected status %q", status)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// responseStatus returns the Status field of a decoded response, re-encoding it to
+// avoid depending on the concrete type.
+func responseStatus(v any) string {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	var envelope struct {
+		Status string `json:"status"`
+	}
+	json.Unmarshal(raw, &envelope)
+	return envelope.Status
+}
@@ -0,0 +1,39 @@
+package tiqs_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/Abhi13027/go-tiqs/tiqs"
+)
+
+// TestCompatibility decodes the recorded responses of every registered API version. A
+// version without fixtures fails, so a version cannot be registered without recordings
+// of its responses.
+func TestCompatibility(t *testing.T) {
+	results, err := tiqs.CheckCompatibility(os.DirFS("testdata/compat"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	byVersion := make(map[tiqs.APIVersion][]tiqs.CompatibilityResult)
+	for _, r := range results {
+		byVersion[r.Version] = append(byVersion[r.Version], r)
+	}
+
+	for _, version := range tiqs.APIVersions() {
+		t.Run(string(version), func(t *testing.T) {
+			if len(byVersion[version]) == 0 {
+				t.Fatalf("no fixtures in testdata/compat/%s", version)
+			}
+			for _, r := range byVersion[version] {
+				if r.Err != nil {
+					t.Errorf("%s: %v", r.Endpoint, r.Err)
+				}
+			}
+		})
+		delete(byVersion, version)
+	}
+	for version := range byVersion {
+		t.Errorf("fixtures of unregistered API version %s", version)
+	}
+}
@@ -2,8 +2,6 @@
 package tiqs
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"net/url"
@@ -11,7 +9,6 @@ import (
 
 	"github.com/pquerna/otp"
 	"github.com/pquerna/otp/totp"
-	"github.com/rs/zerolog/log"
 )
 
 // AuthResponse represents the structure of the authentication response from the API.
@@ -37,14 +34,15 @@ type AuthResponse struct {
 // Returns:
 //   - A SHA256 checksum string.
 func GenerateChecksum(appID, appSecret, requestToken string) string {
-	data := fmt.Sprintf("%s:%s:%s", appID, appSecret, requestToken)
-	hash := sha256.Sum256([]byte(data))
-	return hex.EncodeToString(hash[:])
+	return SHA256Signer{}.Sign(appID, appSecret, requestToken)
 }
 
 // Authenticate exchanges the request token for an access token.
 //
 // This function sends a POST request to authenticate the user and obtain an API token.
+// The checksum is computed with the client's Signer (see SetSigner), which defaults to
+// the scheme of GenerateChecksum. The new session is saved to the token store, if one is
+// set (see SetTokenStore).
 //
 // Parameters:
 //   - requestToken: The temporary token received after user login.
@@ -53,7 +51,7 @@ func GenerateChecksum(appID, appSecret, requestToken string) string {
 //   - A string containing the authentication token if successful.
 //   - An error if authentication fails.
 func (c *Client) Authenticate(requestToken string) (string, error) {
-	checksum := GenerateChecksum(c.Config.AppID, c.Config.AppSecret, requestToken)
+	checksum := c.getSigner().Sign(c.Config.AppID, c.Config.AppSecret, requestToken)
 
 	payload := fmt.Sprintf(`{
 		"checkSum": "%s",
@@ -61,20 +59,20 @@ func (c *Client) Authenticate(requestToken string) (string, error) {
 		"appId": "%s"
 	}`, checksum, requestToken, c.Config.AppID)
 
-	responseBody, err := c.request("/auth/app/authenticate-token", "POST", []byte(payload))
+	responseBody, err := c.request(c.endpoint(EndpointAuthenticate), "POST", []byte(payload))
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to authenticate")
+		c.log().Error().Err(err).Msg("Failed to authenticate")
 		return "", err
 	}
 
 	var authResponse AuthResponse
-	if err := json.Unmarshal(responseBody, &authResponse); err != nil {
-		log.Error().Err(err).Msg("Failed to parse authentication response")
+	if err := c.decode(EndpointAuthenticate, responseBody, &authResponse); err != nil {
+		c.log().Error().Err(err).Msg("Failed to parse authentication response")
 		return "", err
 	}
 
 	if authResponse.Status != "success" {
-		return "", fmt.Errorf("authentication failed: %s", authResponse.Status)
+		return "", newAPIError("authentication", "/auth/app/authenticate-token", 0, responseBody)
 	}
 
 	// Update client token after authentication
@@ -82,8 +80,9 @@ func (c *Client) Authenticate(requestToken string) (string, error) {
 	if authResponse.Data.RefreshToken != "" {
 		c.Config.RefreshToken = authResponse.Data.RefreshToken
 	}
+	c.saveSession(&authResponse)
 
-	log.Info().Str("userID", authResponse.Data.UserID).Msg("Authentication successful")
+	c.log().Info().Str("userID", authResponse.Data.UserID).Msg("Authentication successful")
 	return authResponse.Data.Token, nil
 }
 
@@ -101,13 +100,12 @@ func (c *Client) Login() {
 	fmt.Print("Enter Request Token: ")
 	fmt.Scanln(&requestToken)
 
-	token, err := c.Authenticate(requestToken)
-	if err != nil {
-		log.Error().Err(err).Msg("Login authentication failed")
+	if _, err := c.Authenticate(requestToken); err != nil {
+		c.log().Error().Err(err).Msg("Login authentication failed")
 		return
 	}
 
-	fmt.Println("✅ Authentication successful! Token:", token)
+	fmt.Println("✅ Authentication successful!")
 }
 
 // AutoLogin handles the entire authentication flow automatically using credentials.
@@ -138,7 +136,7 @@ func (c *Client) AutoLogin(username, password, totpSecret string) error {
 
 	resp, err := c.rawRequest(loginURL, "POST", []byte(payload))
 	if err != nil {
-		log.Error().Err(err).Msg("Login request failed")
+		c.log().Error().Err(err).Msg("Login request failed")
 		return err
 	}
 
@@ -149,14 +147,14 @@ func (c *Client) AutoLogin(username, password, totpSecret string) error {
 	}
 
 	if err := json.Unmarshal(resp, &loginResp); err != nil {
-		log.Error().Err(err).Msg("Failed to parse login response")
+		c.log().Error().Err(err).Msg("Failed to parse login response")
 		return err
 	}
 
 	// Step 2: Generate TOTP Code
 	passcode, err := generateTOTP(totpSecret)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to generate TOTP code")
+		c.log().Error().Err(err).Msg("Failed to generate TOTP code")
 		return err
 	}
 
@@ -169,7 +167,7 @@ func (c *Client) AutoLogin(username, password, totpSecret string) error {
 
 	resp, err = c.rawRequest("https://api.tiqs.in/auth/validate-2fa", "POST", []byte(totpPayload))
 	if err != nil {
-		log.Error().Err(err).Msg("2FA validation failed")
+		c.log().Error().Err(err).Msg("2FA validation failed")
 		return err
 	}
 
@@ -180,27 +178,26 @@ func (c *Client) AutoLogin(username, password, totpSecret string) error {
 	}
 
 	if err := json.Unmarshal(resp, &totpResp); err != nil {
-		log.Error().Err(err).Msg("Failed to parse 2FA response")
+		c.log().Error().Err(err).Msg("Failed to parse 2FA response")
 		return err
 	}
 
 	// Step 4: Extract Request Token from Redirect URL
 	parsedURL, err := url.Parse(totpResp.Data.RedirectURL)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to parse redirect URL")
+		c.log().Error().Err(err).Msg("Failed to parse redirect URL")
 		return err
 	}
 
 	requestToken := parsedURL.Query().Get("request-token")
 
 	// Step 5: Authenticate and Get Access Token
-	token, err := c.Authenticate(requestToken)
-	if err != nil {
-		log.Error().Err(err).Msg("Authentication failed")
+	if _, err := c.Authenticate(requestToken); err != nil {
+		c.log().Error().Err(err).Msg("Authentication failed")
 		return err
 	}
 
-	fmt.Println("✅ AutoLogin successful! Token:", token)
+	c.log().Info().Msg("AutoLogin successful")
 	return nil
 }
 
@@ -0,0 +1,478 @@
+package tiqs
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Abhi13027/go-tiqs/ticks"
+)
+
+// HedgeMode controls what an AutoHedger does once a threshold is breached.
+type HedgeMode string
+
+const (
+	HedgeDryRun  HedgeMode = "DRY_RUN" // Report the hedge without placing it.
+	HedgeConfirm HedgeMode = "CONFIRM" // Place the hedge only if the Confirm callback approves it.
+	HedgeLive    HedgeMode = "LIVE"    // Place the hedge immediately.
+)
+
+// HedgeInstrument selects what an AutoHedger trades to protect a position.
+type HedgeInstrument string
+
+const (
+	HedgeWithOptions HedgeInstrument = "OPTIONS" // Buy out-of-the-money options of the threatened side.
+	HedgeWithFutures HedgeInstrument = "FUTURES" // Trade the nearest future against the net delta.
+)
+
+// Default settings of an AutoHedger.
+const (
+	DefaultHedgeInterval       = 30 * time.Second
+	DefaultHedgeCooldown       = 5 * time.Minute
+	DefaultHedgeStrikeDistance = 2
+)
+
+// HedgeThresholds defines when the short options of an underlying are hedged.
+//
+// Zero fields are disabled; a breach of any enabled threshold triggers a hedge.
+type HedgeThresholds struct {
+	MaxLoss    float64 // Loss in rupees across the positions of the underlying.
+	MaxDelta   float64 // Absolute net delta in units of the underlying.
+	MaxMovePct float64 // Move of the underlying from its reference price, in percent.
+}
+
+// UnderlyingExposure represents the risk of the positions held in one underlying.
+type UnderlyingExposure struct {
+	Underlying     string   `json:"underlying"`     // Underlying symbol (e.g., "NIFTY").
+	Exchange       string   `json:"exchange"`       // Exchange of the derivatives (e.g., "NFO").
+	Spot           float64  `json:"spot"`           // Last traded price of the underlying in rupees.
+	Reference      float64  `json:"reference"`      // Reference price the move is measured from.
+	MovePct        float64  `json:"movePct"`        // Signed move of the underlying from the reference, in percent.
+	Pnl            float64  `json:"pnl"`            // Profit and loss of the positions in rupees.
+	Delta          float64  `json:"delta"`          // Net delta in units of the underlying.
+	ShortCallUnits int64    `json:"shortCallUnits"` // Net short call units not covered by long calls.
+	ShortPutUnits  int64    `json:"shortPutUnits"`  // Net short put units not covered by long puts.
+	Breaches       []string `json:"breaches"`       // Thresholds breached, empty when within limits.
+
+	product    Product
+	nearCall   time.Time // Nearest expiry of the short calls.
+	nearPut    time.Time // Nearest expiry of the short puts.
+	underlying int64
+}
+
+// HedgeAction represents a protective order decided by an AutoHedger.
+type HedgeAction struct {
+	Exposure   UnderlyingExposure `json:"exposure"`   // Exposure that breached the thresholds.
+	Instrument Instrument         `json:"instrument"` // Contract traded as the hedge.
+	Order      OrderRequest       `json:"order"`      // The hedge order.
+	Mode       HedgeMode          `json:"mode"`       // Mode the action was taken in.
+	Placed     bool               `json:"placed"`     // Whether the order was sent.
+	Declined   bool               `json:"declined"`   // Whether the Confirm callback declined the order.
+	OrderNo    string             `json:"orderNo"`    // Order number of the placed hedge.
+	Err        error              `json:"-"`          // Error returned while placing the order, if any.
+}
+
+// AutoHedger watches short option positions and buys protection when they breach thresholds.
+//
+// Positions are grouped by underlying; only underlyings with short options are monitored,
+// and all of their derivatives count towards the loss and delta, so hedges already in
+// place are taken into account. Deltas are computed with Black-Scholes from the position
+// LTPs and the price of the underlying. On a breach, the threatened side is derived from
+// the sign of the net delta (or the direction of the move when it is flat): option hedges
+// buy calls or puts StrikeDistance strikes out of the money in the nearest expiry of the
+// short legs, in the quantity left uncovered; futures hedges trade the nearest future to
+// bring the delta back towards zero. An underlying is not acted upon again within Cooldown,
+// whatever the mode, so dry runs and declined hedges are not repeated on every check.
+//
+// Instrument metadata comes from the attached instrument store (see LoadInstruments).
+type AutoHedger struct {
+	Thresholds     HedgeThresholds
+	Mode           HedgeMode
+	Instrument     HedgeInstrument
+	StrikeDistance int                    // Strikes out of the money of option hedges.
+	Cooldown       time.Duration          // Minimum delay between hedges of an underlying.
+	Interval       time.Duration          // Delay between checks of Run.
+	Tag            string                 // Tag the hedge orders are placed with.
+	Confirm        func(HedgeAction) bool // Approves hedges in HedgeConfirm mode; nil declines all.
+
+	client     *Client
+	actions    chan HedgeAction
+	mu         sync.Mutex
+	references map[string]float64
+	lastHedge  map[string]time.Time
+}
+
+// NewAutoHedger creates a hedger in dry-run mode hedging with options.
+//
+// Parameters:
+//   - client: The client used to read positions and place hedges.
+//   - thresholds: The thresholds triggering a hedge.
+//
+// Returns:
+//   - A pointer to a newly created AutoHedger.
+func NewAutoHedger(client *Client, thresholds HedgeThresholds) *AutoHedger {
+	return &AutoHedger{
+		Thresholds:     thresholds,
+		Mode:           HedgeDryRun,
+		Instrument:     HedgeWithOptions,
+		StrikeDistance: DefaultHedgeStrikeDistance,
+		Cooldown:       DefaultHedgeCooldown,
+		Interval:       DefaultHedgeInterval,
+		Tag:            "autohedge",
+		client:         client,
+		actions:        make(chan HedgeAction, 64),
+		references:     make(map[string]float64),
+		lastHedge:      make(map[string]time.Time),
+	}
+}
+
+// Actions returns the channel on which Run emits hedge actions.
+func (h *AutoHedger) Actions() <-chan HedgeAction {
+	return h.actions
+}
+
+// SetReference sets the price the move of an underlying is measured from. Without a
+// reference, the first price seen by the hedger is used.
+//
+// Parameters:
+//   - underlying: The underlying symbol (e.g., "BANKNIFTY").
+//   - price: The reference price in rupees.
+func (h *AutoHedger) SetReference(underlying string, price float64) {
+	h.mu.Lock()
+	h.references[strings.ToUpper(underlying)] = price
+	h.mu.Unlock()
+}
+
+// Run checks the positions every Interval until the context is cancelled, emitting the
+// actions taken. Failed checks are logged and retried. The action channel is closed when
+// Run returns.
+//
+// Returns:
+//   - The context error once the context is cancelled.
+func (h *AutoHedger) Run(ctx context.Context) error {
+	defer close(h.actions)
+
+	ticker := time.NewTicker(h.Interval)
+	defer ticker.Stop()
+
+	for {
+		actions, err := h.Check()
+		if err != nil {
+			h.client.log().Warn().Err(err).Msg("Auto hedger failed to check positions")
+		}
+		for _, action := range actions {
+			emit(ctx, h.actions, action)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check evaluates the positions once and hedges the underlyings breaching the thresholds.
+//
+// Returns:
+//   - The hedge actions taken, one per hedged underlying.
+//   - An error if no instrument store is attached or positions cannot be retrieved.
+func (h *AutoHedger) Check() ([]HedgeAction, error) {
+	exposures, err := h.Exposures()
+	if err != nil {
+		return nil, err
+	}
+
+	var actions []HedgeAction
+	for _, e := range exposures {
+		if len(e.Breaches) == 0 || h.coolingDown(e.Underlying) {
+			continue
+		}
+
+		h.client.log().Warn().Str("underlying", e.Underlying).Strs("breaches", e.Breaches).Float64("pnl", e.Pnl).
+			Float64("delta", e.Delta).Msg("Hedge thresholds breached")
+
+		action, err := h.plan(e)
+		if err != nil {
+			h.client.log().Error().Err(err).Str("underlying", e.Underlying).Msg("Failed to plan hedge")
+			continue
+		}
+		h.execute(&action)
+		if action.Err == nil {
+			h.mu.Lock()
+			h.lastHedge[e.Underlying] = time.Now()
+			h.mu.Unlock()
+		}
+		actions = append(actions, action)
+	}
+	return actions, nil
+}
+
+// Exposures computes the exposure of every underlying with short options.
+//
+// Returns:
+//   - The exposures, ordered by underlying, with the breached thresholds filled in.
+//   - An error if no instrument store is attached or positions cannot be retrieved.
+func (h *AutoHedger) Exposures() ([]UnderlyingExposure, error) {
+	store := h.client.instruments
+	if store == nil {
+		return nil, fmt.Errorf("auto hedging requires an instrument store")
+	}
+
+	positions, err := h.client.GetPositions()
+	if err != nil {
+		return nil, err
+	}
+
+	type leg struct {
+		position Position
+		inst     Instrument
+		units    int64
+	}
+	groups := make(map[string][]leg)
+	for _, p := range positions {
+		qty := parseInt(p.Qty)
+		if qty == 0 {
+			continue
+		}
+		inst, ok := store.Get(parseInt(p.Token))
+		if _, derivative := inst.Expiry(); !ok || !derivative || inst.UnderlyingToken == nil {
+			continue
+		}
+		key := strings.ToUpper(inst.Symbol)
+		groups[key] = append(groups[key], leg{position: p, inst: inst, units: inst.Units(qty)})
+	}
+
+	var exposures []UnderlyingExposure
+	for underlying, legs := range groups {
+		e := UnderlyingExposure{Underlying: underlying, Exchange: legs[0].inst.Exchange}
+		var calls, puts int64
+		for _, l := range legs {
+			if l.inst.IsOption() && l.units < 0 {
+				e.product = Product(l.position.Product)
+				e.underlying = parseInt(*l.inst.UnderlyingToken)
+			}
+		}
+		if e.underlying == 0 {
+			continue
+		}
+
+		quote, err := h.client.GetMarketQuoteDecimal(e.underlying, "ltp")
+		if err != nil {
+			h.client.log().Warn().Err(err).Str("underlying", underlying).Msg("Failed to quote underlying for hedging")
+			continue
+		}
+		e.Spot = quote.LTP
+
+		for _, l := range legs {
+			e.Pnl += parseFloat(l.position.Pnl)
+			if !l.inst.IsOption() {
+				e.Delta += float64(l.units)
+				continue
+			}
+
+			e.Delta += float64(l.units) * optionDelta(l.inst, parseFloat(l.position.Ltp), e.Spot)
+			expiry, _ := l.inst.Expiry()
+			if *l.inst.OptionType == "CE" {
+				calls += l.units
+				if l.units < 0 && (e.nearCall.IsZero() || expiry.Before(e.nearCall)) {
+					e.nearCall = expiry
+				}
+			} else {
+				puts += l.units
+				if l.units < 0 && (e.nearPut.IsZero() || expiry.Before(e.nearPut)) {
+					e.nearPut = expiry
+				}
+			}
+		}
+		e.ShortCallUnits = max(-calls, 0)
+		e.ShortPutUnits = max(-puts, 0)
+
+		e.Reference = h.reference(underlying, e.Spot)
+		if e.Reference > 0 {
+			e.MovePct = (e.Spot - e.Reference) / e.Reference * 100
+		}
+		e.Breaches = h.breaches(e)
+		exposures = append(exposures, e)
+	}
+
+	sort.Slice(exposures, func(i, j int) bool { return exposures[i].Underlying < exposures[j].Underlying })
+	return exposures, nil
+}
+
+// breaches returns the thresholds an exposure breaches.
+func (h *AutoHedger) breaches(e UnderlyingExposure) []string {
+	t := h.Thresholds
+	var breaches []string
+	if t.MaxLoss > 0 && -e.Pnl >= t.MaxLoss {
+		breaches = append(breaches, fmt.Sprintf("loss %.2f >= %.2f", -e.Pnl, t.MaxLoss))
+	}
+	if t.MaxDelta > 0 && math.Abs(e.Delta) >= t.MaxDelta {
+		breaches = append(breaches, fmt.Sprintf("delta %.2f beyond %.2f", e.Delta, t.MaxDelta))
+	}
+	if t.MaxMovePct > 0 && math.Abs(e.MovePct) >= t.MaxMovePct {
+		breaches = append(breaches, fmt.Sprintf("move %.2f%% beyond %.2f%%", e.MovePct, t.MaxMovePct))
+	}
+	return breaches
+}
+
+// plan selects the contract and quantity hedging an exposure.
+func (h *AutoHedger) plan(e UnderlyingExposure) (HedgeAction, error) {
+	// A negative delta loses on a rally, so the calls are threatened.
+	rising := e.Delta < 0 || (e.Delta == 0 && e.MovePct > 0)
+
+	var (
+		inst  Instrument
+		units int64
+		side  = TransactionBuy
+		err   error
+	)
+	if h.Instrument == HedgeWithFutures {
+		inst, err = h.nearestFuture(e)
+		units = int64(math.Round(math.Abs(e.Delta)))
+		if e.Delta > 0 {
+			side = TransactionSell
+		}
+	} else {
+		optionType, expiry := "CE", e.nearCall
+		units = e.ShortCallUnits
+		if !rising {
+			optionType, expiry, units = "PE", e.nearPut, e.ShortPutUnits
+		}
+		if units == 0 {
+			return HedgeAction{}, fmt.Errorf("no uncovered short %s to protect", optionType)
+		}
+		inst, err = h.protectiveOption(e, optionType, expiry)
+	}
+	if err != nil {
+		return HedgeAction{}, err
+	}
+
+	lots := units
+	if inst.LotSize > 0 {
+		lots = max((units+inst.LotSize/2)/inst.LotSize, 1)
+	}
+	order := OrderRequest{
+		Exchange:        Exchange(inst.Exchange),
+		Token:           strconv.FormatInt(inst.Token, 10),
+		Symbol:          inst.TradingSymbol,
+		Quantity:        strconv.FormatInt(inst.OrderQuantity(lots), 10),
+		Product:         e.product,
+		TransactionType: side,
+		OrderType:       OrderTypeMarket,
+		Price:           "0",
+		Validity:        ValidityDay,
+		Tags:            h.Tag,
+	}
+	return HedgeAction{Exposure: e, Instrument: inst, Order: order, Mode: h.Mode}, nil
+}
+
+// protectiveOption returns the option StrikeDistance strikes out of the money on the given side.
+func (h *AutoHedger) protectiveOption(e UnderlyingExposure, optionType string, expiry time.Time) (Instrument, error) {
+	options := h.client.instruments.Filter(InstrumentFilter{
+		Exchanges:  []string{e.Exchange},
+		Underlying: e.Underlying,
+		OptionType: optionType,
+		Expiry:     expiry,
+	})
+
+	var otm []Instrument
+	for _, inst := range options {
+		if (optionType == "CE" && inst.Strike() > e.Spot) || (optionType == "PE" && inst.Strike() < e.Spot) {
+			otm = append(otm, inst)
+		}
+	}
+	if len(otm) == 0 {
+		return Instrument{}, fmt.Errorf("no out-of-the-money %s of %s found", optionType, e.Underlying)
+	}
+	if optionType == "PE" {
+		// Filter sorts by ascending strike, so the puts furthest from the spot come first.
+		slices.Reverse(otm)
+	}
+	return otm[min(max(h.StrikeDistance, 1), len(otm))-1], nil
+}
+
+// nearestFuture returns the unexpired future of the underlying expiring first.
+func (h *AutoHedger) nearestFuture(e UnderlyingExposure) (Instrument, error) {
+	now := time.Now()
+	for _, inst := range h.client.instruments.Filter(InstrumentFilter{Exchanges: []string{e.Exchange}, Underlying: e.Underlying}) {
+		if !inst.IsOption() && !inst.Expired(now) {
+			return inst, nil
+		}
+	}
+	return Instrument{}, fmt.Errorf("no future of %s found", e.Underlying)
+}
+
+// execute places a planned hedge according to the mode.
+func (h *AutoHedger) execute(action *HedgeAction) {
+	logger := h.client.log().With().Str("underlying", action.Exposure.Underlying).Str("symbol", action.Order.Symbol).
+		Str("side", string(action.Order.TransactionType)).Str("quantity", action.Order.Quantity).Logger()
+
+	switch action.Mode {
+	case HedgeLive:
+	case HedgeConfirm:
+		if h.Confirm == nil || !h.Confirm(*action) {
+			action.Declined = true
+			logger.Info().Msg("Hedge declined")
+			return
+		}
+	default:
+		logger.Info().Msg("Hedge not placed in dry-run mode")
+		return
+	}
+
+	resp, err := h.client.PlaceOrder("regular", action.Order)
+	if err != nil {
+		action.Err = err
+		logger.Error().Err(err).Msg("Failed to place hedge")
+		return
+	}
+	action.Placed = true
+	action.OrderNo = resp.Data.OrderNo
+	logger.Info().Str("orderNo", action.OrderNo).Msg("Hedge placed")
+}
+
+// reference returns the reference price of an underlying, recording price when none is set.
+func (h *AutoHedger) reference(underlying string, price float64) float64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ref, ok := h.references[underlying]
+	if !ok {
+		h.references[underlying] = price
+		return price
+	}
+	return ref
+}
+
+// coolingDown reports whether an underlying was hedged within the cooldown.
+func (h *AutoHedger) coolingDown(underlying string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return time.Since(h.lastHedge[underlying]) < h.Cooldown
+}
+
+// optionDelta returns the Black-Scholes delta of an option from its price, or the delta
+// at expiry when the implied volatility cannot be solved.
+func optionDelta(inst Instrument, price, spot float64) float64 {
+	call := *inst.OptionType == "CE"
+	expiry, _ := inst.Expiry()
+	years := yearsToExpiry(expiry)
+
+	if iv, ok := ticks.ImpliedVolatility(call, price, spot, inst.Strike(), years, defaultRiskFreeRate); ok {
+		return ticks.BlackScholesGreeks(call, spot, inst.Strike(), years, defaultRiskFreeRate, iv).Delta
+	}
+	switch {
+	case call && spot > inst.Strike():
+		return 1
+	case !call && spot < inst.Strike():
+		return -1
+	}
+	return 0
+}
@@ -0,0 +1,283 @@
+package tiqs
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Abhi13027/go-tiqs/ticks"
+)
+
+// interestKind is the reason a token is subscribed. Lower values win when the
+// subscription limit forces tokens to be dropped.
+type interestKind int
+
+const (
+	interestPosition  interestKind = iota // An open position in the token.
+	interestOrder                         // A working order in the token.
+	interestPinned                        // Pinned explicitly with Pin.
+	interestWatchlist                     // Part of a watched watchlist.
+)
+
+// AutoSubscriber keeps websocket subscriptions in sync with open positions, working
+// orders and watchlists.
+//
+// Tokens are subscribed as soon as a position is opened or an order is placed in them,
+// and unsubscribed once the position is flat, no order is working and no watchlist or
+// pin refers to them. When MaxTokens is set, tokens of positions are kept first, then
+// those of orders, pins and watchlists, so the subscription limit is never exceeded.
+type AutoSubscriber struct {
+	Mode      string // Subscription mode (e.g., "ltp", "quote", "full").
+	MaxTokens int    // Maximum number of subscribed tokens; zero for no limit.
+
+	ws         *ticks.WS
+	mu         sync.Mutex
+	positions  map[int]bool
+	orders     map[int]bool
+	pinned     map[int]bool
+	watchlists map[string]*Watchlist
+	subscribed map[int]bool
+}
+
+// NewAutoSubscriber creates an auto-subscriber managing the subscriptions of ws.
+//
+// Parameters:
+//   - ws: The websocket client to subscribe on.
+//   - mode: Subscription mode (e.g., "ltp", "full").
+//
+// Returns:
+//   - A pointer to a newly created AutoSubscriber with no interests.
+func NewAutoSubscriber(ws *ticks.WS, mode string) *AutoSubscriber {
+	return &AutoSubscriber{
+		Mode:       mode,
+		ws:         ws,
+		positions:  make(map[int]bool),
+		orders:     make(map[int]bool),
+		pinned:     make(map[int]bool),
+		watchlists: make(map[string]*Watchlist),
+		subscribed: make(map[int]bool),
+	}
+}
+
+// Watch keeps the instruments of a watchlist subscribed. Watching a watchlist with the
+// same name replaces the previous one; later changes to the watchlist are picked up on
+// the next Sync.
+func (a *AutoSubscriber) Watch(w *Watchlist) {
+	a.mu.Lock()
+	a.watchlists[w.Name] = w
+	a.mu.Unlock()
+}
+
+// Unwatch stops keeping the instruments of the named watchlist subscribed.
+func (a *AutoSubscriber) Unwatch(name string) {
+	a.mu.Lock()
+	delete(a.watchlists, name)
+	a.mu.Unlock()
+}
+
+// Pin keeps a token subscribed regardless of positions and orders, e.g., an index
+// used as a reference price.
+func (a *AutoSubscriber) Pin(token int) {
+	a.mu.Lock()
+	a.pinned[token] = true
+	a.mu.Unlock()
+}
+
+// Unpin removes a pin set with Pin.
+func (a *AutoSubscriber) Unpin(token int) {
+	a.mu.Lock()
+	delete(a.pinned, token)
+	a.mu.Unlock()
+}
+
+// UpdatePositions replaces the set of tokens with open positions.
+//
+// Parameters:
+//   - positions: The current positions (e.g., from GetPositions); flat positions are ignored.
+func (a *AutoSubscriber) UpdatePositions(positions []Position) {
+	tokens := make(map[int]bool)
+	for _, p := range positions {
+		if parseInt(p.Qty) != 0 {
+			tokens[int(parseInt(p.Token))] = true
+		}
+	}
+	a.mu.Lock()
+	a.positions = tokens
+	a.mu.Unlock()
+}
+
+// UpdateOrders replaces the set of tokens with working orders.
+//
+// Parameters:
+//   - orders: The current order book rows; orders that are no longer working are ignored.
+func (a *AutoSubscriber) UpdateOrders(orders []OrderDetail) {
+	tokens := make(map[int]bool)
+	for _, o := range orders {
+		if o.NormalizedStatus().Working() {
+			tokens[int(parseInt(o.Token))] = true
+		}
+	}
+	a.mu.Lock()
+	a.orders = tokens
+	a.mu.Unlock()
+}
+
+// Subscribed returns the tokens currently subscribed by the auto-subscriber, in ascending order.
+func (a *AutoSubscriber) Subscribed() []int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	tokens := make([]int, 0, len(a.subscribed))
+	for token := range a.subscribed {
+		tokens = append(tokens, token)
+	}
+	sort.Ints(tokens)
+	return tokens
+}
+
+// Sync subscribes the tokens of current interest that are not subscribed yet and
+// unsubscribes the tokens no longer of interest.
+//
+// Returns:
+//   - An error if a subscription message cannot be sent; the tokens concerned are retried on the next Sync.
+func (a *AutoSubscriber) Sync() error {
+	a.mu.Lock()
+	desired := a.desiredLocked()
+
+	var add, remove []int
+	for token := range desired {
+		if !a.subscribed[token] {
+			add = append(add, token)
+		}
+	}
+	for token := range a.subscribed {
+		if !desired[token] {
+			remove = append(remove, token)
+		}
+	}
+	a.mu.Unlock()
+
+	sort.Ints(add)
+	sort.Ints(remove)
+
+	// Unsubscribe first so the limit has room for the new tokens.
+	if len(remove) > 0 {
+		if err := a.ws.Unsubscribe(remove, a.Mode); err != nil {
+			return fmt.Errorf("error unsubscribing %d tokens: %w", len(remove), err)
+		}
+		a.mu.Lock()
+		for _, token := range remove {
+			delete(a.subscribed, token)
+		}
+		a.mu.Unlock()
+	}
+	if len(add) > 0 {
+		if err := a.ws.Subscribe(add, a.Mode); err != nil {
+			return fmt.Errorf("error subscribing %d tokens: %w", len(add), err)
+		}
+		a.mu.Lock()
+		for _, token := range add {
+			a.subscribed[token] = true
+		}
+		a.mu.Unlock()
+	}
+
+	if len(add) > 0 || len(remove) > 0 {
+		defaultLog().Info().Int("subscribed", len(add)).Int("unsubscribed", len(remove)).Msg("Auto-subscriptions synced")
+	}
+	return nil
+}
+
+// Run polls the order book and positions every interval and syncs the subscriptions
+// until the context is cancelled. A failing poll is logged and keeps the previous
+// interest in that entity.
+//
+// Parameters:
+//   - ctx: Context controlling the polling loop.
+//   - client: The client used to poll.
+//   - interval: Delay between polls.
+//
+// Returns:
+//   - The context error once the context is cancelled.
+func (a *AutoSubscriber) Run(ctx context.Context, client *Client, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if positions, err := client.GetPositions(); err != nil {
+			defaultLog().Warn().Err(err).Msg("Auto-subscriber failed to poll positions")
+		} else {
+			a.UpdatePositions(positions)
+		}
+		if orders, err := client.getOrderRows(); err != nil {
+			defaultLog().Warn().Err(err).Msg("Auto-subscriber failed to poll orders")
+		} else {
+			a.UpdateOrders(orders)
+		}
+		if err := a.Sync(); err != nil {
+			defaultLog().Warn().Err(err).Msg("Auto-subscriber failed to sync subscriptions")
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// desiredLocked returns the tokens that should be subscribed, trimmed to MaxTokens by
+// priority. The caller must hold a.mu.
+func (a *AutoSubscriber) desiredLocked() map[int]bool {
+	priority := make(map[int]interestKind)
+	add := func(token int, kind interestKind) {
+		if token == 0 {
+			return
+		}
+		if current, ok := priority[token]; !ok || kind < current {
+			priority[token] = kind
+		}
+	}
+	for token := range a.positions {
+		add(token, interestPosition)
+	}
+	for token := range a.orders {
+		add(token, interestOrder)
+	}
+	for token := range a.pinned {
+		add(token, interestPinned)
+	}
+	for _, w := range a.watchlists {
+		for _, token := range w.Tokens() {
+			add(int(token), interestWatchlist)
+		}
+	}
+
+	tokens := make([]int, 0, len(priority))
+	for token := range priority {
+		tokens = append(tokens, token)
+	}
+	if a.MaxTokens > 0 && len(tokens) > a.MaxTokens {
+		// Keep already subscribed tokens within the same priority to avoid churn.
+		sort.Slice(tokens, func(i, j int) bool {
+			ti, tj := tokens[i], tokens[j]
+			if priority[ti] != priority[tj] {
+				return priority[ti] < priority[tj]
+			}
+			if a.subscribed[ti] != a.subscribed[tj] {
+				return a.subscribed[ti]
+			}
+			return ti < tj
+		})
+		defaultLog().Warn().Int("wanted", len(tokens)).Int("limit", a.MaxTokens).Msg("Subscription limit reached, dropping lowest priority tokens")
+		tokens = tokens[:a.MaxTokens]
+	}
+
+	desired := make(map[int]bool, len(tokens))
+	for _, token := range tokens {
+		desired[token] = true
+	}
+	return desired
+}
@@ -0,0 +1,129 @@
+package tiqs
+
+import (
+	"fmt"
+	"slices"
+)
+
+// LegMargin is the margin of one leg of a basket traded on its own.
+type LegMargin struct {
+	Leg      MarginRequest `json:"leg"`      // The leg.
+	Margin   float64       `json:"margin"`   // Margin of the leg alone, in rupees.
+	Span     float64       `json:"span"`     // SPAN part of Margin, if reported.
+	Exposure float64       `json:"exposure"` // Exposure part of Margin, if reported.
+}
+
+// BasketMarginBreakdown details the margin of a multi-leg basket and the benefit of
+// trading its legs together.
+type BasketMarginBreakdown struct {
+	Legs         []LegMargin `json:"legs"`         // Standalone margin of every leg, in basket order.
+	Unhedged     float64     `json:"unhedged"`     // Sum of the standalone margins.
+	Hedged       float64     `json:"hedged"`       // Margin of the basket as a whole, the increase of the margin used.
+	HedgeBenefit float64     `json:"hedgeBenefit"` // Unhedged minus Hedged; the margin saved by the offsetting legs.
+	Span         float64     `json:"span"`         // Sum of the SPAN parts of the legs, if reported.
+	Exposure     float64     `json:"exposure"`     // Sum of the exposure parts of the legs, if reported.
+}
+
+// MarginStep is the margin blocked once the legs of a basket up to one leg are placed.
+type MarginStep struct {
+	Leg    MarginRequest `json:"leg"`    // The leg placed at this step.
+	Margin float64       `json:"margin"` // Margin of the legs placed so far, in rupees.
+}
+
+// GetBasketMarginBreakdown fetches the margin of every leg of a basket on its own and of
+// the basket as a whole, to show how much margin the offsetting legs save.
+//
+// Every leg costs one margin request on top of the basket request, all within the
+// client's rate limits.
+//
+// Parameters:
+//   - legs: The orders of the basket.
+//
+// Returns:
+//   - A pointer to the BasketMarginBreakdown if successful.
+//   - An error if a leg is invalid or a margin request fails.
+func (c *Client) GetBasketMarginBreakdown(legs BasketMarginRequest) (*BasketMarginBreakdown, error) {
+	if len(legs) == 0 {
+		return nil, fmt.Errorf("basket has no legs")
+	}
+
+	breakdown := &BasketMarginBreakdown{Legs: make([]LegMargin, 0, len(legs))}
+	for i, leg := range legs {
+		margin, err := c.GetMargin(leg)
+		if err != nil {
+			return nil, fmt.Errorf("basket order %d: %w", i, err)
+		}
+		if margin.Status != "success" {
+			return nil, fmt.Errorf("basket order %d: margin calculation failed", i)
+		}
+		lm := LegMargin{
+			Leg:      leg,
+			Margin:   parseFloat(margin.Data.Margin),
+			Span:     parseFloat(margin.Data.Span),
+			Exposure: parseFloat(margin.Data.Exposure),
+		}
+		breakdown.Legs = append(breakdown.Legs, lm)
+		breakdown.Unhedged += lm.Margin
+		breakdown.Span += lm.Span
+		breakdown.Exposure += lm.Exposure
+	}
+
+	hedged, err := c.basketMargin(legs)
+	if err != nil {
+		return nil, err
+	}
+	breakdown.Hedged = hedged
+	breakdown.HedgeBenefit = breakdown.Unhedged - hedged
+
+	c.log().Info().
+		Int("legs", len(legs)).
+		Float64("unhedged", breakdown.Unhedged).
+		Float64("hedged", breakdown.Hedged).
+		Msg("Basket margin breakdown retrieved")
+	return breakdown, nil
+}
+
+// GetMarginSequence fetches the margin blocked after each leg of a basket is placed, in
+// the given order, showing the peak margin the account needs if the legs fill one after
+// the other. Use SellsFirst to see the worst case, where short legs fill before the long
+// legs hedging them.
+//
+// Parameters:
+//   - legs: The orders of the basket, in the order they would be placed.
+//
+// Returns:
+//   - One step per leg, with the margin of the legs placed so far.
+//   - An error if a leg is invalid or a margin request fails.
+func (c *Client) GetMarginSequence(legs BasketMarginRequest) ([]MarginStep, error) {
+	steps := make([]MarginStep, 0, len(legs))
+	for i := range legs {
+		margin, err := c.basketMargin(legs[:i+1])
+		if err != nil {
+			return nil, fmt.Errorf("step %d: %w", i+1, err)
+		}
+		steps = append(steps, MarginStep{Leg: legs[i], Margin: margin})
+	}
+	return steps, nil
+}
+
+// SellsFirst returns the legs of a basket with the sells before the buys, keeping the
+// order of the legs on each side.
+func SellsFirst(legs BasketMarginRequest) BasketMarginRequest {
+	sorted := slices.Clone(legs)
+	slices.SortStableFunc(sorted, func(a, b MarginRequest) int {
+		return compareBool(a.TransactionType != TransactionSell, b.TransactionType != TransactionSell)
+	})
+	return sorted
+}
+
+// basketMargin returns the increase of the margin used by trading a basket.
+func (c *Client) basketMargin(legs BasketMarginRequest) (float64, error) {
+	margin, err := c.GetBasketMargin(legs)
+	if err != nil {
+		return 0, err
+	}
+	if margin.Status != "success" {
+		return 0, fmt.Errorf("basket margin calculation failed")
+	}
+	return parseFloat(margin.Data.MarginUsedAfterTrade) - parseFloat(margin.Data.MarginUsed), nil
+}
@@ -0,0 +1,418 @@
+package tiqs
+
+import (
+	"cmp"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// bracketVariety is the order variety bracket and cover orders are placed with; the
+// product (ProductBracket or ProductCover) tells the broker to manage the exit legs.
+const bracketVariety = "regular"
+
+// BracketOrderRequest describes a bracket order: an entry with a target and a stop-loss
+// that the broker places once the entry fills. Whichever exit completes first cancels
+// the other.
+type BracketOrderRequest struct {
+	Exchange        Exchange        `json:"exchange"`               // Exchange where the order is placed (e.g., NSE, NFO).
+	Token           string          `json:"token"`                  // Unique identifier for the instrument.
+	Symbol          string          `json:"symbol"`                 // Trading symbol of the instrument.
+	TransactionType TransactionType `json:"transactionType"`        // Side of the entry.
+	Quantity        string          `json:"quantity"`               // Order quantity.
+	OrderType       OrderType       `json:"order"`                  // Type of the entry: LMT or SL-LMT.
+	Price           string          `json:"price"`                  // Limit price of the entry.
+	TriggerPrice    string          `json:"triggerPrice,omitempty"` // Trigger price of an SL-LMT entry.
+	Target          string          `json:"target"`                 // Distance of the target from the entry price, in rupees.
+	StopLoss        string          `json:"stopLoss"`               // Distance of the stop-loss from the entry price, in rupees.
+	TrailingStop    string          `json:"trailingStop,omitempty"` // Step by which the stop-loss trails the price (optional).
+	Validity        Validity        `json:"validity"`               // Validity of the entry; DAY if empty.
+	Tags            string          `json:"tags,omitempty"`         // Custom tags for order tracking (optional).
+}
+
+// CoverOrderRequest describes a cover order: an entry placed together with a compulsory
+// stop-loss managed by the broker.
+type CoverOrderRequest struct {
+	Exchange        Exchange        `json:"exchange"`        // Exchange where the order is placed (e.g., NSE, NFO).
+	Token           string          `json:"token"`           // Unique identifier for the instrument.
+	Symbol          string          `json:"symbol"`          // Trading symbol of the instrument.
+	TransactionType TransactionType `json:"transactionType"` // Side of the entry.
+	Quantity        string          `json:"quantity"`        // Order quantity.
+	OrderType       OrderType       `json:"order"`           // Type of the entry: MKT or LMT.
+	Price           string          `json:"price"`           // Limit price of the entry; empty for market entries.
+	StopLoss        string          `json:"stopLoss"`        // Trigger price of the stop-loss leg.
+	Validity        Validity        `json:"validity"`        // Validity of the entry; DAY if empty.
+	Tags            string          `json:"tags,omitempty"`  // Custom tags for order tracking (optional).
+}
+
+// BracketOrder is a placed bracket or cover order. It finds the exit legs the broker
+// placed for the entry and modifies or exits them.
+//
+// The order book does not link exit legs to their entry, so legs are matched by
+// instrument, product and opposite side among the orders placed after the entry. Keep
+// at most one bracket or cover order per instrument open at a time.
+type BracketOrder struct {
+	OrderNo         string          `json:"orderNo"`         // Order number of the entry.
+	Product         Product         `json:"product"`         // ProductBracket or ProductCover.
+	Exchange        Exchange        `json:"exchange"`        // Exchange of the instrument.
+	Token           int64           `json:"token"`           // Instrument token.
+	TransactionType TransactionType `json:"transactionType"` // Side of the entry.
+
+	client *Client
+}
+
+// BracketLegs holds the entry and exit legs of a bracket or cover order as found in the
+// order book. Exit legs are nil until the broker placed them.
+type BracketLegs struct {
+	Entry    OrderBookEntry  `json:"entry"`    // The entry order.
+	Target   *OrderBookEntry `json:"target"`   // The target leg; always nil for cover orders.
+	StopLoss *OrderBookEntry `json:"stopLoss"` // The stop-loss leg.
+}
+
+// Open reports whether the entry or an exit leg is still working.
+func (l BracketLegs) Open() bool {
+	if l.Entry.Status.Working() {
+		return true
+	}
+	return (l.Target != nil && l.Target.Status.Working()) || (l.StopLoss != nil && l.StopLoss.Status.Working())
+}
+
+// PlaceBracketOrder places a bracket order.
+//
+// The order goes through PlaceOrder, so attached guards and segment validation apply.
+//
+// Parameters:
+//   - req: The entry, target and stop-loss of the order.
+//
+// Returns:
+//   - A pointer to the placed BracketOrder if successful.
+//   - An error if the request is invalid or the placement fails.
+func (c *Client) PlaceBracketOrder(req BracketOrderRequest) (*BracketOrder, error) {
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+
+	order := OrderRequest{
+		Exchange:        req.Exchange,
+		Token:           req.Token,
+		Symbol:          req.Symbol,
+		Quantity:        req.Quantity,
+		Product:         ProductBracket,
+		TransactionType: req.TransactionType,
+		OrderType:       req.OrderType,
+		Price:           req.Price,
+		TriggerPrice:    req.TriggerPrice,
+		BookProfitPrice: req.Target,
+		BookLossPrice:   req.StopLoss,
+		TrailingPrice:   req.TrailingStop,
+		Validity:        cmp.Or(req.Validity, ValidityDay),
+		Tags:            req.Tags,
+	}
+	return c.placeBracket(order)
+}
+
+// PlaceCoverOrder places a cover order.
+//
+// The order goes through PlaceOrder, so attached guards and segment validation apply.
+//
+// Parameters:
+//   - req: The entry and stop-loss of the order.
+//
+// Returns:
+//   - A pointer to the placed BracketOrder if successful.
+//   - An error if the request is invalid or the placement fails.
+func (c *Client) PlaceCoverOrder(req CoverOrderRequest) (*BracketOrder, error) {
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+
+	price := req.Price
+	if req.OrderType == OrderTypeMarket && price == "" {
+		price = "0"
+	}
+	order := OrderRequest{
+		Exchange:        req.Exchange,
+		Token:           req.Token,
+		Symbol:          req.Symbol,
+		Quantity:        req.Quantity,
+		Product:         ProductCover,
+		TransactionType: req.TransactionType,
+		OrderType:       req.OrderType,
+		Price:           price,
+		BookLossPrice:   req.StopLoss,
+		Validity:        cmp.Or(req.Validity, ValidityDay),
+		Tags:            req.Tags,
+	}
+	return c.placeBracket(order)
+}
+
+// GetBracketOrder returns a handle on a bracket or cover order placed earlier, e.g.,
+// before a restart.
+//
+// Parameters:
+//   - orderNo: The order number of the entry.
+//
+// Returns:
+//   - A pointer to the BracketOrder if successful.
+//   - An error if the order book cannot be retrieved, or the order is not in it or is not
+//     a bracket or cover order.
+func (c *Client) GetBracketOrder(orderNo string) (*BracketOrder, error) {
+	rows, err := c.getOrderRows()
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		if row.ID != orderNo {
+			continue
+		}
+		entry := NewOrderBookEntry(row)
+		if entry.Product != ProductBracket && entry.Product != ProductCover {
+			return nil, fmt.Errorf("order %s is a %s order, not a bracket or cover order", orderNo, entry.Product)
+		}
+		return &BracketOrder{
+			OrderNo:         orderNo,
+			Product:         entry.Product,
+			Exchange:        entry.Exchange,
+			Token:           entry.Token,
+			TransactionType: entry.TransactionType,
+			client:          c,
+		}, nil
+	}
+	return nil, fmt.Errorf("order %s not found in the order book", orderNo)
+}
+
+// placeBracket places the entry of a bracket or cover order.
+func (c *Client) placeBracket(order OrderRequest) (*BracketOrder, error) {
+	resp, err := c.PlaceOrder(bracketVariety, order)
+	if err != nil {
+		return nil, err
+	}
+
+	c.log().Info().Str("orderNo", resp.Data.OrderNo).Str("product", order.Product.String()).Msg("Bracket order placed successfully")
+	return &BracketOrder{
+		OrderNo:         resp.Data.OrderNo,
+		Product:         order.Product,
+		Exchange:        order.Exchange,
+		Token:           parseInt(order.Token),
+		TransactionType: order.TransactionType,
+		client:          c,
+	}, nil
+}
+
+// Legs fetches the order book and returns the entry and exit legs of the order.
+//
+// Returns:
+//   - The legs if successful.
+//   - An error if the order book cannot be retrieved or the entry is not in it.
+func (b *BracketOrder) Legs() (BracketLegs, error) {
+	rows, err := b.client.getOrderRows()
+	if err != nil {
+		return BracketLegs{}, err
+	}
+	return b.legsFrom(rows)
+}
+
+// legsFrom matches the legs of the order among the rows of the order book.
+func (b *BracketOrder) legsFrom(rows []OrderDetail) (BracketLegs, error) {
+	var (
+		legs       BracketLegs
+		found      bool
+		candidates []OrderBookEntry
+	)
+	for _, row := range rows {
+		entry := NewOrderBookEntry(row)
+		switch {
+		case entry.OrderNo == b.OrderNo:
+			legs.Entry, found = entry, true
+		case entry.Token == b.Token && entry.Product == b.Product && entry.TransactionType != b.TransactionType:
+			candidates = append(candidates, entry)
+		}
+	}
+	if !found {
+		return BracketLegs{}, fmt.Errorf("order %s not found in the order book", b.OrderNo)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].OrderTime.Before(candidates[j].OrderTime) })
+	for i := range candidates {
+		leg := &candidates[i]
+		if !legs.Entry.OrderTime.IsZero() && leg.OrderTime.Before(legs.Entry.OrderTime) {
+			continue
+		}
+		switch leg.OrderType {
+		case OrderTypeLimit:
+			if b.Product == ProductBracket && legs.Target == nil {
+				legs.Target = leg
+			}
+		case OrderTypeStopLoss, OrderTypeStopLossMkt:
+			if legs.StopLoss == nil {
+				legs.StopLoss = leg
+			}
+		}
+	}
+	return legs, nil
+}
+
+// ModifyEntry changes the price of the entry while it is still working.
+//
+// Parameters:
+//   - price: The new limit price.
+//   - triggerPrice: The new trigger price of an SL-LMT entry; empty to keep it.
+//
+// Returns:
+//   - An error if the entry is no longer working or the modification fails.
+func (b *BracketOrder) ModifyEntry(price, triggerPrice string) error {
+	legs, err := b.Legs()
+	if err != nil {
+		return err
+	}
+	if !legs.Entry.Status.Working() {
+		return fmt.Errorf("entry %s of bracket order is %s", b.OrderNo, legs.Entry.Status)
+	}
+	return b.modify(legs.Entry, legs.Entry.OrderType, price, triggerPrice)
+}
+
+// ModifyTarget moves the target leg of a bracket order.
+//
+// Parameters:
+//   - price: The new limit price of the target.
+//
+// Returns:
+//   - An error if there is no working target leg or the modification fails.
+func (b *BracketOrder) ModifyTarget(price string) error {
+	legs, err := b.Legs()
+	if err != nil {
+		return err
+	}
+	if legs.Target == nil || !legs.Target.Status.Working() {
+		return fmt.Errorf("bracket order %s has no working target leg", b.OrderNo)
+	}
+	return b.modify(*legs.Target, OrderTypeLimit, price, "")
+}
+
+// ModifyStopLoss moves the stop-loss leg of a bracket or cover order.
+//
+// Parameters:
+//   - triggerPrice: The new trigger price.
+//   - price: The new limit price of an SL-LMT leg; empty to keep it, or for SL-MKT legs.
+//
+// Returns:
+//   - An error if there is no working stop-loss leg or the modification fails.
+func (b *BracketOrder) ModifyStopLoss(triggerPrice, price string) error {
+	legs, err := b.Legs()
+	if err != nil {
+		return err
+	}
+	if legs.StopLoss == nil || !legs.StopLoss.Status.Working() {
+		return fmt.Errorf("bracket order %s has no working stop-loss leg", b.OrderNo)
+	}
+	return b.modify(*legs.StopLoss, legs.StopLoss.OrderType, price, triggerPrice)
+}
+
+// Exit closes the order at the market.
+//
+// A working entry is cancelled first. If the entry filled, even partly, the position is
+// then closed by converting the target leg (or the stop-loss leg of a cover order) into a
+// market order; the broker cancels the other leg once it fills. Exiting an order whose
+// legs are all terminal does nothing.
+//
+// Returns:
+//   - An error if the order book cannot be retrieved or the cancellation or modification fails.
+func (b *BracketOrder) Exit() error {
+	legs, err := b.Legs()
+	if err != nil {
+		return err
+	}
+
+	if legs.Entry.Status.Working() {
+		if err := b.client.CancelOrder(bracketVariety, b.OrderNo); err != nil {
+			return err
+		}
+		if legs.Entry.FilledQuantity == 0 {
+			b.client.log().Info().Str("orderNo", b.OrderNo).Msg("Bracket order entry cancelled")
+			return nil
+		}
+	}
+
+	exit := legs.Target
+	if exit == nil || !exit.Status.Working() {
+		exit = legs.StopLoss
+	}
+	if exit == nil || !exit.Status.Working() {
+		if legs.Entry.FilledQuantity > 0 && legs.Open() {
+			return fmt.Errorf("bracket order %s has no working exit leg yet", b.OrderNo)
+		}
+		return nil
+	}
+
+	if err := b.modify(*exit, OrderTypeMarket, "0", ""); err != nil {
+		return err
+	}
+	b.client.log().Info().Str("orderNo", b.OrderNo).Str("leg", exit.OrderNo).Msg("Bracket order exited")
+	return nil
+}
+
+// modify sends a modification of a leg, keeping the prices that are not given.
+func (b *BracketOrder) modify(leg OrderBookEntry, orderType OrderType, price, triggerPrice string) error {
+	order := OrderRequest{
+		Exchange:        leg.Exchange,
+		Token:           strconv.FormatInt(leg.Token, 10),
+		Symbol:          leg.Symbol,
+		Quantity:        strconv.FormatInt(leg.Quantity, 10),
+		Product:         leg.Product,
+		TransactionType: leg.TransactionType,
+		OrderType:       orderType,
+		Price:           cmp.Or(strings.TrimSpace(price), leg.Detail.Price),
+		Validity:        ValidityDay,
+	}
+	if orderType == OrderTypeStopLoss || orderType == OrderTypeStopLossMkt {
+		order.TriggerPrice = cmp.Or(strings.TrimSpace(triggerPrice), leg.Detail.OrderTriggerPrice)
+	}
+
+	_, err := b.client.ModifyOrder(bracketVariety, leg.OrderNo, order)
+	return err
+}
+
+// validate checks the request before it is sent.
+func (r BracketOrderRequest) validate() error {
+	if r.OrderType != OrderTypeLimit && r.OrderType != OrderTypeStopLoss {
+		return fmt.Errorf("bracket order entry must be %s or %s, not %q", OrderTypeLimit, OrderTypeStopLoss, r.OrderType)
+	}
+	if parseFloat(r.Price) <= 0 {
+		return fmt.Errorf("invalid bracket order price: %q", r.Price)
+	}
+	if r.OrderType == OrderTypeStopLoss && parseFloat(r.TriggerPrice) <= 0 {
+		return fmt.Errorf("invalid bracket order trigger price: %q", r.TriggerPrice)
+	}
+	if parseFloat(r.Target) <= 0 {
+		return fmt.Errorf("invalid bracket order target: %q", r.Target)
+	}
+	if parseFloat(r.StopLoss) <= 0 {
+		return fmt.Errorf("invalid bracket order stop-loss: %q", r.StopLoss)
+	}
+	if r.TrailingStop != "" && parseFloat(r.TrailingStop) <= 0 {
+		return fmt.Errorf("invalid bracket order trailing stop: %q", r.TrailingStop)
+	}
+	return nil
+}
+
+// validate checks the request before it is sent.
+func (r CoverOrderRequest) validate() error {
+	if r.OrderType != OrderTypeMarket && r.OrderType != OrderTypeLimit {
+		return fmt.Errorf("cover order entry must be %s or %s, not %q", OrderTypeMarket, OrderTypeLimit, r.OrderType)
+	}
+	if r.OrderType == OrderTypeLimit && parseFloat(r.Price) <= 0 {
+		return fmt.Errorf("invalid cover order price: %q", r.Price)
+	}
+	stop := parseFloat(r.StopLoss)
+	if stop <= 0 {
+		return fmt.Errorf("invalid cover order stop-loss: %q", r.StopLoss)
+	}
+	if r.OrderType == OrderTypeLimit {
+		price := parseFloat(r.Price)
+		if r.TransactionType == TransactionBuy && stop >= price || r.TransactionType == TransactionSell && stop <= price {
+			return fmt.Errorf("cover order stop-loss %s is on the wrong side of the entry price %s", r.StopLoss, r.Price)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package tiqs
+
+import (
+	"cmp"
+	"strconv"
+	"sync"
+)
+
+// DefaultBulkWorkers is the number of orders CancelAllOrders and ModifyAllOrders send
+// concurrently. Requests still pass the client's order rate limit.
+const DefaultBulkWorkers = 5
+
+// bulkVariety is the order variety used to cancel and modify orders found in the order
+// book, which does not report the variety they were placed with.
+const bulkVariety = "regular"
+
+// BulkOrderResult is the outcome of cancelling or modifying one order.
+type BulkOrderResult struct {
+	Entry OrderBookEntry // The order as found in the order book.
+	Order OrderRequest   // The modification sent; zero for cancellations.
+	Err   error          // Error cancelling or modifying the order, or nil.
+}
+
+// ModifyRequest returns the modification that leaves the order as it stands, to change
+// only some fields, e.g., the price of a limit order or the trigger of a stop-loss.
+func (e OrderBookEntry) ModifyRequest() OrderRequest {
+	validity, err := ParseValidity(e.Detail.Retention)
+	if err != nil {
+		validity = ValidityDay
+	}
+	order := OrderRequest{
+		Exchange:        e.Exchange,
+		Token:           strconv.FormatInt(e.Token, 10),
+		Symbol:          e.Symbol,
+		Quantity:        strconv.FormatInt(e.Quantity, 10),
+		DisclosedQty:    e.Detail.DisclosedQuantity,
+		Product:         e.Product,
+		TransactionType: e.TransactionType,
+		OrderType:       e.OrderType,
+		Price:           cmp.Or(e.Detail.Price, "0"),
+		Validity:        validity,
+		Tags:            e.Tag,
+	}
+	if e.OrderType == OrderTypeStopLoss || e.OrderType == OrderTypeStopLossMkt {
+		order.TriggerPrice = e.Detail.OrderTriggerPrice
+	}
+	return order
+}
+
+// CancelAllOrders cancels every working order selected by the filter, e.g., for a panic
+// button or the end-of-day cleanup.
+//
+// Orders are cancelled concurrently, DefaultBulkWorkers at a time, and a failure to
+// cancel one order does not stop the others. Orders are cancelled with the "regular"
+// variety, as the order book does not report the variety of an order.
+//
+// Parameters:
+//   - filter: The orders to cancel; orders that can no longer be filled are always
+//     skipped, so a zero filter cancels every working order.
+//
+// Returns:
+//   - One result per order selected, in order book order.
+//   - An error if the order book cannot be retrieved.
+func (c *Client) CancelAllOrders(filter OrderBookFilter) ([]BulkOrderResult, error) {
+	filter.WorkingOnly = true
+	entries, err := c.FindOrders(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]BulkOrderResult, len(entries))
+	for i, e := range entries {
+		results[i].Entry = e
+	}
+	failed := runBulk(results, func(r *BulkOrderResult) {
+		r.Err = c.CancelOrder(bulkVariety, r.Entry.OrderNo)
+	})
+
+	c.log().Info().Int("orders", len(results)).Int("failed", failed).Msg("Orders cancelled")
+	return results, nil
+}
+
+// ModifyAllOrders modifies every working order selected by the filter, e.g., to move the
+// stop-losses of a strategy or reprice its pending limit orders.
+//
+// The modification of each order is built by modify, which receives the order and
+// returns the request to send and whether to send it; start from OrderBookEntry's
+// ModifyRequest to keep the fields left unchanged. Orders are modified concurrently,
+// DefaultBulkWorkers at a time, with the "regular" variety, and a failure to modify one
+// order does not stop the others.
+//
+// Parameters:
+//   - filter: The orders to modify; orders that can no longer be filled are always
+//     skipped.
+//   - modify: Builds the modification of an order.
+//
+// Returns:
+//   - One result per order modified, in order book order.
+//   - An error if the order book cannot be retrieved.
+func (c *Client) ModifyAllOrders(filter OrderBookFilter, modify func(OrderBookEntry) (OrderRequest, bool)) ([]BulkOrderResult, error) {
+	filter.WorkingOnly = true
+	entries, err := c.FindOrders(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []BulkOrderResult
+	for _, e := range entries {
+		if order, ok := modify(e); ok {
+			results = append(results, BulkOrderResult{Entry: e, Order: order})
+		}
+	}
+	failed := runBulk(results, func(r *BulkOrderResult) {
+		_, r.Err = c.ModifyOrder(bulkVariety, r.Entry.OrderNo, r.Order)
+	})
+
+	c.log().Info().Int("orders", len(results)).Int("failed", failed).Msg("Orders modified")
+	return results, nil
+}
+
+// runBulk runs send on every result with DefaultBulkWorkers workers and returns the
+// number of results holding an error.
+func runBulk(results []BulkOrderResult, send func(*BulkOrderResult)) int {
+	var wg sync.WaitGroup
+	jobs := make(chan *BulkOrderResult)
+	for range min(DefaultBulkWorkers, len(results)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for r := range jobs {
+				send(r)
+			}
+		}()
+	}
+	for i := range results {
+		jobs <- &results[i]
+	}
+	close(jobs)
+	wg.Wait()
+
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+		}
+	}
+	return failed
+}
@@ -0,0 +1,277 @@
+package tiqs
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SpecialSession is a trading session that replaces the regular session of a day, such
+// as the Muhurat session on Diwali or a session shifted by the exchange.
+type SpecialSession struct {
+	Date        time.Time     `json:"date"`        // Day of the session, at midnight IST.
+	Exchanges   []Exchange    `json:"exchanges"`   // Exchanges holding the session; empty for all.
+	Open        time.Duration `json:"open"`        // Session open as an offset from midnight.
+	Close       time.Duration `json:"close"`       // Session close as an offset from midnight.
+	Description string        `json:"description"` // Name of the session (e.g., "Muhurat Trading").
+}
+
+// AppliesTo reports whether the session is held on the given exchange. A session of NSE
+// or BSE is held on all segments of that exchange (e.g., NSE includes NFO and CDS).
+func (s SpecialSession) AppliesTo(exchange Exchange) bool {
+	if len(s.Exchanges) == 0 {
+		return true
+	}
+	for _, e := range s.Exchanges {
+		if e == exchange || e == venueOf(exchange) {
+			return true
+		}
+	}
+	return false
+}
+
+// Window returns the open and close instants of the session.
+func (s SpecialSession) Window() (time.Time, time.Time) {
+	return s.Date.Add(s.Open), s.Date.Add(s.Close)
+}
+
+// TradingCalendar holds the holidays and special sessions of each exchange.
+//
+// Attach it to a MarketClock (see MarketClock.Calendar or TradingCalendar.Clock) so that
+// IsOpen honours holidays, and on days with special sessions opens only during them,
+// weekends included.
+type TradingCalendar struct {
+	mu       sync.RWMutex
+	holidays map[string]map[Exchange]string // Keyed by day, then exchange; "" for all exchanges.
+	special  map[string][]SpecialSession    // Keyed by day.
+}
+
+// NewTradingCalendar creates an empty calendar.
+//
+// Returns:
+//   - A pointer to a newly created TradingCalendar.
+func NewTradingCalendar() *TradingCalendar {
+	return &TradingCalendar{
+		holidays: make(map[string]map[Exchange]string),
+		special:  make(map[string][]SpecialSession),
+	}
+}
+
+// GetTradingCalendar fetches the holidays and special trading days and builds a calendar.
+//
+// Returns:
+//   - A pointer to a TradingCalendar if successful.
+//   - An error if the request fails or the response cannot be parsed.
+func (c *Client) GetTradingCalendar() (*TradingCalendar, error) {
+	resp, err := c.GetHolidays()
+	if err != nil {
+		return nil, err
+	}
+	if resp.Status != "" && resp.Status != "success" {
+		return nil, newAPIError("holidays retrieval", c.endpoint(EndpointHolidays), 0, nil)
+	}
+	return CalendarFromHolidays(resp)
+}
+
+// CalendarFromHolidays builds a calendar from a holidays response.
+//
+// Holidays apply to every exchange. Each special trading day holds a list of sessions,
+// each given as an untyped row; rows are read leniently: strings naming an exchange or
+// segment select where the session is held, the first two clock times (e.g., "18:15" or
+// "18:15:00", with or without a date) are its open and close, and any other text is its
+// description. Rows without both times are skipped with a warning.
+//
+// Parameters:
+//   - resp: The response of GetHolidays.
+//
+// Returns:
+//   - A pointer to a TradingCalendar.
+//   - An error if a date cannot be parsed.
+func CalendarFromHolidays(resp *HolidaysResponse) (*TradingCalendar, error) {
+	cal := NewTradingCalendar()
+
+	for date, description := range resp.Data.Holidays {
+		day, ok := parseTimestamp(date)
+		if !ok {
+			return nil, fmt.Errorf("invalid holiday date: %q", date)
+		}
+		cal.AddHoliday("", day, description)
+	}
+
+	for date, rows := range resp.Data.SpecialTradingDays {
+		day, ok := parseTimestamp(date)
+		if !ok {
+			return nil, fmt.Errorf("invalid special trading day: %q", date)
+		}
+		for _, row := range rows {
+			session, ok := parseSpecialSession(day, row)
+			if !ok {
+				defaultLog().Warn().Str("date", date).Interface("row", row).Msg("Skipping special trading session without open and close times")
+				continue
+			}
+			cal.AddSpecialSession(session)
+		}
+	}
+	return cal, nil
+}
+
+// AddHoliday marks a day as a holiday.
+//
+// Parameters:
+//   - exchange: The exchange closed on the day; empty for all exchanges.
+//   - day: The holiday; the time of day is ignored.
+//   - description: The name of the holiday.
+func (cal *TradingCalendar) AddHoliday(exchange Exchange, day time.Time, description string) {
+	cal.mu.Lock()
+	defer cal.mu.Unlock()
+
+	key := dayKey(day)
+	if cal.holidays[key] == nil {
+		cal.holidays[key] = make(map[Exchange]string)
+	}
+	cal.holidays[key][exchange] = description
+}
+
+// AddSpecialSession adds a session replacing the regular session of its day.
+func (cal *TradingCalendar) AddSpecialSession(session SpecialSession) {
+	cal.mu.Lock()
+	defer cal.mu.Unlock()
+
+	session.Date = midnight(session.Date)
+	key := dayKey(session.Date)
+	cal.special[key] = append(cal.special[key], session)
+	sort.Slice(cal.special[key], func(i, j int) bool { return cal.special[key][i].Open < cal.special[key][j].Open })
+}
+
+// Holiday reports whether an exchange is closed for a holiday on the day of t.
+//
+// Returns:
+//   - The description of the holiday and true if it is one; otherwise, an empty string and false.
+func (cal *TradingCalendar) Holiday(exchange Exchange, t time.Time) (string, bool) {
+	cal.mu.RLock()
+	defer cal.mu.RUnlock()
+
+	days := cal.holidays[dayKey(t)]
+	if description, ok := days[exchange]; ok {
+		return description, true
+	}
+	description, ok := days[""]
+	return description, ok
+}
+
+// SpecialSessions returns the special sessions of an exchange on the day of t, by open time.
+func (cal *TradingCalendar) SpecialSessions(exchange Exchange, t time.Time) []SpecialSession {
+	cal.mu.RLock()
+	defer cal.mu.RUnlock()
+
+	var sessions []SpecialSession
+	for _, s := range cal.special[dayKey(t)] {
+		if s.AppliesTo(exchange) {
+			sessions = append(sessions, s)
+		}
+	}
+	return sessions
+}
+
+// Clock returns the market clock of an exchange with the calendar attached.
+//
+// Parameters:
+//   - exchange: The exchange (e.g., ExchangeNSE, ExchangeMCX).
+//
+// Returns:
+//   - A pointer to a newly created MarketClock.
+func (cal *TradingCalendar) Clock(exchange Exchange) *MarketClock {
+	clock := ClockFor(exchange)
+	clock.Calendar = cal
+	return clock
+}
+
+// parseSpecialSession reads a special session from an untyped row of the holidays response.
+func parseSpecialSession(day time.Time, row []interface{}) (SpecialSession, bool) {
+	session := SpecialSession{Date: midnight(day)}
+	var times []time.Duration
+	var text []string
+
+	for _, value := range row {
+		s, ok := value.(string)
+		if !ok {
+			continue
+		}
+		s = strings.TrimSpace(s)
+		if offset, ok := parseClockTime(s); ok {
+			times = append(times, offset)
+			continue
+		}
+		if exchange, ok := parseSessionExchange(s); ok {
+			session.Exchanges = append(session.Exchanges, exchange)
+			continue
+		}
+		if s != "" {
+			text = append(text, s)
+		}
+	}
+
+	if len(times) < 2 || times[1] <= times[0] {
+		return SpecialSession{}, false
+	}
+	session.Open, session.Close = times[0], times[1]
+	session.Description = strings.Join(text, " ")
+	return session, true
+}
+
+// parseClockTime parses a time of day, alone or as part of a timestamp, into an offset from midnight.
+func parseClockTime(s string) (time.Duration, bool) {
+	for _, layout := range []string{"15:04", "15:04:05", "3:04 PM", "03:04 PM"} {
+		if t, err := time.Parse(layout, strings.ToUpper(s)); err == nil {
+			return sinceMidnight(t), true
+		}
+	}
+	if strings.Contains(s, ":") {
+		if t, ok := parseTimestamp(s); ok {
+			return sinceMidnight(t), true
+		}
+	}
+	return 0, false
+}
+
+// parseSessionExchange maps an exchange or segment name to the exchange selecting it.
+func parseSessionExchange(s string) (Exchange, bool) {
+	if exchange, err := ParseExchange(s); err == nil {
+		return exchange, true
+	}
+	switch normalizeEnum(s) {
+	case "EQ", "EQUITY", "CM", "CASH":
+		return ExchangeNSE, true
+	case "FO", "FNO", "DERIVATIVES":
+		return ExchangeNFO, true
+	case "CD", "CURRENCY":
+		return ExchangeCDS, true
+	case "COM", "COMMODITY":
+		return ExchangeMCX, true
+	}
+	return "", false
+}
+
+// venueOf returns the exchange operating the segment of e.
+func venueOf(e Exchange) Exchange {
+	switch e {
+	case ExchangeNFO, ExchangeCDS:
+		return ExchangeNSE
+	case ExchangeBFO, ExchangeBCD:
+		return ExchangeBSE
+	}
+	return e
+}
+
+// dayKey returns the calendar day of t in IST.
+func dayKey(t time.Time) string {
+	return t.In(IST).Format("2006-01-02")
+}
+
+// midnight returns the start of the day of t in IST.
+func midnight(t time.Time) time.Time {
+	y, m, d := t.In(IST).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, IST)
+}
@@ -0,0 +1,182 @@
+package tiqs
+
+import (
+	"math"
+	"strings"
+)
+
+// ChargeSegment is the segment a fill is charged in, which sets its statutory rates.
+type ChargeSegment string
+
+const (
+	ChargeEquityDelivery   ChargeSegment = "EQUITY_DELIVERY"   // Cash segment, delivery (CNC).
+	ChargeEquityIntraday   ChargeSegment = "EQUITY_INTRADAY"   // Cash segment, intraday.
+	ChargeEquityFutures    ChargeSegment = "EQUITY_FUTURES"    // Index and stock futures.
+	ChargeEquityOptions    ChargeSegment = "EQUITY_OPTIONS"    // Index and stock options.
+	ChargeCurrencyFutures  ChargeSegment = "CURRENCY_FUTURES"  // Currency futures.
+	ChargeCurrencyOptions  ChargeSegment = "CURRENCY_OPTIONS"  // Currency options.
+	ChargeCommodityFutures ChargeSegment = "COMMODITY_FUTURES" // Commodity futures.
+	ChargeCommodityOptions ChargeSegment = "COMMODITY_OPTIONS" // Commodity options.
+)
+
+// ChargeRates are the statutory charges of a segment, as fractions of turnover. The
+// turnover of options is their premium.
+type ChargeRates struct {
+	TransactionTaxBuy  float64 `json:"transactionTaxBuy"`  // STT or CTT on buys.
+	TransactionTaxSell float64 `json:"transactionTaxSell"` // STT or CTT on sells.
+	ExchangeTxnFee     float64 `json:"exchangeTxnFee"`     // Exchange transaction fee.
+	SebiCharges        float64 `json:"sebiCharges"`        // SEBI turnover fee.
+	StampDuty          float64 `json:"stampDuty"`          // Stamp duty, on buys only.
+	Ipft               float64 `json:"ipft"`               // Investor Protection Fund Trust fee.
+}
+
+// DefaultChargeRates are the NSE and MCX rates of each segment as of 2024. Rates change
+// with exchange circulars and budgets; override them in a ChargeSchedule where needed.
+var DefaultChargeRates = map[ChargeSegment]ChargeRates{
+	ChargeEquityDelivery:   {TransactionTaxBuy: 0.001, TransactionTaxSell: 0.001, ExchangeTxnFee: 0.0000297, SebiCharges: 0.000001, StampDuty: 0.00015, Ipft: 0.000001},
+	ChargeEquityIntraday:   {TransactionTaxSell: 0.00025, ExchangeTxnFee: 0.0000297, SebiCharges: 0.000001, StampDuty: 0.00003, Ipft: 0.000001},
+	ChargeEquityFutures:    {TransactionTaxSell: 0.0002, ExchangeTxnFee: 0.0000173, SebiCharges: 0.000001, StampDuty: 0.00002, Ipft: 0.000001},
+	ChargeEquityOptions:    {TransactionTaxSell: 0.001, ExchangeTxnFee: 0.0003503, SebiCharges: 0.000001, StampDuty: 0.00003, Ipft: 0.000005},
+	ChargeCurrencyFutures:  {ExchangeTxnFee: 0.0000035, SebiCharges: 0.000001, StampDuty: 0.000001, Ipft: 0.000001},
+	ChargeCurrencyOptions:  {ExchangeTxnFee: 0.000311, SebiCharges: 0.000001, StampDuty: 0.000001, Ipft: 0.000005},
+	ChargeCommodityFutures: {TransactionTaxSell: 0.0001, ExchangeTxnFee: 0.000021, SebiCharges: 0.000001, StampDuty: 0.00002},
+	ChargeCommodityOptions: {TransactionTaxSell: 0.0005, ExchangeTxnFee: 0.000418, SebiCharges: 0.000001, StampDuty: 0.00003},
+}
+
+// DefaultGSTRate is the GST levied on brokerage and exchange, SEBI and IPFT fees.
+const DefaultGSTRate = 0.18
+
+// ChargeBreakdown itemizes the charges of a fill or an order, following the charge
+// schema of the margin API (see MarginResponse.ChargeBreakdown). Amounts are in rupees.
+type ChargeBreakdown struct {
+	Brokerage      float64 `json:"brokerage" csv:"brokerage"`             // Brokerage.
+	TransactionTax float64 `json:"transactionTax" csv:"transaction_tax"`  // STT or CTT.
+	ExchangeTxnFee float64 `json:"exchangeTxnFee" csv:"exchange_txn_fee"` // Exchange transaction fee.
+	SebiCharges    float64 `json:"sebiCharges" csv:"sebi_charges"`        // SEBI turnover fee.
+	StampDuty      float64 `json:"stampDuty" csv:"stamp_duty"`            // Stamp duty.
+	Ipft           float64 `json:"ipft" csv:"ipft"`                       // IPFT fee.
+	Gst            float64 `json:"gst" csv:"gst"`                         // GST.
+	Total          float64 `json:"total" csv:"total"`                     // Sum of all charges.
+}
+
+// Add returns the sum of two breakdowns.
+func (b ChargeBreakdown) Add(other ChargeBreakdown) ChargeBreakdown {
+	return ChargeBreakdown{
+		Brokerage:      b.Brokerage + other.Brokerage,
+		TransactionTax: b.TransactionTax + other.TransactionTax,
+		ExchangeTxnFee: b.ExchangeTxnFee + other.ExchangeTxnFee,
+		SebiCharges:    b.SebiCharges + other.SebiCharges,
+		StampDuty:      b.StampDuty + other.StampDuty,
+		Ipft:           b.Ipft + other.Ipft,
+		Gst:            b.Gst + other.Gst,
+		Total:          b.Total + other.Total,
+	}
+}
+
+// ChargeBreakdown returns the charges reported by the margin API for the order.
+func (m MarginResponse) ChargeBreakdown() ChargeBreakdown {
+	c := m.Charge
+	return ChargeBreakdown{
+		Brokerage:      float64(c.Brokerage),
+		TransactionTax: float64(c.TransactionTax),
+		ExchangeTxnFee: c.ExchangeTxnFee,
+		SebiCharges:    c.SebiCharges,
+		StampDuty:      c.StampDuty,
+		Ipft:           c.Ipft,
+		Gst:            c.Gst.Total,
+		Total:          c.Total,
+	}
+}
+
+// ChargeSchedule is a ChargeModel estimating the brokerage and statutory charges of
+// fills from their segment, side and turnover.
+//
+// The estimate follows the items of the margin API's charges, which quotes one order at
+// a time; a schedule estimates the charges of whole trade books without a request per
+// fill. Charges are not rounded as on contract notes, so totals may differ by a few paise.
+type ChargeSchedule struct {
+	BrokeragePerFill float64                       // Flat brokerage per fill in rupees.
+	BrokerageRate    float64                       // Brokerage as a fraction of turnover.
+	BrokerageCap     float64                       // Largest brokerage per fill in rupees; zero for no cap.
+	GSTRate          float64                       // GST on brokerage and fees; zero uses DefaultGSTRate.
+	Rates            map[ChargeSegment]ChargeRates // Rates per segment; DefaultChargeRates if nil.
+}
+
+// Charges implements ChargeModel.
+func (s ChargeSchedule) Charges(trade Trade) float64 {
+	return s.Estimate(trade).Total
+}
+
+// Estimate itemizes the charges of a fill.
+//
+// Parameters:
+//   - trade: The fill, e.g., from GetTradeBook.
+//
+// Returns:
+//   - The charges of the fill in rupees.
+func (s ChargeSchedule) Estimate(trade Trade) ChargeBreakdown {
+	turnover := float64(parseInt(trade.FillShares)) * parseFloat(trade.FillPrice)
+	if turnover <= 0 {
+		return ChargeBreakdown{}
+	}
+
+	rates := s.Rates
+	if rates == nil {
+		rates = DefaultChargeRates
+	}
+	r := rates[ChargeSegmentOf(trade)]
+	buy := !strings.EqualFold(trade.TransactionType, string(TransactionSell))
+
+	var b ChargeBreakdown
+	b.Brokerage = s.BrokeragePerFill + turnover*s.BrokerageRate
+	if s.BrokerageCap > 0 {
+		b.Brokerage = math.Min(b.Brokerage, s.BrokerageCap)
+	}
+	if buy {
+		b.TransactionTax = turnover * r.TransactionTaxBuy
+		b.StampDuty = turnover * r.StampDuty
+	} else {
+		b.TransactionTax = turnover * r.TransactionTaxSell
+	}
+	b.ExchangeTxnFee = turnover * r.ExchangeTxnFee
+	b.SebiCharges = turnover * r.SebiCharges
+	b.Ipft = turnover * r.Ipft
+
+	gst := s.GSTRate
+	if gst == 0 {
+		gst = DefaultGSTRate
+	}
+	b.Gst = (b.Brokerage + b.ExchangeTxnFee + b.SebiCharges + b.Ipft) * gst
+	b.Total = b.Brokerage + b.TransactionTax + b.ExchangeTxnFee + b.SebiCharges + b.StampDuty + b.Ipft + b.Gst
+	return b
+}
+
+// ChargeSegmentOf returns the segment a fill is charged in, from its exchange, product
+// and trading symbol; options are told apart by their CE or PE suffix.
+func ChargeSegmentOf(trade Trade) ChargeSegment {
+	symbol := strings.ToUpper(trade.Symbol)
+	option := strings.HasSuffix(symbol, "CE") || strings.HasSuffix(symbol, "PE")
+
+	switch Exchange(strings.ToUpper(trade.Exchange)) {
+	case ExchangeNFO, ExchangeBFO:
+		if option {
+			return ChargeEquityOptions
+		}
+		return ChargeEquityFutures
+	case ExchangeCDS, ExchangeBCD:
+		if option {
+			return ChargeCurrencyOptions
+		}
+		return ChargeCurrencyFutures
+	case ExchangeMCX:
+		if option {
+			return ChargeCommodityOptions
+		}
+		return ChargeCommodityFutures
+	}
+
+	if strings.EqualFold(trade.Product, string(ProductCNC)) {
+		return ChargeEquityDelivery
+	}
+	return ChargeEquityIntraday
+}
@@ -2,7 +2,11 @@
 package tiqs
 
 import (
-	"github.com/rs/zerolog/log"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
 	"github.com/valyala/fasthttp"
 )
 
@@ -13,6 +17,9 @@ type Config struct {
 	Token        string // Authentication token for API requests.
 	BaseURL      string // Base URL of the Tiqs API.
 	RefreshToken string // Token used to refresh authentication when expired.
+
+	MaxResponseSize int64      // Size limit in bytes for streamed downloads; zero uses DefaultMaxResponseSize.
+	APIVersion      APIVersion // Version of the REST API to use; empty uses APIVersion1.
 }
 
 // Client is the main struct for interacting with the Tiqs API.
@@ -21,6 +28,32 @@ type Config struct {
 type Client struct {
 	Config     Config           // Configuration settings for the API client.
 	HTTPClient *fasthttp.Client // HTTP client for executing requests.
+
+	staleGuard  *StaleGuard         // Optional guard rejecting orders on stale prices.
+	sectors     SectorProvider      // Optional sector mapping used by exposure reports.
+	instruments *InstrumentStore    // Optional instrument master used for price conversion and lookups.
+	health      *HealthMonitor      // Optional monitor tracking broker health.
+	duplicates  *DuplicateGuard     // Optional guard rejecting repeated identical orders.
+	symbols     *SymbolControl      // Optional allow/deny list for new orders.
+	progress    ProgressFunc        // Optional callback notified while large responses download.
+	signer      Signer              // Optional signer replacing the default SHA256 checksum.
+	signRules   map[string]SignRule // Signing rules keyed by endpoint prefix.
+
+	excludePreOpen  bool                        // Whether pre-open candles are dropped from historical data.
+	chunkHistory    bool                        // Whether long historical ranges are fetched in chunks.
+	historyWindows  map[string]time.Duration    // Per-interval overrides of the historical window.
+	rateLimits      map[RateClass]*classLimiter // Optional budget of each rate class, applied to every request.
+	faults          *FaultInjector              // Optional injector of random failures, for resilience testing.
+	interlock       *Interlock                  // Optional interlock refusing orders and other account actions until live trading is armed.
+	sim             *Simulator                  // Optional simulator executing orders instead of the exchange.
+	hedging         *hedger                     // Optional policy hedging slow reads.
+	tokens          TokenStore                  // Optional store persisting the session across restarts.
+	freezeLimits    map[string]int64            // Largest order quantity per underlying; DefaultFreezeLimits if nil.
+	instrumentCache *InstrumentCache            // Optional disk cache of the instrument master.
+	risk            *RiskManager                // Optional kill switch refusing orders once risk limits are breached.
+	retry           *RetryPolicy                // Optional policy retrying requests that failed with a transient error.
+	orderWatcher    *OrderWatcher               // Optional watcher waking WaitForOrder on order socket updates.
+	logger          *zerolog.Logger             // Optional logger replacing the default logger (see SetLogger).
 }
 
 // NewClient initializes a new SDK client with the provided application credentials.
@@ -45,7 +78,8 @@ func NewClient(appID, appSecret string) *Client {
 // request sends an HTTP API request to the Tiqs server and retrieves the response.
 //
 // This function constructs an HTTP request with the required authentication headers
-// and executes it using the `fasthttp` client.
+// and executes it using the `fasthttp` client, once the budget of the request's rate
+// class allows it (see SetRateLimits).
 //
 // Parameters:
 //   - endpoint: The API endpoint (relative to BaseURL) to send the request to.
@@ -54,35 +88,125 @@ func NewClient(appID, appSecret string) *Client {
 //
 // Returns:
 //   - A byte slice containing the response body if successful.
-//   - An error if the request fails.
+//   - An error if the request fails, or an *APIError if the server answers with a 4xx or 5xx status.
 func (c *Client) request(endpoint string, method string, payload []byte) ([]byte, error) {
-	url := c.Config.BaseURL + endpoint
-	log.Info().Str("url", url).Msg("Making request")
+	c.log().Debug().Str("url", c.Config.BaseURL+endpoint).Msg("Making request")
+
+	var body []byte
+	err := c.exchange(endpoint, method, payload, func(b []byte) error {
+		// The response buffer is pooled, so keep a copy of the body.
+		body = append([]byte(nil), b...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// exchange sends an HTTP API request and passes the response body to consume before the
+// pooled response is released. Unlike request, it does not log successful requests, which
+// keeps the order fast path free of logging.
+//
+// Parameters:
+//   - endpoint: The API endpoint (relative to BaseURL) to send the request to.
+//   - method: The HTTP method ("GET" or "POST").
+//   - payload: The request body (for POST requests).
+//   - consume: Called with the response body; it must not retain the slice.
+//
+// Requests failing with a transient error are retried as the retry policy allows (see
+// SetRetryPolicy).
+//
+// Returns:
+//   - The error returned by consume, an error if the request fails, or an *APIError if
+//     the server answers with a 4xx or 5xx status.
+func (c *Client) exchange(endpoint string, method string, payload []byte, consume func([]byte) error) error {
+	// Paper orders never reach the exchange, so they bypass the interlock and rate limits.
+	if c.sim != nil {
+		if body, ok := c.sim.serve(c, method, endpoint, payload); ok {
+			return consume(body)
+		}
+	}
+
+	if err := c.checkInterlock(method, endpoint); err != nil {
+		return err
+	}
 
+	return c.withRetries(method, endpoint, func() error {
+		if budget, ok := c.hedgeBudget(method, endpoint); ok {
+			return c.hedgedExchange(endpoint, method, payload, budget, consume)
+		}
+
+		if err := c.waitRate(method, endpoint); err != nil {
+			return err
+		}
+		return c.send(endpoint, method, payload, consume)
+	})
+}
+
+// send executes a request whose rate budget was already taken and passes the response
+// body to consume before the pooled response is released.
+func (c *Client) send(endpoint string, method string, payload []byte, consume func([]byte) error) error {
 	req := fasthttp.AcquireRequest()
 	defer fasthttp.ReleaseRequest(req)
-	req.SetRequestURI(url)
+	req.SetRequestURI(c.Config.BaseURL + endpoint)
 	req.Header.Set("appId", c.Config.AppID)
 	req.Header.Set("token", c.Config.Token)
+	c.signRequest(req, endpoint, payload)
 
-	if method == "POST" {
-		req.Header.SetMethod("POST")
+	faults, err := c.injectFaults(method, endpoint)
+	if err != nil {
+		c.log().Error().Err(err).Msg("API request failed")
+		return err
+	}
+
+	req.Header.SetMethod(method)
+	if payload != nil {
 		req.SetBody(payload)
-	} else {
-		req.Header.SetMethod("GET")
 	}
 
 	resp := fasthttp.AcquireResponse()
 	defer fasthttp.ReleaseResponse(resp)
 
 	// Execute the request using the fasthttp client.
-	err := c.HTTPClient.Do(req, resp)
+	err = c.HTTPClient.Do(req, resp)
 	if err != nil {
-		log.Error().Err(err).Msg("API request failed")
-		return nil, err
+		c.log().Error().Err(err).Msg("API request failed")
+		c.recordHealth(err)
+		return err
 	}
 
-	return resp.Body(), nil
+	if status := resp.StatusCode(); status >= fasthttp.StatusInternalServerError {
+		c.recordHealth(fmt.Errorf("server error: HTTP %d", status))
+	} else {
+		c.recordHealth(nil)
+	}
+
+	if status := resp.StatusCode(); status >= fasthttp.StatusBadRequest {
+		if status == fasthttp.StatusTooManyRequests {
+			c.rateLimited(method, endpoint)
+		}
+		err := newAPIError(method+" "+endpoint, endpoint, status, resp.Body())
+		c.log().Error().Err(err).Msg("API request failed")
+		return err
+	}
+
+	if faults.malformed {
+		return consume(c.faults.truncate(resp.Body()))
+	}
+	return consume(resp.Body())
+}
+
+// recordHealth reports the outcome of a REST request to the attached health monitor.
+func (c *Client) recordHealth(err error) {
+	if c.health == nil {
+		return
+	}
+	if err != nil {
+		c.health.RecordFailure(HealthSourceREST, err)
+	} else {
+		c.health.RecordSuccess(HealthSourceREST)
+	}
 }
 
 // rawRequest sends an HTTP request to a fully specified URL and retrieves the response.
@@ -102,11 +226,9 @@ func (c *Client) rawRequest(url string, method string, payload []byte) ([]byte,
 	defer fasthttp.ReleaseRequest(req)
 	req.SetRequestURI(url)
 
-	if method == "POST" {
-		req.Header.SetMethod("POST")
+	req.Header.SetMethod(method)
+	if payload != nil {
 		req.SetBody(payload)
-	} else {
-		req.Header.SetMethod("GET")
 	}
 
 	resp := fasthttp.AcquireResponse()
@@ -115,7 +237,7 @@ func (c *Client) rawRequest(url string, method string, payload []byte) ([]byte,
 	// Execute the request using the fasthttp client.
 	err := c.HTTPClient.Do(req, resp)
 	if err != nil {
-		log.Error().Err(err).Msg("API request failed")
+		c.log().Error().Err(err).Msg("API request failed")
 		return nil, err
 	}
 
@@ -151,3 +273,89 @@ func (c *Client) GetToken() string {
 func (c *Client) GetRefreshToken() string {
 	return c.Config.RefreshToken
 }
+
+// Credentials returns the application ID and current token of the client.
+//
+// It lets a Client act as a ticks.CredentialsProvider, so websockets created with
+// ticks.NewWSWithCredentials(client) reconnect with the token set by the latest SetToken.
+//
+// Returns:
+//   - The application ID and token, or an error if no token is set.
+func (c *Client) Credentials(ctx context.Context) (string, string, error) {
+	if c.Config.Token == "" {
+		return "", "", fmt.Errorf("no token set, authenticate first")
+	}
+	return c.Config.AppID, c.Config.Token, nil
+}
+
+// SetStaleGuard attaches a StaleGuard to the client.
+//
+// Once attached, market quotes fetched through the client are recorded by the guard and,
+// if the guard has BlockOrders enabled, PlaceOrder rejects orders on tokens with stale prices.
+//
+// Parameters:
+//   - guard: The guard to attach, or nil to detach the current one.
+func (c *Client) SetStaleGuard(guard *StaleGuard) {
+	c.staleGuard = guard
+}
+
+// SetSectorProvider attaches a sector mapping to the client.
+//
+// Once attached, GetExposureSummary additionally groups exposure by sector.
+//
+// Parameters:
+//   - provider: The sector mapping to use, or nil to detach the current one.
+func (c *Client) SetSectorProvider(provider SectorProvider) {
+	c.sectors = provider
+}
+
+// SetHealthMonitor attaches a HealthMonitor to the client.
+//
+// Once attached, the outcome of every REST request is reported to the monitor and, if the
+// monitor has BlockOrders enabled, PlaceOrder refuses new orders while the broker is degraded.
+//
+// Parameters:
+//   - monitor: The monitor to attach, or nil to detach the current one.
+func (c *Client) SetHealthMonitor(monitor *HealthMonitor) {
+	c.health = monitor
+}
+
+// SetDuplicateGuard attaches a DuplicateGuard to the client.
+//
+// Once attached, PlaceOrder rejects an order identical to one placed within the guard's
+// window unless the order sets AllowDuplicate.
+//
+// Parameters:
+//   - guard: The guard to attach, or nil to detach the current one.
+func (c *Client) SetDuplicateGuard(guard *DuplicateGuard) {
+	c.duplicates = guard
+}
+
+// SetDownloadProgress sets a callback notified while large responses, such as the
+// instrument master and historical candles, are downloaded.
+//
+// Parameters:
+//   - fn: The callback, or nil to disable progress reporting.
+func (c *Client) SetDownloadProgress(fn ProgressFunc) {
+	c.progress = fn
+}
+
+// SetIncludePreOpen selects whether candles from the pre-open session are returned by
+// GetHistoricalData. They are included by default and flagged with PreOpen.
+//
+// Parameters:
+//   - include: false to drop pre-open candles, e.g., for opening-range calculations.
+func (c *Client) SetIncludePreOpen(include bool) {
+	c.excludePreOpen = !include
+}
+
+// SetSymbolControl attaches a SymbolControl to the client.
+//
+// Once attached, PlaceOrder refuses orders in symbols that are denied or missing from a
+// non-empty allow list.
+//
+// Parameters:
+//   - control: The control to attach, or nil to detach the current one.
+func (c *Client) SetSymbolControl(control *SymbolControl) {
+	c.symbols = control
+}
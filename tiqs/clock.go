@@ -0,0 +1,155 @@
+package tiqs
+
+import (
+	"time"
+)
+
+// IST is the Indian Standard Time zone used by all Indian exchanges.
+var IST = time.FixedZone("IST", 5*60*60+30*60)
+
+// MarketClock describes the regular trading session of an exchange.
+//
+// With a Calendar attached, holidays of the exchange are closed and days with special
+// sessions are open only during those sessions, so Muhurat and shifted sessions are
+// traded at their own times.
+type MarketClock struct {
+	Location *time.Location   // Time zone in which Open and Close are expressed.
+	PreOpen  time.Duration    // Pre-open (call auction) start as an offset from midnight; zero if there is none.
+	Open     time.Duration    // Session open as an offset from midnight (e.g., 9h15m).
+	Close    time.Duration    // Session close as an offset from midnight (e.g., 15h30m).
+	Exchange Exchange         // Exchange the holidays and special sessions of the Calendar are looked up for.
+	Calendar *TradingCalendar // Optional holidays and special sessions; nil for weekdays only.
+}
+
+// NewMarketClock returns the clock for the regular NSE/BSE equity and F&O session
+// (09:15 to 15:30 IST, Monday to Friday), preceded by the pre-open call auction from 09:00.
+//
+// Returns:
+//   - A pointer to a MarketClock configured for the regular equity session.
+func NewMarketClock() *MarketClock {
+	return &MarketClock{
+		Location: IST,
+		PreOpen:  9 * time.Hour,
+		Open:     9*time.Hour + 15*time.Minute,
+		Close:    15*time.Hour + 30*time.Minute,
+		Exchange: ExchangeNSE,
+	}
+}
+
+// NewCurrencyClock returns the clock for the NSE/BSE currency derivatives session
+// (09:00 to 17:00 IST, Monday to Friday). The currency segment has no pre-open session.
+//
+// Returns:
+//   - A pointer to a MarketClock configured for the currency session.
+func NewCurrencyClock() *MarketClock {
+	return &MarketClock{
+		Location: IST,
+		Open:     9 * time.Hour,
+		Close:    17 * time.Hour,
+		Exchange: ExchangeCDS,
+	}
+}
+
+// NewCommodityClock returns the clock for the MCX session (09:00 to 23:30 IST, Monday to
+// Friday). The evening session closes at 23:55 while US daylight saving time is not in
+// effect; adjust Close for those months.
+//
+// Returns:
+//   - A pointer to a MarketClock configured for the commodity session.
+func NewCommodityClock() *MarketClock {
+	return &MarketClock{
+		Location: IST,
+		Open:     9 * time.Hour,
+		Close:    23*time.Hour + 30*time.Minute,
+		Exchange: ExchangeMCX,
+	}
+}
+
+// ClockFor returns the market clock of the segment an exchange belongs to.
+//
+// Parameters:
+//   - exchange: The exchange (e.g., ExchangeNSE, ExchangeMCX).
+//
+// Returns:
+//   - A pointer to a newly created MarketClock; the equity clock for unknown exchanges.
+func ClockFor(exchange Exchange) *MarketClock {
+	var clock *MarketClock
+	switch exchange.Segment() {
+	case SegmentCurrency:
+		clock = NewCurrencyClock()
+	case SegmentCommodity:
+		clock = NewCommodityClock()
+	default:
+		clock = NewMarketClock()
+	}
+	if exchange != "" {
+		clock.Exchange = exchange
+	}
+	return clock
+}
+
+// IsOpen reports whether the market is open at the given instant.
+//
+// Parameters:
+//   - t: The instant to check.
+//
+// Returns:
+//   - true if t falls on a weekday within the session window, or within a special session
+//     of the attached calendar; otherwise, false.
+func (m *MarketClock) IsOpen(t time.Time) bool {
+	if m.Calendar != nil {
+		if sessions := m.Calendar.SpecialSessions(m.Exchange, t); len(sessions) > 0 {
+			for _, s := range sessions {
+				if open, close := s.Window(); !t.Before(open) && t.Before(close) {
+					return true
+				}
+			}
+			return false
+		}
+		if _, ok := m.Calendar.Holiday(m.Exchange, t); ok {
+			return false
+		}
+	}
+
+	local := t.In(m.Location)
+	if local.Weekday() == time.Saturday || local.Weekday() == time.Sunday {
+		return false
+	}
+
+	offset := sinceMidnight(local)
+	return offset >= m.Open && offset < m.Close
+}
+
+// IsPreOpen reports whether the given instant falls in the pre-open session, i.e., the
+// call auction and buffer period between PreOpen and Open.
+//
+// Parameters:
+//   - t: The instant to check.
+//
+// Returns:
+//   - true if t falls on a weekday within the pre-open window; otherwise, false. Holidays
+//     and days with special sessions of the attached calendar have no pre-open session.
+func (m *MarketClock) IsPreOpen(t time.Time) bool {
+	if m.PreOpen <= 0 {
+		return false
+	}
+	if m.Calendar != nil {
+		if _, ok := m.Calendar.Holiday(m.Exchange, t); ok || len(m.Calendar.SpecialSessions(m.Exchange, t)) > 0 {
+			return false
+		}
+	}
+
+	local := t.In(m.Location)
+	if local.Weekday() == time.Saturday || local.Weekday() == time.Sunday {
+		return false
+	}
+
+	offset := sinceMidnight(local)
+	return offset >= m.PreOpen && offset < m.Open
+}
+
+// sinceMidnight returns the duration elapsed since midnight in t's location.
+func sinceMidnight(t time.Time) time.Duration {
+	h, m, s := t.Clock()
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(s)*time.Second + time.Duration(t.Nanosecond())
+}
@@ -0,0 +1,66 @@
+package tiqs
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timestampLayouts lists the timestamp formats used across Tiqs API responses.
+var timestampLayouts = []string{
+	"02-01-2006 15:04:05",
+	"15:04:05 02-01-2006",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	time.RFC3339,
+	"02-Jan-2006 15:04:05",
+	"02-01-2006",
+	"2006-01-02",
+	"02-Jan-2006",
+	"02Jan2006",
+}
+
+// parseFloat converts a numeric string returned by the API into a float64.
+//
+// The Tiqs API returns most numeric fields as strings. Empty or malformed values
+// are treated as zero so that aggregations over partially populated rows still work.
+func parseFloat(s string) float64 {
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+// parseInt converts a numeric string returned by the API into an int64.
+//
+// Empty or malformed values are treated as zero.
+func parseInt(s string) int64 {
+	v, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil {
+		return int64(parseFloat(s))
+	}
+	return v
+}
+
+// parseTimestamp parses a timestamp returned by the API into a time in IST.
+//
+// Both formatted timestamps and Unix epoch seconds are accepted. Formatted timestamps
+// without a zone are interpreted as IST.
+func parseTimestamp(s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, false
+	}
+
+	if epoch, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(epoch, 0).In(IST), true
+	}
+
+	for _, layout := range timestampLayouts {
+		if t, err := time.ParseInLocation(layout, s, IST); err == nil {
+			return t.In(IST), true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,173 @@
+package tiqs
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// ChargeModel estimates the charges (brokerage, taxes and fees) paid on a fill.
+type ChargeModel interface {
+	// Charges returns the charges for a fill in rupees.
+	Charges(trade Trade) float64
+}
+
+// FlatCharges is a simple ChargeModel with a fixed fee per fill plus a percentage of turnover.
+type FlatCharges struct {
+	PerFill float64 // Fixed charge per fill in rupees.
+	Rate    float64 // Charge as a fraction of turnover (e.g., 0.0005 for 0.05%).
+}
+
+// Charges implements ChargeModel.
+func (f FlatCharges) Charges(trade Trade) float64 {
+	turnover := parseFloat(trade.FillShares) * parseFloat(trade.FillPrice)
+	return f.PerFill + turnover*f.Rate
+}
+
+// CostBasis represents the position of an instrument rebuilt from individual fills.
+type CostBasis struct {
+	Exchange    string  `json:"exchange"`    // Exchange of the instrument.
+	Token       string  `json:"token"`       // Unique identifier for the instrument.
+	Symbol      string  `json:"symbol"`      // Trading symbol of the instrument.
+	Product     string  `json:"product"`     // Product type of the position.
+	NetQty      int64   `json:"netQty"`      // Open quantity; negative for short positions.
+	AvgPrice    float64 `json:"avgPrice"`    // Average price of the open quantity.
+	RealizedPnL float64 `json:"realizedPnL"` // P&L of closed quantity before charges.
+	Charges     float64 `json:"charges"`     // Total charges of all fills.
+	Breakeven   float64 `json:"breakeven"`   // Price at which closing the open quantity nets zero after charges.
+	Fills       int     `json:"fills"`       // Number of fills processed.
+}
+
+// NetPnL returns the realized P&L after charges.
+func (b CostBasis) NetPnL() float64 {
+	return b.RealizedPnL - b.Charges
+}
+
+// ComputeCostBasis rebuilds positions from fills and recomputes their average price and breakeven.
+//
+// The broker's netted average price mixes the prices of quantity that was already closed
+// with the price of quantity that is still open, which is misleading after partial
+// intraday round trips. Here fills are replayed in time order: adding to a position
+// updates its weighted average price, reducing it realizes P&L against that average,
+// and crossing through zero opens the remainder at the fill price. The breakeven then
+// accounts for realized P&L and charges.
+//
+// Only the fills passed in are considered, so carry-forward quantity from previous days
+// is not included when computing from the day's trade book.
+//
+// Parameters:
+//   - trades: The fills to replay (e.g., from GetTradeBook).
+//   - charges: The charge model, or nil to ignore charges.
+//
+// Returns:
+//   - One CostBasis per instrument and product, ordered by symbol.
+func ComputeCostBasis(trades []Trade, charges ChargeModel) []CostBasis {
+	ordered := append([]Trade(nil), trades...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return fillTime(ordered[i]).Before(fillTime(ordered[j]))
+	})
+
+	byKey := make(map[string]*CostBasis)
+	var keys []string
+	for _, t := range ordered {
+		key := t.Token + ":" + t.Product
+		basis, ok := byKey[key]
+		if !ok {
+			basis = &CostBasis{Exchange: t.Exchange, Token: t.Token, Symbol: t.Symbol, Product: t.Product}
+			byKey[key] = basis
+			keys = append(keys, key)
+		}
+		basis.apply(t)
+		if charges != nil {
+			basis.Charges += charges.Charges(t)
+		}
+	}
+
+	result := make([]CostBasis, 0, len(keys))
+	for _, key := range keys {
+		basis := byKey[key]
+		if basis.NetQty != 0 {
+			basis.Breakeven = basis.AvgPrice - basis.NetPnL()/float64(basis.NetQty)
+		}
+		result = append(result, *basis)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Symbol != result[j].Symbol {
+			return result[i].Symbol < result[j].Symbol
+		}
+		return result[i].Product < result[j].Product
+	})
+	return result
+}
+
+// GetCostBasis recomputes average prices and breakevens from the day's trade book.
+//
+// Parameters:
+//   - charges: The charge model, or nil to ignore charges.
+//
+// Returns:
+//   - One CostBasis per instrument and product traded today if successful.
+//   - An error if the trade book cannot be retrieved.
+func (c *Client) GetCostBasis(charges ChargeModel) ([]CostBasis, error) {
+	trades, err := c.GetTradeBook()
+	if err != nil {
+		return nil, err
+	}
+
+	result := ComputeCostBasis(trades, charges)
+	c.log().Info().Int("positions", len(result)).Msg("Cost basis computed successfully")
+	return result, nil
+}
+
+// apply replays a single fill against the position.
+func (b *CostBasis) apply(t Trade) {
+	qty := parseInt(t.FillShares)
+	price := parseFloat(t.FillPrice)
+	if qty == 0 {
+		return
+	}
+	if strings.EqualFold(t.TransactionType, string(TransactionSell)) {
+		qty = -qty
+	}
+	b.Fills++
+
+	switch {
+	case b.NetQty == 0 || (b.NetQty > 0) == (qty > 0):
+		// Opening or adding: update the weighted average price.
+		total := b.NetQty + qty
+		b.AvgPrice = (b.AvgPrice*float64(b.NetQty) + price*float64(qty)) / float64(total)
+		b.NetQty = total
+	default:
+		// Reducing: realize P&L on the closed quantity at the current average.
+		closed := min(abs64(qty), abs64(b.NetQty))
+		if b.NetQty > 0 {
+			b.RealizedPnL += (price - b.AvgPrice) * float64(closed)
+		} else {
+			b.RealizedPnL += (b.AvgPrice - price) * float64(closed)
+		}
+
+		b.NetQty += qty
+		switch {
+		case b.NetQty == 0:
+			b.AvgPrice = 0
+		case (b.NetQty > 0) == (qty > 0):
+			// Crossed through zero: the remainder opens at the fill price.
+			b.AvgPrice = price
+		}
+	}
+}
+
+// fillTime returns the time of a fill, or the zero time if it cannot be parsed.
+func fillTime(t Trade) time.Time {
+	ts, _ := parseTimestamp(t.FillTime)
+	return ts
+}
+
+// abs64 returns the absolute value of n.
+func abs64(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
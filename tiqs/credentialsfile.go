@@ -0,0 +1,200 @@
+package tiqs
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// credentialsFileVersion is the version of the encrypted credentials file format.
+const credentialsFileVersion = 1
+
+// credentialsKDFIterations is the number of PBKDF2 iterations deriving the file key.
+const credentialsKDFIterations = 600_000
+
+// LoginCredentials holds everything needed to log in without user interaction.
+type LoginCredentials struct {
+	AppID      string    `json:"appId"`      // Application ID.
+	AppSecret  string    `json:"appSecret"`  // Application secret.
+	UserID     string    `json:"userId"`     // User ID of the account.
+	Password   string    `json:"password"`   // Password of the account.
+	TOTPSecret string    `json:"totpSecret"` // Base32 TOTP secret of the account.
+	CreatedAt  time.Time `json:"createdAt"`  // Time the credentials were saved.
+}
+
+// credentialsEnvelope is the on-disk form of an encrypted credentials file.
+type credentialsEnvelope struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// WriteCredentialsFile encrypts credentials with a passphrase and writes them to path.
+//
+// The key is derived from the passphrase with PBKDF2-SHA256 and a random salt, and the
+// credentials are sealed with AES-256-GCM. The file is replaced atomically and is only
+// readable by its owner.
+//
+// Parameters:
+//   - path: The path of the credentials file.
+//   - passphrase: The passphrase protecting the file; must not be empty.
+//   - creds: The credentials to save.
+//
+// Returns:
+//   - An error if the credentials cannot be encrypted or written.
+func WriteCredentialsFile(path, passphrase string, creds LoginCredentials) error {
+	if passphrase == "" {
+		return fmt.Errorf("credentials file requires a passphrase")
+	}
+	if creds.CreatedAt.IsZero() {
+		creds.CreatedAt = time.Now()
+	}
+
+	plaintext, err := json.Marshal(creds)
+	if err != nil {
+		return fmt.Errorf("error encoding credentials: %w", err)
+	}
+
+	env := credentialsEnvelope{
+		Version:    credentialsFileVersion,
+		KDF:        "pbkdf2-sha256",
+		Iterations: credentialsKDFIterations,
+		Salt:       make([]byte, 16),
+	}
+	if _, err := rand.Read(env.Salt); err != nil {
+		return fmt.Errorf("error generating salt: %w", err)
+	}
+
+	aead, err := credentialsCipher(passphrase, env.Salt, env.Iterations)
+	if err != nil {
+		return err
+	}
+	env.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(env.Nonce); err != nil {
+		return fmt.Errorf("error generating nonce: %w", err)
+	}
+	env.Ciphertext = aead.Seal(nil, env.Nonce, plaintext, nil)
+
+	data, err := json.MarshalIndent(env, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding credentials file: %w", err)
+	}
+	if err := writeFileAtomic(path, data, 0o600); err != nil {
+		return fmt.Errorf("error writing credentials file: %w", err)
+	}
+	return nil
+}
+
+// ReadCredentialsFile decrypts the credentials written by WriteCredentialsFile.
+//
+// Parameters:
+//   - path: The path of the credentials file.
+//   - passphrase: The passphrase the file was written with.
+//
+// Returns:
+//   - The decrypted credentials.
+//   - An error if the file cannot be read, has an unknown format or the passphrase is wrong.
+func ReadCredentialsFile(path, passphrase string) (LoginCredentials, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return LoginCredentials{}, fmt.Errorf("error reading credentials file: %w", err)
+	}
+
+	var env credentialsEnvelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return LoginCredentials{}, fmt.Errorf("error parsing credentials file: %w", err)
+	}
+	if env.Version != credentialsFileVersion || env.KDF != "pbkdf2-sha256" || env.Iterations <= 0 {
+		return LoginCredentials{}, fmt.Errorf("unsupported credentials file: version %d, kdf %q", env.Version, env.KDF)
+	}
+
+	aead, err := credentialsCipher(passphrase, env.Salt, env.Iterations)
+	if err != nil {
+		return LoginCredentials{}, err
+	}
+	if len(env.Nonce) != aead.NonceSize() {
+		return LoginCredentials{}, fmt.Errorf("invalid credentials file nonce")
+	}
+	plaintext, err := aead.Open(nil, env.Nonce, env.Ciphertext, nil)
+	if err != nil {
+		return LoginCredentials{}, fmt.Errorf("cannot decrypt credentials file: wrong passphrase or corrupted file")
+	}
+
+	var creds LoginCredentials
+	if err := json.Unmarshal(plaintext, &creds); err != nil {
+		return LoginCredentials{}, fmt.Errorf("error parsing credentials: %w", err)
+	}
+	return creds, nil
+}
+
+// LoginWithCredentialsFile creates a client from an encrypted credentials file and logs in.
+//
+// This is how unattended processes log in after the credentials were saved with RunSetup.
+//
+// Parameters:
+//   - path: The path of the credentials file.
+//   - passphrase: The passphrase the file was written with.
+//
+// Returns:
+//   - A pointer to the logged in Client.
+//   - An error if the file cannot be decrypted or the login fails.
+func LoginWithCredentialsFile(path, passphrase string) (*Client, error) {
+	creds, err := ReadCredentialsFile(path, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	client := NewClient(creds.AppID, creds.AppSecret)
+	if err := client.AutoLogin(creds.UserID, creds.Password, creds.TOTPSecret); err != nil {
+		return nil, fmt.Errorf("login failed: %w", err)
+	}
+	return client, nil
+}
+
+// credentialsCipher derives the file key from a passphrase and returns its AES-GCM cipher.
+func credentialsCipher(passphrase string, salt []byte, iterations int) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(pbkdf2SHA256([]byte(passphrase), salt, iterations, 32))
+	if err != nil {
+		return nil, fmt.Errorf("error creating cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// pbkdf2SHA256 derives a key of keyLen bytes with PBKDF2 (RFC 8018) using HMAC-SHA256.
+func pbkdf2SHA256(password, salt []byte, iterations, keyLen int) []byte {
+	prf := hmac.New(sha256.New, password)
+	size := prf.Size()
+
+	var key []byte
+	var counter [4]byte
+	for block := 1; len(key) < keyLen; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		binary.BigEndian.PutUint32(counter[:], uint32(block))
+		prf.Write(counter[:])
+		u := prf.Sum(nil)
+
+		t := make([]byte, size)
+		copy(t, u)
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		key = append(key, t...)
+	}
+	return key[:keyLen]
+}
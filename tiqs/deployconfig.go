@@ -0,0 +1,530 @@
+package tiqs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Abhi13027/go-tiqs/ticks"
+	"github.com/rs/zerolog"
+	"gopkg.in/yaml.v3"
+)
+
+// Duration is a time.Duration written as a Go duration string (e.g., "30s", "5m") in
+// configuration files.
+type Duration time.Duration
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"30s\": %w", err)
+	}
+	return d.parse(s)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (d *Duration) UnmarshalYAML(node *yaml.Node) error {
+	return d.parse(node.Value)
+}
+
+// parse sets the duration from a Go duration string.
+func (d *Duration) parse(s string) error {
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("invalid duration %q: %w", s, err)
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// DeploymentConfig is the declarative description of a deployment: client settings,
+// watchlists, risk limits, notifier targets and strategy parameters.
+//
+// It is loaded from YAML or JSON with LoadDeploymentConfig, so that what runs in
+// production is reviewed like code instead of being hardcoded in main(). Secrets are never
+// stored in the file; it names the environment variables holding them.
+type DeploymentConfig struct {
+	Client     ClientConfig     `json:"client" yaml:"client"`
+	WebSocket  *WebSocketConfig `json:"websocket,omitempty" yaml:"websocket,omitempty"`
+	Session    SessionConfig    `json:"session" yaml:"session"`
+	Risk       RiskConfig       `json:"risk" yaml:"risk"`
+	Watchlists []Watchlist      `json:"watchlists,omitempty" yaml:"watchlists,omitempty"`
+	Notifiers  []NotifierTarget `json:"notifiers,omitempty" yaml:"notifiers,omitempty"`
+	Strategies []StrategyConfig `json:"strategies,omitempty" yaml:"strategies,omitempty"`
+}
+
+// ClientConfig configures the REST client.
+type ClientConfig struct {
+	AppIDEnv        string `json:"appIdEnv" yaml:"appIdEnv"`                                   // Environment variable holding the application ID.
+	AppSecretEnv    string `json:"appSecretEnv" yaml:"appSecretEnv"`                           // Environment variable holding the application secret.
+	TokenEnv        string `json:"tokenEnv,omitempty" yaml:"tokenEnv,omitempty"`               // Optional environment variable holding an access token.
+	BaseURL         string `json:"baseUrl,omitempty" yaml:"baseUrl,omitempty"`                 // API base URL; the production URL if empty.
+	MaxResponseSize int64  `json:"maxResponseSize,omitempty" yaml:"maxResponseSize,omitempty"` // Download size limit in bytes; zero for the default.
+	APIVersion      string `json:"apiVersion,omitempty" yaml:"apiVersion,omitempty"`           // REST API version, v1 or one added with RegisterEndpoint; v1 if empty.
+	SessionFile     string `json:"sessionFile,omitempty" yaml:"sessionFile,omitempty"`         // File persisting the session across restarts (see FileTokenStore).
+	InstrumentCache string `json:"instrumentCache,omitempty" yaml:"instrumentCache,omitempty"` // Directory caching the instrument master for the trade date.
+	LogLevel        string `json:"logLevel,omitempty" yaml:"logLevel,omitempty"`               // Minimum level logged by the client and websockets (e.g., "warn", "disabled"); unchanged if empty.
+
+	Paper *PaperConfig `json:"paper,omitempty" yaml:"paper,omitempty"` // Execute orders in a Simulator instead of on the exchange.
+	Retry *RetryConfig `json:"retry,omitempty" yaml:"retry,omitempty"` // Retry requests failing with a transient error.
+}
+
+// RetryConfig configures the RetryPolicy of the client.
+type RetryConfig struct {
+	MaxAttempts   int      `json:"maxAttempts" yaml:"maxAttempts"`                         // Attempts of a request, the first included.
+	Backoff       Duration `json:"backoff,omitempty" yaml:"backoff,omitempty"`             // Delay before the first retry; DefaultRetryBackoff if zero.
+	MaxBackoff    Duration `json:"maxBackoff,omitempty" yaml:"maxBackoff,omitempty"`       // Longest delay between attempts; DefaultRetryMaxBackoff if zero.
+	Statuses      []int    `json:"statuses,omitempty" yaml:"statuses,omitempty"`           // HTTP statuses retried; DefaultRetryStatuses if empty.
+	NetworkErrors bool     `json:"networkErrors,omitempty" yaml:"networkErrors,omitempty"` // Retry transport errors.
+	Orders        bool     `json:"orders,omitempty" yaml:"orders,omitempty"`               // Retry order requests that were certainly not acted on.
+}
+
+// PaperConfig configures paper trading.
+type PaperConfig struct {
+	Capital  float64 `json:"capital" yaml:"capital"`                       // Starting cash of the virtual account, in rupees.
+	Slippage float64 `json:"slippage,omitempty" yaml:"slippage,omitempty"` // Adverse slippage of market fills, in basis points.
+}
+
+// WebSocketConfig configures the market data websocket.
+type WebSocketConfig struct {
+	Compression bool   `json:"compression,omitempty" yaml:"compression,omitempty"` // Request permessage-deflate compression.
+	MaxRetries  int    `json:"maxRetries,omitempty" yaml:"maxRetries,omitempty"`   // Connection attempts; zero for the default.
+	URL         string `json:"url,omitempty" yaml:"url,omitempty"`                 // WebSocket URL; the production URL if empty.
+	TokenFile   string `json:"tokenFile,omitempty" yaml:"tokenFile,omitempty"`     // File re-read for the token on every dial; the client token if empty.
+
+	PingInterval Duration `json:"pingInterval,omitempty" yaml:"pingInterval,omitempty"` // Delay between pings; ticks.DefaultPingInterval if zero.
+	PongTimeout  Duration `json:"pongTimeout,omitempty" yaml:"pongTimeout,omitempty"`   // Wait for traffic after a ping; ticks.DefaultPongTimeout if zero.
+}
+
+// SessionConfig configures shutdown behavior.
+type SessionConfig struct {
+	CancelPolicy string `json:"cancelPolicy,omitempty" yaml:"cancelPolicy,omitempty"` // "none", "tracked" (default) or "all".
+	RequireArm   bool   `json:"requireArm,omitempty" yaml:"requireArm,omitempty"`     // Refuse orders until Session.Arm is called.
+	ArmTokenEnv  string `json:"armTokenEnv,omitempty" yaml:"armTokenEnv,omitempty"`   // Environment variable holding the confirmation Arm must be given.
+	ArmEnv       string `json:"armEnv,omitempty" yaml:"armEnv,omitempty"`             // Environment variable that must be true for Arm to succeed.
+}
+
+// RiskConfig configures the guards attached to the client.
+type RiskConfig struct {
+	StaleMaxAge     Duration          `json:"staleMaxAge,omitempty" yaml:"staleMaxAge,omitempty"`         // Attach a StaleGuard flagging prices older than this.
+	BlockStale      bool              `json:"blockStale,omitempty" yaml:"blockStale,omitempty"`           // Refuse orders on stale prices.
+	DuplicateWindow Duration          `json:"duplicateWindow,omitempty" yaml:"duplicateWindow,omitempty"` // Attach a DuplicateGuard with this window.
+	HealthFailures  int               `json:"healthFailures,omitempty" yaml:"healthFailures,omitempty"`   // Attach a HealthMonitor degrading after this many failures.
+	HealthWindow    Duration          `json:"healthWindow,omitempty" yaml:"healthWindow,omitempty"`       // Window of the HealthMonitor.
+	BlockDegraded   bool              `json:"blockDegraded,omitempty" yaml:"blockDegraded,omitempty"`     // Refuse orders while the broker is degraded.
+	DataRate        float64           `json:"dataRate,omitempty" yaml:"dataRate,omitempty"`               // Data requests per second, replacing rateLimits.quotes and .historical; zero for no limit.
+	DataBurst       int               `json:"dataBurst,omitempty" yaml:"dataBurst,omitempty"`             // Burst of the data limiter.
+	OrderRate       float64           `json:"orderRate,omitempty" yaml:"orderRate,omitempty"`             // Order requests per second, replacing rateLimits.orders; zero for no limit.
+	OrderBurst      int               `json:"orderBurst,omitempty" yaml:"orderBurst,omitempty"`           // Burst of the order limiter.
+	RateLimits      *RateLimits       `json:"rateLimits,omitempty" yaml:"rateLimits,omitempty"`           // Budget of each rate class; no limits if unset.
+	Allow           []string          `json:"allow,omitempty" yaml:"allow,omitempty"`                     // Symbols that may be traded; empty allows all.
+	Deny            map[string]string `json:"deny,omitempty" yaml:"deny,omitempty"`                       // Symbols that may not be traded, with the reason.
+	Limits          *RiskLimits       `json:"limits,omitempty" yaml:"limits,omitempty"`                   // Attach a RiskManager enforcing these limits.
+	SquareOff       bool              `json:"squareOff,omitempty" yaml:"squareOff,omitempty"`             // Exit all positions when the RiskManager trips.
+}
+
+// NotifierTarget describes where alerts are delivered. The SDK validates and exposes the
+// targets; wiring them to a delivery mechanism is left to the application.
+type NotifierTarget struct {
+	Name   string            `json:"name" yaml:"name"`                         // Name strategies refer to the target by.
+	Type   string            `json:"type" yaml:"type"`                         // "webhook", "telegram", "slack" or "email".
+	URL    string            `json:"url,omitempty" yaml:"url,omitempty"`       // Endpoint for webhook and slack targets.
+	Params map[string]string `json:"params,omitempty" yaml:"params,omitempty"` // Type-specific settings (e.g., chat ID, recipient).
+}
+
+// StrategyConfig holds the parameters of a strategy.
+type StrategyConfig struct {
+	Tag        string         `json:"tag" yaml:"tag"`                                   // Strategy tag orders are placed with.
+	Enabled    bool           `json:"enabled" yaml:"enabled"`                           // Whether the strategy should run.
+	Watchlists []string       `json:"watchlists,omitempty" yaml:"watchlists,omitempty"` // Watchlists the strategy trades.
+	Notify     []string       `json:"notify,omitempty" yaml:"notify,omitempty"`         // Notifier targets of the strategy.
+	Params     map[string]any `json:"params,omitempty" yaml:"params,omitempty"`         // Strategy-specific parameters.
+}
+
+// DecodeParams decodes the strategy parameters into v, typically a pointer to a struct
+// with json tags, so each strategy can define its own typed parameters.
+func (s StrategyConfig) DecodeParams(v any) error {
+	data, err := json.Marshal(s.Params)
+	if err != nil {
+		return fmt.Errorf("strategy %s: error encoding params: %w", s.Tag, err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("strategy %s: invalid params: %w", s.Tag, err)
+	}
+	return nil
+}
+
+// LoadDeploymentConfig reads and validates a deployment configuration.
+//
+// The format is chosen from the file extension: .yaml or .yml for YAML, .json for JSON.
+// Unknown fields are rejected so that typos do not silently fall back to defaults.
+//
+// Parameters:
+//   - path: The configuration file.
+//
+// Returns:
+//   - A pointer to the loaded DeploymentConfig if successful.
+//   - An error if the file cannot be read or parsed, or fails validation.
+func LoadDeploymentConfig(path string) (*DeploymentConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading config: %w", err)
+	}
+
+	var cfg DeploymentConfig
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&cfg); err != nil {
+			return nil, fmt.Errorf("error parsing config %s: %w", path, err)
+		}
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&cfg); err != nil {
+			return nil, fmt.Errorf("error parsing config %s: %w", path, err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported config format %q", ext)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// Validate checks the configuration and returns every problem found, joined into one error.
+func (c *DeploymentConfig) Validate() error {
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if c.Client.AppIDEnv == "" {
+		fail("client.appIdEnv is required")
+	}
+	if c.Client.AppSecretEnv == "" {
+		fail("client.appSecretEnv is required")
+	}
+	if c.Client.MaxResponseSize < 0 {
+		fail("client.maxResponseSize must not be negative")
+	}
+	if v := c.Client.APIVersion; v != "" && !slices.Contains(APIVersions(), APIVersion(v)) {
+		fail("client.apiVersion: unknown version %q", v)
+	}
+	if p := c.Client.Paper; p != nil && (p.Capital <= 0 || p.Slippage < 0) {
+		fail("client.paper: capital must be positive and slippage not negative")
+	}
+	if v := c.Client.LogLevel; v != "" {
+		if _, err := zerolog.ParseLevel(v); err != nil {
+			fail("client.logLevel: unknown level %q", v)
+		}
+	}
+	if r := c.Client.Retry; r != nil && (r.MaxAttempts < 1 || r.Backoff < 0 || r.MaxBackoff < 0) {
+		fail("client.retry: maxAttempts must be positive and backoffs not negative")
+	}
+	if c.WebSocket != nil && c.WebSocket.MaxRetries < 0 {
+		fail("websocket.maxRetries must not be negative")
+	}
+	if c.WebSocket != nil && (c.WebSocket.PingInterval < 0 || c.WebSocket.PongTimeout < 0) {
+		fail("websocket heartbeat durations must not be negative")
+	}
+	if _, err := parseCancelPolicy(c.Session.CancelPolicy); err != nil {
+		fail("session.cancelPolicy: %v", err)
+	}
+
+	r := c.Risk
+	if r.StaleMaxAge < 0 || r.DuplicateWindow < 0 || r.HealthWindow < 0 {
+		fail("risk durations must not be negative")
+	}
+	if r.BlockStale && r.StaleMaxAge == 0 {
+		fail("risk.blockStale requires risk.staleMaxAge")
+	}
+	if r.HealthFailures < 0 {
+		fail("risk.healthFailures must not be negative")
+	}
+	if r.HealthFailures > 0 && r.HealthWindow == 0 {
+		fail("risk.healthFailures requires risk.healthWindow")
+	}
+	if r.BlockDegraded && r.HealthFailures == 0 {
+		fail("risk.blockDegraded requires risk.healthFailures")
+	}
+	if r.DataRate < 0 || r.OrderRate < 0 || r.DataBurst < 0 || r.OrderBurst < 0 {
+		fail("risk rate limits must not be negative")
+	}
+	if l := r.Limits; l != nil && (l.MaxLoss < 0 || l.MaxOrdersPerMinute < 0 || l.MaxPosition < 0) {
+		fail("risk.limits must not be negative")
+	}
+	if r.SquareOff && r.Limits == nil {
+		fail("risk.squareOff requires risk.limits")
+	}
+	if l := r.RateLimits; l != nil {
+		for _, limit := range []RateLimit{l.Orders, l.Quotes, l.Historical, l.General} {
+			if limit.PerSecond < 0 || limit.PerMinute < 0 {
+				fail("risk.rateLimits must not be negative")
+				break
+			}
+		}
+	}
+
+	watchlists := make(map[string]bool)
+	for i, w := range c.Watchlists {
+		switch {
+		case w.Name == "":
+			fail("watchlists[%d].name is required", i)
+		case watchlists[w.Name]:
+			fail("watchlist %q is defined twice", w.Name)
+		}
+		watchlists[w.Name] = true
+		for j, item := range w.Items {
+			if item.Token <= 0 {
+				fail("watchlist %q item %d: token is required", w.Name, j)
+			}
+			if _, err := ParseExchange(item.Exchange); err != nil {
+				fail("watchlist %q item %d: %v", w.Name, j, err)
+			}
+		}
+	}
+
+	notifiers := make(map[string]bool)
+	for i, n := range c.Notifiers {
+		if n.Name == "" {
+			fail("notifiers[%d].name is required", i)
+		} else if notifiers[n.Name] {
+			fail("notifier %q is defined twice", n.Name)
+		}
+		notifiers[n.Name] = true
+		switch n.Type {
+		case "webhook", "slack":
+			if !strings.HasPrefix(n.URL, "https://") && !strings.HasPrefix(n.URL, "http://") {
+				fail("notifier %q: url must be an http(s) URL", n.Name)
+			}
+		case "telegram", "email":
+		default:
+			fail("notifier %q: unknown type %q", n.Name, n.Type)
+		}
+	}
+
+	tags := make(map[string]bool)
+	for i, s := range c.Strategies {
+		if s.Tag == "" {
+			fail("strategies[%d].tag is required", i)
+		} else if tags[s.Tag] {
+			fail("strategy %q is defined twice", s.Tag)
+		}
+		tags[s.Tag] = true
+		for _, name := range s.Watchlists {
+			if !watchlists[name] {
+				fail("strategy %q: unknown watchlist %q", s.Tag, name)
+			}
+		}
+		for _, name := range s.Notify {
+			if !notifiers[name] {
+				fail("strategy %q: unknown notifier %q", s.Tag, name)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Deployment is the set of components built from a DeploymentConfig.
+type Deployment struct {
+	Config     *DeploymentConfig         // The configuration the deployment was built from.
+	Client     *Client                   // REST client with the configured guards attached.
+	WS         *ticks.WS                 // Market data websocket, if configured.
+	Session    *Session                  // Session around the client and websocket.
+	Health     *HealthMonitor            // Health monitor, if configured.
+	Stale      *StaleGuard               // Stale price guard, if configured.
+	Positions  *PositionEngine           // Position engine feeding the risk manager, if configured; run it with the order updates.
+	Risk       *RiskManager              // Kill switch, if configured; feed it ticks with Update.
+	Simulator  *Simulator                // Paper trading simulator, if configured.
+	Watchlists map[string]*Watchlist     // Watchlists by name.
+	Notifiers  map[string]NotifierTarget // Notifier targets by name.
+}
+
+// Strategy returns the configuration of the strategy with the given tag.
+func (d *Deployment) Strategy(tag string) (StrategyConfig, bool) {
+	for _, s := range d.Config.Strategies {
+		if s.Tag == tag {
+			return s, true
+		}
+	}
+	return StrategyConfig{}, false
+}
+
+// Build creates the client, websocket, session and guards described by the configuration.
+// Credentials are read from the environment variables named in the client section.
+//
+// Returns:
+//   - A pointer to the built Deployment.
+//   - An error if the configuration is invalid or a credential variable is not set.
+func (c *DeploymentConfig) Build() (*Deployment, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	appID, secret := os.Getenv(c.Client.AppIDEnv), os.Getenv(c.Client.AppSecretEnv)
+	if appID == "" || secret == "" {
+		return nil, fmt.Errorf("credentials not set: %s and %s are required", c.Client.AppIDEnv, c.Client.AppSecretEnv)
+	}
+
+	client := NewClient(appID, secret)
+	if c.Client.BaseURL != "" {
+		client.Config.BaseURL = c.Client.BaseURL
+	}
+	client.Config.MaxResponseSize = c.Client.MaxResponseSize
+	client.Config.APIVersion = APIVersion(c.Client.APIVersion)
+	if c.Client.TokenEnv != "" {
+		client.SetToken(os.Getenv(c.Client.TokenEnv))
+	}
+	if c.Client.SessionFile != "" {
+		client.SetTokenStore(NewFileTokenStore(c.Client.SessionFile))
+	}
+	if c.Client.InstrumentCache != "" {
+		client.SetInstrumentCache(NewInstrumentCache(c.Client.InstrumentCache))
+	}
+
+	logLevel, _ := zerolog.ParseLevel(c.Client.LogLevel)
+	if c.Client.LogLevel != "" {
+		client.SetLogLevel(logLevel)
+	}
+	if r := c.Client.Retry; r != nil {
+		client.SetRetryPolicy(&RetryPolicy{
+			MaxAttempts:   r.MaxAttempts,
+			Backoff:       time.Duration(r.Backoff),
+			MaxBackoff:    time.Duration(r.MaxBackoff),
+			Statuses:      r.Statuses,
+			NetworkErrors: r.NetworkErrors,
+			Orders:        r.Orders,
+		})
+	}
+
+	d := &Deployment{
+		Config:     c,
+		Client:     client,
+		Watchlists: make(map[string]*Watchlist, len(c.Watchlists)),
+		Notifiers:  make(map[string]NotifierTarget, len(c.Notifiers)),
+	}
+
+	if p := c.Client.Paper; p != nil {
+		d.Simulator = NewSimulator(p.Capital)
+		d.Simulator.Slippage = p.Slippage
+		client.SetSimulator(d.Simulator)
+	}
+
+	r := c.Risk
+	if r.StaleMaxAge > 0 {
+		d.Stale = NewStaleGuard(time.Duration(r.StaleMaxAge))
+		d.Stale.BlockOrders = r.BlockStale
+		client.SetStaleGuard(d.Stale)
+	}
+	if r.DuplicateWindow > 0 {
+		client.SetDuplicateGuard(NewDuplicateGuard(time.Duration(r.DuplicateWindow)))
+	}
+	if r.HealthFailures > 0 {
+		d.Health = NewHealthMonitor(r.HealthFailures, time.Duration(r.HealthWindow))
+		d.Health.BlockOrders = r.BlockDegraded
+		client.SetHealthMonitor(d.Health)
+	}
+	if r.RateLimits != nil {
+		client.SetRateLimits(*r.RateLimits)
+	}
+	if r.DataRate > 0 {
+		client.SetDataRateLimiter(NewRateLimiter(r.DataRate, r.DataBurst))
+	}
+	if r.OrderRate > 0 {
+		client.SetOrderRateLimiter(NewRateLimiter(r.OrderRate, r.OrderBurst))
+	}
+	if len(r.Allow) > 0 || len(r.Deny) > 0 {
+		control := NewSymbolControl()
+		control.SetAllowList(r.Allow...)
+		for symbol, reason := range r.Deny {
+			control.Deny(symbol, reason)
+		}
+		client.SetSymbolControl(control)
+	}
+	if r.Limits != nil {
+		d.Positions = NewPositionEngine(client)
+		d.Risk = NewRiskManager(client, d.Positions, *r.Limits)
+		d.Risk.SquareOff = r.SquareOff
+		client.SetRiskManager(d.Risk)
+	}
+
+	if ws := c.WebSocket; ws != nil {
+		var credentials ticks.CredentialsProvider = client
+		if ws.TokenFile != "" {
+			credentials = ticks.FileCredentials{AppID: appID, TokenPath: ws.TokenFile}
+		}
+		d.WS = ticks.NewWSWithCredentials(credentials)
+		d.WS.EnableCompression = ws.Compression
+		if ws.MaxRetries > 0 {
+			d.WS.MaxRetries = ws.MaxRetries
+		}
+		if ws.URL != "" {
+			d.WS.URL = ws.URL
+		}
+		if ws.PingInterval > 0 {
+			d.WS.PingInterval = time.Duration(ws.PingInterval)
+		}
+		if ws.PongTimeout > 0 {
+			d.WS.PongTimeout = time.Duration(ws.PongTimeout)
+		}
+		if c.Client.LogLevel != "" {
+			d.WS.SetLogLevel(logLevel)
+		}
+		if d.Health != nil {
+			d.Health.WatchWS(d.WS)
+		}
+	}
+
+	d.Session = NewSession(client, d.WS)
+	d.Session.CancelPolicy, _ = parseCancelPolicy(c.Session.CancelPolicy)
+	if c.Session.RequireArm {
+		token := ""
+		if c.Session.ArmTokenEnv != "" {
+			if token = os.Getenv(c.Session.ArmTokenEnv); token == "" {
+				return nil, fmt.Errorf("arm confirmation not set: %s is required", c.Session.ArmTokenEnv)
+			}
+		}
+		d.Session.RequireArm(token, c.Session.ArmEnv)
+	}
+
+	for i := range c.Watchlists {
+		w := c.Watchlists[i]
+		d.Watchlists[w.Name] = &w
+	}
+	for _, n := range c.Notifiers {
+		d.Notifiers[n.Name] = n
+	}
+	return d, nil
+}
+
+// parseCancelPolicy parses the name of a shutdown cancel policy; empty means CancelTracked.
+func parseCancelPolicy(s string) (ShutdownCancelPolicy, error) {
+	switch strings.ToLower(s) {
+	case "", "tracked":
+		return CancelTracked, nil
+	case "none":
+		return CancelNone, nil
+	case "all":
+		return CancelAllOpen, nil
+	}
+	return CancelTracked, fmt.Errorf("unknown cancel policy %q", s)
+}
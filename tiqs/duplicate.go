@@ -0,0 +1,98 @@
+package tiqs
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DuplicateGuard rejects orders identical to one placed shortly before.
+//
+// Two orders are identical when they share token, side, quantity, price and product.
+// When the guard is attached to a Client via SetDuplicateGuard, PlaceOrder rejects an
+// identical order placed within Window of the previous one unless the order sets
+// AllowDuplicate. This protects against strategies that fire the same signal twice.
+type DuplicateGuard struct {
+	Window time.Duration    // Period during which an identical order is rejected.
+	Now    func() time.Time // Time source, defaults to time.Now.
+
+	mu     sync.Mutex
+	recent map[string]time.Time
+}
+
+// NewDuplicateGuard creates a guard that rejects identical orders placed within window.
+//
+// Parameters:
+//   - window: The period during which an identical order is rejected.
+//
+// Returns:
+//   - A pointer to a newly created DuplicateGuard.
+func NewDuplicateGuard(window time.Duration) *DuplicateGuard {
+	return &DuplicateGuard{
+		Window: window,
+		Now:    time.Now,
+		recent: make(map[string]time.Time),
+	}
+}
+
+// Reset forgets all previously placed orders.
+func (g *DuplicateGuard) Reset() {
+	g.mu.Lock()
+	g.recent = make(map[string]time.Time)
+	g.mu.Unlock()
+}
+
+// reserve records the order as placed and returns an error if an identical order was
+// placed within the window. The returned release function forgets the order again and
+// must be called if the order is not placed after all.
+func (g *DuplicateGuard) reserve(order OrderRequest) (func(), error) {
+	key := duplicateKey(order)
+	now := g.now()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for k, placed := range g.recent {
+		if now.Sub(placed) >= g.Window {
+			delete(g.recent, k)
+		}
+	}
+
+	if placed, ok := g.recent[key]; ok && !order.AllowDuplicate {
+		defaultLog().Warn().Str("token", order.Token).Str("transactionType", string(order.TransactionType)).Msg("Duplicate order blocked")
+		return nil, fmt.Errorf("order blocked: identical order placed %s ago", now.Sub(placed).Truncate(time.Millisecond))
+	}
+
+	previous, hadPrevious := g.recent[key]
+	g.recent[key] = now
+	release := func() {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		if hadPrevious {
+			g.recent[key] = previous
+		} else {
+			delete(g.recent, key)
+		}
+	}
+	return release, nil
+}
+
+// now returns the current time from the configured time source.
+func (g *DuplicateGuard) now() time.Time {
+	if g.Now != nil {
+		return g.Now()
+	}
+	return time.Now()
+}
+
+// duplicateKey identifies the fields that make two orders identical.
+func duplicateKey(order OrderRequest) string {
+	return strings.Join([]string{
+		order.Token,
+		string(order.TransactionType),
+		order.Quantity,
+		order.Price,
+		string(order.Product),
+	}, ":")
+}
@@ -0,0 +1,74 @@
+package tiqs_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Abhi13027/go-tiqs/tiqs"
+)
+
+func TestDuplicateGuard(t *testing.T) {
+	const window = 5 * time.Second
+	buy := order(tiqs.TransactionBuy, "75", tiqs.ProductMIS)
+	repriced := buy
+	repriced.Price = "103"
+	duplicate := buy
+	duplicate.AllowDuplicate = true
+
+	// step places an order after advancing the clock, failing the request at the server
+	// if fail is set
+	type step struct {
+		advance time.Duration
+		order   tiqs.OrderRequest
+		fail    bool
+		wantErr bool
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{"same order within the window", []step{{0, buy, false, false}, {time.Second, buy, false, true}}},
+		{"same order at the end of the window", []step{{0, buy, false, false}, {window, buy, false, false}}},
+		{"window restarts after each order", []step{{0, buy, false, false}, {window, buy, false, false}, {time.Second, buy, false, true}}},
+		{"different price", []step{{0, buy, false, false}, {time.Second, repriced, false, false}}},
+		{"AllowDuplicate", []step{{0, buy, false, false}, {time.Second, duplicate, false, false}}},
+		{"failed send releases the order", []step{{0, buy, true, true}, {time.Second, buy, false, false}}},
+		{"failed duplicate keeps the original", []step{{0, buy, false, false}, {time.Second, duplicate, true, true}, {time.Second, buy, false, true}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := newTestClient(t)
+			now := time.Date(2024, 12, 19, 9, 15, 0, 0, time.UTC)
+			guard := tiqs.NewDuplicateGuard(window)
+			guard.Now = func() time.Time { return now }
+			client.SetDuplicateGuard(guard)
+
+			for i, s := range tt.steps {
+				now = now.Add(s.advance)
+				if s.fail {
+					server.Fail(tiqs.EndpointPlaceOrder, http.StatusBadRequest, "rejected")
+				}
+				before := len(server.Requests())
+				_, err := client.PlaceOrder("regular", s.order)
+				sent := len(server.Requests()) - before
+				if s.fail {
+					server.Reset()
+				}
+
+				if (err != nil) != s.wantErr {
+					t.Fatalf("order %d: PlaceOrder = %v, want error %t", i+1, err, s.wantErr)
+				}
+				// An order the guard blocks is not sent
+				wantSent := 1
+				if s.wantErr && !s.fail {
+					wantSent = 0
+				}
+				if sent != wantSent {
+					t.Fatalf("order %d: %d requests sent, want %d", i+1, sent, wantSent)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package tiqs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Error categories that an APIError matches with errors.Is, so callers can decide whether
+// to retry, re-authenticate or give up without inspecting broker-specific codes.
+var (
+	ErrInsufficientMargin = errors.New("insufficient margin")       // Not enough funds or margin for the order.
+	ErrInvalidInstrument  = errors.New("invalid instrument")        // Unknown token, symbol or exchange.
+	ErrRateLimited        = errors.New("rate limited")              // Too many requests; retry after a delay.
+	ErrUnauthorized       = errors.New("unauthorized")              // Missing, invalid or expired session; re-authenticate.
+	ErrOrderRejected      = errors.New("order rejected")            // The order was refused for another reason.
+	ErrServerUnavailable  = errors.New("broker server unavailable") // The broker failed with a 5xx status; retry later.
+)
+
+// APIError is returned when the Tiqs API reports a failure.
+//
+// Use errors.Is with the category errors (ErrInsufficientMargin, ErrRateLimited, ...) to
+// classify it, or errors.As to inspect the broker's code and message.
+type APIError struct {
+	Op         string // Operation that failed (e.g., "order placement").
+	Endpoint   string // API endpoint the request was sent to.
+	HTTPStatus int    // HTTP status code of the response; zero if unknown.
+	ErrorCode  string // Error code reported by the API, if any.
+	Message    string // Error message reported by the API, if any.
+}
+
+// Error implements the error interface.
+func (e *APIError) Error() string {
+	var b strings.Builder
+	b.WriteString(e.Op)
+	b.WriteString(" failed")
+	if e.Message != "" {
+		b.WriteString(": ")
+		b.WriteString(e.Message)
+	}
+	if e.ErrorCode != "" {
+		fmt.Fprintf(&b, " (code %s)", e.ErrorCode)
+	}
+	if e.HTTPStatus != 0 {
+		fmt.Fprintf(&b, " [HTTP %d]", e.HTTPStatus)
+	}
+	return b.String()
+}
+
+// Is reports whether the error belongs to the given category.
+func (e *APIError) Is(target error) bool {
+	category := e.Category()
+	return category != nil && target == category
+}
+
+// Category returns the category error the failure belongs to, or nil if it cannot be classified.
+func (e *APIError) Category() error {
+	switch {
+	case e.HTTPStatus == 429:
+		return ErrRateLimited
+	case e.HTTPStatus == 401 || e.HTTPStatus == 403:
+		return ErrUnauthorized
+	case e.HTTPStatus >= 500:
+		return ErrServerUnavailable
+	}
+
+	text := strings.ToLower(e.ErrorCode + " " + e.Message)
+	switch {
+	case containsAny(text, "rate limit", "too many requests", "throttl"):
+		return ErrRateLimited
+	case containsAny(text, "session", "unauthori", "invalid token", "token expired", "not logged in", "login required"):
+		return ErrUnauthorized
+	case containsAny(text, "margin", "insufficient", "funds"):
+		return ErrInsufficientMargin
+	case containsAny(text, "invalid instrument", "invalid symbol", "symbol not found", "instrument not found", "invalid exchange", "token not found", "invalid scrip"):
+		return ErrInvalidInstrument
+	case strings.HasPrefix(e.Endpoint, "/order") && e.Message != "":
+		return ErrOrderRejected
+	}
+	return nil
+}
+
+// Temporary reports whether the request may succeed if retried later.
+func (e *APIError) Temporary() bool {
+	category := e.Category()
+	return category == ErrRateLimited || category == ErrServerUnavailable
+}
+
+// apiEnvelope holds the fields common to every API response.
+type apiEnvelope struct {
+	Status    string `json:"status"`
+	Message   string `json:"message"`
+	ErrorCode string `json:"errorCode"`
+	Code      any    `json:"code"`
+}
+
+// newAPIError builds an APIError from a failed response body.
+//
+// Parameters:
+//   - op: The operation that failed (e.g., "order placement").
+//   - endpoint: The API endpoint of the request.
+//   - httpStatus: The HTTP status code, or zero if unknown.
+//   - body: The response body, or nil if unavailable.
+func newAPIError(op, endpoint string, httpStatus int, body []byte) *APIError {
+	apiErr := &APIError{Op: op, Endpoint: endpoint, HTTPStatus: httpStatus}
+
+	var envelope apiEnvelope
+	if json.Unmarshal(body, &envelope) == nil {
+		apiErr.Message = envelope.Message
+		apiErr.ErrorCode = envelope.ErrorCode
+		if apiErr.ErrorCode == "" && envelope.Code != nil {
+			apiErr.ErrorCode = fmt.Sprint(envelope.Code)
+		}
+	} else if len(body) > 0 {
+		apiErr.Message = strings.TrimSpace(string(body[:min(len(body), 200)]))
+	}
+	return apiErr
+}
+
+// containsAny reports whether s contains any of the substrings.
+func containsAny(s string, substrings ...string) bool {
+	for _, sub := range substrings {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
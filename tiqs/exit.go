@@ -0,0 +1,251 @@
+package tiqs
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// PositionFilter selects the positions closed by ExitAllPositions. Empty fields match
+// every position.
+type PositionFilter struct {
+	Products  []Product  // Products to close (e.g., ProductMIS for an intraday square-off).
+	Exchanges []Exchange // Exchanges to close.
+	Symbols   []string   // Trading symbols to close, compared case-insensitively.
+}
+
+// Match reports whether a position is selected by the filter.
+func (f PositionFilter) Match(p Position) bool {
+	if len(f.Products) > 0 && !slices.Contains(f.Products, Product(p.Product)) {
+		return false
+	}
+	if len(f.Exchanges) > 0 && !slices.Contains(f.Exchanges, Exchange(strings.ToUpper(p.Exchange))) {
+		return false
+	}
+	if len(f.Symbols) > 0 && !slices.ContainsFunc(f.Symbols, func(s string) bool { return strings.EqualFold(s, p.Symbol) }) {
+		return false
+	}
+	return true
+}
+
+// ExitResult is the outcome of closing one position.
+//
+// Exits above the instrument's freeze limit are placed as several child orders (see
+// PlaceSlicedOrder). When a child fails, those placed before it stay working, and the
+// exit is partial: the position is only reduced by PlacedQuantity.
+type ExitResult struct {
+	Position Position       // The position closed.
+	Order    OrderRequest   // The order closing it, before slicing.
+	Orders   []OrderRequest // The child orders the exit was sliced into; Order itself if it was not sliced.
+	OrderNos []string       // Order numbers of the children placed, in the order of Orders.
+	OrderNo  string         // Order number of the first order placed, empty if none was placed.
+	Err      error          // Error building or placing the orders, or nil.
+}
+
+// Partial reports whether some but not all of the exit's orders were placed.
+func (r ExitResult) Partial() bool {
+	return len(r.OrderNos) > 0 && len(r.OrderNos) < len(r.Orders)
+}
+
+// PlacedQuantity returns the quantity of the orders placed, in the convention of the
+// order's segment (lots for currency and commodity).
+func (r ExitResult) PlacedQuantity() int64 {
+	var quantity int64
+	for _, order := range r.Orders[:len(r.OrderNos)] {
+		quantity += parseInt(order.Quantity)
+	}
+	return quantity
+}
+
+// ExitOrder builds the order that closes a position: the opposite side of its net
+// quantity, in the same product.
+//
+// Quantities are converted to the convention of the position's segment, so currency and
+// commodity positions are closed in lots. When the instrument is known, F&O quantities
+// are checked against the lot size. Limit orders are priced at the position's last
+// traded price, rounded to the tick size.
+//
+// Parameters:
+//   - position: The position to close.
+//   - orderType: The order type of the exit (OrderTypeMarket or OrderTypeLimit).
+//
+// Returns:
+//   - The order closing the position.
+//   - An error if the position is flat, the order type is not supported or the quantity
+//     does not match the lot size.
+func (c *Client) ExitOrder(position Position, orderType OrderType) (OrderRequest, error) {
+	qty := parseInt(position.Qty)
+	if qty == 0 {
+		return OrderRequest{}, fmt.Errorf("position in %s is flat", position.Symbol)
+	}
+
+	side := TransactionSell
+	if qty < 0 {
+		side = TransactionBuy
+	}
+	units := abs64(qty)
+
+	token := parseInt(position.Token)
+	prices := c.PriceConverter()
+	inst := prices.Instrument(token)
+	if inst.LotSize <= 0 {
+		inst.LotSize = parseInt(position.LotSize)
+	}
+	inst.Exchange = cmp.Or(inst.Exchange, position.Exchange)
+
+	quantity := units
+	if Exchange(strings.ToUpper(inst.Exchange)).Segment().Rules().QuantityInLots {
+		if inst.LotSize > 0 {
+			if units%inst.LotSize != 0 {
+				return OrderRequest{}, fmt.Errorf("position of %d in %s is not a multiple of the lot size %d", units, position.Symbol, inst.LotSize)
+			}
+			quantity = units / inst.LotSize
+		}
+	} else if inst.LotSize > 1 && units%inst.LotSize != 0 {
+		return OrderRequest{}, fmt.Errorf("position of %d in %s is not a multiple of the lot size %d", units, position.Symbol, inst.LotSize)
+	}
+
+	order := OrderRequest{
+		Exchange:        Exchange(strings.ToUpper(position.Exchange)),
+		Token:           position.Token,
+		Symbol:          position.Symbol,
+		Product:         Product(position.Product),
+		Quantity:        strconv.FormatInt(quantity, 10),
+		TransactionType: side,
+		OrderType:       orderType,
+		Price:           "0",
+		Validity:        ValidityDay,
+	}
+
+	switch orderType {
+	case OrderTypeMarket:
+	case OrderTypeLimit:
+		ltp := parseFloat(position.Ltp)
+		if ltp <= 0 {
+			return OrderRequest{}, fmt.Errorf("position in %s has no last traded price to price a limit exit", position.Symbol)
+		}
+		order.Price = prices.FormatPrice(token, ltp)
+	default:
+		return OrderRequest{}, fmt.Errorf("unsupported exit order type %q", orderType)
+	}
+	return order, nil
+}
+
+// ExitPosition closes a position by placing the opposing order, sliced into child orders
+// when it exceeds the instrument's freeze limit (see PlaceSlicedOrder).
+//
+// Parameters:
+//   - position: The position to close, as returned by GetPositions.
+//   - orderType: The order type of the exit (OrderTypeMarket or OrderTypeLimit).
+//
+// Returns:
+//   - The result of the exit, with the orders placed; see ExitResult.Partial when an
+//     error is returned.
+//   - An error if the order cannot be built or sliced, or one of its children cannot be
+//     placed.
+func (c *Client) ExitPosition(position Position, orderType OrderType) (ExitResult, error) {
+	result := ExitResult{Position: position}
+	order, err := c.ExitOrder(position, orderType)
+	if err != nil {
+		result.Err = err
+		return result, err
+	}
+	result.Order = order
+
+	sliced, err := c.PlaceSlicedOrder("regular", order)
+	if sliced != nil {
+		result.Orders = sliced.Orders
+		result.OrderNos = sliced.OrderNos
+		if len(sliced.OrderNos) > 0 {
+			result.OrderNo = sliced.OrderNos[0]
+		}
+	}
+	if err != nil {
+		result.Err = err
+		if result.Partial() {
+			c.log().Error().Err(err).
+				Str("symbol", position.Symbol).
+				Str("quantity", order.Quantity).
+				Int64("placed", result.PlacedQuantity()).
+				Msg("Position partially exited")
+		} else {
+			c.log().Error().Err(err).Str("symbol", position.Symbol).Msg("Failed to exit position")
+		}
+		return result, err
+	}
+
+	c.log().Info().Str("symbol", position.Symbol).Str("quantity", order.Quantity).Str("orderNo", result.OrderNo).Int("orders", len(result.Orders)).Msg("Position exit placed")
+	return result, nil
+}
+
+// ExitAllPositions closes every open position selected by the filter with market orders.
+//
+// Short positions are closed before long ones, so that options bought as hedges keep
+// covering the shorts until those are gone and the margin of the remaining orders is not
+// inflated. Exits above the freeze limit are sliced. A failure to close one position does
+// not stop the others; check ExitResult.Partial for positions only partly closed.
+//
+// Parameters:
+//   - filter: The positions to close; a zero filter closes every position.
+//
+// Returns:
+//   - One result per open position selected, shorts first.
+//   - An error if the positions cannot be retrieved.
+func (c *Client) ExitAllPositions(filter PositionFilter) ([]ExitResult, error) {
+	positions, err := c.GetPositions()
+	if err != nil {
+		return nil, err
+	}
+
+	var open []Position
+	for _, p := range positions {
+		if parseInt(p.Qty) != 0 && filter.Match(p) {
+			open = append(open, p)
+		}
+	}
+	slices.SortStableFunc(open, func(a, b Position) int {
+		return compareBool(parseInt(a.Qty) > 0, parseInt(b.Qty) > 0)
+	})
+
+	results := make([]ExitResult, 0, len(open))
+	failed, partial := 0, 0
+	for _, p := range open {
+		result, err := c.ExitPosition(p, OrderTypeMarket)
+		if err != nil {
+			failed++
+		}
+		if result.Partial() {
+			partial++
+		}
+		results = append(results, result)
+	}
+
+	c.log().Info().Int("positions", len(results)).Int("failed", failed).Int("partial", partial).Msg("Positions exited")
+	return results, nil
+}
+
+// SquareOff closes every open position of a product with market orders, e.g., ProductMIS
+// before the broker's intraday auto square-off.
+//
+// Parameters:
+//   - product: The product whose positions are closed.
+//
+// Returns:
+//   - One result per open position of the product, shorts first.
+//   - An error if the positions cannot be retrieved.
+func (c *Client) SquareOff(product Product) ([]ExitResult, error) {
+	return c.ExitAllPositions(PositionFilter{Products: []Product{product}})
+}
+
+// compareBool orders false before true.
+func compareBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case !a:
+		return -1
+	}
+	return 1
+}
@@ -0,0 +1,190 @@
+package tiqs
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ExpiryKind classifies derivative contracts by the cycle they expire in.
+type ExpiryKind string
+
+const (
+	ExpiryWeekly  ExpiryKind = "WEEKLY"  // Contracts expiring before the last expiry of their month.
+	ExpiryMonthly ExpiryKind = "MONTHLY" // Contracts expiring with the futures of their month.
+)
+
+// ContractExpiry is an expiry day of the derivatives of an underlying.
+type ContractExpiry struct {
+	Date     time.Time  `json:"date"`     // Expiry day, at midnight IST.
+	Listed   time.Time  `json:"listed"`   // Expiry day in the instrument master; differs from Date when moved for a holiday.
+	Kind     ExpiryKind `json:"kind"`     // Weekly or monthly.
+	Exchange Exchange   `json:"exchange"` // Exchange of the contracts (e.g., ExchangeNFO).
+}
+
+// ExpiryList returns the expiries of the derivatives of an underlying, in ascending order,
+// classified as weekly or monthly.
+//
+// The monthly expiry of a month is the day its futures expire. Underlyings without
+// futures in the store take the last expiry of each month as monthly, which is exact
+// while the store lists every expiry of the month.
+//
+// Parameters:
+//   - underlying: The underlying symbol (e.g., "NIFTY"), ignoring case.
+//   - cal: The trading calendar, e.g., from GetTradingCalendar; expiries listed on a
+//     holiday or weekend are moved to the previous trading day, as the exchange does.
+//     Nil keeps the days of the instrument master.
+//
+// Returns:
+//   - The expiries, or nil if the underlying has no derivatives in the store.
+func (s *InstrumentStore) ExpiryList(underlying string, cal *TradingCalendar) []ContractExpiry {
+	s.mu.RLock()
+	expiries := s.expiriesLocked(strings.ToUpper(underlying))
+	s.mu.RUnlock()
+
+	if cal != nil {
+		for i := range expiries {
+			expiries[i].Date = previousTradingDay(cal, expiries[i].Exchange, expiries[i].Date)
+		}
+	}
+	return expiries
+}
+
+// NearestExpiry returns the first expiry of an underlying on or after the day of a date.
+//
+// A contract remains tradable for the whole of its expiry day, so an expiry on the day
+// of from is returned.
+//
+// Parameters:
+//   - underlying: The underlying symbol (e.g., "BANKNIFTY"), ignoring case.
+//   - from: The date to search from, usually time.Now().
+//   - kind: ExpiryWeekly or ExpiryMonthly; empty for the nearest expiry of either kind.
+//   - cal: The trading calendar, as for ExpiryList; may be nil.
+//
+// Returns:
+//   - The expiry and true if found; otherwise, a zero ContractExpiry and false.
+func (s *InstrumentStore) NearestExpiry(underlying string, from time.Time, kind ExpiryKind, cal *TradingCalendar) (ContractExpiry, bool) {
+	day := midnight(from)
+	for _, expiry := range s.ExpiryList(underlying, cal) {
+		if expiry.Date.Before(day) || (kind != "" && expiry.Kind != kind) {
+			continue
+		}
+		return expiry, true
+	}
+	return ContractExpiry{}, false
+}
+
+// ExpiryKindOf classifies the expiry of a derivative contract as weekly or monthly, as in
+// ExpiryList.
+//
+// Returns:
+//   - The kind and true for derivatives with a known expiry; otherwise, an empty kind and false.
+func (s *InstrumentStore) ExpiryKindOf(inst Instrument) (ExpiryKind, bool) {
+	expiry, ok := inst.Expiry()
+	if !ok || inst.Symbol == "" {
+		return "", false
+	}
+	day := midnight(expiry)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, e := range s.expiriesLocked(strings.ToUpper(inst.Symbol)) {
+		if e.Listed.Equal(day) {
+			return e.Kind, true
+		}
+	}
+	// The contract is not in the store; it expires monthly if a future expires with it.
+	if isFuture(inst) {
+		return ExpiryMonthly, true
+	}
+	return ExpiryWeekly, true
+}
+
+// NearestExpiry returns the nearest expiry of an underlying from today, fetching the
+// trading calendar to account for holidays.
+//
+// Parameters:
+//   - underlying: The underlying symbol (e.g., "NIFTY"), ignoring case.
+//   - kind: ExpiryWeekly or ExpiryMonthly; empty for the nearest expiry of either kind.
+//
+// Returns:
+//   - The expiry if successful.
+//   - An error if no instrument store is attached, the calendar cannot be fetched, or the
+//     underlying has no such expiry.
+func (c *Client) NearestExpiry(underlying string, kind ExpiryKind) (ContractExpiry, error) {
+	if c.instruments == nil {
+		return ContractExpiry{}, fmt.Errorf("nearest expiry requires an instrument store")
+	}
+	cal, err := c.GetTradingCalendar()
+	if err != nil {
+		return ContractExpiry{}, err
+	}
+
+	expiry, ok := c.instruments.NearestExpiry(underlying, time.Now(), kind, cal)
+	if !ok {
+		return ContractExpiry{}, fmt.Errorf("no %s expiry found for %s", cmp.Or(string(kind), "upcoming"), underlying)
+	}
+	return expiry, nil
+}
+
+// expiriesLocked returns the expiries of an underlying as listed in the instrument
+// master, classified. The caller must hold s.mu.
+func (s *InstrumentStore) expiriesLocked(underlying string) []ContractExpiry {
+	byDay := make(map[time.Time]*ContractExpiry)
+	futures := make(map[time.Time]bool)
+	var days []time.Time
+	for _, token := range s.byUnderlying[underlying] {
+		inst := s.byToken[token]
+		expiry, ok := inst.Expiry()
+		if !ok {
+			continue
+		}
+		day := midnight(expiry)
+		if isFuture(inst) {
+			futures[day] = true
+		}
+		if byDay[day] == nil {
+			byDay[day] = &ContractExpiry{Date: day, Listed: day, Exchange: Exchange(strings.ToUpper(inst.Exchange))}
+			days = append(days, day)
+		}
+	}
+	if len(days) == 0 {
+		return nil
+	}
+	slices.SortFunc(days, func(a, b time.Time) int { return a.Compare(b) })
+
+	expiries := make([]ContractExpiry, 0, len(days))
+	for i, day := range days {
+		expiry := *byDay[day]
+		monthly := futures[day]
+		if len(futures) == 0 {
+			monthly = i == len(days)-1 || days[i+1].Month() != day.Month()
+		}
+		expiry.Kind = ExpiryWeekly
+		if monthly {
+			expiry.Kind = ExpiryMonthly
+		}
+		expiries = append(expiries, expiry)
+	}
+	return expiries
+}
+
+// previousTradingDay returns day, or the last trading day of an exchange before it if it
+// is a holiday or weekend. Days without a trading day in the fortnight before them are
+// returned unchanged.
+func previousTradingDay(cal *TradingCalendar, exchange Exchange, day time.Time) time.Time {
+	for d, i := day, 0; i < 14; d, i = d.AddDate(0, 0, -1), i+1 {
+		_, holiday := cal.Holiday(venueOf(exchange), d)
+		if weekday := d.Weekday(); !holiday && weekday != time.Saturday && weekday != time.Sunday {
+			return d
+		}
+	}
+	return day
+}
+
+// isFuture reports whether an instrument is a futures contract.
+func isFuture(inst Instrument) bool {
+	return strings.HasPrefix(strings.ToUpper(inst.Instrument), "FUT")
+}
@@ -0,0 +1,181 @@
+package tiqs
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// ExpiringPosition represents an open position in a contract that expires on the given day.
+type ExpiringPosition struct {
+	Position         Position   `json:"position"`         // The open position.
+	Instrument       Instrument `json:"instrument"`       // Instrument master row of the contract.
+	Expiry           time.Time  `json:"expiry"`           // Expiry date of the contract.
+	Qty              int64      `json:"qty"`              // Net quantity; negative for short positions.
+	UnderlyingPrice  float64    `json:"underlyingPrice"`  // Last traded price of the underlying in rupees.
+	Intrinsic        float64    `json:"intrinsic"`        // Intrinsic value per unit in rupees.
+	IntrinsicValue   float64    `json:"intrinsicValue"`   // Intrinsic value of the whole position in rupees.
+	ITM              bool       `json:"itm"`              // Whether the option is in the money.
+	PhysicalDelivery bool       `json:"physicalDelivery"` // Whether an ITM stock option will be physically settled.
+}
+
+// Short reports whether the position is short.
+func (p ExpiringPosition) Short() bool {
+	return p.Qty < 0
+}
+
+// ExpirySquareOff represents the outcome of squaring off one expiring position.
+type ExpirySquareOff struct {
+	Position ExpiringPosition `json:"position"` // The position that was squared off.
+	OrderNo  string           `json:"orderNo"`  // Order number of the square-off order, empty in dry-run mode.
+	Err      error            `json:"-"`        // Error returned while placing the order, if any.
+}
+
+// GetExpiringPositions returns the open positions in contracts expiring on the given day.
+//
+// Instrument metadata is taken from the attached instrument store (see LoadInstruments).
+// For options, the underlying is quoted to compute the intrinsic value at its current
+// price, and ITM stock options are flagged for potential physical delivery.
+//
+// Parameters:
+//   - day: The expiry day to check, usually today.
+//
+// Returns:
+//   - A slice of ExpiringPosition structs if successful.
+//   - An error if no instrument store is attached or positions cannot be retrieved.
+func (c *Client) GetExpiringPositions(day time.Time) ([]ExpiringPosition, error) {
+	if c.instruments == nil {
+		return nil, fmt.Errorf("expiring positions require an instrument store")
+	}
+
+	positions, err := c.GetPositions()
+	if err != nil {
+		return nil, err
+	}
+
+	var expiring []ExpiringPosition
+	for _, p := range positions {
+		qty := parseInt(p.Qty)
+		if qty == 0 {
+			continue
+		}
+
+		inst, ok := c.instruments.Get(parseInt(p.Token))
+		if !ok {
+			continue
+		}
+
+		expiry, ok := inst.Expiry()
+		if !ok || !sameDay(expiry, day) {
+			continue
+		}
+
+		ep := ExpiringPosition{Position: p, Instrument: inst, Expiry: expiry, Qty: qty}
+		if inst.IsOption() {
+			c.evaluateIntrinsic(&ep)
+		}
+
+		if ep.PhysicalDelivery {
+			c.log().Warn().Str("symbol", p.Symbol).Int64("qty", qty).Msg("ITM stock option may result in physical delivery")
+		}
+		expiring = append(expiring, ep)
+	}
+
+	c.log().Info().Int("positions", len(expiring)).Msg("Expiring positions retrieved successfully")
+	return expiring, nil
+}
+
+// SquareOffExpiringShorts buys back in-the-money short options before the cutoff time.
+//
+// Only short option positions flagged as ITM are squared off, with market orders in the
+// position's product. Nothing is placed once the cutoff has passed.
+//
+// Parameters:
+//   - positions: Positions returned by GetExpiringPositions.
+//   - cutoff: The latest time at which square-off orders may be placed.
+//   - dryRun: If true, the positions that would be squared off are returned without placing orders.
+//
+// Returns:
+//   - A slice of ExpirySquareOff results, one per position acted upon.
+//   - An error if the cutoff has already passed.
+func (c *Client) SquareOffExpiringShorts(positions []ExpiringPosition, cutoff time.Time, dryRun bool) ([]ExpirySquareOff, error) {
+	if time.Now().After(cutoff) {
+		return nil, fmt.Errorf("square-off cutoff %s has passed", cutoff.Format(time.Kitchen))
+	}
+
+	var results []ExpirySquareOff
+	for _, ep := range positions {
+		if !ep.Short() || !ep.ITM {
+			continue
+		}
+
+		result := ExpirySquareOff{Position: ep}
+		if !dryRun {
+			order := OrderRequest{
+				Exchange:        Exchange(ep.Position.Exchange),
+				Token:           ep.Position.Token,
+				Symbol:          ep.Position.Symbol,
+				Product:         Product(ep.Position.Product),
+				Quantity:        strconv.FormatInt(-ep.Qty, 10),
+				TransactionType: TransactionBuy,
+				OrderType:       OrderTypeMarket,
+				Price:           "0",
+				Validity:        ValidityDay,
+			}
+
+			resp, err := c.PlaceOrder("regular", order)
+			if err != nil {
+				c.log().Error().Err(err).Str("symbol", ep.Position.Symbol).Msg("Failed to square off expiring short")
+				result.Err = err
+			} else {
+				result.OrderNo = resp.Data.OrderNo
+			}
+		}
+		results = append(results, result)
+	}
+
+	c.log().Info().Int("orders", len(results)).Bool("dryRun", dryRun).Msg("Expiring shorts processed")
+	return results, nil
+}
+
+// evaluateIntrinsic quotes the underlying of an option and fills in its intrinsic value.
+func (c *Client) evaluateIntrinsic(ep *ExpiringPosition) {
+	inst := ep.Instrument
+	if inst.UnderlyingToken == nil {
+		return
+	}
+
+	underlying := parseInt(*inst.UnderlyingToken)
+	quote, err := c.GetMarketQuoteDecimal(underlying, "ltp")
+	if err != nil {
+		c.log().Warn().Err(err).Str("symbol", inst.TradingSymbol).Msg("Failed to quote underlying of expiring option")
+		return
+	}
+
+	ep.UnderlyingPrice = quote.LTP
+	ep.Intrinsic = intrinsicValue(*inst.OptionType, ep.UnderlyingPrice, inst.Strike())
+
+	multiplier := float64(inst.Multiplier)
+	if multiplier == 0 {
+		multiplier = 1
+	}
+	ep.IntrinsicValue = ep.Intrinsic * math.Abs(float64(ep.Qty)) * multiplier
+	ep.ITM = ep.Intrinsic > 0
+	ep.PhysicalDelivery = ep.ITM && inst.Instrument == "OPTSTK"
+}
+
+// intrinsicValue returns the intrinsic value per unit of a call ("CE") or put ("PE").
+func intrinsicValue(optionType string, spot, strike float64) float64 {
+	if optionType == "PE" {
+		return math.Max(0, strike-spot)
+	}
+	return math.Max(0, spot-strike)
+}
+
+// sameDay reports whether a and b fall on the same calendar day in IST.
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.In(IST).Date()
+	by, bm, bd := b.In(IST).Date()
+	return ay == by && am == bm && ad == bd
+}
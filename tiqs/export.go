@@ -0,0 +1,234 @@
+package tiqs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gocarina/gocsv"
+)
+
+// ExportFormat selects the file format produced by the export helpers.
+type ExportFormat string
+
+const (
+	ExportCSV  ExportFormat = "csv"  // Comma-separated values with a header row.
+	ExportJSON ExportFormat = "json" // Indented JSON array.
+)
+
+// OrderExportRow is the typed, export-friendly representation of an order book row.
+type OrderExportRow struct {
+	OrderID         string  `csv:"order_id" json:"orderId"`
+	ExchangeOrderID string  `csv:"exchange_order_id" json:"exchangeOrderId"`
+	Exchange        string  `csv:"exchange" json:"exchange"`
+	Symbol          string  `csv:"symbol" json:"symbol"`
+	Token           string  `csv:"token" json:"token"`
+	TransactionType string  `csv:"transaction_type" json:"transactionType"`
+	Product         string  `csv:"product" json:"product"`
+	OrderType       string  `csv:"order_type" json:"orderType"`
+	Status          string  `csv:"status" json:"status"`
+	Quantity        int64   `csv:"quantity" json:"quantity"`
+	FilledQuantity  int64   `csv:"filled_quantity" json:"filledQuantity"`
+	Price           float64 `csv:"price" json:"price"`
+	TriggerPrice    float64 `csv:"trigger_price" json:"triggerPrice"`
+	AveragePrice    float64 `csv:"average_price" json:"averagePrice"`
+	OrderTime       string  `csv:"order_time" json:"orderTime"`
+	Tag             string  `csv:"tag" json:"tag"`
+	RejectReason    string  `csv:"reject_reason" json:"rejectReason"`
+}
+
+// TradeExportRow is the typed, export-friendly representation of a trade book row.
+type TradeExportRow struct {
+	TradeID         string  `csv:"trade_id" json:"tradeId"`
+	OrderID         string  `csv:"order_id" json:"orderId"`
+	Exchange        string  `csv:"exchange" json:"exchange"`
+	Symbol          string  `csv:"symbol" json:"symbol"`
+	Token           string  `csv:"token" json:"token"`
+	TransactionType string  `csv:"transaction_type" json:"transactionType"`
+	Product         string  `csv:"product" json:"product"`
+	Quantity        int64   `csv:"quantity" json:"quantity"`
+	Price           float64 `csv:"price" json:"price"`
+	Value           float64 `csv:"value" json:"value"`
+	TradeTime       string  `csv:"trade_time" json:"tradeTime"`
+}
+
+// PositionExportRow is the typed, export-friendly representation of a position.
+type PositionExportRow struct {
+	Exchange      string  `csv:"exchange" json:"exchange"`
+	Symbol        string  `csv:"symbol" json:"symbol"`
+	Token         string  `csv:"token" json:"token"`
+	Product       string  `csv:"product" json:"product"`
+	NetQuantity   int64   `csv:"net_quantity" json:"netQuantity"`
+	BuyQuantity   int64   `csv:"buy_quantity" json:"buyQuantity"`
+	SellQuantity  int64   `csv:"sell_quantity" json:"sellQuantity"`
+	AveragePrice  float64 `csv:"average_price" json:"averagePrice"`
+	LTP           float64 `csv:"ltp" json:"ltp"`
+	RealisedPnL   float64 `csv:"realised_pnl" json:"realisedPnl"`
+	UnrealisedPnL float64 `csv:"unrealised_pnl" json:"unrealisedPnl"`
+	PnL           float64 `csv:"pnl" json:"pnl"`
+}
+
+// OrderExportRows converts order book rows into typed export rows.
+func OrderExportRows(orders []OrderDetail) []OrderExportRow {
+	rows := make([]OrderExportRow, len(orders))
+	for i, o := range orders {
+		rows[i] = OrderExportRow{
+			OrderID:         o.ID,
+			ExchangeOrderID: o.ExchangeOrderID,
+			Exchange:        o.Exchange,
+			Symbol:          o.Symbol,
+			Token:           o.Token,
+			TransactionType: o.TransactionType,
+			Product:         o.Product,
+			OrderType:       o.Order,
+			Status:          o.Status,
+			Quantity:        parseInt(o.Quantity),
+			FilledQuantity:  parseInt(o.FillShares),
+			Price:           parseFloat(o.Price),
+			TriggerPrice:    parseFloat(o.OrderTriggerPrice),
+			AveragePrice:    parseFloat(o.AveragePrice),
+			OrderTime:       formatIST(o.OrderTime),
+			Tag:             o.Remarks,
+			RejectReason:    o.RejectReason,
+		}
+	}
+	return rows
+}
+
+// TradeExportRows converts trade book rows into typed export rows.
+func TradeExportRows(trades []Trade) []TradeExportRow {
+	rows := make([]TradeExportRow, len(trades))
+	for i, t := range trades {
+		qty := parseInt(t.FillShares)
+		price := parseFloat(t.FillPrice)
+		rows[i] = TradeExportRow{
+			TradeID:         t.FillID,
+			OrderID:         t.ID,
+			Exchange:        t.Exchange,
+			Symbol:          t.Symbol,
+			Token:           t.Token,
+			TransactionType: t.TransactionType,
+			Product:         t.Product,
+			Quantity:        qty,
+			Price:           price,
+			Value:           float64(qty) * price,
+			TradeTime:       formatIST(t.FillTime),
+		}
+	}
+	return rows
+}
+
+// PositionExportRows converts positions into typed export rows.
+func PositionExportRows(positions []Position) []PositionExportRow {
+	rows := make([]PositionExportRow, len(positions))
+	for i, p := range positions {
+		rows[i] = PositionExportRow{
+			Exchange:      p.Exchange,
+			Symbol:        p.Symbol,
+			Token:         p.Token,
+			Product:       p.Product,
+			NetQuantity:   parseInt(p.Qty),
+			BuyQuantity:   parseInt(p.DayBuyQty) + parseInt(p.CarryForwardBuyQty),
+			SellQuantity:  parseInt(p.DaySellQty) + parseInt(p.CarryForwardSellQty),
+			AveragePrice:  parseFloat(p.AvgPrice),
+			LTP:           parseFloat(p.Ltp),
+			RealisedPnL:   parseFloat(p.RealisedPnL),
+			UnrealisedPnL: parseFloat(p.UnRealisedPnl),
+			PnL:           parseFloat(p.Pnl),
+		}
+	}
+	return rows
+}
+
+// WriteExport writes export rows (or any slice of structs) to w in the given format.
+//
+// Parameters:
+//   - w: Destination writer.
+//   - format: ExportCSV or ExportJSON.
+//   - rows: A slice of export rows, e.g., the result of OrderExportRows.
+//
+// Returns:
+//   - An error if the format is unknown or the rows cannot be encoded; otherwise, nil.
+func WriteExport(w io.Writer, format ExportFormat, rows interface{}) error {
+	switch format {
+	case ExportCSV:
+		return gocsv.Marshal(rows, w)
+	case ExportJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(rows)
+	default:
+		return fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+// ExportTradingDay writes the day's order book, trade book and positions to dir.
+//
+// Three files are produced: orders.<ext>, trades.<ext> and positions.<ext>, with all
+// numbers typed and timestamps converted to ISO 8601 in IST.
+//
+// Parameters:
+//   - dir: Directory in which the files are created.
+//   - format: ExportCSV or ExportJSON.
+//
+// Returns:
+//   - An error if fetching data or writing any of the files fails; otherwise, nil.
+func (c *Client) ExportTradingDay(dir string, format ExportFormat) error {
+	orders, err := c.getOrderRows()
+	if err != nil {
+		return err
+	}
+
+	trades, err := c.GetTradeBook()
+	if err != nil {
+		return err
+	}
+
+	positions, err := c.GetPositions()
+	if err != nil {
+		return err
+	}
+
+	exports := map[string]interface{}{
+		"orders":    OrderExportRows(orders),
+		"trades":    TradeExportRows(trades),
+		"positions": PositionExportRows(positions),
+	}
+
+	for name, rows := range exports {
+		path := filepath.Join(dir, name+"."+string(format))
+		if err := writeExportFile(path, format, rows); err != nil {
+			c.log().Error().Err(err).Str("path", path).Msg("Failed to write export file")
+			return err
+		}
+	}
+
+	c.log().Info().Str("dir", dir).Str("format", string(format)).Msg("Trading day exported successfully")
+	return nil
+}
+
+// writeExportFile writes rows to a file at path in the given format.
+func writeExportFile(path string, format ExportFormat, rows interface{}) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := WriteExport(file, format, rows); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// formatIST converts an API timestamp into ISO 8601 in IST, leaving unparsable values untouched.
+func formatIST(s string) string {
+	t, ok := parseTimestamp(s)
+	if !ok {
+		return s
+	}
+	return t.Format(time.RFC3339)
+}
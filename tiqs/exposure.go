@@ -0,0 +1,173 @@
+package tiqs
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ExposureGroup represents the aggregated exposure of a group of positions.
+type ExposureGroup struct {
+	Key              string  `json:"key"`              // Group key (underlying, segment or direction).
+	Positions        int     `json:"positions"`        // Number of open positions in the group.
+	NetQty           int64   `json:"netQty"`           // Net quantity across the group (long minus short).
+	Notional         float64 `json:"notional"`         // Gross notional exposure of the group.
+	NetNotional      float64 `json:"netNotional"`      // Signed notional exposure of the group.
+	MarginAttributed float64 `json:"marginAttributed"` // Share of the used margin attributed to the group.
+	ConcentrationPct float64 `json:"concentrationPct"` // Share of the gross notional held by the group, in percent.
+}
+
+// ExposureSummary represents account-level exposure aggregated across all open positions.
+type ExposureSummary struct {
+	GeneratedAt   time.Time       `json:"generatedAt"`   // Time at which the summary was computed.
+	GrossNotional float64         `json:"grossNotional"` // Sum of absolute notional exposure across positions.
+	NetNotional   float64         `json:"netNotional"`   // Signed notional exposure (long minus short).
+	MarginUsed    float64         `json:"marginUsed"`    // Margin used as reported by the limits endpoint.
+	ByUnderlying  []ExposureGroup `json:"byUnderlying"`  // Exposure grouped by underlying, largest first.
+	BySegment     []ExposureGroup `json:"bySegment"`     // Exposure grouped by market segment, largest first.
+	ByDirection   []ExposureGroup `json:"byDirection"`   // Exposure grouped by direction (LONG/SHORT), largest first.
+	BySector      []ExposureGroup `json:"bySector"`      // Exposure grouped by sector, present when a SectorProvider is set.
+}
+
+// GetExposureSummary aggregates open positions into an account-level exposure summary.
+//
+// It fetches positions and trading limits, computes the notional exposure of every open
+// position and groups it by underlying, segment and direction. The used margin reported
+// by the limits endpoint is attributed to each group in proportion to its gross notional.
+// When a SectorProvider is attached via SetSectorProvider, exposure is also grouped by sector.
+//
+// Returns:
+//   - A pointer to an ExposureSummary struct if successful.
+//   - An error if positions or limits cannot be retrieved.
+func (c *Client) GetExposureSummary() (*ExposureSummary, error) {
+	positions, err := c.GetPositions()
+	if err != nil {
+		c.log().Error().Err(err).Msg("Failed to fetch positions for exposure summary")
+		return nil, err
+	}
+
+	limits, err := c.GetLimits()
+	if err != nil {
+		c.log().Error().Err(err).Msg("Failed to fetch limits for exposure summary")
+		return nil, err
+	}
+
+	var marginUsed float64
+	if len(limits.Data) > 0 {
+		marginUsed = parseFloat(limits.Data[0].MarginUsed)
+	}
+
+	summary := buildExposureSummary(positions, marginUsed, c.sectors)
+	c.log().Info().Float64("grossNotional", summary.GrossNotional).Msg("Exposure summary computed successfully")
+	return summary, nil
+}
+
+// buildExposureSummary computes the exposure summary for the given positions.
+func buildExposureSummary(positions []Position, marginUsed float64, sectors SectorProvider) *ExposureSummary {
+	summary := &ExposureSummary{
+		GeneratedAt: time.Now(),
+		MarginUsed:  marginUsed,
+	}
+
+	byUnderlying := make(map[string]*ExposureGroup)
+	bySegment := make(map[string]*ExposureGroup)
+	byDirection := make(map[string]*ExposureGroup)
+	bySector := make(map[string]*ExposureGroup)
+
+	for _, p := range positions {
+		qty := parseInt(p.Qty)
+		if qty == 0 {
+			continue
+		}
+
+		multiplier := parseFloat(p.Multiplier)
+		if multiplier == 0 {
+			multiplier = 1
+		}
+		netNotional := float64(qty) * parseFloat(p.Ltp) * multiplier
+		notional := math.Abs(netNotional)
+
+		direction := "LONG"
+		if qty < 0 {
+			direction = "SHORT"
+		}
+
+		summary.GrossNotional += notional
+		summary.NetNotional += netNotional
+
+		addExposure(byUnderlying, underlyingOf(p.Symbol), qty, notional, netNotional)
+		addExposure(bySegment, Exchange(strings.ToUpper(p.Exchange)).Segment().String(), qty, notional, netNotional)
+		addExposure(byDirection, direction, qty, notional, netNotional)
+
+		if sectors != nil {
+			sector := unclassifiedSector
+			if info, ok := sectors.Sector(underlyingOf(p.Symbol), ""); ok && info.Sector != "" {
+				sector = info.Sector
+			}
+			addExposure(bySector, sector, qty, notional, netNotional)
+		}
+	}
+
+	summary.ByUnderlying = finalizeExposureGroups(byUnderlying, summary.GrossNotional, marginUsed)
+	summary.BySegment = finalizeExposureGroups(bySegment, summary.GrossNotional, marginUsed)
+	summary.ByDirection = finalizeExposureGroups(byDirection, summary.GrossNotional, marginUsed)
+	if sectors != nil {
+		summary.BySector = finalizeExposureGroups(bySector, summary.GrossNotional, marginUsed)
+	}
+	return summary
+}
+
+// addExposure accumulates a single position into the group identified by key.
+func addExposure(groups map[string]*ExposureGroup, key string, qty int64, notional, netNotional float64) {
+	g, ok := groups[key]
+	if !ok {
+		g = &ExposureGroup{Key: key}
+		groups[key] = g
+	}
+	g.Positions++
+	g.NetQty += qty
+	g.Notional += notional
+	g.NetNotional += netNotional
+}
+
+// finalizeExposureGroups computes concentration and margin attribution and sorts the groups
+// by gross notional in descending order.
+func finalizeExposureGroups(groups map[string]*ExposureGroup, gross, marginUsed float64) []ExposureGroup {
+	result := make([]ExposureGroup, 0, len(groups))
+	for _, g := range groups {
+		if gross > 0 {
+			share := g.Notional / gross
+			g.ConcentrationPct = share * 100
+			g.MarginAttributed = share * marginUsed
+		}
+		result = append(result, *g)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Notional == result[j].Notional {
+			return result[i].Key < result[j].Key
+		}
+		return result[i].Notional > result[j].Notional
+	})
+	return result
+}
+
+// underlyingOf derives the underlying name from a trading symbol.
+//
+// Derivative symbols (e.g., "NIFTY25MAY24000CE") are cut at the first digit, while
+// equity symbols (e.g., "RELIANCE-EQ") have their series suffix removed.
+func underlyingOf(symbol string) string {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+
+	if i := strings.IndexFunc(symbol, unicode.IsDigit); i > 0 {
+		return symbol[:i]
+	}
+
+	if i := strings.LastIndex(symbol, "-"); i > 0 && len(symbol)-i <= 3 {
+		return symbol[:i]
+	}
+
+	return symbol
+}
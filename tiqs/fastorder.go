@@ -0,0 +1,239 @@
+package tiqs
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// OrderTemplate places orders on a fixed instrument with minimal client-side overhead.
+//
+// Everything that does not change between orders (exchange, token, symbol, product, order
+// type, validity, tags) is validated and marshalled once when the template is created.
+// Placing an order then only appends side, quantity and prices to a pooled buffer and
+// skips the Info-level request and payload logging of PlaceOrder, which keeps client-side
+// latency well below a millisecond for scalping strategies.
+//
+// Attached guards (health monitor, symbol control, stale guard, risk manager, duplicate
+// guard) and the order rate limiter still apply, so a tripped kill switch refuses template
+// orders as it refuses PlaceOrder, and they count towards MaxOrdersPerMinute. Symbol, risk
+// and duplicate checks are the only ones that allocate, so leave those guards detached
+// when every microsecond counts.
+type OrderTemplate struct {
+	client    *Client
+	endpoint  string
+	base      OrderRequest
+	prefix    []byte // Marshalled static fields, without the closing brace.
+	precision int    // Decimal places of prices in the instrument's segment.
+	lotSize   int64  // Quantities must be a multiple of it; 1 when unknown or in lots.
+	token     int64
+}
+
+// orderTemplateFields are the static fields of an order marshalled by an OrderTemplate.
+type orderTemplateFields struct {
+	Exchange        Exchange  `json:"exchange"`
+	Token           string    `json:"token"`
+	DisclosedQty    string    `json:"disclosedQty,omitempty"`
+	Product         Product   `json:"product"`
+	Symbol          string    `json:"symbol"`
+	OrderType       OrderType `json:"order"`
+	Validity        Validity  `json:"validity"`
+	Tags            string    `json:"tags,omitempty"`
+	AMO             bool      `json:"amo,omitempty"`
+	BookLossPrice   string    `json:"bookLossPrice,omitempty"`
+	BookProfitPrice string    `json:"bookProfitPrice,omitempty"`
+	TrailingPrice   string    `json:"trailingPrice,omitempty"`
+}
+
+// payloadPool recycles the buffers payloads are built in.
+var payloadPool = sync.Pool{New: func() any { b := make([]byte, 0, 512); return &b }}
+
+// NewOrderTemplate prepares a template for fast order placement.
+//
+// The quantity, price, trigger price and side of base are ignored; they are given to
+// each Place call instead.
+//
+// Parameters:
+//   - orderType: Type of order passed to the endpoint (e.g., "regular").
+//   - base: The static fields of the orders.
+//
+// Returns:
+//   - A pointer to the OrderTemplate if successful.
+//   - An error if an enum code of base, e.g., its exchange or product, is invalid.
+func (c *Client) NewOrderTemplate(orderType string, base OrderRequest) (*OrderTemplate, error) {
+	// Quantity and prices are checked on each Place; only validate what the template fixes.
+	check := withQuantity(base, "1")
+	check.TransactionType, check.Price, check.TriggerPrice = TransactionBuy, "1", "1"
+	if err := check.Validate(); err != nil {
+		return nil, err
+	}
+	check.Price, check.TriggerPrice = "", ""
+	if err := ValidateSegment(check, nil); err != nil {
+		return nil, err
+	}
+	exchange := Exchange(strings.ToUpper(string(base.Exchange)))
+	rules := exchange.Segment().Rules()
+
+	var lotSize int64 = 1
+	if c.instruments != nil && !rules.QuantityInLots {
+		if inst, ok := c.instruments.Get(parseInt(base.Token)); ok && inst.LotSize > 1 {
+			lotSize = inst.LotSize
+		}
+	}
+
+	prefix, err := json.Marshal(orderTemplateFields{
+		Exchange:        base.Exchange,
+		Token:           base.Token,
+		DisclosedQty:    base.DisclosedQty,
+		Product:         base.Product,
+		Symbol:          base.Symbol,
+		OrderType:       base.OrderType,
+		Validity:        base.Validity,
+		Tags:            base.Tags,
+		AMO:             base.AMO,
+		BookLossPrice:   base.BookLossPrice,
+		BookProfitPrice: base.BookProfitPrice,
+		TrailingPrice:   base.TrailingPrice,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling order template: %w", err)
+	}
+
+	return &OrderTemplate{
+		client:    c,
+		endpoint:  c.endpoint(EndpointPlaceOrder, orderType),
+		base:      base,
+		prefix:    prefix[:len(prefix)-1],
+		precision: rules.PricePrecision,
+		lotSize:   lotSize,
+		token:     parseInt(base.Token),
+	}, nil
+}
+
+// Place places an order from the template.
+//
+// Parameters:
+//   - side: Buy or sell.
+//   - quantity: Order quantity; must be positive.
+//   - price: Limit price in rupees; zero for market orders.
+//   - triggerPrice: Trigger price in rupees; zero if not applicable.
+//
+// Returns:
+//   - A pointer to OrderResponse with the order confirmation details if successful.
+//   - An error if a guard refuses the order or the placement fails.
+func (t *OrderTemplate) Place(side TransactionType, quantity int64, price, triggerPrice float64) (*OrderResponse, error) {
+	if quantity <= 0 {
+		return nil, fmt.Errorf("invalid quantity: %d", quantity)
+	}
+	if quantity%t.lotSize != 0 {
+		return nil, fmt.Errorf("quantity %d is not a multiple of the lot size %d", quantity, t.lotSize)
+	}
+	c := t.client
+
+	if c.health != nil {
+		if err := c.health.check(); err != nil {
+			return nil, err
+		}
+	}
+	if c.staleGuard != nil {
+		if err := c.staleGuard.check(t.token); err != nil {
+			return nil, err
+		}
+	}
+
+	var release func()
+	if c.symbols != nil || c.risk != nil || c.duplicates != nil {
+		order := t.Order(side, quantity, price, triggerPrice)
+		if c.symbols != nil {
+			if err := c.symbols.check(order); err != nil {
+				return nil, err
+			}
+		}
+		if c.risk != nil {
+			if err := c.risk.check(order); err != nil {
+				return nil, err
+			}
+		}
+		if c.duplicates != nil {
+			var err error
+			if release, err = c.duplicates.reserve(order); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	result, err := t.send(side, quantity, price, triggerPrice)
+	if err != nil && release != nil {
+		release()
+	}
+	return result, err
+}
+
+// Order returns the OrderRequest equivalent to a Place call, e.g., for journaling.
+func (t *OrderTemplate) Order(side TransactionType, quantity int64, price, triggerPrice float64) OrderRequest {
+	order := withQuantity(t.base, strconv.FormatInt(quantity, 10))
+	order.TransactionType = side
+	order.Price = ""
+	order.TriggerPrice = ""
+	if price != 0 {
+		order.Price = strconv.FormatFloat(price, 'f', t.precision, 64)
+	}
+	if triggerPrice != 0 {
+		order.TriggerPrice = strconv.FormatFloat(triggerPrice, 'f', t.precision, 64)
+	}
+	return order
+}
+
+// AppendPayload appends the JSON payload of an order to dst, as sent by Place.
+func (t *OrderTemplate) AppendPayload(dst []byte, side TransactionType, quantity int64, price, triggerPrice float64) []byte {
+	dst = append(dst, t.prefix...)
+	dst = append(dst, `,"quantity":"`...)
+	dst = strconv.AppendInt(dst, quantity, 10)
+	dst = append(dst, `","transactionType":`...)
+	dst = strconv.AppendQuote(dst, string(side))
+	dst = append(dst, `,"price":"`...)
+	if price != 0 {
+		dst = strconv.AppendFloat(dst, price, 'f', t.precision, 64)
+	}
+	dst = append(dst, '"')
+	if triggerPrice != 0 {
+		dst = append(dst, `,"triggerPrice":"`...)
+		dst = strconv.AppendFloat(dst, triggerPrice, 'f', t.precision, 64)
+		dst = append(dst, '"')
+	}
+	return append(dst, '}')
+}
+
+// send builds the payload in a pooled buffer and posts it without Info-level logging.
+func (t *OrderTemplate) send(side TransactionType, quantity int64, price, triggerPrice float64) (*OrderResponse, error) {
+	c := t.client
+	buf := payloadPool.Get().(*[]byte)
+	payload := t.AppendPayload((*buf)[:0], side, quantity, price, triggerPrice)
+	defer func() {
+		*buf = payload[:0]
+		payloadPool.Put(buf)
+	}()
+
+	var result OrderResponse
+	err := c.exchange(t.endpoint, "POST", payload, func(body []byte) error {
+		if err := c.decode(EndpointPlaceOrder, body, &result); err != nil {
+			return err
+		}
+		if result.Status != "success" {
+			return newAPIError("order placement", t.endpoint, 0, body)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// withQuantity returns a copy of the order with the given quantity.
+func withQuantity(order OrderRequest, quantity string) OrderRequest {
+	order.Quantity = quantity
+	return order
+}
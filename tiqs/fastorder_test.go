@@ -0,0 +1,78 @@
+package tiqs_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/Abhi13027/go-tiqs/tiqs"
+	"github.com/Abhi13027/go-tiqs/tiqs/tiqstest"
+	"github.com/rs/zerolog"
+)
+
+// benchOrder is the order placed by the order benchmarks.
+var benchOrder = tiqs.OrderRequest{
+	Exchange:        tiqs.ExchangeNFO,
+	Token:           "35001",
+	Quantity:        "75",
+	Price:           "102.05",
+	Product:         tiqs.ProductMIS,
+	Symbol:          "NIFTY24DEC24000CE",
+	TransactionType: tiqs.TransactionBuy,
+	OrderType:       tiqs.OrderTypeLimit,
+	Validity:        tiqs.ValidityDay,
+}
+
+// benchClient returns a client of a mock server accepting every order. The client logs to
+// a discarded writer, so PlaceOrder pays for formatting its log lines as it would in
+// production. The numbers exclude network and broker latency, but include the mock
+// server, which runs in the same process.
+func benchClient(b *testing.B) *tiqs.Client {
+	server := tiqstest.NewServer()
+	b.Cleanup(server.Close)
+	client := server.Client()
+	client.SetLogger(tiqs.NewZerologLogger(zerolog.New(io.Discard)))
+	return client
+}
+
+func BenchmarkPlaceOrder(b *testing.B) {
+	client := benchClient(b)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		if _, err := client.PlaceOrder("regular", benchOrder); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkOrderTemplatePlace(b *testing.B) {
+	client := benchClient(b)
+	template, err := client.NewOrderTemplate("regular", benchOrder)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		if _, err := template.Place(tiqs.TransactionBuy, 75, 102.05, 0); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkOrderTemplateAppendPayload measures building the payload alone.
+func BenchmarkOrderTemplateAppendPayload(b *testing.B) {
+	client := tiqs.NewClient("bench", "bench")
+	template, err := client.NewOrderTemplate("regular", benchOrder)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	buf := make([]byte, 0, 512)
+	for range b.N {
+		buf = template.AppendPayload(buf[:0], tiqs.TransactionBuy, 75, 102.05, 0)
+	}
+}
@@ -0,0 +1,171 @@
+package tiqs
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ErrInjectedFault is returned by requests failed on purpose by a FaultInjector.
+var ErrInjectedFault = errors.New("injected fault")
+
+// FaultConfig sets the probabilities of the faults injected into REST requests.
+//
+// Each probability is a fraction between 0 and 1 and is drawn independently for every
+// request, so a request may be both delayed and failed.
+type FaultConfig struct {
+	ErrorRate       float64       // Probability that a request fails with a transport error before being sent.
+	ServerErrorRate float64       // Probability that a request fails with an HTTP 503 APIError.
+	MalformedRate   float64       // Probability that the response body is truncated.
+	DelayRate       float64       // Probability that a request is delayed.
+	MaxDelay        time.Duration // Upper bound of an injected delay; delays are uniform in [0, MaxDelay).
+	Seed            int64         // Seed of the random source; zero seeds from the current time.
+}
+
+// FaultStats counts the faults injected so far.
+type FaultStats struct {
+	Requests     int64 `json:"requests"`     // Requests seen by the injector.
+	Errors       int64 `json:"errors"`       // Requests failed with a transport error.
+	ServerErrors int64 `json:"serverErrors"` // Requests answered with a server error.
+	Malformed    int64 `json:"malformed"`    // Responses truncated.
+	Delays       int64 `json:"delays"`       // Requests delayed.
+}
+
+// FaultInjector makes a client's REST requests fail, stall or return garbage at random.
+//
+// It is meant for tests, typically against a mock server, to check that retries, health
+// monitoring and recovery logic cope with an unreliable broker. SDK users can attach one
+// to harden their own bots before going live. A client without an injector is unaffected.
+type FaultInjector struct {
+	mu      sync.Mutex
+	config  FaultConfig
+	rng     *rand.Rand
+	stats   FaultStats
+	enabled bool
+}
+
+// faultPlan is the set of faults drawn for a single request.
+type faultPlan struct {
+	delay       time.Duration
+	fail        bool
+	serverError bool
+	malformed   bool
+}
+
+// NewFaultInjector creates an enabled injector.
+//
+// Parameters:
+//   - config: The fault probabilities.
+//
+// Returns:
+//   - A pointer to a newly created FaultInjector.
+func NewFaultInjector(config FaultConfig) *FaultInjector {
+	seed := config.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &FaultInjector{
+		config:  config,
+		rng:     rand.New(rand.NewSource(seed)),
+		enabled: true,
+	}
+}
+
+// SetEnabled turns fault injection on or off without detaching the injector, e.g., to
+// let a test warm up before the chaos starts.
+func (f *FaultInjector) SetEnabled(enabled bool) {
+	f.mu.Lock()
+	f.enabled = enabled
+	f.mu.Unlock()
+}
+
+// Stats returns the number of faults injected so far.
+func (f *FaultInjector) Stats() FaultStats {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.stats
+}
+
+// plan draws the faults of the next request.
+func (f *FaultInjector) plan() faultPlan {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var p faultPlan
+	if !f.enabled {
+		return p
+	}
+	f.stats.Requests++
+
+	if f.config.MaxDelay > 0 && f.rng.Float64() < f.config.DelayRate {
+		p.delay = time.Duration(f.rng.Int63n(int64(f.config.MaxDelay)))
+		f.stats.Delays++
+	}
+	switch {
+	case f.rng.Float64() < f.config.ErrorRate:
+		p.fail = true
+		f.stats.Errors++
+	case f.rng.Float64() < f.config.ServerErrorRate:
+		p.serverError = true
+		f.stats.ServerErrors++
+	case f.rng.Float64() < f.config.MalformedRate:
+		p.malformed = true
+		f.stats.Malformed++
+	}
+	return p
+}
+
+// truncate returns a prefix of body cut at a random position.
+func (f *FaultInjector) truncate(body []byte) []byte {
+	if len(body) == 0 {
+		return body
+	}
+	f.mu.Lock()
+	n := f.rng.Intn(len(body))
+	f.mu.Unlock()
+	return body[:n]
+}
+
+// injectedServerError is the body returned for an injected server error, shaped like the
+// error responses of the API.
+var injectedServerError = []byte(`{"status":"error","message":"injected fault: service unavailable","errorCode":"503"}`)
+
+// SetFaultInjector attaches a FaultInjector to the client.
+//
+// Once attached, every REST request may be delayed, failed before it is sent, answered
+// with a server error or have its response truncated, with the configured probabilities.
+// Injected failures are reported to an attached HealthMonitor like real ones.
+//
+// Parameters:
+//   - injector: The injector to attach, or nil to detach the current one.
+func (c *Client) SetFaultInjector(injector *FaultInjector) {
+	c.faults = injector
+}
+
+// injectFaults applies the faults drawn for a request before it is sent.
+//
+// Returns:
+//   - The plan, so that response faults can be applied once the response is received.
+//   - An error if the request must fail, an *APIError with HTTP status 503 for injected server errors.
+func (c *Client) injectFaults(method, endpoint string) (faultPlan, error) {
+	if c.faults == nil {
+		return faultPlan{}, nil
+	}
+	p := c.faults.plan()
+	if p.delay > 0 {
+		time.Sleep(p.delay)
+	}
+	if p.fail {
+		err := fmt.Errorf("%s: %w", endpoint, ErrInjectedFault)
+		c.recordHealth(err)
+		return p, err
+	}
+	if p.serverError {
+		err := newAPIError(method+" "+endpoint, endpoint, 503, injectedServerError)
+		c.recordHealth(err)
+		return p, err
+	}
+	return p, nil
+}
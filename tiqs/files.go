@@ -0,0 +1,15 @@
+package tiqs
+
+import (
+	"os"
+)
+
+// writeFileAtomic writes data to a temporary file next to path and renames it into place,
+// so that readers never observe a partially written file. The file is created with perm.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
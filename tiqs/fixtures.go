@@ -0,0 +1,159 @@
+package tiqs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Abhi13027/go-tiqs/ticks"
+)
+
+// GoldenResult is the outcome of decoding one recorded response against its golden file.
+type GoldenResult struct {
+	Version  APIVersion   // API version of the fixture.
+	Endpoint EndpointName // Endpoint the response was recorded from.
+	File     string       // Path of the fixture.
+	Got      []byte       // Decoding of the response, see DecodeResponse.
+	Err      error        // Mismatch or decoding error, or nil if the decoding equals the golden file.
+}
+
+// DecodeResponse decodes a recorded response body into the SDK type of its endpoint and
+// returns the result as indented JSON, the format of golden files. The JSON shows the
+// fields exactly as the SDK sees them, so fields lost to a renamed key or a changed type
+// stand out.
+//
+// Parameters:
+//   - version: The API version the response was recorded from.
+//   - endpoint: The endpoint the response was recorded from (e.g., EndpointQuote).
+//   - body: The response body.
+//
+// Returns:
+//   - The decoded response as JSON.
+//   - An error if the endpoint has no buffered JSON response or the body cannot be decoded.
+func DecodeResponse(version APIVersion, endpoint EndpointName, body []byte) ([]byte, error) {
+	target, ok := compatibilityTargets[endpoint]
+	if !ok {
+		return nil, fmt.Errorf("unknown endpoint %q", endpoint)
+	}
+	client := &Client{Config: Config{APIVersion: version}}
+	v := target()
+	if err := client.decode(endpoint, body, v); err != nil {
+		return nil, fmt.Errorf("error decoding response: %w", err)
+	}
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+// CheckGoldenResponses decodes every recorded response of a corpus and compares the
+// result with its golden file.
+//
+// Fixtures are shared with CheckCompatibility, as "<version>/<endpoint>.json", with the
+// expected decoding in "<version>/<endpoint>.golden.json" (see DecodeResponse).
+// Files are compared as JSON, so formatting differences do not matter. A response without
+// golden file fails; create it with UpdateGoldenResponses once the decoding was checked.
+//
+// Parameters:
+//   - fixtures: The file system holding the fixtures, e.g., os.DirFS("testdata/compat").
+//
+// Returns:
+//   - One result per fixture, sorted by version and endpoint.
+//   - An error if the fixtures cannot be listed.
+func CheckGoldenResponses(fixtures fs.FS) ([]GoldenResult, error) {
+	files, err := goldenInputs(fixtures)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]GoldenResult, 0, len(files))
+	for _, file := range files {
+		results = append(results, checkGoldenResponse(fixtures, file))
+	}
+	return results, nil
+}
+
+// checkGoldenResponse decodes one recorded response and compares it with its golden file.
+func checkGoldenResponse(fixtures fs.FS, file string) GoldenResult {
+	result := GoldenResult{
+		Version:  APIVersion(path.Dir(file)),
+		Endpoint: EndpointName(strings.TrimSuffix(path.Base(file), ".json")),
+		File:     file,
+	}
+	body, err := fs.ReadFile(fixtures, file)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	if result.Got, err = DecodeResponse(result.Version, result.Endpoint, body); err != nil {
+		result.Err = err
+		return result
+	}
+	want, err := fs.ReadFile(fixtures, goldenResponseName(file))
+	if err != nil {
+		result.Err = fmt.Errorf("missing golden file: %w", err)
+		return result
+	}
+	result.Err = ticks.CompareJSON(want, result.Got)
+	return result
+}
+
+// UpdateGoldenResponses writes the golden file of every recorded response in dir from
+// the current decoders, e.g., after adding captures or after a deliberate change of an SDK
+// type. Review the differences before committing them.
+//
+// Parameters:
+//   - dir: The directory holding the "<version>/<endpoint>.json" fixtures.
+//
+// Returns:
+//   - The number of golden files written.
+//   - An error if a response cannot be read, decoded or written.
+func UpdateGoldenResponses(dir string) (int, error) {
+	files, err := goldenInputs(os.DirFS(dir))
+	if err != nil {
+		return 0, err
+	}
+	for i, file := range files {
+		body, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			return i, err
+		}
+		version := APIVersion(path.Dir(file))
+		endpoint := EndpointName(strings.TrimSuffix(path.Base(file), ".json"))
+		golden, err := DecodeResponse(version, endpoint, body)
+		if err != nil {
+			return i, fmt.Errorf("%s: %w", file, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, goldenResponseName(file)), golden, 0o644); err != nil {
+			return i, err
+		}
+	}
+	return len(files), nil
+}
+
+// goldenInputs lists the recorded responses of a corpus, leaving out the golden files.
+func goldenInputs(fixtures fs.FS) ([]string, error) {
+	files, err := fs.Glob(fixtures, "*/*.json")
+	if err != nil {
+		return nil, fmt.Errorf("error listing fixtures: %w", err)
+	}
+	inputs := files[:0]
+	for _, file := range files {
+		if !strings.HasSuffix(file, ticks.GoldenSuffix) {
+			inputs = append(inputs, file)
+		}
+	}
+	sort.Strings(inputs)
+	return inputs, nil
+}
+
+// goldenResponseName returns the golden file of a recorded response.
+func goldenResponseName(file string) string {
+	return strings.TrimSuffix(file, ".json") + ticks.GoldenSuffix
+}
@@ -0,0 +1,26 @@
+package tiqs_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/Abhi13027/go-tiqs/tiqs"
+)
+
+// TestGoldenResponses decodes the recorded responses in testdata/compat and compares them
+// with their golden files. Regenerate the golden files with examples/fixtures -update after
+// a deliberate change of an SDK type.
+func TestGoldenResponses(t *testing.T) {
+	results, err := tiqs.CheckGoldenResponses(os.DirFS("testdata/compat"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) == 0 {
+		t.Fatal("no responses in testdata/compat")
+	}
+	for _, r := range results {
+		if r.Err != nil {
+			t.Errorf("%s: %v", r.File, r.Err)
+		}
+	}
+}
@@ -0,0 +1,307 @@
+package tiqs
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// PaymentMode is the channel of a payin.
+type PaymentMode string
+
+const (
+	PaymentUPI        PaymentMode = "UPI"        // UPI collect request or intent.
+	PaymentNetBanking PaymentMode = "NETBANKING" // Net banking through the payment gateway.
+)
+
+// FundTransactionType tells payins from payouts.
+type FundTransactionType string
+
+const (
+	FundPayin  FundTransactionType = "PAYIN"  // Funds added to the trading account.
+	FundPayout FundTransactionType = "PAYOUT" // Funds withdrawn to the bank account.
+)
+
+// FundTransactionStatus is the state of a payin or a payout.
+type FundTransactionStatus string
+
+const (
+	FundPending   FundTransactionStatus = "PENDING"   // Initiated and awaiting the bank or the payout batch.
+	FundSuccess   FundTransactionStatus = "SUCCESS"   // Credited to the trading or the bank account.
+	FundFailed    FundTransactionStatus = "FAILED"    // Declined by the bank or the broker.
+	FundCancelled FundTransactionStatus = "CANCELLED" // Cancelled before it was processed.
+)
+
+// PayinRequest is a request to add funds to the trading account from a linked bank account.
+type PayinRequest struct {
+	Amount      float64     `json:"amount"`                // Amount in rupees.
+	Mode        PaymentMode `json:"mode"`                  // Payment channel.
+	BankAccount string      `json:"bankAccount,omitempty"` // Linked bank account to debit; empty for the primary account.
+	VPA         string      `json:"vpa,omitempty"`         // UPI address to send a collect request to; PaymentUPI only.
+	Segment     string      `json:"segment,omitempty"`     // Segment to credit, e.g., "COMMODITY"; empty for equity.
+}
+
+// PayinResponse represents the API response to a payin.
+type PayinResponse struct {
+	Status string `json:"status"` // API response status (e.g., "success" or "error").
+	Data   struct {
+		TransactionID string `json:"transactionId"` // Identifier of the payin, as listed by GetFundTransactions.
+		PaymentURL    string `json:"paymentUrl"`    // Page completing a net banking or UPI intent payment.
+		Status        string `json:"status"`        // State of the payin, usually PENDING until the bank confirms it.
+	} `json:"data"`
+}
+
+// PayoutRequest is a request to withdraw funds to a linked bank account.
+type PayoutRequest struct {
+	Amount      float64 `json:"amount"`                // Amount in rupees.
+	BankAccount string  `json:"bankAccount,omitempty"` // Linked bank account to credit; empty for the primary account.
+	Segment     string  `json:"segment,omitempty"`     // Segment to withdraw from, e.g., "COMMODITY"; empty for equity.
+}
+
+// PayoutResponse represents the API response to a payout request.
+type PayoutResponse struct {
+	Status string `json:"status"` // API response status (e.g., "success" or "error").
+	Data   struct {
+		TransactionID string `json:"transactionId"` // Identifier of the payout, as listed by GetFundTransactions.
+		Status        string `json:"status"`        // State of the payout, PENDING until the payout batch runs.
+		Message       string `json:"message"`       // Confirmation message, e.g., the expected credit date.
+	} `json:"data"`
+}
+
+// validateFundAmount checks that an amount is positive and in whole paise.
+func validateFundAmount(amount float64) error {
+	if amount <= 0 || math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return fmt.Errorf("invalid amount: %v", amount)
+	}
+	if paise := amount * 100; math.Abs(paise-math.Round(paise)) > 1e-6 {
+		return fmt.Errorf("amount %v is not in whole paise", amount)
+	}
+	return nil
+}
+
+// Validate checks the amount and payment channel of the payin.
+//
+// Returns:
+//   - An error describing the first problem found, or nil.
+func (r PayinRequest) Validate() error {
+	if err := validateFundAmount(r.Amount); err != nil {
+		return err
+	}
+	switch r.Mode {
+	case PaymentUPI:
+	case PaymentNetBanking:
+		if r.VPA != "" {
+			return fmt.Errorf("a UPI address is only used by %s payins", PaymentUPI)
+		}
+	default:
+		return fmt.Errorf("invalid payment mode: %q", r.Mode)
+	}
+	return nil
+}
+
+// Validate checks the amount of the payout.
+//
+// Returns:
+//   - An error describing the first problem found, or nil.
+func (r PayoutRequest) Validate() error {
+	return validateFundAmount(r.Amount)
+}
+
+// InitiatePayin starts adding funds to the trading account.
+//
+// It sends a POST request to the "/funds/payin" endpoint. The payin completes outside
+// the SDK: the user approves the UPI collect request, or follows PaymentURL to pay by net
+// banking; poll GetFundTransactions for its final status.
+//
+// Payins move money, so they are never retried after a failure that may have left them
+// processed (see RetryPolicy), and a disarmed Interlock refuses them.
+//
+// Parameters:
+//   - req: The amount and payment channel.
+//
+// Returns:
+//   - A pointer to the PayinResponse if successful.
+//   - An error if the payin is invalid, the request fails or the API rejects it.
+func (c *Client) InitiatePayin(req PayinRequest) (*PayinResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	endpoint := c.endpoint(EndpointPayin)
+	payload, err := json.Marshal(req)
+	if err != nil {
+		c.log().Error().Err(err).Msg("Failed to serialize payin")
+		return nil, err
+	}
+
+	resp, err := c.request(endpoint, "POST", payload)
+	if err != nil {
+		c.log().Error().Err(err).Msg("Failed to initiate payin")
+		return nil, err
+	}
+
+	var result PayinResponse
+	if err := c.decode(EndpointPayin, resp, &result); err != nil {
+		c.log().Error().Err(err).Msg("Failed to parse payin response")
+		return nil, err
+	}
+	if result.Status != "success" {
+		return nil, newAPIError("payin", endpoint, 0, resp)
+	}
+
+	c.log().Info().
+		Float64("amount", req.Amount).
+		Str("mode", string(req.Mode)).
+		Str("transactionId", result.Data.TransactionID).
+		Msg("Payin initiated")
+	return &result, nil
+}
+
+// RequestPayout requests a withdrawal to a linked bank account.
+//
+// It sends a POST request to the "/funds/payout" endpoint. Payouts are processed in
+// batches by the broker, so the response only acknowledges the request; poll
+// GetFundTransactions for its final status. The withdrawable amount is bounded by the
+// cash in GetLimits less margins in use and unsettled credits.
+//
+// Payouts move money, so they are never retried after a failure that may have left them
+// processed (see RetryPolicy), and a disarmed Interlock refuses them.
+//
+// Parameters:
+//   - req: The amount and bank account.
+//
+// Returns:
+//   - A pointer to the PayoutResponse if successful.
+//   - An error if the payout is invalid, the request fails or the API rejects it.
+func (c *Client) RequestPayout(req PayoutRequest) (*PayoutResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	endpoint := c.endpoint(EndpointPayout)
+	payload, err := json.Marshal(req)
+	if err != nil {
+		c.log().Error().Err(err).Msg("Failed to serialize payout")
+		return nil, err
+	}
+
+	resp, err := c.request(endpoint, "POST", payload)
+	if err != nil {
+		c.log().Error().Err(err).Msg("Failed to request payout")
+		return nil, err
+	}
+
+	var result PayoutResponse
+	if err := c.decode(EndpointPayout, resp, &result); err != nil {
+		c.log().Error().Err(err).Msg("Failed to parse payout response")
+		return nil, err
+	}
+	if result.Status != "success" {
+		return nil, newAPIError("payout", endpoint, 0, resp)
+	}
+
+	c.log().Info().
+		Float64("amount", req.Amount).
+		Str("transactionId", result.Data.TransactionID).
+		Msg("Payout requested")
+	return &result, nil
+}
+
+// FundTransactionRow is a payin or payout as returned by the API, with every field a
+// string; GetFundTransactions converts rows to FundTransactions.
+type FundTransactionRow struct {
+	TransactionID string `json:"transactionId"` // Identifier of the transaction.
+	Type          string `json:"type"`          // PAYIN or PAYOUT.
+	Amount        string `json:"amount"`        // Amount in rupees.
+	Status        string `json:"status"`        // State of the transaction.
+	Mode          string `json:"mode"`          // Payment channel of payins.
+	BankAccount   string `json:"bankAccount"`   // Masked bank account number.
+	Segment       string `json:"segment"`       // Segment credited or debited.
+	Reference     string `json:"reference"`     // Bank reference (UTR) once processed.
+	Remarks       string `json:"remarks"`       // Reason of failures and cancellations.
+	CreatedAt     string `json:"createdAt"`     // Time the transaction was initiated.
+	UpdatedAt     string `json:"updatedAt"`     // Time of the last status change.
+}
+
+// FundTransactionsResponse represents the API response containing fund transactions.
+type FundTransactionsResponse struct {
+	Status string               `json:"status"` // API response status (e.g., "success" or "error").
+	Data   []FundTransactionRow `json:"data"`   // Transactions, latest first.
+}
+
+// FundTransaction is a typed payin or payout.
+type FundTransaction struct {
+	ID          string                `json:"id"`          // Identifier of the transaction.
+	Type        FundTransactionType   `json:"type"`        // Payin or payout.
+	Amount      float64               `json:"amount"`      // Amount in rupees.
+	Status      FundTransactionStatus `json:"status"`      // State of the transaction.
+	Mode        PaymentMode           `json:"mode"`        // Payment channel of payins; empty for payouts.
+	BankAccount string                `json:"bankAccount"` // Masked bank account number.
+	Segment     string                `json:"segment"`     // Segment credited or debited.
+	Reference   string                `json:"reference"`   // Bank reference (UTR) once processed.
+	Remarks     string                `json:"remarks"`     // Reason of failures and cancellations.
+	Created     time.Time             `json:"created"`     // Time the transaction was initiated.
+	Updated     time.Time             `json:"updated"`     // Time of the last status change.
+}
+
+// Settled reports whether the transaction reached a final status.
+func (t FundTransaction) Settled() bool {
+	return t.Status != FundPending
+}
+
+// GetFundTransactions retrieves the payins and payouts initiated in a date range.
+//
+// It sends a GET request to the "/funds/transactions" endpoint. Unlike
+// GetAccountStatement, which lists what was posted to the ledger, it includes pending,
+// failed and cancelled transactions.
+//
+// Parameters:
+//   - from: The first day of the range.
+//   - to: The last day of the range.
+//
+// Returns:
+//   - The transactions, latest first, if successful.
+//   - An error if the request fails or the response cannot be parsed.
+func (c *Client) GetFundTransactions(from, to time.Time) ([]FundTransaction, error) {
+	endpoint := c.endpoint(EndpointFundTransactions, from.In(IST).Format("2006-01-02"), to.In(IST).Format("2006-01-02"))
+
+	resp, err := c.request(endpoint, "GET", nil)
+	if err != nil {
+		c.log().Error().Err(err).Msg("Failed to fetch fund transactions")
+		return nil, err
+	}
+
+	var result FundTransactionsResponse
+	if err := c.decode(EndpointFundTransactions, resp, &result); err != nil {
+		c.log().Error().Err(err).Msg("Failed to parse fund transactions response")
+		return nil, err
+	}
+
+	if result.Status != "success" {
+		return nil, newAPIError("fund transactions retrieval", endpoint, 0, resp)
+	}
+
+	transactions := make([]FundTransaction, len(result.Data))
+	for i, row := range result.Data {
+		created, _ := parseTimestamp(row.CreatedAt)
+		updated, _ := parseTimestamp(row.UpdatedAt)
+		transactions[i] = FundTransaction{
+			ID:          row.TransactionID,
+			Type:        FundTransactionType(strings.ToUpper(row.Type)),
+			Amount:      parseFloat(row.Amount),
+			Status:      FundTransactionStatus(strings.ToUpper(row.Status)),
+			Mode:        PaymentMode(strings.ToUpper(row.Mode)),
+			BankAccount: row.BankAccount,
+			Segment:     row.Segment,
+			Reference:   row.Reference,
+			Remarks:     row.Remarks,
+			Created:     created,
+			Updated:     updated,
+		}
+	}
+
+	c.log().Info().Int("transactions", len(transactions)).Msg("Fund transactions retrieved successfully")
+	return transactions, nil
+}
@@ -0,0 +1,113 @@
+package tiqs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GapFillMode selects how missing bars are synthesized by FillGaps.
+type GapFillMode int
+
+const (
+	// GapFillFlat inserts bars whose OHLC all equal the previous close, with zero volume.
+	GapFillFlat GapFillMode = iota
+	// GapFillForward inserts copies of the previous bar's OHLC, with zero volume.
+	GapFillForward
+	// GapFillMarker inserts zero-valued bars, the integer equivalent of NaN, to be skipped by indicators.
+	GapFillMarker
+)
+
+// FillGaps inserts bars for missing intervals in a candle series.
+//
+// Candles must be sorted by time. For intraday intervals only timestamps within the
+// market session of the clock are filled, so overnight and weekend gaps are preserved;
+// for daily intervals weekends are skipped. Inserted candles have Filled set to true.
+//
+// Parameters:
+//   - candles: The candle series, sorted by time (e.g., from GetHistoricalData).
+//   - interval: The candle interval as passed to GetHistoricalData (e.g., "1m", "5m", "1d").
+//   - mode: How missing bars are synthesized.
+//   - clock: The market clock used for intraday intervals; nil fills every gap.
+//
+// Returns:
+//   - A new candle series without gaps.
+//   - An error if the interval or a candle timestamp cannot be parsed.
+func FillGaps(candles []HistoricalCandle, interval string, mode GapFillMode, clock *MarketClock) ([]HistoricalCandle, error) {
+	step, err := parseCandleInterval(interval)
+	if err != nil {
+		return nil, err
+	}
+	daily := step >= 24*time.Hour
+
+	filled := make([]HistoricalCandle, 0, len(candles))
+	var prevTime time.Time
+	for i, candle := range candles {
+		t, ok := parseTimestamp(candle.Time)
+		if !ok {
+			return nil, fmt.Errorf("invalid candle time at index %d: %q", i, candle.Time)
+		}
+
+		if i > 0 {
+			prev := filled[len(filled)-1]
+			for gap := prevTime.Add(step); gap.Before(t); gap = gap.Add(step) {
+				if daily && isWeekend(gap) {
+					continue
+				}
+				if !daily && clock != nil && !clock.IsOpen(gap) {
+					continue
+				}
+				bar := syntheticCandle(prev, gap, mode)
+				filled = append(filled, bar)
+				prev = bar
+			}
+		}
+
+		filled = append(filled, candle)
+		prevTime = t
+	}
+	return filled, nil
+}
+
+// syntheticCandle builds a gap bar at t from the previous bar.
+func syntheticCandle(prev HistoricalCandle, t time.Time, mode GapFillMode) HistoricalCandle {
+	bar := HistoricalCandle{Time: t.Format(time.RFC3339), Filled: true}
+
+	switch mode {
+	case GapFillFlat:
+		bar.Open, bar.High, bar.Low, bar.Close = prev.Close, prev.Close, prev.Close, prev.Close
+	case GapFillForward:
+		bar.Open, bar.High, bar.Low, bar.Close = prev.Open, prev.High, prev.Low, prev.Close
+	}
+
+	if prev.OI != nil && mode != GapFillMarker {
+		oi := *prev.OI
+		bar.OI = &oi
+	}
+	return bar
+}
+
+// parseCandleInterval converts a candle interval such as "1m", "15m", "1h" or "1d" into a duration.
+func parseCandleInterval(interval string) (time.Duration, error) {
+	s := strings.ToLower(strings.TrimSpace(interval))
+	if strings.HasSuffix(s, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(s, "d"))
+		if err != nil || days <= 0 {
+			return 0, fmt.Errorf("invalid candle interval: %q", interval)
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+
+	step, err := time.ParseDuration(s)
+	if err != nil || step <= 0 {
+		return 0, fmt.Errorf("invalid candle interval: %q", interval)
+	}
+	return step, nil
+}
+
+// isWeekend reports whether t falls on a Saturday or Sunday in IST.
+func isWeekend(t time.Time) bool {
+	day := t.In(IST).Weekday()
+	return day == time.Saturday || day == time.Sunday
+}
@@ -0,0 +1,106 @@
+package tiqs
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Abhi13027/go-tiqs/ticks"
+)
+
+// defaultRiskFreeRate is the annual risk-free rate used for computed Greeks.
+const defaultRiskFreeRate = 0.065
+
+// GreeksCalculator fills in Greeks for option ticks that do not carry them.
+//
+// Ticks whose packets include Greeks (see ticks.RegisterGreeksLayout) are passed through
+// unchanged. For other option ticks, the implied volatility is solved from the option's
+// LTP and the latest LTP of its underlying, and the Greeks are computed with
+// Black-Scholes, so consumers see Greeks the same way regardless of their source. The
+// underlying must be subscribed on the same feed for its LTP to be known.
+type GreeksCalculator struct {
+	Rate float64 // Annual risk-free rate as a fraction.
+
+	store *InstrumentStore
+	mu    sync.RWMutex
+	spots map[int64]float64
+}
+
+// NewGreeksCalculator creates a calculator using the instrument metadata of store.
+//
+// Parameters:
+//   - store: The instrument store providing strikes, expiries and underlyings.
+//
+// Returns:
+//   - A pointer to a newly created GreeksCalculator.
+func NewGreeksCalculator(store *InstrumentStore) *GreeksCalculator {
+	return &GreeksCalculator{
+		Rate:  defaultRiskFreeRate,
+		store: store,
+		spots: make(map[int64]float64),
+	}
+}
+
+// Update records the LTP of a tick so it can serve as the underlying price of options.
+func (g *GreeksCalculator) Update(tick ticks.TickData) {
+	if tick.Token < 0 || tick.LTP <= 0 {
+		return
+	}
+
+	token := int64(tick.Token)
+	g.mu.Lock()
+	g.spots[token] = float64(tick.LTP) / g.divisor(token)
+	g.mu.Unlock()
+}
+
+// Enrich returns the tick with Greeks filled in, computing them if the packet had none.
+// Ticks of non-options, or of options whose underlying price is not known yet, are
+// returned unchanged.
+func (g *GreeksCalculator) Enrich(tick ticks.TickData) ticks.TickData {
+	g.Update(tick)
+	if tick.Greeks != nil || tick.Token < 0 || tick.LTP <= 0 {
+		return tick
+	}
+
+	inst, ok := g.store.Get(int64(tick.Token))
+	if !ok || !inst.IsOption() || inst.UnderlyingToken == nil {
+		return tick
+	}
+
+	g.mu.RLock()
+	spot, ok := g.spots[parseInt(*inst.UnderlyingToken)]
+	g.mu.RUnlock()
+	if !ok {
+		return tick
+	}
+
+	expiry, ok := inst.Expiry()
+	if !ok {
+		return tick
+	}
+	years := yearsToExpiry(expiry)
+
+	call := *inst.OptionType == "CE"
+	price := float64(tick.LTP) / inst.PriceDivisor()
+	iv, ok := ticks.ImpliedVolatility(call, price, spot, inst.Strike(), years, g.Rate)
+	if !ok {
+		return tick
+	}
+
+	greeks := ticks.BlackScholesGreeks(call, spot, inst.Strike(), years, g.Rate, iv)
+	tick.Greeks = &greeks
+	return tick
+}
+
+// divisor returns the price divisor of a token.
+func (g *GreeksCalculator) divisor(token int64) float64 {
+	if inst, ok := g.store.Get(token); ok {
+		return inst.PriceDivisor()
+	}
+	return Instrument{}.PriceDivisor()
+}
+
+// yearsToExpiry returns the time left until the close of an expiry day, in years.
+func yearsToExpiry(expiry time.Time) float64 {
+	y, m, d := expiry.In(IST).Date()
+	return time.Until(time.Date(y, m, d, 15, 30, 0, 0, IST)).Hours() / (24 * 365)
+}
@@ -0,0 +1,316 @@
+package tiqs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Abhi13027/go-tiqs/ticks"
+)
+
+// GTTCondition describes when a simulated GTT triggers.
+type GTTCondition string
+
+const (
+	GTTAbove GTTCondition = "LTP_ABOVE" // Triggers when the LTP rises to or above the trigger price.
+	GTTBelow GTTCondition = "LTP_BELOW" // Triggers when the LTP falls to or below the trigger price.
+)
+
+// GTTStatus represents the lifecycle state of a simulated GTT.
+type GTTStatus string
+
+const (
+	GTTActive    GTTStatus = "ACTIVE"    // Waiting for the trigger condition.
+	GTTTriggered GTTStatus = "TRIGGERED" // Condition hit and the order was placed.
+	GTTFailed    GTTStatus = "FAILED"    // Condition hit but the order could not be placed.
+	GTTCancelled GTTStatus = "CANCELLED" // Cancelled before triggering.
+)
+
+// GTT represents a client-side good-till-triggered order.
+type GTT struct {
+	ID           string       `json:"id"`                    // Identifier assigned by the engine.
+	Token        int64        `json:"token"`                 // Instrument whose LTP is monitored.
+	Condition    GTTCondition `json:"condition"`             // Trigger condition.
+	TriggerPrice float64      `json:"triggerPrice"`          // Trigger price in rupees.
+	Variety      string       `json:"variety"`               // Order variety passed to PlaceOrder (e.g., "regular").
+	Order        OrderRequest `json:"order"`                 // Order placed when the condition hits.
+	Status       GTTStatus    `json:"status"`                // Current state.
+	CreatedAt    time.Time    `json:"createdAt"`             // Time the GTT was added.
+	TriggeredAt  time.Time    `json:"triggeredAt,omitempty"` // Time the condition hit.
+	OrderNo      string       `json:"orderNo,omitempty"`     // Order number of the placed order.
+	Error        string       `json:"error,omitempty"`       // Reason the order could not be placed.
+}
+
+// GTTEngine simulates GTT orders on the client for accounts where native GTT is unavailable.
+//
+// The engine watches LTPs from websocket ticks and places the stored order once the
+// trigger condition hits. GTTs are persisted to Path on every change so they survive
+// restarts. As safety checks, GTTs only trigger while Clock reports the market open,
+// orders go through PlaceOrder and therefore through any StaleGuard, HealthMonitor or
+// DuplicateGuard attached to the client, and a GTT whose order was in flight when the
+// process stopped is marked failed on restart rather than placed a second time.
+type GTTEngine struct {
+	Path  string       // File the GTTs are persisted to; empty disables persistence.
+	Clock *MarketClock // Market clock; GTTs never trigger while the market is closed.
+
+	client *Client
+	mu     sync.Mutex
+	gtts   map[string]*GTT
+	nextID int
+}
+
+// NewGTTEngine creates a GTT engine and recovers the GTTs persisted at path.
+//
+// Parameters:
+//   - client: The client used to place triggered orders.
+//   - path: File the GTTs are persisted to, or "" to keep them in memory only.
+//
+// Returns:
+//   - A pointer to a newly created GTTEngine.
+//   - An error if the persisted GTTs cannot be read.
+func NewGTTEngine(client *Client, path string) (*GTTEngine, error) {
+	engine := &GTTEngine{
+		Path:   path,
+		Clock:  NewMarketClock(),
+		client: client,
+		gtts:   make(map[string]*GTT),
+	}
+	if path == "" {
+		return engine, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return engine, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read GTTs: %w", err)
+	}
+
+	var gtts []*GTT
+	if err := json.Unmarshal(data, &gtts); err != nil {
+		return nil, fmt.Errorf("failed to parse GTTs: %w", err)
+	}
+
+	recovered := 0
+	for _, gtt := range gtts {
+		if gtt.Status == GTTTriggered && gtt.OrderNo == "" {
+			gtt.Status = GTTFailed
+			gtt.Error = "interrupted while placing the order; check the order book"
+			client.log().Warn().Str("id", gtt.ID).Msg("GTT was interrupted during order placement")
+		}
+		if gtt.Status == GTTActive {
+			recovered++
+		}
+		engine.gtts[gtt.ID] = gtt
+		if n, err := strconv.Atoi(gtt.ID); err == nil && n > engine.nextID {
+			engine.nextID = n
+		}
+	}
+
+	client.log().Info().Int("active", recovered).Msg("GTTs recovered successfully")
+	return engine, engine.saveLocked()
+}
+
+// Add registers a new GTT.
+//
+// Parameters:
+//   - token: The instrument whose LTP is monitored.
+//   - condition: The trigger condition.
+//   - triggerPrice: The trigger price in rupees.
+//   - variety: The order variety passed to PlaceOrder (e.g., "regular").
+//   - order: The order to place once the condition hits.
+//
+// Returns:
+//   - The identifier of the new GTT.
+//   - An error if the GTT is invalid or cannot be persisted.
+func (e *GTTEngine) Add(token int64, condition GTTCondition, triggerPrice float64, variety string, order OrderRequest) (string, error) {
+	if condition != GTTAbove && condition != GTTBelow {
+		return "", fmt.Errorf("unknown GTT condition: %q", condition)
+	}
+	if triggerPrice <= 0 {
+		return "", fmt.Errorf("GTT trigger price must be positive")
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.nextID++
+	gtt := &GTT{
+		ID:           strconv.Itoa(e.nextID),
+		Token:        token,
+		Condition:    condition,
+		TriggerPrice: triggerPrice,
+		Variety:      variety,
+		Order:        order,
+		Status:       GTTActive,
+		CreatedAt:    time.Now(),
+	}
+	e.gtts[gtt.ID] = gtt
+
+	if err := e.saveLocked(); err != nil {
+		delete(e.gtts, gtt.ID)
+		return "", err
+	}
+
+	e.client.log().Info().Str("id", gtt.ID).Int64("token", token).Float64("trigger", triggerPrice).Msg("GTT added")
+	return gtt.ID, nil
+}
+
+// Cancel cancels an active GTT.
+//
+// Returns:
+//   - An error if the GTT does not exist, is no longer active or cannot be persisted.
+func (e *GTTEngine) Cancel(id string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	gtt, ok := e.gtts[id]
+	if !ok {
+		return fmt.Errorf("GTT %s not found", id)
+	}
+	if gtt.Status != GTTActive {
+		return fmt.Errorf("GTT %s is %s", id, gtt.Status)
+	}
+
+	gtt.Status = GTTCancelled
+	return e.saveLocked()
+}
+
+// List returns all GTTs ordered by identifier.
+func (e *GTTEngine) List() []GTT {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	list := make([]GTT, 0, len(e.gtts))
+	for _, gtt := range e.gtts {
+		list = append(list, *gtt)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		a, _ := strconv.Atoi(list[i].ID)
+		b, _ := strconv.Atoi(list[j].ID)
+		return a < b
+	})
+	return list
+}
+
+// Tokens returns the tokens monitored by active GTTs, for subscribing on the websocket.
+func (e *GTTEngine) Tokens() []int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	seen := make(map[int64]bool)
+	var tokens []int
+	for _, gtt := range e.gtts {
+		if gtt.Status == GTTActive && !seen[gtt.Token] {
+			seen[gtt.Token] = true
+			tokens = append(tokens, int(gtt.Token))
+		}
+	}
+	sort.Ints(tokens)
+	return tokens
+}
+
+// Update evaluates the active GTTs of the tick's token and places the orders of those
+// whose condition hit.
+func (e *GTTEngine) Update(tick ticks.TickData) {
+	if tick.Token < 0 || tick.LTP <= 0 {
+		return
+	}
+	if e.Clock != nil && !e.Clock.IsOpen(time.Now()) {
+		return
+	}
+
+	token := int64(tick.Token)
+	ltp := float64(tick.LTP) / e.client.priceDivisor(token)
+
+	e.mu.Lock()
+	var hit []*GTT
+	for _, gtt := range e.gtts {
+		if gtt.Status == GTTActive && gtt.Token == token && gtt.triggered(ltp) {
+			gtt.Status = GTTTriggered
+			gtt.TriggeredAt = time.Now()
+			hit = append(hit, gtt)
+		}
+	}
+	if len(hit) > 0 {
+		// Persist before placing so that a crash cannot place the same order twice.
+		if err := e.saveLocked(); err != nil {
+			e.client.log().Error().Err(err).Msg("Failed to persist triggered GTTs")
+		}
+	}
+	e.mu.Unlock()
+
+	for _, gtt := range hit {
+		e.place(gtt, ltp)
+	}
+}
+
+// Run feeds ticks from source into Update until the context is cancelled or source closes.
+func (e *GTTEngine) Run(ctx context.Context, source <-chan ticks.TickData) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case tick, ok := <-source:
+			if !ok {
+				return nil
+			}
+			e.Update(tick)
+		}
+	}
+}
+
+// place places the order of a triggered GTT and records the outcome.
+func (e *GTTEngine) place(gtt *GTT, ltp float64) {
+	e.client.log().Info().Str("id", gtt.ID).Float64("ltp", ltp).Msg("GTT triggered")
+	resp, err := e.client.PlaceOrder(gtt.Variety, gtt.Order)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err != nil {
+		e.client.log().Error().Err(err).Str("id", gtt.ID).Msg("Failed to place GTT order")
+		gtt.Status = GTTFailed
+		gtt.Error = err.Error()
+	} else {
+		gtt.OrderNo = resp.Data.OrderNo
+	}
+	if err := e.saveLocked(); err != nil {
+		e.client.log().Error().Err(err).Msg("Failed to persist GTTs")
+	}
+}
+
+// saveLocked persists the GTTs to Path. The caller must hold e.mu.
+func (e *GTTEngine) saveLocked() error {
+	if e.Path == "" {
+		return nil
+	}
+
+	list := make([]*GTT, 0, len(e.gtts))
+	for _, gtt := range e.gtts {
+		list = append(list, gtt)
+	}
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize GTTs: %w", err)
+	}
+	if err := writeFileAtomic(e.Path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write GTTs: %w", err)
+	}
+	return nil
+}
+
+// triggered reports whether the condition of the GTT holds at the given LTP.
+func (g *GTT) triggered(ltp float64) bool {
+	if g.Condition == GTTAbove {
+		return ltp >= g.TriggerPrice
+	}
+	return ltp <= g.TriggerPrice
+}
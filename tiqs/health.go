@@ -0,0 +1,196 @@
+package tiqs
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Abhi13027/go-tiqs/ticks"
+)
+
+// HealthState represents the health of the broker connection as seen by the client.
+type HealthState string
+
+const (
+	HealthHealthy  HealthState = "HEALTHY"  // Requests and streams are succeeding.
+	HealthDegraded HealthState = "DEGRADED" // Repeated failures were detected; avoid new entries.
+)
+
+// HealthSource identifies the channel on which a failure or success was observed.
+type HealthSource string
+
+const (
+	HealthSourceREST      HealthSource = "REST"      // REST API requests.
+	HealthSourceWebSocket HealthSource = "WEBSOCKET" // Market data websocket.
+)
+
+// HealthEvent is broadcast to subscribers whenever the health state changes.
+type HealthEvent struct {
+	State     HealthState  `json:"state"`     // The new health state.
+	Source    HealthSource `json:"source"`    // Source of the observation that caused the change.
+	Failures  int          `json:"failures"`  // Failures observed within the window at the time of the change.
+	LastError string       `json:"lastError"` // Last error observed, if any.
+	Time      time.Time    `json:"time"`      // Time of the change.
+}
+
+// HealthMonitor detects broker degradation from repeated REST failures and websocket
+// disconnects and broadcasts state changes to its subscribers.
+//
+// The broker is considered degraded once FailureThreshold failures are observed within
+// Window, and healthy again after RecoveryThreshold consecutive successes. Strategy
+// runners subscribe to pause new entries (and, if they choose, widen their stops) while
+// the broker is degraded. When BlockOrders is enabled, a client with the monitor attached
+// refuses new orders while degraded.
+type HealthMonitor struct {
+	FailureThreshold  int           // Failures within Window that mark the broker degraded.
+	RecoveryThreshold int           // Consecutive successes that mark the broker healthy again.
+	Window            time.Duration // Sliding window over which failures are counted.
+	BlockOrders       bool          // Whether PlaceOrder should be refused while degraded.
+
+	mu          sync.Mutex
+	state       HealthState
+	failures    []time.Time
+	successes   int
+	lastErr     error
+	subscribers []chan HealthEvent
+}
+
+// NewHealthMonitor creates a monitor that degrades after failureThreshold failures within window.
+//
+// Parameters:
+//   - failureThreshold: Failures within window that mark the broker degraded.
+//   - window: Sliding window over which failures are counted.
+//
+// Returns:
+//   - A pointer to a newly created HealthMonitor in the healthy state.
+func NewHealthMonitor(failureThreshold int, window time.Duration) *HealthMonitor {
+	return &HealthMonitor{
+		FailureThreshold:  failureThreshold,
+		RecoveryThreshold: 3,
+		Window:            window,
+		state:             HealthHealthy,
+	}
+}
+
+// Subscribe returns a channel receiving every subsequent health state change.
+//
+// Events are delivered without blocking the monitor; a subscriber that does not keep up
+// misses intermediate events but can always query State.
+func (m *HealthMonitor) Subscribe() <-chan HealthEvent {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ch := make(chan HealthEvent, 16)
+	m.subscribers = append(m.subscribers, ch)
+	return ch
+}
+
+// State returns the current health state.
+func (m *HealthMonitor) State() HealthState {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.state
+}
+
+// Degraded reports whether the broker is currently considered degraded.
+func (m *HealthMonitor) Degraded() bool {
+	return m.State() == HealthDegraded
+}
+
+// RecordFailure registers a failure observed on the given source.
+func (m *HealthMonitor) RecordFailure(source HealthSource, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	m.failures = append(m.failures, now)
+	m.pruneLocked(now)
+	m.successes = 0
+	m.lastErr = err
+
+	if m.state == HealthHealthy && len(m.failures) >= m.FailureThreshold {
+		m.transitionLocked(HealthDegraded, source, now)
+	}
+}
+
+// RecordSuccess registers a successful request or connection on the given source.
+func (m *HealthMonitor) RecordSuccess(source HealthSource) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	m.pruneLocked(now)
+	m.successes++
+
+	if m.state == HealthDegraded && m.successes >= m.RecoveryThreshold {
+		m.failures = nil
+		m.transitionLocked(HealthHealthy, source, now)
+	}
+}
+
+// WatchWS hooks the monitor into a websocket so that disconnects count as failures and
+// successful connections as successes. Existing connection hooks are preserved.
+//
+// Parameters:
+//   - ws: The websocket client to watch.
+func (m *HealthMonitor) WatchWS(ws *ticks.WS) {
+	onConnect, onDisconnect := ws.OnConnect, ws.OnDisconnect
+
+	ws.OnConnect = func() {
+		m.RecordSuccess(HealthSourceWebSocket)
+		if onConnect != nil {
+			onConnect()
+		}
+	}
+	ws.OnDisconnect = func(err error) {
+		m.RecordFailure(HealthSourceWebSocket, err)
+		if onDisconnect != nil {
+			onDisconnect(err)
+		}
+	}
+}
+
+// check returns an error if new orders must be refused because the broker is degraded.
+func (m *HealthMonitor) check() error {
+	if m.BlockOrders && m.Degraded() {
+		defaultLog().Warn().Msg("Order blocked while broker is degraded")
+		return fmt.Errorf("order blocked: broker connection is degraded")
+	}
+	return nil
+}
+
+// pruneLocked drops failures that fell out of the window. The caller must hold m.mu.
+func (m *HealthMonitor) pruneLocked(now time.Time) {
+	cutoff := now.Add(-m.Window)
+	kept := m.failures[:0]
+	for _, t := range m.failures {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	m.failures = kept
+}
+
+// transitionLocked changes the state and notifies subscribers. The caller must hold m.mu.
+func (m *HealthMonitor) transitionLocked(state HealthState, source HealthSource, now time.Time) {
+	m.state = state
+
+	event := HealthEvent{
+		State:    state,
+		Source:   source,
+		Failures: len(m.failures),
+		Time:     now,
+	}
+	if m.lastErr != nil {
+		event.LastError = m.lastErr.Error()
+	}
+
+	defaultLog().Warn().Str("state", string(state)).Str("source", string(source)).Msg("Broker health changed")
+	for _, ch := range m.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
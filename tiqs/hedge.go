@@ -0,0 +1,185 @@
+package tiqs
+
+import (
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultHedgedEndpoints are the reads hedged by a HedgePolicy without Endpoints.
+var DefaultHedgedEndpoints = []EndpointName{EndpointQuote, EndpointQuotes, EndpointOrderHistory}
+
+// hedgeableEndpoints lists the idempotent reads that may be sent twice, with the method
+// they are requested with.
+var hedgeableEndpoints = map[EndpointName]string{
+	EndpointQuote:        "POST",
+	EndpointQuotes:       "POST",
+	EndpointOptionChain:  "POST",
+	EndpointOrderHistory: "GET",
+	EndpointOrderBook:    "GET",
+	EndpointTrades:       "GET",
+	EndpointPositions:    "GET",
+	EndpointLimits:       "GET",
+	EndpointHoldings:     "GET",
+}
+
+// HedgePolicy sends a second attempt of a slow read once the first exceeds its latency
+// budget, and takes whichever response arrives first. This trims the tail latency that
+// stalls strategy loops waiting on a quote or an order status.
+//
+// Only idempotent reads are hedged; other endpoints listed in Endpoints are ignored. The
+// second attempt is sent only if the budget of the request's rate class (see
+// SetRateLimits) allows it without waiting, so hedging never pushes the client into the
+// broker's rate limit.
+type HedgePolicy struct {
+	Budget    time.Duration                  // Latency after which a read is hedged.
+	Budgets   map[EndpointName]time.Duration // Per-endpoint budgets overriding Budget.
+	Endpoints []EndpointName                 // Endpoints to hedge; DefaultHedgedEndpoints if empty.
+}
+
+// HedgeStats counts the hedged requests of a client since hedging was enabled.
+type HedgeStats struct {
+	Requests  int64 `json:"requests"`  // Requests eligible for hedging.
+	Hedged    int64 `json:"hedged"`    // Requests that exceeded their budget and were sent twice.
+	Skipped   int64 `json:"skipped"`   // Requests that exceeded their budget but were not hedged for lack of rate budget.
+	HedgeWins int64 `json:"hedgeWins"` // Hedged requests answered first by the second attempt.
+}
+
+// hedger is a HedgePolicy attached to a client, with its counters.
+type hedger struct {
+	policy    HedgePolicy
+	requests  atomic.Int64
+	hedged    atomic.Int64
+	skipped   atomic.Int64
+	hedgeWins atomic.Int64
+}
+
+// SetHedging enables hedged requests for slow reads.
+//
+// Parameters:
+//   - policy: The budgets and endpoints to hedge, or nil to disable hedging.
+func (c *Client) SetHedging(policy *HedgePolicy) {
+	if policy == nil {
+		c.hedging = nil
+		return
+	}
+	c.hedging = &hedger{policy: *policy}
+}
+
+// HedgeStats returns the counters of hedged requests, or zero counters if hedging is
+// disabled.
+func (c *Client) HedgeStats() HedgeStats {
+	h := c.hedging
+	if h == nil {
+		return HedgeStats{}
+	}
+	return HedgeStats{
+		Requests:  h.requests.Load(),
+		Hedged:    h.hedged.Load(),
+		Skipped:   h.skipped.Load(),
+		HedgeWins: h.hedgeWins.Load(),
+	}
+}
+
+// hedgeBudget returns the latency budget of a request, and false if it is not hedged.
+func (c *Client) hedgeBudget(method, endpoint string) (time.Duration, bool) {
+	h := c.hedging
+	if h == nil {
+		return 0, false
+	}
+	endpoints := h.policy.Endpoints
+	if len(endpoints) == 0 {
+		endpoints = DefaultHedgedEndpoints
+	}
+	for _, name := range endpoints {
+		if hedgeableEndpoints[name] != method || !c.matchesEndpoint(name, endpoint) {
+			continue
+		}
+		budget, ok := h.policy.Budgets[name]
+		if !ok {
+			budget = h.policy.Budget
+		}
+		return budget, budget > 0
+	}
+	return 0, false
+}
+
+// matchesEndpoint reports whether a request path belongs to an endpoint. Endpoints without
+// arguments must match exactly, so that, e.g., "/user/orders" does not match a longer path.
+func (c *Client) matchesEndpoint(name EndpointName, endpoint string) bool {
+	prefix := c.endpointPrefix(name)
+	if prefix == c.endpoint(name) {
+		return endpoint == prefix
+	}
+	return strings.HasPrefix(endpoint, prefix)
+}
+
+// hedgedExchange sends a read and, if no response arrived within the budget, a second
+// attempt, passing the first successful response to consume. It fails only once every
+// attempt failed, with the error of the first attempt.
+func (c *Client) hedgedExchange(endpoint, method string, payload []byte, budget time.Duration, consume func([]byte) error) error {
+	h := c.hedging
+	h.requests.Add(1)
+
+	if err := c.waitRate(method, endpoint); err != nil {
+		return err
+	}
+
+	type result struct {
+		body  []byte
+		err   error
+		hedge bool
+	}
+	// Buffered for both attempts, so the losing attempt never blocks.
+	results := make(chan result, 2)
+	attempt := func(hedge bool) {
+		var body []byte
+		err := c.send(endpoint, method, payload, func(b []byte) error {
+			// The response buffer is pooled, so keep a copy of the body.
+			body = append([]byte(nil), b...)
+			return nil
+		})
+		results <- result{body: body, err: err, hedge: hedge}
+	}
+
+	go attempt(false)
+	timer := time.NewTimer(budget)
+	defer timer.Stop()
+
+	pending := 1
+	var firstErr error
+	for {
+		select {
+		case <-timer.C:
+			if !c.allowHedge(method, endpoint) {
+				h.skipped.Add(1)
+				c.log().Debug().Str("endpoint", endpoint).Dur("budget", budget).Msg("Latency budget exceeded; no rate budget to hedge")
+				continue
+			}
+			h.hedged.Add(1)
+			c.log().Debug().Str("endpoint", endpoint).Dur("budget", budget).Msg("Latency budget exceeded; hedging request")
+			pending++
+			go attempt(true)
+
+		case r := <-results:
+			pending--
+			if r.err == nil {
+				if r.hedge {
+					h.hedgeWins.Add(1)
+				}
+				return consume(r.body)
+			}
+			if firstErr == nil || !r.hedge {
+				firstErr = r.err
+			}
+			if pending == 0 {
+				return firstErr
+			}
+		}
+	}
+}
+
+// allowHedge takes the rate budget of a second attempt if it is available without waiting.
+func (c *Client) allowHedge(method, endpoint string) bool {
+	return c.allowRate(method, endpoint)
+}
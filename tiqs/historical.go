@@ -4,32 +4,54 @@ package tiqs
 import (
 	"encoding/json"
 	"fmt"
-
-	"github.com/rs/zerolog/log"
+	"io"
+	"strconv"
+	"time"
 )
 
 // HistoricalCandle represents a single OHLCV (Open, High, Low, Close, Volume) data point.
 type HistoricalCandle struct {
-	Time   string `json:"time"`         // Timestamp of the candle in ISO 8601 format.
-	Open   int64  `json:"open"`         // Open price of the candle.
-	High   int64  `json:"high"`         // Highest price during the candle period.
-	Low    int64  `json:"low"`          // Lowest price during the candle period.
-	Close  int64  `json:"close"`        // Closing price of the candle.
-	Volume int64  `json:"volume"`       // Trading volume during the candle period.
-	OI     *int64 `json:"oi,omitempty"` // Open Interest (optional, included if requested).
+	Time    string `json:"time"`              // Timestamp of the candle in ISO 8601 format.
+	Open    int64  `json:"open"`              // Open price of the candle.
+	High    int64  `json:"high"`              // Highest price during the candle period.
+	Low     int64  `json:"low"`               // Lowest price during the candle period.
+	Close   int64  `json:"close"`             // Closing price of the candle.
+	Volume  int64  `json:"volume"`            // Trading volume during the candle period.
+	OI      *int64 `json:"oi,omitempty"`      // Open Interest (optional, included if requested).
+	Filled  bool   `json:"filled,omitempty"`  // Set on bars synthesized by FillGaps.
+	PreOpen bool   `json:"preOpen,omitempty"` // Set on bars from the pre-open session.
+}
+
+// ParsedTime returns the start of the candle in IST.
+//
+// Returns:
+//   - The time of the candle.
+//   - An error if the timestamp is not in a format returned by the API.
+func (h HistoricalCandle) ParsedTime() (time.Time, error) {
+	t, ok := parseTimestamp(h.Time)
+	if !ok {
+		return time.Time{}, fmt.Errorf("invalid candle time: %q", h.Time)
+	}
+	return t, nil
 }
 
 // HistoricalDataResponse represents the structure of the historical data API response.
 type HistoricalDataResponse struct {
-	Status string             `json:"status"` // API response status (e.g., "success" or "error").
-	Data   []HistoricalCandle `json:"data"`   // List of historical OHLCV candles.
+	Status  string             `json:"status"`            // API response status (e.g., "success" or "error").
+	Message string             `json:"message,omitempty"` // Error message, if the request failed.
+	Data    []HistoricalCandle `json:"data"`              // List of historical OHLCV candles.
 }
 
 // GetHistoricalData fetches historical OHLCV data for a given instrument.
 //
 // It sends a GET request to the "/candle/{exchange}/{token}/{interval}?from={from}&to={to}" endpoint
 // to retrieve OHLCV data for the specified time range. If Open Interest (OI) is requested, it is appended
-// as a query parameter.
+// as a query parameter. The response is streamed, subject to Config.MaxResponseSize.
+//
+// Candles from the pre-open session are flagged with PreOpen, and dropped altogether if
+// the client was configured with SetIncludePreOpen(false). With SetHistoricalChunking
+// enabled, ranges longer than the API serves in one call are fetched in chunks. Ranges too
+// large to hold in memory are streamed with GetHistoricalDataIter.
 //
 // Parameters:
 //   - exchange: The exchange where the instrument is listed (e.g., NSE, BSE).
@@ -43,38 +65,90 @@ type HistoricalDataResponse struct {
 //   - A slice of HistoricalCandle structs containing OHLCV data if successful.
 //   - An error if the request fails or the response cannot be parsed.
 func (c *Client) GetHistoricalData(exchange, token, interval, from, to string, includeOI bool) ([]HistoricalCandle, error) {
-	endpoint := fmt.Sprintf("/candle/%s/%s/%s?from=%s&to=%s", exchange, token, interval, from, to)
+	var (
+		candles []HistoricalCandle
+		err     error
+	)
+	if c.chunkHistory {
+		candles, err = c.fetchHistoricalChunked(exchange, token, interval, from, to, includeOI)
+	} else {
+		candles, err = c.fetchHistorical(exchange, token, interval, from, to, includeOI)
+	}
+	if err != nil {
+		return nil, err
+	}
 
-	// If Open Interest (OI) is requested, append it as a query parameter.
-	if includeOI {
-		endpoint += "&oi=1"
+	candles = MarkPreOpen(candles, NewMarketClock())
+	if c.excludePreOpen {
+		candles = ExcludePreOpen(candles)
 	}
 
-	// Send a GET request to the API to fetch historical data.
-	resp, err := c.request(endpoint, "GET", nil)
+	c.log().Info().
+		Str("exchange", exchange).
+		Str("token", token).
+		Str("interval", interval).
+		Bool("includeOI", includeOI).
+		Msg("Historical data retrieved successfully")
+
+	return candles, nil
+}
+
+// GetHistoricalCandles fetches historical OHLCV data as GetHistoricalData does, with
+// times parsed and prices converted into rupees at the precision of the instrument
+// (see PriceConverter), ready for indicator math.
+//
+// Parameters:
+//   - exchange: The exchange where the instrument is listed (e.g., NSE, BSE).
+//   - token: The unique identifier of the instrument.
+//   - interval: The timeframe of the candles (e.g., "1m", "5m", "1d").
+//   - from: The start date/time for historical data (ISO 8601 format).
+//   - to: The end date/time for historical data (ISO 8601 format).
+//   - includeOI: Boolean flag to include Open Interest (OI) data if available.
+//
+// Returns:
+//   - A slice of DecimalCandle structs if successful.
+//   - An error if the token is invalid, the request fails or a candle time cannot be
+//     parsed.
+func (c *Client) GetHistoricalCandles(exchange, token, interval, from, to string, includeOI bool) ([]DecimalCandle, error) {
+	id, err := strconv.ParseInt(token, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid token %q: %w", token, err)
+	}
+
+	candles, err := c.GetHistoricalData(exchange, token, interval, from, to, includeOI)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to fetch historical data")
 		return nil, err
 	}
+	for _, candle := range candles {
+		if _, err := candle.ParsedTime(); err != nil {
+			return nil, err
+		}
+	}
+	return c.PriceConverter().HistoricalCandles(id, candles), nil
+}
+
+// fetchHistorical fetches the candles of a date range with a single request.
+func (c *Client) fetchHistorical(exchange, token, interval, from, to string, includeOI bool) ([]HistoricalCandle, error) {
+	endpoint := c.endpoint(EndpointCandles, exchange, token, interval, from, to)
+
+	// If Open Interest (OI) is requested, append it as a query parameter.
+	if includeOI {
+		endpoint += "&oi=1"
+	}
 
 	var result HistoricalDataResponse
-	// Parse the JSON response into the HistoricalDataResponse struct.
-	if err := json.Unmarshal(resp, &result); err != nil {
-		log.Error().Err(err).Msg("Failed to parse historical data response")
+	// Stream the response and parse the JSON into the HistoricalDataResponse struct.
+	err := c.download(endpoint, func(r io.Reader) error {
+		return json.NewDecoder(r).Decode(&result)
+	})
+	if err != nil {
+		c.log().Error().Err(err).Msg("Failed to fetch historical data")
 		return nil, err
 	}
 
 	// Check if the API response status indicates success.
 	if result.Status != "success" {
-		return nil, fmt.Errorf("historical data retrieval failed")
+		return nil, &APIError{Op: "historical data retrieval", Endpoint: endpoint, Message: result.Message}
 	}
-
-	log.Info().
-		Str("exchange", exchange).
-		Str("token", token).
-		Str("interval", interval).
-		Bool("includeOI", includeOI).
-		Msg("Historical data retrieved successfully")
-
 	return result.Data, nil
 }
@@ -0,0 +1,188 @@
+package tiqs
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultHistoricalWindow returns the longest date range fetched in one historical data
+// request for candles of the given length, when no window was set with SetHistoricalWindow.
+//
+// The windows stay below the limits the API enforces per call, which shrink with the
+// candle length: about two months of 1-minute candles, but years of daily candles.
+func DefaultHistoricalWindow(step time.Duration) time.Duration {
+	const day = 24 * time.Hour
+	switch {
+	case step < 3*time.Minute:
+		return 60 * day
+	case step < 15*time.Minute:
+		return 100 * day
+	case step < time.Hour:
+		return 200 * day
+	case step < day:
+		return 400 * day
+	default:
+		return 2000 * day
+	}
+}
+
+// SetHistoricalChunking makes GetHistoricalData split ranges longer than the per-call
+// window of the interval into chunks, fetch them one after the other and stitch the
+// candles, dropping the duplicates at chunk boundaries.
+//
+// Chunks are fetched through the same rate limits as single requests, so a long range of
+// minute candles takes a few seconds.
+//
+// Parameters:
+//   - enabled: true to fetch long ranges in chunks.
+func (c *Client) SetHistoricalChunking(enabled bool) {
+	c.chunkHistory = enabled
+}
+
+// SetHistoricalWindow overrides the longest range fetched in one request for an interval,
+// e.g., if the API limits change.
+//
+// Parameters:
+//   - interval: The timeframe of the candles (e.g., "1m", "1d").
+//   - window: The longest range per request; zero restores DefaultHistoricalWindow.
+func (c *Client) SetHistoricalWindow(interval string, window time.Duration) {
+	key := strings.ToLower(strings.TrimSpace(interval))
+	if window <= 0 {
+		delete(c.historyWindows, key)
+		return
+	}
+	if c.historyWindows == nil {
+		c.historyWindows = make(map[string]time.Duration)
+	}
+	c.historyWindows[key] = window
+}
+
+// historyWindow returns the longest range fetched in one request for an interval.
+func (c *Client) historyWindow(interval string) (time.Duration, error) {
+	if window, ok := c.historyWindows[strings.ToLower(strings.TrimSpace(interval))]; ok {
+		return window, nil
+	}
+	step, err := parseCandleInterval(interval)
+	if err != nil {
+		return 0, err
+	}
+	return DefaultHistoricalWindow(step), nil
+}
+
+// fetchHistoricalChunked fetches the candles of a date range in chunks no longer than the
+// window of the interval. Ranges whose bounds cannot be parsed are fetched in one request
+// and left for the API to judge.
+func (c *Client) fetchHistoricalChunked(exchange, token, interval, from, to string, includeOI bool) ([]HistoricalCandle, error) {
+	start, layout, ok := parseRangeBound(from)
+	end, _, ok2 := parseRangeBound(to)
+	window, err := c.historyWindow(interval)
+	if !ok || !ok2 || err != nil || !end.After(start.Add(window)) {
+		return c.fetchHistorical(exchange, token, interval, from, to, includeOI)
+	}
+
+	chunks := chunkRange(start, end, window, isDateLayout(layout))
+	var candles []HistoricalCandle
+	for i, chunk := range chunks {
+		chunkFrom, chunkTo := formatRangeBound(chunk[0], layout), formatRangeBound(chunk[1], layout)
+		part, err := c.fetchHistorical(exchange, token, interval, chunkFrom, chunkTo, includeOI)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching chunk %d of %d (%s to %s): %w", i+1, len(chunks), chunkFrom, chunkTo, err)
+		}
+		c.log().Debug().
+			Str("token", token).
+			Str("from", chunkFrom).
+			Str("to", chunkTo).
+			Int("candles", len(part)).
+			Msg("Historical data chunk retrieved")
+		candles = append(candles, part...)
+	}
+	return stitchCandles(candles), nil
+}
+
+// parseRangeBound parses a from or to parameter of GetHistoricalData, returning the layout
+// it was written in so that chunk bounds can be written the same way. Epoch seconds have
+// an empty layout.
+func parseRangeBound(s string) (time.Time, string, bool) {
+	s = strings.TrimSpace(s)
+	if epoch, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(epoch, 0).In(IST), "", true
+	}
+	for _, layout := range timestampLayouts {
+		if t, err := time.ParseInLocation(layout, s, IST); err == nil {
+			return t.In(IST), layout, true
+		}
+	}
+	return time.Time{}, "", false
+}
+
+// formatRangeBound writes a chunk bound in the layout of the original parameter.
+func formatRangeBound(t time.Time, layout string) string {
+	if layout == "" {
+		return strconv.FormatInt(t.Unix(), 10)
+	}
+	return t.In(IST).Format(layout)
+}
+
+// isDateLayout reports whether a layout carries a date without a time of day.
+func isDateLayout(layout string) bool {
+	return layout != "" && !strings.Contains(layout, "15")
+}
+
+// chunkRange splits [start, end] into consecutive ranges no longer than window. Ranges of
+// dates are split into whole days that do not overlap, since each bound covers the entire
+// day; other ranges share their bounds, and the candle on a bound is dropped once by
+// stitchCandles.
+func chunkRange(start, end time.Time, window time.Duration, dates bool) [][2]time.Time {
+	const day = 24 * time.Hour
+	if dates {
+		window = max(window.Truncate(day), day)
+	}
+
+	var chunks [][2]time.Time
+	for !start.After(end) {
+		next := start.Add(window)
+		if dates {
+			// The last day of the chunk; the next chunk starts the day after.
+			next = start.AddDate(0, 0, int(window/day)-1)
+		}
+		if next.After(end) {
+			next = end
+		}
+		chunks = append(chunks, [2]time.Time{start, next})
+		if !next.Before(end) {
+			break
+		}
+		if dates {
+			start = next.AddDate(0, 0, 1)
+		} else {
+			start = next
+		}
+	}
+	return chunks
+}
+
+// stitchCandles sorts the candles of several chunks by time and drops duplicates, keeping
+// the last copy of each candle. Candles whose time cannot be parsed are kept as they are.
+func stitchCandles(candles []HistoricalCandle) []HistoricalCandle {
+	times := make([]time.Time, len(candles))
+	for i, candle := range candles {
+		times[i], _ = parseTimestamp(candle.Time)
+	}
+	index := make([]int, len(candles))
+	for i := range index {
+		index[i] = i
+	}
+	sort.SliceStable(index, func(a, b int) bool { return times[index[a]].Before(times[index[b]]) })
+
+	stitched := make([]HistoricalCandle, 0, len(candles))
+	for n, i := range index {
+		if n+1 < len(index) && !times[i].IsZero() && times[index[n+1]].Equal(times[i]) {
+			continue
+		}
+		stitched = append(stitched, candles[i])
+	}
+	return stitched
+}
@@ -0,0 +1,84 @@
+package tiqs
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"time"
+)
+
+// GetHistoricalDataIter streams the candles of a date range of any length, fetching it in
+// chunks no longer than the per-call window of the interval (see SetHistoricalWindow).
+//
+// Only one chunk is held in memory at a time, and the next chunk is fetched once the
+// loop has consumed the previous one, so a slow consumer never has candles piling up and
+// years of minute candles can be processed in constant memory. Candles are yielded in
+// time order without the duplicates at chunk boundaries; pre-open candles are flagged or
+// dropped as in GetHistoricalData.
+//
+// Iteration stops when the loop breaks, when ctx is cancelled or at the first error, which
+// is yielded with a zero candle.
+//
+// Parameters:
+//   - ctx: Context whose cancellation stops fetching further chunks.
+//   - exchange: The exchange where the instrument is listed (e.g., NSE, BSE).
+//   - token: The unique identifier of the instrument.
+//   - interval: The timeframe of the candles (e.g., "1m", "5m", "1d").
+//   - from: The start date/time for historical data.
+//   - to: The end date/time for historical data.
+//   - includeOI: Boolean flag to include Open Interest (OI) data if available.
+//
+// Returns:
+//   - An iterator over the candles and the error that ended the iteration, if any.
+func (c *Client) GetHistoricalDataIter(ctx context.Context, exchange, token, interval, from, to string, includeOI bool) iter.Seq2[HistoricalCandle, error] {
+	return func(yield func(HistoricalCandle, error) bool) {
+		chunks := [][2]string{{from, to}}
+		start, layout, ok := parseRangeBound(from)
+		end, _, ok2 := parseRangeBound(to)
+		if window, err := c.historyWindow(interval); ok && ok2 && err == nil {
+			chunks = chunks[:0]
+			for _, chunk := range chunkRange(start, end, window, isDateLayout(layout)) {
+				chunks = append(chunks, [2]string{formatRangeBound(chunk[0], layout), formatRangeBound(chunk[1], layout)})
+			}
+		}
+
+		clock := NewMarketClock()
+		var last time.Time
+		for i, chunk := range chunks {
+			if err := ctx.Err(); err != nil {
+				yield(HistoricalCandle{}, err)
+				return
+			}
+
+			candles, err := c.fetchHistorical(exchange, token, interval, chunk[0], chunk[1], includeOI)
+			if err != nil {
+				yield(HistoricalCandle{}, fmt.Errorf("error fetching chunk %d of %d (%s to %s): %w", i+1, len(chunks), chunk[0], chunk[1], err))
+				return
+			}
+			c.log().Debug().
+				Str("token", token).
+				Str("from", chunk[0]).
+				Str("to", chunk[1]).
+				Int("candles", len(candles)).
+				Msg("Historical data chunk retrieved")
+
+			candles = MarkPreOpen(stitchCandles(candles), clock)
+			if c.excludePreOpen {
+				candles = ExcludePreOpen(candles)
+			}
+			for _, candle := range candles {
+				// Chunks share their bounds, so skip the candles the previous chunk yielded.
+				t, ok := parseTimestamp(candle.Time)
+				if ok && !last.IsZero() && !t.After(last) {
+					continue
+				}
+				if !yield(candle, nil) {
+					return
+				}
+				if ok {
+					last = t
+				}
+			}
+		}
+	}
+}
@@ -1,11 +1,6 @@
 package tiqs
 
-import (
-	"encoding/json"
-	"fmt"
-
-	"github.com/rs/zerolog/log"
-)
+import ()
 
 // Holding represents a user's stock or asset holding details.
 type Holding struct {
@@ -46,27 +41,27 @@ type HoldingsResponse struct {
 //   - A slice of Holding structs containing all available holdings if successful.
 //   - An error if the request fails or the response cannot be parsed.
 func (c *Client) GetHoldings() ([]Holding, error) {
-	endpoint := "/user/holdings"
+	endpoint := c.endpoint(EndpointHoldings)
 
 	// Send a GET request to the API to fetch holdings.
 	resp, err := c.request(endpoint, "GET", nil)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to fetch holdings")
+		c.log().Error().Err(err).Msg("Failed to fetch holdings")
 		return nil, err
 	}
 
 	var result HoldingsResponse
 	// Parse the JSON response into the HoldingsResponse struct.
-	if err := json.Unmarshal(resp, &result); err != nil {
-		log.Error().Err(err).Msg("Failed to parse holdings response")
+	if err := c.decode(EndpointHoldings, resp, &result); err != nil {
+		c.log().Error().Err(err).Msg("Failed to parse holdings response")
 		return nil, err
 	}
 
 	// Check if the API response status indicates success.
 	if result.Status != "success" {
-		return nil, fmt.Errorf("holdings retrieval failed")
+		return nil, newAPIError("holdings retrieval", endpoint, 0, resp)
 	}
 
-	log.Info().Msg("Holdings retrieved successfully")
+	c.log().Info().Msg("Holdings retrieved successfully")
 	return result.Data, nil
 }
@@ -0,0 +1,199 @@
+package tiqs
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Abhi13027/go-tiqs/ticks"
+)
+
+// ExecutionStyle is how an order is best executed given its estimated market impact.
+type ExecutionStyle int
+
+const (
+	ExecuteMarket ExecutionStyle = iota // Cross the spread at once with a market order.
+	ExecuteWorked                       // Work the order with limits or slices over time.
+)
+
+// String returns the name of the execution style.
+func (s ExecutionStyle) String() string {
+	if s == ExecuteWorked {
+		return "worked"
+	}
+	return "market"
+}
+
+// ImpactEstimate is the expected result of a hypothetical market order walking the book.
+// Prices are in rupees; slippage and impact are positive when adverse to the order.
+type ImpactEstimate struct {
+	Token      int64           `json:"token"`      // Unique identifier for the instrument.
+	Side       TransactionType `json:"side"`       // Side of the hypothetical order.
+	Quantity   int64           `json:"quantity"`   // Quantity of the hypothetical order.
+	Filled     int64           `json:"filled"`     // Quantity the visible depth can absorb.
+	Levels     int             `json:"levels"`     // Depth levels consumed, partly or fully.
+	AvgPrice   float64         `json:"avgPrice"`   // Average fill price of the filled quantity.
+	BestPrice  float64         `json:"bestPrice"`  // Best price on the side being taken.
+	WorstPrice float64         `json:"worstPrice"` // Price of the last level consumed.
+	Mid        float64         `json:"mid"`        // Mid price before the order, or the LTP of a one-sided book.
+	Slippage   float64         `json:"slippage"`   // Average fill price minus the best price, per unit.
+	ImpactBps  float64         `json:"impactBps"`  // Distance of the average fill price from the mid, in basis points.
+	Cost       float64         `json:"cost"`       // Slippage over the whole filled quantity, in rupees.
+	Age        time.Duration   `json:"age"`        // Age of the depth the estimate is based on.
+}
+
+// Complete reports whether the visible depth can absorb the whole order. Only five levels
+// are streamed, so an incomplete estimate understates the impact of the rest.
+func (e ImpactEstimate) Complete() bool {
+	return e.Filled >= e.Quantity
+}
+
+// Remaining returns the quantity the visible depth cannot absorb.
+func (e ImpactEstimate) Remaining() int64 {
+	return e.Quantity - e.Filled
+}
+
+// Style chooses between a market and a worked order: market if the visible depth absorbs
+// the whole order within the impact budget, worked otherwise.
+//
+// Parameters:
+//   - maxImpactBps: The largest acceptable distance of the average fill from the mid.
+//
+// Returns:
+//   - The execution style.
+func (e ImpactEstimate) Style(maxImpactBps float64) ExecutionStyle {
+	if e.Complete() && e.ImpactBps <= maxImpactBps {
+		return ExecuteMarket
+	}
+	return ExecuteWorked
+}
+
+// ImpactEstimator estimates the market impact of orders from the depth of full-mode ticks.
+type ImpactEstimator struct {
+	Book   *ticks.DepthBook // Latest depth per token, updated from the websocket.
+	Prices *PriceConverter  // Converts depth prices into rupees.
+	MaxAge time.Duration    // Depth older than this is refused; zero accepts any age.
+}
+
+// NewImpactEstimator creates an estimator reading a depth book.
+//
+// Parameters:
+//   - book: The depth book fed with full-mode ticks.
+//   - prices: The converter of depth prices into rupees; nil assumes paise.
+//
+// Returns:
+//   - A pointer to a newly created ImpactEstimator.
+func NewImpactEstimator(book *ticks.DepthBook, prices *PriceConverter) *ImpactEstimator {
+	if prices == nil {
+		prices = NewPriceConverter(nil)
+	}
+	return &ImpactEstimator{Book: book, Prices: prices}
+}
+
+// ImpactEstimator returns an estimator reading a depth book and converting prices with the
+// instrument store attached to the client.
+func (c *Client) ImpactEstimator(book *ticks.DepthBook) *ImpactEstimator {
+	return NewImpactEstimator(book, c.PriceConverter())
+}
+
+// EstimateImpact walks the visible depth of a token as a market order of qty would: a buy
+// lifts the asks from the best price upwards, a sell hits the bids downwards.
+//
+// Parameters:
+//   - token: The instrument.
+//   - side: The side of the hypothetical order.
+//   - qty: The quantity of the hypothetical order, in units.
+//
+// Returns:
+//   - The estimate; check Complete before trusting it for orders deeper than the book.
+//   - An error if the quantity or side is invalid, or the token has no recent depth on the
+//     side being taken.
+func (e *ImpactEstimator) EstimateImpact(token int64, side TransactionType, qty int64) (ImpactEstimate, error) {
+	if qty <= 0 {
+		return ImpactEstimate{}, fmt.Errorf("invalid quantity: %d", qty)
+	}
+	snapshot, ok := e.Book.Get(int32(token))
+	if !ok {
+		return ImpactEstimate{}, fmt.Errorf("no depth for token %d", token)
+	}
+	age := time.Since(snapshot.UpdatedAt)
+	if e.MaxAge > 0 && age > e.MaxAge {
+		return ImpactEstimate{}, fmt.Errorf("depth of token %d is %s old", token, age.Round(time.Millisecond))
+	}
+
+	var (
+		levels [5]ticks.DepthLevel
+		book   string
+	)
+	switch side {
+	case TransactionBuy:
+		levels, book = snapshot.Depth.Asks, "asks"
+	case TransactionSell:
+		levels, book = snapshot.Depth.Bids, "bids"
+	default:
+		return ImpactEstimate{}, fmt.Errorf("invalid side: %q", side)
+	}
+	if levels[0].Price <= 0 || levels[0].Quantity <= 0 {
+		return ImpactEstimate{}, fmt.Errorf("no %s for token %d", book, token)
+	}
+
+	divisor := e.Prices.Divisor(token)
+	rupees := func(v int32) float64 { return float64(v) / divisor }
+
+	est := ImpactEstimate{
+		Token:     token,
+		Side:      side,
+		Quantity:  qty,
+		BestPrice: rupees(levels[0].Price),
+		Mid:       rupees(snapshot.LTP),
+		Age:       age,
+	}
+	if bid, ask := snapshot.Depth.Bids[0].Price, snapshot.Depth.Asks[0].Price; bid > 0 && ask > 0 {
+		est.Mid = (rupees(bid) + rupees(ask)) / 2
+	}
+
+	var value float64
+	for _, level := range levels {
+		if est.Filled >= qty || level.Price <= 0 || level.Quantity <= 0 {
+			break
+		}
+		take := min(level.Quantity, qty-est.Filled)
+		value += float64(take) * rupees(level.Price)
+		est.Filled += take
+		est.Levels++
+		est.WorstPrice = rupees(level.Price)
+	}
+
+	est.AvgPrice = value / float64(est.Filled)
+	est.Slippage = est.AvgPrice - est.BestPrice
+	if side == TransactionSell {
+		est.Slippage = -est.Slippage
+	}
+	est.Cost = est.Slippage * float64(est.Filled)
+	if est.Mid > 0 {
+		est.ImpactBps = (est.AvgPrice - est.Mid) / est.Mid * 10000
+		if side == TransactionSell {
+			est.ImpactBps = -est.ImpactBps
+		}
+	}
+	return est, nil
+}
+
+// ChooseStyle estimates the impact of an order and chooses how to execute it. Orders are
+// worked whenever no estimate can be made, e.g., before the first full-mode tick.
+//
+// Parameters:
+//   - token: The instrument.
+//   - side: The side of the order.
+//   - qty: The quantity of the order, in units.
+//   - maxImpactBps: The largest acceptable distance of the average fill from the mid.
+//
+// Returns:
+//   - The execution style.
+//   - The estimate it is based on, or an error if none could be made.
+func (e *ImpactEstimator) ChooseStyle(token int64, side TransactionType, qty int64, maxImpactBps float64) (ExecutionStyle, ImpactEstimate, error) {
+	est, err := e.EstimateImpact(token, side, qty)
+	if err != nil {
+		return ExecuteWorked, est, err
+	}
+	return est.Style(maxImpactBps), est, nil
+}
@@ -3,8 +3,6 @@ package tiqs
 import (
 	"encoding/json"
 	"fmt"
-
-	"github.com/rs/zerolog/log"
 )
 
 // HolidaysResponse represents the API response structure for market holidays.
@@ -55,7 +53,7 @@ type OptionChainResponse struct {
 //   - A pointer to a HolidaysResponse struct containing holiday details if successful.
 //   - An error if the request fails or the response cannot be parsed.
 func (c *Client) GetHolidays() (*HolidaysResponse, error) {
-	endpoint := "/info/holidays"
+	endpoint := c.endpoint(EndpointHolidays)
 
 	// Send a GET request to fetch market holidays.
 	resp, err := c.request(endpoint, "GET", nil)
@@ -65,7 +63,7 @@ func (c *Client) GetHolidays() (*HolidaysResponse, error) {
 
 	// Parse the JSON response into the HolidaysResponse struct.
 	var holidaysResponse HolidaysResponse
-	if err := json.Unmarshal(resp, &holidaysResponse); err != nil {
+	if err := c.decode(EndpointHolidays, resp, &holidaysResponse); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal holidays response: %w", err)
 	}
 
@@ -81,7 +79,7 @@ func (c *Client) GetHolidays() (*HolidaysResponse, error) {
 //   - A pointer to an IndexListResponse struct containing index details if successful.
 //   - An error if the request fails or the response cannot be parsed.
 func (c *Client) GetIndexList() (*IndexListResponse, error) {
-	endpoint := "/info/index-list"
+	endpoint := c.endpoint(EndpointIndexList)
 
 	// Send a GET request to fetch the list of indices.
 	resp, err := c.request(endpoint, "GET", nil)
@@ -91,7 +89,7 @@ func (c *Client) GetIndexList() (*IndexListResponse, error) {
 
 	// Parse the JSON response into the IndexListResponse struct.
 	var indexListResponse IndexListResponse
-	if err := json.Unmarshal(resp, &indexListResponse); err != nil {
+	if err := c.decode(EndpointIndexList, resp, &indexListResponse); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal index list response: %w", err)
 	}
 
@@ -107,7 +105,7 @@ func (c *Client) GetIndexList() (*IndexListResponse, error) {
 //   - A pointer to an OptionChainSymbolResponse struct containing option chain symbols if successful.
 //   - An error if the request fails or the response cannot be parsed.
 func (c *Client) GetOptionChainSymbol() (*OptionChainSymbolResponse, error) {
-	endpoint := "/info/option-chain-symbols"
+	endpoint := c.endpoint(EndpointOptionChainSymbols)
 
 	// Send a GET request to fetch option chain symbols.
 	resp, err := c.request(endpoint, "GET", nil)
@@ -117,7 +115,7 @@ func (c *Client) GetOptionChainSymbol() (*OptionChainSymbolResponse, error) {
 
 	// Parse the JSON response into the OptionChainSymbolResponse struct.
 	var optionChainSymbolResponse OptionChainSymbolResponse
-	if err := json.Unmarshal(resp, &optionChainSymbolResponse); err != nil {
+	if err := c.decode(EndpointOptionChainSymbols, resp, &optionChainSymbolResponse); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal option chain symbols response: %w", err)
 	}
 
@@ -129,24 +127,26 @@ func (c *Client) GetOptionChainSymbol() (*OptionChainSymbolResponse, error) {
 // It sends a POST request to the "/info/option-chain" endpoint to retrieve the option chain
 // details for a specific symbol.
 //
+// Parameters:
+//   - params: The underlying, exchange, strike count and expiry of the chain.
+//
 // Returns:
 //   - A pointer to an OptionChainResponse struct containing option chain details if successful.
-//   - An error if the request fails or the response cannot be parsed.
-func (c *Client) GetOptionChain(token, exchange, count, expiry string) (*OptionChainResponse, error) {
-	endpoint := "/info/option-chain"
+//   - An error if the parameters are invalid, the request fails or the response cannot be parsed.
+func (c *Client) GetOptionChain(params OptionChainParams) (*OptionChainResponse, error) {
+	endpoint := c.endpoint(EndpointOptionChain)
 
-	// Prepare the request payload with the required parameters.
-	req := map[string]string{
-		"token":    token,
-		"exchange": exchange,
-		"count":    count,
-		"expiry":   expiry,
+	if err := params.Validate(); err != nil {
+		return nil, err
 	}
 
+	// Prepare the request payload with the required parameters.
+	req := params.payload()
+
 	payload, err := json.Marshal(req)
-	log.Info().Str("payload", string(payload)).Msg("Getting the Option Chain")
+	c.log().Debug().Str("payload", string(payload)).Msg("Getting the Option Chain")
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to serialize option chain payload")
+		c.log().Error().Err(err).Msg("Failed to serialize option chain payload")
 		return nil, err
 	}
 
@@ -158,7 +158,7 @@ func (c *Client) GetOptionChain(token, exchange, count, expiry string) (*OptionC
 
 	// Parse the JSON response into the OptionChainResponse struct.
 	var optionChainResponse OptionChainResponse
-	if err := json.Unmarshal(resp, &optionChainResponse); err != nil {
+	if err := c.decode(EndpointOptionChain, resp, &optionChainResponse); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal option chain response: %w", err)
 	}
 
@@ -0,0 +1,135 @@
+package tiqs
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultInstrumentPublishTime is the time of day, in IST, after which the instrument
+// master of the trading day is assumed to be published.
+const DefaultInstrumentPublishTime = 8 * time.Hour
+
+// instrumentCachePrefix starts the name of every instrument cache file.
+const instrumentCachePrefix = "instruments-"
+
+// InstrumentCache keeps the parsed instrument master on disk, one gob file per trade
+// date, so that restarts within a day skip downloading and parsing the full CSV.
+//
+// The cache of a date is used from PublishTime that day until PublishTime the next day;
+// after that, GetInstrumentList downloads the new master and replaces the cache.
+type InstrumentCache struct {
+	Dir         string        // Directory of the cache files.
+	PublishTime time.Duration // Time of day (IST) from which the day's master is available, as an offset from midnight.
+}
+
+// NewInstrumentCache creates a cache in dir with DefaultInstrumentPublishTime.
+//
+// Parameters:
+//   - dir: The directory of the cache files; it is created on the first save.
+//
+// Returns:
+//   - A pointer to a newly created InstrumentCache.
+func NewInstrumentCache(dir string) *InstrumentCache {
+	return &InstrumentCache{Dir: dir, PublishTime: DefaultInstrumentPublishTime}
+}
+
+// TradeDate returns the date of the instrument master current at now, formatted as
+// YYYYMMDD: the previous day before PublishTime, the day itself after it.
+func (c *InstrumentCache) TradeDate(now time.Time) string {
+	return now.In(IST).Add(-c.PublishTime).Format("20060102")
+}
+
+// Load reads the cached instruments of the trade date of now.
+//
+// Returns:
+//   - The instruments and true if the cache is fresh; otherwise, nil and false.
+//   - An error if the cache file exists but cannot be read.
+func (c *InstrumentCache) Load(now time.Time) ([]Instrument, bool, error) {
+	data, err := os.ReadFile(c.path(c.TradeDate(now)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("error reading instrument cache: %w", err)
+	}
+
+	var instruments []Instrument
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&instruments); err != nil {
+		return nil, false, fmt.Errorf("error decoding instrument cache: %w", err)
+	}
+	return instruments, true, nil
+}
+
+// Save writes the instruments as the cache of the trade date of now and removes the
+// caches of other dates.
+func (c *InstrumentCache) Save(now time.Time, instruments []Instrument) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(instruments); err != nil {
+		return fmt.Errorf("error encoding instrument cache: %w", err)
+	}
+	if err := os.MkdirAll(c.Dir, 0o755); err != nil {
+		return fmt.Errorf("error creating instrument cache directory: %w", err)
+	}
+
+	current := c.path(c.TradeDate(now))
+	if err := writeFileAtomic(current, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("error writing instrument cache: %w", err)
+	}
+
+	stale, _ := filepath.Glob(filepath.Join(c.Dir, instrumentCachePrefix+"*.gob"))
+	for _, path := range stale {
+		if path != current {
+			os.Remove(path)
+		}
+	}
+	return nil
+}
+
+// path returns the cache file of a trade date.
+func (c *InstrumentCache) path(date string) string {
+	return filepath.Join(c.Dir, instrumentCachePrefix+date+".gob")
+}
+
+// SetInstrumentCache makes GetInstrumentList serve the instrument master from a disk
+// cache while it is fresh, and save every download to it.
+//
+// Parameters:
+//   - cache: The cache to use, or nil to always download the master.
+func (c *Client) SetInstrumentCache(cache *InstrumentCache) {
+	c.instrumentCache = cache
+}
+
+// cachedInstruments returns the instruments of a fresh disk cache, if one is set.
+func (c *Client) cachedInstruments() ([]Instrument, bool) {
+	cache := c.instrumentCache
+	if cache == nil {
+		return nil, false
+	}
+	instruments, ok, err := cache.Load(time.Now())
+	if err != nil {
+		c.log().Warn().Err(err).Msg("Ignoring unreadable instrument cache")
+		return nil, false
+	}
+	if ok {
+		c.log().Info().Int("instruments", len(instruments)).Str("tradeDate", cache.TradeDate(time.Now())).Msg("Instrument list loaded from cache")
+	}
+	return instruments, ok
+}
+
+// cacheInstruments saves a downloaded instrument master to the disk cache, if one is set.
+// A failure is logged without failing the download.
+func (c *Client) cacheInstruments(instruments []Instrument) {
+	cache := c.instrumentCache
+	if cache == nil || strings.TrimSpace(cache.Dir) == "" {
+		return
+	}
+	if err := cache.Save(time.Now(), instruments); err != nil {
+		c.log().Warn().Err(err).Msg("Failed to cache instrument list")
+	}
+}
@@ -0,0 +1,278 @@
+package tiqs
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// searchEntry holds the searchable names of an instrument.
+type searchEntry struct {
+	token  int64
+	symbol string // Lower-case trading symbol.
+	name   string // Lower-case company name.
+}
+
+// InstrumentFilter selects instruments of the store.
+//
+// Empty fields do not filter; strings are matched case-insensitively.
+type InstrumentFilter struct {
+	Exchanges   []string  // Exchanges to include (e.g., "NSE", "NFO").
+	Segments    []string  // Segments as reported by the instrument master.
+	Instruments []string  // Instrument types (e.g., "EQ", "OPTIDX", "FUTSTK").
+	Underlying  string    // Underlying symbol of derivatives (e.g., "NIFTY").
+	OptionType  string    // "CE" or "PE".
+	Expiry      time.Time // Expiry day of derivatives; the time of day is ignored.
+	MinStrike   float64   // Lowest strike in rupees; zero for no lower bound.
+	MaxStrike   float64   // Highest strike in rupees; zero for no upper bound.
+}
+
+// Match reports whether an instrument passes the filter.
+func (f InstrumentFilter) Match(inst Instrument) bool {
+	if len(f.Exchanges) > 0 && !containsFold(f.Exchanges, inst.Exchange) {
+		return false
+	}
+	if len(f.Segments) > 0 && !containsFold(f.Segments, inst.Segment) {
+		return false
+	}
+	if len(f.Instruments) > 0 && !containsFold(f.Instruments, inst.Instrument) {
+		return false
+	}
+	if f.Underlying != "" && !strings.EqualFold(inst.Symbol, f.Underlying) {
+		return false
+	}
+	if f.OptionType != "" && (inst.OptionType == nil || !strings.EqualFold(*inst.OptionType, f.OptionType)) {
+		return false
+	}
+	if !f.Expiry.IsZero() {
+		expiry, ok := inst.Expiry()
+		if !ok || !sameDay(expiry, f.Expiry) {
+			return false
+		}
+	}
+	if f.MinStrike > 0 || f.MaxStrike > 0 {
+		if !inst.IsOption() {
+			return false
+		}
+		strike := inst.Strike()
+		if strike < f.MinStrike || (f.MaxStrike > 0 && strike > f.MaxStrike) {
+			return false
+		}
+	}
+	return true
+}
+
+// symbolKey returns the key of an instrument in the symbol index.
+func symbolKey(exchange, tradingSymbol string) string {
+	return strings.ToUpper(strings.TrimSpace(exchange)) + ":" + strings.ToUpper(strings.TrimSpace(tradingSymbol))
+}
+
+// indexLocked rebuilds the secondary indexes from byToken. The caller must hold s.mu.
+func (s *InstrumentStore) indexLocked() {
+	s.bySymbol = make(map[string]int64, len(s.byToken))
+	s.byUnderlying = make(map[string][]int64)
+	s.names = make([]searchEntry, 0, len(s.byToken))
+
+	for token, inst := range s.byToken {
+		s.bySymbol[symbolKey(inst.Exchange, inst.TradingSymbol)] = token
+		if _, ok := inst.Expiry(); ok && inst.Symbol != "" {
+			underlying := strings.ToUpper(inst.Symbol)
+			s.byUnderlying[underlying] = append(s.byUnderlying[underlying], token)
+		}
+		s.names = append(s.names, searchEntry{
+			token:  token,
+			symbol: strings.ToLower(inst.TradingSymbol),
+			name:   strings.ToLower(inst.CompanyName),
+		})
+	}
+	// Keep results deterministic across rebuilds.
+	sort.Slice(s.names, func(i, j int) bool { return s.names[i].token < s.names[j].token })
+}
+
+// Lookup returns the instrument with the given trading symbol on an exchange.
+//
+// Parameters:
+//   - exchange: The exchange (e.g., "NSE", "NFO").
+//   - tradingSymbol: The trading symbol (e.g., "RELIANCE-EQ"), ignoring case.
+//
+// Returns:
+//   - The instrument and true if found; otherwise, a zero Instrument and false.
+func (s *InstrumentStore) Lookup(exchange, tradingSymbol string) (Instrument, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	token, ok := s.bySymbol[symbolKey(exchange, tradingSymbol)]
+	if !ok {
+		return Instrument{}, false
+	}
+	return s.byToken[token], true
+}
+
+// TokenOf returns the token of a trading symbol on an exchange.
+//
+// Returns:
+//   - The token and true if found; otherwise, zero and false.
+func (s *InstrumentStore) TokenOf(exchange, tradingSymbol string) (int64, bool) {
+	inst, ok := s.Lookup(exchange, tradingSymbol)
+	return inst.Token, ok
+}
+
+// Filter returns the instruments passing the filter, ordered by exchange, expiry, strike
+// and trading symbol. Filters on an underlying only scan the derivatives of that underlying.
+//
+// Parameters:
+//   - filter: The filter to apply, e.g., the NIFTY calls of an expiry.
+//
+// Returns:
+//   - The matching instruments.
+func (s *InstrumentStore) Filter(filter InstrumentFilter) []Instrument {
+	s.mu.RLock()
+	var matched []Instrument
+	if filter.Underlying != "" {
+		for _, token := range s.byUnderlying[strings.ToUpper(filter.Underlying)] {
+			if inst := s.byToken[token]; filter.Match(inst) {
+				matched = append(matched, inst)
+			}
+		}
+	} else {
+		for _, inst := range s.byToken {
+			if filter.Match(inst) {
+				matched = append(matched, inst)
+			}
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(matched, func(i, j int) bool {
+		a, b := matched[i], matched[j]
+		if a.Exchange != b.Exchange {
+			return a.Exchange < b.Exchange
+		}
+		ea, _ := a.Expiry()
+		eb, _ := b.Expiry()
+		if !ea.Equal(eb) {
+			return ea.Before(eb)
+		}
+		if a.StrikePrice != b.StrikePrice {
+			return a.StrikePrice < b.StrikePrice
+		}
+		return a.TradingSymbol < b.TradingSymbol
+	})
+	return matched
+}
+
+// Expiries returns the distinct expiry days of the derivatives of an underlying, in ascending order.
+//
+// Parameters:
+//   - underlying: The underlying symbol (e.g., "BANKNIFTY").
+func (s *InstrumentStore) Expiries(underlying string) []time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	seen := make(map[time.Time]bool)
+	var expiries []time.Time
+	for _, token := range s.byUnderlying[strings.ToUpper(underlying)] {
+		expiry, ok := s.byToken[token].Expiry()
+		if !ok {
+			continue
+		}
+		y, m, d := expiry.In(IST).Date()
+		day := time.Date(y, m, d, 0, 0, 0, 0, IST)
+		if !seen[day] {
+			seen[day] = true
+			expiries = append(expiries, day)
+		}
+	}
+	sort.Slice(expiries, func(i, j int) bool { return expiries[i].Before(expiries[j]) })
+	return expiries
+}
+
+// Search finds instruments by trading symbol or company name, best matches first.
+//
+// Exact symbol matches rank first, followed by symbol prefixes, name prefixes, substrings
+// and finally fuzzy matches whose characters appear in order (e.g., "hdfcbk" matches
+// "HDFC Bank"). Ties are broken by shorter symbols, so equities rank before derivatives.
+//
+// Parameters:
+//   - query: The text to search for, ignoring case.
+//   - limit: Maximum number of results; zero or negative for no limit.
+//
+// Returns:
+//   - The matching instruments.
+func (s *InstrumentStore) Search(query string, limit int) []Instrument {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return nil
+	}
+
+	type hit struct {
+		token  int64
+		score  int
+		length int
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var hits []hit
+	for _, e := range s.names {
+		if score := searchScore(query, e); score > 0 {
+			hits = append(hits, hit{token: e.token, score: score, length: len(e.symbol)})
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].score != hits[j].score {
+			return hits[i].score > hits[j].score
+		}
+		return hits[i].length < hits[j].length
+	})
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+
+	results := make([]Instrument, len(hits))
+	for i, h := range hits {
+		results[i] = s.byToken[h.token]
+	}
+	return results
+}
+
+// searchScore rates how well an instrument matches a lower-case query; zero means no match.
+func searchScore(query string, e searchEntry) int {
+	base := strings.TrimSuffix(e.symbol, "-eq")
+	switch {
+	case e.symbol == query || base == query:
+		return 6
+	case strings.HasPrefix(e.symbol, query):
+		return 5
+	case strings.HasPrefix(e.name, query):
+		return 4
+	case strings.Contains(e.symbol, query):
+		return 3
+	case strings.Contains(e.name, query):
+		return 2
+	case isSubsequence(query, e.name) || isSubsequence(query, e.symbol):
+		return 1
+	}
+	return 0
+}
+
+// isSubsequence reports whether the non-space characters of query appear in s in order.
+func isSubsequence(query, s string) bool {
+	i := 0
+	for _, r := range s {
+		for i < len(query) && query[i] == ' ' {
+			i++
+		}
+		if i == len(query) {
+			return true
+		}
+		if r == rune(query[i]) {
+			i++
+		}
+	}
+	for i < len(query) && query[i] == ' ' {
+		i++
+	}
+	return i == len(query)
+}
@@ -4,10 +4,11 @@ import (
 	"bytes"
 	"encoding/csv"
 	"fmt"
+	"io"
 	"strings"
+	"time"
 
 	"github.com/gocarina/gocsv"
-	"github.com/rs/zerolog/log"
 )
 
 type Instrument struct {
@@ -39,72 +40,125 @@ type Instrument struct {
 // GetInstrumentList fetches the list of all available instruments.
 //
 // It sends a GET request to the "/all" endpoint to retrieve a list of all available
-// instruments on the platform.
+// instruments on the platform. The CSV is streamed and cleaned row by row, subject to
+// Config.MaxResponseSize. With an instrument cache set (see SetInstrumentCache), the
+// master of the current trade date is read from disk instead when available.
 //
 // Returns:
 //   - A slice of Instrument structs containing all available instruments if successful.
 //   - An error if the request fails or the response cannot be parsed.
 func (c *Client) GetInstrumentList() ([]Instrument, error) {
-	endpoint := "/all"
-
-	resp, err := c.request(endpoint, "GET", nil)
-	if err != nil {
-		log.Error().Err(err).Msg("Failed to fetch instrument list")
-		return nil, err
+	if instruments, ok := c.cachedInstruments(); ok {
+		return instruments, nil
 	}
 
-	// Preprocess CSV to clean up any malformed lines
-	cleanCSV, err := preprocessCSV(resp)
+	endpoint := c.endpoint(EndpointInstruments)
+
+	// Preprocess CSV to clean up any malformed lines while it downloads
+	var cleanCSV []byte
+	err := c.download(endpoint, func(r io.Reader) error {
+		var err error
+		cleanCSV, err = preprocessCSV(r)
+		return err
+	})
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to preprocess CSV response")
+		c.log().Error().Err(err).Msg("Failed to fetch instrument list")
 		return nil, err
 	}
 
 	var instruments []Instrument
 	if err := gocsv.UnmarshalBytes(cleanCSV, &instruments); err != nil {
-		log.Error().Err(err).Msg("Failed to parse CSV response")
+		c.log().Error().Err(err).Msg("Failed to parse CSV response")
 		return nil, err
 	}
 
-	log.Info().Msg("Successfully parsed instrument list")
+	c.log().Info().Msg("Successfully parsed instrument list")
+	c.cacheInstruments(instruments)
 	return instruments, nil
 }
 
-func preprocessCSV(data []byte) ([]byte, error) {
-	reader := csv.NewReader(bytes.NewReader(data))
+func preprocessCSV(r io.Reader) ([]byte, error) {
+	reader := csv.NewReader(r)
 	reader.TrimLeadingSpace = true
 	reader.FieldsPerRecord = -1 // Allows variable fields per record
+	reader.ReuseRecord = true
 
-	records, err := reader.ReadAll()
-	if err != nil {
-		return nil, fmt.Errorf("error reading CSV data: %w", err)
-	}
+	// Rows are written back as they are read so that only the cleaned CSV is kept in memory
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+	expectedCols := -1
 
-	// Ensure all rows have the correct number of fields
-	expectedCols := len(records[0])
-	var cleanedRecords [][]string
+	for line := 1; ; line++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading CSV data: %w", err)
+		}
 
-	for i, row := range records {
+		// Ensure all rows have the same number of fields as the header
+		if expectedCols < 0 {
+			expectedCols = len(row)
+		}
 		if len(row) == 0 || strings.TrimSpace(strings.Join(row, "")) == "" {
 			continue // Skip empty lines
 		}
 		if len(row) != expectedCols {
-			log.Warn().
-				Int("line", i+1).
+			defaultLog().Warn().
+				Int("line", line).
 				Int("expected_fields", expectedCols).
 				Int("actual_fields", len(row)).
 				Str("raw_data", fmt.Sprintf("%q", row)). // Print the actual content
 				Msg("Skipping malformed CSV row due to incorrect field count")
 			continue // Skip malformed rows
 		}
-		cleanedRecords = append(cleanedRecords, row)
+		writer.Write(row)
 	}
 
-	// Convert back to CSV format
-	var buffer bytes.Buffer
-	writer := csv.NewWriter(&buffer)
-	writer.WriteAll(cleanedRecords)
 	writer.Flush()
+	return buffer.Bytes(), writer.Error()
+}
 
-	return buffer.Bytes(), nil
+// Expiry returns the expiry date of a derivative instrument in IST.
+//
+// Returns:
+//   - The expiry date and true for derivatives with a known expiry; otherwise, a zero time and false.
+func (i Instrument) Expiry() (time.Time, bool) {
+	if i.ExpiryDate != nil {
+		if t, ok := parseTimestamp(*i.ExpiryDate); ok {
+			return t, true
+		}
+	}
+	if i.ExchExpiryDate > 0 {
+		return time.Unix(i.ExchExpiryDate, 0).In(IST), true
+	}
+	return time.Time{}, false
+}
+
+// Expired reports whether a derivative instrument expired before the trading day of now.
+//
+// Instruments without an expiry, such as equities, never expire. A contract remains
+// valid for the whole of its expiry day.
+func (i Instrument) Expired(now time.Time) bool {
+	expiry, ok := i.Expiry()
+	if !ok {
+		return false
+	}
+
+	ey, em, ed := expiry.In(IST).Date()
+	ny, nm, nd := now.In(IST).Date()
+	return time.Date(ey, em, ed, 0, 0, 0, 0, IST).Before(time.Date(ny, nm, nd, 0, 0, 0, 0, IST))
+}
+
+// IsOption reports whether the instrument is an option contract.
+func (i Instrument) IsOption() bool {
+	return i.OptionType != nil && (*i.OptionType == "CE" || *i.OptionType == "PE")
+}
+
+// Strike returns the strike price of an option in rupees.
+//
+// The instrument master stores strikes in the same integer scale as prices.
+func (i Instrument) Strike() float64 {
+	return float64(i.StrikePrice) / i.PriceDivisor()
 }
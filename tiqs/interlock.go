@@ -0,0 +1,182 @@
+package tiqs
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrDisarmed is returned for requests acting on the account, such as orders, sent while
+// the client's Interlock is not armed.
+var ErrDisarmed = errors.New("live trading is not armed")
+
+// DefaultArmEnv is the environment variable conventionally used as the Interlock's
+// environment flag.
+const DefaultArmEnv = "TIQS_LIVE_TRADING"
+
+// Interlock refuses every request acting on the account (order placements and
+// modifications, position conversions, payins and payouts) until it is explicitly armed,
+// preventing accidental live orders, e.g., from a strategy started with the live instead
+// of the paper configuration.
+//
+// Reads, authentication and cancellations always pass, so that working orders can be
+// taken down while disarmed.
+type Interlock struct {
+	Token string // Confirmation that Arm must be given; empty for none.
+	Env   string // Environment variable that must hold a true value ("1", "true") for Arm to succeed; empty for none.
+
+	mu      sync.Mutex
+	armed   bool
+	armedAt time.Time
+}
+
+// NewInterlock creates a disarmed interlock.
+//
+// Parameters:
+//   - token: The confirmation that Arm must be given, or empty for none.
+//   - env: The environment variable that must be set to a true value to arm, e.g.,
+//     DefaultArmEnv, or empty for none.
+//
+// Returns:
+//   - A pointer to a newly created Interlock.
+func NewInterlock(token, env string) *Interlock {
+	return &Interlock{Token: token, Env: env}
+}
+
+// Arm allows orders to be sent.
+//
+// Parameters:
+//   - token: The confirmation; it must equal Token if one is set.
+//
+// Returns:
+//   - An error if the confirmation is wrong or the environment flag is not set.
+func (l *Interlock) Arm(token string) error {
+	if l.Token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(l.Token)) != 1 {
+		return fmt.Errorf("cannot arm live trading: wrong confirmation")
+	}
+	if l.Env != "" {
+		if on, _ := strconv.ParseBool(strings.TrimSpace(os.Getenv(l.Env))); !on {
+			return fmt.Errorf("cannot arm live trading: %s is not set", l.Env)
+		}
+	}
+
+	l.mu.Lock()
+	l.armed = true
+	l.armedAt = time.Now()
+	l.mu.Unlock()
+	defaultLog().Warn().Msg("Live trading armed")
+	return nil
+}
+
+// Disarm refuses orders again until the next Arm.
+func (l *Interlock) Disarm() {
+	l.mu.Lock()
+	was := l.armed
+	l.armed = false
+	l.mu.Unlock()
+	if was {
+		defaultLog().Warn().Msg("Live trading disarmed")
+	}
+}
+
+// Armed reports whether orders may be sent, and since when.
+func (l *Interlock) Armed() (bool, time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.armed, l.armedAt
+}
+
+// check returns ErrDisarmed unless the interlock is armed.
+func (l *Interlock) check() error {
+	if armed, _ := l.Armed(); !armed {
+		return ErrDisarmed
+	}
+	return nil
+}
+
+// SetInterlock attaches an Interlock to the client.
+//
+// Once attached, every request other than a read, an authentication or a cancellation
+// fails with ErrDisarmed until the interlock is armed. This covers orders, including those
+// sent by FastOrder templates, bracket orders and webhooks, position conversions, payins
+// and payouts, and any endpoint added later.
+//
+// Parameters:
+//   - interlock: The interlock to attach, or nil to detach the current one.
+func (c *Client) SetInterlock(interlock *Interlock) {
+	c.interlock = interlock
+}
+
+// checkInterlock refuses every request but reads, authentication and cancellations while
+// the client's interlock is disarmed. Requests are refused unless known to be harmless, so
+// that new endpoints acting on the account are refused too.
+func (c *Client) checkInterlock(method, endpoint string) error {
+	if c.interlock == nil || c.idempotent(method, endpoint) {
+		return nil
+	}
+	if method == "DELETE" && c.matchesEndpoint(EndpointCancelOrder, endpoint) {
+		return nil
+	}
+	if method == "POST" && c.matchesEndpoint(EndpointAuthenticate, endpoint) {
+		return nil
+	}
+	if err := c.interlock.check(); err != nil {
+		c.log().Error().Str("method", method).Str("endpoint", endpoint).Msg("Request refused: live trading is not armed")
+		return err
+	}
+	return nil
+}
+
+// RequireArm attaches a disarmed Interlock to the session's client, so that no order is
+// sent until Arm is called.
+//
+// Parameters:
+//   - token: The confirmation that Arm must be given, or empty for none.
+//   - env: The environment variable that must be set to a true value to arm, or empty.
+//
+// Returns:
+//   - The attached interlock.
+func (s *Session) RequireArm(token, env string) *Interlock {
+	interlock := NewInterlock(token, env)
+	s.Client.SetInterlock(interlock)
+	return interlock
+}
+
+// Arm allows the session's client to send orders. It does nothing if the client has no
+// Interlock, since orders are then never refused.
+//
+// Parameters:
+//   - token: The confirmation required by the interlock, if any.
+//
+// Returns:
+//   - An error if the interlock refused to arm.
+func (s *Session) Arm(token string) error {
+	if s.Client.interlock == nil {
+		return nil
+	}
+	return s.Client.interlock.Arm(token)
+}
+
+// Disarm stops the session's client from sending orders and other requests acting on
+// the account, attaching an Interlock without confirmation or environment flag if the
+// client has none.
+func (s *Session) Disarm() {
+	if s.Client.interlock == nil {
+		s.Client.SetInterlock(NewInterlock("", ""))
+	}
+	s.Client.interlock.Disarm()
+}
+
+// Armed reports whether the session's client may send orders.
+func (s *Session) Armed() bool {
+	if s.Client.interlock == nil {
+		return true
+	}
+	armed, _ := s.Client.interlock.Armed()
+	return armed
+}
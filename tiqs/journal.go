@@ -0,0 +1,446 @@
+package tiqs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// IssueDuplicateFill is reported when a fill appears more than once in the trades given to
+// BuildTradeJournal, e.g., in overlapping trade books; only its first copy is counted.
+const IssueDuplicateFill ReconcileIssueKind = "DUPLICATE_FILL"
+
+// RoundTrip is a position taken from flat and closed back to flat, the unit the win rate
+// of a journal is counted in.
+//
+// A fill reversing a position closes the round trip and opens the next one with the
+// remaining quantity; its charges are split between the two by quantity.
+type RoundTrip struct {
+	Exchange    string    `json:"exchange" csv:"exchange"`        // Exchange of the instrument.
+	Symbol      string    `json:"symbol" csv:"symbol"`            // Trading symbol of the instrument.
+	Token       string    `json:"token" csv:"token"`              // Unique identifier for the instrument.
+	Product     string    `json:"product" csv:"product"`          // Product type of the fills.
+	Tag         string    `json:"tag" csv:"tag"`                  // Strategy tag of the opening order.
+	Side        string    `json:"side" csv:"side"`                // "LONG" or "SHORT".
+	Quantity    int64     `json:"quantity" csv:"quantity"`        // Largest open quantity, unsigned.
+	EntryPrice  float64   `json:"entryPrice" csv:"entry_price"`   // Average price of the fills opening or adding to the position.
+	ExitPrice   float64   `json:"exitPrice" csv:"exit_price"`     // Average price of the fills reducing the position.
+	Opened      time.Time `json:"opened" csv:"opened"`            // Time of the first fill.
+	Closed      time.Time `json:"closed" csv:"closed"`            // Time of the fill closing the position; zero while open.
+	Fills       int       `json:"fills" csv:"fills"`              // Number of fills.
+	Turnover    float64   `json:"turnover" csv:"turnover"`        // Traded value of the fills.
+	RealizedPnL float64   `json:"realizedPnL" csv:"realized_pnl"` // P&L before charges.
+	Charges     float64   `json:"charges" csv:"charges"`          // Estimated charges.
+	NetPnL      float64   `json:"netPnL" csv:"net_pnl"`           // P&L after charges.
+}
+
+// Open reports whether the position is still open.
+func (t RoundTrip) Open() bool {
+	return t.Closed.IsZero()
+}
+
+// PnLStats are the P&L and win rate of a group of fills and round trips.
+//
+// Realized P&L, charges and turnover sum the fills of the group, so they include the
+// closing fills of round trips still open. Wins and losses count the closed round trips,
+// by net P&L; round trips closing flat after charges are neither.
+type PnLStats struct {
+	Trades      int     `json:"trades" csv:"trades"`            // Closed round trips.
+	Wins        int     `json:"wins" csv:"wins"`                // Closed round trips with a positive net P&L.
+	Losses      int     `json:"losses" csv:"losses"`            // Closed round trips with a negative net P&L.
+	WinRate     float64 `json:"winRate" csv:"win_rate"`         // Wins as a percentage of closed round trips.
+	Fills       int     `json:"fills" csv:"fills"`              // Number of fills.
+	Turnover    float64 `json:"turnover" csv:"turnover"`        // Traded value.
+	RealizedPnL float64 `json:"realizedPnL" csv:"realized_pnl"` // P&L before charges.
+	Charges     float64 `json:"charges" csv:"charges"`          // Estimated charges.
+	NetPnL      float64 `json:"netPnL" csv:"net_pnl"`           // P&L after charges.
+}
+
+// SymbolPnL is the P&L of an instrument, across products.
+type SymbolPnL struct {
+	Exchange string `json:"exchange" csv:"exchange"` // Exchange of the instrument.
+	Symbol   string `json:"symbol" csv:"symbol"`     // Trading symbol of the instrument.
+	Token    string `json:"token" csv:"token"`       // Unique identifier for the instrument.
+	OpenQty  int64  `json:"openQty" csv:"open_qty"`  // Quantity still open at the last fill; negative for short positions.
+	PnLStats
+}
+
+// DayPnL is the P&L of a trading day. Fills count on the day they executed, round trips on
+// the day they closed.
+type DayPnL struct {
+	Date string `json:"date" csv:"date"` // Trading day in IST, as YYYY-MM-DD.
+	PnLStats
+}
+
+// JournalSummary is the P&L of the whole journal.
+type JournalSummary struct {
+	PnLStats
+	GrossProfit  float64 `json:"grossProfit" csv:"gross_profit"`   // Sum of the net P&L of winning round trips.
+	GrossLoss    float64 `json:"grossLoss" csv:"gross_loss"`       // Sum of the net P&L of losing round trips, negative.
+	ProfitFactor float64 `json:"profitFactor" csv:"profit_factor"` // Gross profit over the absolute gross loss; zero without losses.
+	AverageWin   float64 `json:"averageWin" csv:"average_win"`     // Average net P&L of winning round trips.
+	AverageLoss  float64 `json:"averageLoss" csv:"average_loss"`   // Average net P&L of losing round trips, negative.
+	LargestWin   float64 `json:"largestWin" csv:"largest_win"`     // Largest net P&L of a round trip.
+	LargestLoss  float64 `json:"largestLoss" csv:"largest_loss"`   // Smallest net P&L of a round trip, negative.
+	OpenTrips    int     `json:"openTrips" csv:"open_trips"`       // Round trips still open.
+}
+
+// TradeJournal reports realized P&L, charges and win rates per round trip, instrument and
+// day.
+type TradeJournal struct {
+	RoundTrips []RoundTrip         `json:"roundTrips"` // Closed and open round trips, in order of opening.
+	BySymbol   []SymbolPnL         `json:"bySymbol"`   // P&L per instrument, by symbol.
+	ByDay      []DayPnL            `json:"byDay"`      // P&L per trading day, oldest first.
+	ByTag      map[string]PnLStats `json:"byTag"`      // P&L per strategy tag of the orders opening the round trips.
+	Summary    JournalSummary      `json:"summary"`    // Totals.
+	Charges    ChargeBreakdown     `json:"charges"`    // Itemized charges, with a ChargeSchedule.
+	Issues     []ReconcileIssue    `json:"issues"`     // Duplicate fills and mismatches with the order history.
+}
+
+// BuildTradeJournal replays fills into round trips and aggregates their P&L per
+// instrument, day and strategy tag.
+//
+// Fills are replayed in time order per instrument and product, as in ComputeCostBasis, so
+// trade books of several days can be concatenated; fills repeated across them are counted
+// once. Positions carried from before the first fill are not known, so their closing
+// fills open round trips of their own.
+//
+// Parameters:
+//   - orders: The order history, e.g., from GetOrderBook, used for strategy tags and to
+//     check the fills against; nil to skip the check.
+//   - trades: The fills, e.g., from GetTradeBook.
+//   - charges: The charge model, e.g., a ChargeSchedule, or nil to ignore charges.
+//
+// Returns:
+//   - The journal.
+func BuildTradeJournal(orders []OrderDetail, trades []Trade, charges ChargeModel) TradeJournal {
+	var journal TradeJournal
+	trades, journal.Issues = dedupeFills(trades)
+	if orders != nil {
+		journal.Issues = append(journal.Issues, ReconcileTrades(orders, trades, nil).Issues...)
+	}
+
+	tags := make(map[string]string, len(orders))
+	for _, o := range orders {
+		tags[o.ID] = o.Remarks
+	}
+	itemizer, itemized := charges.(interface{ Estimate(Trade) ChargeBreakdown })
+
+	ordered := append([]Trade(nil), trades...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return fillTime(ordered[i]).Before(fillTime(ordered[j]))
+	})
+
+	type position struct {
+		basis CostBasis
+		trip  *RoundTrip
+		entry float64 // Opening quantity of the round trip.
+		exit  float64 // Reducing quantity of the round trip.
+	}
+	positions := make(map[string]*position)
+	symbols := make(map[string]*SymbolPnL)
+	days := make(map[string]*DayPnL)
+	byTag := make(map[string]*PnLStats)
+	var trips []*RoundTrip
+
+	for _, t := range ordered {
+		qty := parseInt(t.FillShares)
+		if qty == 0 {
+			continue
+		}
+		price := parseFloat(t.FillPrice)
+		at := fillTime(t)
+		fee := 0.0
+		if charges != nil {
+			fee = charges.Charges(t)
+		}
+		if itemized {
+			journal.Charges = journal.Charges.Add(itemizer.Estimate(t))
+		}
+
+		key := t.Token + ":" + t.Product
+		p, ok := positions[key]
+		if !ok {
+			p = &position{}
+			positions[key] = p
+		}
+		before, held := p.basis.RealizedPnL, p.basis.NetQty
+		p.basis.apply(t)
+		realized := p.basis.RealizedPnL - before
+
+		signed := qty
+		if strings.EqualFold(t.TransactionType, string(TransactionSell)) {
+			signed = -qty
+		}
+		closing := int64(0)
+		if held != 0 && (held > 0) != (signed > 0) {
+			closing = min(qty, abs64(held))
+		}
+		opening := qty - closing
+
+		tag := tags[t.ID]
+		if tag == "" {
+			tag = t.Remarks
+		}
+		// Fills count towards the tag of the round trip they close, if any, so that a
+		// strategy is credited with the P&L of the positions it opened.
+		fillTag := tag
+		if closing > 0 {
+			trip := p.trip
+			fillTag = trip.Tag
+			share := float64(closing) / float64(qty)
+			trip.Fills++
+			trip.Turnover += float64(closing) * price
+			trip.RealizedPnL += realized
+			trip.Charges += fee * share
+			p.exit += float64(closing)
+			trip.ExitPrice += (price - trip.ExitPrice) * float64(closing) / p.exit
+			if p.basis.NetQty == 0 || opening > 0 {
+				trip.Closed = at
+				p.trip = nil
+			}
+		}
+		if opening > 0 {
+			if p.trip == nil {
+				side := "LONG"
+				if signed < 0 {
+					side = "SHORT"
+				}
+				p.trip = &RoundTrip{
+					Exchange: t.Exchange,
+					Symbol:   t.Symbol,
+					Token:    t.Token,
+					Product:  t.Product,
+					Tag:      tag,
+					Side:     side,
+					Opened:   at,
+				}
+				p.entry, p.exit = 0, 0
+				trips = append(trips, p.trip)
+			}
+			trip := p.trip
+			share := float64(opening) / float64(qty)
+			trip.Fills++
+			trip.Turnover += float64(opening) * price
+			trip.Charges += fee * share
+			p.entry += float64(opening)
+			trip.EntryPrice += (price - trip.EntryPrice) * float64(opening) / p.entry
+			trip.Quantity = max(trip.Quantity, abs64(p.basis.NetQty))
+		}
+
+		// Fills count towards the instrument, day and tag they belong to.
+		symbolKey := t.Exchange + ":" + t.Token
+		s, ok := symbols[symbolKey]
+		if !ok {
+			s = &SymbolPnL{Exchange: t.Exchange, Symbol: t.Symbol, Token: t.Token}
+			symbols[symbolKey] = s
+		}
+		s.OpenQty += p.basis.NetQty - held
+		day := journalDay(at)
+		d, ok := days[day]
+		if !ok {
+			d = &DayPnL{Date: day}
+			days[day] = d
+		}
+		tagStats, ok := byTag[fillTag]
+		if !ok {
+			tagStats = &PnLStats{}
+			byTag[fillTag] = tagStats
+		}
+		for _, stats := range []*PnLStats{&s.PnLStats, &d.PnLStats, tagStats, &journal.Summary.PnLStats} {
+			stats.addFill(float64(qty)*price, realized, fee)
+		}
+	}
+
+	// Closed round trips count towards their instrument, closing day and tag.
+	for _, trip := range trips {
+		trip.NetPnL = trip.RealizedPnL - trip.Charges
+		journal.RoundTrips = append(journal.RoundTrips, *trip)
+		if trip.Open() {
+			journal.Summary.OpenTrips++
+			continue
+		}
+		s := symbols[trip.Exchange+":"+trip.Token]
+		d := days[journalDay(trip.Closed)]
+		for _, stats := range []*PnLStats{&s.PnLStats, &d.PnLStats, byTag[trip.Tag]} {
+			stats.addTrip(trip.NetPnL)
+		}
+		journal.Summary.addTrip(trip.NetPnL)
+	}
+
+	for _, s := range symbols {
+		s.finish()
+		journal.BySymbol = append(journal.BySymbol, *s)
+	}
+	sort.Slice(journal.BySymbol, func(i, j int) bool {
+		a, b := journal.BySymbol[i], journal.BySymbol[j]
+		if a.Symbol != b.Symbol {
+			return a.Symbol < b.Symbol
+		}
+		return a.Exchange < b.Exchange
+	})
+	for _, d := range days {
+		d.finish()
+		journal.ByDay = append(journal.ByDay, *d)
+	}
+	sort.Slice(journal.ByDay, func(i, j int) bool { return journal.ByDay[i].Date < journal.ByDay[j].Date })
+	journal.ByTag = make(map[string]PnLStats, len(byTag))
+	for tag, stats := range byTag {
+		stats.finish()
+		journal.ByTag[tag] = *stats
+	}
+	journal.Summary.finish()
+	return journal
+}
+
+// GetTradeJournal builds the journal of the day's trade and order books.
+//
+// Parameters:
+//   - charges: The charge model, e.g., ChargeSchedule{}, or nil to ignore charges.
+//
+// Returns:
+//   - The journal if successful.
+//   - An error if either book cannot be retrieved.
+func (c *Client) GetTradeJournal(charges ChargeModel) (*TradeJournal, error) {
+	orders, err := c.getOrderRows()
+	if err != nil {
+		return nil, err
+	}
+	trades, err := c.GetTradeBook()
+	if err != nil {
+		return nil, err
+	}
+
+	journal := BuildTradeJournal(orders, trades, charges)
+	c.log().Info().
+		Int("roundTrips", len(journal.RoundTrips)).
+		Float64("winRate", journal.Summary.WinRate).
+		Float64("netPnL", journal.Summary.NetPnL).
+		Msg("Trade journal built")
+	return &journal, nil
+}
+
+// WriteFiles writes the journal to dir as four files: journal_trips.<ext>,
+// journal_symbols.<ext>, journal_days.<ext> and journal_summary.<ext>.
+//
+// Parameters:
+//   - dir: Directory in which the files are created.
+//   - format: ExportCSV or ExportJSON.
+//
+// Returns:
+//   - An error if writing any of the files fails; otherwise, nil.
+func (j *TradeJournal) WriteFiles(dir string, format ExportFormat) error {
+	if format != ExportCSV && format != ExportJSON {
+		return fmt.Errorf("unsupported export format: %s", format)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	files := []struct {
+		name string
+		rows interface{}
+	}{
+		{"journal_trips", j.RoundTrips},
+		{"journal_symbols", j.BySymbol},
+		{"journal_days", j.ByDay},
+		{"journal_summary", []JournalSummary{j.Summary}},
+	}
+	for _, f := range files {
+		path := filepath.Join(dir, f.name+"."+string(format))
+		if err := writeExportFile(path, format, f.rows); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// addFill adds a fill to the stats.
+func (s *PnLStats) addFill(turnover, realized, charges float64) {
+	s.Fills++
+	s.Turnover += turnover
+	s.RealizedPnL += realized
+	s.Charges += charges
+}
+
+// addTrip adds a closed round trip to the stats.
+func (s *PnLStats) addTrip(net float64) {
+	s.Trades++
+	switch {
+	case net > 0:
+		s.Wins++
+	case net < 0:
+		s.Losses++
+	}
+}
+
+// finish computes the derived fields of the stats.
+func (s *PnLStats) finish() {
+	s.NetPnL = s.RealizedPnL - s.Charges
+	if s.Trades > 0 {
+		s.WinRate = float64(s.Wins) / float64(s.Trades) * 100
+	}
+}
+
+// addTrip adds a closed round trip to the summary.
+func (s *JournalSummary) addTrip(net float64) {
+	s.PnLStats.addTrip(net)
+	switch {
+	case net > 0:
+		s.GrossProfit += net
+		s.LargestWin = max(s.LargestWin, net)
+	case net < 0:
+		s.GrossLoss += net
+		s.LargestLoss = min(s.LargestLoss, net)
+	}
+}
+
+// finish computes the derived fields of the summary.
+func (s *JournalSummary) finish() {
+	s.PnLStats.finish()
+	if s.Wins > 0 {
+		s.AverageWin = s.GrossProfit / float64(s.Wins)
+	}
+	if s.Losses > 0 {
+		s.AverageLoss = s.GrossLoss / float64(s.Losses)
+		s.ProfitFactor = s.GrossProfit / -s.GrossLoss
+	}
+}
+
+// dedupeFills drops the repeated copies of fills, keyed by exchange and fill ID, and
+// reports them.
+func dedupeFills(trades []Trade) ([]Trade, []ReconcileIssue) {
+	seen := make(map[string]bool, len(trades))
+	unique := make([]Trade, 0, len(trades))
+	var issues []ReconcileIssue
+	for _, t := range trades {
+		key := t.Exchange + ":" + t.FillID
+		if t.FillID == "" {
+			key = strings.Join([]string{t.ID, t.FillTime, t.FillShares, t.FillPrice}, ":")
+		}
+		if seen[key] {
+			issues = append(issues, ReconcileIssue{
+				Kind:    IssueDuplicateFill,
+				OrderID: t.ID,
+				FillID:  t.FillID,
+				Detail:  fmt.Sprintf("fill %s of %s appears more than once", t.FillID, t.Symbol),
+			})
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, t)
+	}
+	return unique, issues
+}
+
+// journalDay returns the trading day of a fill, or an empty string for fills without a
+// parsable time.
+func journalDay(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return dayKey(t)
+}
@@ -0,0 +1,218 @@
+package tiqs
+
+import (
+	"io"
+	"strings"
+	"time"
+)
+
+// LedgerEntryKind classifies a funds ledger entry.
+type LedgerEntryKind string
+
+const (
+	LedgerPayin      LedgerEntryKind = "PAYIN"      // Funds added to the trading account.
+	LedgerPayout     LedgerEntryKind = "PAYOUT"     // Funds withdrawn to the bank account.
+	LedgerSettlement LedgerEntryKind = "SETTLEMENT" // Buy or sell obligations of trades.
+	LedgerCharges    LedgerEntryKind = "CHARGES"    // Brokerage, taxes, DP charges and other fees.
+	LedgerInterest   LedgerEntryKind = "INTEREST"   // Interest or delayed payment charges.
+	LedgerOpening    LedgerEntryKind = "OPENING"    // Opening balance of the statement.
+	LedgerOther      LedgerEntryKind = "OTHER"      // Journal entries and anything not classified above.
+)
+
+// ledgerRow is a ledger entry as returned by the API.
+type ledgerRow struct {
+	Date        string `json:"date"`        // Posting date of the entry.
+	VoucherNo   string `json:"voucherNo"`   // Voucher number of the entry.
+	VoucherType string `json:"voucherType"` // Voucher type (e.g., "Bank Receipt", "Bill").
+	Narration   string `json:"narration"`   // Description of the entry.
+	Debit       string `json:"debit"`       // Amount debited from the account.
+	Credit      string `json:"credit"`      // Amount credited to the account.
+	Balance     string `json:"balance"`     // Running balance after the entry.
+}
+
+// LedgerResponse represents the API response containing the funds ledger.
+type LedgerResponse struct {
+	Status string      `json:"status"` // API response status (e.g., "success" or "error").
+	Data   []ledgerRow `json:"data"`   // Ledger entries in posting order.
+}
+
+// LedgerEntry is a typed entry of the funds ledger.
+type LedgerEntry struct {
+	Date      time.Time       // Posting date in IST.
+	Voucher   string          // Voucher number of the entry.
+	Type      string          // Voucher type as reported by the broker.
+	Narration string          // Description of the entry.
+	Kind      LedgerEntryKind // Classification of the entry.
+	Debit     float64         // Amount debited from the account, in rupees.
+	Credit    float64         // Amount credited to the account, in rupees.
+	Balance   float64         // Running balance after the entry, in rupees.
+}
+
+// Amount returns the signed amount of the entry: positive for credits, negative for debits.
+func (e LedgerEntry) Amount() float64 {
+	return e.Credit - e.Debit
+}
+
+// AccountStatement is the funds ledger of a date range.
+type AccountStatement struct {
+	From           time.Time     // First day of the statement.
+	To             time.Time     // Last day of the statement.
+	Entries        []LedgerEntry // Entries in posting order.
+	OpeningBalance float64       // Balance before the first entry.
+	ClosingBalance float64       // Balance after the last entry.
+}
+
+// Totals returns the net amount of the entries of each kind, e.g., to compare payins and
+// payouts with bank records.
+func (s *AccountStatement) Totals() map[LedgerEntryKind]float64 {
+	totals := make(map[LedgerEntryKind]float64)
+	for _, e := range s.Entries {
+		if e.Kind != LedgerOpening {
+			totals[e.Kind] += e.Amount()
+		}
+	}
+	return totals
+}
+
+// Filter returns the entries of the given kinds.
+func (s *AccountStatement) Filter(kinds ...LedgerEntryKind) []LedgerEntry {
+	var entries []LedgerEntry
+	for _, e := range s.Entries {
+		for _, kind := range kinds {
+			if e.Kind == kind {
+				entries = append(entries, e)
+				break
+			}
+		}
+	}
+	return entries
+}
+
+// ledgerKeywords maps lowercase keywords of narrations and voucher types to entry kinds,
+// checked in order.
+var ledgerKeywords = []struct {
+	keywords []string
+	kind     LedgerEntryKind
+}{
+	{[]string{"opening balance"}, LedgerOpening},
+	{[]string{"payout", "withdraw", "bank payment"}, LedgerPayout},
+	{[]string{"payin", "pay-in", "funds added", "bank receipt", "upi", "neft", "imps", "rtgs"}, LedgerPayin},
+	{[]string{"interest", "dpc", "delayed payment"}, LedgerInterest},
+	{[]string{"brokerage", "charges", "gst", "stamp", "stt", "amc", "dp charge", "fee"}, LedgerCharges},
+	{[]string{"bill", "settlement", "obligation", "net amount", "trade"}, LedgerSettlement},
+}
+
+// classifyLedgerEntry guesses the kind of an entry from its voucher type and narration.
+func classifyLedgerEntry(voucherType, narration string) LedgerEntryKind {
+	text := strings.ToLower(voucherType + " " + narration)
+	for _, rule := range ledgerKeywords {
+		if containsAny(text, rule.keywords...) {
+			return rule.kind
+		}
+	}
+	return LedgerOther
+}
+
+// GetAccountStatement retrieves the funds ledger for a date range.
+//
+// It sends a GET request to the "/user/ledger" endpoint and converts every entry into a
+// typed LedgerEntry classified as payin, payout, settlement, charges, interest or other.
+//
+// Parameters:
+//   - from: The first day of the statement.
+//   - to: The last day of the statement.
+//
+// Returns:
+//   - A pointer to the AccountStatement if successful.
+//   - An error if the request fails or the response cannot be parsed.
+func (c *Client) GetAccountStatement(from, to time.Time) (*AccountStatement, error) {
+	endpoint := c.endpoint(EndpointLedger, from.In(IST).Format("2006-01-02"), to.In(IST).Format("2006-01-02"))
+
+	resp, err := c.request(endpoint, "GET", nil)
+	if err != nil {
+		c.log().Error().Err(err).Msg("Failed to fetch account statement")
+		return nil, err
+	}
+
+	var result LedgerResponse
+	if err := c.decode(EndpointLedger, resp, &result); err != nil {
+		c.log().Error().Err(err).Msg("Failed to parse account statement response")
+		return nil, err
+	}
+
+	if result.Status != "success" {
+		return nil, newAPIError("account statement retrieval", endpoint, 0, resp)
+	}
+
+	statement := &AccountStatement{
+		From:    from,
+		To:      to,
+		Entries: make([]LedgerEntry, 0, len(result.Data)),
+	}
+	for i, row := range result.Data {
+		date, _ := parseTimestamp(row.Date)
+		entry := LedgerEntry{
+			Date:      date,
+			Voucher:   row.VoucherNo,
+			Type:      row.VoucherType,
+			Narration: row.Narration,
+			Kind:      classifyLedgerEntry(row.VoucherType, row.Narration),
+			Debit:     parseFloat(row.Debit),
+			Credit:    parseFloat(row.Credit),
+			Balance:   parseFloat(row.Balance),
+		}
+		if i == 0 {
+			statement.OpeningBalance = entry.Balance - entry.Amount()
+		}
+		statement.ClosingBalance = entry.Balance
+		statement.Entries = append(statement.Entries, entry)
+	}
+
+	c.log().Info().Int("entries", len(statement.Entries)).Msg("Account statement retrieved successfully")
+	return statement, nil
+}
+
+// LedgerExportRow is the typed, export-friendly representation of a ledger entry.
+type LedgerExportRow struct {
+	Date      string  `csv:"date" json:"date"`
+	Voucher   string  `csv:"voucher" json:"voucher"`
+	Type      string  `csv:"type" json:"type"`
+	Kind      string  `csv:"kind" json:"kind"`
+	Narration string  `csv:"narration" json:"narration"`
+	Debit     float64 `csv:"debit" json:"debit"`
+	Credit    float64 `csv:"credit" json:"credit"`
+	Balance   float64 `csv:"balance" json:"balance"`
+}
+
+// LedgerExportRows converts ledger entries into typed export rows.
+func LedgerExportRows(entries []LedgerEntry) []LedgerExportRow {
+	rows := make([]LedgerExportRow, len(entries))
+	for i, e := range entries {
+		date := ""
+		if !e.Date.IsZero() {
+			date = e.Date.In(IST).Format("2006-01-02")
+		}
+		rows[i] = LedgerExportRow{
+			Date:      date,
+			Voucher:   e.Voucher,
+			Type:      e.Type,
+			Kind:      string(e.Kind),
+			Narration: e.Narration,
+			Debit:     e.Debit,
+			Credit:    e.Credit,
+			Balance:   e.Balance,
+		}
+	}
+	return rows
+}
+
+// WriteCSV writes the entries of the statement to w as CSV with a header row.
+//
+// Parameters:
+//   - w: Destination writer.
+//
+// Returns:
+//   - An error if the rows cannot be written; otherwise, nil.
+func (s *AccountStatement) WriteCSV(w io.Writer) error {
+	return WriteExport(w, ExportCSV, LedgerExportRows(s.Entries))
+}
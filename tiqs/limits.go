@@ -1,11 +1,6 @@
 package tiqs
 
-import (
-	"encoding/json"
-	"fmt"
-
-	"github.com/rs/zerolog/log"
-)
+import ()
 
 // Limits represents the trading limits and margin details for a user.
 type Limits struct {
@@ -130,24 +125,24 @@ type Limits struct {
 //   - A pointer to a Limits struct containing the trading limits if successful.
 //   - An error if the request fails or the response cannot be parsed.
 func (c *Client) GetLimits() (*Limits, error) {
-	endpoint := "/user/limits"
+	endpoint := c.endpoint(EndpointLimits)
 
 	resp, err := c.request(endpoint, "GET", nil)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to fetch trading limits")
+		c.log().Error().Err(err).Msg("Failed to fetch trading limits")
 		return nil, err
 	}
 
 	var result Limits
-	if err := json.Unmarshal(resp, &result); err != nil {
-		log.Error().Err(err).Msg("Failed to parse trading limits response")
+	if err := c.decode(EndpointLimits, resp, &result); err != nil {
+		c.log().Error().Err(err).Msg("Failed to parse trading limits response")
 		return nil, err
 	}
 
 	if result.Status != "success" {
-		return nil, fmt.Errorf("failed to retrieve trading limits")
+		return nil, newAPIError("trading limits retrieval", endpoint, 0, resp)
 	}
 
-	log.Info().Msg("Trading limits retrieved successfully")
+	c.log().Info().Msg("Trading limits retrieved successfully")
 	return &result, nil
 }
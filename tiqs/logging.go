@@ -0,0 +1,140 @@
+package tiqs
+
+import (
+	"io"
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	"github.com/Abhi13027/go-tiqs/ticks"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// Logger receives the log events of the SDK, the same interface as the WebSocket clients
+// of package ticks. Implement it to route the events to a logging library other than
+// zerolog, or wrap a zerolog logger with NewZerologLogger.
+type Logger = ticks.Logger
+
+// NewZerologLogger returns a Logger writing to a zerolog logger, the logging library of
+// the SDK.
+//
+// Parameters:
+//   - logger: The zerolog logger, e.g., zerolog.New(NewRedactingWriter(os.Stderr)).
+//
+// Returns:
+//   - The Logger, written to without decoding the events.
+func NewZerologLogger(logger zerolog.Logger) Logger {
+	return ticks.NewZerologLogger(logger)
+}
+
+// DefaultRedactedFields are the log fields masked by a RedactingWriter created without
+// fields. Instrument tokens, logged as "token", are not sensitive and are left as is.
+var DefaultRedactedFields = []string{
+	"accessToken", "access_token", "refreshToken", "refresh_token", "authorization",
+	"appSecret", "secret", "password", "pin", "totp", "totpSecret", "checksum", "session",
+}
+
+// defaultLogger is the logger set with SetDefaultLogger, nil for the global zerolog logger.
+var defaultLogger atomic.Pointer[zerolog.Logger]
+
+// SetDefaultLogger sets the logger of clients without their own logger (see
+// Client.SetLogger) and of the components not bound to a client, such as AlertEngine,
+// HealthMonitor and SymbolControl.
+//
+// Until it is called, the SDK logs with the global zerolog logger (log.Logger).
+//
+// Parameters:
+//   - logger: The logger; nil silences the SDK.
+func SetDefaultLogger(logger Logger) {
+	l := ticks.AsZerolog(logger)
+	defaultLogger.Store(&l)
+}
+
+// defaultLog returns the logger set with SetDefaultLogger, or the global zerolog logger.
+func defaultLog() *zerolog.Logger {
+	if logger := defaultLogger.Load(); logger != nil {
+		return logger
+	}
+	return &log.Logger
+}
+
+// SetLogger sets the logger of the client, used for its requests and by the components
+// bound to it, such as RiskManager, GTTEngine and Session.
+//
+// Order requests are logged at info level by order number only; their payloads are
+// logged at debug level.
+//
+// Parameters:
+//   - logger: The logger, e.g., NewZerologLogger(zerolog.New(NewRedactingWriter(os.Stderr)));
+//     nil silences the client.
+func (c *Client) SetLogger(logger Logger) {
+	l := ticks.AsZerolog(logger)
+	c.logger = &l
+}
+
+// SetLogLevel sets the minimum level of the events logged by the client.
+//
+// Parameters:
+//   - level: The level (e.g., zerolog.WarnLevel); zerolog.Disabled silences the client.
+func (c *Client) SetLogLevel(level zerolog.Level) {
+	logger := c.log().Level(level)
+	c.logger = &logger
+}
+
+// DisableLogging silences the client.
+func (c *Client) DisableLogging() {
+	c.SetLogger(nil)
+}
+
+// log returns the logger of the client, or the default logger if it has none or c is nil.
+func (c *Client) log() *zerolog.Logger {
+	if c != nil && c.logger != nil {
+		return c.logger
+	}
+	return defaultLog()
+}
+
+// RedactingWriter masks the values of sensitive fields in the JSON log events written
+// through it, so that credentials do not reach log files or collectors.
+//
+// Wrap the output of a zerolog logger with it, before any console formatting:
+//
+//	logger := zerolog.New(tiqs.NewRedactingWriter(zerolog.ConsoleWriter{Out: os.Stderr}))
+type RedactingWriter struct {
+	out     io.Writer
+	pattern *regexp.Regexp
+}
+
+// redactedValue replaces the values of redacted fields.
+const redactedValue = "[REDACTED]"
+
+// NewRedactingWriter creates a writer masking fields of the events written to out.
+//
+// Parameters:
+//   - out: The destination of the events.
+//   - fields: The names of the fields to mask, ignoring case; DefaultRedactedFields if none.
+//
+// Returns:
+//   - A pointer to a newly created RedactingWriter.
+func NewRedactingWriter(out io.Writer, fields ...string) *RedactingWriter {
+	if len(fields) == 0 {
+		fields = DefaultRedactedFields
+	}
+	quoted := make([]string, len(fields))
+	for i, field := range fields {
+		quoted[i] = regexp.QuoteMeta(field)
+	}
+	// Match string and scalar values; escaped quotes do not end a string.
+	pattern := regexp.MustCompile(`"((?i:` + strings.Join(quoted, "|") + `))"\s*:\s*("(?:[^"\\]|\\.)*"|[^,}\s]+)`)
+	return &RedactingWriter{out: out, pattern: pattern}
+}
+
+// Write writes p to the underlying writer with the sensitive fields masked.
+func (w *RedactingWriter) Write(p []byte) (int, error) {
+	masked := w.pattern.ReplaceAll(p, []byte(`"$1":"`+redactedValue+`"`))
+	if _, err := w.out.Write(masked); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
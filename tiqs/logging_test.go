@@ -0,0 +1,122 @@
+package tiqs_test
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/Abhi13027/go-tiqs/tiqs"
+	"github.com/rs/zerolog"
+)
+
+func TestRedactingWriter(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields []string
+		event  string
+		want   string
+	}{
+		{
+			"default fields",
+			nil,
+			`{"level":"info","accessToken":"eyJhbGciOi","password":"hunter2","token":"35001","message":"login"}`,
+			`{"level":"info","accessToken":"[REDACTED]","password":"[REDACTED]","token":"35001","message":"login"}`,
+		},
+		{
+			"case and spacing",
+			nil,
+			`{"Password" : "hunter2","PIN":1234}`,
+			`{"Password":"[REDACTED]","PIN":"[REDACTED]"}`,
+		},
+		{
+			"escaped quotes",
+			nil,
+			`{"password":"a\"b,c","user":"x"}`,
+			`{"password":"[REDACTED]","user":"x"}`,
+		},
+		{
+			"explicit fields",
+			[]string{"token", "password"},
+			`{"token":"abc123","password":"hunter2","accessToken":"eyJhbGciOi"}`,
+			`{"token":"[REDACTED]","password":"[REDACTED]","accessToken":"eyJhbGciOi"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			w := tiqs.NewRedactingWriter(&out, tt.fields...)
+			n, err := w.Write([]byte(tt.event))
+			if err != nil || n != len(tt.event) {
+				t.Fatalf("Write = %d, %v, want %d, nil", n, err, len(tt.event))
+			}
+			if got := out.String(); got != tt.want {
+				t.Fatalf("got  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRedactingWriterZerolog checks the masking of the events of a zerolog logger.
+func TestRedactingWriterZerolog(t *testing.T) {
+	var out bytes.Buffer
+	logger := zerolog.New(tiqs.NewRedactingWriter(&out, "token", "password"))
+	logger.Info().Str("token", "abc123").Str("password", "hunter2").Str("symbol", "NIFTY").Msg("login")
+
+	want := `{"level":"info","token":"[REDACTED]","password":"[REDACTED]","symbol":"NIFTY","message":"login"}` + "\n"
+	if got := out.String(); got != want {
+		t.Fatalf("got  %s\nwant %s", got, want)
+	}
+}
+
+// logEvent is an event received by a recordingLogger.
+type logEvent struct {
+	level  zerolog.Level
+	msg    string
+	fields map[string]any
+}
+
+// recordingLogger is a Logger that is not backed by zerolog.
+type recordingLogger struct {
+	mu     sync.Mutex
+	events []logEvent
+}
+
+func (l *recordingLogger) Log(level zerolog.Level, msg string, fields map[string]any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, logEvent{level, msg, fields})
+}
+
+func TestClientSetLogger(t *testing.T) {
+	client, _ := newTestClient(t)
+	logger := &recordingLogger{}
+	client.SetLogger(logger)
+
+	if _, err := client.PlaceOrder("regular", order(tiqs.TransactionBuy, "75", tiqs.ProductMIS)); err != nil {
+		t.Fatal(err)
+	}
+
+	var placed *logEvent
+	for i, e := range logger.events {
+		if e.msg == "Order placed successfully" {
+			placed = &logger.events[i]
+		}
+	}
+	if placed == nil {
+		t.Fatalf("no order event in %+v", logger.events)
+	}
+	if placed.level != zerolog.InfoLevel || placed.fields["orderNo"] != "24121900000123" {
+		t.Fatalf("order event = %+v, want info with orderNo 24121900000123", *placed)
+	}
+
+	// The level set on the client filters the events before they reach the Logger
+	logger.events = nil
+	client.SetLogLevel(zerolog.WarnLevel)
+	if _, err := client.PlaceOrder("regular", order(tiqs.TransactionSell, "75", tiqs.ProductMIS)); err != nil {
+		t.Fatal(err)
+	}
+	if len(logger.events) != 0 {
+		t.Fatalf("events below warn level logged: %+v", logger.events)
+	}
+}
@@ -2,20 +2,19 @@ package tiqs
 
 import (
 	"encoding/json"
-
-	"github.com/rs/zerolog/log"
+	"fmt"
 )
 
 // MarginRequest represents the structure for a single order margin request.
 type MarginRequest struct {
-	Exchange        string `json:"exchange"`        // Exchange where the order is placed (e.g., NSE, BSE).
-	Token           string `json:"token"`           // Unique identifier for the instrument.
-	Quantity        string `json:"quantity"`        // Order quantity.
-	Product         string `json:"product"`         // Product type (e.g., MIS, CNC, NRML).
-	Price           string `json:"price"`           // Order price (applicable for LIMIT orders).
-	TransactionType string `json:"transactionType"` // Order transaction type (BUY/SELL).
-	OrderType       string `json:"order"`           // Type of order (e.g., MARKET, LIMIT).
-	Symbol          string `json:"symbol"`          // Trading symbol of the instrument.
+	Exchange        Exchange        `json:"exchange"`        // Exchange where the order is placed (e.g., NSE, BSE).
+	Token           string          `json:"token"`           // Unique identifier for the instrument.
+	Quantity        string          `json:"quantity"`        // Order quantity.
+	Product         Product         `json:"product"`         // Product type (e.g., MIS, CNC, NRML).
+	Price           string          `json:"price"`           // Order price (applicable for LIMIT orders).
+	TransactionType TransactionType `json:"transactionType"` // Order transaction type (BUY/SELL).
+	OrderType       OrderType       `json:"order"`           // Type of order (e.g., MARKET, LIMIT).
+	Symbol          string          `json:"symbol"`          // Trading symbol of the instrument.
 }
 
 // BasketMarginRequest represents a collection of margin requests for multiple orders.
@@ -42,8 +41,10 @@ type MarginResponse struct {
 		Total float64 `json:"total"` // Total charge applied.
 	} `json:"charge"`
 
-	Margin     string `json:"margin"`     // Required margin for the order.
-	MarginUsed string `json:"marginUsed"` // Margin already used.
+	Margin     string `json:"margin"`             // Required margin for the order.
+	MarginUsed string `json:"marginUsed"`         // Margin already used.
+	Span       string `json:"span,omitempty"`     // SPAN part of the margin of F&O orders, if reported.
+	Exposure   string `json:"exposure,omitempty"` // Exposure part of the margin of F&O orders, if reported.
 }
 
 // OrderMargin represents the API response for a single order margin request.
@@ -71,28 +72,32 @@ type BasketOrderMargin struct {
 //
 // Returns:
 //   - A pointer to an OrderMargin struct with margin details if successful.
-//   - An error if the request fails or the response cannot be parsed.
+//   - An error if the order is invalid for its segment, the request fails or the response cannot be parsed.
 func (c *Client) GetMargin(order MarginRequest) (*OrderMargin, error) {
-	endpoint := "/margin/order"
+	endpoint := c.endpoint(EndpointOrderMargin)
+
+	if err := c.validateSegment(order.orderRequest()); err != nil {
+		return nil, err
+	}
 
 	// Convert order details into JSON payload.
 	payload, err := json.Marshal(order)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to serialize margin request")
+		c.log().Error().Err(err).Msg("Failed to serialize margin request")
 		return nil, err
 	}
 
 	// Send the request to the API.
 	resp, err := c.request(endpoint, "POST", []byte(payload))
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to fetch margin")
+		c.log().Error().Err(err).Msg("Failed to fetch margin")
 		return nil, err
 	}
 
 	// Parse the JSON response into the OrderMargin struct.
 	var result OrderMargin
-	if err := json.Unmarshal(resp, &result); err != nil {
-		log.Error().Err(err).Msg("Failed to parse margin response")
+	if err := c.decode(EndpointOrderMargin, resp, &result); err != nil {
+		c.log().Error().Err(err).Msg("Failed to parse margin response")
 		return nil, err
 	}
 
@@ -102,37 +107,44 @@ func (c *Client) GetMargin(order MarginRequest) (*OrderMargin, error) {
 // GetBasketMargin fetches the margin details for multiple orders.
 //
 // This function sends a POST request to the "/margin/basket" endpoint with a collection
-// of orders to calculate the combined margin requirements.
+// of orders to calculate the combined margin requirements. GetBasketMarginBreakdown adds
+// the margin of every leg and the hedge benefit.
 //
 // Parameters:
 //   - order: A BasketMarginRequest struct containing multiple orders.
 //
 // Returns:
 //   - A pointer to a BasketOrderMargin struct with total margin details if successful.
-//   - An error if the request fails or the response cannot be parsed.
+//   - An error if an order is invalid for its segment, the request fails or the response cannot be parsed.
 func (c *Client) GetBasketMargin(order BasketMarginRequest) (*BasketOrderMargin, error) {
-	endpoint := "/margin/basket"
+	endpoint := c.endpoint(EndpointBasketMargin)
+
+	for i, leg := range order {
+		if err := c.validateSegment(leg.orderRequest()); err != nil {
+			return nil, fmt.Errorf("basket order %d: %w", i, err)
+		}
+	}
 
 	// Convert order details into JSON payload.
 	payload, err := json.Marshal(order)
-	log.Info().Msgf("Payload: %s", payload) // Log the payload for debugging.
+	c.log().Info().Msgf("Payload: %s", payload) // Log the payload for debugging.
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to serialize margin request")
+		c.log().Error().Err(err).Msg("Failed to serialize margin request")
 		return nil, err
 	}
 
 	// Send the request to the API.
 	resp, err := c.request(endpoint, "POST", []byte(payload))
-	log.Info().Msgf("Response: %s", resp) // Log the response for debugging.
+	c.log().Info().Msgf("Response: %s", resp) // Log the response for debugging.
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to fetch margin")
+		c.log().Error().Err(err).Msg("Failed to fetch margin")
 		return nil, err
 	}
 
 	// Parse the JSON response into the BasketOrderMargin struct.
 	var result BasketOrderMargin
-	if err := json.Unmarshal(resp, &result); err != nil {
-		log.Error().Err(err).Msg("Failed to parse margin response")
+	if err := c.decode(EndpointBasketMargin, resp, &result); err != nil {
+		c.log().Error().Err(err).Msg("Failed to parse margin response")
 		return nil, err
 	}
 
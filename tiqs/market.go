@@ -3,9 +3,6 @@ package tiqs
 
 import (
 	"encoding/json"
-	"fmt"
-
-	"github.com/rs/zerolog/log"
 )
 
 // MarketQuote represents the response structure for market quotes.
@@ -23,10 +20,94 @@ type MarketQuote struct {
 	Status       string `json:"status"`       // API response status (e.g., "success" or "error").
 }
 
+// LTPQuote is the quote returned in "ltp" mode. Prices are integers scaled by the
+// instrument's precision, as in MarketQuote.
+type LTPQuote struct {
+	Token int64 `json:"token"` // Unique identifier for the instrument.
+	LTP   int64 `json:"ltp"`   // Last traded price of the instrument.
+}
+
+// OHLCQuote is the session summary of an instrument, the common part of the quotes
+// returned in "full" and "depth" modes.
+type OHLCQuote struct {
+	LTPQuote
+	Open         int64 `json:"open"`         // Opening price of the instrument for the trading session.
+	High         int64 `json:"high"`         // Highest price of the instrument in the current session.
+	Low          int64 `json:"low"`          // Lowest price of the instrument in the current session.
+	Close        int64 `json:"close"`        // Closing price of the instrument from the previous session.
+	Volume       int64 `json:"volume"`       // Total traded volume of the instrument.
+	TotalBuyQty  int64 `json:"totalBuyQty"`  // Total quantity of buy orders in the market.
+	TotalSellQty int64 `json:"totalSellQty"` // Total quantity of sell orders in the market.
+	LTT          int64 `json:"ltt"`          // Last trade time of the instrument (epoch timestamp).
+}
+
+// QuoteLevel is one price level of the market depth of a quote.
+type QuoteLevel struct {
+	Price    int64 `json:"price"`    // Price of the level.
+	Quantity int64 `json:"quantity"` // Total quantity at the price.
+	Orders   int64 `json:"orders"`   // Number of orders at the price.
+}
+
+// MarketDepth holds the best bids and offers of a quote, best price first.
+type MarketDepth struct {
+	Bids []QuoteLevel `json:"bids"` // Buy orders, highest price first.
+	Asks []QuoteLevel `json:"asks"` // Sell orders, lowest price first.
+}
+
+// FullQuote is the quote returned in "full" and "depth" modes, with every field the API
+// sends: the session summary, open interest, price bands, 52-week range and depth.
+type FullQuote struct {
+	OHLCQuote
+	LTQ        int64       `json:"ltq"`        // Quantity of the last trade.
+	AvgPrice   int64       `json:"avgPrice"`   // Volume-weighted average price of the session.
+	NetChange  int64       `json:"netChange"`  // Change of the LTP from the previous close.
+	OI         int64       `json:"oi"`         // Open interest, for derivatives.
+	OIDayHigh  int64       `json:"oiDayHigh"`  // Highest open interest of the session.
+	OIDayLow   int64       `json:"oiDayLow"`   // Lowest open interest of the session.
+	LowerLimit int64       `json:"lowerLimit"` // Lower circuit limit.
+	UpperLimit int64       `json:"upperLimit"` // Upper circuit limit.
+	High52Week int64       `json:"high52Week"` // Highest price of the last 52 weeks.
+	Low52Week  int64       `json:"low52Week"`  // Lowest price of the last 52 weeks.
+	Depth      MarketDepth `json:"depth"`      // Best bids and offers.
+}
+
+// MarketQuote returns the fields of the quote held by a MarketQuote.
+func (q OHLCQuote) MarketQuote() MarketQuote {
+	return MarketQuote{
+		Token:        q.Token,
+		LTP:          q.LTP,
+		Open:         q.Open,
+		High:         q.High,
+		Low:          q.Low,
+		Close:        q.Close,
+		Volume:       q.Volume,
+		TotalBuyQty:  q.TotalBuyQty,
+		TotalSellQty: q.TotalSellQty,
+		LTT:          q.LTT,
+	}
+}
+
+// BestBid returns the best bid of the quote, and false if there is none.
+func (q FullQuote) BestBid() (QuoteLevel, bool) {
+	if len(q.Depth.Bids) == 0 || q.Depth.Bids[0].Quantity == 0 {
+		return QuoteLevel{}, false
+	}
+	return q.Depth.Bids[0], true
+}
+
+// BestAsk returns the best offer of the quote, and false if there is none.
+func (q FullQuote) BestAsk() (QuoteLevel, bool) {
+	if len(q.Depth.Asks) == 0 || q.Depth.Asks[0].Quantity == 0 {
+		return QuoteLevel{}, false
+	}
+	return q.Depth.Asks[0], true
+}
+
 // GetMarketQuote fetches market data for a single instrument.
 //
 // It sends a POST request to the "/info/quote/{mode}" endpoint to retrieve
-// the latest market details for a given token.
+// the latest market details for a given token. Only the fields common to every mode are
+// kept; use GetFullQuote for the depth, open interest and price bands.
 //
 // Parameters:
 //   - token: The unique identifier of the instrument.
@@ -34,42 +115,92 @@ type MarketQuote struct {
 //
 // Returns:
 //   - A pointer to MarketQuote struct containing market data if successful.
-//   - An error if the request fails or the response cannot be parsed.
+//   - An error if the mode or token is invalid, the request fails or the response cannot be parsed.
 func (c *Client) GetMarketQuote(token int64, mode string) (*MarketQuote, error) {
-	endpoint := fmt.Sprintf("/info/quote/%s", mode)
-	payload := fmt.Sprintf(`{"token": %d}`, token)
-
-	// Send a POST request to fetch market data.
-	resp, err := c.request(endpoint, "POST", []byte(payload))
+	quote, err := fetchQuote[MarketQuote](c, token, mode)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to fetch market quote")
 		return nil, err
 	}
+	c.observeQuote(quote)
+	return &quote, nil
+}
 
-	var result struct {
-		Status string      `json:"status"`
-		Data   MarketQuote `json:"data"`
+// GetLTPQuote fetches the last traded price of an instrument in "ltp" mode.
+//
+// Parameters:
+//   - token: The unique identifier of the instrument.
+//
+// Returns:
+//   - A pointer to the LTPQuote if successful.
+//   - An error if the token is invalid, the request fails or the response cannot be parsed.
+func (c *Client) GetLTPQuote(token int64) (*LTPQuote, error) {
+	quote, err := fetchQuote[LTPQuote](c, token, "ltp")
+	if err != nil {
+		return nil, err
 	}
+	return &quote, nil
+}
 
-	// Parse the JSON response into the MarketQuote struct.
-	if err := json.Unmarshal(resp, &result); err != nil {
-		log.Error().Err(err).Msg("Failed to parse market quote response")
+// GetFullQuote fetches every field of the quote of an instrument in "full" mode.
+//
+// Parameters:
+//   - token: The unique identifier of the instrument.
+//
+// Returns:
+//   - A pointer to the FullQuote if successful.
+//   - An error if the token is invalid, the request fails or the response cannot be parsed.
+func (c *Client) GetFullQuote(token int64) (*FullQuote, error) {
+	quote, err := fetchQuote[FullQuote](c, token, "full")
+	if err != nil {
 		return nil, err
 	}
+	c.observeQuote(quote.MarketQuote())
+	return &quote, nil
+}
+
+// fetchQuote requests the quote of one instrument in a mode and decodes it into T.
+func fetchQuote[T any](c *Client, token int64, mode string) (T, error) {
+	var zero T
+	if err := validateQuote(mode, token); err != nil {
+		return zero, err
+	}
+
+	endpoint := c.endpoint(EndpointQuote, mode)
+	payload, err := json.Marshal(quoteRequest{Token: token})
+	if err != nil {
+		c.log().Error().Err(err).Msg("Failed to serialize market quote request")
+		return zero, err
+	}
+
+	// Send a POST request to fetch market data.
+	resp, err := c.request(endpoint, "POST", payload)
+	if err != nil {
+		c.log().Error().Err(err).Msg("Failed to fetch market quote")
+		return zero, err
+	}
+
+	var result apiResponse[T]
+
+	// Parse the JSON response into the quote struct.
+	if err := c.decode(EndpointQuote, resp, &result); err != nil {
+		c.log().Error().Err(err).Msg("Failed to parse market quote response")
+		return zero, err
+	}
 
 	// Check if the API response status indicates success.
 	if result.Status != "success" {
-		return nil, fmt.Errorf("market data retrieval failed")
+		return zero, newAPIError("market data retrieval", endpoint, 0, resp)
 	}
 
-	log.Info().Int64("token", token).Msg("Market quote retrieved successfully")
-	return &result.Data, nil
+	c.log().Info().Int64("token", token).Msg("Market quote retrieved successfully")
+	return result.Data, nil
 }
 
 // GetMarketQuotes fetches market data for multiple instruments.
 //
 // It sends a POST request to the "/info/quotes/{mode}" endpoint to retrieve
-// market data for a list of tokens.
+// market data for a list of tokens. Only the fields common to every mode are kept; use
+// GetFullQuotes for the depth, open interest and price bands.
 //
 // Parameters:
 //   - tokens: A slice of unique identifiers representing instruments.
@@ -77,43 +208,91 @@ func (c *Client) GetMarketQuote(token int64, mode string) (*MarketQuote, error)
 //
 // Returns:
 //   - A slice of MarketQuote structs containing market data if successful.
-//   - An error if the request fails or the response cannot be parsed.
+//   - An error if the mode or a token is invalid, the request fails or the response cannot be parsed.
 func (c *Client) GetMarketQuotes(tokens []int64, mode string) ([]MarketQuote, error) {
-	endpoint := fmt.Sprintf("/info/quotes/%s", mode)
+	quotes, err := fetchQuotes[MarketQuote](c, tokens, mode)
+	if err != nil {
+		return nil, err
+	}
+	for _, quote := range quotes {
+		c.observeQuote(quote)
+	}
+	return quotes, nil
+}
+
+// GetLTPQuotes fetches the last traded prices of multiple instruments in "ltp" mode.
+//
+// Parameters:
+//   - tokens: A slice of unique identifiers representing instruments.
+//
+// Returns:
+//   - A slice of LTPQuote structs if successful.
+//   - An error if a token is invalid, the request fails or the response cannot be parsed.
+func (c *Client) GetLTPQuotes(tokens []int64) ([]LTPQuote, error) {
+	return fetchQuotes[LTPQuote](c, tokens, "ltp")
+}
+
+// GetFullQuotes fetches every field of the quotes of multiple instruments in "full" mode.
+//
+// Parameters:
+//   - tokens: A slice of unique identifiers representing instruments.
+//
+// Returns:
+//   - A slice of FullQuote structs if successful.
+//   - An error if a token is invalid, the request fails or the response cannot be parsed.
+func (c *Client) GetFullQuotes(tokens []int64) ([]FullQuote, error) {
+	quotes, err := fetchQuotes[FullQuote](c, tokens, "full")
+	if err != nil {
+		return nil, err
+	}
+	for _, quote := range quotes {
+		c.observeQuote(quote.MarketQuote())
+	}
+	return quotes, nil
+}
+
+// fetchQuotes requests the quotes of multiple instruments in a mode and decodes them into T.
+func fetchQuotes[T any](c *Client, tokens []int64, mode string) ([]T, error) {
+	if err := validateQuote(mode, tokens...); err != nil {
+		return nil, err
+	}
+
+	endpoint := c.endpoint(EndpointQuotes, mode)
 
 	// Construct JSON payload for multiple tokens.
-	payload := "["
-	for i, token := range tokens {
-		payload += fmt.Sprintf("%d", token)
-		if i < len(tokens)-1 {
-			payload += ","
-		}
+	payload, err := json.Marshal(tokens)
+	if err != nil {
+		c.log().Error().Err(err).Msg("Failed to serialize market quotes request")
+		return nil, err
 	}
-	payload += "]"
 
 	// Send a POST request to fetch market data for multiple tokens.
-	resp, err := c.request(endpoint, "POST", []byte(payload))
+	resp, err := c.request(endpoint, "POST", payload)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to fetch market quotes")
+		c.log().Error().Err(err).Msg("Failed to fetch market quotes")
 		return nil, err
 	}
 
-	var result struct {
-		Status string        `json:"status"`
-		Data   []MarketQuote `json:"data"`
-	}
+	var result apiResponse[[]T]
 
-	// Parse the JSON response into a slice of MarketQuote structs.
-	if err := json.Unmarshal(resp, &result); err != nil {
-		log.Error().Err(err).Msg("Failed to parse market quotes response")
+	// Parse the JSON response into a slice of quote structs.
+	if err := c.decode(EndpointQuotes, resp, &result); err != nil {
+		c.log().Error().Err(err).Msg("Failed to parse market quotes response")
 		return nil, err
 	}
 
 	// Check if the API response status indicates success.
 	if result.Status != "success" {
-		return nil, fmt.Errorf("market data retrieval failed")
+		return nil, newAPIError("market data retrieval", endpoint, 0, resp)
 	}
 
-	log.Info().Msg("Market quotes retrieved successfully")
+	c.log().Info().Msg("Market quotes retrieved successfully")
 	return result.Data, nil
 }
+
+// observeQuote records the last trade time of a quote in the stale guard, if any.
+func (c *Client) observeQuote(quote MarketQuote) {
+	if c.staleGuard != nil {
+		c.staleGuard.ObserveQuote(quote)
+	}
+}
@@ -0,0 +1,250 @@
+package tiqs
+
+import (
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Abhi13027/go-tiqs/ticks"
+)
+
+// DefaultMTMCooldown is the delay before an MTMMonitor repeats an alert of a position.
+const DefaultMTMCooldown = 5 * time.Minute
+
+// CircuitState tells whether an instrument is locked at one of its price bands.
+type CircuitState string
+
+const (
+	CircuitNone  CircuitState = ""      // Trading within the price band.
+	CircuitUpper CircuitState = "UPPER" // At the upper limit with no sellers.
+	CircuitLower CircuitState = "LOWER" // At the lower limit with no buyers.
+)
+
+// CircuitOf returns the circuit state of an instrument from a full-mode tick: locked at
+// the upper limit when it trades there with no offers, and at the lower limit when it
+// trades there with no bids. Ticks without price bands are never locked.
+func CircuitOf(tick ticks.TickData) CircuitState {
+	switch {
+	case tick.LTP <= 0:
+		return CircuitNone
+	case tick.UpperLimit > 0 && tick.LTP >= tick.UpperLimit && tick.MarketDepth.Asks[0].Quantity == 0:
+		return CircuitUpper
+	case tick.LowerLimit > 0 && tick.LTP <= tick.LowerLimit && tick.MarketDepth.Bids[0].Quantity == 0:
+		return CircuitLower
+	}
+	return CircuitNone
+}
+
+// MTMAlertKind distinguishes a position losing money from a position that cannot be exited.
+type MTMAlertKind string
+
+const (
+	MTMAdverseMove     MTMAlertKind = "ADVERSE_MOVE"      // The loss exceeds the limit; the position can be exited.
+	MTMLockedAtCircuit MTMAlertKind = "LOCKED_AT_CIRCUIT" // The price is locked at a circuit against the position; there is no counterparty to exit.
+)
+
+// HedgeSuggestion is an executable order offsetting a position that cannot be exited.
+type HedgeSuggestion struct {
+	Instrument Instrument      `json:"instrument"` // The derivative to trade.
+	Side       TransactionType `json:"side"`       // The side of the hedge.
+	Quantity   int64           `json:"quantity"`   // The order quantity, in the convention of the instrument's segment.
+	Reason     string          `json:"reason"`     // Why the hedge offsets the position.
+}
+
+// MTMAlert reports a position whose mark-to-market needs attention.
+type MTMAlert struct {
+	Kind          MTMAlertKind      `json:"kind"`          // Why the alert was raised.
+	Position      LivePosition      `json:"position"`      // The position.
+	LTP           float64           `json:"ltp"`           // Last traded price, in rupees.
+	UnrealizedPnL float64           `json:"unrealizedPnL"` // P&L of the open quantity at LTP.
+	Circuit       CircuitState      `json:"circuit"`       // Circuit state of the instrument.
+	LowerLimit    float64           `json:"lowerLimit"`    // Lower price band, in rupees; zero if unknown.
+	UpperLimit    float64           `json:"upperLimit"`    // Upper price band, in rupees; zero if unknown.
+	CanExit       bool              `json:"canExit"`       // Whether an exit order can currently be filled.
+	Hedges        []HedgeSuggestion `json:"hedges"`        // Orders offsetting a locked position, if derivatives are listed.
+	Time          time.Time         `json:"time"`          // Time of the alert.
+}
+
+// MTMMonitor watches the positions of a PositionEngine against live ticks and raises an
+// alert when a position loses more than MaxLoss, or when its instrument is locked at a
+// circuit against it.
+//
+// A position locked at a circuit cannot be exited, so its alert carries hedges in the
+// futures and options of the same underlying (e.g., selling the future or buying a put
+// against a long locked at the lower circuit), found in the client's instrument store.
+// Circuit detection needs full-mode ticks, which carry the price bands and depth.
+type MTMMonitor struct {
+	MaxLoss  float64        // Loss per position, in rupees, raising an adverse move alert; zero disables them.
+	Cooldown time.Duration  // Delay before an alert of the same kind is repeated for a position.
+	OnAlert  func(MTMAlert) // Optional callback for every alert.
+
+	client *Client
+	engine *PositionEngine
+	mu     sync.Mutex
+	last   map[string]time.Time
+}
+
+// NewMTMMonitor creates a monitor with DefaultMTMCooldown.
+//
+// Parameters:
+//   - client: The client whose instrument store resolves prices and hedges.
+//   - engine: The engine holding the positions to watch.
+//   - maxLoss: The loss per position raising an alert, in rupees; zero alerts on circuits only.
+//
+// Returns:
+//   - A pointer to a newly created MTMMonitor.
+func NewMTMMonitor(client *Client, engine *PositionEngine, maxLoss float64) *MTMMonitor {
+	return &MTMMonitor{
+		MaxLoss:  maxLoss,
+		Cooldown: DefaultMTMCooldown,
+		client:   client,
+		engine:   engine,
+		last:     make(map[string]time.Time),
+	}
+}
+
+// Update checks the positions in the tick's instrument and returns the alerts raised.
+func (m *MTMMonitor) Update(tick ticks.TickData) []MTMAlert {
+	token := int64(tick.Token)
+	prices := m.client.PriceConverter()
+	divisor := prices.Divisor(token)
+	ltp := float64(tick.LTP) / divisor
+	if ltp <= 0 {
+		return nil
+	}
+	circuit := CircuitOf(tick)
+
+	var alerts []MTMAlert
+	for _, p := range m.engine.Positions() {
+		if p.Token != token {
+			continue
+		}
+		alert := MTMAlert{
+			Position:      p,
+			LTP:           ltp,
+			UnrealizedPnL: p.UnrealizedPnL(ltp),
+			Circuit:       circuit,
+			LowerLimit:    float64(tick.LowerLimit) / divisor,
+			UpperLimit:    float64(tick.UpperLimit) / divisor,
+			CanExit:       true,
+			Time:          time.Now(),
+		}
+
+		// A long is exited by selling, which needs bids; a short by buying, which needs offers.
+		locked := p.NetQty > 0 && circuit == CircuitLower || p.NetQty < 0 && circuit == CircuitUpper
+		switch {
+		case locked:
+			alert.Kind = MTMLockedAtCircuit
+			alert.CanExit = false
+			alert.Hedges = m.hedges(p, ltp)
+		case m.MaxLoss > 0 && alert.UnrealizedPnL+p.RealizedPnL <= -m.MaxLoss:
+			alert.Kind = MTMAdverseMove
+		default:
+			continue
+		}
+
+		if !m.due(p, alert.Kind, alert.Time) {
+			continue
+		}
+		m.client.log().Warn().
+			Str("kind", string(alert.Kind)).
+			Str("symbol", p.Symbol).
+			Int64("netQty", p.NetQty).
+			Float64("ltp", ltp).
+			Float64("unrealizedPnL", alert.UnrealizedPnL).
+			Int("hedges", len(alert.Hedges)).
+			Msg("MTM alert")
+		if m.OnAlert != nil {
+			m.OnAlert(alert)
+		}
+		alerts = append(alerts, alert)
+	}
+	return alerts
+}
+
+// due reports whether an alert of a position may be raised, recording it if so.
+func (m *MTMMonitor) due(p LivePosition, kind MTMAlertKind, now time.Time) bool {
+	key := positionKey(strconv.FormatInt(p.Token, 10), p.Product) + ":" + string(kind)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if last, ok := m.last[key]; ok && now.Sub(last) < m.Cooldown {
+		return false
+	}
+	m.last[key] = now
+	return true
+}
+
+// hedges returns the futures and options of the position's underlying that offset it:
+// the nearest future on the opposite side and the nearest at-the-money put (for a long)
+// or call (for a short), sized to cover the position.
+func (m *MTMMonitor) hedges(p LivePosition, ltp float64) []HedgeSuggestion {
+	store := m.client.instruments
+	if store == nil {
+		return nil
+	}
+	inst, ok := store.Get(p.Token)
+	if !ok || inst.Symbol == "" {
+		return nil
+	}
+	units := abs64(p.NetQty)
+	now := time.Now()
+
+	var suggestions []HedgeSuggestion
+	if future, ok := nearest(store.Filter(InstrumentFilter{Underlying: inst.Symbol, Instruments: []string{"FUTSTK", "FUTIDX"}}), p.Token, now, 0); ok {
+		side, reason := TransactionSell, "sell the future against a long locked at the lower circuit"
+		if p.NetQty < 0 {
+			side, reason = TransactionBuy, "buy the future against a short locked at the upper circuit"
+		}
+		suggestions = append(suggestions, hedgeSuggestion(future, side, units, reason))
+	}
+
+	optionType, reason := "PE", "buy an at-the-money put against a long locked at the lower circuit"
+	if p.NetQty < 0 {
+		optionType, reason = "CE", "buy an at-the-money call against a short locked at the upper circuit"
+	}
+	options := store.Filter(InstrumentFilter{Underlying: inst.Symbol, OptionType: optionType})
+	if option, ok := nearest(options, p.Token, now, ltp); ok {
+		suggestions = append(suggestions, hedgeSuggestion(option, TransactionBuy, units, reason))
+	}
+	return suggestions
+}
+
+// nearest returns the instrument of the nearest unexpired expiry other than the excluded
+// token, with the strike closest to the given price when it is positive.
+func nearest(instruments []Instrument, exclude int64, now time.Time, strike float64) (Instrument, bool) {
+	var (
+		best       Instrument
+		bestExpiry time.Time
+		found      bool
+	)
+	for _, inst := range instruments {
+		expiry, ok := inst.Expiry()
+		if inst.Token == exclude || !ok || inst.Expired(now) {
+			continue
+		}
+		switch {
+		case !found, expiry.Before(bestExpiry):
+		case expiry.Equal(bestExpiry) && strike > 0 && math.Abs(inst.Strike()-strike) < math.Abs(best.Strike()-strike):
+		default:
+			continue
+		}
+		best, bestExpiry, found = inst, expiry, true
+	}
+	return best, found
+}
+
+// hedgeSuggestion sizes a hedge to cover the units of a position with whole lots.
+func hedgeSuggestion(inst Instrument, side TransactionType, units int64, reason string) HedgeSuggestion {
+	lots := units
+	if inst.LotSize > 0 {
+		lots = (units + inst.LotSize - 1) / inst.LotSize
+	}
+	return HedgeSuggestion{
+		Instrument: inst,
+		Side:       side,
+		Quantity:   inst.OrderQuantity(lots),
+		Reason:     reason,
+	}
+}
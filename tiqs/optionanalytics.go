@@ -0,0 +1,115 @@
+package tiqs
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Abhi13027/go-tiqs/ticks"
+)
+
+// OptionAnalytics holds the implied volatility and Greeks of one option of a chain.
+type OptionAnalytics struct {
+	Token      int64         `json:"token"`      // Token of the option.
+	Symbol     string        `json:"symbol"`     // Trading symbol of the option.
+	OptionType string        `json:"optionType"` // "CE" or "PE".
+	Strike     float64       `json:"strike"`     // Strike price, in rupees.
+	Price      float64       `json:"price"`      // Option price the volatility was solved from, in rupees.
+	Spot       float64       `json:"spot"`       // Underlying price, in rupees.
+	Greeks     *ticks.Greeks `json:"greeks"`     // Implied volatility and Greeks; nil if the option has no price or no volatility matches it.
+}
+
+// EvaluateOptionChain computes the implied volatility and Black-Scholes Greeks of every
+// option of a chain.
+//
+// Options without a price, or whose price is outside the range Black-Scholes can produce
+// (e.g., below the intrinsic value), are returned without Greeks.
+//
+// Parameters:
+//   - chain: The option chain, as returned by GetOptionChain.
+//   - expiry: The expiry of the chain's options.
+//   - spot: The price of the underlying, in rupees.
+//   - prices: The prices of the options by token, in rupees.
+//   - rate: The annual risk-free rate as a fraction.
+//
+// Returns:
+//   - The analytics of every option, in the order of the chain.
+func EvaluateOptionChain(chain *OptionChainResponse, expiry time.Time, spot float64, prices map[int64]float64, rate float64) []OptionAnalytics {
+	years := yearsToExpiry(expiry)
+	analytics := make([]OptionAnalytics, 0, len(chain.Data))
+	for _, option := range chain.Data {
+		a := OptionAnalytics{
+			Token:      parseInt(option.Token),
+			Symbol:     option.Symbol,
+			OptionType: option.OptionType,
+			Strike:     parseFloat(option.StrikePrice),
+			Spot:       spot,
+		}
+		a.Price = prices[a.Token]
+
+		call := a.OptionType == "CE"
+		if iv, ok := ticks.ImpliedVolatility(call, a.Price, spot, a.Strike, years, rate); ok {
+			greeks := ticks.BlackScholesGreeks(call, spot, a.Strike, years, rate, iv)
+			a.Greeks = &greeks
+		}
+		analytics = append(analytics, a)
+	}
+	return analytics
+}
+
+// EvaluateChain computes the Greeks of every option of a chain against the latest LTP of
+// its underlying seen by Update, with the calculator's Rate.
+//
+// Parameters:
+//   - chain: The option chain, as returned by GetOptionChain.
+//   - underlying: The token of the underlying, which must be subscribed on the feed.
+//   - expiry: The expiry of the chain's options.
+//   - prices: The prices of the options by token, in rupees.
+//
+// Returns:
+//   - The analytics of every option, in the order of the chain.
+//   - An error if no tick of the underlying was seen yet.
+func (g *GreeksCalculator) EvaluateChain(chain *OptionChainResponse, underlying int64, expiry time.Time, prices map[int64]float64) ([]OptionAnalytics, error) {
+	g.mu.RLock()
+	spot, ok := g.spots[underlying]
+	g.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("no price of underlying %d", underlying)
+	}
+	return EvaluateOptionChain(chain, expiry, spot, prices, g.Rate), nil
+}
+
+// GetOptionChainGreeks fetches an option chain with the LTPs of its options and of the
+// underlying, and computes the implied volatility and Greeks of every option.
+//
+// Parameters:
+//   - params: The underlying, exchange, strike count and expiry of the chain.
+//
+// Returns:
+//   - The analytics of every option, in the order of the chain.
+//   - An error if the chain or the quotes cannot be fetched, or the underlying has no LTP.
+func (c *Client) GetOptionChainGreeks(params OptionChainParams) ([]OptionAnalytics, error) {
+	chain, err := c.GetOptionChain(params)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := make([]int64, 0, len(chain.Data)+1)
+	tokens = append(tokens, params.Token)
+	for _, option := range chain.Data {
+		tokens = append(tokens, parseInt(option.Token))
+	}
+	quotes, err := c.GetMarketQuotesDecimal(tokens, "ltp")
+	if err != nil {
+		return nil, err
+	}
+
+	prices := make(map[int64]float64, len(quotes))
+	for _, quote := range quotes {
+		prices[quote.Token] = quote.LTP
+	}
+	spot := prices[params.Token]
+	if spot <= 0 {
+		return nil, fmt.Errorf("no price of underlying %d", params.Token)
+	}
+	return EvaluateOptionChain(chain, params.Expiry, spot, prices, defaultRiskFreeRate), nil
+}
@@ -0,0 +1,204 @@
+package tiqs
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// ArchivePolicy controls when an OrderTracker moves terminal orders out of its hot map.
+//
+// Long sessions accumulate thousands of completed, cancelled and rejected orders that are
+// never updated again. Archiving them keeps the maps touched on every update small and
+// reduces GC pressure, while keeping recent orders queryable.
+type ArchivePolicy struct {
+	After       time.Duration // Time an order stays in the hot map after reaching a terminal status.
+	MaxArchived int           // Capacity of the in-memory ring buffer of archived orders; zero keeps none.
+	Path        string        // Optional JSON lines file every archived order is appended to.
+}
+
+// ArchivedOrder is the final state of an order moved to the archive.
+type ArchivedOrder struct {
+	OrderMachine
+	TerminalAt time.Time `json:"terminalAt"` // Time the order reached its terminal status.
+	ArchivedAt time.Time `json:"archivedAt"` // Time the order was archived.
+}
+
+// orderArchive is a ring buffer of archived orders indexed by order number.
+type orderArchive struct {
+	ring    []ArchivedOrder
+	next    int
+	byOrder map[string]int // order number → index in ring
+}
+
+// SetArchivePolicy sets the archival policy of the tracker. Terminal orders are archived
+// by Poll and Apply once they have been terminal for policy.After, or immediately by Archive.
+//
+// Parameters:
+//   - policy: The policy to apply.
+func (t *OrderTracker) SetArchivePolicy(policy ArchivePolicy) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.policy = &policy
+	t.archive = &orderArchive{byOrder: make(map[string]int)}
+	if t.terminalAt == nil {
+		t.terminalAt = make(map[string]time.Time)
+	}
+}
+
+// Archive moves every order that has been terminal for the policy's After duration to
+// the archive, regardless of when the last sweep ran.
+//
+// Returns:
+//   - The number of orders archived.
+func (t *OrderTracker) Archive() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.archiveLocked(time.Now())
+}
+
+// Archived returns the archived state of an order still held in the ring buffer.
+//
+// Returns:
+//   - The archived order and true if found; otherwise, a zero ArchivedOrder and false.
+func (t *OrderTracker) Archived(orderNo string) (ArchivedOrder, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.archive == nil {
+		return ArchivedOrder{}, false
+	}
+	i, ok := t.archive.byOrder[orderNo]
+	if !ok {
+		return ArchivedOrder{}, false
+	}
+	return t.archive.ring[i], true
+}
+
+// ArchivedOrders returns the orders held in the ring buffer, oldest first.
+func (t *OrderTracker) ArchivedOrders() []ArchivedOrder {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.archive == nil || len(t.archive.ring) == 0 {
+		return nil
+	}
+	a := t.archive
+	orders := make([]ArchivedOrder, 0, len(a.ring))
+	if len(a.ring) == t.policy.MaxArchived {
+		orders = append(orders, a.ring[a.next:]...)
+		return append(orders, a.ring[:a.next]...)
+	}
+	return append(orders, a.ring...)
+}
+
+// LoadOrderArchive reads the orders appended to an archive file by an ArchivePolicy.
+//
+// Parameters:
+//   - path: The archive file.
+//
+// Returns:
+//   - The archived orders in the order they were archived.
+//   - An error if the file cannot be read or a line cannot be decoded.
+func LoadOrderArchive(path string) ([]ArchivedOrder, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening order archive: %w", err)
+	}
+	defer file.Close()
+
+	var orders []ArchivedOrder
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var order ArchivedOrder
+		if err := json.Unmarshal(scanner.Bytes(), &order); err != nil {
+			return nil, fmt.Errorf("error decoding order archive: %w", err)
+		}
+		orders = append(orders, order)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading order archive: %w", err)
+	}
+	return orders, nil
+}
+
+// noteTerminalLocked records when a tracked order reached a terminal status. The caller must hold t.mu.
+func (t *OrderTracker) noteTerminalLocked(m *OrderMachine, now time.Time) {
+	if t.policy == nil || !m.Status.Terminal() {
+		return
+	}
+	if _, ok := t.terminalAt[m.OrderNo]; !ok {
+		t.terminalAt[m.OrderNo] = now
+	}
+}
+
+// archiveLocked moves the orders terminal for at least policy.After to the archive.
+// The caller must hold t.mu.
+func (t *OrderTracker) archiveLocked(now time.Time) int {
+	if t.policy == nil {
+		return 0
+	}
+
+	var due []ArchivedOrder
+	for orderNo, at := range t.terminalAt {
+		if now.Sub(at) < t.policy.After {
+			continue
+		}
+		if m, ok := t.machines[orderNo]; ok {
+			due = append(due, ArchivedOrder{OrderMachine: *m, TerminalAt: at, ArchivedAt: now})
+			delete(t.machines, orderNo)
+		}
+		delete(t.terminalAt, orderNo)
+	}
+	if len(due) == 0 {
+		return 0
+	}
+
+	if t.policy.Path != "" {
+		if err := appendArchive(t.policy.Path, due); err != nil {
+			t.client.log().Error().Err(err).Int("orders", len(due)).Msg("Failed to write order archive")
+		}
+	}
+	for _, order := range due {
+		t.archive.add(order, t.policy.MaxArchived)
+	}
+	return len(due)
+}
+
+// add stores an order in the ring buffer, evicting the oldest one when full.
+func (a *orderArchive) add(order ArchivedOrder, capacity int) {
+	if capacity <= 0 {
+		return
+	}
+	if len(a.ring) < capacity {
+		a.byOrder[order.OrderNo] = len(a.ring)
+		a.ring = append(a.ring, order)
+		return
+	}
+
+	delete(a.byOrder, a.ring[a.next].OrderNo)
+	a.ring[a.next] = order
+	a.byOrder[order.OrderNo] = a.next
+	a.next = (a.next + 1) % capacity
+}
+
+// appendArchive appends orders to the archive file as JSON lines.
+func appendArchive(path string, orders []ArchivedOrder) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for _, order := range orders {
+		if err := encoder.Encode(order); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
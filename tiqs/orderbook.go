@@ -0,0 +1,148 @@
+package tiqs
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// OrderBookEntry is a typed row of the order book.
+//
+// Enum fields hold the empty value when the API reports a code the SDK does not know;
+// the untouched row is available in Detail.
+type OrderBookEntry struct {
+	OrderNo         string          // Order number assigned by the broker.
+	ExchangeOrderID string          // Order ID assigned by the exchange.
+	Exchange        Exchange        // Exchange of the order.
+	Symbol          string          // Trading symbol.
+	Token           int64           // Instrument token.
+	TransactionType TransactionType // Buy or sell.
+	Product         Product         // Product of the order.
+	OrderType       OrderType       // Order type.
+	Status          OrderStatus     // Normalized status, taking partial fills into account.
+	Quantity        int64           // Ordered quantity.
+	FilledQuantity  int64           // Quantity filled so far.
+	CancelQuantity  int64           // Quantity cancelled.
+	Price           float64         // Limit price in rupees; zero for market orders.
+	TriggerPrice    float64         // Trigger price in rupees; zero if not applicable.
+	AveragePrice    float64         // Average fill price in rupees.
+	Tag             string          // Tag (remarks) set when the order was placed.
+	RejectReason    string          // Reason of a rejection.
+	OrderTime       time.Time       // Time the order was placed, in IST.
+	UpdateTime      time.Time       // Time of the last exchange update, in IST.
+	Detail          OrderDetail     // The row as returned by the API.
+}
+
+// PendingQuantity returns the quantity still open on the order.
+func (e OrderBookEntry) PendingQuantity() int64 {
+	if !e.Status.Working() {
+		return 0
+	}
+	return max(e.Quantity-e.FilledQuantity-e.CancelQuantity, 0)
+}
+
+// NewOrderBookEntry converts an order book row into a typed entry.
+func NewOrderBookEntry(d OrderDetail) OrderBookEntry {
+	exchange, _ := ParseExchange(d.Exchange)
+	side, _ := ParseTransactionType(d.TransactionType)
+	product, _ := ParseProduct(d.Product)
+	orderType, _ := ParseOrderType(d.Order)
+	orderTime, _ := parseTimestamp(d.OrderTime)
+	updateTime, _ := parseTimestamp(d.ExchangeUpdateTime)
+
+	return OrderBookEntry{
+		OrderNo:         d.ID,
+		ExchangeOrderID: d.ExchangeOrderID,
+		Exchange:        exchange,
+		Symbol:          d.Symbol,
+		Token:           parseInt(d.Token),
+		TransactionType: side,
+		Product:         product,
+		OrderType:       orderType,
+		Status:          d.NormalizedStatus(),
+		Quantity:        parseInt(d.Quantity),
+		FilledQuantity:  parseInt(d.FillShares),
+		CancelQuantity:  parseInt(d.CancelQuantity),
+		Price:           parseFloat(d.Price),
+		TriggerPrice:    parseFloat(d.OrderTriggerPrice),
+		AveragePrice:    parseFloat(d.AveragePrice),
+		Tag:             d.Remarks,
+		RejectReason:    d.RejectReason,
+		OrderTime:       orderTime,
+		UpdateTime:      updateTime,
+		Detail:          d,
+	}
+}
+
+// OrderBookFilter selects order book entries.
+//
+// Empty fields do not filter; symbols and tags are matched case-insensitively.
+type OrderBookFilter struct {
+	Statuses    []OrderStatus     // Statuses to include (e.g., OrderStatusOpen).
+	Symbols     []string          // Trading symbols to include.
+	Products    []Product         // Products to include.
+	Tags        []string          // Tags to include.
+	Sides       []TransactionType // Sides to include.
+	WorkingOnly bool              // Whether to include only orders that can still be filled.
+}
+
+// Match reports whether an entry passes the filter.
+func (f OrderBookFilter) Match(e OrderBookEntry) bool {
+	if f.WorkingOnly && !e.Status.Working() {
+		return false
+	}
+	if len(f.Statuses) > 0 && !slices.Contains(f.Statuses, e.Status) {
+		return false
+	}
+	if len(f.Products) > 0 && !slices.Contains(f.Products, e.Product) {
+		return false
+	}
+	if len(f.Sides) > 0 && !slices.Contains(f.Sides, e.TransactionType) {
+		return false
+	}
+	if len(f.Symbols) > 0 && !containsFold(f.Symbols, e.Symbol) {
+		return false
+	}
+	if len(f.Tags) > 0 && !containsFold(f.Tags, e.Tag) {
+		return false
+	}
+	return true
+}
+
+// Apply returns the entries that pass the filter, in their original order.
+func (f OrderBookFilter) Apply(entries []OrderBookEntry) []OrderBookEntry {
+	var matched []OrderBookEntry
+	for _, e := range entries {
+		if f.Match(e) {
+			matched = append(matched, e)
+		}
+	}
+	return matched
+}
+
+// containsFold reports whether values contains s, ignoring case and surrounding spaces.
+func containsFold(values []string, s string) bool {
+	s = strings.TrimSpace(s)
+	for _, v := range values {
+		if strings.EqualFold(strings.TrimSpace(v), s) {
+			return true
+		}
+	}
+	return false
+}
+
+// FindOrders retrieves the order book and returns the orders that pass the filter.
+//
+// Parameters:
+//   - filter: The filter to apply, e.g., OrderBookFilter{WorkingOnly: true, Tags: []string{"scalper"}}.
+//
+// Returns:
+//   - The matching entries, in order book order.
+//   - An error if the order book cannot be retrieved.
+func (c *Client) FindOrders(filter OrderBookFilter) ([]OrderBookEntry, error) {
+	entries, err := c.GetOrderBook()
+	if err != nil {
+		return nil, err
+	}
+	return filter.Apply(entries), nil
+}
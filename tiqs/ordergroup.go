@@ -0,0 +1,272 @@
+package tiqs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// OrderGroupState represents the aggregated state of the orders in an OrderGroup.
+type OrderGroupState string
+
+const (
+	GroupEmpty     OrderGroupState = "EMPTY"     // No orders have been added yet.
+	GroupWorking   OrderGroupState = "WORKING"   // At least one order is still working.
+	GroupComplete  OrderGroupState = "COMPLETE"  // Every order was filled.
+	GroupPartial   OrderGroupState = "PARTIAL"   // Every order is terminal but only some were filled.
+	GroupCancelled OrderGroupState = "CANCELLED" // Every order is terminal and none was filled.
+)
+
+// OrderGroupStatus summarizes the orders of an OrderGroup.
+type OrderGroupStatus struct {
+	State     OrderGroupState `json:"state"`     // Aggregated state of the group.
+	Total     int             `json:"total"`     // Number of orders in the group.
+	Working   int             `json:"working"`   // Orders that are still working or not yet seen in the order book.
+	Complete  int             `json:"complete"`  // Orders that were filled.
+	Cancelled int             `json:"cancelled"` // Orders that were cancelled.
+	Rejected  int             `json:"rejected"`  // Orders that were rejected.
+}
+
+// groupOrder is the state kept for a single order of a group.
+type groupOrder struct {
+	variety string
+	status  OrderStatus
+}
+
+// OrderGroup tracks related orders, such as the slices of a large order, the legs of a
+// basket or the entry and exit legs of a bracket, so they can be monitored and cancelled
+// as one unit.
+//
+// Order statuses are refreshed from the order book with Refresh, or pushed with Update
+// by callers that already receive order updates. Done is closed once every order of the
+// group reached a terminal state.
+type OrderGroup struct {
+	Name string // Name of the group, used in logs.
+
+	client    *Client
+	mu        sync.Mutex
+	orders    map[string]*groupOrder
+	sequence  []string
+	cancelled bool
+	done      chan struct{}
+	closed    bool
+}
+
+// NewOrderGroup creates an empty order group.
+//
+// Parameters:
+//   - client: The client used to refresh and cancel the orders of the group.
+//   - name: Name of the group, used in logs.
+//
+// Returns:
+//   - A pointer to a newly created OrderGroup.
+func NewOrderGroup(client *Client, name string) *OrderGroup {
+	return &OrderGroup{
+		Name:   name,
+		client: client,
+		orders: make(map[string]*groupOrder),
+		done:   make(chan struct{}),
+	}
+}
+
+// Add adds a placed order to the group.
+//
+// Orders cannot be added once the group has been cancelled or has completed, so that a
+// slice placed concurrently with CancelAll is not silently left working.
+//
+// Parameters:
+//   - variety: The order variety passed to PlaceOrder (e.g., "regular").
+//   - orderNo: The order number returned by PlaceOrder.
+//
+// Returns:
+//   - An error if the group no longer accepts orders.
+func (g *OrderGroup) Add(variety, orderNo string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.cancelled {
+		return fmt.Errorf("order group %s has been cancelled", g.Name)
+	}
+	if g.closed {
+		return fmt.Errorf("order group %s has completed", g.Name)
+	}
+	if _, ok := g.orders[orderNo]; ok {
+		return nil
+	}
+
+	g.orders[orderNo] = &groupOrder{variety: variety, status: OrderStatusPending}
+	g.sequence = append(g.sequence, orderNo)
+	return nil
+}
+
+// Orders returns the order numbers of the group in the order they were added.
+func (g *OrderGroup) Orders() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return append([]string(nil), g.sequence...)
+}
+
+// Update records the latest status of an order of the group. Orders not in the group are ignored.
+//
+// Parameters:
+//   - orderNo: The order number.
+//   - status: The normalized order status.
+func (g *OrderGroup) Update(orderNo string, status OrderStatus) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	order, ok := g.orders[orderNo]
+	if !ok {
+		return
+	}
+	order.status = status
+	g.checkDoneLocked()
+}
+
+// Refresh updates the status of every order of the group from the order book.
+//
+// Returns:
+//   - An error if the order book cannot be retrieved.
+func (g *OrderGroup) Refresh() error {
+	rows, err := g.client.getOrderRows()
+	if err != nil {
+		return err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for _, row := range rows {
+		if order, ok := g.orders[row.ID]; ok {
+			order.status = row.NormalizedStatus()
+		}
+	}
+	g.checkDoneLocked()
+	return nil
+}
+
+// Status returns the aggregated status of the group as of the last refresh or update.
+func (g *OrderGroup) Status() OrderGroupStatus {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.statusLocked()
+}
+
+// Done returns a channel that is closed once every order of the group reached a terminal state.
+func (g *OrderGroup) Done() <-chan struct{} {
+	return g.done
+}
+
+// Wait refreshes the group every interval until all of its orders are terminal.
+//
+// Parameters:
+//   - ctx: Context bounding the wait.
+//   - interval: Delay between order book refreshes.
+//
+// Returns:
+//   - The final OrderGroupStatus.
+//   - An error if the context expires first.
+func (g *OrderGroup) Wait(ctx context.Context, interval time.Duration) (OrderGroupStatus, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := g.Refresh(); err != nil {
+			g.client.log().Warn().Err(err).Str("group", g.Name).Msg("Failed to refresh order group")
+		}
+
+		select {
+		case <-g.done:
+			return g.Status(), nil
+		case <-ctx.Done():
+			return g.Status(), ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// CancelAll cancels every working order of the group.
+//
+// The group stops accepting new orders before any cancellation is sent, and every
+// working order is attempted even if an earlier cancellation fails.
+//
+// Returns:
+//   - An error joining the failed cancellations, or nil if all succeeded.
+func (g *OrderGroup) CancelAll() error {
+	g.mu.Lock()
+	g.cancelled = true
+	targets := make(map[string]string)
+	for _, orderNo := range g.sequence {
+		order := g.orders[orderNo]
+		if !order.status.Terminal() {
+			targets[orderNo] = order.variety
+		}
+	}
+	g.mu.Unlock()
+
+	var errs []error
+	for orderNo, variety := range targets {
+		if err := g.client.CancelOrder(variety, orderNo); err != nil {
+			errs = append(errs, fmt.Errorf("order %s: %w", orderNo, err))
+			continue
+		}
+		g.Update(orderNo, OrderStatusCancelled)
+	}
+
+	if len(errs) > 0 {
+		g.client.log().Error().Str("group", g.Name).Int("failed", len(errs)).Msg("Failed to cancel all orders of group")
+		return errors.Join(errs...)
+	}
+
+	g.client.log().Info().Str("group", g.Name).Int("cancelled", len(targets)).Msg("Order group cancelled successfully")
+	return nil
+}
+
+// statusLocked aggregates the order statuses. The caller must hold g.mu.
+func (g *OrderGroup) statusLocked() OrderGroupStatus {
+	status := OrderGroupStatus{Total: len(g.orders)}
+	for _, order := range g.orders {
+		switch order.status {
+		case OrderStatusComplete:
+			status.Complete++
+		case OrderStatusCancelled:
+			status.Cancelled++
+		case OrderStatusRejected:
+			status.Rejected++
+		default:
+			status.Working++
+		}
+	}
+
+	switch {
+	case status.Total == 0:
+		status.State = GroupEmpty
+	case status.Working > 0:
+		status.State = GroupWorking
+	case status.Complete == status.Total:
+		status.State = GroupComplete
+	case status.Complete > 0:
+		status.State = GroupPartial
+	default:
+		status.State = GroupCancelled
+	}
+	return status
+}
+
+// checkDoneLocked closes the done channel once every order is terminal. The caller must hold g.mu.
+func (g *OrderGroup) checkDoneLocked() {
+	if g.closed || len(g.orders) == 0 {
+		return
+	}
+	if g.statusLocked().Working > 0 {
+		return
+	}
+
+	g.closed = true
+	close(g.done)
+	g.client.log().Info().Str("group", g.Name).Msg("Order group completed")
+}
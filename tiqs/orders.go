@@ -4,26 +4,84 @@ package tiqs
 import (
 	"encoding/json"
 	"fmt"
-
-	"github.com/rs/zerolog/log"
 )
 
 // OrderRequest represents the structure for placing an order.
 type OrderRequest struct {
-	Exchange        string `json:"exchange"`                // Exchange where the order is placed (e.g., NSE, BSE).
-	Token           string `json:"token"`                   // Unique identifier for the instrument.
-	Quantity        string `json:"quantity"`                // Order quantity.
-	DisclosedQty    string `json:"disclosedQty,omitempty"`  // Disclosed quantity (optional).
-	Product         string `json:"product"`                 // Product type (e.g., MIS, CNC, NRML).
-	Symbol          string `json:"symbol"`                  // Trading symbol of the instrument.
-	TransactionType string `json:"transactionType"`         // Order transaction type (BUY/SELL).
-	OrderType       string `json:"order"`                   // Type of order (e.g., MARKET, LIMIT).
-	Price           string `json:"price"`                   // Order price (applicable for LIMIT orders).
-	Validity        string `json:"validity"`                // Order validity (e.g., DAY, IOC).
-	Tags            string `json:"tags,omitempty"`          // Custom tags for order tracking (optional).
-	AMO             bool   `json:"amo,omitempty"`           // Indicates if the order is an After Market Order (AMO).
-	TriggerPrice    string `json:"triggerPrice,omitempty"`  // Trigger price for stop-loss or conditional orders.
-	BookLossPrice   string `json:"bookLossPrice,omitempty"` // Book loss price for risk management.
+	Exchange        Exchange        `json:"exchange"`                  // Exchange where the order is placed (e.g., NSE, BSE).
+	Token           string          `json:"token"`                     // Unique identifier for the instrument.
+	Quantity        string          `json:"quantity"`                  // Order quantity.
+	DisclosedQty    string          `json:"disclosedQty,omitempty"`    // Disclosed quantity (optional).
+	Product         Product         `json:"product"`                   // Product type (e.g., MIS, CNC, NRML).
+	Symbol          string          `json:"symbol"`                    // Trading symbol of the instrument.
+	TransactionType TransactionType `json:"transactionType"`           // Order transaction type (BUY/SELL).
+	OrderType       OrderType       `json:"order"`                     // Type of order (e.g., MARKET, LIMIT).
+	Price           string          `json:"price"`                     // Order price (applicable for LIMIT orders).
+	Validity        Validity        `json:"validity"`                  // Order validity (e.g., DAY, IOC).
+	Tags            string          `json:"tags,omitempty"`            // Custom tags for order tracking (optional).
+	AMO             bool            `json:"amo,omitempty"`             // Indicates if the order is an After Market Order (AMO).
+	TriggerPrice    string          `json:"triggerPrice,omitempty"`    // Trigger price for stop-loss or conditional orders.
+	BookLossPrice   string          `json:"bookLossPrice,omitempty"`   // Book loss price for risk management.
+	BookProfitPrice string          `json:"bookProfitPrice,omitempty"` // Target of a bracket order.
+	TrailingPrice   string          `json:"trailingPrice,omitempty"`   // Trailing stop-loss step of a bracket order.
+	AllowDuplicate  bool            `json:"-"`                         // Bypasses an attached DuplicateGuard for this order.
+}
+
+// Validate checks that the enum fields of the order hold API codes rather than
+// conventional names or typos (e.g., "LIMIT" instead of OrderTypeLimit, which is "LMT"),
+// and that limit and trigger prices are present where the order type needs them.
+//
+// Returns:
+//   - An error naming the first invalid field, suggesting the constant to use when the
+//     value is a recognized alias.
+func (o OrderRequest) Validate() error {
+	if !o.Exchange.Valid() {
+		return enumError("exchange", string(o.Exchange), func(s string) (string, error) {
+			e, err := ParseExchange(s)
+			return string(e), err
+		})
+	}
+	if !o.Product.Valid() {
+		return enumError("product", string(o.Product), func(s string) (string, error) {
+			p, err := ParseProduct(s)
+			return string(p), err
+		})
+	}
+	if !o.TransactionType.Valid() {
+		return enumError("transaction type", string(o.TransactionType), func(s string) (string, error) {
+			t, err := ParseTransactionType(s)
+			return string(t), err
+		})
+	}
+	if !o.OrderType.Valid() {
+		return enumError("order type", string(o.OrderType), func(s string) (string, error) {
+			t, err := ParseOrderType(s)
+			return string(t), err
+		})
+	}
+	if !o.Validity.Valid() {
+		return enumError("validity", string(o.Validity), func(s string) (string, error) {
+			v, err := ParseValidity(s)
+			return string(v), err
+		})
+	}
+
+	if o.OrderType.NeedsPrice() && parseFloat(o.Price) <= 0 {
+		return fmt.Errorf("%s order needs a price, got %q", o.OrderType, o.Price)
+	}
+	if o.OrderType.NeedsTrigger() && parseFloat(o.TriggerPrice) <= 0 {
+		return fmt.Errorf("%s order needs a trigger price, got %q", o.OrderType, o.TriggerPrice)
+	}
+	return nil
+}
+
+// enumError describes an invalid enum value, suggesting the API code when parse recognizes
+// the value as an alias.
+func enumError(field, value string, parse func(string) (string, error)) error {
+	if code, err := parse(value); err == nil {
+		return fmt.Errorf("invalid %s %q: use the API code %q", field, value, code)
+	}
+	return fmt.Errorf("invalid %s %q", field, value)
 }
 
 // OrderResponse represents the API response after placing an order.
@@ -37,48 +95,61 @@ type OrderResponse struct {
 	} `json:"data,omitempty"`
 }
 
+// OrderDetail represents a single row of order details as returned by the order and order book endpoints.
+type OrderDetail struct {
+	Status             string `json:"status"`
+	Exchange           string `json:"exchange"`
+	Symbol             string `json:"symbol"`
+	ID                 string `json:"id"`
+	Price              string `json:"price"`
+	Quantity           string `json:"quantity"`
+	Product            string `json:"product"`
+	OrderStatus        string `json:"orderStatus"`
+	ReportType         string `json:"reportType"`
+	TransactionType    string `json:"transactionType"`
+	Order              string `json:"order"`
+	FillShares         string `json:"fillShares"`
+	AveragePrice       string `json:"averagePrice"`
+	RejectReason       string `json:"rejectReason"`
+	ExchangeOrderID    string `json:"exchangeOrderID"`
+	CancelQuantity     string `json:"cancelQuantity"`
+	Remarks            string `json:"remarks"`
+	DisclosedQuantity  string `json:"disclosedQuantity"`
+	OrderTriggerPrice  string `json:"orderTriggerPrice"`
+	Retention          string `json:"retention"`
+	BookProfitPrice    string `json:"bookProfitPrice"`
+	BookLossPrice      string `json:"bookLossPrice"`
+	TrailingPrice      string `json:"trailingPrice"`
+	Amo                string `json:"amo"`
+	PricePrecision     string `json:"pricePrecision"`
+	TickSize           string `json:"tickSize"`
+	LotSize            string `json:"lotSize"`
+	Token              string `json:"token"`
+	TimeStamp          string `json:"timeStamp"`
+	OrderTime          string `json:"orderTime"`
+	ExchangeUpdateTime string `json:"exchangeUpdateTime"`
+	RequestTime        string `json:"requestTime"`
+	ErrorMessage       string `json:"errorMessage"`
+}
+
+// OrderDetailsResponse represents the API response containing the history of an order.
 type OrderDetailsResponse struct {
-	Data []struct {
-		Status             string `json:"status"`
-		Exchange           string `json:"exchange"`
-		Symbol             string `json:"symbol"`
-		ID                 string `json:"id"`
-		Price              string `json:"price"`
-		Quantity           string `json:"quantity"`
-		Product            string `json:"product"`
-		OrderStatus        string `json:"orderStatus"`
-		ReportType         string `json:"reportType"`
-		TransactionType    string `json:"transactionType"`
-		Order              string `json:"order"`
-		FillShares         string `json:"fillShares"`
-		AveragePrice       string `json:"averagePrice"`
-		RejectReason       string `json:"rejectReason"`
-		ExchangeOrderID    string `json:"exchangeOrderID"`
-		CancelQuantity     string `json:"cancelQuantity"`
-		Remarks            string `json:"remarks"`
-		DisclosedQuantity  string `json:"disclosedQuantity"`
-		OrderTriggerPrice  string `json:"orderTriggerPrice"`
-		Retention          string `json:"retention"`
-		BookProfitPrice    string `json:"bookProfitPrice"`
-		BookLossPrice      string `json:"bookLossPrice"`
-		TrailingPrice      string `json:"trailingPrice"`
-		Amo                string `json:"amo"`
-		PricePrecision     string `json:"pricePrecision"`
-		TickSize           string `json:"tickSize"`
-		LotSize            string `json:"lotSize"`
-		Token              string `json:"token"`
-		TimeStamp          string `json:"timeStamp"`
-		OrderTime          string `json:"orderTime"`
-		ExchangeUpdateTime string `json:"exchangeUpdateTime"`
-		RequestTime        string `json:"requestTime"`
-		ErrorMessage       string `json:"errorMessage"`
-	} `json:"data"`
-	Status string `json:"status"`
+	Data   []OrderDetail `json:"data"`
+	Status string        `json:"status"`
 }
 
 // PlaceOrder places a new order in the market.
 //
 // It sends a POST request to the API endpoint "/order/{orderType}" with the order details.
+// If a StaleGuard with BlockOrders enabled is attached, orders on tokens with stale prices
+// are rejected before reaching the API, as are all orders while an attached HealthMonitor
+// with BlockOrders enabled reports the broker as degraded. If a DuplicateGuard is attached,
+// orders identical to one placed within its window are rejected unless AllowDuplicate is set.
+// Orders in symbols disabled by an attached SymbolControl are rejected as well.
+// Every order is first checked for invalid enum codes and missing prices (see
+// OrderRequest.Validate) and against the conventions of its exchange segment (see
+// ValidateSegment), so that, e.g., currency prices with more than four decimal places or
+// equity quantities in CDS lots are refused locally.
 //
 // Parameters:
 //   - orderType: Type of order (e.g., MARKET, LIMIT).
@@ -88,33 +159,83 @@ type OrderDetailsResponse struct {
 //   - A pointer to OrderResponse with the order confirmation details if successful.
 //   - An error if the order placement fails.
 func (c *Client) PlaceOrder(orderType string, order OrderRequest) (*OrderResponse, error) {
-	endpoint := fmt.Sprintf("/order/%s", orderType)
+	if c.health != nil {
+		if err := c.health.check(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := order.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := c.validateSegment(order); err != nil {
+		return nil, err
+	}
+
+	if c.symbols != nil {
+		if err := c.symbols.check(order); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.staleGuard != nil {
+		if err := c.staleGuard.check(parseInt(order.Token)); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.risk != nil {
+		if err := c.risk.check(order); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.duplicates != nil {
+		release, err := c.duplicates.reserve(order)
+		if err != nil {
+			return nil, err
+		}
+
+		result, err := c.sendOrder(orderType, order)
+		if err != nil {
+			release()
+		}
+		return result, err
+	}
+
+	return c.sendOrder(orderType, order)
+}
+
+// sendOrder sends an order placement request once all client-side checks have passed.
+func (c *Client) sendOrder(orderType string, order OrderRequest) (*OrderResponse, error) {
+	endpoint := c.endpoint(EndpointPlaceOrder, orderType)
 
 	payload, err := json.Marshal(order)
-	log.Info().Str("payload", string(payload)).Msg("Placing order")
+	c.log().Debug().Str("payload", string(payload)).Msg("Placing order")
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to serialize order request")
+		c.log().Error().Err(err).Msg("Failed to serialize order request")
 		return nil, err
 	}
 
 	resp, err := c.request(endpoint, "POST", payload)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to place order")
+		c.log().Error().Err(err).Msg("Failed to place order")
 		return nil, err
 	}
 
 	var result OrderResponse
-	if err := json.Unmarshal(resp, &result); err != nil {
-		log.Error().Err(err).Msg("Failed to parse order response")
+	if err := c.decode(EndpointPlaceOrder, resp, &result); err != nil {
+		c.log().Error().Err(err).Msg("Failed to parse order response")
 		return nil, err
 	}
 
 	if result.Status != "success" {
-		log.Error().Str("errorCode", result.ErrorCode).Str("message", result.Message).Msg("Order placement failed")
-		return nil, fmt.Errorf("order placement failed")
+		c.log().Error().Str("errorCode", result.ErrorCode).Str("message", result.Message).Msg("Order placement failed")
+		return nil, newAPIError("order placement", endpoint, 0, resp)
 	}
 
-	log.Info().Str("orderNo", result.Data.OrderNo).Msg("Order placed successfully")
+	c.log().Info().Str("orderNo", result.Data.OrderNo).Msg("Order placed successfully")
 	return &result, nil
 }
 
@@ -131,31 +252,31 @@ func (c *Client) PlaceOrder(orderType string, order OrderRequest) (*OrderRespons
 //   - A pointer to OrderResponse with the updated order details if successful.
 //   - An error if the modification fails.
 func (c *Client) ModifyOrder(orderType, orderID string, order OrderRequest) (*OrderResponse, error) {
-	endpoint := fmt.Sprintf("/order/%s/%s", orderType, orderID)
+	endpoint := c.endpoint(EndpointModifyOrder, orderType, orderID)
 
 	payload, err := json.Marshal(order)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to serialize modify order request")
+		c.log().Error().Err(err).Msg("Failed to serialize modify order request")
 		return nil, err
 	}
 
 	resp, err := c.request(endpoint, "PATCH", payload)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to modify order")
+		c.log().Error().Err(err).Msg("Failed to modify order")
 		return nil, err
 	}
 
 	var result OrderResponse
-	if err := json.Unmarshal(resp, &result); err != nil {
-		log.Error().Err(err).Msg("Failed to parse modify order response")
+	if err := c.decode(EndpointModifyOrder, resp, &result); err != nil {
+		c.log().Error().Err(err).Msg("Failed to parse modify order response")
 		return nil, err
 	}
 
 	if result.Status != "success" {
-		return nil, fmt.Errorf("order modification failed")
+		return nil, newAPIError("order modification", endpoint, 0, resp)
 	}
 
-	log.Info().Str("orderNo", result.Data.OrderNo).Msg("Order modified successfully")
+	c.log().Info().Str("orderNo", result.Data.OrderNo).Msg("Order modified successfully")
 	return &result, nil
 }
 
@@ -170,11 +291,11 @@ func (c *Client) ModifyOrder(orderType, orderID string, order OrderRequest) (*Or
 // Returns:
 //   - An error if the cancellation fails; otherwise, nil.
 func (c *Client) CancelOrder(orderType, orderID string) error {
-	endpoint := fmt.Sprintf("/order/%s/%s", orderType, orderID)
+	endpoint := c.endpoint(EndpointCancelOrder, orderType, orderID)
 
 	resp, err := c.request(endpoint, "DELETE", nil)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to cancel order")
+		c.log().Error().Err(err).Msg("Failed to cancel order")
 		return err
 	}
 
@@ -185,16 +306,16 @@ func (c *Client) CancelOrder(orderType, orderID string) error {
 		} `json:"data"`
 	}
 
-	if err := json.Unmarshal(resp, &result); err != nil {
-		log.Error().Err(err).Msg("Failed to parse cancel order response")
+	if err := c.decode(EndpointCancelOrder, resp, &result); err != nil {
+		c.log().Error().Err(err).Msg("Failed to parse cancel order response")
 		return err
 	}
 
 	if result.Status != "success" {
-		return fmt.Errorf("order cancellation failed")
+		return newAPIError("order cancellation", endpoint, 0, resp)
 	}
 
-	log.Info().Str("message", result.Data.Message).Msg("Order cancelled successfully")
+	c.log().Info().Str("message", result.Data.Message).Msg("Order cancelled successfully")
 	return nil
 }
 
@@ -209,58 +330,72 @@ func (c *Client) CancelOrder(orderType, orderID string) error {
 //   - A pointer to OrderResponse containing order details if successful.
 //   - An error if the retrieval fails.
 func (c *Client) GetOrder(orderID string) (*OrderDetailsResponse, error) {
-	endpoint := fmt.Sprintf("/order/%s", orderID)
+	endpoint := c.endpoint(EndpointOrderHistory, orderID)
 
 	resp, err := c.request(endpoint, "GET", nil)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to get order details")
+		c.log().Error().Err(err).Msg("Failed to get order details")
 		return nil, err
 	}
 
 	var result OrderDetailsResponse
-	if err := json.Unmarshal(resp, &result); err != nil {
-		log.Error().Err(err).Msg("Failed to parse order details response")
+	if err := c.decode(EndpointOrderHistory, resp, &result); err != nil {
+		c.log().Error().Err(err).Msg("Failed to parse order details response")
 		return nil, err
 	}
 
 	if result.Status != "success" {
-		return nil, fmt.Errorf("failed to retrieve order details")
+		return nil, newAPIError("order details retrieval", endpoint, 0, resp)
 	}
 
-	log.Info().Str("orderNo", orderID).Msg("Order details retrieved successfully")
+	c.log().Info().Str("orderNo", orderID).Msg("Order details retrieved successfully")
 	return &result, nil
 }
 
 // GetOrderBook retrieves all orders for the current trading day.
 //
-// It sends a GET request to the API endpoint "/user/orders" and returns a list of orders.
+// It sends a GET request to the API endpoint "/user/orders" and converts every row into
+// a typed OrderBookEntry. Use FindOrders to filter the orders by status, symbol, product or tag.
 //
 // Returns:
-//   - A slice of OrderResponse structs containing all orders if successful.
+//   - A slice of OrderBookEntry structs containing all orders if successful.
 //   - An error if the retrieval fails.
-func (c *Client) GetOrderBook() ([]OrderResponse, error) {
-	endpoint := "/user/orders"
-
-	resp, err := c.request(endpoint, "GET", nil)
+func (c *Client) GetOrderBook() ([]OrderBookEntry, error) {
+	rows, err := c.getOrderRows()
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to fetch order book")
 		return nil, err
 	}
 
-	var result struct {
-		Status string          `json:"status"`
-		Data   []OrderResponse `json:"data"`
+	entries := make([]OrderBookEntry, len(rows))
+	for i, row := range rows {
+		entries[i] = NewOrderBookEntry(row)
+	}
+
+	c.log().Info().Int("orders", len(entries)).Msg("Order book retrieved successfully")
+	return entries, nil
+}
+
+// getOrderRows retrieves the rows of the order book for the current trading day.
+//
+// It sends a GET request to the API endpoint "/user/orders" and decodes every row
+// into an OrderDetail.
+func (c *Client) getOrderRows() ([]OrderDetail, error) {
+	endpoint := c.endpoint(EndpointOrderBook)
+	resp, err := c.request(endpoint, "GET", nil)
+	if err != nil {
+		c.log().Error().Err(err).Msg("Failed to fetch order book")
+		return nil, err
 	}
 
-	if err := json.Unmarshal(resp, &result); err != nil {
-		log.Error().Err(err).Msg("Failed to parse order book response")
+	var result OrderDetailsResponse
+	if err := c.decode(EndpointOrderBook, resp, &result); err != nil {
+		c.log().Error().Err(err).Msg("Failed to parse order book response")
 		return nil, err
 	}
 
 	if result.Status != "success" {
-		return nil, fmt.Errorf("failed to retrieve order book")
+		return nil, newAPIError("order book retrieval", endpoint, 0, resp)
 	}
 
-	log.Info().Msg("Order book retrieved successfully")
 	return result.Data, nil
 }
@@ -0,0 +1,331 @@
+package tiqs
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OrderStatus is the normalized status of an order.
+//
+// The API reports statuses with varying spelling and granularity; ParseOrderStatus maps
+// them onto this enum so that order handling code can switch on a closed set of values.
+type OrderStatus string
+
+const (
+	OrderStatusPending         OrderStatus = "PENDING"          // Received but not yet acknowledged by the exchange.
+	OrderStatusTriggerPending  OrderStatus = "TRIGGER_PENDING"  // Stop-loss order waiting for its trigger price.
+	OrderStatusOpen            OrderStatus = "OPEN"             // Working on the exchange with nothing filled.
+	OrderStatusPartiallyFilled OrderStatus = "PARTIALLY_FILLED" // Working on the exchange with part of the quantity filled.
+	OrderStatusComplete        OrderStatus = "COMPLETE"         // Fully filled.
+	OrderStatusCancelled       OrderStatus = "CANCELLED"        // Cancelled, possibly after partial fills.
+	OrderStatusRejected        OrderStatus = "REJECTED"         // Rejected by the broker or the exchange.
+	OrderStatusUnknown         OrderStatus = "UNKNOWN"          // Not recognized.
+)
+
+// ParseOrderStatus normalizes an order status as reported by the API.
+//
+// Returns:
+//   - The OrderStatus, or OrderStatusUnknown if the value is not recognized.
+func ParseOrderStatus(s string) OrderStatus {
+	switch strings.ReplaceAll(normalizeEnum(s), " ", "-") {
+	case "PENDING", "PUT-ORDER-REQ-RECEIVED", "VALIDATION-PENDING", "OPEN-PENDING", "MODIFY-PENDING", "CANCEL-PENDING":
+		return OrderStatusPending
+	case "TRIGGER-PENDING", "TRIGGER":
+		return OrderStatusTriggerPending
+	case "OPEN", "MODIFIED", "NEW", "REPLACED":
+		return OrderStatusOpen
+	case "PARTIALLY-FILLED", "PARTIAL", "PARTIALLY-EXECUTED":
+		return OrderStatusPartiallyFilled
+	case "COMPLETE", "COMPLETED", "FILLED", "EXECUTED":
+		return OrderStatusComplete
+	case "CANCELLED", "CANCELED":
+		return OrderStatusCancelled
+	case "REJECTED":
+		return OrderStatusRejected
+	}
+	return OrderStatusUnknown
+}
+
+// String returns the status name.
+func (s OrderStatus) String() string { return string(s) }
+
+// Terminal reports whether the order can no longer change.
+func (s OrderStatus) Terminal() bool {
+	return s == OrderStatusComplete || s == OrderStatusCancelled || s == OrderStatusRejected
+}
+
+// Working reports whether the order is still live and may be cancelled.
+func (s OrderStatus) Working() bool {
+	switch s {
+	case OrderStatusPending, OrderStatusTriggerPending, OrderStatusOpen, OrderStatusPartiallyFilled:
+		return true
+	}
+	return false
+}
+
+// orderTransitions lists the statuses each non-terminal status may move to. Staying in
+// the same status is always allowed.
+var orderTransitions = map[OrderStatus][]OrderStatus{
+	OrderStatusPending:         {OrderStatusTriggerPending, OrderStatusOpen, OrderStatusPartiallyFilled, OrderStatusComplete, OrderStatusCancelled, OrderStatusRejected},
+	OrderStatusTriggerPending:  {OrderStatusOpen, OrderStatusPartiallyFilled, OrderStatusComplete, OrderStatusCancelled, OrderStatusRejected},
+	OrderStatusOpen:            {OrderStatusPartiallyFilled, OrderStatusComplete, OrderStatusCancelled, OrderStatusRejected},
+	OrderStatusPartiallyFilled: {OrderStatusComplete, OrderStatusCancelled, OrderStatusRejected},
+}
+
+// ErrInvalidTransition is returned when an update would move an order to a status it cannot reach.
+var ErrInvalidTransition = errors.New("invalid order status transition")
+
+// canTransition reports whether an order may move from one status to another.
+func canTransition(from, to OrderStatus) bool {
+	if from == to {
+		return true
+	}
+	for _, next := range orderTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizedStatus returns the normalized status of the order row, taking partial fills into account.
+func (d OrderDetail) NormalizedStatus() OrderStatus {
+	status := ParseOrderStatus(d.Status)
+	if status == OrderStatusUnknown {
+		status = ParseOrderStatus(d.OrderStatus)
+	}
+
+	filled := parseInt(d.FillShares)
+	if status == OrderStatusOpen && filled > 0 && filled < parseInt(d.Quantity) {
+		return OrderStatusPartiallyFilled
+	}
+	return status
+}
+
+// OrderEventType identifies an event derived from order updates.
+type OrderEventType string
+
+const (
+	EventStatusChanged        OrderEventType = "STATUS_CHANGED"         // The order moved to a new status.
+	EventNewFill              OrderEventType = "NEW_FILL"               // Additional quantity was filled.
+	EventFullyFilled          OrderEventType = "FULLY_FILLED"           // The whole quantity was filled.
+	EventRejectedAfterPartial OrderEventType = "REJECTED_AFTER_PARTIAL" // The order was rejected after part of it filled.
+	EventReplacedPriceAck     OrderEventType = "REPLACED_PRICE_ACK"     // A price or trigger price modification was acknowledged.
+)
+
+// OrderEvent is derived from the difference between two consecutive states of an order.
+type OrderEvent struct {
+	Type         OrderEventType `json:"type"`         // Kind of event.
+	OrderNo      string         `json:"orderNo"`      // Order the event belongs to.
+	From         OrderStatus    `json:"from"`         // Status before the update.
+	To           OrderStatus    `json:"to"`           // Status after the update.
+	FillQty      int64          `json:"fillQty"`      // Quantity filled by this update, for NewFill.
+	FilledQty    int64          `json:"filledQty"`    // Total quantity filled so far.
+	AveragePrice float64        `json:"averagePrice"` // Average fill price so far.
+	Price        float64        `json:"price"`        // Order price after the update.
+	TriggerPrice float64        `json:"triggerPrice"` // Trigger price after the update.
+	Time         time.Time      `json:"time"`         // Time the update was applied.
+}
+
+// OrderMachine tracks the state of a single order and derives events from its updates.
+//
+// Updates come from order book polls or order update streams and may repeat or arrive
+// out of order; an update that would move the order backwards (e.g., from COMPLETE back
+// to OPEN) is rejected with ErrInvalidTransition and leaves the machine unchanged.
+type OrderMachine struct {
+	OrderNo      string      `json:"orderNo"`      // Order number.
+	Status       OrderStatus `json:"status"`       // Current normalized status.
+	Quantity     int64       `json:"quantity"`     // Order quantity.
+	FilledQty    int64       `json:"filledQty"`    // Quantity filled so far.
+	AveragePrice float64     `json:"averagePrice"` // Average fill price so far.
+	Price        float64     `json:"price"`        // Current order price.
+	TriggerPrice float64     `json:"triggerPrice"` // Current trigger price.
+}
+
+// NewOrderMachine creates a machine for an order that was just placed.
+func NewOrderMachine(orderNo string) *OrderMachine {
+	return &OrderMachine{OrderNo: orderNo, Status: OrderStatusPending}
+}
+
+// Apply applies an order update and returns the events it implies.
+//
+// Parameters:
+//   - detail: The latest order row for the order (e.g., from GetOrder or the order book).
+//
+// Returns:
+//   - The derived events, in the order they happened.
+//   - ErrInvalidTransition if the update is not a valid successor of the current state.
+func (m *OrderMachine) Apply(detail OrderDetail) ([]OrderEvent, error) {
+	next := *m
+	next.Status = detail.NormalizedStatus()
+	next.Quantity = parseInt(detail.Quantity)
+	next.FilledQty = parseInt(detail.FillShares)
+	next.AveragePrice = parseFloat(detail.AveragePrice)
+	next.Price = parseFloat(detail.Price)
+	next.TriggerPrice = parseFloat(detail.OrderTriggerPrice)
+
+	if next.Status == OrderStatusUnknown {
+		return nil, fmt.Errorf("%w: unrecognized status %q for order %s", ErrInvalidTransition, detail.Status, m.OrderNo)
+	}
+	if !canTransition(m.Status, next.Status) || next.FilledQty < m.FilledQty {
+		return nil, fmt.Errorf("%w: order %s from %s to %s", ErrInvalidTransition, m.OrderNo, m.Status, next.Status)
+	}
+
+	now := time.Now()
+	event := func(t OrderEventType) OrderEvent {
+		return OrderEvent{
+			Type:         t,
+			OrderNo:      m.OrderNo,
+			From:         m.Status,
+			To:           next.Status,
+			FilledQty:    next.FilledQty,
+			AveragePrice: next.AveragePrice,
+			Price:        next.Price,
+			TriggerPrice: next.TriggerPrice,
+			Time:         now,
+		}
+	}
+
+	var events []OrderEvent
+	if next.Status != m.Status {
+		events = append(events, event(EventStatusChanged))
+	}
+	if next.FilledQty > m.FilledQty {
+		e := event(EventNewFill)
+		e.FillQty = next.FilledQty - m.FilledQty
+		events = append(events, e)
+	}
+	if next.Status == OrderStatusComplete && m.Status != OrderStatusComplete {
+		events = append(events, event(EventFullyFilled))
+	}
+	if next.Status == OrderStatusRejected && m.Status != OrderStatusRejected && next.FilledQty > 0 {
+		events = append(events, event(EventRejectedAfterPartial))
+	}
+	if next.Status.Working() && m.Status != OrderStatusPending &&
+		(next.Price != m.Price || next.TriggerPrice != m.TriggerPrice) {
+		events = append(events, event(EventReplacedPriceAck))
+	}
+
+	*m = next
+	return events, nil
+}
+
+// OrderTracker drives the state machines of a set of orders from order book polls and
+// publishes the derived events.
+type OrderTracker struct {
+	client   *Client
+	mu       sync.Mutex
+	machines map[string]*OrderMachine
+	events   chan OrderEvent
+
+	policy     *ArchivePolicy       // Optional archival policy, see SetArchivePolicy.
+	archive    *orderArchive        // Archived orders, when a policy is set.
+	terminalAt map[string]time.Time // Time each tracked order reached a terminal status.
+}
+
+// NewOrderTracker creates a tracker publishing events on a channel buffered for buffer events.
+//
+// Parameters:
+//   - client: The client used to poll the order book.
+//   - buffer: Capacity of the events channel; events are dropped when it is full.
+//
+// Returns:
+//   - A pointer to a newly created OrderTracker.
+func NewOrderTracker(client *Client, buffer int) *OrderTracker {
+	return &OrderTracker{
+		client:   client,
+		machines: make(map[string]*OrderMachine),
+		events:   make(chan OrderEvent, buffer),
+	}
+}
+
+// Track starts tracking an order.
+func (t *OrderTracker) Track(orderNo string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.machines[orderNo]; !ok {
+		t.machines[orderNo] = NewOrderMachine(orderNo)
+	}
+}
+
+// Untrack stops tracking an order.
+func (t *OrderTracker) Untrack(orderNo string) {
+	t.mu.Lock()
+	delete(t.machines, orderNo)
+	delete(t.terminalAt, orderNo)
+	t.mu.Unlock()
+}
+
+// Events returns the channel on which derived events are published.
+func (t *OrderTracker) Events() <-chan OrderEvent {
+	return t.events
+}
+
+// State returns a copy of the current state of a tracked order, falling back to the
+// archive for orders archived by an ArchivePolicy.
+func (t *OrderTracker) State(orderNo string) (OrderMachine, bool) {
+	t.mu.Lock()
+	var state OrderMachine
+	m, ok := t.machines[orderNo]
+	if ok {
+		state = *m
+	}
+	t.mu.Unlock()
+	if ok {
+		return state, true
+	}
+
+	if archived, ok := t.Archived(orderNo); ok {
+		return archived.OrderMachine, true
+	}
+	return OrderMachine{}, false
+}
+
+// Apply feeds an order update into the machine of its order and publishes the derived
+// events. Updates for untracked orders are ignored; invalid transitions are logged and dropped.
+// With an archive policy set, orders terminal for long enough are archived afterwards.
+func (t *OrderTracker) Apply(detail OrderDetail) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	m, ok := t.machines[detail.ID]
+	if !ok {
+		return
+	}
+
+	events, err := m.Apply(detail)
+	if err != nil {
+		t.client.log().Warn().Err(err).Msg("Ignoring order update")
+		return
+	}
+	now := time.Now()
+	t.noteTerminalLocked(m, now)
+	defer t.archiveLocked(now)
+
+	for _, event := range events {
+		select {
+		case t.events <- event:
+		default:
+			t.client.log().Warn().Str("orderNo", event.OrderNo).Str("event", string(event.Type)).Msg("Order event channel is full, dropping event")
+		}
+	}
+}
+
+// Poll fetches the order book once and applies the rows of all tracked orders.
+//
+// Returns:
+//   - An error if the order book cannot be retrieved.
+func (t *OrderTracker) Poll() error {
+	rows, err := t.client.getOrderRows()
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		t.Apply(row)
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package tiqs
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Abhi13027/go-tiqs/ticks"
+)
+
+// Poll intervals of WaitForOrder.
+const (
+	DefaultOrderPollInterval = 500 * time.Millisecond // Without an OrderWatcher.
+	WatchedOrderPollInterval = 5 * time.Second        // With an OrderWatcher, as a fallback for missed updates.
+)
+
+// OrderWatcher wakes the WaitForOrder calls of a client when the order socket reports a
+// change of their order, so they return as soon as the order settles instead of at the
+// next poll.
+//
+// Feed it with the updates of a ticks.OrderSocket, with Run or Apply, and attach it with
+// SetOrderWatcher. The socket only signals changes: the details returned by WaitForOrder
+// are always fetched with GetOrder.
+type OrderWatcher struct {
+	mu      sync.Mutex
+	nextID  int
+	waiters map[string]map[int]chan struct{}
+}
+
+// NewOrderWatcher creates a watcher without waiters.
+func NewOrderWatcher() *OrderWatcher {
+	return &OrderWatcher{waiters: make(map[string]map[int]chan struct{})}
+}
+
+// SetOrderWatcher attaches an OrderWatcher to the client.
+//
+// Parameters:
+//   - watcher: The watcher to attach, or nil to detach the current one.
+func (c *Client) SetOrderWatcher(watcher *OrderWatcher) {
+	c.orderWatcher = watcher
+}
+
+// Run applies order updates until the context is cancelled or the update channel is
+// closed.
+//
+// Parameters:
+//   - ctx: Context controlling the watcher.
+//   - updates: Order updates, e.g., from ticks.OrderSocket.GetUpdateChannel.
+//
+// Returns:
+//   - The context error once the context is cancelled, or nil once updates is closed.
+func (w *OrderWatcher) Run(ctx context.Context, updates <-chan ticks.OrderUpdate) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case update, ok := <-updates:
+			if !ok {
+				return nil
+			}
+			w.Apply(update)
+		}
+	}
+}
+
+// Apply wakes the waiters of the update's order.
+func (w *OrderWatcher) Apply(update ticks.OrderUpdate) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, wake := range w.waiters[update.OrderNo] {
+		select {
+		case wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// wait registers a waiter of an order and returns its wake-up channel and the function
+// removing it.
+func (w *OrderWatcher) wait(orderNo string) (<-chan struct{}, func()) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.nextID++
+	id := w.nextID
+	wake := make(chan struct{}, 1)
+	if w.waiters[orderNo] == nil {
+		w.waiters[orderNo] = make(map[int]chan struct{})
+	}
+	w.waiters[orderNo][id] = wake
+
+	return wake, func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		delete(w.waiters[orderNo], id)
+		if len(w.waiters[orderNo]) == 0 {
+			delete(w.waiters, orderNo)
+		}
+	}
+}
+
+// WaitForOrder waits until an order reaches one of the given statuses and returns its
+// latest details.
+//
+// The order is polled with GetOrder every DefaultOrderPollInterval. With an OrderWatcher
+// attached, it is also checked as soon as the order socket reports a change, and polled
+// every WatchedOrderPollInterval only in case an update was missed. Polls failing with a
+// temporary error, such as a rate limit, are retried at the next interval.
+//
+// Parameters:
+//   - ctx: Context bounding the wait, e.g., with a timeout.
+//   - orderID: Unique identifier of the order.
+//   - states: The statuses to wait for; the terminal statuses (OrderStatusComplete,
+//     OrderStatusRejected and OrderStatusCancelled) if none are given.
+//
+// Returns:
+//   - The latest details of the order: in one of the statuses if successful, or as last
+//     seen, possibly nil, if the wait ends early.
+//   - The context error if the context ends first, or the error of a failed poll.
+func (c *Client) WaitForOrder(ctx context.Context, orderID string, states ...OrderStatus) (*OrderDetail, error) {
+	if len(states) == 0 {
+		states = []OrderStatus{OrderStatusComplete, OrderStatusRejected, OrderStatusCancelled}
+	}
+
+	interval := DefaultOrderPollInterval
+	var wake <-chan struct{}
+	if w := c.orderWatcher; w != nil {
+		var stop func()
+		wake, stop = w.wait(orderID)
+		defer stop()
+		interval = WatchedOrderPollInterval
+	}
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	var last *OrderDetail
+	for {
+		select {
+		case <-ctx.Done():
+			return last, ctx.Err()
+		case <-timer.C:
+		case <-wake:
+		}
+
+		detail, err := c.latestOrderDetail(orderID)
+		switch {
+		case err == nil && detail == nil:
+			// The order may not be in its history yet right after placement.
+		case err == nil:
+			last = detail
+			if status := detail.NormalizedStatus(); slices.Contains(states, status) {
+				c.log().Info().Str("orderNo", orderID).Str("status", status.String()).Msg("Order reached awaited status")
+				return detail, nil
+			}
+		case isTemporary(err):
+			c.log().Warn().Err(err).Str("orderNo", orderID).Msg("Failed to poll order; retrying")
+		default:
+			return last, err
+		}
+		timer.Reset(interval)
+	}
+}
+
+// latestOrderDetail returns the current state of an order from its history: a terminal
+// row if there is one, otherwise the most recently updated row, or nil if the history is
+// empty.
+func (c *Client) latestOrderDetail(orderID string) (*OrderDetail, error) {
+	resp, err := c.GetOrder(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Data) == 0 {
+		return nil, nil
+	}
+
+	latest := resp.Data[0]
+	latestTime, _ := parseTimestamp(latest.TimeStamp)
+	for _, row := range resp.Data {
+		if row.NormalizedStatus().Terminal() {
+			return &row, nil
+		}
+		if t, ok := parseTimestamp(row.TimeStamp); ok && t.After(latestTime) {
+			latest, latestTime = row, t
+		}
+	}
+	return &latest, nil
+}
+
+// isTemporary reports whether a failed request may succeed if sent again later.
+func isTemporary(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Temporary()
+	}
+	return isNetworkError(err) || notSent(err)
+}
@@ -0,0 +1,79 @@
+package tiqs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// optionChainExpiryLayout is the expiry format expected by the option chain endpoint (e.g., "06-MAR-2025").
+const optionChainExpiryLayout = "02-Jan-2006"
+
+// quoteModes lists the modes accepted by the quote endpoints.
+var quoteModes = map[string]bool{"ltp": true, "full": true, "depth": true}
+
+// quoteRequest is the payload of the single quote endpoint.
+type quoteRequest struct {
+	Token int64 `json:"token"`
+}
+
+// OptionChainParams holds the parameters of an option chain request.
+type OptionChainParams struct {
+	Token    int64     // Token of the underlying (e.g., 26000 for NIFTY 50).
+	Exchange string    // Exchange of the underlying (e.g., "INDEX", "NSE").
+	Count    int       // Number of strikes on each side of the money.
+	Expiry   time.Time // Expiry date of the options.
+}
+
+// Validate checks the parameters before they are sent.
+//
+// Returns:
+//   - An error describing the first invalid parameter, or nil if all are valid.
+func (p OptionChainParams) Validate() error {
+	switch {
+	case p.Token <= 0:
+		return fmt.Errorf("invalid option chain token: %d", p.Token)
+	case strings.TrimSpace(p.Exchange) == "":
+		return fmt.Errorf("option chain exchange is required")
+	case p.Count <= 0:
+		return fmt.Errorf("invalid option chain strike count: %d", p.Count)
+	case p.Expiry.IsZero():
+		return fmt.Errorf("option chain expiry is required")
+	}
+	return nil
+}
+
+// optionChainRequest is the payload of the option chain endpoint, which takes every field as a string.
+type optionChainRequest struct {
+	Token    string `json:"token"`
+	Exchange string `json:"exchange"`
+	Count    string `json:"count"`
+	Expiry   string `json:"expiry"`
+}
+
+// payload converts the parameters into the request payload.
+func (p OptionChainParams) payload() optionChainRequest {
+	return optionChainRequest{
+		Token:    strconv.FormatInt(p.Token, 10),
+		Exchange: strings.ToUpper(p.Exchange),
+		Count:    strconv.Itoa(p.Count),
+		Expiry:   strings.ToUpper(p.Expiry.In(IST).Format(optionChainExpiryLayout)),
+	}
+}
+
+// validateQuote checks the mode and tokens of a quote request.
+func validateQuote(mode string, tokens ...int64) error {
+	if !quoteModes[mode] {
+		return fmt.Errorf("invalid quote mode: %q", mode)
+	}
+	if len(tokens) == 0 {
+		return fmt.Errorf("at least one token is required")
+	}
+	for _, token := range tokens {
+		if token <= 0 {
+			return fmt.Errorf("invalid token: %d", token)
+		}
+	}
+	return nil
+}
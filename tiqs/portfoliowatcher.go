@@ -0,0 +1,173 @@
+package tiqs
+
+import (
+	"context"
+	"time"
+)
+
+// ChangeKind describes how an entity changed between two polls.
+type ChangeKind string
+
+const (
+	ChangeAdded   ChangeKind = "ADDED"   // The entity appeared.
+	ChangeUpdated ChangeKind = "UPDATED" // The entity changed.
+	ChangeRemoved ChangeKind = "REMOVED" // The entity disappeared.
+)
+
+// OrderDiff describes a change to an order between two polls.
+type OrderDiff struct {
+	Kind     ChangeKind  `json:"kind"`     // How the order changed.
+	Order    OrderDetail `json:"order"`    // The order after the change, or the removed order.
+	Previous OrderDetail `json:"previous"` // The order before the change; zero for added orders.
+}
+
+// PositionDiff describes a change to a position between two polls.
+type PositionDiff struct {
+	Kind     ChangeKind `json:"kind"`     // How the position changed.
+	Position Position   `json:"position"` // The position after the change, or the removed position.
+	Previous Position   `json:"previous"` // The position before the change; zero for added positions.
+}
+
+// PortfolioWatcher polls the order book and positions and emits only what changed.
+//
+// It gives pseudo-real-time order and position updates without an order update stream.
+// Positions are compared on quantities and prices of the trades only; LTP and
+// mark-to-market fields, which change on every poll, do not produce updates.
+type PortfolioWatcher struct {
+	Interval time.Duration // Delay between polls.
+
+	client    *Client
+	orders    chan OrderDiff
+	positions chan PositionDiff
+
+	lastOrders    map[string]OrderDetail
+	lastPositions map[string]Position
+}
+
+// NewPortfolioWatcher creates a watcher polling every interval.
+//
+// Parameters:
+//   - client: The client used to poll.
+//   - interval: Delay between polls.
+//
+// Returns:
+//   - A pointer to a newly created PortfolioWatcher.
+func NewPortfolioWatcher(client *Client, interval time.Duration) *PortfolioWatcher {
+	return &PortfolioWatcher{
+		Interval:  interval,
+		client:    client,
+		orders:    make(chan OrderDiff, 256),
+		positions: make(chan PositionDiff, 256),
+	}
+}
+
+// Orders returns the channel on which order changes are emitted.
+func (w *PortfolioWatcher) Orders() <-chan OrderDiff {
+	return w.orders
+}
+
+// Positions returns the channel on which position changes are emitted.
+func (w *PortfolioWatcher) Positions() <-chan PositionDiff {
+	return w.positions
+}
+
+// Run polls until the context is cancelled. The first poll emits every existing order
+// and position as added. Both channels are closed when Run returns.
+//
+// Returns:
+//   - The context error once the context is cancelled.
+func (w *PortfolioWatcher) Run(ctx context.Context) error {
+	defer close(w.orders)
+	defer close(w.positions)
+
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	for {
+		w.Poll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll fetches the order book and positions once and emits their changes. A failing
+// fetch is logged and leaves the previous snapshot of that entity in place.
+func (w *PortfolioWatcher) Poll(ctx context.Context) {
+	if rows, err := w.client.getOrderRows(); err != nil {
+		w.client.log().Warn().Err(err).Msg("Portfolio watcher failed to poll orders")
+	} else {
+		w.diffOrders(ctx, rows)
+	}
+
+	if positions, err := w.client.GetPositions(); err != nil {
+		w.client.log().Warn().Err(err).Msg("Portfolio watcher failed to poll positions")
+	} else {
+		w.diffPositions(ctx, positions)
+	}
+}
+
+// diffOrders emits the differences between the previous and current order book.
+func (w *PortfolioWatcher) diffOrders(ctx context.Context, rows []OrderDetail) {
+	current := make(map[string]OrderDetail, len(rows))
+	for _, row := range rows {
+		current[row.ID] = row
+
+		prev, ok := w.lastOrders[row.ID]
+		switch {
+		case !ok:
+			emit(ctx, w.orders, OrderDiff{Kind: ChangeAdded, Order: row})
+		case prev != row:
+			emit(ctx, w.orders, OrderDiff{Kind: ChangeUpdated, Order: row, Previous: prev})
+		}
+	}
+	for id, prev := range w.lastOrders {
+		if _, ok := current[id]; !ok {
+			emit(ctx, w.orders, OrderDiff{Kind: ChangeRemoved, Order: prev, Previous: prev})
+		}
+	}
+	w.lastOrders = current
+}
+
+// diffPositions emits the differences between the previous and current positions.
+func (w *PortfolioWatcher) diffPositions(ctx context.Context, positions []Position) {
+	current := make(map[string]Position, len(positions))
+	for _, p := range positions {
+		key := p.Token + ":" + p.Product
+		current[key] = p
+
+		prev, ok := w.lastPositions[key]
+		switch {
+		case !ok:
+			emit(ctx, w.positions, PositionDiff{Kind: ChangeAdded, Position: p})
+		case tradedFields(prev) != tradedFields(p):
+			emit(ctx, w.positions, PositionDiff{Kind: ChangeUpdated, Position: p, Previous: prev})
+		}
+	}
+	for key, prev := range w.lastPositions {
+		if _, ok := current[key]; !ok {
+			emit(ctx, w.positions, PositionDiff{Kind: ChangeRemoved, Position: prev, Previous: prev})
+		}
+	}
+	w.lastPositions = current
+}
+
+// tradedFields returns the position without the fields that move with the market price.
+func tradedFields(p Position) Position {
+	p.Ltp = ""
+	p.Pnl = ""
+	p.UnRealisedPnl = ""
+	p.UnrealisedMarkToMarket = ""
+	return p
+}
+
+// emit sends a change unless the context is cancelled first.
+func emit[T any](ctx context.Context, ch chan<- T, change T) {
+	select {
+	case ch <- change:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,174 @@
+package tiqs
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Position types of a ConvertPositionRequest.
+const (
+	PositionTypeDay          = "DAY" // The quantity traded today.
+	PositionTypeCarryForward = "CF"  // The quantity carried forward from previous sessions.
+)
+
+// ConvertPositionRequest is a request to move an open position, or part of it, to another
+// product, e.g., an intraday (MIS) position to delivery (CNC) or overnight (NRML).
+type ConvertPositionRequest struct {
+	Exchange        Exchange        `json:"exchange"`               // Exchange of the instrument.
+	Token           string          `json:"token"`                  // Unique identifier for the instrument.
+	Symbol          string          `json:"symbol"`                 // Trading symbol of the instrument.
+	TransactionType TransactionType `json:"transactionType"`        // Side of the position: TransactionBuy for a long, TransactionSell for a short.
+	Quantity        string          `json:"quantity"`               // Quantity to convert, in the convention of the segment's orders.
+	PreviousProduct Product         `json:"previousProduct"`        // Current product of the position.
+	Product         Product         `json:"product"`                // Product to convert to.
+	PositionType    string          `json:"positionType,omitempty"` // PositionTypeDay or PositionTypeCarryForward; empty for PositionTypeDay.
+}
+
+// ConvertPositionResponse represents the API response to a position conversion.
+type ConvertPositionResponse struct {
+	Status string `json:"status"` // API response status (e.g., "success" or "error").
+	Data   struct {
+		Message string `json:"message"` // Confirmation message of the conversion.
+	} `json:"data"`
+}
+
+// Validate checks that the conversion is complete and allowed in the instrument's
+// segment: bracket and cover positions cannot be converted, CNC exists for equities only
+// and NRML for derivatives only.
+//
+// Returns:
+//   - An error describing the first problem found, or nil.
+func (r ConvertPositionRequest) Validate() error {
+	switch {
+	case r.Token == "":
+		return fmt.Errorf("conversion has no token")
+	case parseInt(r.Quantity) <= 0:
+		return fmt.Errorf("invalid conversion quantity: %q", r.Quantity)
+	case r.TransactionType != TransactionBuy && r.TransactionType != TransactionSell:
+		return fmt.Errorf("invalid conversion side: %q", r.TransactionType)
+	case r.PositionType != "" && r.PositionType != PositionTypeDay && r.PositionType != PositionTypeCarryForward:
+		return fmt.Errorf("invalid position type: %q", r.PositionType)
+	case r.PreviousProduct == r.Product:
+		return fmt.Errorf("position is already in product %s", r.Product)
+	}
+
+	rules := r.Exchange.Segment().Rules()
+	for _, product := range []Product{r.PreviousProduct, r.Product} {
+		if product == ProductBracket || product == ProductCover {
+			return fmt.Errorf("%s positions cannot be converted", product)
+		}
+		if !rules.allows(product) {
+			return fmt.Errorf("product %s is not available on %s", product, r.Exchange)
+		}
+	}
+	return nil
+}
+
+// ConvertPosition moves an open position, or part of it, to another product.
+//
+// It sends a POST request to the "/position/convert" endpoint. Conversions are not
+// orders: they trade nothing and pass the RiskManager, but they change the margin the
+// position blocks, e.g., converting MIS to CNC pays for the shares in full, so a disarmed
+// Interlock refuses them.
+//
+// Parameters:
+//   - req: The conversion; see PositionConversion to build it from a position.
+//
+// Returns:
+//   - A pointer to the ConvertPositionResponse if successful.
+//   - An error if the conversion is invalid, the request fails or the API rejects it.
+func (c *Client) ConvertPosition(req ConvertPositionRequest) (*ConvertPositionResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	endpoint := c.endpoint(EndpointConvertPosition)
+	payload, err := json.Marshal(req)
+	if err != nil {
+		c.log().Error().Err(err).Msg("Failed to serialize position conversion")
+		return nil, err
+	}
+
+	resp, err := c.request(endpoint, "POST", payload)
+	if err != nil {
+		c.log().Error().Err(err).Str("symbol", req.Symbol).Msg("Failed to convert position")
+		return nil, err
+	}
+
+	var result ConvertPositionResponse
+	if err := c.decode(EndpointConvertPosition, resp, &result); err != nil {
+		c.log().Error().Err(err).Msg("Failed to parse position conversion response")
+		return nil, err
+	}
+	if result.Status != "success" {
+		return nil, newAPIError("position conversion", endpoint, 0, resp)
+	}
+
+	c.log().Info().
+		Str("symbol", req.Symbol).
+		Str("quantity", req.Quantity).
+		Str("from", req.PreviousProduct.String()).
+		Str("to", req.Product.String()).
+		Msg("Position converted")
+	return &result, nil
+}
+
+// PositionConversion builds the conversion of a position, as returned by GetPositions,
+// to another product.
+//
+// Quantities are converted to the convention of the position's segment, so currency and
+// commodity positions are converted in lots.
+//
+// Parameters:
+//   - position: The position to convert.
+//   - to: The product to convert to.
+//   - quantity: The number of units to convert; zero or less converts the whole position.
+//
+// Returns:
+//   - The conversion request.
+//   - An error if the position is flat or smaller than quantity, or the quantity does not
+//     match the lot size.
+func (c *Client) PositionConversion(position Position, to Product, quantity int64) (ConvertPositionRequest, error) {
+	net := parseInt(position.Qty)
+	if net == 0 {
+		return ConvertPositionRequest{}, fmt.Errorf("position in %s is flat", position.Symbol)
+	}
+	units := abs64(net)
+	if quantity > 0 {
+		if quantity > units {
+			return ConvertPositionRequest{}, fmt.Errorf("cannot convert %d of a position of %d in %s", quantity, units, position.Symbol)
+		}
+		units = quantity
+	}
+
+	side := TransactionBuy
+	if net < 0 {
+		side = TransactionSell
+	}
+
+	exchange := Exchange(strings.ToUpper(position.Exchange))
+	inst := c.PriceConverter().Instrument(parseInt(position.Token))
+	if inst.LotSize <= 0 {
+		inst.LotSize = parseInt(position.LotSize)
+	}
+	qty := units
+	if exchange.Segment().Rules().QuantityInLots && inst.LotSize > 0 {
+		if units%inst.LotSize != 0 {
+			return ConvertPositionRequest{}, fmt.Errorf("quantity %d in %s is not a multiple of the lot size %d", units, position.Symbol, inst.LotSize)
+		}
+		qty = units / inst.LotSize
+	}
+
+	return ConvertPositionRequest{
+		Exchange:        exchange,
+		Token:           position.Token,
+		Symbol:          position.Symbol,
+		TransactionType: side,
+		Quantity:        strconv.FormatInt(qty, 10),
+		PreviousProduct: Product(position.Product),
+		Product:         to,
+		PositionType:    PositionTypeDay,
+	}, nil
+}
@@ -0,0 +1,412 @@
+package tiqs
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Abhi13027/go-tiqs/ticks"
+)
+
+// Default settings of a PositionEngine.
+const (
+	DefaultReconcileInterval = time.Minute
+	DefaultReconcileSettle   = 3 * time.Second
+)
+
+// LivePosition is a position maintained from order updates.
+type LivePosition struct {
+	Exchange    string    `json:"exchange"`    // Exchange of the instrument.
+	Symbol      string    `json:"symbol"`      // Trading symbol of the instrument.
+	Token       int64     `json:"token"`       // Unique identifier for the instrument.
+	Product     string    `json:"product"`     // Product code of the position (e.g., "I", "M").
+	NetQty      int64     `json:"netQty"`      // Net quantity; negative for short positions.
+	BuyQty      int64     `json:"buyQty"`      // Quantity bought, carry-forward included.
+	SellQty     int64     `json:"sellQty"`     // Quantity sold, carry-forward included.
+	AvgPrice    float64   `json:"avgPrice"`    // Average price of the open quantity.
+	RealizedPnL float64   `json:"realizedPnL"` // P&L of the closed quantity.
+	Fills       int       `json:"fills"`       // Number of fills applied.
+	UpdatedAt   time.Time `json:"updatedAt"`   // Time of the last change.
+}
+
+// UnrealizedPnL returns the P&L of the open quantity at the given price.
+func (p LivePosition) UnrealizedPnL(ltp float64) float64 {
+	return float64(p.NetQty) * (ltp - p.AvgPrice)
+}
+
+// PositionDivergence describes a position whose local quantity disagrees with the broker.
+type PositionDivergence struct {
+	Key        string    `json:"key"`        // Token and product of the position.
+	Exchange   string    `json:"exchange"`   // Exchange of the instrument.
+	Symbol     string    `json:"symbol"`     // Trading symbol of the instrument.
+	Product    string    `json:"product"`    // Product code of the position.
+	LocalQty   int64     `json:"localQty"`   // Net quantity derived from order updates.
+	BrokerQty  int64     `json:"brokerQty"`  // Net quantity reported by GetPositions.
+	DetectedAt time.Time `json:"detectedAt"` // Time of the reconciliation.
+}
+
+// orderProgress is the cumulative fill of an order seen so far.
+type orderProgress struct {
+	filled  int64
+	average float64
+}
+
+// PositionEngine maintains positions from the order update stream.
+//
+// Every fill is applied as it arrives, so strategies read positions as fresh as the
+// order socket rather than a poll. Updates carrying a fill ID are applied once per fill;
+// updates with only cumulative fill quantities are applied by their increment. The
+// engine is reconciled against GetPositions every ReconcileInterval: positions whose
+// quantity disagrees, and that had no fill within ReconcileSettle, are reported as
+// divergences and replaced by the broker's view.
+//
+// Both the update and divergence channels must be drained while Run is running.
+type PositionEngine struct {
+	ReconcileInterval time.Duration // Delay between reconciliations; zero disables them.
+	ReconcileSettle   time.Duration // Quiet time after a fill before a position is reconciled.
+
+	client      *Client
+	mu          sync.RWMutex
+	positions   map[string]*LivePosition
+	orders      map[string]orderProgress
+	fills       map[string]bool
+	updates     chan LivePosition
+	divergences chan PositionDivergence
+}
+
+// NewPositionEngine creates an engine reconciling every DefaultReconcileInterval.
+//
+// Parameters:
+//   - client: The client used to seed and reconcile positions.
+//
+// Returns:
+//   - A pointer to a newly created PositionEngine.
+func NewPositionEngine(client *Client) *PositionEngine {
+	return &PositionEngine{
+		ReconcileInterval: DefaultReconcileInterval,
+		ReconcileSettle:   DefaultReconcileSettle,
+		client:            client,
+		positions:         make(map[string]*LivePosition),
+		orders:            make(map[string]orderProgress),
+		fills:             make(map[string]bool),
+		updates:           make(chan LivePosition, 256),
+		divergences:       make(chan PositionDivergence, 64),
+	}
+}
+
+// Updates returns the channel on which Run emits positions changed by a fill.
+func (e *PositionEngine) Updates() <-chan LivePosition {
+	return e.updates
+}
+
+// Divergences returns the channel on which Run emits reconciliation mismatches.
+func (e *PositionEngine) Divergences() <-chan PositionDivergence {
+	return e.divergences
+}
+
+// Run seeds the positions from GetPositions, then applies order updates until the
+// context is cancelled or the update channel is closed. Both output channels are
+// closed when Run returns.
+//
+// Parameters:
+//   - ctx: Context controlling the engine.
+//   - updates: Order updates, e.g., from ticks.OrderSocket.GetUpdateChannel.
+//
+// Returns:
+//   - The context error once the context is cancelled, or nil once updates is closed.
+func (e *PositionEngine) Run(ctx context.Context, updates <-chan ticks.OrderUpdate) error {
+	defer close(e.updates)
+	defer close(e.divergences)
+
+	if err := e.Seed(); err != nil {
+		e.client.log().Warn().Err(err).Msg("Position engine failed to seed positions")
+	}
+
+	var reconcile <-chan time.Time
+	if e.ReconcileInterval > 0 {
+		ticker := time.NewTicker(e.ReconcileInterval)
+		defer ticker.Stop()
+		reconcile = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case update, ok := <-updates:
+			if !ok {
+				return nil
+			}
+			if position, changed := e.Apply(update); changed {
+				emit(ctx, e.updates, position)
+			}
+		case <-reconcile:
+			divergences, err := e.Reconcile()
+			if err != nil {
+				e.client.log().Warn().Err(err).Msg("Position engine failed to reconcile positions")
+			}
+			for _, d := range divergences {
+				emit(ctx, e.divergences, d)
+			}
+		}
+	}
+}
+
+// Seed replaces the positions with those reported by GetPositions, e.g., to start from
+// the carry-forward positions of the day. The fills of the order book are recorded as
+// applied, so later updates of partially filled orders only add their new fills.
+//
+// Returns:
+//   - An error if positions or the order book cannot be retrieved.
+func (e *PositionEngine) Seed() error {
+	positions, err := e.client.GetPositions()
+	if err != nil {
+		return err
+	}
+	rows, err := e.client.getOrderRows()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.positions = make(map[string]*LivePosition, len(positions))
+	for _, p := range positions {
+		e.positions[positionKey(p.Token, p.Product)] = livePositionOf(p, now)
+	}
+	e.orders = make(map[string]orderProgress, len(rows))
+	for _, row := range rows {
+		e.orders[row.ID] = orderProgress{filled: parseInt(row.FillShares), average: parseFloat(row.AveragePrice)}
+	}
+	return nil
+}
+
+// Apply applies an order update and reports whether it changed a position.
+//
+// Parameters:
+//   - update: The order update.
+//
+// Returns:
+//   - The position after the update, and true if the update carried a new fill.
+func (e *PositionEngine) Apply(update ticks.OrderUpdate) (LivePosition, bool) {
+	side, err := ParseTransactionType(update.TransactionType)
+	if err != nil {
+		if update.IsFill() || update.FilledQuantity > 0 {
+			e.client.log().Warn().Err(err).Str("orderNo", update.OrderNo).Msg("Position engine ignored fill with unknown side")
+		}
+		return LivePosition{}, false
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	qty, price := e.fillLocked(update)
+	if qty == 0 {
+		return LivePosition{}, false
+	}
+
+	token := strconv.FormatInt(int64(update.Token), 10)
+	key := positionKey(token, update.Product)
+	p, ok := e.positions[key]
+	if !ok {
+		p = &LivePosition{Exchange: update.Exchange, Symbol: update.Symbol, Token: int64(update.Token), Product: update.Product}
+		e.positions[key] = p
+	}
+
+	signed := qty
+	if side == TransactionSell {
+		signed, p.SellQty = -qty, p.SellQty+qty
+	} else {
+		p.BuyQty += qty
+	}
+	p.applyFill(signed, price)
+	p.Fills++
+	p.UpdatedAt = time.Now()
+	return *p, true
+}
+
+// fillLocked returns the quantity and price of the fill carried by an update that was
+// not applied yet. The caller must hold e.mu.
+func (e *PositionEngine) fillLocked(update ticks.OrderUpdate) (int64, float64) {
+	prev := e.orders[update.OrderNo]
+
+	if update.IsFill() {
+		id := update.OrderNo + "/" + update.FillID
+		if update.FillID != "" && e.fills[id] {
+			return 0, 0
+		}
+		e.fills[id] = true
+		e.orders[update.OrderNo] = orderProgress{filled: prev.filled + update.FillQuantity, average: update.AveragePrice}
+		price := update.FillPrice
+		if price == 0 {
+			price = update.AveragePrice
+		}
+		return update.FillQuantity, price
+	}
+
+	if update.FilledQuantity <= prev.filled {
+		return 0, 0
+	}
+	qty := update.FilledQuantity - prev.filled
+	// Recover the price of the increment from the change of the average price.
+	price := (update.AveragePrice*float64(update.FilledQuantity) - prev.average*float64(prev.filled)) / float64(qty)
+	e.orders[update.OrderNo] = orderProgress{filled: update.FilledQuantity, average: update.AveragePrice}
+	return qty, price
+}
+
+// applyFill updates the net quantity, average price and realized P&L with a signed fill.
+func (p *LivePosition) applyFill(qty int64, price float64) {
+	switch {
+	case p.NetQty == 0 || (p.NetQty > 0) == (qty > 0):
+		// Opening or adding to the position.
+		total := p.NetQty + qty
+		p.AvgPrice = (p.AvgPrice*float64(p.NetQty) + price*float64(qty)) / float64(total)
+		p.NetQty = total
+	default:
+		closed := min(abs64(qty), abs64(p.NetQty))
+		direction := float64(p.NetQty) / math.Abs(float64(p.NetQty))
+		p.RealizedPnL += float64(closed) * (price - p.AvgPrice) * direction
+		p.NetQty += qty
+		switch {
+		case p.NetQty == 0:
+			p.AvgPrice = 0
+		case (p.NetQty > 0) == (qty > 0):
+			// The fill reversed the position; the remainder opens at the fill price.
+			p.AvgPrice = price
+		}
+	}
+}
+
+// Reconcile compares the positions with GetPositions, reports the positions that disagree
+// and replaces them with the broker's view. Positions with a fill within ReconcileSettle
+// are skipped, as the broker may not reflect the fill yet.
+//
+// Returns:
+//   - The divergences found.
+//   - An error if positions cannot be retrieved.
+func (e *PositionEngine) Reconcile() ([]PositionDivergence, error) {
+	positions, err := e.client.GetPositions()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	broker := make(map[string]Position, len(positions))
+	for _, p := range positions {
+		broker[positionKey(p.Token, p.Product)] = p
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var divergences []PositionDivergence
+	settled := func(p *LivePosition) bool { return now.Sub(p.UpdatedAt) >= e.ReconcileSettle }
+
+	for key, bp := range broker {
+		local, ok := e.positions[key]
+		if ok && !settled(local) {
+			continue
+		}
+		var localQty int64
+		if ok {
+			localQty = local.NetQty
+		}
+		if brokerQty := parseInt(bp.Qty); localQty != brokerQty {
+			divergences = append(divergences, PositionDivergence{
+				Key: key, Exchange: bp.Exchange, Symbol: bp.Symbol, Product: bp.Product,
+				LocalQty: localQty, BrokerQty: brokerQty, DetectedAt: now,
+			})
+			e.positions[key] = livePositionOf(bp, now)
+		}
+	}
+	for key, local := range e.positions {
+		if _, ok := broker[key]; ok || local.NetQty == 0 || !settled(local) {
+			continue
+		}
+		divergences = append(divergences, PositionDivergence{
+			Key: key, Exchange: local.Exchange, Symbol: local.Symbol, Product: local.Product,
+			LocalQty: local.NetQty, DetectedAt: now,
+		})
+		delete(e.positions, key)
+	}
+
+	for _, d := range divergences {
+		e.client.log().Warn().Str("symbol", d.Symbol).Int64("localQty", d.LocalQty).Int64("brokerQty", d.BrokerQty).
+			Msg("Position diverged from broker")
+	}
+	sort.Slice(divergences, func(i, j int) bool { return divergences[i].Key < divergences[j].Key })
+	return divergences, nil
+}
+
+// Positions returns the open positions, ordered by symbol.
+func (e *PositionEngine) Positions() []LivePosition {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	positions := make([]LivePosition, 0, len(e.positions))
+	for _, p := range e.positions {
+		if p.NetQty != 0 {
+			positions = append(positions, *p)
+		}
+	}
+	sort.Slice(positions, func(i, j int) bool {
+		if positions[i].Symbol != positions[j].Symbol {
+			return positions[i].Symbol < positions[j].Symbol
+		}
+		return positions[i].Product < positions[j].Product
+	})
+	return positions
+}
+
+// Position returns the position in a token and product.
+//
+// Parameters:
+//   - token: The unique identifier of the instrument.
+//   - product: The product code or name (e.g., "M", "NRML").
+//
+// Returns:
+//   - The position and true if the engine holds one; otherwise, a zero LivePosition and false.
+func (e *PositionEngine) Position(token int64, product string) (LivePosition, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	p, ok := e.positions[positionKey(strconv.FormatInt(token, 10), product)]
+	if !ok {
+		return LivePosition{}, false
+	}
+	return *p, true
+}
+
+// positionKey identifies a position by token and normalized product.
+func positionKey(token, product string) string {
+	if p, err := ParseProduct(product); err == nil {
+		product = string(p)
+	}
+	return token + ":" + product
+}
+
+// livePositionOf converts a position reported by the broker.
+func livePositionOf(p Position, now time.Time) *LivePosition {
+	qty := parseInt(p.Qty)
+	avg := parseFloat(p.AvgPrice)
+	if avg == 0 {
+		avg = parseFloat(p.NetUploadPrice)
+	}
+	return &LivePosition{
+		Exchange:    p.Exchange,
+		Symbol:      p.Symbol,
+		Token:       parseInt(p.Token),
+		Product:     p.Product,
+		NetQty:      qty,
+		BuyQty:      parseInt(p.DayBuyQty) + parseInt(p.CarryForwardBuyQty),
+		SellQty:     parseInt(p.DaySellQty) + parseInt(p.CarryForwardSellQty),
+		AvgPrice:    avg,
+		RealizedPnL: parseFloat(p.RealisedPnL),
+		UpdatedAt:   now,
+	}
+}
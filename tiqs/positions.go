@@ -1,11 +1,6 @@
 package tiqs
 
-import (
-	"encoding/json"
-	"fmt"
-
-	"github.com/rs/zerolog/log"
-)
+import ()
 
 // Position represents a trading position held by the user.
 
@@ -67,27 +62,27 @@ type PositionsResponse struct {
 //   - A slice of Position structs containing all active positions if successful.
 //   - An error if the request fails or the response cannot be parsed.
 func (c *Client) GetPositions() ([]Position, error) {
-	endpoint := "/user/positions"
+	endpoint := c.endpoint(EndpointPositions)
 
 	// Send a GET request to the API to fetch position details.
 	resp, err := c.request(endpoint, "GET", nil)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to fetch positions")
+		c.log().Error().Err(err).Msg("Failed to fetch positions")
 		return nil, err
 	}
 
 	var result PositionsResponse
 	// Parse the JSON response into the PositionsResponse struct.
-	if err := json.Unmarshal(resp, &result); err != nil {
-		log.Error().Err(err).Msg("Failed to parse positions response")
+	if err := c.decode(EndpointPositions, resp, &result); err != nil {
+		c.log().Error().Err(err).Msg("Failed to parse positions response")
 		return nil, err
 	}
 
 	// Check if the API response status indicates success.
 	if result.Status != "success" {
-		return nil, fmt.Errorf("positions retrieval failed")
+		return nil, newAPIError("positions retrieval", endpoint, 0, resp)
 	}
 
-	log.Info().Msg("Positions retrieved successfully")
+	c.log().Info().Msg("Positions retrieved successfully")
 	return result.Data, nil
 }
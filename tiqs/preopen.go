@@ -0,0 +1,56 @@
+package tiqs
+
+import (
+	"time"
+
+	"github.com/Abhi13027/go-tiqs/ticks"
+)
+
+// MarkPreOpen flags the candles that fall in the pre-open session of the clock.
+//
+// The candles are modified in place; candles whose time cannot be parsed are left unflagged.
+//
+// Parameters:
+//   - candles: The candle series to flag.
+//   - clock: The market clock defining the pre-open window.
+//
+// Returns:
+//   - The same slice, for chaining.
+func MarkPreOpen(candles []HistoricalCandle, clock *MarketClock) []HistoricalCandle {
+	for i := range candles {
+		if t, ok := parseTimestamp(candles[i].Time); ok {
+			candles[i].PreOpen = clock.IsPreOpen(t)
+		}
+	}
+	return candles
+}
+
+// ExcludePreOpen returns the candles that are not flagged as pre-open.
+//
+// Parameters:
+//   - candles: The candle series, flagged with MarkPreOpen.
+//
+// Returns:
+//   - A new slice without pre-open candles.
+func ExcludePreOpen(candles []HistoricalCandle) []HistoricalCandle {
+	kept := make([]HistoricalCandle, 0, len(candles))
+	for _, candle := range candles {
+		if !candle.PreOpen {
+			kept = append(kept, candle)
+		}
+	}
+	return kept
+}
+
+// IsPreOpenTick reports whether a websocket tick was traded in the pre-open session,
+// based on its last trade time. Heartbeats and ticks without a trade time are never pre-open.
+//
+// Parameters:
+//   - tick: The tick to check.
+//   - clock: The market clock defining the pre-open window.
+func IsPreOpenTick(tick ticks.TickData, clock *MarketClock) bool {
+	if tick.Token < 0 || tick.LTT <= 0 {
+		return false
+	}
+	return clock.IsPreOpen(time.Unix(int64(tick.LTT), 0))
+}
@@ -0,0 +1,149 @@
+package tiqs
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// defaultPricePrecision is the precision used when instrument metadata is unavailable.
+// Equity and F&O prices are quoted in paise.
+const defaultPricePrecision = 2
+
+// PriceDivisor returns the factor that converts the instrument's integer prices into rupees.
+//
+// Integer prices are scaled by 10^PricePrecision, e.g., paise (100) for equities and
+// 10^4 for currency derivatives. When the instrument master has no precision, the
+// precision of the instrument's segment is used.
+func (i Instrument) PriceDivisor() float64 {
+	return math.Pow10(i.Precision())
+}
+
+// Precision returns the number of decimal places of the instrument's prices, falling
+// back to the precision of its segment when the instrument master has none.
+func (i Instrument) Precision() int {
+	if i.PricePrecision > 0 {
+		return i.PricePrecision
+	}
+	return Exchange(strings.ToUpper(i.Exchange)).Segment().Rules().PricePrecision
+}
+
+// Price converts an integer price of the instrument into rupees.
+//
+// Parameters:
+//   - scaled: The price as sent in ticks, quotes and candles (e.g., paise).
+//
+// Returns:
+//   - The price in rupees.
+func (i Instrument) Price(scaled int64) float64 {
+	return float64(scaled) / i.PriceDivisor()
+}
+
+// ScaledPrice converts a price in rupees into the instrument's integer scale, the inverse
+// of Price.
+func (i Instrument) ScaledPrice(rupees float64) int64 {
+	return int64(math.Round(rupees * i.PriceDivisor()))
+}
+
+// RoundToTick rounds a price in rupees to the nearest multiple of the instrument's tick
+// size. Without a tick size, the price is rounded to the instrument's precision.
+func (i Instrument) RoundToTick(rupees float64) float64 {
+	divisor := i.PriceDivisor()
+	if i.TickSize <= 0 {
+		return math.Round(rupees*divisor) / divisor
+	}
+	ticks := math.Round(rupees / i.TickSize)
+	// Snap to the precision so that, e.g., 2 * 0.05 yields 0.1 rather than 0.10000000000000001.
+	return math.Round(ticks*i.TickSize*divisor) / divisor
+}
+
+// FormatPrice formats a price in rupees with the instrument's precision, as expected by
+// OrderRequest.Price and OrderRequest.TriggerPrice.
+func (i Instrument) FormatPrice(rupees float64) string {
+	return strconv.FormatFloat(rupees, 'f', i.Precision(), 64)
+}
+
+// DecimalQuote represents a market quote with prices converted into rupees.
+type DecimalQuote struct {
+	Token        int64   `json:"token"`        // Unique identifier for the instrument.
+	LTP          float64 `json:"ltp"`          // Last traded price in rupees.
+	Open         float64 `json:"open"`         // Opening price in rupees.
+	High         float64 `json:"high"`         // Highest price of the session in rupees.
+	Low          float64 `json:"low"`          // Lowest price of the session in rupees.
+	Close        float64 `json:"close"`        // Previous close in rupees.
+	Volume       int64   `json:"volume"`       // Total traded volume.
+	TotalBuyQty  int64   `json:"totalBuyQty"`  // Total quantity of buy orders.
+	TotalSellQty int64   `json:"totalSellQty"` // Total quantity of sell orders.
+	LTT          int64   `json:"ltt"`          // Last trade time (epoch timestamp).
+}
+
+// Decimal converts the quote's integer prices into rupees using the given divisor.
+//
+// Parameters:
+//   - divisor: The price divisor, typically Instrument.PriceDivisor().
+//
+// Returns:
+//   - A DecimalQuote with scaled prices.
+func (q MarketQuote) Decimal(divisor float64) DecimalQuote {
+	return DecimalQuote{
+		Token:        q.Token,
+		LTP:          float64(q.LTP) / divisor,
+		Open:         float64(q.Open) / divisor,
+		High:         float64(q.High) / divisor,
+		Low:          float64(q.Low) / divisor,
+		Close:        float64(q.Close) / divisor,
+		Volume:       q.Volume,
+		TotalBuyQty:  q.TotalBuyQty,
+		TotalSellQty: q.TotalSellQty,
+		LTT:          q.LTT,
+	}
+}
+
+// GetMarketQuoteDecimal fetches market data for a single instrument with prices in rupees.
+//
+// Prices are scaled with the instrument's precision looked up in the attached instrument
+// store; without a store, or for unknown tokens, paise are assumed.
+//
+// Parameters:
+//   - token: The unique identifier of the instrument.
+//   - mode: Market mode (e.g., "full", "ltp", "depth").
+//
+// Returns:
+//   - A pointer to a DecimalQuote if successful.
+//   - An error if the request fails or the response cannot be parsed.
+func (c *Client) GetMarketQuoteDecimal(token int64, mode string) (*DecimalQuote, error) {
+	quote, err := c.GetMarketQuote(token, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	decimal := quote.Decimal(c.priceDivisor(quote.Token))
+	return &decimal, nil
+}
+
+// GetMarketQuotesDecimal fetches market data for multiple instruments with prices in rupees.
+//
+// Parameters:
+//   - tokens: A slice of unique identifiers representing instruments.
+//   - mode: Market mode (e.g., "full", "ltp", "depth").
+//
+// Returns:
+//   - A slice of DecimalQuote structs if successful.
+//   - An error if the request fails or the response cannot be parsed.
+func (c *Client) GetMarketQuotesDecimal(tokens []int64, mode string) ([]DecimalQuote, error) {
+	quotes, err := c.GetMarketQuotes(tokens, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	decimals := make([]DecimalQuote, len(quotes))
+	for i, quote := range quotes {
+		decimals[i] = quote.Decimal(c.priceDivisor(quote.Token))
+	}
+	return decimals, nil
+}
+
+// priceDivisor returns the price divisor for a token using the attached instrument store.
+func (c *Client) priceDivisor(token int64) float64 {
+	return c.PriceConverter().Divisor(token)
+}
@@ -0,0 +1,201 @@
+package tiqs
+
+import (
+	"time"
+
+	"github.com/Abhi13027/go-tiqs/ticks"
+)
+
+// PriceConverter converts the integer prices of ticks, quotes and candles into rupees
+// using the precision of each instrument in the instrument master.
+//
+// Ticks carry prices as int32 and REST quotes and candles as int64, all scaled by
+// 10^PricePrecision: paise for equities and F&O, 10^4 for currency derivatives. Tokens
+// missing from the store, and every token of a converter without a store, are assumed
+// to be quoted in paise.
+type PriceConverter struct {
+	store *InstrumentStore
+}
+
+// DecimalLevel is a level of the market depth with its price in rupees.
+type DecimalLevel struct {
+	Price    float64 `json:"price"`    // Price of the level in rupees.
+	Quantity int64   `json:"quantity"` // Total quantity at the level.
+	Orders   int16   `json:"orders"`   // Number of orders at the level.
+}
+
+// DecimalTick is a tick with its prices converted into rupees.
+type DecimalTick struct {
+	Token        int32           `json:"token"`            // Unique identifier for the instrument.
+	LTP          float64         `json:"ltp"`              // Last traded price in rupees.
+	Change       float64         `json:"change"`           // Change from the previous close in rupees.
+	ChangePct    float64         `json:"changePct"`        // Change from the previous close in percent.
+	LTQ          int32           `json:"ltq"`              // Last traded quantity.
+	AvgPrice     float64         `json:"avgPrice"`         // Volume-weighted average price in rupees.
+	Open         float64         `json:"open"`             // Opening price in rupees.
+	High         float64         `json:"high"`             // Highest price of the session in rupees.
+	Low          float64         `json:"low"`              // Lowest price of the session in rupees.
+	Close        float64         `json:"close"`            // Previous close in rupees.
+	LowerLimit   float64         `json:"lowerLimit"`       // Lower circuit limit in rupees.
+	UpperLimit   float64         `json:"upperLimit"`       // Upper circuit limit in rupees.
+	Volume       int64           `json:"volume"`           // Total traded volume.
+	TotalBuyQty  int64           `json:"totalBuyQty"`      // Total quantity of buy orders.
+	TotalSellQty int64           `json:"totalSellQty"`     // Total quantity of sell orders.
+	OI           int32           `json:"oi"`               // Open interest.
+	LTT          int32           `json:"ltt"`              // Last trade time (epoch timestamp).
+	Bids         [5]DecimalLevel `json:"bids"`             // Best bids, in rupees.
+	Asks         [5]DecimalLevel `json:"asks"`             // Best asks, in rupees.
+	Greeks       *ticks.Greeks   `json:"greeks,omitempty"` // Option greeks, if streamed.
+}
+
+// DecimalCandle is an OHLC bar, live or historical, with its prices converted into rupees.
+type DecimalCandle struct {
+	Token   int64     `json:"token"`             // Unique identifier for the instrument.
+	Time    time.Time `json:"time"`              // Start of the bar.
+	Open    float64   `json:"open"`              // Opening price in rupees.
+	High    float64   `json:"high"`              // Highest price in rupees.
+	Low     float64   `json:"low"`               // Lowest price in rupees.
+	Close   float64   `json:"close"`             // Closing price in rupees.
+	Volume  int64     `json:"volume"`            // Traded volume during the bar.
+	OI      int64     `json:"oi"`                // Open interest at the end of the bar, if known.
+	Filled  bool      `json:"filled,omitempty"`  // Set on historical bars synthesized by FillGaps.
+	PreOpen bool      `json:"preOpen,omitempty"` // Set on historical bars from the pre-open session.
+}
+
+// NewPriceConverter creates a converter reading precisions from an instrument store.
+//
+// Parameters:
+//   - store: The instrument master; nil assumes paise for every token.
+//
+// Returns:
+//   - A pointer to a newly created PriceConverter.
+func NewPriceConverter(store *InstrumentStore) *PriceConverter {
+	return &PriceConverter{store: store}
+}
+
+// PriceConverter returns a converter bound to the instrument store attached to the client
+// (see LoadInstruments and SetInstrumentStore).
+func (c *Client) PriceConverter() *PriceConverter {
+	return NewPriceConverter(c.instruments)
+}
+
+// Instrument returns the instrument of a token, or a zero Instrument, which converts with
+// the default precision, if the token is unknown.
+func (p *PriceConverter) Instrument(token int64) Instrument {
+	if p.store != nil {
+		if inst, ok := p.store.Get(token); ok {
+			return inst
+		}
+	}
+	return Instrument{}
+}
+
+// Divisor returns the factor that converts the token's integer prices into rupees.
+func (p *PriceConverter) Divisor(token int64) float64 {
+	return p.Instrument(token).PriceDivisor()
+}
+
+// Rupees converts an integer price of a token into rupees.
+func (p *PriceConverter) Rupees(token int64, scaled int64) float64 {
+	return float64(scaled) / p.Divisor(token)
+}
+
+// Scaled converts a price in rupees into the token's integer scale.
+func (p *PriceConverter) Scaled(token int64, rupees float64) int64 {
+	return p.Instrument(token).ScaledPrice(rupees)
+}
+
+// RoundToTick rounds a price in rupees to the token's tick size.
+func (p *PriceConverter) RoundToTick(token int64, rupees float64) float64 {
+	return p.Instrument(token).RoundToTick(rupees)
+}
+
+// FormatPrice rounds a price in rupees to the token's tick size and formats it with the
+// token's precision, ready for OrderRequest.Price.
+func (p *PriceConverter) FormatPrice(token int64, rupees float64) string {
+	inst := p.Instrument(token)
+	return inst.FormatPrice(inst.RoundToTick(rupees))
+}
+
+// Quote converts a market quote into rupees.
+func (p *PriceConverter) Quote(q MarketQuote) DecimalQuote {
+	return q.Decimal(p.Divisor(q.Token))
+}
+
+// Tick converts a tick, including its market depth, into rupees.
+func (p *PriceConverter) Tick(tick ticks.TickData) DecimalTick {
+	divisor := p.Divisor(int64(tick.Token))
+	rupees := func(v int32) float64 { return float64(v) / divisor }
+
+	decimal := DecimalTick{
+		Token:        tick.Token,
+		LTP:          rupees(tick.LTP),
+		LTQ:          tick.LTQ,
+		AvgPrice:     rupees(tick.AvgPrice),
+		Open:         rupees(tick.Open),
+		High:         rupees(tick.High),
+		Low:          rupees(tick.Low),
+		Close:        rupees(tick.Close),
+		LowerLimit:   rupees(tick.LowerLimit),
+		UpperLimit:   rupees(tick.UpperLimit),
+		Volume:       tick.Volume,
+		TotalBuyQty:  tick.TotalBuyQty,
+		TotalSellQty: tick.TotalSellQty,
+		OI:           tick.OI,
+		LTT:          tick.LTT,
+		Greeks:       tick.Greeks,
+	}
+	// NetChange is a truncated percentage in LTP packets and a price difference elsewhere,
+	// so the change is derived from the prices instead.
+	if tick.Close > 0 && tick.LTP > 0 {
+		decimal.Change = rupees(tick.LTP - tick.Close)
+		decimal.ChangePct = float64(tick.LTP-tick.Close) / float64(tick.Close) * 100
+	}
+	for i, level := range tick.MarketDepth.Bids {
+		decimal.Bids[i] = DecimalLevel{Price: rupees(level.Price), Quantity: level.Quantity, Orders: level.Orders}
+	}
+	for i, level := range tick.MarketDepth.Asks {
+		decimal.Asks[i] = DecimalLevel{Price: rupees(level.Price), Quantity: level.Quantity, Orders: level.Orders}
+	}
+	return decimal
+}
+
+// Candle converts a bar built from ticks (see ticks.CandleAggregator) into rupees.
+func (p *PriceConverter) Candle(candle ticks.Candle) DecimalCandle {
+	divisor := p.Divisor(int64(candle.Token))
+	return DecimalCandle{
+		Token:  int64(candle.Token),
+		Time:   candle.Start,
+		Open:   float64(candle.Open) / divisor,
+		High:   float64(candle.High) / divisor,
+		Low:    float64(candle.Low) / divisor,
+		Close:  float64(candle.Close) / divisor,
+		Volume: candle.Volume,
+		OI:     int64(candle.OI),
+	}
+}
+
+// HistoricalCandles converts the candles of a token returned by GetHistoricalData into
+// rupees. Candles whose time cannot be parsed keep a zero Time.
+func (p *PriceConverter) HistoricalCandles(token int64, candles []HistoricalCandle) []DecimalCandle {
+	divisor := p.Divisor(token)
+	decimals := make([]DecimalCandle, len(candles))
+	for i, candle := range candles {
+		t, _ := parseTimestamp(candle.Time)
+		decimals[i] = DecimalCandle{
+			Token:   token,
+			Time:    t,
+			Open:    float64(candle.Open) / divisor,
+			High:    float64(candle.High) / divisor,
+			Low:     float64(candle.Low) / divisor,
+			Close:   float64(candle.Close) / divisor,
+			Volume:  candle.Volume,
+			Filled:  candle.Filled,
+			PreOpen: candle.PreOpen,
+		}
+		if candle.OI != nil {
+			decimals[i].OI = *candle.OI
+		}
+	}
+	return decimals
+}
@@ -0,0 +1,313 @@
+package tiqs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket limiting how often requests are sent.
+//
+// Tokens are added continuously at the configured rate up to the burst size. A client
+// limits each rate class separately (see SetRateLimits, SetDataRateLimiter and
+// SetOrderRateLimiter), so bulk downloads cannot starve live trading calls of request
+// budget.
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter creates a token bucket that starts full.
+//
+// Parameters:
+//   - rate: Requests allowed per second on average; zero allows only the initial burst.
+//   - burst: Maximum number of requests allowed at once.
+//
+// Returns:
+//   - A pointer to a newly created RateLimiter.
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Allow takes a token if one is available without waiting.
+//
+// Returns:
+//   - true if the request may be sent now; otherwise, false.
+func (l *RateLimiter) Allow() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refillLocked(time.Now())
+	if l.tokens >= 1 {
+		l.tokens--
+		return true
+	}
+	return false
+}
+
+// Wait blocks until a token is available or the context is done.
+//
+// Returns:
+//   - An error if the context is done before a token becomes available.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		l.refillLocked(now)
+		if l.tokens >= 1 {
+			l.tokens--
+			l.mu.Unlock()
+			return nil
+		}
+		if l.rate <= 0 {
+			l.mu.Unlock()
+			return fmt.Errorf("rate limit exhausted")
+		}
+		wait := time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+		l.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// refillLocked adds the tokens accumulated since the last refill. The caller must hold l.mu.
+func (l *RateLimiter) refillLocked(now time.Time) {
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+}
+
+// SetDataRateLimiter sets the limiter shared by the data endpoints: market quotes,
+// historical candles and option chains.
+//
+// It replaces the budget of RateClassQuotes and RateClassHistorical set with
+// SetRateLimits, the two classes then drawing from the same bucket.
+//
+// Parameters:
+//   - limiter: The limiter to use, or nil to disable data rate limiting.
+func (c *Client) SetDataRateLimiter(limiter *RateLimiter) {
+	c.setClassLimiter(limiter, RateClassQuotes, RateClassHistorical)
+}
+
+// SetOrderRateLimiter sets the limiter used by order placement, modification and cancellation.
+//
+// It replaces the budget of RateClassOrders set with SetRateLimits.
+//
+// Parameters:
+//   - limiter: The limiter to use, or nil to disable order rate limiting.
+func (c *Client) SetOrderRateLimiter(limiter *RateLimiter) {
+	c.setClassLimiter(limiter, RateClassOrders)
+}
+
+// setClassLimiter makes limiter the budget of classes, or removes their budget if it is nil.
+func (c *Client) setClassLimiter(limiter *RateLimiter, classes ...RateClass) {
+	limits := make(map[RateClass]*classLimiter, len(c.rateLimits)+len(classes))
+	for class, l := range c.rateLimits {
+		limits[class] = l
+	}
+	for _, class := range classes {
+		if limiter == nil {
+			delete(limits, class)
+		} else {
+			limits[class] = &classLimiter{buckets: []*RateLimiter{limiter}}
+		}
+	}
+	c.rateLimits = limits
+}
+
+// drain empties the bucket, e.g., after the server reported that the limit was exceeded.
+func (l *RateLimiter) drain() {
+	l.mu.Lock()
+	l.refillLocked(time.Now())
+	l.tokens = 0
+	l.mu.Unlock()
+}
+
+// refund returns a token taken by Allow that was not used.
+func (l *RateLimiter) refund() {
+	l.mu.Lock()
+	l.tokens = min(l.burst, l.tokens+1)
+	l.mu.Unlock()
+}
+
+// RateClass groups the endpoints that share a rate limit.
+type RateClass string
+
+const (
+	RateClassOrders     RateClass = "orders"     // Order placement, modification and cancellation.
+	RateClassQuotes     RateClass = "quotes"     // Market quotes and option chains.
+	RateClassHistorical RateClass = "historical" // Historical candles.
+	RateClassGeneral    RateClass = "general"    // Every other endpoint.
+)
+
+// RateLimit is the request budget of a rate class. Zero fields do not limit.
+type RateLimit struct {
+	PerSecond int `json:"perSecond,omitempty" yaml:"perSecond,omitempty"` // Requests allowed per second.
+	PerMinute int `json:"perMinute,omitempty" yaml:"perMinute,omitempty"` // Requests allowed per minute.
+}
+
+// RateLimits holds the request budget of every rate class.
+type RateLimits struct {
+	Orders     RateLimit `json:"orders,omitempty" yaml:"orders,omitempty"`         // Order placement, modification and cancellation.
+	Quotes     RateLimit `json:"quotes,omitempty" yaml:"quotes,omitempty"`         // Market quotes and option chains.
+	Historical RateLimit `json:"historical,omitempty" yaml:"historical,omitempty"` // Historical candles.
+	General    RateLimit `json:"general,omitempty" yaml:"general,omitempty"`       // Every other endpoint.
+}
+
+// classLimiter enforces the budget of a rate class: a request waits for every bucket,
+// e.g., a per-minute and a per-second one.
+type classLimiter struct {
+	buckets []*RateLimiter
+}
+
+// newClassLimiter returns the limiter of a budget, or nil if the budget does not limit.
+func newClassLimiter(limit RateLimit) *classLimiter {
+	l := &classLimiter{}
+	if limit.PerMinute > 0 {
+		l.buckets = append(l.buckets, NewRateLimiter(float64(limit.PerMinute)/60, limit.PerMinute))
+	}
+	if limit.PerSecond > 0 {
+		l.buckets = append(l.buckets, NewRateLimiter(float64(limit.PerSecond), limit.PerSecond))
+	}
+	if len(l.buckets) == 0 {
+		return nil
+	}
+	return l
+}
+
+// wait blocks until every bucket allows a request.
+func (l *classLimiter) wait(ctx context.Context) error {
+	for _, b := range l.buckets {
+		if err := b.Wait(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// allow takes a request from every bucket if all allow it without waiting.
+func (l *classLimiter) allow() bool {
+	for i, b := range l.buckets {
+		if !b.Allow() {
+			for _, taken := range l.buckets[:i] {
+				taken.refund()
+			}
+			return false
+		}
+	}
+	return true
+}
+
+// drain empties every bucket.
+func (l *classLimiter) drain() {
+	for _, b := range l.buckets {
+		b.drain()
+	}
+}
+
+// SetRateLimits replaces the request budget of every rate class. Each request waits, in
+// Client.request, until the budget of its class allows it, so bulk operations such as
+// downloading candles for hundreds of instruments are paced rather than rejected. When
+// the server still answers 429 Too Many Requests, the budget of the class is emptied so
+// that the following requests back off.
+//
+// Clients created with NewClient have no budget and are not limited until one is set.
+// The SDK ships no default budget because the broker's per-account limits are not
+// documented in its sources; take them from the broker's API documentation for the
+// account, and leave some headroom.
+//
+// The budgets replace the limiters set with SetDataRateLimiter and SetOrderRateLimiter,
+// which in turn replace the budget of their classes only, so that every request waits for
+// a single limiter. A zero RateLimits removes every limit.
+//
+// Parameters:
+//   - limits: The budget of every rate class.
+func (c *Client) SetRateLimits(limits RateLimits) {
+	c.rateLimits = make(map[RateClass]*classLimiter)
+	for class, limit := range map[RateClass]RateLimit{
+		RateClassOrders:     limits.Orders,
+		RateClassQuotes:     limits.Quotes,
+		RateClassHistorical: limits.Historical,
+		RateClassGeneral:    limits.General,
+	} {
+		if l := newClassLimiter(limit); l != nil {
+			c.rateLimits[class] = l
+		}
+	}
+}
+
+// RateClassOf returns the rate class of a request.
+//
+// Parameters:
+//   - method: The HTTP method of the request.
+//   - endpoint: The path of the request, relative to BaseURL.
+//
+// Returns:
+//   - The rate class whose budget the request consumes.
+func (c *Client) RateClassOf(method, endpoint string) RateClass {
+	switch {
+	case method != "GET" && strings.HasPrefix(endpoint, c.endpointPrefix(EndpointPlaceOrder)):
+		return RateClassOrders
+	case strings.HasPrefix(endpoint, c.endpointPrefix(EndpointQuote)),
+		strings.HasPrefix(endpoint, c.endpointPrefix(EndpointQuotes)),
+		strings.HasPrefix(endpoint, c.endpointPrefix(EndpointOptionChain)):
+		return RateClassQuotes
+	case strings.HasPrefix(endpoint, c.endpointPrefix(EndpointCandles)):
+		return RateClassHistorical
+	}
+	return RateClassGeneral
+}
+
+// endpointPrefix returns the fixed part of an endpoint's path in the configured API
+// version, before its first argument or query.
+func (c *Client) endpointPrefix(name EndpointName) string {
+	spec, _ := LookupEndpoint(c.apiVersion(), name)
+	prefix, _, _ := strings.Cut(spec.Path, "%")
+	prefix, _, _ = strings.Cut(prefix, "?")
+	return prefix
+}
+
+// waitRate waits until the budget of the request's class allows it.
+func (c *Client) waitRate(method, endpoint string) error {
+	if len(c.rateLimits) == 0 {
+		return nil
+	}
+	l, ok := c.rateLimits[c.RateClassOf(method, endpoint)]
+	if !ok {
+		return nil
+	}
+	return l.wait(context.Background())
+}
+
+// allowRate takes a request from the budget of the request's class if it is available
+// without waiting.
+func (c *Client) allowRate(method, endpoint string) bool {
+	l, ok := c.rateLimits[c.RateClassOf(method, endpoint)]
+	return !ok || l.allow()
+}
+
+// rateLimited empties the budget of a request's class after the server answered 429.
+func (c *Client) rateLimited(method, endpoint string) {
+	if l, ok := c.rateLimits[c.RateClassOf(method, endpoint)]; ok {
+		c.log().Warn().Str("class", string(c.RateClassOf(method, endpoint))).Msg("Rate limit exceeded; backing off")
+		l.drain()
+	}
+}
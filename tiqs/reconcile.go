@@ -0,0 +1,225 @@
+package tiqs
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ReconcileIssueKind classifies a mismatch between the order book and the trade book.
+type ReconcileIssueKind string
+
+const (
+	IssueUnknownOrder ReconcileIssueKind = "UNKNOWN_ORDER" // A trade refers to an order missing from the order book.
+	IssueFillMismatch ReconcileIssueKind = "FILL_MISMATCH" // An order's filled quantity differs from the sum of its trades.
+)
+
+// ReconcileIssue describes a mismatch found while reconciling trades with orders.
+type ReconcileIssue struct {
+	Kind    ReconcileIssueKind `json:"kind"`    // Kind of mismatch.
+	OrderID string             `json:"orderId"` // Order number involved.
+	FillID  string             `json:"fillId"`  // Fill involved, for unknown orders.
+	Detail  string             `json:"detail"`  // Human-readable description.
+}
+
+// OrderPnL is the P&L and charges attributed to a single order.
+type OrderPnL struct {
+	OrderID         string  `json:"orderId"`         // Order number.
+	Tag             string  `json:"tag"`             // Strategy tag of the order.
+	Exchange        string  `json:"exchange"`        // Exchange of the instrument.
+	Symbol          string  `json:"symbol"`          // Trading symbol of the instrument.
+	Token           string  `json:"token"`           // Unique identifier for the instrument.
+	Product         string  `json:"product"`         // Product type of the order.
+	TransactionType string  `json:"transactionType"` // Side of the order.
+	FilledQty       int64   `json:"filledQty"`       // Quantity filled according to the trade book.
+	AvgFillPrice    float64 `json:"avgFillPrice"`    // Volume-weighted price of the fills.
+	Turnover        float64 `json:"turnover"`        // Traded value of the fills.
+	Fills           int     `json:"fills"`           // Number of fills.
+	RealizedPnL     float64 `json:"realizedPnL"`     // P&L realized by the fills, before charges.
+	Charges         float64 `json:"charges"`         // Estimated charges of the fills.
+	KnownOrder      bool    `json:"knownOrder"`      // Whether the order was found in the order book.
+}
+
+// NetPnL returns the realized P&L of the order after charges.
+func (o OrderPnL) NetPnL() float64 {
+	return o.RealizedPnL - o.Charges
+}
+
+// TagPnL aggregates the P&L of the orders placed with the same strategy tag.
+type TagPnL struct {
+	Tag         string  `json:"tag"`         // Strategy tag; empty for untagged orders.
+	Orders      int     `json:"orders"`      // Number of orders with fills.
+	Turnover    float64 `json:"turnover"`    // Traded value.
+	RealizedPnL float64 `json:"realizedPnL"` // P&L before charges.
+	Charges     float64 `json:"charges"`     // Estimated charges.
+	NetPnL      float64 `json:"netPnL"`      // P&L after charges.
+}
+
+// Reconciliation is the result of joining the trade book with the order book.
+type Reconciliation struct {
+	Orders       []OrderPnL       `json:"orders"`       // One entry per order with fills, in order of first fill.
+	ByTag        []TagPnL         `json:"byTag"`        // P&L per strategy tag, largest net P&L first.
+	Issues       []ReconcileIssue `json:"issues"`       // Mismatches between the two books.
+	RealizedPnL  float64          `json:"realizedPnL"`  // Total P&L before charges.
+	TotalCharges float64          `json:"totalCharges"` // Total estimated charges.
+	NetPnL       float64          `json:"netPnL"`       // Total P&L after charges.
+}
+
+// ReconcileTrades joins fills with their orders and attributes P&L and charges to each order.
+//
+// Fills are replayed in time order per instrument and product, as in ComputeCostBasis, and
+// the P&L realized by each fill is credited to the order it belongs to. Each order is then
+// aggregated under its strategy tag (the order's remarks, or the fill's when the order is
+// unknown). Trades of orders missing from the order book, and orders whose filled quantity
+// disagrees with their trades, are reported as issues.
+//
+// Parameters:
+//   - orders: The order book rows (e.g., the Detail of the entries returned by GetOrderBook).
+//   - trades: The fills (e.g., from GetTradeBook).
+//   - charges: The charge model, or nil to ignore charges.
+//
+// Returns:
+//   - The reconciliation of the two books.
+func ReconcileTrades(orders []OrderDetail, trades []Trade, charges ChargeModel) Reconciliation {
+	byID := make(map[string]OrderDetail, len(orders))
+	for _, o := range orders {
+		byID[o.ID] = o
+	}
+
+	ordered := append([]Trade(nil), trades...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return fillTime(ordered[i]).Before(fillTime(ordered[j]))
+	})
+
+	var result Reconciliation
+	positions := make(map[string]*CostBasis)
+	pnls := make(map[string]*OrderPnL)
+	var orderIDs []string
+	for _, t := range ordered {
+		order, known := byID[t.ID]
+		pnl, ok := pnls[t.ID]
+		if !ok {
+			pnl = &OrderPnL{
+				OrderID:         t.ID,
+				Tag:             t.Remarks,
+				Exchange:        t.Exchange,
+				Symbol:          t.Symbol,
+				Token:           t.Token,
+				Product:         t.Product,
+				TransactionType: t.TransactionType,
+				KnownOrder:      known,
+			}
+			if known {
+				pnl.Tag = order.Remarks
+			}
+			pnls[t.ID] = pnl
+			orderIDs = append(orderIDs, t.ID)
+		}
+		if !known {
+			result.Issues = append(result.Issues, ReconcileIssue{
+				Kind:    IssueUnknownOrder,
+				OrderID: t.ID,
+				FillID:  t.FillID,
+				Detail:  fmt.Sprintf("fill %s of %s refers to an order missing from the order book", t.FillID, t.Symbol),
+			})
+		}
+
+		key := t.Token + ":" + t.Product
+		position, ok := positions[key]
+		if !ok {
+			position = &CostBasis{}
+			positions[key] = position
+		}
+		before := position.RealizedPnL
+		position.apply(t)
+
+		qty := parseInt(t.FillShares)
+		pnl.FilledQty += qty
+		pnl.Turnover += float64(qty) * parseFloat(t.FillPrice)
+		pnl.Fills++
+		pnl.RealizedPnL += position.RealizedPnL - before
+		if charges != nil {
+			pnl.Charges += charges.Charges(t)
+		}
+	}
+
+	byTag := make(map[string]*TagPnL)
+	for _, id := range orderIDs {
+		pnl := pnls[id]
+		if pnl.FilledQty > 0 {
+			pnl.AvgFillPrice = pnl.Turnover / float64(pnl.FilledQty)
+		}
+		if order, ok := byID[id]; ok {
+			if filled := parseInt(order.FillShares); filled != pnl.FilledQty {
+				result.Issues = append(result.Issues, ReconcileIssue{
+					Kind:    IssueFillMismatch,
+					OrderID: id,
+					Detail:  fmt.Sprintf("order reports %d filled but trades add up to %d", filled, pnl.FilledQty),
+				})
+			}
+		}
+		result.Orders = append(result.Orders, *pnl)
+
+		tag, ok := byTag[pnl.Tag]
+		if !ok {
+			tag = &TagPnL{Tag: pnl.Tag}
+			byTag[pnl.Tag] = tag
+		}
+		tag.Orders++
+		tag.Turnover += pnl.Turnover
+		tag.RealizedPnL += pnl.RealizedPnL
+		tag.Charges += pnl.Charges
+		tag.NetPnL += pnl.NetPnL()
+
+		result.RealizedPnL += pnl.RealizedPnL
+		result.TotalCharges += pnl.Charges
+	}
+	result.NetPnL = result.RealizedPnL - result.TotalCharges
+
+	for _, order := range orders {
+		if _, ok := pnls[order.ID]; !ok && parseInt(order.FillShares) > 0 {
+			result.Issues = append(result.Issues, ReconcileIssue{
+				Kind:    IssueFillMismatch,
+				OrderID: order.ID,
+				Detail:  fmt.Sprintf("order reports %s filled but has no trades", order.FillShares),
+			})
+		}
+	}
+
+	for _, tag := range byTag {
+		result.ByTag = append(result.ByTag, *tag)
+	}
+	sort.Slice(result.ByTag, func(i, j int) bool {
+		if result.ByTag[i].NetPnL != result.ByTag[j].NetPnL {
+			return result.ByTag[i].NetPnL > result.ByTag[j].NetPnL
+		}
+		return result.ByTag[i].Tag < result.ByTag[j].Tag
+	})
+	return result
+}
+
+// ReconcileTrades fetches the day's order and trade books and reconciles them.
+//
+// Parameters:
+//   - charges: The charge model, or nil to ignore charges.
+//
+// Returns:
+//   - The reconciliation of the day's books if successful.
+//   - An error if either book cannot be retrieved.
+func (c *Client) ReconcileTrades(charges ChargeModel) (*Reconciliation, error) {
+	orders, err := c.getOrderRows()
+	if err != nil {
+		return nil, err
+	}
+	trades, err := c.GetTradeBook()
+	if err != nil {
+		return nil, err
+	}
+
+	result := ReconcileTrades(orders, trades, charges)
+	c.log().Info().
+		Int("orders", len(result.Orders)).
+		Int("issues", len(result.Issues)).
+		Float64("netPnL", result.NetPnL).
+		Msg("Trades reconciled")
+	return &result, nil
+}
@@ -0,0 +1,292 @@
+package tiqs
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// AlignMode selects how AlignCandles builds the common time axis of several series.
+type AlignMode int
+
+const (
+	// AlignIntersect keeps only the times at which every series has a bar.
+	AlignIntersect AlignMode = iota
+	// AlignForwardFill keeps every time at which any series has a bar, carrying the last
+	// close of the series without one forward. Times before every series has started are
+	// dropped.
+	AlignForwardFill
+)
+
+// AlignedSeries holds the closes of several instruments on a common time axis, so that
+// they can be compared bar by bar.
+type AlignedSeries struct {
+	Times  []time.Time `json:"times"`  // The common time axis, in ascending order.
+	Tokens []int64     `json:"tokens"` // Tokens of the series, in the order they were given.
+	Closes [][]float64 `json:"closes"` // Closes in rupees, indexed like Tokens, then like Times.
+}
+
+// StrengthRank is the performance of an instrument relative to a benchmark over a lookback.
+type StrengthRank struct {
+	Token    int64   `json:"token"`    // Unique identifier for the instrument.
+	Return   float64 `json:"return"`   // Return of the instrument over the lookback, in percent.
+	Relative float64 `json:"relative"` // Return relative to the benchmark, in percent.
+}
+
+// AlignCandles puts candle series of several instruments on a common time axis.
+//
+// Parameters:
+//   - mode: How times missing from some series are handled.
+//   - series: One candle series per instrument, e.g., from PriceConverter.HistoricalCandles;
+//     each series is identified by the token of its candles.
+//
+// Returns:
+//   - The aligned closes.
+//   - An error if a series is empty or two series share a token.
+func AlignCandles(mode AlignMode, series ...[]DecimalCandle) (*AlignedSeries, error) {
+	aligned := &AlignedSeries{}
+	closes := make([]map[int64]float64, len(series))
+	count := make(map[int64]int)
+
+	for i, candles := range series {
+		if len(candles) == 0 {
+			return nil, fmt.Errorf("series %d has no candles", i)
+		}
+		token := candles[0].Token
+		if slices.Contains(aligned.Tokens, token) {
+			return nil, fmt.Errorf("token %d is given twice", token)
+		}
+		aligned.Tokens = append(aligned.Tokens, token)
+
+		closes[i] = make(map[int64]float64, len(candles))
+		for _, c := range candles {
+			if c.Time.IsZero() {
+				continue
+			}
+			key := c.Time.UnixNano()
+			if _, ok := closes[i][key]; !ok {
+				count[key]++
+			}
+			closes[i][key] = c.Close
+		}
+	}
+
+	keys := make([]int64, 0, len(count))
+	for key, n := range count {
+		if mode == AlignForwardFill || n == len(series) {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+
+	aligned.Closes = make([][]float64, len(series))
+	last := make([]float64, len(series))
+	for _, key := range keys {
+		started := true
+		for i := range series {
+			if v, ok := closes[i][key]; ok {
+				last[i] = v
+			}
+			started = started && last[i] != 0
+		}
+		if !started {
+			continue
+		}
+		aligned.Times = append(aligned.Times, time.Unix(0, key).In(IST))
+		for i := range series {
+			aligned.Closes[i] = append(aligned.Closes[i], last[i])
+		}
+	}
+	return aligned, nil
+}
+
+// GetAlignedHistory fetches the candles of several instruments and aligns them.
+//
+// The exchange of each token is looked up in the attached instrument store, and prices
+// are converted into rupees with the instrument's precision.
+//
+// Parameters:
+//   - tokens: The instruments to compare, e.g., the constituents of a sector and its index.
+//   - interval: The timeframe of the candles (e.g., "5m", "1d").
+//   - from: The start date/time (ISO 8601 format).
+//   - to: The end date/time (ISO 8601 format).
+//   - mode: How times missing from some series are handled.
+//
+// Returns:
+//   - The aligned closes if successful.
+//   - An error if a token is unknown or a request fails.
+func (c *Client) GetAlignedHistory(tokens []int64, interval, from, to string, mode AlignMode) (*AlignedSeries, error) {
+	if c.instruments == nil {
+		return nil, fmt.Errorf("aligned history needs an instrument store (see LoadInstruments)")
+	}
+
+	converter := c.PriceConverter()
+	series := make([][]DecimalCandle, len(tokens))
+	for i, token := range tokens {
+		inst, ok := c.instruments.Get(token)
+		if !ok {
+			return nil, fmt.Errorf("unknown token: %d", token)
+		}
+		candles, err := c.GetHistoricalData(inst.Exchange, strconv.FormatInt(token, 10), interval, from, to, false)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching candles of %s: %w", inst.TradingSymbol, err)
+		}
+		series[i] = converter.HistoricalCandles(token, candles)
+	}
+	return AlignCandles(mode, series...)
+}
+
+// Close returns the closes of a token, or nil if the token is not in the series.
+func (a *AlignedSeries) Close(token int64) []float64 {
+	if i := slices.Index(a.Tokens, token); i >= 0 {
+		return a.Closes[i]
+	}
+	return nil
+}
+
+// Ratio returns the price ratio of two instruments at every time, e.g., the spread of a pair.
+//
+// Returns:
+//   - The ratio series, or nil if a token is not in the series.
+func (a *AlignedSeries) Ratio(numerator, denominator int64) []float64 {
+	num, den := a.Close(numerator), a.Close(denominator)
+	if num == nil || den == nil {
+		return nil
+	}
+	ratio := make([]float64, len(num))
+	for i := range num {
+		ratio[i] = num[i] / den[i]
+	}
+	return ratio
+}
+
+// RelativeStrength returns the performance of an instrument relative to a benchmark,
+// rebased to 100 at the first time: values above 100 mean the instrument outperformed
+// the benchmark since then.
+//
+// Returns:
+//   - The relative strength series, or nil if a token is not in the series.
+func (a *AlignedSeries) RelativeStrength(token, benchmark int64) []float64 {
+	ratio := a.Ratio(token, benchmark)
+	if len(ratio) == 0 {
+		return ratio
+	}
+	base := ratio[0]
+	for i := range ratio {
+		ratio[i] = ratio[i] / base * 100
+	}
+	return ratio
+}
+
+// Returns returns the bar-to-bar returns of a token. The first value is NaN, so the
+// series stays aligned with Times.
+//
+// Returns:
+//   - The returns as fractions (0.01 for 1%), or nil if the token is not in the series.
+func (a *AlignedSeries) Returns(token int64) []float64 {
+	closes := a.Close(token)
+	if closes == nil {
+		return nil
+	}
+	returns := make([]float64, len(closes))
+	if len(returns) > 0 {
+		returns[0] = math.NaN()
+	}
+	for i := 1; i < len(closes); i++ {
+		returns[i] = closes[i]/closes[i-1] - 1
+	}
+	return returns
+}
+
+// RollingCorrelation returns the Pearson correlation of the returns of two instruments
+// over a rolling window of bars. Values are NaN until the window is full and where either
+// instrument did not move at all within the window.
+//
+// Parameters:
+//   - x, y: The tokens to correlate.
+//   - window: The number of returns in each window; at least 2.
+//
+// Returns:
+//   - The correlation series, aligned with Times, or nil if a token is not in the series.
+func (a *AlignedSeries) RollingCorrelation(x, y int64, window int) []float64 {
+	rx, ry := a.Returns(x), a.Returns(y)
+	if rx == nil || ry == nil {
+		return nil
+	}
+	window = max(window, 2)
+
+	corr := make([]float64, len(rx))
+	for i := range corr {
+		corr[i] = math.NaN()
+		if i < window {
+			continue
+		}
+		corr[i] = pearson(rx[i-window+1:i+1], ry[i-window+1:i+1])
+	}
+	return corr
+}
+
+// RankByStrength ranks every instrument by its return over the last lookback bars
+// relative to a benchmark, strongest first, e.g., to rotate into the leading sectors.
+//
+// Parameters:
+//   - benchmark: The token of the benchmark, e.g., NIFTY 50; it is not ranked.
+//   - lookback: The number of bars to measure over; the whole series if it is not positive
+//     or longer than the series.
+//
+// Returns:
+//   - The ranked instruments, or nil if the benchmark is not in the series or there are
+//     fewer than two times.
+func (a *AlignedSeries) RankByStrength(benchmark int64, lookback int) []StrengthRank {
+	bench := a.Close(benchmark)
+	if bench == nil || len(a.Times) < 2 {
+		return nil
+	}
+	end := len(a.Times) - 1
+	start := 0
+	if lookback > 0 && lookback < end {
+		start = end - lookback
+	}
+	benchReturn := bench[end]/bench[start] - 1
+
+	var ranks []StrengthRank
+	for i, token := range a.Tokens {
+		if token == benchmark {
+			continue
+		}
+		r := a.Closes[i][end]/a.Closes[i][start] - 1
+		ranks = append(ranks, StrengthRank{
+			Token:    token,
+			Return:   r * 100,
+			Relative: ((1+r)/(1+benchReturn) - 1) * 100,
+		})
+	}
+	sort.SliceStable(ranks, func(i, j int) bool { return ranks[i].Relative > ranks[j].Relative })
+	return ranks
+}
+
+// pearson returns the correlation coefficient of two equally long samples.
+func pearson(x, y []float64) float64 {
+	n := float64(len(x))
+	var sx, sy float64
+	for i := range x {
+		sx += x[i]
+		sy += y[i]
+	}
+	mx, my := sx/n, sy/n
+
+	var cov, vx, vy float64
+	for i := range x {
+		dx, dy := x[i]-mx, y[i]-my
+		cov += dx * dy
+		vx += dx * dx
+		vy += dy * dy
+	}
+	if vx == 0 || vy == 0 {
+		return math.NaN()
+	}
+	return cov / math.Sqrt(vx*vy)
+}
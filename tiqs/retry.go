@@ -0,0 +1,175 @@
+package tiqs
+
+import (
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"slices"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// Defaults of a RetryPolicy.
+const (
+	DefaultRetryBackoff    = 200 * time.Millisecond
+	DefaultRetryMaxBackoff = 5 * time.Second
+)
+
+// DefaultRetryStatuses are the HTTP statuses retried by a RetryPolicy without Statuses.
+var DefaultRetryStatuses = []int{429, 502, 503, 504}
+
+// RetryPolicy retries REST requests that failed with a transient error: a status listed
+// in Statuses or, with NetworkErrors, a transport error such as a timeout or a refused
+// connection.
+//
+// Only reads are retried on any of these failures: GET requests and the reads sent with
+// POST, such as quotes and margin calculations. Every other request, including orders,
+// position conversions, payins and payouts, is never retried after a failure that leaves
+// its outcome unknown. The API has no idempotency key, so a request that timed out or was
+// answered with a 5xx may have been applied, and retrying it could fill an order or
+// withdraw funds twice. With Orders set, such requests are retried only when the broker
+// cannot have acted on them: on HTTP 429, which refuses the request, and on transport
+// errors raised before the request was sent, such as a failed dial. Other failures are
+// returned for the caller to reconcile, e.g., with FindOrders.
+//
+// Attempts wait for the rate budget of their class like any request, so a 429 also holds
+// back the requests sharing the budget.
+type RetryPolicy struct {
+	MaxAttempts   int           // Attempts of a request, the first included; below 2 disables retries.
+	Backoff       time.Duration // Delay before the first retry, doubled on every further retry; zero uses DefaultRetryBackoff.
+	MaxBackoff    time.Duration // Longest delay between attempts; zero uses DefaultRetryMaxBackoff.
+	Statuses      []int         // HTTP statuses retried; DefaultRetryStatuses if empty.
+	NetworkErrors bool          // Whether transport errors are retried.
+	Orders        bool          // Whether orders and other requests that are not reads are retried, on failures they were certainly not acted on.
+}
+
+// SetRetryPolicy enables retries of failed REST requests.
+//
+// Parameters:
+//   - policy: The retry policy, or nil to disable retries.
+func (c *Client) SetRetryPolicy(policy *RetryPolicy) {
+	if policy == nil {
+		c.retry = nil
+		return
+	}
+	p := *policy
+	p.Statuses = slices.Clone(p.Statuses)
+	c.retry = &p
+}
+
+// withRetries runs attempt until it succeeds, fails with an error the retry policy does
+// not retry, or runs out of attempts.
+func (c *Client) withRetries(method, endpoint string, attempt func() error) error {
+	p := c.retry
+	if p == nil || p.MaxAttempts < 2 {
+		return attempt()
+	}
+
+	mutating := !c.idempotent(method, endpoint)
+	for n := 1; ; n++ {
+		err := attempt()
+		if err == nil || n >= p.MaxAttempts || !p.retries(err, mutating) {
+			return err
+		}
+
+		delay := p.backoff(n)
+		c.log().Warn().Err(err).
+			Str("endpoint", endpoint).
+			Int("attempt", n).
+			Dur("delay", delay).
+			Msg("Request failed; retrying")
+		time.Sleep(delay)
+	}
+}
+
+// idempotentPosts are the reads sent with POST. Like GET requests, they are retried on
+// any transient failure.
+var idempotentPosts = []EndpointName{
+	EndpointQuote,
+	EndpointQuotes,
+	EndpointOptionChain,
+	EndpointOrderMargin,
+	EndpointBasketMargin,
+}
+
+// idempotent reports whether a request can be sent again without effect: GET requests
+// and the POST requests of idempotentPosts.
+func (c *Client) idempotent(method, endpoint string) bool {
+	if method == "GET" {
+		return true
+	}
+	if method != "POST" {
+		return false
+	}
+	for _, name := range idempotentPosts {
+		if c.matchesEndpoint(name, endpoint) {
+			return true
+		}
+	}
+	return false
+}
+
+// retries reports whether a failed request is attempted again. Requests that are not
+// idempotent are retried only on failures that leave them certainly not acted on.
+func (p *RetryPolicy) retries(err error, mutating bool) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		if mutating {
+			return p.Orders && apiErr.HTTPStatus == fasthttp.StatusTooManyRequests
+		}
+		statuses := p.Statuses
+		if len(statuses) == 0 {
+			statuses = DefaultRetryStatuses
+		}
+		return slices.Contains(statuses, apiErr.HTTPStatus)
+	}
+
+	if !p.NetworkErrors {
+		return false
+	}
+	if mutating {
+		return p.Orders && notSent(err)
+	}
+	return notSent(err) || isNetworkError(err)
+}
+
+// backoff returns the delay before the retry following attempt n, with jitter so that
+// clients failing together do not retry in lockstep.
+func (p *RetryPolicy) backoff(n int) time.Duration {
+	delay := p.Backoff
+	if delay <= 0 {
+		delay = DefaultRetryBackoff
+	}
+	ceiling := p.MaxBackoff
+	if ceiling <= 0 {
+		ceiling = DefaultRetryMaxBackoff
+	}
+	for i := 1; i < n && delay < ceiling; i++ {
+		delay *= 2
+	}
+	delay = min(delay, ceiling)
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// notSent reports whether a transport error was raised before the request was written,
+// so the broker cannot have received it.
+func notSent(err error) bool {
+	if errors.Is(err, fasthttp.ErrDialTimeout) || errors.Is(err, fasthttp.ErrNoFreeConns) || errors.Is(err, ErrInjectedFault) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// isNetworkError reports whether an error is a transport failure rather than an answer
+// of the API.
+func isNetworkError(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) ||
+		errors.Is(err, fasthttp.ErrTimeout) ||
+		errors.Is(err, fasthttp.ErrConnectionClosed) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
@@ -0,0 +1,132 @@
+package tiqs_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Abhi13027/go-tiqs/tiqs"
+	"github.com/valyala/fasthttp"
+)
+
+// retryReadTimeout is the response timeout of the clients of TestRetryPolicy.
+const retryReadTimeout = 50 * time.Millisecond
+
+// flakyHandler fails the first attempt of every request, by method and path, with a 503
+// or, with stall set, by answering only after the client timed out. Later attempts are
+// passed to the mock server.
+type flakyHandler struct {
+	next     http.Handler
+	stall    bool
+	mu       sync.Mutex
+	attempts map[string]int
+}
+
+func (h *flakyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := r.Method + " " + r.URL.Path
+	h.mu.Lock()
+	h.attempts[key]++
+	n := h.attempts[key]
+	h.mu.Unlock()
+
+	switch {
+	case n > 1:
+		h.next.ServeHTTP(w, r)
+	case h.stall:
+		select {
+		case <-time.After(4 * retryReadTimeout):
+		case <-r.Context().Done():
+		}
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"status":"error","message":"service unavailable"}`))
+	}
+}
+
+// count returns the attempts of a request.
+func (h *flakyHandler) count(key string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.attempts[key]
+}
+
+func TestRetryPolicy(t *testing.T) {
+	const orderNo = "24121900000123"
+	buy := order(tiqs.TransactionBuy, "75", tiqs.ProductMIS)
+
+	requests := []struct {
+		name     string
+		key      string
+		send     func(*tiqs.Client) error
+		attempts int // attempts sent when the first one fails
+	}{
+		{"place", "POST /order/regular", func(c *tiqs.Client) error {
+			_, err := c.PlaceOrder("regular", buy)
+			return err
+		}, 1},
+		{"modify", "PATCH /order/regular/" + orderNo, func(c *tiqs.Client) error {
+			_, err := c.ModifyOrder("regular", orderNo, buy)
+			return err
+		}, 1},
+		{"cancel", "DELETE /order/regular/" + orderNo, func(c *tiqs.Client) error {
+			return c.CancelOrder("regular", orderNo)
+		}, 1},
+		{"quote", "POST /info/quote/full", func(c *tiqs.Client) error {
+			_, err := c.GetMarketQuote(35001, "full")
+			return err
+		}, 2},
+		{"margin", "POST /margin/order", func(c *tiqs.Client) error {
+			_, err := c.GetMargin(tiqs.MarginRequest{
+				Exchange:        buy.Exchange,
+				Token:           buy.Token,
+				Quantity:        buy.Quantity,
+				Product:         buy.Product,
+				Price:           buy.Price,
+				TransactionType: buy.TransactionType,
+				OrderType:       buy.OrderType,
+				Symbol:          buy.Symbol,
+			})
+			return err
+		}, 2},
+		{"positions", "GET /user/positions", func(c *tiqs.Client) error {
+			_, err := c.GetPositions()
+			return err
+		}, 2},
+	}
+
+	for _, failure := range []string{"5xx", "timeout"} {
+		for _, r := range requests {
+			t.Run(failure+"/"+r.name, func(t *testing.T) {
+				client, mock := newTestClient(t)
+				handler := &flakyHandler{next: mock.Config.Handler, stall: failure == "timeout", attempts: make(map[string]int)}
+				server := httptest.NewServer(handler)
+				t.Cleanup(server.Close)
+
+				client.Config.BaseURL = server.URL
+				client.HTTPClient = &fasthttp.Client{ReadTimeout: retryReadTimeout}
+				// Orders set: the orders are still sent once, since they may have been
+				// acted on
+				client.SetRetryPolicy(&tiqs.RetryPolicy{
+					MaxAttempts:   3,
+					Backoff:       time.Millisecond,
+					NetworkErrors: true,
+					Orders:        true,
+				})
+
+				err := r.send(client)
+				if got := handler.count(r.key); got != r.attempts {
+					t.Fatalf("%s sent %d times, want %d", r.key, got, r.attempts)
+				}
+				if r.attempts == 1 && err == nil {
+					t.Fatal("request succeeded, want the error of its only attempt")
+				}
+				if r.attempts > 1 && err != nil {
+					t.Fatalf("retried request failed: %v", err)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package tiqs
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Abhi13027/go-tiqs/ticks"
+)
+
+// ErrKillSwitch is returned for orders refused because the client's RiskManager tripped.
+var ErrKillSwitch = errors.New("kill switch tripped")
+
+// ErrPositionLimit is returned for orders that would take a position beyond the
+// RiskManager's MaxPosition.
+var ErrPositionLimit = errors.New("position limit exceeded")
+
+// RiskLimits are the limits a RiskManager enforces; zero disables a limit.
+type RiskLimits struct {
+	MaxLoss            float64 `json:"maxLoss,omitempty" yaml:"maxLoss,omitempty"`                       // Loss of the day, realized and unrealized, in rupees, tripping the kill switch.
+	MaxOrdersPerMinute int     `json:"maxOrdersPerMinute,omitempty" yaml:"maxOrdersPerMinute,omitempty"` // Orders placed in any minute beyond which the kill switch trips.
+	MaxPosition        int64   `json:"maxPosition,omitempty" yaml:"maxPosition,omitempty"`               // Largest absolute net quantity per instrument and product, in the quantity convention of its orders.
+}
+
+// RiskBreach describes why a RiskManager's kill switch tripped.
+type RiskBreach struct {
+	Reason        string    `json:"reason"`        // The limit breached.
+	RealizedPnL   float64   `json:"realizedPnL"`   // Realized P&L of all positions when the switch tripped.
+	UnrealizedPnL float64   `json:"unrealizedPnL"` // Unrealized P&L of all positions when the switch tripped.
+	Time          time.Time `json:"time"`          // Time the switch tripped.
+}
+
+// RiskManager is a kill switch guarding every strategy using a client: once the loss of
+// the day exceeds MaxLoss, orders are placed faster than MaxOrdersPerMinute, or a position
+// grows beyond MaxPosition, the switch trips and PlaceOrder and OrderTemplate.Place refuse
+// every order that does not reduce a position, until Reset is called.
+//
+// P&L is taken from a PositionEngine, which must be seeded and fed order updates, with
+// open positions marked at the prices passed to Update. Limits are checked on every tick,
+// on every order and whenever Check is called, e.g., after each position update.
+//
+// With SquareOff set, tripping the switch also exits all open positions; exit orders
+// reduce positions and so pass the tripped switch.
+type RiskManager struct {
+	Limits    RiskLimits       // The limits enforced.
+	SquareOff bool             // Whether tripping the switch exits all open positions.
+	OnTrip    func(RiskBreach) // Optional callback invoked when the switch trips.
+
+	client *Client
+	engine *PositionEngine
+	mu     sync.Mutex
+	ltp    map[int64]float64
+	orders []time.Time
+	breach *RiskBreach
+}
+
+// NewRiskManager creates a risk manager. Attach it to the client with SetRiskManager.
+//
+// Parameters:
+//   - client: The client whose orders are guarded and whose instrument store converts tick prices.
+//   - engine: The engine holding the positions.
+//   - limits: The limits to enforce.
+//
+// Returns:
+//   - A pointer to a newly created RiskManager.
+func NewRiskManager(client *Client, engine *PositionEngine, limits RiskLimits) *RiskManager {
+	return &RiskManager{
+		Limits: limits,
+		client: client,
+		engine: engine,
+		ltp:    make(map[int64]float64),
+	}
+}
+
+// Update records the last traded price of the tick's instrument and checks the limits.
+func (r *RiskManager) Update(tick ticks.TickData) {
+	if tick.Token < 0 || tick.LTP <= 0 {
+		return
+	}
+	token := int64(tick.Token)
+	ltp := float64(tick.LTP) / r.client.PriceConverter().Divisor(token)
+
+	r.mu.Lock()
+	r.ltp[token] = ltp
+	r.mu.Unlock()
+	r.Check()
+}
+
+// PnL returns the realized P&L of all positions and the unrealized P&L of the open
+// positions at their last traded prices. Positions without a price yet are not marked.
+func (r *RiskManager) PnL() (realized, unrealized float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.pnlLocked(r.engine.Positions())
+}
+
+// pnlLocked returns the P&L of positions. The caller must hold r.mu.
+func (r *RiskManager) pnlLocked(positions []LivePosition) (realized, unrealized float64) {
+	for _, p := range positions {
+		realized += p.RealizedPnL
+		if ltp, ok := r.ltp[p.Token]; ok && p.NetQty != 0 {
+			unrealized += p.UnrealizedPnL(ltp)
+		}
+	}
+	return realized, unrealized
+}
+
+// Check trips the kill switch if the loss or a position exceeds its limit.
+//
+// Returns:
+//   - The breach and true if the switch is tripped, whether now or before.
+func (r *RiskManager) Check() (RiskBreach, bool) {
+	positions := r.engine.Positions()
+
+	r.mu.Lock()
+	if r.breach != nil {
+		defer r.mu.Unlock()
+		return *r.breach, true
+	}
+	realized, unrealized := r.pnlLocked(positions)
+	reason := ""
+	if r.Limits.MaxLoss > 0 && realized+unrealized <= -r.Limits.MaxLoss {
+		reason = fmt.Sprintf("loss %.2f exceeds the limit of %.2f", -(realized + unrealized), r.Limits.MaxLoss)
+	}
+	for _, p := range positions {
+		if reason != "" || r.Limits.MaxPosition <= 0 {
+			break
+		}
+		if abs64(p.NetQty) > r.Limits.MaxPosition {
+			reason = fmt.Sprintf("position of %d in %s exceeds the limit of %d", p.NetQty, p.Symbol, r.Limits.MaxPosition)
+		}
+	}
+	if reason == "" {
+		r.mu.Unlock()
+		return RiskBreach{}, false
+	}
+	breach := r.tripLocked(reason, realized, unrealized)
+	r.mu.Unlock()
+
+	r.tripped(breach)
+	return breach, true
+}
+
+// Trip trips the kill switch by hand, e.g., from an operator command.
+//
+// Parameters:
+//   - reason: Why the switch was tripped.
+func (r *RiskManager) Trip(reason string) {
+	positions := r.engine.Positions()
+
+	r.mu.Lock()
+	if r.breach != nil {
+		r.mu.Unlock()
+		return
+	}
+	realized, unrealized := r.pnlLocked(positions)
+	breach := r.tripLocked(reason, realized, unrealized)
+	r.mu.Unlock()
+
+	r.tripped(breach)
+}
+
+// Tripped reports whether the kill switch is tripped, and why.
+func (r *RiskManager) Tripped() (RiskBreach, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.breach == nil {
+		return RiskBreach{}, false
+	}
+	return *r.breach, true
+}
+
+// Reset re-enables orders after the kill switch tripped and restarts the order rate count.
+// Limits still breached trip the switch again on the next check.
+func (r *RiskManager) Reset() {
+	r.mu.Lock()
+	was := r.breach != nil
+	r.breach = nil
+	r.orders = nil
+	r.mu.Unlock()
+	if was {
+		r.client.log().Warn().Msg("Kill switch reset")
+	}
+}
+
+// tripLocked records a breach. The caller must hold r.mu.
+func (r *RiskManager) tripLocked(reason string, realized, unrealized float64) RiskBreach {
+	breach := RiskBreach{Reason: reason, RealizedPnL: realized, UnrealizedPnL: unrealized, Time: time.Now()}
+	r.breach = &breach
+	return breach
+}
+
+// tripped reports a new breach and squares off if configured.
+func (r *RiskManager) tripped(breach RiskBreach) {
+	r.client.log().Error().
+		Str("reason", breach.Reason).
+		Float64("realizedPnL", breach.RealizedPnL).
+		Float64("unrealizedPnL", breach.UnrealizedPnL).
+		Msg("Kill switch tripped")
+	if r.OnTrip != nil {
+		r.OnTrip(breach)
+	}
+	if r.SquareOff {
+		go r.squareOff()
+	}
+}
+
+// squareOff exits all open positions.
+func (r *RiskManager) squareOff() {
+	results, err := r.client.ExitAllPositions(PositionFilter{})
+	if err != nil {
+		r.client.log().Error().Err(err).Msg("Kill switch square-off failed")
+		return
+	}
+	for _, result := range results {
+		if result.Err != nil {
+			r.client.log().Error().Err(result.Err).Str("symbol", result.Position.Symbol).Msg("Kill switch failed to exit position")
+		}
+	}
+	r.client.log().Warn().Int("positions", len(results)).Msg("Kill switch squared off positions")
+}
+
+// check refuses an order while the kill switch is tripped unless it reduces a position,
+// trips the switch when the order rate is exceeded, and refuses orders that would take a
+// position beyond MaxPosition.
+func (r *RiskManager) check(order OrderRequest) error {
+	qty := parseInt(order.Quantity)
+	if order.TransactionType == TransactionSell {
+		qty = -qty
+	}
+	current, _ := r.engine.Position(parseInt(order.Token), string(order.Product))
+	next := current.NetQty + qty
+	reduces := current.NetQty != 0 && abs64(next) < abs64(current.NetQty) && (next == 0 || (next > 0) == (current.NetQty > 0))
+
+	r.mu.Lock()
+	if r.breach != nil {
+		reason := r.breach.Reason
+		r.mu.Unlock()
+		if reduces {
+			return nil
+		}
+		r.client.log().Error().Str("symbol", order.Symbol).Str("reason", reason).Msg("Order refused: kill switch tripped")
+		return fmt.Errorf("%w: %s", ErrKillSwitch, reason)
+	}
+
+	if r.Limits.MaxPosition > 0 && !reduces && abs64(next) > r.Limits.MaxPosition {
+		r.mu.Unlock()
+		r.client.log().Error().Str("symbol", order.Symbol).Int64("position", next).Int64("limit", r.Limits.MaxPosition).Msg("Order refused: position limit exceeded")
+		return fmt.Errorf("%w: %s would reach %d, limit %d", ErrPositionLimit, order.Symbol, next, r.Limits.MaxPosition)
+	}
+
+	now := time.Now()
+	if limit := r.Limits.MaxOrdersPerMinute; limit > 0 {
+		recent := r.orders[:0]
+		for _, t := range r.orders {
+			if now.Sub(t) < time.Minute {
+				recent = append(recent, t)
+			}
+		}
+		r.orders = recent
+		if len(r.orders) >= limit {
+			realized, unrealized := r.pnlLocked(r.engine.Positions())
+			breach := r.tripLocked(fmt.Sprintf("more than %d orders in a minute", limit), realized, unrealized)
+			r.mu.Unlock()
+			r.tripped(breach)
+			return fmt.Errorf("%w: %s", ErrKillSwitch, breach.Reason)
+		}
+		r.orders = append(r.orders, now)
+	}
+	r.mu.Unlock()
+	return nil
+}
+
+// SetRiskManager attaches a RiskManager to the client.
+//
+// Once attached, PlaceOrder and OrderTemplate.Place refuse orders with ErrKillSwitch
+// while the manager's kill switch is tripped, except those reducing a position, and with
+// ErrPositionLimit when they would take a position beyond its limit.
+//
+// Parameters:
+//   - manager: The manager to attach, or nil to detach the current one.
+func (c *Client) SetRiskManager(manager *RiskManager) {
+	c.risk = manager
+}
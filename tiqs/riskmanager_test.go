@@ -0,0 +1,171 @@
+package tiqs_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/Abhi13027/go-tiqs/tiqs"
+	"github.com/Abhi13027/go-tiqs/tiqs/tiqstest"
+	"github.com/rs/zerolog"
+)
+
+// testTimeout bounds the waits of the tests for work done in the background.
+const testTimeout = 5 * time.Second
+
+// newTestClient returns a client of a mock server holding a long intraday position of 75
+// NIFTY24DEC24000CE (token 35001). The client does not log.
+func newTestClient(t *testing.T) (*tiqs.Client, *tiqstest.Server) {
+	t.Helper()
+	server := tiqstest.NewServer()
+	t.Cleanup(server.Close)
+	client := server.Client()
+	client.SetLogger(tiqs.NewZerologLogger(zerolog.New(io.Discard)))
+	return client, server
+}
+
+// order returns an order of the position of the mock account.
+func order(side tiqs.TransactionType, quantity string, product tiqs.Product) tiqs.OrderRequest {
+	return tiqs.OrderRequest{
+		Exchange:        tiqs.ExchangeNFO,
+		Token:           "35001",
+		Symbol:          "NIFTY24DEC24000CE",
+		Quantity:        quantity,
+		Price:           "0",
+		Product:         product,
+		TransactionType: side,
+		OrderType:       tiqs.OrderTypeMarket,
+		Validity:        tiqs.ValidityDay,
+	}
+}
+
+// placedOrders decodes the orders received by the server.
+func placedOrders(t *testing.T, server *tiqstest.Server) []tiqs.OrderRequest {
+	t.Helper()
+	var orders []tiqs.OrderRequest
+	for _, r := range server.RequestsTo(tiqs.EndpointPlaceOrder) {
+		var o tiqs.OrderRequest
+		if err := json.Unmarshal(r.Body, &o); err != nil {
+			t.Fatalf("order payload %s: %v", r.Body, err)
+		}
+		orders = append(orders, o)
+	}
+	return orders
+}
+
+func TestRiskManagerCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		limits  tiqs.RiskLimits
+		trip    bool
+		order   tiqs.OrderRequest
+		wantErr error
+	}{
+		{"armed buy", tiqs.RiskLimits{}, false, order(tiqs.TransactionBuy, "75", tiqs.ProductMIS), nil},
+		{"tripped buy", tiqs.RiskLimits{}, true, order(tiqs.TransactionBuy, "75", tiqs.ProductMIS), tiqs.ErrKillSwitch},
+		{"tripped partial exit", tiqs.RiskLimits{}, true, order(tiqs.TransactionSell, "25", tiqs.ProductMIS), nil},
+		{"tripped full exit", tiqs.RiskLimits{}, true, order(tiqs.TransactionSell, "75", tiqs.ProductMIS), nil},
+		{"tripped reversal", tiqs.RiskLimits{}, true, order(tiqs.TransactionSell, "150", tiqs.ProductMIS), tiqs.ErrKillSwitch},
+		{"tripped sell of another product", tiqs.RiskLimits{}, true, order(tiqs.TransactionSell, "75", tiqs.ProductNRML), tiqs.ErrKillSwitch},
+		{"within position limit", tiqs.RiskLimits{MaxPosition: 150}, false, order(tiqs.TransactionBuy, "75", tiqs.ProductMIS), nil},
+		{"beyond position limit", tiqs.RiskLimits{MaxPosition: 100}, false, order(tiqs.TransactionBuy, "75", tiqs.ProductMIS), tiqs.ErrPositionLimit},
+		{"exit beyond position limit", tiqs.RiskLimits{MaxPosition: 50}, false, order(tiqs.TransactionSell, "50", tiqs.ProductMIS), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := newTestClient(t)
+			engine := tiqs.NewPositionEngine(client)
+			if err := engine.Seed(); err != nil {
+				t.Fatal(err)
+			}
+			risk := tiqs.NewRiskManager(client, engine, tt.limits)
+			client.SetRiskManager(risk)
+			if tt.trip {
+				risk.Trip("test")
+			}
+
+			_, err := client.PlaceOrder("regular", tt.order)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("PlaceOrder = %v, want %v", err, tt.wantErr)
+			}
+			want := 1
+			if tt.wantErr != nil {
+				want = 0
+			}
+			if placed := len(server.RequestsTo(tiqs.EndpointPlaceOrder)); placed != want {
+				t.Fatalf("%d orders sent, want %d", placed, want)
+			}
+		})
+	}
+}
+
+func TestRiskManagerOrderRate(t *testing.T) {
+	client, server := newTestClient(t)
+	engine := tiqs.NewPositionEngine(client)
+	risk := tiqs.NewRiskManager(client, engine, tiqs.RiskLimits{MaxOrdersPerMinute: 2})
+	client.SetRiskManager(risk)
+
+	buy := order(tiqs.TransactionBuy, "75", tiqs.ProductNRML)
+	for i := range 2 {
+		if _, err := client.PlaceOrder("regular", buy); err != nil {
+			t.Fatalf("order %d: %v", i+1, err)
+		}
+	}
+	if _, err := client.PlaceOrder("regular", buy); !errors.Is(err, tiqs.ErrKillSwitch) {
+		t.Fatalf("order 3 = %v, want ErrKillSwitch", err)
+	}
+	if _, tripped := risk.Tripped(); !tripped {
+		t.Fatal("switch not tripped by the order rate")
+	}
+	if _, err := client.PlaceOrder("regular", buy); !errors.Is(err, tiqs.ErrKillSwitch) {
+		t.Fatalf("order after trip = %v, want ErrKillSwitch", err)
+	}
+
+	risk.Reset()
+	if _, err := client.PlaceOrder("regular", buy); err != nil {
+		t.Fatalf("order after Reset: %v", err)
+	}
+	if n := len(server.RequestsTo(tiqs.EndpointPlaceOrder)); n != 3 {
+		t.Fatalf("%d orders sent, want 3", n)
+	}
+}
+
+// TestRiskManagerSquareOff checks that tripping the switch exits the open positions
+// through ExitAllPositions, and that the exits pass the tripped switch.
+func TestRiskManagerSquareOff(t *testing.T) {
+	client, server := newTestClient(t)
+	engine := tiqs.NewPositionEngine(client)
+	if err := engine.Seed(); err != nil {
+		t.Fatal(err)
+	}
+	risk := tiqs.NewRiskManager(client, engine, tiqs.RiskLimits{})
+	risk.SquareOff = true
+	client.SetRiskManager(risk)
+	server.Reset()
+
+	risk.Trip("test")
+
+	deadline := time.Now().Add(testTimeout)
+	for len(server.RequestsTo(tiqs.EndpointPlaceOrder)) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("no exit order placed after the switch tripped")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if n := len(server.RequestsTo(tiqs.EndpointPositions)); n != 1 {
+		t.Fatalf("%d position requests, want 1 from ExitAllPositions", n)
+	}
+	orders := placedOrders(t, server)
+	want := order(tiqs.TransactionSell, "75", tiqs.ProductMIS)
+	if len(orders) != 1 || orders[0] != want {
+		t.Fatalf("exit orders = %+v, want %+v", orders, want)
+	}
+
+	// New positions stay refused
+	if _, err := client.PlaceOrder("regular", order(tiqs.TransactionBuy, "75", tiqs.ProductMIS)); !errors.Is(err, tiqs.ErrKillSwitch) {
+		t.Fatalf("PlaceOrder after square-off = %v, want ErrKillSwitch", err)
+	}
+}
@@ -0,0 +1,147 @@
+package tiqs
+
+import (
+	"strings"
+)
+
+// screenerBatchSize is the number of tokens requested per GetMarketQuotes call while screening.
+const screenerBatchSize = 100
+
+// ScreenerFilter narrows down the instrument universe before quotes are fetched.
+//
+// Empty fields do not filter; values are matched case-insensitively.
+type ScreenerFilter struct {
+	Exchanges   []Exchange // Exchanges to include (e.g., ExchangeNSE, ExchangeBSE).
+	Segments    []string   // Segments to include as reported by the instrument master.
+	Instruments []string   // Instrument types to include (e.g., EQ, FUTSTK).
+}
+
+// ScreenResult represents an instrument that passed the screen along with its quote.
+type ScreenResult struct {
+	Instrument Instrument  `json:"instrument"` // Instrument master row.
+	Quote      MarketQuote `json:"quote"`      // Market quote used for screening.
+	LTP        float64     `json:"ltp"`        // Last traded price in rupees.
+	ChangePct  float64     `json:"changePct"`  // Percentage change of LTP over the previous close.
+}
+
+// ScreenPredicate decides whether a screened instrument is a match.
+type ScreenPredicate func(ScreenResult) bool
+
+// PriceBetween matches instruments whose last traded price, in rupees, lies within [min, max].
+func PriceBetween(min, max float64) ScreenPredicate {
+	return func(r ScreenResult) bool {
+		return r.LTP >= min && r.LTP <= max
+	}
+}
+
+// ChangeBetween matches instruments whose percentage change lies within [min, max].
+func ChangeBetween(min, max float64) ScreenPredicate {
+	return func(r ScreenResult) bool {
+		return r.ChangePct >= min && r.ChangePct <= max
+	}
+}
+
+// MinVolume matches instruments whose traded volume is at least volume.
+func MinVolume(volume int64) ScreenPredicate {
+	return func(r ScreenResult) bool {
+		return r.Quote.Volume >= volume
+	}
+}
+
+// Matches reports whether an instrument passes the filter.
+func (f ScreenerFilter) Matches(inst Instrument) bool {
+	exchanges := make([]string, len(f.Exchanges))
+	for i, e := range f.Exchanges {
+		exchanges[i] = string(e)
+	}
+
+	return matchesAny(exchanges, inst.Exchange) &&
+		matchesAny(f.Segments, inst.Segment) &&
+		matchesAny(f.Instruments, inst.Instrument)
+}
+
+// Screen filters the instrument universe, fetches quotes in batches and applies predicates.
+//
+// Instruments are first narrowed down with the filter, then quoted in batches through
+// GetMarketQuotes. An instrument is returned only if every predicate matches.
+//
+// Parameters:
+//   - instruments: The instrument universe (e.g., from GetInstrumentList).
+//   - filter: The ScreenerFilter applied before quotes are fetched.
+//   - mode: Market mode used for quotes (e.g., "full").
+//   - predicates: Conditions that every match must satisfy.
+//
+// Returns:
+//   - A slice of ScreenResult structs for the matching instruments.
+//   - An error if fetching quotes fails.
+func (c *Client) Screen(instruments []Instrument, filter ScreenerFilter, mode string, predicates ...ScreenPredicate) ([]ScreenResult, error) {
+	universe := make(map[int64]Instrument)
+	tokens := make([]int64, 0)
+	for _, inst := range instruments {
+		if !filter.Matches(inst) {
+			continue
+		}
+		if _, ok := universe[inst.Token]; !ok {
+			universe[inst.Token] = inst
+			tokens = append(tokens, inst.Token)
+		}
+	}
+
+	var results []ScreenResult
+	for start := 0; start < len(tokens); start += screenerBatchSize {
+		end := min(start+screenerBatchSize, len(tokens))
+
+		quotes, err := c.GetMarketQuotes(tokens[start:end], mode)
+		if err != nil {
+			c.log().Error().Err(err).Msg("Failed to fetch quotes for screener")
+			return nil, err
+		}
+
+		for _, quote := range quotes {
+			inst, ok := universe[quote.Token]
+			if !ok {
+				continue
+			}
+
+			result := ScreenResult{
+				Instrument: inst,
+				Quote:      quote,
+				LTP:        float64(quote.LTP) / inst.PriceDivisor(),
+			}
+			if quote.Close > 0 {
+				result.ChangePct = float64(quote.LTP-quote.Close) / float64(quote.Close) * 100
+			}
+
+			if matchesAll(result, predicates) {
+				results = append(results, result)
+			}
+		}
+	}
+
+	c.log().Info().Int("universe", len(tokens)).Int("matches", len(results)).Msg("Screen completed successfully")
+	return results, nil
+}
+
+// matchesAll reports whether every predicate matches the result.
+func matchesAll(result ScreenResult, predicates []ScreenPredicate) bool {
+	for _, predicate := range predicates {
+		if !predicate(result) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesAny reports whether value equals one of the allowed values, ignoring case.
+// An empty allow list matches every value.
+func matchesAny(allowed []string, value string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, a := range allowed {
+		if strings.EqualFold(a, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,142 @@
+package tiqs
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gocarina/gocsv"
+)
+
+// SectorInfo holds the sector and industry classification of an instrument.
+type SectorInfo struct {
+	Sector   string `json:"sector"`   // Broad sector (e.g., Financial Services, IT).
+	Industry string `json:"industry"` // Industry within the sector (e.g., Private Bank, Software).
+}
+
+// SectorProvider resolves the sector classification of an instrument.
+//
+// Implementations may look instruments up by symbol, ISIN or both; isin may be empty
+// when the caller only knows the symbol (e.g., for positions).
+type SectorProvider interface {
+	Sector(symbol, isin string) (SectorInfo, bool)
+}
+
+// EnrichedInstrument is an instrument joined with its sector classification.
+type EnrichedInstrument struct {
+	Instrument
+	SectorInfo
+}
+
+// sectorRow represents a single row of a sector mapping CSV file.
+type sectorRow struct {
+	Symbol   string `csv:"Symbol"`
+	Isin     string `csv:"ISIN"`
+	Sector   string `csv:"Sector"`
+	Industry string `csv:"Industry"`
+}
+
+// CSVSectorProvider is a SectorProvider backed by a CSV mapping file.
+type CSVSectorProvider struct {
+	bySymbol map[string]SectorInfo
+	byISIN   map[string]SectorInfo
+}
+
+// NewCSVSectorProvider loads a sector mapping from CSV data.
+//
+// The data must contain a header row with the columns "Symbol", "ISIN", "Sector" and
+// "Industry"; either Symbol or ISIN may be left empty for a row. ISIN matches take
+// precedence over symbol matches.
+//
+// Parameters:
+//   - r: Reader providing the CSV data.
+//
+// Returns:
+//   - A pointer to the CSVSectorProvider if successful.
+//   - An error if the CSV data cannot be parsed.
+func NewCSVSectorProvider(r io.Reader) (*CSVSectorProvider, error) {
+	var rows []sectorRow
+	if err := gocsv.Unmarshal(r, &rows); err != nil {
+		return nil, fmt.Errorf("failed to parse sector mapping: %w", err)
+	}
+
+	provider := &CSVSectorProvider{
+		bySymbol: make(map[string]SectorInfo, len(rows)),
+		byISIN:   make(map[string]SectorInfo, len(rows)),
+	}
+
+	for _, row := range rows {
+		info := SectorInfo{Sector: strings.TrimSpace(row.Sector), Industry: strings.TrimSpace(row.Industry)}
+		if symbol := normalizeSymbol(row.Symbol); symbol != "" {
+			provider.bySymbol[symbol] = info
+		}
+		if isin := strings.ToUpper(strings.TrimSpace(row.Isin)); isin != "" {
+			provider.byISIN[isin] = info
+		}
+	}
+
+	defaultLog().Info().Int("rows", len(rows)).Msg("Sector mapping loaded successfully")
+	return provider, nil
+}
+
+// Sector returns the classification for the given symbol or ISIN.
+func (p *CSVSectorProvider) Sector(symbol, isin string) (SectorInfo, bool) {
+	if isin != "" {
+		if info, ok := p.byISIN[strings.ToUpper(isin)]; ok {
+			return info, true
+		}
+	}
+	info, ok := p.bySymbol[normalizeSymbol(symbol)]
+	return info, ok
+}
+
+// EnrichInstruments joins instruments with their sector classification.
+//
+// Instruments unknown to the provider are returned with an empty SectorInfo.
+// Derivatives are classified by their underlying symbol.
+//
+// Parameters:
+//   - instruments: The instruments to enrich.
+//   - provider: The sector mapping to use.
+//
+// Returns:
+//   - A slice of EnrichedInstrument in the same order as the input.
+func EnrichInstruments(instruments []Instrument, provider SectorProvider) []EnrichedInstrument {
+	enriched := make([]EnrichedInstrument, len(instruments))
+	for i, inst := range instruments {
+		enriched[i].Instrument = inst
+		if info, ok := provider.Sector(underlyingOf(inst.Symbol), inst.Isin); ok {
+			enriched[i].SectorInfo = info
+		}
+	}
+	return enriched
+}
+
+// GroupBySector groups enriched instruments by sector.
+//
+// Instruments without a classification are grouped under "UNCLASSIFIED".
+//
+// Parameters:
+//   - instruments: The enriched instruments to group.
+//
+// Returns:
+//   - A map from sector name to the instruments in that sector.
+func GroupBySector(instruments []EnrichedInstrument) map[string][]EnrichedInstrument {
+	groups := make(map[string][]EnrichedInstrument)
+	for _, inst := range instruments {
+		sector := inst.Sector
+		if sector == "" {
+			sector = unclassifiedSector
+		}
+		groups[sector] = append(groups[sector], inst)
+	}
+	return groups
+}
+
+// unclassifiedSector is the group key used for instruments without a sector.
+const unclassifiedSector = "UNCLASSIFIED"
+
+// normalizeSymbol upper-cases a symbol and strips its equity series suffix.
+func normalizeSymbol(symbol string) string {
+	return underlyingOf(symbol)
+}
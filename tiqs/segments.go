@@ -0,0 +1,179 @@
+package tiqs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SegmentRules describes the trading conventions of a market segment.
+type SegmentRules struct {
+	PricePrecision int       // Number of decimal places of prices (2 for paise, 4 for currency).
+	QuantityInLots bool      // Whether order quantities are expressed in lots rather than units.
+	Products       []Product // Products that can be traded in the segment.
+}
+
+// segmentRules holds the conventions of each known segment.
+var segmentRules = map[Segment]SegmentRules{
+	SegmentEquity: {
+		PricePrecision: 2,
+		Products:       []Product{ProductMIS, ProductCNC, ProductBracket, ProductCover},
+	},
+	SegmentDerivatives: {
+		PricePrecision: 2,
+		Products:       []Product{ProductMIS, ProductNRML, ProductBracket, ProductCover},
+	},
+	SegmentCurrency: {
+		PricePrecision: 4,
+		QuantityInLots: true,
+		Products:       []Product{ProductMIS, ProductNRML},
+	},
+	SegmentCommodity: {
+		PricePrecision: 2,
+		QuantityInLots: true,
+		Products:       []Product{ProductMIS, ProductNRML},
+	},
+}
+
+// Rules returns the trading conventions of the segment. Unknown segments get the
+// equity and F&O conventions the API defaults to.
+func (s Segment) Rules() SegmentRules {
+	if rules, ok := segmentRules[s]; ok {
+		return rules
+	}
+	return SegmentRules{PricePrecision: defaultPricePrecision, Products: []Product{ProductMIS, ProductCNC, ProductNRML}}
+}
+
+// allows reports whether the product can be traded in the segment.
+func (r SegmentRules) allows(product Product) bool {
+	for _, p := range r.Products {
+		if p == product {
+			return true
+		}
+	}
+	return false
+}
+
+// OrderQuantity returns the quantity to send in an order for the given number of lots.
+//
+// Currency and commodity orders are placed in lots, while equity and F&O orders are
+// placed in units, so the same number of lots maps to different order quantities.
+//
+// Parameters:
+//   - lots: The number of lots to trade.
+//
+// Returns:
+//   - The order quantity in the convention of the instrument's segment.
+func (i Instrument) OrderQuantity(lots int64) int64 {
+	if Exchange(strings.ToUpper(i.Exchange)).Segment().Rules().QuantityInLots || i.LotSize <= 0 {
+		return lots
+	}
+	return lots * i.LotSize
+}
+
+// Units returns the number of units traded by an order quantity of the instrument.
+//
+// Parameters:
+//   - quantity: The order quantity.
+//
+// Returns:
+//   - The number of units, accounting for segments whose quantities are lots.
+func (i Instrument) Units(quantity int64) int64 {
+	if Exchange(strings.ToUpper(i.Exchange)).Segment().Rules().QuantityInLots && i.LotSize > 0 {
+		return quantity * i.LotSize
+	}
+	return quantity
+}
+
+// ValidateSegment checks an order against the conventions of its exchange segment.
+//
+// It checks that the exchange is known, that the product can be traded in the segment,
+// that the quantity is a positive integer and that prices do not have more decimal places
+// than the segment allows. When the instrument is known, it also checks that the order's
+// exchange matches the instrument's and that unit quantities are multiples of the lot size.
+//
+// Parameters:
+//   - order: The order to check.
+//   - inst: The instrument of the order, or nil if unknown.
+//
+// Returns:
+//   - An error describing the first violation, or nil if the order is valid.
+func ValidateSegment(order OrderRequest, inst *Instrument) error {
+	exchange, err := ParseExchange(string(order.Exchange))
+	if err != nil {
+		return err
+	}
+	segment := exchange.Segment()
+	rules := segment.Rules()
+
+	if !rules.allows(order.Product) {
+		return fmt.Errorf("product %s is not available in the %s segment", order.Product, strings.ToLower(segment.String()))
+	}
+
+	quantity, err := strconv.ParseInt(strings.TrimSpace(order.Quantity), 10, 64)
+	if err != nil || quantity <= 0 {
+		return fmt.Errorf("invalid quantity: %q", order.Quantity)
+	}
+
+	if err := checkPrecision(order.Price, rules.PricePrecision); err != nil {
+		return fmt.Errorf("invalid price for %s: %w", exchange, err)
+	}
+	if err := checkPrecision(order.TriggerPrice, rules.PricePrecision); err != nil {
+		return fmt.Errorf("invalid trigger price for %s: %w", exchange, err)
+	}
+
+	if inst == nil {
+		return nil
+	}
+	if !strings.EqualFold(inst.Exchange, exchange.String()) {
+		return fmt.Errorf("token %s is listed on %s, not %s", order.Token, inst.Exchange, exchange)
+	}
+	if !rules.QuantityInLots && inst.LotSize > 1 && quantity%inst.LotSize != 0 {
+		return fmt.Errorf("quantity %d is not a multiple of the lot size %d", quantity, inst.LotSize)
+	}
+	return nil
+}
+
+// checkPrecision returns an error if a decimal price has more than precision decimal places.
+// Empty prices, as sent for market orders, are accepted.
+func checkPrecision(price string, precision int) error {
+	price = strings.TrimSpace(price)
+	if price == "" {
+		return nil
+	}
+	if _, err := strconv.ParseFloat(price, 64); err != nil {
+		return fmt.Errorf("%q is not a number", price)
+	}
+	if i := strings.IndexByte(price, '.'); i >= 0 {
+		if decimals := strings.TrimRight(price[i+1:], "0"); len(decimals) > precision {
+			return fmt.Errorf("%q has more than %d decimal places", price, precision)
+		}
+	}
+	return nil
+}
+
+// validateSegment checks an order against its segment, using the attached instrument
+// store to look up the instrument when available.
+func (c *Client) validateSegment(order OrderRequest) error {
+	var inst *Instrument
+	if c.instruments != nil {
+		if found, ok := c.instruments.Get(parseInt(order.Token)); ok {
+			inst = &found
+		}
+	}
+	return ValidateSegment(order, inst)
+}
+
+// orderRequest returns the order whose margin is requested, for validation.
+func (m MarginRequest) orderRequest() OrderRequest {
+	return OrderRequest{
+		Exchange:        m.Exchange,
+		Token:           m.Token,
+		Quantity:        m.Quantity,
+		Product:         m.Product,
+		Symbol:          m.Symbol,
+		TransactionType: m.TransactionType,
+		OrderType:       m.OrderType,
+		Price:           m.Price,
+	}
+}
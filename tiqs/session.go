@@ -0,0 +1,250 @@
+package tiqs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	"github.com/Abhi13027/go-tiqs/ticks"
+)
+
+// Stopper is implemented by long-running components, such as strategy runners, that a
+// Session stops on shutdown.
+type Stopper interface {
+	Stop(ctx context.Context) error
+}
+
+// Flusher is implemented by components that buffer data, such as journals and recorders,
+// that a Session flushes on shutdown.
+type Flusher interface {
+	Flush() error
+}
+
+// ShutdownCancelPolicy selects which working orders a Session cancels on shutdown.
+type ShutdownCancelPolicy int
+
+const (
+	CancelNone    ShutdownCancelPolicy = iota // Leave all working orders in place.
+	CancelTracked                             // Cancel working orders placed through Session.TrackOrder.
+	CancelAllOpen                             // Cancel every open order in the order book.
+)
+
+// ShutdownIssue describes a component that could not be cleaned up.
+type ShutdownIssue struct {
+	Component string `json:"component"` // Component that failed (e.g., "runner", "order 12345").
+	Err       error  `json:"-"`         // The error encountered.
+}
+
+// ShutdownReport summarizes a session shutdown.
+type ShutdownReport struct {
+	StoppedRunners  int             `json:"stoppedRunners"`  // Runners stopped successfully.
+	CancelledOrders []string        `json:"cancelledOrders"` // Order numbers cancelled successfully.
+	Flushed         int             `json:"flushed"`         // Flushers flushed successfully.
+	Issues          []ShutdownIssue `json:"issues"`          // Everything that could not be cleaned up.
+}
+
+// Err returns the issues of the report joined into a single error, or nil if there were none.
+func (r *ShutdownReport) Err() error {
+	errs := make([]error, len(r.Issues))
+	for i, issue := range r.Issues {
+		errs[i] = fmt.Errorf("%s: %w", issue.Component, issue.Err)
+	}
+	return errors.Join(errs...)
+}
+
+// Session ties together a Client, an optional websocket and the components running on top
+// of them, and shuts them down in a safe order.
+type Session struct {
+	Client       *Client              // REST client of the session.
+	WS           *ticks.WS            // Market data websocket, optional.
+	CancelPolicy ShutdownCancelPolicy // Which working orders to cancel on shutdown.
+
+	mu       sync.Mutex
+	runners  []Stopper
+	flushers []Flusher
+	orders   map[string]string // order number → order type used to place it
+}
+
+// NewSession creates a session around a client and an optional websocket.
+//
+// Parameters:
+//   - client: The REST client.
+//   - ws: The market data websocket, or nil.
+//
+// Returns:
+//   - A pointer to a newly created Session that cancels tracked orders on shutdown.
+func NewSession(client *Client, ws *ticks.WS) *Session {
+	return &Session{
+		Client:       client,
+		WS:           ws,
+		CancelPolicy: CancelTracked,
+		orders:       make(map[string]string),
+	}
+}
+
+// RegisterRunner registers a component to be stopped on shutdown.
+func (s *Session) RegisterRunner(runner Stopper) {
+	s.mu.Lock()
+	s.runners = append(s.runners, runner)
+	s.mu.Unlock()
+}
+
+// RegisterFlusher registers a component to be flushed on shutdown.
+func (s *Session) RegisterFlusher(flusher Flusher) {
+	s.mu.Lock()
+	s.flushers = append(s.flushers, flusher)
+	s.mu.Unlock()
+}
+
+// TrackOrder records an order placed by the session so it can be cancelled on shutdown.
+//
+// Parameters:
+//   - orderType: The order type passed to PlaceOrder (e.g., "regular").
+//   - orderNo: The order number returned by PlaceOrder.
+func (s *Session) TrackOrder(orderType, orderNo string) {
+	s.mu.Lock()
+	s.orders[orderNo] = orderType
+	s.mu.Unlock()
+}
+
+// UntrackOrder stops tracking an order, e.g., once it reached a terminal state.
+func (s *Session) UntrackOrder(orderNo string) {
+	s.mu.Lock()
+	delete(s.orders, orderNo)
+	s.mu.Unlock()
+}
+
+// Shutdown stops the session in a safe order.
+//
+// Runners are stopped first so that no new orders are generated, then working orders are
+// cancelled according to CancelPolicy, flushers are flushed, and finally the websocket is
+// drained and closed. Every step is attempted even if an earlier one fails; failures are
+// collected in the report.
+//
+// Parameters:
+//   - ctx: Context bounding the shutdown; runners and the websocket drain honour its deadline.
+//
+// Returns:
+//   - A ShutdownReport describing what was cleaned up and what could not be.
+//   - An error joining all issues, or nil if shutdown was clean.
+func (s *Session) Shutdown(ctx context.Context) (*ShutdownReport, error) {
+	report := &ShutdownReport{}
+
+	s.mu.Lock()
+	runners := append([]Stopper(nil), s.runners...)
+	flushers := append([]Flusher(nil), s.flushers...)
+	s.mu.Unlock()
+
+	for i, runner := range runners {
+		if err := runner.Stop(ctx); err != nil {
+			report.Issues = append(report.Issues, ShutdownIssue{Component: fmt.Sprintf("runner %d", i), Err: err})
+			continue
+		}
+		report.StoppedRunners++
+	}
+
+	s.cancelOrders(report)
+
+	for i, flusher := range flushers {
+		if err := flusher.Flush(); err != nil {
+			report.Issues = append(report.Issues, ShutdownIssue{Component: fmt.Sprintf("flusher %d", i), Err: err})
+			continue
+		}
+		report.Flushed++
+	}
+
+	if s.WS != nil {
+		drainWS(ctx, s.WS)
+		if err := s.WS.Close(); err != nil {
+			report.Issues = append(report.Issues, ShutdownIssue{Component: "websocket", Err: err})
+		}
+	}
+
+	if len(report.Issues) > 0 {
+		s.Client.log().Warn().Int("issues", len(report.Issues)).Msg("Session shutdown completed with issues")
+	} else {
+		s.Client.log().Info().Msg("Session shutdown completed cleanly")
+	}
+	return report, report.Err()
+}
+
+// ShutdownOnSignal blocks until one of the signals is received (SIGINT by default) and
+// then shuts the session down within timeout.
+//
+// Parameters:
+//   - timeout: Maximum duration of the shutdown.
+//   - signals: Signals that trigger the shutdown, e.g., syscall.SIGTERM.
+//
+// Returns:
+//   - The result of Shutdown.
+func (s *Session) ShutdownOnSignal(timeout time.Duration, signals ...os.Signal) (*ShutdownReport, error) {
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt}
+	}
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, signals...)
+	defer signal.Stop(ch)
+
+	sig := <-ch
+	s.Client.log().Info().Str("signal", sig.String()).Msg("Shutting down session")
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return s.Shutdown(ctx)
+}
+
+// cancelOrders cancels working orders according to the cancel policy.
+func (s *Session) cancelOrders(report *ShutdownReport) {
+	targets := make(map[string]string)
+
+	switch s.CancelPolicy {
+	case CancelNone:
+		return
+	case CancelTracked:
+		s.mu.Lock()
+		for orderNo, orderType := range s.orders {
+			targets[orderNo] = orderType
+		}
+		s.mu.Unlock()
+	case CancelAllOpen:
+		rows, err := s.Client.getOrderRows()
+		if err != nil {
+			report.Issues = append(report.Issues, ShutdownIssue{Component: "order book", Err: err})
+			return
+		}
+		for _, row := range rows {
+			if row.NormalizedStatus().Working() {
+				targets[row.ID] = "regular"
+			}
+		}
+	}
+
+	for orderNo, orderType := range targets {
+		if err := s.Client.CancelOrder(orderType, orderNo); err != nil {
+			report.Issues = append(report.Issues, ShutdownIssue{Component: "order " + orderNo, Err: err})
+			continue
+		}
+		report.CancelledOrders = append(report.CancelledOrders, orderNo)
+		s.UntrackOrder(orderNo)
+	}
+}
+
+// drainWS waits until buffered ticks have been consumed or the context expires.
+func drainWS(ctx context.Context, ws *ticks.WS) {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+
+	for len(ws.DataChan) > 0 {
+		select {
+		case <-ctx.Done():
+			defaultLog().Warn().Int("pending", len(ws.DataChan)).Msg("Websocket drain interrupted")
+			return
+		case <-ticker.C:
+		}
+	}
+}
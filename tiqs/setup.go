@@ -0,0 +1,177 @@
+package tiqs
+
+import (
+	"encoding/base32"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+)
+
+// SetupStep identifies a step of the first-run setup.
+type SetupStep string
+
+const (
+	SetupValidate SetupStep = "VALIDATE" // Check the format of the app and account credentials.
+	SetupTOTP     SetupStep = "TOTP"     // Check that the TOTP secret generates valid codes.
+	SetupLogin    SetupStep = "LOGIN"    // Log in and fetch the user profile with the new token.
+	SetupSave     SetupStep = "SAVE"     // Write the encrypted credentials file.
+)
+
+// SetupOptions holds the inputs of the first-run setup.
+type SetupOptions struct {
+	Credentials LoginCredentials                // Credentials to verify and save.
+	Path        string                          // Path of the encrypted credentials file.
+	Passphrase  string                          // Passphrase encrypting the credentials file.
+	SkipLogin   bool                            // Skip the login step, e.g., to save credentials on an offline machine.
+	Progress    func(step SetupStep, err error) // Called after every step; nil to disable.
+}
+
+// SetupResult represents the outcome of a successful setup.
+type SetupResult struct {
+	Client   *Client // The logged in client; nil if the login was skipped.
+	UserName string  // Name on the account, as returned by the user profile.
+	Path     string  // Path of the written credentials file.
+}
+
+// RunSetup verifies credentials end to end and saves them to an encrypted file.
+//
+// The steps run in order and stop at the first failure, so a wrong app secret or TOTP
+// secret is reported before anything is written: the credentials are checked for
+// obvious mistakes, the TOTP secret is decoded and a generated code is validated against
+// it, the account is logged in and the user profile fetched with the new token, and the
+// credentials are finally encrypted into the file read by LoginWithCredentialsFile.
+//
+// Parameters:
+//   - opts: The credentials, the file to write and its passphrase.
+//
+// Returns:
+//   - A pointer to a SetupResult if every step succeeded.
+//   - An error naming the failing step otherwise.
+func RunSetup(opts SetupOptions) (*SetupResult, error) {
+	creds := opts.Credentials
+	creds.TOTPSecret = NormalizeTOTPSecret(creds.TOTPSecret)
+	result := &SetupResult{}
+
+	step := func(s SetupStep, err error) error {
+		if opts.Progress != nil {
+			opts.Progress(s, err)
+		}
+		if err != nil {
+			defaultLog().Error().Err(err).Str("step", string(s)).Msg("Setup step failed")
+			return fmt.Errorf("setup %s: %w", strings.ToLower(string(s)), err)
+		}
+		return nil
+	}
+
+	if err := step(SetupValidate, ValidateLoginCredentials(creds, opts.Path, opts.Passphrase)); err != nil {
+		return nil, err
+	}
+	if err := step(SetupTOTP, VerifyTOTPSecret(creds.TOTPSecret)); err != nil {
+		return nil, err
+	}
+
+	if !opts.SkipLogin {
+		client := NewClient(creds.AppID, creds.AppSecret)
+		err := client.AutoLogin(creds.UserID, creds.Password, creds.TOTPSecret)
+		if err == nil {
+			var user *User
+			if user, err = client.GetUserDetails(); err == nil {
+				result.Client = client
+				result.UserName = user.Data.Name
+			}
+		}
+		if err != nil {
+			err = fmt.Errorf("check the user ID, password, app secret and TOTP secret: %w", err)
+		}
+		if err := step(SetupLogin, err); err != nil {
+			return nil, err
+		}
+	}
+
+	creds.CreatedAt = time.Now()
+	if err := step(SetupSave, WriteCredentialsFile(opts.Path, opts.Passphrase, creds)); err != nil {
+		return nil, err
+	}
+	result.Path = opts.Path
+
+	defaultLog().Info().Str("path", opts.Path).Msg("Setup completed successfully")
+	return result, nil
+}
+
+// ValidateLoginCredentials checks credentials and the file settings for obvious mistakes,
+// such as missing values or whitespace pasted along with a secret.
+//
+// Returns:
+//   - An error describing the first problem found; otherwise, nil.
+func ValidateLoginCredentials(creds LoginCredentials, path, passphrase string) error {
+	fields := []struct{ name, value string }{
+		{"app ID", creds.AppID},
+		{"app secret", creds.AppSecret},
+		{"user ID", creds.UserID},
+		{"password", creds.Password},
+		{"TOTP secret", creds.TOTPSecret},
+	}
+	for _, f := range fields {
+		if f.value == "" {
+			return fmt.Errorf("%s is required", f.name)
+		}
+	}
+	for _, f := range fields[:3] {
+		if strings.IndexFunc(f.value, unicode.IsSpace) >= 0 {
+			return fmt.Errorf("%s must not contain whitespace", f.name)
+		}
+	}
+	if path == "" {
+		return fmt.Errorf("credentials file path is required")
+	}
+	if len(passphrase) < 8 {
+		return fmt.Errorf("passphrase must be at least 8 characters long")
+	}
+	return nil
+}
+
+// NormalizeTOTPSecret removes the spaces and dashes authenticator apps group secrets with,
+// and upper-cases the result.
+func NormalizeTOTPSecret(secret string) string {
+	return strings.ToUpper(strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || r == '-' {
+			return -1
+		}
+		return r
+	}, secret))
+}
+
+// VerifyTOTPSecret checks that a TOTP secret is valid base32 and that a code generated
+// from it validates, catching secrets copied incompletely or from the wrong field.
+//
+// Returns:
+//   - An error if the secret cannot generate valid codes; otherwise, nil.
+func VerifyTOTPSecret(secret string) error {
+	secret = NormalizeTOTPSecret(secret)
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.TrimRight(secret, "="))
+	if err != nil {
+		return fmt.Errorf("TOTP secret is not valid base32: %w", err)
+	}
+	if len(key) < 10 {
+		return fmt.Errorf("TOTP secret is too short: %d bytes, at least 10 expected", len(key))
+	}
+
+	code, err := generateTOTP(secret)
+	if err != nil {
+		return fmt.Errorf("error generating TOTP code: %w", err)
+	}
+	ok, err := totp.ValidateCustom(code, secret, time.Now(), totp.ValidateOpts{
+		Period:    30,
+		Skew:      1,
+		Digits:    6,
+		Algorithm: otp.AlgorithmSHA1,
+	})
+	if err != nil || !ok {
+		return fmt.Errorf("generated TOTP code %s does not validate against the secret", code)
+	}
+	return nil
+}
@@ -0,0 +1,846 @@
+package tiqs
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Abhi13027/go-tiqs/ticks"
+)
+
+// DefaultSimQuoteInterval is the delay between two quote polls of a Simulator's Run.
+const DefaultSimQuoteInterval = 2 * time.Second
+
+// DefaultSimMargins is the fraction of the notional value blocked by each product in a
+// Simulator. Products without an entry block the full value.
+var DefaultSimMargins = map[Product]float64{
+	ProductMIS:     0.2,
+	ProductCNC:     1,
+	ProductNRML:    1,
+	ProductBracket: 0.2,
+	ProductCover:   0.2,
+}
+
+// Simulator executes orders against market data instead of sending them to the exchange,
+// keeping virtual orders, trades, positions and limits.
+//
+// Attached to a client with SetSimulator (or created with NewSimulatedClient), it answers
+// the order placement, modification, cancellation, order book, trade book, positions,
+// position conversion and limits requests, so strategies written against the Client run unchanged in paper mode.
+// Every other request, such as quotes and historical data, still goes to the API.
+//
+// Orders fill in full at the top of the book: market orders at once, limit orders as soon
+// as they are marketable and stop-loss orders once the last traded price crosses their
+// trigger. Prices come from the ticks passed to Update and, for tokens without a recent
+// tick, from quotes fetched through the client.
+type Simulator struct {
+	Capital       float64             // Starting cash of the virtual account.
+	Margins       map[Product]float64 // Fraction of the notional value blocked per product; DefaultSimMargins if nil.
+	Slippage      float64             // Adverse slippage of market fills, in basis points.
+	QuoteInterval time.Duration       // Delay between quote polls in Run; zero uses DefaultSimQuoteInterval.
+	OnOrder       func(OrderDetail)   // Optional callback for every change of an order's status.
+	OnFill        func(Trade)         // Optional callback for every fill.
+	Now           func() time.Time    // Optional clock; time.Now if nil.
+
+	client    *Client
+	mu        sync.Mutex
+	seq       int64
+	orders    map[string]*simOrder
+	sequence  []string
+	trades    []Trade
+	positions map[simKey]*simPosition
+	markets   map[int64]simMarket
+}
+
+// simOrder is an order held by a Simulator.
+type simOrder struct {
+	no        string
+	req       OrderRequest
+	qty       int64
+	price     float64
+	trigger   float64
+	status    OrderStatus
+	triggered bool
+	filled    int64
+	avgPrice  float64
+	reason    string
+	placed    time.Time
+	updated   time.Time
+}
+
+// simKey identifies a virtual position.
+type simKey struct {
+	token   int64
+	product Product
+}
+
+// simPosition is a virtual position of a Simulator.
+type simPosition struct {
+	exchange   Exchange
+	symbol     string
+	netQty     int64
+	avgPrice   float64
+	realized   float64
+	buyQty     int64
+	buyAmount  float64
+	sellQty    int64
+	sellAmount float64
+}
+
+// simMarket is the last known price of a token, in rupees.
+type simMarket struct {
+	ltp, bid, ask float64
+	at            time.Time
+}
+
+// NewSimulator creates a simulator with an empty virtual account.
+//
+// Parameters:
+//   - capital: The starting cash of the account, in rupees.
+//
+// Returns:
+//   - A pointer to a newly created Simulator.
+func NewSimulator(capital float64) *Simulator {
+	return &Simulator{
+		Capital:   capital,
+		orders:    make(map[string]*simOrder),
+		positions: make(map[simKey]*simPosition),
+		markets:   make(map[int64]simMarket),
+	}
+}
+
+// NewSimulatedClient initializes a client that trades against a Simulator instead of the
+// exchange. Market data requests still need valid credentials and a token.
+//
+// Parameters:
+//   - appID: The application ID used for authentication.
+//   - appSecret: The application secret key used for authentication.
+//   - capital: The starting cash of the virtual account, in rupees.
+//
+// Returns:
+//   - A pointer to a newly created Client with the simulator attached.
+func NewSimulatedClient(appID, appSecret string, capital float64) *Client {
+	c := NewClient(appID, appSecret)
+	c.SetSimulator(NewSimulator(capital))
+	return c
+}
+
+// SetSimulator attaches a simulator that executes orders instead of the exchange.
+// Pass nil to trade live again.
+func (c *Client) SetSimulator(sim *Simulator) {
+	if sim != nil {
+		sim.client = c
+	}
+	c.sim = sim
+}
+
+// Simulator returns the simulator attached to the client, or nil when trading live.
+func (c *Client) Simulator() *Simulator {
+	return c.sim
+}
+
+// Update records the prices of a tick and executes the working orders of its token that
+// became marketable.
+func (s *Simulator) Update(tick ticks.TickData) {
+	token := int64(tick.Token)
+	divisor := s.prices().Divisor(token)
+	m := simMarket{
+		ltp: float64(tick.LTP) / divisor,
+		bid: float64(tick.MarketDepth.Bids[0].Price) / divisor,
+		ask: float64(tick.MarketDepth.Asks[0].Price) / divisor,
+		at:  s.now(),
+	}
+
+	s.mu.Lock()
+	events := s.updateLocked(token, m)
+	s.mu.Unlock()
+	s.notify(events)
+}
+
+// Run polls quotes for the tokens of working orders that had no tick within
+// QuoteInterval, executing the orders that became marketable, until the context is
+// cancelled. It is not needed when every traded token is passed to Update.
+//
+// Parameters:
+//   - ctx: Context whose cancellation stops the polling.
+//
+// Returns:
+//   - The context's error once it is cancelled.
+func (s *Simulator) Run(ctx context.Context) error {
+	interval := cmp.Or(s.QuoteInterval, DefaultSimQuoteInterval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			s.poll(interval)
+		}
+	}
+}
+
+// poll fetches quotes for the tokens of working orders without a price newer than maxAge.
+func (s *Simulator) poll(maxAge time.Duration) {
+	s.mu.Lock()
+	seen := make(map[int64]bool)
+	var tokens []int64
+	for _, o := range s.orders {
+		token := parseInt(o.req.Token)
+		if !o.status.Terminal() && !seen[token] && s.now().Sub(s.markets[token].at) > maxAge {
+			seen[token] = true
+			tokens = append(tokens, token)
+		}
+	}
+	s.mu.Unlock()
+
+	if len(tokens) == 0 || s.client == nil {
+		return
+	}
+	quotes, err := s.client.GetFullQuotes(tokens)
+	if err != nil {
+		s.client.log().Warn().Err(err).Msg("Simulator failed to fetch quotes")
+		return
+	}
+	for _, q := range quotes {
+		s.applyQuote(q)
+	}
+}
+
+// applyQuote records the prices of a quote and executes the orders it makes marketable.
+func (s *Simulator) applyQuote(q FullQuote) {
+	divisor := s.prices().Divisor(q.Token)
+	m := simMarket{ltp: float64(q.LTP) / divisor, at: s.now()}
+	if bid, ok := q.BestBid(); ok {
+		m.bid = float64(bid.Price) / divisor
+	}
+	if ask, ok := q.BestAsk(); ok {
+		m.ask = float64(ask.Price) / divisor
+	}
+
+	s.mu.Lock()
+	events := s.updateLocked(q.Token, m)
+	s.mu.Unlock()
+	s.notify(events)
+}
+
+// Orders returns every order of the session in the order they were placed.
+func (s *Simulator) Orders() []OrderDetail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	details := make([]OrderDetail, 0, len(s.sequence))
+	for _, no := range s.sequence {
+		details = append(details, s.orders[no].detail())
+	}
+	return details
+}
+
+// Trades returns every fill of the session in the order they occurred.
+func (s *Simulator) Trades() []Trade {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Trade(nil), s.trades...)
+}
+
+// Positions returns the virtual positions, marked to the last known prices.
+func (s *Simulator) Positions() []Position {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := make([]simKey, 0, len(s.positions))
+	for key := range s.positions {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].token != keys[j].token {
+			return keys[i].token < keys[j].token
+		}
+		return keys[i].product < keys[j].product
+	})
+
+	positions := make([]Position, 0, len(keys))
+	for _, key := range keys {
+		positions = append(positions, s.position(key))
+	}
+	return positions
+}
+
+// Funds returns the cash available for new orders: the capital plus realized and
+// unrealized profit, less the margin blocked by positions and working orders.
+func (s *Simulator) Funds() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.availableLocked()
+}
+
+// serve answers a request of the client if it belongs to the simulator.
+//
+// Returns:
+//   - The response body, in the format of the v1 API.
+//   - Whether the simulator handles the request; if not, it must go to the API.
+func (s *Simulator) serve(c *Client, method, endpoint string, payload []byte) ([]byte, bool) {
+	switch {
+	case method == "GET" && endpoint == c.endpoint(EndpointOrderBook):
+		return simSuccess(s.Orders()), true
+	case method == "GET" && endpoint == c.endpoint(EndpointTrades):
+		return simSuccess(s.Trades()), true
+	case method == "GET" && endpoint == c.endpoint(EndpointPositions):
+		return simSuccess(s.Positions()), true
+	case method == "GET" && endpoint == c.endpoint(EndpointLimits):
+		return simSuccess([]map[string]string{s.limits()}), true
+	case method == "POST" && endpoint == c.endpoint(EndpointConvertPosition):
+		return s.convert(payload), true
+	}
+
+	rest, ok := strings.CutPrefix(endpoint, c.endpointPrefix(EndpointPlaceOrder))
+	if !ok {
+		return nil, false
+	}
+	// Placements are sent to /order/{variety}, history requests to /order/{orderNo}, and
+	// modifications and cancellations to /order/{variety}/{orderNo}.
+	first, orderNo, _ := strings.Cut(rest, "/")
+
+	switch method {
+	case "POST":
+		return s.place(payload), true
+	case "PATCH":
+		return s.modify(orderNo, payload), true
+	case "DELETE":
+		return s.cancel(orderNo), true
+	case "GET":
+		return s.history(first), true
+	}
+	return nil, false
+}
+
+// place handles an order placement.
+func (s *Simulator) place(payload []byte) []byte {
+	var req OrderRequest
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return simError("invalid order request: " + err.Error())
+	}
+	token := parseInt(req.Token)
+	s.ensureMarket(token, req.OrderType)
+
+	s.mu.Lock()
+	s.seq++
+	now := s.now()
+	o := &simOrder{
+		no:      fmt.Sprintf("%s9%07d", now.In(IST).Format("060102"), s.seq),
+		req:     req,
+		qty:     parseInt(req.Quantity),
+		price:   parseFloat(req.Price),
+		trigger: parseFloat(req.TriggerPrice),
+		status:  OrderStatusOpen,
+		placed:  now,
+		updated: now,
+	}
+	if req.OrderType.NeedsTrigger() {
+		o.status = OrderStatusTriggerPending
+	}
+	s.orders[o.no] = o
+	s.sequence = append(s.sequence, o.no)
+
+	events := []simEvent{{order: o.detail()}}
+	switch {
+	case o.qty <= 0:
+		events = append(events, s.rejectLocked(o, "invalid quantity"))
+	case s.requiredLocked(o) > s.availableLocked():
+		events = append(events, s.rejectLocked(o, "insufficient funds"))
+	default:
+		events = append(events, s.matchLocked(o, s.markets[token], true)...)
+	}
+	s.mu.Unlock()
+	s.notify(events)
+
+	return simSuccess(map[string]string{"orderNo": o.no, "requestTime": now.In(IST).Format("15:04:05 02-01-2006")})
+}
+
+// modify handles an order modification.
+func (s *Simulator) modify(orderNo string, payload []byte) []byte {
+	var req OrderRequest
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return simError("invalid order request: " + err.Error())
+	}
+
+	s.mu.Lock()
+	o, ok := s.orders[orderNo]
+	if !ok || o.status.Terminal() {
+		s.mu.Unlock()
+		return simError(fmt.Sprintf("order %s is not open", orderNo))
+	}
+	if qty := parseInt(req.Quantity); qty > 0 {
+		o.qty = qty
+	}
+	if req.Price != "" {
+		o.price = parseFloat(req.Price)
+	}
+	if req.TriggerPrice != "" {
+		o.trigger = parseFloat(req.TriggerPrice)
+	}
+	if req.OrderType != "" {
+		o.req.OrderType = req.OrderType
+	}
+	o.updated = s.now()
+	events := []simEvent{{order: o.detail()}}
+	events = append(events, s.matchLocked(o, s.markets[parseInt(o.req.Token)], false)...)
+	s.mu.Unlock()
+	s.notify(events)
+
+	return simSuccess(map[string]string{"orderNo": orderNo})
+}
+
+// cancel handles an order cancellation.
+func (s *Simulator) cancel(orderNo string) []byte {
+	s.mu.Lock()
+	o, ok := s.orders[orderNo]
+	if !ok || o.status.Terminal() {
+		s.mu.Unlock()
+		return simError(fmt.Sprintf("order %s is not open", orderNo))
+	}
+	o.status = OrderStatusCancelled
+	o.updated = s.now()
+	event := simEvent{order: o.detail()}
+	s.mu.Unlock()
+	s.notify([]simEvent{event})
+
+	return simSuccess(map[string]string{"message": "order " + orderNo + " cancelled"})
+}
+
+// history handles an order history request.
+func (s *Simulator) history(orderNo string) []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o, ok := s.orders[orderNo]
+	if !ok {
+		return simError(fmt.Sprintf("order %s not found", orderNo))
+	}
+	return simSuccess([]OrderDetail{o.detail()})
+}
+
+// ensureMarket fetches a quote for a market order on a token without a known price.
+func (s *Simulator) ensureMarket(token int64, orderType OrderType) {
+	if orderType != OrderTypeMarket || s.client == nil {
+		return
+	}
+	s.mu.Lock()
+	_, known := s.markets[token]
+	s.mu.Unlock()
+	if known {
+		return
+	}
+	quote, err := s.client.GetFullQuote(token)
+	if err != nil {
+		s.client.log().Warn().Err(err).Int64("token", token).Msg("Simulator failed to fetch quote")
+		return
+	}
+	s.applyQuote(*quote)
+}
+
+// simEvent is an order change or fill to report once the lock is released.
+type simEvent struct {
+	order OrderDetail
+	fill  *Trade
+}
+
+// notify calls the callbacks with the events of an update.
+func (s *Simulator) notify(events []simEvent) {
+	for _, e := range events {
+		if e.fill != nil {
+			s.client.log().Info().Str("orderNo", e.fill.ID).Str("price", e.fill.FillPrice).Msg("Simulated fill")
+			if s.OnFill != nil {
+				s.OnFill(*e.fill)
+			}
+		}
+		if e.order.ID != "" && s.OnOrder != nil {
+			s.OnOrder(e.order)
+		}
+	}
+}
+
+// updateLocked records the price of a token and executes its marketable orders.
+func (s *Simulator) updateLocked(token int64, m simMarket) []simEvent {
+	s.markets[token] = m
+
+	var events []simEvent
+	for _, no := range s.sequence {
+		o := s.orders[no]
+		if parseInt(o.req.Token) == token && !o.status.Terminal() {
+			events = append(events, s.matchLocked(o, m, false)...)
+		}
+	}
+	return events
+}
+
+// matchLocked triggers and fills an order if the market allows it. IOC orders that do not
+// fill on arrival are cancelled.
+func (s *Simulator) matchLocked(o *simOrder, m simMarket, arrival bool) []simEvent {
+	var events []simEvent
+	buy := o.req.TransactionType == TransactionBuy
+
+	if o.status == OrderStatusTriggerPending && m.ltp > 0 {
+		if buy && m.ltp >= o.trigger || !buy && m.ltp <= o.trigger {
+			o.status = OrderStatusOpen
+			o.triggered = true
+			o.updated = s.now()
+			events = append(events, simEvent{order: o.detail()})
+		}
+	}
+
+	if o.status == OrderStatusOpen {
+		if price, ok := s.fillPrice(o, m, buy); ok {
+			events = append(events, s.fillLocked(o, price)...)
+		}
+	}
+
+	if arrival && o.req.Validity == ValidityIOC && o.status == OrderStatusOpen {
+		o.status = OrderStatusCancelled
+		o.updated = s.now()
+		events = append(events, simEvent{order: o.detail()})
+	}
+	return events
+}
+
+// fillPrice returns the price an open order fills at, if it is marketable.
+func (s *Simulator) fillPrice(o *simOrder, m simMarket, buy bool) (float64, bool) {
+	touch := cmp.Or(m.bid, m.ltp)
+	if buy {
+		touch = cmp.Or(m.ask, m.ltp)
+	}
+	if touch <= 0 {
+		return 0, false
+	}
+
+	switch o.req.OrderType {
+	case OrderTypeMarket, OrderTypeStopLossMkt:
+		slip := touch * s.Slippage / 10000
+		if buy {
+			return s.prices().RoundToTick(parseInt(o.req.Token), touch+slip), true
+		}
+		return s.prices().RoundToTick(parseInt(o.req.Token), touch-slip), true
+	default:
+		if buy && touch <= o.price || !buy && touch >= o.price {
+			return touch, true
+		}
+		return 0, false
+	}
+}
+
+// fillLocked fills the remaining quantity of an order and updates its position.
+func (s *Simulator) fillLocked(o *simOrder, price float64) []simEvent {
+	qty := o.qty - o.filled
+	now := s.now()
+
+	o.avgPrice = (o.avgPrice*float64(o.filled) + price*float64(qty)) / float64(o.qty)
+	o.filled = o.qty
+	o.status = OrderStatusComplete
+	o.updated = now
+
+	token := parseInt(o.req.Token)
+	key := simKey{token: token, product: o.req.Product}
+	p, ok := s.positions[key]
+	if !ok {
+		p = &simPosition{exchange: o.req.Exchange, symbol: o.req.Symbol}
+		s.positions[key] = p
+	}
+	signed := qty
+	if o.req.TransactionType == TransactionSell {
+		signed = -qty
+		p.sellQty += qty
+		p.sellAmount += price * float64(qty)
+	} else {
+		p.buyQty += qty
+		p.buyAmount += price * float64(qty)
+	}
+	p.apply(signed, price)
+
+	trade := Trade{
+		ID:              o.no,
+		FillID:          strconv.Itoa(len(s.trades) + 1),
+		Exchange:        string(o.req.Exchange),
+		Symbol:          o.req.Symbol,
+		Token:           o.req.Token,
+		Product:         string(o.req.Product),
+		TransactionType: string(o.req.TransactionType),
+		Order:           string(o.req.OrderType),
+		Quantity:        strconv.FormatInt(o.qty, 10),
+		FillShares:      strconv.FormatInt(qty, 10),
+		FillPrice:       formatSimPrice(price),
+		AveragePrice:    formatSimPrice(o.avgPrice),
+		FillTime:        now.In(IST).Format("02-01-2006 15:04:05"),
+		Remarks:         o.req.Tags,
+	}
+	s.trades = append(s.trades, trade)
+	return []simEvent{{order: o.detail(), fill: &trade}}
+}
+
+// convert handles a position conversion, moving open quantity between products at its
+// average price.
+func (s *Simulator) convert(payload []byte) []byte {
+	var req ConvertPositionRequest
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return simError("invalid conversion request: " + err.Error())
+	}
+	qty := parseInt(req.Quantity)
+	token := parseInt(req.Token)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	from, ok := s.positions[simKey{token: token, product: req.PreviousProduct}]
+	signed := qty
+	if req.TransactionType == TransactionSell {
+		signed = -qty
+	}
+	if !ok || qty <= 0 || (from.netQty > 0) != (signed > 0) || abs64(from.netQty) < qty {
+		return simError("no open position to convert")
+	}
+
+	key := simKey{token: token, product: req.Product}
+	to, ok := s.positions[key]
+	if !ok {
+		to = &simPosition{exchange: from.exchange, symbol: from.symbol}
+		s.positions[key] = to
+	}
+	amount := from.avgPrice * float64(qty)
+	if signed > 0 {
+		from.buyQty, from.buyAmount = from.buyQty-qty, from.buyAmount-amount
+		to.buyQty, to.buyAmount = to.buyQty+qty, to.buyAmount+amount
+	} else {
+		from.sellQty, from.sellAmount = from.sellQty-qty, from.sellAmount-amount
+		to.sellQty, to.sellAmount = to.sellQty+qty, to.sellAmount+amount
+	}
+	to.apply(signed, from.avgPrice)
+	from.netQty -= signed
+	if from.netQty == 0 {
+		from.avgPrice = 0
+	}
+	return simSuccess(map[string]string{"message": "Position converted"})
+}
+
+// apply adds a signed fill to the position, realizing the profit of the closed quantity.
+func (p *simPosition) apply(qty int64, price float64) {
+	switch {
+	case p.netQty == 0 || (p.netQty > 0) == (qty > 0):
+		total := p.netQty + qty
+		p.avgPrice = (p.avgPrice*float64(p.netQty) + price*float64(qty)) / float64(total)
+		p.netQty = total
+	default:
+		closed := min(abs64(qty), abs64(p.netQty))
+		if p.netQty > 0 {
+			p.realized += (price - p.avgPrice) * float64(closed)
+		} else {
+			p.realized += (p.avgPrice - price) * float64(closed)
+		}
+		p.netQty += qty
+		switch {
+		case p.netQty == 0:
+			p.avgPrice = 0
+		case abs64(qty) > closed:
+			// The fill reversed the position, which opens at the fill price.
+			p.avgPrice = price
+		}
+	}
+}
+
+// rejectLocked rejects an order.
+func (s *Simulator) rejectLocked(o *simOrder, reason string) simEvent {
+	o.status = OrderStatusRejected
+	o.reason = reason
+	o.updated = s.now()
+	s.client.log().Warn().Str("orderNo", o.no).Str("reason", reason).Msg("Simulated order rejected")
+	return simEvent{order: o.detail()}
+}
+
+// requiredLocked returns the margin a new order blocks, which is zero for orders that
+// only reduce a position.
+func (s *Simulator) requiredLocked(o *simOrder) float64 {
+	token := parseInt(o.req.Token)
+	if p, ok := s.positions[simKey{token: token, product: o.req.Product}]; ok {
+		reduces := p.netQty > 0 && o.req.TransactionType == TransactionSell ||
+			p.netQty < 0 && o.req.TransactionType == TransactionBuy
+		if reduces && o.qty <= abs64(p.netQty) {
+			return 0
+		}
+	}
+	return float64(o.qty) * s.referencePrice(o) * s.margin(o.req.Product)
+}
+
+// referencePrice returns the price an order is valued at for margin purposes.
+func (s *Simulator) referencePrice(o *simOrder) float64 {
+	if o.price > 0 {
+		return o.price
+	}
+	m := s.markets[parseInt(o.req.Token)]
+	return cmp.Or(m.ltp, m.ask, m.bid, o.trigger)
+}
+
+// availableLocked returns the cash available for new orders.
+func (s *Simulator) availableLocked() float64 {
+	realized, unrealized, used := s.totalsLocked()
+	return s.Capital + realized + unrealized - used
+}
+
+// totalsLocked returns the realized and unrealized profit and the margin in use.
+func (s *Simulator) totalsLocked() (realized, unrealized, used float64) {
+	for key, p := range s.positions {
+		realized += p.realized
+		if p.netQty != 0 {
+			unrealized += float64(p.netQty) * (s.markPrice(key.token, p) - p.avgPrice)
+			used += float64(abs64(p.netQty)) * p.avgPrice * s.margin(key.product)
+		}
+	}
+	for _, o := range s.orders {
+		if !o.status.Terminal() {
+			used += s.requiredLocked(o)
+		}
+	}
+	return realized, unrealized, used
+}
+
+// markPrice returns the price a position is marked at.
+func (s *Simulator) markPrice(token int64, p *simPosition) float64 {
+	return cmp.Or(s.markets[token].ltp, p.avgPrice)
+}
+
+// margin returns the fraction of the notional value a product blocks.
+func (s *Simulator) margin(product Product) float64 {
+	margins := s.Margins
+	if margins == nil {
+		margins = DefaultSimMargins
+	}
+	if rate, ok := margins[product]; ok {
+		return rate
+	}
+	return 1
+}
+
+// position renders a virtual position in the format of the positions endpoint.
+func (s *Simulator) position(key simKey) Position {
+	p := s.positions[key]
+	ltp := s.markPrice(key.token, p)
+	unrealized := float64(p.netQty) * (ltp - p.avgPrice)
+	avg := func(amount float64, qty int64) float64 {
+		if qty == 0 {
+			return 0
+		}
+		return amount / float64(qty)
+	}
+
+	return Position{
+		AvgPrice:        formatSimPrice(p.avgPrice),
+		DayBuyAmount:    formatSimPrice(p.buyAmount),
+		DayBuyAvgPrice:  formatSimPrice(avg(p.buyAmount, p.buyQty)),
+		DayBuyQty:       strconv.FormatInt(p.buyQty, 10),
+		DaySellAmount:   formatSimPrice(p.sellAmount),
+		DaySellAvgPrice: formatSimPrice(avg(p.sellAmount, p.sellQty)),
+		DaySellQty:      strconv.FormatInt(p.sellQty, 10),
+		Exchange:        string(p.exchange),
+		Ltp:             formatSimPrice(ltp),
+		NetBuyQty:       strconv.FormatInt(p.buyQty, 10),
+		NetSellQty:      strconv.FormatInt(p.sellQty, 10),
+		Pnl:             formatSimPrice(p.realized + unrealized),
+		Product:         string(key.product),
+		Qty:             strconv.FormatInt(p.netQty, 10),
+		RealisedPnL:     formatSimPrice(p.realized),
+		Symbol:          p.symbol,
+		Token:           strconv.FormatInt(key.token, 10),
+		UnRealisedPnl:   formatSimPrice(unrealized),
+	}
+}
+
+// limits renders the virtual account in the format of the limits endpoint.
+func (s *Simulator) limits() map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	realized, unrealized, used := s.totalsLocked()
+	return map[string]string{
+		"cash":           formatSimPrice(s.Capital),
+		"payIn":          "0",
+		"payOut":         "0",
+		"marginUsed":     formatSimPrice(used),
+		"realisedPnL":    formatSimPrice(realized),
+		"unRealisedMtoM": formatSimPrice(unrealized),
+	}
+}
+
+// detail renders an order in the format of the order book.
+func (o *simOrder) detail() OrderDetail {
+	// Triggered stop-loss orders are reported as their limit or market counterpart.
+	orderType := o.req.OrderType
+	switch {
+	case o.triggered && orderType == OrderTypeStopLoss:
+		orderType = OrderTypeLimit
+	case o.triggered && orderType == OrderTypeStopLossMkt:
+		orderType = OrderTypeMarket
+	}
+	return OrderDetail{
+		Status:             "success",
+		Exchange:           string(o.req.Exchange),
+		Symbol:             o.req.Symbol,
+		ID:                 o.no,
+		Price:              formatSimPrice(o.price),
+		Quantity:           strconv.FormatInt(o.qty, 10),
+		Product:            string(o.req.Product),
+		OrderStatus:        string(o.status),
+		TransactionType:    string(o.req.TransactionType),
+		Order:              string(orderType),
+		FillShares:         strconv.FormatInt(o.filled, 10),
+		AveragePrice:       formatSimPrice(o.avgPrice),
+		RejectReason:       o.reason,
+		Remarks:            o.req.Tags,
+		OrderTriggerPrice:  formatSimPrice(o.trigger),
+		Retention:          string(o.req.Validity),
+		Token:              o.req.Token,
+		TimeStamp:          o.updated.In(IST).Format("15:04:05 02-01-2006"),
+		OrderTime:          o.placed.In(IST).Format("02-01-2006 15:04:05"),
+		ExchangeUpdateTime: o.updated.In(IST).Format("02-01-2006 15:04:05"),
+		RequestTime:        o.placed.In(IST).Format("15:04:05 02-01-2006"),
+	}
+}
+
+// prices returns the converter of the attached client, which knows the precision of
+// every loaded instrument.
+func (s *Simulator) prices() *PriceConverter {
+	if s.client != nil {
+		return s.client.PriceConverter()
+	}
+	return NewPriceConverter(nil)
+}
+
+// now returns the current time of the simulator's clock.
+func (s *Simulator) now() time.Time {
+	if s.Now != nil {
+		return s.Now()
+	}
+	return time.Now()
+}
+
+// simSuccess encodes a successful response with the given data.
+func simSuccess(data any) []byte {
+	body, _ := json.Marshal(map[string]any{"status": "success", "data": data})
+	return body
+}
+
+// simError encodes a failed response with the given message.
+func simError(message string) []byte {
+	body, _ := json.Marshal(map[string]string{"status": "error", "message": message})
+	return body
+}
+
+// formatSimPrice formats an amount in rupees, rounded to four decimal places to cover
+// currency prices.
+func formatSimPrice(v float64) string {
+	return strconv.FormatFloat(math.Round(v*10000)/10000, 'f', -1, 64)
+}
@@ -0,0 +1,167 @@
+package tiqs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultFreezeLimits are the largest quantities, in units, accepted in one order for the
+// index derivatives of each underlying. Exchanges revise them periodically, and stock
+// derivatives have limits of their own; use SetFreezeLimits to keep them current.
+var DefaultFreezeLimits = map[string]int64{
+	"NIFTY":      1800,
+	"BANKNIFTY":  900,
+	"FINNIFTY":   1800,
+	"MIDCPNIFTY": 2800,
+	"NIFTYNXT50": 600,
+	"SENSEX":     1000,
+	"BANKEX":     900,
+}
+
+// SlicedOrder is the outcome of placing an order split into child orders.
+type SlicedOrder struct {
+	Orders   []OrderRequest // The child orders, in the order they are placed.
+	OrderNos []string       // Order numbers of the children placed, in the same order.
+}
+
+// SetFreezeLimits replaces the freeze limits used to slice derivative orders.
+//
+// Parameters:
+//   - limits: The largest quantity, in units, accepted in one order, keyed by underlying
+//     symbol (e.g., "NIFTY"); nil restores DefaultFreezeLimits.
+func (c *Client) SetFreezeLimits(limits map[string]int64) {
+	c.freezeLimits = limits
+}
+
+// FreezeLimit returns the largest quantity of an instrument, in units, accepted in one
+// order, or zero if it is not limited. Only equity and index derivatives (NFO and BFO)
+// have freeze limits.
+func (c *Client) FreezeLimit(inst Instrument) int64 {
+	if Exchange(strings.ToUpper(inst.Exchange)).Segment() != SegmentDerivatives {
+		return 0
+	}
+	limits := c.freezeLimits
+	if limits == nil {
+		limits = DefaultFreezeLimits
+	}
+	return limits[strings.ToUpper(inst.Symbol)]
+}
+
+// SliceQuantity splits a quantity into parts of at most limit, each a multiple of the lot
+// size, as the exchange requires of orders above the freeze limit.
+//
+// Parameters:
+//   - quantity: The total quantity to split.
+//   - limit: The largest part; zero or less does not split.
+//   - lotSize: The lot size parts must be multiples of; zero or less for none.
+//
+// Returns:
+//   - The parts, largest first, summing to quantity.
+//   - An error if quantity is not positive, is not a multiple of the lot size, or limit is
+//     smaller than one lot.
+func SliceQuantity(quantity, limit, lotSize int64) ([]int64, error) {
+	if quantity <= 0 {
+		return nil, fmt.Errorf("invalid quantity: %d", quantity)
+	}
+	if lotSize <= 0 {
+		lotSize = 1
+	}
+	if quantity%lotSize != 0 {
+		return nil, fmt.Errorf("quantity %d is not a multiple of the lot size %d", quantity, lotSize)
+	}
+	if limit <= 0 || quantity <= limit {
+		return []int64{quantity}, nil
+	}
+
+	part := limit - limit%lotSize
+	if part <= 0 {
+		return nil, fmt.Errorf("freeze limit %d is smaller than the lot size %d", limit, lotSize)
+	}
+	parts := make([]int64, 0, (quantity+part-1)/part)
+	for quantity > 0 {
+		n := min(part, quantity)
+		parts = append(parts, n)
+		quantity -= n
+	}
+	return parts, nil
+}
+
+// SliceOrder splits an order above its instrument's freeze limit into child orders that
+// the exchange accepts, each a whole number of lots.
+//
+// The instrument is looked up in the attached instrument store; orders in unknown
+// instruments, or below the limit, are returned unchanged. Quantities of segments that
+// trade in lots are converted so the limit, which is in units, applies to them too.
+//
+// Parameters:
+//   - order: The order to split.
+//
+// Returns:
+//   - The child orders, or the order itself if it needs no splitting.
+//   - An error if the quantity is invalid or not a multiple of the lot size.
+func (c *Client) SliceOrder(order OrderRequest) ([]OrderRequest, error) {
+	quantity := parseInt(order.Quantity)
+	if quantity <= 0 {
+		return nil, fmt.Errorf("invalid order quantity: %q", order.Quantity)
+	}
+
+	inst := c.PriceConverter().Instrument(parseInt(order.Token))
+	limit := c.FreezeLimit(inst)
+	if limit <= 0 || inst.Units(quantity) <= limit {
+		return []OrderRequest{order}, nil
+	}
+
+	// Slice in units, then convert every part back into the order's convention.
+	parts, err := SliceQuantity(inst.Units(quantity), limit, inst.LotSize)
+	if err != nil {
+		return nil, fmt.Errorf("error slicing order in %s: %w", order.Symbol, err)
+	}
+	disclosed := parseInt(order.DisclosedQty)
+	perQuantity := inst.Units(1)
+
+	children := make([]OrderRequest, len(parts))
+	for i, units := range parts {
+		child := withQuantity(order, strconv.FormatInt(units/perQuantity, 10))
+		if disclosed > 0 {
+			child.DisclosedQty = strconv.FormatInt(min(disclosed, parseInt(child.Quantity)), 10)
+		}
+		// The children are identical by design, so they must not trip the DuplicateGuard.
+		child.AllowDuplicate = true
+		children[i] = child
+	}
+	return children, nil
+}
+
+// PlaceSlicedOrder places an order, split into child orders when it exceeds its
+// instrument's freeze limit (see SliceOrder).
+//
+// Children are placed one after the other; placement stops at the first failure, so that
+// a rejected child does not leave a larger position than intended.
+//
+// Parameters:
+//   - orderType: Type of order (e.g., "regular").
+//   - order: The order to place.
+//
+// Returns:
+//   - The child orders and the order numbers of those placed, even on failure.
+//   - An error if the order cannot be sliced or a child cannot be placed.
+func (c *Client) PlaceSlicedOrder(orderType string, order OrderRequest) (*SlicedOrder, error) {
+	children, err := c.SliceOrder(order)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SlicedOrder{Orders: children}
+	for i, child := range children {
+		resp, err := c.PlaceOrder(orderType, child)
+		if err != nil {
+			c.log().Error().Err(err).Str("symbol", order.Symbol).Int("child", i+1).Int("children", len(children)).Msg("Failed to place sliced order")
+			return result, fmt.Errorf("child %d of %d: %w", i+1, len(children), err)
+		}
+		result.OrderNos = append(result.OrderNos, resp.Data.OrderNo)
+	}
+
+	c.log().Info().Str("symbol", order.Symbol).Str("quantity", order.Quantity).Int("children", len(children)).Msg("Sliced order placed")
+	return result, nil
+}
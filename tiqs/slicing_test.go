@@ -0,0 +1,143 @@
+package tiqs_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Abhi13027/go-tiqs/tiqs"
+)
+
+func TestSliceQuantity(t *testing.T) {
+	tests := []struct {
+		name                     string
+		quantity, limit, lotSize int64
+		want                     []int64
+		wantErr                  bool
+	}{
+		{"below the limit", 75, 1800, 75, []int64{75}, false},
+		{"equal to the limit", 1800, 1800, 75, []int64{1800}, false},
+		{"even parts", 3600, 1800, 75, []int64{1800, 1800}, false},
+		{"remainder", 4050, 1800, 75, []int64{1800, 1800, 450}, false},
+		{"limit rounded down to lots", 300, 100, 30, []int64{90, 90, 90, 30}, false},
+		{"no limit", 4500, 0, 75, []int64{4500}, false},
+		{"no lot size", 10, 4, 0, []int64{4, 4, 2}, false},
+		{"zero quantity", 0, 1800, 75, nil, true},
+		{"not a multiple of the lot size", 100, 1800, 75, nil, true},
+		{"limit below one lot", 150, 50, 75, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tiqs.SliceQuantity(tt.quantity, tt.limit, tt.lotSize)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SliceQuantity(%d, %d, %d) error = %v, want error %t", tt.quantity, tt.limit, tt.lotSize, err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("SliceQuantity(%d, %d, %d) = %v, want %v", tt.quantity, tt.limit, tt.lotSize, got, tt.want)
+			}
+		})
+	}
+}
+
+// rejectNth rejects the nth order placed, passing every other request to the mock server.
+type rejectNth struct {
+	next   http.Handler
+	n      int
+	mu     sync.Mutex
+	orders int
+}
+
+func (h *rejectNth) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost && r.URL.Path == "/order/regular" {
+		h.mu.Lock()
+		h.orders++
+		reject := h.orders == h.n
+		h.mu.Unlock()
+		if reject {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"status":"error","message":"order rejected"}`))
+			return
+		}
+	}
+	h.next.ServeHTTP(w, r)
+}
+
+// TestExitPositionSliced exits a short position of five lots, sliced into children of at
+// most two lots, with one of the children rejected.
+func TestExitPositionSliced(t *testing.T) {
+	position := tiqs.Position{
+		Exchange: "NFO",
+		Token:    "35001",
+		Symbol:   "NIFTY24DEC24000CE",
+		Product:  "I",
+		Qty:      "-375",
+		LotSize:  "75",
+	}
+
+	tests := []struct {
+		reject      int // child rejected, zero for none
+		wantPlaced  int64
+		wantPartial bool
+	}{
+		{0, 375, false},
+		{1, 0, false},
+		{2, 150, true},
+		{3, 300, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("reject %d", tt.reject), func(t *testing.T) {
+			client, mock := newTestClient(t)
+			server := httptest.NewServer(&rejectNth{next: mock.Config.Handler, n: tt.reject})
+			t.Cleanup(server.Close)
+			client.Config.BaseURL = server.URL
+			if _, err := client.LoadInstruments(true); err != nil {
+				t.Fatal(err)
+			}
+			client.SetFreezeLimits(map[string]int64{"NIFTY": 150})
+
+			result, err := client.ExitPosition(position, tiqs.OrderTypeMarket)
+			if tt.reject == 0 && err != nil {
+				t.Fatal(err)
+			}
+			if want := fmt.Sprintf("child %d of 3", tt.reject); tt.reject > 0 && (err == nil || !strings.Contains(err.Error(), want)) {
+				t.Fatalf("ExitPosition = %v, want the error of %s", err, want)
+			}
+
+			var quantities []string
+			for _, o := range result.Orders {
+				quantities = append(quantities, o.Quantity)
+			}
+			if want := []string{"150", "150", "75"}; !slices.Equal(quantities, want) {
+				t.Fatalf("child quantities = %v, want %v", quantities, want)
+			}
+			if got := result.PlacedQuantity(); got != tt.wantPlaced {
+				t.Errorf("PlacedQuantity = %d, want %d", got, tt.wantPlaced)
+			}
+			if got := result.Partial(); got != tt.wantPartial {
+				t.Errorf("Partial = %t, want %t", got, tt.wantPartial)
+			}
+
+			// Placement stops at the rejected child
+			wantSent := 3
+			if tt.reject > 0 {
+				wantSent = tt.reject - 1
+			}
+			placed := placedOrders(t, mock)
+			if len(placed) != wantSent {
+				t.Errorf("%d children accepted, want %d", len(placed), wantSent)
+			}
+			for _, o := range placed {
+				if o.TransactionType != tiqs.TransactionBuy {
+					t.Errorf("exit of a short sent as %s", o.TransactionType)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package tiqs
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Default settings of GetAccountSnapshot.
+const (
+	DefaultSnapshotAttempts   = 3
+	DefaultSnapshotRetryDelay = 250 * time.Millisecond
+)
+
+// AccountSnapshot holds the limits, positions, holdings and orders of the account as of
+// a single point in time.
+type AccountSnapshot struct {
+	AsOf       time.Time        `json:"asOf"`       // Time at which every endpoint was requested.
+	Duration   time.Duration    `json:"duration"`   // Time from the requests to the last response.
+	Attempts   int              `json:"attempts"`   // Number of attempts needed.
+	Consistent bool             `json:"consistent"` // Whether no order changed while the snapshot was taken.
+	Limits     *Limits          `json:"limits"`     // Trading limits and margins.
+	Positions  []Position       `json:"positions"`  // Open and carry-forward positions.
+	Holdings   []Holding        `json:"holdings"`   // Long-term holdings.
+	Orders     []OrderBookEntry `json:"orders"`     // Orders of the trading day.
+}
+
+// SnapshotOptions controls how GetAccountSnapshotWith retries.
+type SnapshotOptions struct {
+	MaxAttempts int           // Attempts before giving up; zero for DefaultSnapshotAttempts.
+	RetryDelay  time.Duration // Delay between attempts; zero for DefaultSnapshotRetryDelay.
+}
+
+// GetAccountSnapshot fetches limits, positions, holdings and the order book as one
+// coherent snapshot, using the default retry settings.
+//
+// See GetAccountSnapshotWith for how consistency is ensured.
+//
+// Returns:
+//   - A pointer to an AccountSnapshot if successful.
+//   - An error if an endpoint keeps failing.
+func (c *Client) GetAccountSnapshot() (*AccountSnapshot, error) {
+	return c.GetAccountSnapshotWith(SnapshotOptions{})
+}
+
+// GetAccountSnapshotWith fetches limits, positions, holdings and the order book as one
+// coherent snapshot.
+//
+// The four endpoints are requested concurrently, so they observe the account within the
+// same few hundred milliseconds rather than seconds apart. The order book is then read a
+// second time: if any order changed status or fill quantity in between, a fill may be
+// reflected in some of the responses but not in others, and the whole snapshot is taken
+// again. Failed requests are retried as well, unless the session is unauthorized. When
+// the account keeps changing, the last snapshot is returned with Consistent set to false.
+//
+// Parameters:
+//   - opts: The retry settings.
+//
+// Returns:
+//   - A pointer to an AccountSnapshot if successful.
+//   - An error if an endpoint keeps failing.
+func (c *Client) GetAccountSnapshotWith(opts SnapshotOptions) (*AccountSnapshot, error) {
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = DefaultSnapshotAttempts
+	}
+	if opts.RetryDelay <= 0 {
+		opts.RetryDelay = DefaultSnapshotRetryDelay
+	}
+
+	var (
+		snapshot *AccountSnapshot
+		err      error
+	)
+	for attempt := 1; attempt <= opts.MaxAttempts; attempt++ {
+		if attempt > 1 {
+			time.Sleep(opts.RetryDelay)
+		}
+
+		snapshot, err = c.fetchSnapshot()
+		if err != nil {
+			if errors.Is(err, ErrUnauthorized) {
+				return nil, err
+			}
+			c.log().Warn().Err(err).Int("attempt", attempt).Msg("Account snapshot failed")
+			continue
+		}
+
+		snapshot.Attempts = attempt
+		if snapshot.Consistent {
+			c.log().Info().Int("attempts", attempt).Dur("duration", snapshot.Duration).Msg("Account snapshot retrieved successfully")
+			return snapshot, nil
+		}
+		c.log().Warn().Int("attempt", attempt).Msg("Orders changed while taking account snapshot")
+	}
+
+	if snapshot == nil {
+		return nil, fmt.Errorf("account snapshot failed after %d attempts: %w", opts.MaxAttempts, err)
+	}
+	c.log().Warn().Int("attempts", snapshot.Attempts).Msg("Returning account snapshot taken while orders were changing")
+	return snapshot, nil
+}
+
+// fetchSnapshot requests every endpoint concurrently and checks the order book for changes.
+func (c *Client) fetchSnapshot() (*AccountSnapshot, error) {
+	var (
+		wg                    sync.WaitGroup
+		limits                *Limits
+		positions             []Position
+		holdings              []Holding
+		rows                  []OrderDetail
+		errLimits, errPos     error
+		errHoldings, errOrder error
+	)
+
+	asOf := time.Now()
+	wg.Add(4)
+	go func() { defer wg.Done(); limits, errLimits = c.GetLimits() }()
+	go func() { defer wg.Done(); positions, errPos = c.GetPositions() }()
+	go func() { defer wg.Done(); holdings, errHoldings = c.GetHoldings() }()
+	go func() { defer wg.Done(); rows, errOrder = c.getOrderRows() }()
+	wg.Wait()
+
+	if err := errors.Join(errLimits, errPos, errHoldings, errOrder); err != nil {
+		return nil, err
+	}
+
+	check, err := c.getOrderRows()
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &AccountSnapshot{
+		AsOf:       asOf,
+		Duration:   time.Since(asOf),
+		Consistent: sameOrderProgress(rows, check),
+		Limits:     limits,
+		Positions:  positions,
+		Holdings:   holdings,
+		Orders:     make([]OrderBookEntry, len(rows)),
+	}
+	for i, row := range rows {
+		snapshot.Orders[i] = NewOrderBookEntry(row)
+	}
+	return snapshot, nil
+}
+
+// sameOrderProgress reports whether two reads of the order book hold the same orders
+// with the same statuses and fill quantities.
+func sameOrderProgress(before, after []OrderDetail) bool {
+	if len(before) != len(after) {
+		return false
+	}
+
+	type progress struct{ status, filled string }
+	seen := make(map[string]progress, len(before))
+	for _, o := range before {
+		seen[o.ID] = progress{o.Status, o.FillShares}
+	}
+	for _, o := range after {
+		if p, ok := seen[o.ID]; !ok || p != (progress{o.Status, o.FillShares}) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,291 @@
+package tiqs
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"io"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Abhi13027/go-tiqs/ticks"
+)
+
+const (
+	// DefaultSoakInterval is the delay between two samples of a SoakMonitor.
+	DefaultSoakInterval = time.Minute
+	// DefaultSoakWindow is the number of samples a metric must grow over to be flagged.
+	DefaultSoakWindow = 30
+	// DefaultSoakMinGrowth is the relative growth over the window required to flag a metric.
+	DefaultSoakMinGrowth = 0.05
+)
+
+// SoakSample is the value of every watched metric at one point in time.
+type SoakSample struct {
+	Time    time.Time        `json:"time"`    // When the sample was taken.
+	Metrics map[string]int64 `json:"metrics"` // Value of each metric, keyed by name.
+}
+
+// LeakSuspect is a metric that grew at every sample of the window.
+type LeakSuspect struct {
+	Metric string    `json:"metric"` // Name of the metric.
+	From   int64     `json:"from"`   // Value at the start of the window.
+	To     int64     `json:"to"`     // Value at the end of the window.
+	Since  time.Time `json:"since"`  // Time of the first sample of the window.
+}
+
+// SoakMonitor samples goroutines, memory, channel depths and map sizes at a fixed interval
+// over long sessions, and flags the metrics that never stop growing, which usually points
+// to a leak, e.g., a goroutine per reconnect or a map keyed by order number.
+//
+// The runtime metrics (goroutines, heap_alloc, heap_objects) are always watched; add the
+// websocket, the client guards and application state with the Watch methods and Gauge.
+type SoakMonitor struct {
+	Interval  time.Duration     // Delay between samples; zero uses DefaultSoakInterval.
+	Window    int               // Samples a metric must grow over to be flagged; zero uses DefaultSoakWindow.
+	MinGrowth float64           // Relative growth over the window required to flag a metric.
+	Export    io.Writer         // Optional destination of every sample, as JSON lines.
+	OnSample  func(SoakSample)  // Optional callback for every sample.
+	OnLeak    func(LeakSuspect) // Optional callback when a metric is first flagged.
+
+	mu       sync.Mutex
+	gauges   map[string]func() int64
+	history  map[string][]int64
+	times    []time.Time
+	suspects map[string]LeakSuspect
+}
+
+// NewSoakMonitor creates a monitor watching the Go runtime.
+//
+// Parameters:
+//   - interval: The delay between samples; zero uses DefaultSoakInterval.
+//
+// Returns:
+//   - A pointer to a newly created SoakMonitor.
+func NewSoakMonitor(interval time.Duration) *SoakMonitor {
+	m := &SoakMonitor{
+		Interval:  interval,
+		Window:    DefaultSoakWindow,
+		MinGrowth: DefaultSoakMinGrowth,
+		gauges:    make(map[string]func() int64),
+		history:   make(map[string][]int64),
+		suspects:  make(map[string]LeakSuspect),
+	}
+	m.watchRuntime()
+	return m
+}
+
+// Gauge adds a metric to the monitor, replacing any metric with the same name.
+//
+// Parameters:
+//   - name: The name of the metric in samples and suspects.
+//   - read: Returns the current value; it is called from the monitor's goroutine and must
+//     be safe for concurrent use.
+func (m *SoakMonitor) Gauge(name string, read func() int64) {
+	m.mu.Lock()
+	m.gauges[name] = read
+	delete(m.history, name)
+	delete(m.suspects, name)
+	m.mu.Unlock()
+}
+
+// watchRuntime adds the goroutine count and heap metrics.
+func (m *SoakMonitor) watchRuntime() {
+	var (
+		mu    sync.Mutex
+		stats runtime.MemStats
+		read  time.Time
+	)
+	// ReadMemStats stops the world, so read it once per sample for both heap metrics.
+	mem := func() runtime.MemStats {
+		mu.Lock()
+		defer mu.Unlock()
+		if time.Since(read) > time.Second {
+			runtime.ReadMemStats(&stats)
+			read = time.Now()
+		}
+		return stats
+	}
+
+	m.gauges["goroutines"] = func() int64 { return int64(runtime.NumGoroutine()) }
+	m.gauges["heap_alloc"] = func() int64 { return int64(mem().HeapAlloc) }
+	m.gauges["heap_objects"] = func() int64 { return int64(mem().HeapObjects) }
+}
+
+// WatchWS adds the channel depths and subscription state of a websocket, prefixed with
+// name (e.g., "ws").
+func (m *SoakMonitor) WatchWS(name string, ws *ticks.WS) {
+	m.Gauge(name+".data_chan", func() int64 { return int64(ws.Diagnostics().DataChanLen) })
+	m.Gauge(name+".err_chan", func() int64 { return int64(ws.Diagnostics().ErrChanLen) })
+	m.Gauge(name+".subscriptions", func() int64 { return int64(ws.Diagnostics().Subscriptions) })
+	m.Gauge(name+".token_list", func() int64 { return int64(ws.Diagnostics().TokenList) })
+	m.Gauge(name+".handlers", func() int64 { return int64(ws.Diagnostics().Handlers) })
+	m.Gauge(name+".token_channels", func() int64 { return int64(ws.Diagnostics().TokenChannels) })
+}
+
+// WatchClient adds the map sizes of the guards attached to a client.
+func (m *SoakMonitor) WatchClient(c *Client) {
+	if g := c.staleGuard; g != nil {
+		m.Gauge("stale_guard.tokens", func() int64 {
+			g.mu.RLock()
+			defer g.mu.RUnlock()
+			return int64(len(g.lastSeen))
+		})
+	}
+	if g := c.duplicates; g != nil {
+		m.Gauge("duplicate_guard.orders", func() int64 {
+			g.mu.Lock()
+			defer g.mu.Unlock()
+			return int64(len(g.recent))
+		})
+	}
+}
+
+// WatchPositionEngine adds the map sizes and channel depths of a position engine.
+func (m *SoakMonitor) WatchPositionEngine(e *PositionEngine) {
+	size := func(f func() int) func() int64 {
+		return func() int64 {
+			e.mu.RLock()
+			defer e.mu.RUnlock()
+			return int64(f())
+		}
+	}
+	m.Gauge("positions.positions", size(func() int { return len(e.positions) }))
+	m.Gauge("positions.orders", size(func() int { return len(e.orders) }))
+	m.Gauge("positions.fills", size(func() int { return len(e.fills) }))
+	m.Gauge("positions.updates", func() int64 { return int64(len(e.updates)) })
+	m.Gauge("positions.divergences", func() int64 { return int64(len(e.divergences)) })
+}
+
+// Run samples every Interval until the context is cancelled.
+//
+// Parameters:
+//   - ctx: Context whose cancellation stops the monitor.
+//
+// Returns:
+//   - The context's error once it is cancelled.
+func (m *SoakMonitor) Run(ctx context.Context) error {
+	ticker := time.NewTicker(cmp.Or(m.Interval, DefaultSoakInterval))
+	defer ticker.Stop()
+
+	m.Sample()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			m.Sample()
+		}
+	}
+}
+
+// Sample reads every metric once, exports and checks the sample, and returns it.
+func (m *SoakMonitor) Sample() SoakSample {
+	m.mu.Lock()
+	gauges := make(map[string]func() int64, len(m.gauges))
+	for name, read := range m.gauges {
+		gauges[name] = read
+	}
+	m.mu.Unlock()
+
+	sample := SoakSample{Time: time.Now(), Metrics: make(map[string]int64, len(gauges))}
+	for name, read := range gauges {
+		sample.Metrics[name] = read()
+	}
+
+	leaks := m.record(sample)
+
+	event := defaultLog().Debug()
+	for name, v := range sample.Metrics {
+		event = event.Int64(name, v)
+	}
+	event.Msg("Soak sample")
+
+	if m.Export != nil {
+		if err := json.NewEncoder(m.Export).Encode(sample); err != nil {
+			defaultLog().Error().Err(err).Msg("Failed to export soak sample")
+		}
+	}
+	if m.OnSample != nil {
+		m.OnSample(sample)
+	}
+	for _, leak := range leaks {
+		defaultLog().Warn().
+			Str("metric", leak.Metric).
+			Int64("from", leak.From).
+			Int64("to", leak.To).
+			Time("since", leak.Since).
+			Msg("Possible leak: metric grew at every sample")
+		if m.OnLeak != nil {
+			m.OnLeak(leak)
+		}
+	}
+	return sample
+}
+
+// Suspects returns the metrics currently flagged, sorted by name.
+func (m *SoakMonitor) Suspects() []LeakSuspect {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	suspects := make([]LeakSuspect, 0, len(m.suspects))
+	for _, s := range m.suspects {
+		suspects = append(suspects, s)
+	}
+	sort.Slice(suspects, func(i, j int) bool { return suspects[i].Metric < suspects[j].Metric })
+	return suspects
+}
+
+// record appends a sample to the history and returns the metrics newly flagged. A metric
+// is flagged when it never decreased over the window and grew by at least MinGrowth, and
+// cleared as soon as it decreases.
+func (m *SoakMonitor) record(sample SoakSample) []LeakSuspect {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	window := max(cmp.Or(m.Window, DefaultSoakWindow), 2)
+	m.times = append(m.times, sample.Time)
+	if len(m.times) > window {
+		m.times = m.times[len(m.times)-window:]
+	}
+
+	var leaks []LeakSuspect
+	for name, v := range sample.Metrics {
+		h := append(m.history[name], v)
+		if len(h) > window {
+			h = h[len(h)-window:]
+		}
+		m.history[name] = h
+
+		if len(h) >= 2 && h[len(h)-1] < h[len(h)-2] {
+			delete(m.suspects, name)
+			continue
+		}
+		if len(h) < window || !growing(h, m.MinGrowth) {
+			continue
+		}
+		if _, flagged := m.suspects[name]; flagged {
+			continue
+		}
+		leak := LeakSuspect{Metric: name, From: h[0], To: h[len(h)-1], Since: m.times[len(m.times)-len(h)]}
+		m.suspects[name] = leak
+		leaks = append(leaks, leak)
+	}
+	return leaks
+}
+
+// growing reports whether a series never decreased and grew by at least minGrowth.
+func growing(h []int64, minGrowth float64) bool {
+	for i := 1; i < len(h); i++ {
+		if h[i] < h[i-1] {
+			return false
+		}
+	}
+	first, last := h[0], h[len(h)-1]
+	if last <= first {
+		return false
+	}
+	return first <= 0 || float64(last-first)/float64(first) >= minGrowth
+}
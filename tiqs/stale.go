@@ -0,0 +1,127 @@
+package tiqs
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Abhi13027/go-tiqs/ticks"
+)
+
+// StaleGuard tracks the last trade time of quotes and ticks and flags prices
+// whose last trade is older than MaxAge while the market is open.
+//
+// When BlockOrders is enabled and the guard is attached to a Client via SetStaleGuard,
+// PlaceOrder refuses orders on tokens whose price is stale.
+type StaleGuard struct {
+	MaxAge      time.Duration    // Maximum age of the last trade before a price is considered stale.
+	Clock       *MarketClock     // Market clock; prices are never stale while the market is closed.
+	BlockOrders bool             // Whether PlaceOrder should reject orders on stale tokens.
+	Now         func() time.Time // Time source, defaults to time.Now.
+
+	mu       sync.RWMutex
+	lastSeen map[int64]time.Time
+}
+
+// NewStaleGuard creates a guard that flags prices older than maxAge during regular market hours.
+//
+// Parameters:
+//   - maxAge: The maximum allowed age of the last trade.
+//
+// Returns:
+//   - A pointer to a newly created StaleGuard.
+func NewStaleGuard(maxAge time.Duration) *StaleGuard {
+	return &StaleGuard{
+		MaxAge:   maxAge,
+		Clock:    NewMarketClock(),
+		Now:      time.Now,
+		lastSeen: make(map[int64]time.Time),
+	}
+}
+
+// Observe records the last trade time of a token.
+//
+// Parameters:
+//   - token: The unique identifier of the instrument.
+//   - ltt: The last trade time reported by the exchange.
+func (g *StaleGuard) Observe(token int64, ltt time.Time) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if prev, ok := g.lastSeen[token]; !ok || ltt.After(prev) {
+		g.lastSeen[token] = ltt
+	}
+}
+
+// ObserveQuote records the last trade time carried by a market quote.
+func (g *StaleGuard) ObserveQuote(quote MarketQuote) {
+	if quote.LTT > 0 {
+		g.Observe(quote.Token, time.Unix(quote.LTT, 0))
+	}
+}
+
+// ObserveTick records the last trade time carried by a websocket tick.
+// Heartbeat ticks and ticks without a last trade time are ignored.
+func (g *StaleGuard) ObserveTick(tick ticks.TickData) {
+	if tick.Token >= 0 && tick.LTT > 0 {
+		g.Observe(int64(tick.Token), time.Unix(int64(tick.LTT), 0))
+	}
+}
+
+// IsStale reports whether the price of a token is stale.
+//
+// Tokens that have never been observed are not considered stale, and no price is
+// considered stale while the market is closed.
+//
+// Parameters:
+//   - token: The unique identifier of the instrument.
+//
+// Returns:
+//   - true if the last trade is older than MaxAge during market hours; otherwise, false.
+func (g *StaleGuard) IsStale(token int64) bool {
+	g.mu.RLock()
+	ltt, ok := g.lastSeen[token]
+	g.mu.RUnlock()
+	if !ok {
+		return false
+	}
+
+	now := g.now()
+	if g.Clock != nil && !g.Clock.IsOpen(now) {
+		return false
+	}
+	return now.Sub(ltt) > g.MaxAge
+}
+
+// Age returns how long ago the last trade of a token happened.
+//
+// Returns:
+//   - The age of the last trade and true if the token has been observed; otherwise, zero and false.
+func (g *StaleGuard) Age(token int64) (time.Duration, bool) {
+	g.mu.RLock()
+	ltt, ok := g.lastSeen[token]
+	g.mu.RUnlock()
+	if !ok {
+		return 0, false
+	}
+	return g.now().Sub(ltt), true
+}
+
+// check returns an error if orders on the token must be blocked because its price is stale.
+func (g *StaleGuard) check(token int64) error {
+	if !g.BlockOrders || !g.IsStale(token) {
+		return nil
+	}
+
+	age, _ := g.Age(token)
+	defaultLog().Warn().Int64("token", token).Dur("age", age).Msg("Order blocked due to stale price")
+	return fmt.Errorf("order blocked: price for token %d is stale (last trade %s ago)", token, age.Truncate(time.Second))
+}
+
+// now returns the current time from the configured time source.
+func (g *StaleGuard) now() time.Time {
+	if g.Now != nil {
+		return g.Now()
+	}
+	return time.Now()
+}
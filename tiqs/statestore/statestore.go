@@ -0,0 +1,237 @@
+// Package statestore persists strategy state in an embedded bbolt file.
+//
+// It is kept out of the tiqs package so that only programs importing it link bbolt;
+// integrations with dependencies of their own live in subpackages like this one.
+package statestore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Store is an embedded key-value store persisting strategy state, such as entry
+// prices, flags and cooldowns, across restarts.
+//
+// State is namespaced per strategy tag (the Tags value a strategy places its orders with),
+// so strategies sharing a process cannot overwrite each other's keys. Values are stored
+// as JSON in a single bbolt file; every write is durable once it returns.
+type Store struct {
+	db *bolt.DB
+}
+
+// StrategyState is the view of a Store restricted to one strategy tag.
+type StrategyState struct {
+	store *Store
+	tag   string
+}
+
+// Tx is a read-write transaction on the state of one strategy.
+type Tx struct {
+	bucket *bolt.Bucket
+}
+
+// stateEntry is the stored form of a value.
+type stateEntry struct {
+	Value     json.RawMessage `json:"value"`
+	ExpiresAt *time.Time      `json:"expiresAt,omitempty"`
+}
+
+// Open opens or creates the state file at path.
+//
+// Only one process can open a file at a time; Open waits at most a second for
+// another process to release it.
+//
+// Parameters:
+//   - path: The path of the state file.
+//
+// Returns:
+//   - A pointer to the opened Store.
+//   - An error if the file cannot be opened.
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("error opening state store: %w", err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes the state file.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Strategy returns the state of the strategy with the given tag.
+//
+// Parameters:
+//   - tag: The strategy tag; must not be empty.
+//
+// Returns:
+//   - A StrategyState bound to the tag.
+func (s *Store) Strategy(tag string) *StrategyState {
+	return &StrategyState{store: s, tag: tag}
+}
+
+// Strategies returns the tags of every strategy with stored state.
+func (s *Store) Strategies() ([]string, error) {
+	var tags []string
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(name []byte, _ *bolt.Bucket) error {
+			tags = append(tags, string(name))
+			return nil
+		})
+	})
+	return tags, err
+}
+
+// Get decodes the value stored under key into v.
+//
+// Returns:
+//   - true if the key exists and has not expired; otherwise, false.
+//   - An error if the store cannot be read or the value cannot be decoded into v.
+func (s *StrategyState) Get(key string, v any) (bool, error) {
+	var found bool
+	err := s.store.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(s.tag))
+		if bucket == nil {
+			return nil
+		}
+		var err error
+		found, err = (&Tx{bucket: bucket}).Get(key, v)
+		return err
+	})
+	return found, err
+}
+
+// Put stores v under key.
+func (s *StrategyState) Put(key string, v any) error {
+	return s.Update(func(tx *Tx) error { return tx.Put(key, v) })
+}
+
+// PutFor stores v under key for ttl, after which the key reads as missing, e.g., for cooldowns.
+func (s *StrategyState) PutFor(key string, v any, ttl time.Duration) error {
+	return s.Update(func(tx *Tx) error { return tx.PutFor(key, v, ttl) })
+}
+
+// Delete removes key.
+func (s *StrategyState) Delete(key string) error {
+	return s.Update(func(tx *Tx) error { return tx.Delete(key) })
+}
+
+// Keys returns the keys of the strategy that have not expired.
+func (s *StrategyState) Keys() ([]string, error) {
+	var keys []string
+	err := s.store.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(s.tag))
+		if bucket == nil {
+			return nil
+		}
+		now := time.Now()
+		return bucket.ForEach(func(k, raw []byte) error {
+			var entry stateEntry
+			if err := json.Unmarshal(raw, &entry); err != nil {
+				return fmt.Errorf("error decoding state %q: %w", k, err)
+			}
+			if !entry.expired(now) {
+				keys = append(keys, string(k))
+			}
+			return nil
+		})
+	})
+	return keys, err
+}
+
+// Clear removes every key of the strategy.
+func (s *StrategyState) Clear() error {
+	return s.store.db.Update(func(tx *bolt.Tx) error {
+		err := tx.DeleteBucket([]byte(s.tag))
+		if errors.Is(err, bolt.ErrBucketNotFound) {
+			return nil
+		}
+		return err
+	})
+}
+
+// Update runs fn in a read-write transaction, so that several keys are read and written
+// atomically. If fn returns an error, none of its writes are applied.
+//
+// Parameters:
+//   - fn: The function reading and writing state through the transaction.
+//
+// Returns:
+//   - The error returned by fn, or an error if the transaction cannot be committed.
+func (s *StrategyState) Update(fn func(tx *Tx) error) error {
+	if s.tag == "" {
+		return fmt.Errorf("strategy tag is required")
+	}
+	return s.store.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte(s.tag))
+		if err != nil {
+			return fmt.Errorf("error creating state bucket: %w", err)
+		}
+		return fn(&Tx{bucket: bucket})
+	})
+}
+
+// Get decodes the value stored under key into v.
+//
+// Returns:
+//   - true if the key exists and has not expired; otherwise, false.
+//   - An error if the value cannot be decoded into v.
+func (t *Tx) Get(key string, v any) (bool, error) {
+	raw := t.bucket.Get([]byte(key))
+	if raw == nil {
+		return false, nil
+	}
+	var entry stateEntry
+	if err := json.Unmarshal(raw, &entry); err != nil {
+		return false, fmt.Errorf("error decoding state %q: %w", key, err)
+	}
+	if entry.expired(time.Now()) {
+		return false, nil
+	}
+	if err := json.Unmarshal(entry.Value, v); err != nil {
+		return false, fmt.Errorf("error decoding state %q: %w", key, err)
+	}
+	return true, nil
+}
+
+// Put stores v under key.
+func (t *Tx) Put(key string, v any) error {
+	return t.put(key, v, nil)
+}
+
+// PutFor stores v under key for ttl.
+func (t *Tx) PutFor(key string, v any, ttl time.Duration) error {
+	expiresAt := time.Now().Add(ttl)
+	return t.put(key, v, &expiresAt)
+}
+
+// Delete removes key.
+func (t *Tx) Delete(key string) error {
+	return t.bucket.Delete([]byte(key))
+}
+
+// put encodes and stores a value with an optional expiry.
+func (t *Tx) put(key string, v any, expiresAt *time.Time) error {
+	if key == "" {
+		return fmt.Errorf("state key is required")
+	}
+	value, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("error encoding state %q: %w", key, err)
+	}
+	raw, err := json.Marshal(stateEntry{Value: value, ExpiresAt: expiresAt})
+	if err != nil {
+		return fmt.Errorf("error encoding state %q: %w", key, err)
+	}
+	return t.bucket.Put([]byte(key), raw)
+}
+
+// expired reports whether the entry has an expiry at or before now.
+func (e stateEntry) expired(now time.Time) bool {
+	return e.ExpiresAt != nil && !now.Before(*e.ExpiresAt)
+}
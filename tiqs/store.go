@@ -0,0 +1,197 @@
+package tiqs
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// InstrumentStore is an in-memory index of the instrument master.
+//
+// Instruments are indexed by token, by exchange and trading symbol and by underlying, and
+// can be searched by name (see Search) or filtered by contract details (see Filter).
+//
+// Unless IncludeExpired is set, expired derivative contracts are pruned whenever the
+// store is refreshed through the client, so memory usage stays stable across months of
+// uptime as new series are listed.
+type InstrumentStore struct {
+	IncludeExpired bool // Whether expired derivatives are kept on refresh, e.g., for research.
+
+	mu           sync.RWMutex
+	byToken      map[int64]Instrument
+	bySymbol     map[string]int64   // Tokens keyed by symbolKey(exchange, trading symbol).
+	byUnderlying map[string][]int64 // Tokens of derivatives keyed by upper-case underlying symbol.
+	names        []searchEntry      // Lower-case names scanned by Search.
+}
+
+// NewInstrumentStore builds an instrument store from the given instruments.
+//
+// Parameters:
+//   - instruments: The instrument master rows (e.g., from GetInstrumentList).
+//
+// Returns:
+//   - A pointer to the newly created InstrumentStore.
+func NewInstrumentStore(instruments []Instrument) *InstrumentStore {
+	store := &InstrumentStore{}
+	store.Replace(instruments)
+	return store
+}
+
+// Replace swaps the contents of the store with the given instruments.
+//
+// Parameters:
+//   - instruments: The new instrument master rows.
+func (s *InstrumentStore) Replace(instruments []Instrument) {
+	byToken := make(map[int64]Instrument, len(instruments))
+	for _, inst := range instruments {
+		byToken[inst.Token] = inst
+	}
+
+	s.mu.Lock()
+	s.byToken = byToken
+	s.indexLocked()
+	s.mu.Unlock()
+}
+
+// Get returns the instrument with the given token.
+//
+// Parameters:
+//   - token: The unique identifier of the instrument.
+//
+// Returns:
+//   - The instrument and true if found; otherwise, a zero Instrument and false.
+func (s *InstrumentStore) Get(token int64) (Instrument, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	inst, ok := s.byToken[token]
+	return inst, ok
+}
+
+// Len returns the number of instruments in the store.
+func (s *InstrumentStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.byToken)
+}
+
+// Prune removes derivative contracts that expired before the trading day of now.
+//
+// Parameters:
+//   - now: The current time.
+//
+// Returns:
+//   - The number of instruments removed.
+func (s *InstrumentStore) Prune(now time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := 0
+	for token, inst := range s.byToken {
+		if inst.Expired(now) {
+			delete(s.byToken, token)
+			removed++
+		}
+	}
+	if removed > 0 {
+		s.indexLocked()
+	}
+	return removed
+}
+
+// LoadInstruments fetches the instrument master and attaches it to the client as its instrument store.
+//
+// Features that need instrument metadata, such as decimal price conversion, use the
+// attached store automatically.
+//
+// Parameters:
+//   - includeExpired: If true, expired derivatives are kept in the store; otherwise, they are pruned.
+//
+// Returns:
+//   - A pointer to the loaded InstrumentStore if successful.
+//   - An error if the instrument list cannot be retrieved.
+func (c *Client) LoadInstruments(includeExpired bool) (*InstrumentStore, error) {
+	store := &InstrumentStore{IncludeExpired: includeExpired}
+	if err := c.refreshStore(store); err != nil {
+		return nil, err
+	}
+
+	c.SetInstrumentStore(store)
+	return store, nil
+}
+
+// RefreshInstruments reloads the instrument master into the attached instrument store.
+//
+// Returns:
+//   - An error if no instrument store is attached or the instrument list cannot be retrieved.
+func (c *Client) RefreshInstruments() error {
+	if c.instruments == nil {
+		return fmt.Errorf("no instrument store attached")
+	}
+	return c.refreshStore(c.instruments)
+}
+
+// RefreshInstrumentsDaily refreshes the attached instrument store every day at the given
+// time of day (IST) until the context is cancelled. Failed refreshes are logged and the
+// previous contents are kept until the next day.
+//
+// Parameters:
+//   - ctx: Context controlling the refresh loop.
+//   - at: Time of day of the refresh as an offset from midnight (e.g., 8h30m).
+//
+// Returns:
+//   - The context error once the context is cancelled.
+func (c *Client) RefreshInstrumentsDaily(ctx context.Context, at time.Duration) error {
+	for {
+		now := time.Now().In(IST)
+		next := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, IST).Add(at)
+		if !next.After(now) {
+			next = next.AddDate(0, 0, 1)
+		}
+
+		timer := time.NewTimer(next.Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		if err := c.RefreshInstruments(); err != nil {
+			c.log().Error().Err(err).Msg("Daily instrument refresh failed")
+		}
+	}
+}
+
+// refreshStore fetches the instrument master into store and prunes expired contracts
+// unless the store includes them.
+func (c *Client) refreshStore(store *InstrumentStore) error {
+	instruments, err := c.GetInstrumentList()
+	if err != nil {
+		return err
+	}
+
+	store.Replace(instruments)
+	pruned := 0
+	if !store.IncludeExpired {
+		pruned = store.Prune(time.Now())
+	}
+
+	c.log().Info().Int("instruments", store.Len()).Int("pruned", pruned).Msg("Instrument store loaded successfully")
+	return nil
+}
+
+// SetInstrumentStore attaches an instrument store to the client.
+//
+// Parameters:
+//   - store: The instrument store to use, or nil to detach the current one.
+func (c *Client) SetInstrumentStore(store *InstrumentStore) {
+	c.instruments = store
+}
+
+// InstrumentStore returns the instrument store attached to the client, if any.
+func (c *Client) InstrumentStore() *InstrumentStore {
+	return c.instruments
+}
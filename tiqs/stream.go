@@ -0,0 +1,149 @@
+package tiqs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/valyala/fasthttp"
+)
+
+// DefaultMaxResponseSize is the size limit applied to streamed responses when
+// Config.MaxResponseSize is not set.
+const DefaultMaxResponseSize = 256 << 20
+
+// progressInterval is the number of bytes between two progress callbacks.
+const progressInterval = 1 << 20
+
+// ErrResponseTooLarge is returned when a streamed response exceeds the configured size limit.
+var ErrResponseTooLarge = errors.New("response body exceeds size limit")
+
+// ProgressFunc is called while a large response is downloaded.
+//
+// Parameters:
+//   - endpoint: The API endpoint being downloaded.
+//   - read: The number of bytes read so far.
+//   - total: The size announced by the server, or -1 if unknown.
+type ProgressFunc func(endpoint string, read, total int64)
+
+// download sends a GET request and streams the response body to consume.
+//
+// Unlike request, the body is not buffered by fasthttp: it is read incrementally, the
+// configured size limit is enforced while reading, and the progress callback set with
+// SetDownloadProgress is notified as data arrives. It is used for large responses such
+// as the instrument master and multi-day candles.
+//
+// Parameters:
+//   - endpoint: The API endpoint (relative to BaseURL) to download.
+//   - consume: Function reading the body; it must not retain the reader.
+//
+// Returns:
+//   - An error if the request fails, the body exceeds the size limit or consume fails.
+func (c *Client) download(endpoint string, consume func(io.Reader) error) error {
+	url := c.Config.BaseURL + endpoint
+	c.log().Info().Str("url", url).Msg("Downloading")
+
+	if err := c.waitRate("GET", endpoint); err != nil {
+		return err
+	}
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	req.SetRequestURI(url)
+	req.Header.SetMethod("GET")
+	req.Header.Set("appId", c.Config.AppID)
+	req.Header.Set("token", c.Config.Token)
+	c.signRequest(req, endpoint, nil)
+
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+	resp.StreamBody = true
+
+	if err := c.HTTPClient.Do(req, resp); err != nil {
+		c.log().Error().Err(err).Msg("API request failed")
+		c.recordHealth(err)
+		return err
+	}
+	defer resp.CloseBodyStream()
+
+	if status := resp.StatusCode(); status >= fasthttp.StatusInternalServerError {
+		c.recordHealth(fmt.Errorf("server error: HTTP %d", status))
+	} else {
+		c.recordHealth(nil)
+	}
+
+	if status := resp.StatusCode(); status >= fasthttp.StatusBadRequest {
+		if status == fasthttp.StatusTooManyRequests {
+			c.rateLimited("GET", endpoint)
+		}
+		body, _ := io.ReadAll(io.LimitReader(resp.BodyStream(), 4096))
+		return newAPIError("GET "+endpoint, endpoint, status, body)
+	}
+
+	limit := c.Config.MaxResponseSize
+	if limit <= 0 {
+		limit = DefaultMaxResponseSize
+	}
+
+	total := int64(resp.Header.ContentLength())
+	if total < 0 {
+		total = -1
+	}
+	if total > limit {
+		return fmt.Errorf("%w: %d bytes announced, limit is %d", ErrResponseTooLarge, total, limit)
+	}
+
+	body := resp.BodyStream()
+	if body == nil {
+		body = bytes.NewReader(resp.Body())
+	}
+
+	reader := &progressReader{
+		r:        body,
+		endpoint: endpoint,
+		total:    total,
+		limit:    limit,
+		progress: c.progress,
+	}
+	if err := consume(reader); err != nil {
+		return err
+	}
+
+	reader.report()
+	c.log().Info().Str("url", url).Int64("bytes", reader.read).Msg("Download completed")
+	return nil
+}
+
+// progressReader enforces a size limit on a body and reports download progress.
+type progressReader struct {
+	r        io.Reader
+	endpoint string
+	total    int64
+	limit    int64
+	read     int64
+	reported int64
+	progress ProgressFunc
+}
+
+// Read implements io.Reader.
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.read += int64(n)
+
+	if p.read > p.limit {
+		return n, fmt.Errorf("%w: more than %d bytes", ErrResponseTooLarge, p.limit)
+	}
+	if p.read-p.reported >= progressInterval {
+		p.report()
+	}
+	return n, err
+}
+
+// report notifies the progress callback of the bytes read so far.
+func (p *progressReader) report() {
+	p.reported = p.read
+	if p.progress != nil {
+		p.progress(p.endpoint, p.read, p.total)
+	}
+}
@@ -0,0 +1,145 @@
+package tiqs
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// BlockedOrder is the audit record of an order refused by a SymbolControl.
+type BlockedOrder struct {
+	Symbol          string          `json:"symbol"`          // Trading symbol of the order.
+	Underlying      string          `json:"underlying"`      // Underlying the rule matched on.
+	Token           string          `json:"token"`           // Unique identifier for the instrument.
+	TransactionType TransactionType `json:"transactionType"` // Side of the order.
+	Quantity        string          `json:"quantity"`        // Order quantity.
+	Reason          string          `json:"reason"`          // Why the symbol is blocked.
+	Time            time.Time       `json:"time"`            // Time of the attempt.
+}
+
+// SymbolControl is a runtime allow/deny list for new orders.
+//
+// Rules match on the underlying of the trading symbol, so denying "IDEA" blocks the
+// equity as well as its futures and options, as needed for the F&O ban list. When an
+// allow list is set, only its symbols may be traded; the deny list always takes
+// precedence. Lists can be changed at any time while strategies are running.
+//
+// Attach it to a Client with SetSymbolControl. Every refused order is logged and passed
+// to OnBlocked, if set.
+type SymbolControl struct {
+	OnBlocked func(BlockedOrder) // Optional audit hook called for every refused order.
+
+	mu      sync.RWMutex
+	denied  map[string]string
+	allowed map[string]bool
+}
+
+// NewSymbolControl creates a control with empty allow and deny lists.
+//
+// Returns:
+//   - A pointer to a newly created SymbolControl that allows every symbol.
+func NewSymbolControl() *SymbolControl {
+	return &SymbolControl{
+		denied:  make(map[string]string),
+		allowed: make(map[string]bool),
+	}
+}
+
+// Deny blocks new orders in a symbol.
+//
+// Parameters:
+//   - symbol: The trading symbol or underlying to block (e.g., "IDEA").
+//   - reason: Reason recorded in the audit log (e.g., "F&O ban").
+func (s *SymbolControl) Deny(symbol, reason string) {
+	s.mu.Lock()
+	s.denied[underlyingOf(symbol)] = reason
+	s.mu.Unlock()
+
+	defaultLog().Info().Str("symbol", symbol).Str("reason", reason).Msg("Symbol trading disabled")
+}
+
+// Undeny removes a symbol from the deny list.
+func (s *SymbolControl) Undeny(symbol string) {
+	s.mu.Lock()
+	delete(s.denied, underlyingOf(symbol))
+	s.mu.Unlock()
+
+	defaultLog().Info().Str("symbol", symbol).Msg("Symbol trading re-enabled")
+}
+
+// SetAllowList restricts trading to the given symbols. An empty list allows every symbol.
+func (s *SymbolControl) SetAllowList(symbols ...string) {
+	allowed := make(map[string]bool, len(symbols))
+	for _, symbol := range symbols {
+		allowed[underlyingOf(symbol)] = true
+	}
+
+	s.mu.Lock()
+	s.allowed = allowed
+	s.mu.Unlock()
+
+	defaultLog().Info().Int("symbols", len(allowed)).Msg("Symbol allow list updated")
+}
+
+// Denied returns the denied underlyings, sorted.
+func (s *SymbolControl) Denied() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	symbols := make([]string, 0, len(s.denied))
+	for symbol := range s.denied {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	return symbols
+}
+
+// IsAllowed reports whether new orders in a symbol are allowed.
+//
+// Returns:
+//   - true and an empty reason if allowed; otherwise, false and the reason.
+func (s *SymbolControl) IsAllowed(symbol string) (bool, string) {
+	underlying := underlyingOf(symbol)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if reason, ok := s.denied[underlying]; ok {
+		return false, reason
+	}
+	if len(s.allowed) > 0 && !s.allowed[underlying] {
+		return false, "not in allow list"
+	}
+	return true, ""
+}
+
+// check returns an error, and records an audit entry, if the order's symbol is blocked.
+func (s *SymbolControl) check(order OrderRequest) error {
+	ok, reason := s.IsAllowed(order.Symbol)
+	if ok {
+		return nil
+	}
+
+	blocked := BlockedOrder{
+		Symbol:          order.Symbol,
+		Underlying:      underlyingOf(order.Symbol),
+		Token:           order.Token,
+		TransactionType: order.TransactionType,
+		Quantity:        order.Quantity,
+		Reason:          reason,
+		Time:            time.Now(),
+	}
+	defaultLog().Warn().
+		Str("symbol", blocked.Symbol).
+		Str("token", blocked.Token).
+		Str("transactionType", string(blocked.TransactionType)).
+		Str("quantity", blocked.Quantity).
+		Str("reason", reason).
+		Msg("Order blocked by symbol control")
+	if s.OnBlocked != nil {
+		s.OnBlocked(blocked)
+	}
+
+	return fmt.Errorf("order blocked: trading in %s is disabled (%s)", blocked.Underlying, reason)
+}
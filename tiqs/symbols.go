@@ -0,0 +1,183 @@
+package tiqs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Abhi13027/go-tiqs/ticks"
+)
+
+// ParseSymbol splits a symbol of the form "EXCHANGE:TRADINGSYMBOL", e.g.,
+// "NSE:RELIANCE-EQ" or "NFO:NIFTY25MAY24000CE", into its exchange and trading symbol.
+//
+// Returns:
+//   - The exchange and trading symbol, upper-cased.
+//   - An error if either part is missing.
+func ParseSymbol(symbol string) (Exchange, string, error) {
+	exchange, tradingSymbol, ok := strings.Cut(strings.TrimSpace(symbol), ":")
+	exchange = strings.ToUpper(strings.TrimSpace(exchange))
+	tradingSymbol = strings.ToUpper(strings.TrimSpace(tradingSymbol))
+	if !ok || exchange == "" || tradingSymbol == "" {
+		return "", "", fmt.Errorf("invalid symbol %q: want EXCHANGE:TRADINGSYMBOL", symbol)
+	}
+	return Exchange(exchange), tradingSymbol, nil
+}
+
+// Resolve returns the instrument of a symbol of the form "EXCHANGE:TRADINGSYMBOL".
+//
+// Parameters:
+//   - symbol: The symbol, e.g., "NSE:RELIANCE-EQ", ignoring case.
+//
+// Returns:
+//   - The instrument if found.
+//   - An error if the symbol is malformed, or one matching ErrInvalidInstrument if the
+//     store has no such instrument.
+func (s *InstrumentStore) Resolve(symbol string) (Instrument, error) {
+	exchange, tradingSymbol, err := ParseSymbol(symbol)
+	if err != nil {
+		return Instrument{}, err
+	}
+	inst, ok := s.Lookup(string(exchange), tradingSymbol)
+	if !ok {
+		return Instrument{}, fmt.Errorf("%w: no instrument %s:%s", ErrInvalidInstrument, exchange, tradingSymbol)
+	}
+	return inst, nil
+}
+
+// ResolveSymbol returns the instrument of a symbol from the attached instrument store
+// (see LoadInstruments).
+//
+// Parameters:
+//   - symbol: The symbol, e.g., "NFO:NIFTY25MAY24000CE".
+//
+// Returns:
+//   - The instrument if found.
+//   - An error if no instrument store is attached or the symbol cannot be resolved.
+func (c *Client) ResolveSymbol(symbol string) (Instrument, error) {
+	if c.instruments == nil {
+		return Instrument{}, fmt.Errorf("no instrument store attached")
+	}
+	return c.instruments.Resolve(symbol)
+}
+
+// ResolveSymbols returns the instruments of symbols, in the order of symbols.
+//
+// Returns:
+//   - The instruments if every symbol resolves.
+//   - An error naming the first symbol that cannot be resolved.
+func (c *Client) ResolveSymbols(symbols []string) ([]Instrument, error) {
+	instruments := make([]Instrument, len(symbols))
+	for i, symbol := range symbols {
+		inst, err := c.ResolveSymbol(symbol)
+		if err != nil {
+			return nil, err
+		}
+		instruments[i] = inst
+	}
+	return instruments, nil
+}
+
+// PlaceOrderBySymbol places an order for the instrument of a symbol, filling the
+// exchange, token and trading symbol of the order from the attached instrument store.
+//
+// Parameters:
+//   - orderType: The order variety, as in PlaceOrder.
+//   - symbol: The symbol, e.g., "NSE:RELIANCE-EQ".
+//   - order: The order; its Exchange, Token and Symbol are overwritten.
+//
+// Returns:
+//   - A pointer to the OrderResponse if successful.
+//   - An error if the symbol cannot be resolved or the order fails as in PlaceOrder.
+func (c *Client) PlaceOrderBySymbol(orderType, symbol string, order OrderRequest) (*OrderResponse, error) {
+	inst, err := c.ResolveSymbol(symbol)
+	if err != nil {
+		return nil, err
+	}
+	order.Exchange = Exchange(strings.ToUpper(inst.Exchange))
+	order.Token = strconv.FormatInt(inst.Token, 10)
+	order.Symbol = inst.TradingSymbol
+	return c.PlaceOrder(orderType, order)
+}
+
+// GetMarketQuoteBySymbol fetches market data for the instrument of a symbol.
+//
+// Parameters:
+//   - symbol: The symbol, e.g., "NSE:RELIANCE-EQ".
+//   - mode: Market mode, as in GetMarketQuote.
+//
+// Returns:
+//   - A pointer to the MarketQuote if successful.
+//   - An error if the symbol cannot be resolved or the request fails.
+func (c *Client) GetMarketQuoteBySymbol(symbol, mode string) (*MarketQuote, error) {
+	inst, err := c.ResolveSymbol(symbol)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetMarketQuote(inst.Token, mode)
+}
+
+// GetMarketQuotesBySymbol fetches market data for the instruments of several symbols in
+// one request.
+//
+// Parameters:
+//   - symbols: The symbols, e.g., []string{"NSE:RELIANCE-EQ", "NSE:INFY-EQ"}.
+//   - mode: Market mode, as in GetMarketQuotes.
+//
+// Returns:
+//   - The quotes if successful; match them to symbols by Token.
+//   - An error if a symbol cannot be resolved or the request fails.
+func (c *Client) GetMarketQuotesBySymbol(symbols []string, mode string) ([]MarketQuote, error) {
+	instruments, err := c.ResolveSymbols(symbols)
+	if err != nil {
+		return nil, err
+	}
+	tokens := make([]int64, len(instruments))
+	for i, inst := range instruments {
+		tokens[i] = inst.Token
+	}
+	return c.GetMarketQuotes(tokens, mode)
+}
+
+// GetHistoricalDataBySymbol fetches historical OHLCV data for the instrument of a symbol.
+//
+// Parameters:
+//   - symbol: The symbol, e.g., "NFO:NIFTY25MAY24000CE".
+//   - interval, from, to, includeOI: As in GetHistoricalData.
+//
+// Returns:
+//   - The candles if successful.
+//   - An error if the symbol cannot be resolved or the request fails.
+func (c *Client) GetHistoricalDataBySymbol(symbol, interval, from, to string, includeOI bool) ([]HistoricalCandle, error) {
+	inst, err := c.ResolveSymbol(symbol)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetHistoricalData(strings.ToUpper(inst.Exchange), strconv.FormatInt(inst.Token, 10), interval, from, to, includeOI)
+}
+
+// SubscribeSymbols subscribes a market data socket to the instruments of symbols.
+//
+// Ticks carry tokens only; use the returned tokens, or InstrumentStore.Get, to tell which
+// symbol a tick belongs to.
+//
+// Parameters:
+//   - ws: The market data socket.
+//   - symbols: The symbols, e.g., []string{"NSE:NIFTY 50", "NFO:NIFTY25MAY24000CE"}.
+//   - mode: The subscription mode, e.g., ticks.ModeFull.
+//
+// Returns:
+//   - The tokens of the symbols, in the order of symbols.
+//   - An error if a symbol cannot be resolved, in which case nothing is subscribed, or
+//     the subscription fails.
+func (c *Client) SubscribeSymbols(ws *ticks.WS, symbols []string, mode string) ([]int, error) {
+	instruments, err := c.ResolveSymbols(symbols)
+	if err != nil {
+		return nil, err
+	}
+	tokens := make([]int, len(instruments))
+	for i, inst := range instruments {
+		tokens[i] = int(inst.Token)
+	}
+	return tokens, ws.Subscribe(tokens, mode)
+}
@@ -0,0 +1,246 @@
+// Package tiqstest provides a mock Tiqs REST server for testing code built on the SDK.
+//
+// The server answers every endpoint of the SDK with canned fixtures of a small account
+// (an intraday NIFTY option position, a RELIANCE holding and the order that opened the
+// position), records the requests it receives, and lets tests replace the response of
+// any endpoint, e.g., to simulate a rejection or an expired session:
+//
+//	srv := tiqstest.NewServer()
+//	defer srv.Close()
+//	client := srv.Client()
+//	srv.Fail(tiqs.EndpointPlaceOrder, http.StatusBadRequest, "insufficient margin")
+package tiqstest
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/Abhi13027/go-tiqs/tiqs"
+)
+
+// Credentials of the mock account.
+const (
+	AppID     = "test-app"
+	AppSecret = "test-secret"
+	Token     = "test-token" // Session token returned by authentication and required by every other request.
+)
+
+//go:embed fixtures
+var fixtures embed.FS
+
+// routes are the endpoints the server answers, with the method the client sends.
+var routes = []struct {
+	name   tiqs.EndpointName
+	method string
+}{
+	{tiqs.EndpointAuthenticate, http.MethodPost},
+	{tiqs.EndpointUserDetails, http.MethodGet},
+	{tiqs.EndpointHoldings, http.MethodGet},
+	{tiqs.EndpointLimits, http.MethodGet},
+	{tiqs.EndpointPositions, http.MethodGet},
+	{tiqs.EndpointConvertPosition, http.MethodPost},
+	{tiqs.EndpointTrades, http.MethodGet},
+	{tiqs.EndpointOrderBook, http.MethodGet},
+	{tiqs.EndpointLedger, http.MethodGet},
+	{tiqs.EndpointPayin, http.MethodPost},
+	{tiqs.EndpointPayout, http.MethodPost},
+	{tiqs.EndpointFundTransactions, http.MethodGet},
+	{tiqs.EndpointPlaceOrder, http.MethodPost},
+	{tiqs.EndpointModifyOrder, http.MethodPatch},
+	{tiqs.EndpointCancelOrder, http.MethodDelete},
+	{tiqs.EndpointOrderHistory, http.MethodGet},
+	{tiqs.EndpointHolidays, http.MethodGet},
+	{tiqs.EndpointIndexList, http.MethodGet},
+	{tiqs.EndpointOptionChainSymbols, http.MethodGet},
+	{tiqs.EndpointOptionChain, http.MethodPost},
+	{tiqs.EndpointQuote, http.MethodPost},
+	{tiqs.EndpointQuotes, http.MethodPost},
+	{tiqs.EndpointOrderMargin, http.MethodPost},
+	{tiqs.EndpointBasketMargin, http.MethodPost},
+	{tiqs.EndpointInstruments, http.MethodGet},
+	{tiqs.EndpointCandles, http.MethodGet},
+}
+
+// Request is a request received by the server.
+type Request struct {
+	Method   string            // HTTP method.
+	Path     string            // Path and query, relative to the server URL.
+	Endpoint tiqs.EndpointName // Endpoint the request was routed to; empty if none matched.
+	Body     []byte            // Request body.
+}
+
+// Response is the answer of the server to an endpoint.
+type Response struct {
+	Status int    // HTTP status; zero for 200.
+	Body   []byte // Response body.
+}
+
+// Server is a mock Tiqs REST server serving the paths of tiqs.APIVersion1.
+type Server struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	responses map[tiqs.EndpointName]Response
+	requests  []Request
+}
+
+// NewServer starts a server answering with the canned fixtures. Close it when done.
+func NewServer() *Server {
+	s := &Server{}
+	s.Reset()
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// Client returns a client of the mock account, authenticated and pointed at the server.
+func (s *Server) Client() *tiqs.Client {
+	client := tiqs.NewClient(AppID, AppSecret)
+	client.Config.BaseURL = s.URL
+	client.SetToken(Token)
+	return client
+}
+
+// Respond replaces the response of an endpoint.
+//
+// Parameters:
+//   - endpoint: The endpoint to answer.
+//   - status: The HTTP status, e.g., http.StatusOK.
+//   - body: The response body.
+func (s *Server) Respond(endpoint tiqs.EndpointName, status int, body []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.responses[endpoint] = Response{Status: status, Body: body}
+}
+
+// RespondJSON replaces the response of an endpoint with a successful response carrying
+// data, in the envelope of the API.
+//
+// Parameters:
+//   - endpoint: The endpoint to answer.
+//   - data: The value of the response's data field.
+//
+// Returns:
+//   - An error if data cannot be encoded.
+func (s *Server) RespondJSON(endpoint tiqs.EndpointName, data any) error {
+	body, err := json.Marshal(map[string]any{"status": "success", "data": data})
+	if err != nil {
+		return fmt.Errorf("error encoding response of %s: %w", endpoint, err)
+	}
+	s.Respond(endpoint, http.StatusOK, body)
+	return nil
+}
+
+// Fail makes an endpoint answer with an error, as the API reports it.
+//
+// Parameters:
+//   - endpoint: The endpoint to fail.
+//   - status: The HTTP status, e.g., http.StatusBadRequest.
+//   - message: The error message.
+func (s *Server) Fail(endpoint tiqs.EndpointName, status int, message string) {
+	body, _ := json.Marshal(map[string]string{"status": "error", "message": message})
+	s.Respond(endpoint, status, body)
+}
+
+// Requests returns the requests received so far, in order.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// RequestsTo returns the requests routed to an endpoint, in order.
+func (s *Server) RequestsTo(endpoint tiqs.EndpointName) []Request {
+	var matched []Request
+	for _, r := range s.Requests() {
+		if r.Endpoint == endpoint {
+			matched = append(matched, r)
+		}
+	}
+	return matched
+}
+
+// Reset restores the canned fixtures and forgets the requests received.
+func (s *Server) Reset() {
+	responses := make(map[tiqs.EndpointName]Response, len(routes))
+	for _, route := range routes {
+		name := "fixtures/" + string(route.name) + ".json"
+		if route.name == tiqs.EndpointInstruments {
+			name = "fixtures/" + string(route.name) + ".csv"
+		}
+		if body, err := fixtures.ReadFile(name); err == nil {
+			responses[route.name] = Response{Body: body}
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.responses = responses
+	s.requests = nil
+}
+
+// serve routes a request to its endpoint and writes the endpoint's response.
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	endpoint, ok := route(r.Method, r.URL.Path)
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{Method: r.Method, Path: r.URL.RequestURI(), Endpoint: endpoint, Body: body})
+	resp, found := s.responses[endpoint]
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case !ok:
+		writeError(w, http.StatusNotFound, fmt.Sprintf("no endpoint %s %s", r.Method, r.URL.Path))
+	case endpoint != tiqs.EndpointAuthenticate && r.Header.Get("token") != Token:
+		writeError(w, http.StatusUnauthorized, "invalid session token")
+	case !found:
+		writeError(w, http.StatusNotFound, fmt.Sprintf("no fixture for %s", endpoint))
+	default:
+		if resp.Status != 0 {
+			w.WriteHeader(resp.Status)
+		}
+		w.Write(resp.Body)
+	}
+}
+
+// writeError writes an error response as the API reports it.
+func writeError(w http.ResponseWriter, status int, message string) {
+	body, _ := json.Marshal(map[string]string{"status": "error", "message": message})
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// route returns the endpoint serving a method and path.
+func route(method, path string) (tiqs.EndpointName, bool) {
+	for _, r := range routes {
+		spec, ok := tiqs.LookupEndpoint(tiqs.APIVersion1, r.name)
+		if ok && r.method == method && matchPath(spec.Path, path) {
+			return r.name, true
+		}
+	}
+	return "", false
+}
+
+// matchPath reports whether a path matches the path format of an endpoint, where every
+// %s stands for one path segment.
+func matchPath(format, path string) bool {
+	format, _, _ = strings.Cut(format, "?")
+	want := strings.Split(format, "/")
+	got := strings.Split(path, "/")
+	if len(want) != len(got) {
+		return false
+	}
+	for i := range want {
+		if want[i] != "%s" && want[i] != got[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,205 @@
+package tiqs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// sessionResetHour is the hour, in IST, at which access tokens issued on the previous
+// trading day are assumed to have expired.
+const sessionResetHour = 6
+
+// ErrNoSession is returned when no saved session can be restored: none was saved, it
+// belongs to another application, it expired or the API rejected its token.
+var ErrNoSession = errors.New("no saved session")
+
+// SavedSession is the login state persisted by a TokenStore.
+type SavedSession struct {
+	AppID        string    `json:"appId"`        // Application the tokens were issued to.
+	UserID       string    `json:"userId"`       // User ID of the account.
+	Name         string    `json:"name"`         // Name of the account holder.
+	Token        string    `json:"token"`        // Access token.
+	RefreshToken string    `json:"refreshToken"` // Refresh token, if the API issued one.
+	IssuedAt     time.Time `json:"issuedAt"`     // Time of the login.
+	ExpiresAt    time.Time `json:"expiresAt"`    // Time after which the access token is assumed to have expired.
+}
+
+// Valid reports whether the session has a token that has not expired at now.
+func (s SavedSession) Valid(now time.Time) bool {
+	return s.Token != "" && now.Before(s.ExpiresAt)
+}
+
+// TokenStore persists the login state of a client across restarts, so that a process
+// resumes its session instead of logging in with the password and TOTP again.
+//
+// Implementations may keep the session in a file (see FileTokenStore), an OS keyring or a
+// secrets manager. They must be safe for use by one client at a time.
+type TokenStore interface {
+	// Load returns the saved session, or ErrNoSession if none was saved.
+	Load() (SavedSession, error)
+	// Save replaces the saved session.
+	Save(session SavedSession) error
+	// Clear removes the saved session; it is not an error if none was saved.
+	Clear() error
+}
+
+// FileTokenStore is a TokenStore keeping the session in a JSON file readable only by its
+// owner. The file holds a live access token and must be protected like a password.
+type FileTokenStore struct {
+	Path string // Path of the session file.
+}
+
+// NewFileTokenStore creates a store keeping the session in the file at path.
+func NewFileTokenStore(path string) *FileTokenStore {
+	return &FileTokenStore{Path: path}
+}
+
+// Load reads the session file.
+func (s *FileTokenStore) Load() (SavedSession, error) {
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return SavedSession{}, ErrNoSession
+	}
+	if err != nil {
+		return SavedSession{}, fmt.Errorf("error reading session file: %w", err)
+	}
+
+	var session SavedSession
+	if err := json.Unmarshal(data, &session); err != nil {
+		return SavedSession{}, fmt.Errorf("error decoding session file: %w", err)
+	}
+	return session, nil
+}
+
+// Save replaces the session file atomically.
+func (s *FileTokenStore) Save(session SavedSession) error {
+	data, err := json.MarshalIndent(session, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding session: %w", err)
+	}
+	if err := writeFileAtomic(s.Path, data, 0o600); err != nil {
+		return fmt.Errorf("error writing session file: %w", err)
+	}
+	return nil
+}
+
+// Clear removes the session file.
+func (s *FileTokenStore) Clear() error {
+	if err := os.Remove(s.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("error removing session file: %w", err)
+	}
+	return nil
+}
+
+// SetTokenStore attaches a store that Authenticate, and so AutoLogin, saves the session
+// to after every successful login.
+//
+// Parameters:
+//   - store: The store persisting the session, or nil to stop persisting it.
+func (c *Client) SetTokenStore(store TokenStore) {
+	c.tokens = store
+}
+
+// RestoreSession applies the session saved in the token store, once it is checked to be
+// still accepted by the API.
+//
+// Sessions of another application, sessions past their expiry and sessions whose token
+// is rejected are cleared from the store and reported as ErrNoSession, so that the
+// caller logs in again.
+//
+// Returns:
+//   - ErrNoSession if no usable session was saved.
+//   - Another error if the store cannot be read or the token cannot be checked, e.g.,
+//     because the API is unreachable.
+func (c *Client) RestoreSession() error {
+	if c.tokens == nil {
+		return fmt.Errorf("no token store set")
+	}
+	session, err := c.tokens.Load()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case session.AppID != c.Config.AppID:
+		return c.discardSession("session belongs to another application")
+	case !session.Valid(time.Now()):
+		return c.discardSession("session expired")
+	}
+
+	previous, previousRefresh := c.Config.Token, c.Config.RefreshToken
+	c.Config.Token, c.Config.RefreshToken = session.Token, session.RefreshToken
+	if _, err := c.GetUserDetails(); err != nil {
+		c.Config.Token, c.Config.RefreshToken = previous, previousRefresh
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && !apiErr.Temporary() {
+			return c.discardSession("session token rejected")
+		}
+		return fmt.Errorf("error checking saved session: %w", err)
+	}
+
+	c.log().Info().Str("userID", session.UserID).Time("expiresAt", session.ExpiresAt).Msg("Session restored")
+	return nil
+}
+
+// RestoreOrAutoLogin restores the saved session, falling back to AutoLogin when there is
+// no usable one. The new session is saved to the token store.
+//
+// Parameters:
+//   - username: The user's registered ID or email.
+//   - password: The user's password.
+//   - totpSecret: The TOTP secret key used to generate 2FA codes.
+//
+// Returns:
+//   - An error if neither the restore nor the login succeeds.
+func (c *Client) RestoreOrAutoLogin(username, password, totpSecret string) error {
+	err := c.RestoreSession()
+	if err == nil || !errors.Is(err, ErrNoSession) {
+		return err
+	}
+	return c.AutoLogin(username, password, totpSecret)
+}
+
+// discardSession clears an unusable saved session and returns ErrNoSession with the reason.
+func (c *Client) discardSession(reason string) error {
+	c.log().Warn().Str("reason", reason).Msg("Discarding saved session")
+	if err := c.tokens.Clear(); err != nil {
+		c.log().Warn().Err(err).Msg("Failed to clear saved session")
+	}
+	return fmt.Errorf("%w: %s", ErrNoSession, reason)
+}
+
+// saveSession persists the session of a successful login, if a token store is set. A
+// failure is logged without failing the login.
+func (c *Client) saveSession(auth *AuthResponse) {
+	if c.tokens == nil {
+		return
+	}
+	now := time.Now()
+	session := SavedSession{
+		AppID:        c.Config.AppID,
+		UserID:       auth.Data.UserID,
+		Name:         auth.Data.Name,
+		Token:        c.Config.Token,
+		RefreshToken: c.Config.RefreshToken,
+		IssuedAt:     now,
+		ExpiresAt:    sessionExpiry(now),
+	}
+	if err := c.tokens.Save(session); err != nil {
+		c.log().Warn().Err(err).Msg("Failed to save session")
+	}
+}
+
+// sessionExpiry returns the time a token issued at issued is assumed to expire: the next
+// sessionResetHour in IST.
+func sessionExpiry(issued time.Time) time.Time {
+	t := issued.In(IST)
+	expiry := time.Date(t.Year(), t.Month(), t.Day(), sessionResetHour, 0, 0, 0, IST)
+	if !expiry.After(t) {
+		expiry = expiry.AddDate(0, 0, 1)
+	}
+	return expiry
+}
@@ -0,0 +1,61 @@
+package tiqs
+
+import ()
+
+// Trade represents a single fill from the trade book.
+type Trade struct {
+	ID                 string `json:"id"`                 // Order number the fill belongs to.
+	FillID             string `json:"fillID"`             // Exchange fill (trade) identifier.
+	Exchange           string `json:"exchange"`           // Exchange where the trade was executed.
+	Symbol             string `json:"symbol"`             // Trading symbol of the instrument.
+	Token              string `json:"token"`              // Unique identifier for the instrument.
+	Product            string `json:"product"`            // Product type of the order.
+	TransactionType    string `json:"transactionType"`    // Transaction type (B/S).
+	Order              string `json:"order"`              // Order type of the parent order.
+	Quantity           string `json:"quantity"`           // Quantity of the parent order.
+	FillShares         string `json:"fillShares"`         // Quantity filled in this trade.
+	FillPrice          string `json:"fillPrice"`          // Price at which the trade was executed.
+	AveragePrice       string `json:"averagePrice"`       // Average fill price of the parent order.
+	FillTime           string `json:"fillTime"`           // Time of the fill as reported by the exchange.
+	ExchangeOrderID    string `json:"exchangeOrderID"`    // Exchange order identifier.
+	ExchangeUpdateTime string `json:"exchangeUpdateTime"` // Last exchange update time.
+	Remarks            string `json:"remarks"`            // Remarks (tags) attached to the parent order.
+	LotSize            string `json:"lotSize"`            // Lot size of the instrument.
+	PricePrecision     string `json:"pricePrecision"`     // Price precision of the instrument.
+}
+
+// TradeBookResponse represents the API response containing the trade book.
+type TradeBookResponse struct {
+	Data   []Trade `json:"data"`   // List of trades executed during the day.
+	Status string  `json:"status"` // API response status (e.g., "success" or "error").
+}
+
+// GetTradeBook retrieves all trades executed during the current trading day.
+//
+// It sends a GET request to the "/user/trades" endpoint.
+//
+// Returns:
+//   - A slice of Trade structs if successful.
+//   - An error if the request fails or the response cannot be parsed.
+func (c *Client) GetTradeBook() ([]Trade, error) {
+	endpoint := c.endpoint(EndpointTrades)
+
+	resp, err := c.request(endpoint, "GET", nil)
+	if err != nil {
+		c.log().Error().Err(err).Msg("Failed to fetch trade book")
+		return nil, err
+	}
+
+	var result TradeBookResponse
+	if err := c.decode(EndpointTrades, resp, &result); err != nil {
+		c.log().Error().Err(err).Msg("Failed to parse trade book response")
+		return nil, err
+	}
+
+	if result.Status != "success" {
+		return nil, newAPIError("trade book retrieval", endpoint, 0, resp)
+	}
+
+	c.log().Info().Int("trades", len(result.Data)).Msg("Trade book retrieved successfully")
+	return result.Data, nil
+}
@@ -0,0 +1,277 @@
+package tiqs
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Exchange identifies an exchange as used by the Tiqs API.
+type Exchange string
+
+const (
+	ExchangeNSE Exchange = "NSE" // National Stock Exchange, cash segment.
+	ExchangeBSE Exchange = "BSE" // Bombay Stock Exchange, cash segment.
+	ExchangeNFO Exchange = "NFO" // NSE futures and options.
+	ExchangeBFO Exchange = "BFO" // BSE futures and options.
+	ExchangeCDS Exchange = "CDS" // NSE currency derivatives.
+	ExchangeBCD Exchange = "BCD" // BSE currency derivatives.
+	ExchangeMCX Exchange = "MCX" // Multi Commodity Exchange.
+)
+
+// Segment identifies the market segment an exchange belongs to.
+type Segment string
+
+const (
+	SegmentEquity      Segment = "EQUITY"      // Cash equities.
+	SegmentDerivatives Segment = "DERIVATIVES" // Equity and index futures and options.
+	SegmentCurrency    Segment = "CURRENCY"    // Currency derivatives.
+	SegmentCommodity   Segment = "COMMODITY"   // Commodity derivatives.
+)
+
+// Product identifies the product type of an order or position.
+type Product string
+
+const (
+	ProductMIS  Product = "I" // Intraday, squared off at the end of the day.
+	ProductCNC  Product = "C" // Cash and carry, delivery-based equity.
+	ProductNRML Product = "M" // Normal, overnight derivatives.
+
+	ProductBracket Product = "B" // Bracket order, intraday with a broker-managed target and stop-loss.
+	ProductCover   Product = "H" // Cover order, intraday with a broker-managed stop-loss.
+)
+
+// OrderType identifies the pricing type of an order.
+type OrderType string
+
+const (
+	OrderTypeMarket      OrderType = "MKT"    // Market order.
+	OrderTypeLimit       OrderType = "LMT"    // Limit order.
+	OrderTypeStopLoss    OrderType = "SL-LMT" // Stop-loss limit order.
+	OrderTypeStopLossMkt OrderType = "SL-MKT" // Stop-loss market order.
+)
+
+// TransactionType identifies the side of an order.
+type TransactionType string
+
+const (
+	TransactionBuy  TransactionType = "B" // Buy.
+	TransactionSell TransactionType = "S" // Sell.
+)
+
+// Validity identifies how long an order remains active.
+type Validity string
+
+const (
+	ValidityDay Validity = "DAY" // Valid until the end of the trading day.
+	ValidityIOC Validity = "IOC" // Immediate or cancel.
+)
+
+// String returns the exchange code.
+func (e Exchange) String() string { return string(e) }
+
+// Segment returns the market segment the exchange belongs to.
+func (e Exchange) Segment() Segment {
+	switch e {
+	case ExchangeNSE, ExchangeBSE:
+		return SegmentEquity
+	case ExchangeNFO, ExchangeBFO:
+		return SegmentDerivatives
+	case ExchangeCDS, ExchangeBCD:
+		return SegmentCurrency
+	case ExchangeMCX:
+		return SegmentCommodity
+	default:
+		return Segment(e)
+	}
+}
+
+// String returns the segment name.
+func (s Segment) String() string { return string(s) }
+
+// String returns the conventional product name (MIS, CNC, NRML, BO or CO).
+func (p Product) String() string {
+	switch p {
+	case ProductMIS:
+		return "MIS"
+	case ProductCNC:
+		return "CNC"
+	case ProductNRML:
+		return "NRML"
+	case ProductBracket:
+		return "BO"
+	case ProductCover:
+		return "CO"
+	default:
+		return string(p)
+	}
+}
+
+// String returns the conventional order type name (MARKET, LIMIT, SL or SL-M).
+func (o OrderType) String() string {
+	switch o {
+	case OrderTypeMarket:
+		return "MARKET"
+	case OrderTypeLimit:
+		return "LIMIT"
+	case OrderTypeStopLoss:
+		return "SL"
+	case OrderTypeStopLossMkt:
+		return "SL-M"
+	default:
+		return string(o)
+	}
+}
+
+// String returns the conventional side name (BUY or SELL).
+func (t TransactionType) String() string {
+	switch t {
+	case TransactionBuy:
+		return "BUY"
+	case TransactionSell:
+		return "SELL"
+	default:
+		return string(t)
+	}
+}
+
+// Opposite returns the other side.
+func (t TransactionType) Opposite() TransactionType {
+	if t == TransactionBuy {
+		return TransactionSell
+	}
+	return TransactionBuy
+}
+
+// String returns the validity code.
+func (v Validity) String() string { return string(v) }
+
+// Valid reports whether the exchange is one of the Exchange constants.
+func (e Exchange) Valid() bool {
+	switch e {
+	case ExchangeNSE, ExchangeBSE, ExchangeNFO, ExchangeBFO, ExchangeCDS, ExchangeBCD, ExchangeMCX:
+		return true
+	}
+	return false
+}
+
+// Valid reports whether the product is one of the Product constants.
+func (p Product) Valid() bool {
+	switch p {
+	case ProductMIS, ProductCNC, ProductNRML, ProductBracket, ProductCover:
+		return true
+	}
+	return false
+}
+
+// Valid reports whether the order type is one of the OrderType constants.
+func (o OrderType) Valid() bool {
+	switch o {
+	case OrderTypeMarket, OrderTypeLimit, OrderTypeStopLoss, OrderTypeStopLossMkt:
+		return true
+	}
+	return false
+}
+
+// NeedsPrice reports whether orders of the type must carry a limit price.
+func (o OrderType) NeedsPrice() bool {
+	return o == OrderTypeLimit || o == OrderTypeStopLoss
+}
+
+// NeedsTrigger reports whether orders of the type must carry a trigger price.
+func (o OrderType) NeedsTrigger() bool {
+	return o == OrderTypeStopLoss || o == OrderTypeStopLossMkt
+}
+
+// Valid reports whether the side is one of the TransactionType constants.
+func (t TransactionType) Valid() bool {
+	return t == TransactionBuy || t == TransactionSell
+}
+
+// Valid reports whether the validity is one of the Validity constants.
+func (v Validity) Valid() bool {
+	return v == ValidityDay || v == ValidityIOC
+}
+
+// ParseExchange parses an exchange code, ignoring case.
+//
+// Returns:
+//   - The Exchange if recognized.
+//   - An error if the value is not a known exchange.
+func ParseExchange(s string) (Exchange, error) {
+	if e := Exchange(normalizeEnum(s)); e.Valid() {
+		return e, nil
+	}
+	return "", fmt.Errorf("unknown exchange: %q", s)
+}
+
+// ParseProduct parses a product from its API code or conventional name (e.g., "I", "MIS", "INTRADAY").
+//
+// Returns:
+//   - The Product if recognized.
+//   - An error if the value is not a known product.
+func ParseProduct(s string) (Product, error) {
+	switch normalizeEnum(s) {
+	case "I", "MIS", "INTRADAY":
+		return ProductMIS, nil
+	case "C", "CNC", "DELIVERY":
+		return ProductCNC, nil
+	case "M", "NRML", "NORMAL", "CARRYFORWARD":
+		return ProductNRML, nil
+	case "B", "BO", "BRACKET":
+		return ProductBracket, nil
+	case "H", "CO", "COVER":
+		return ProductCover, nil
+	}
+	return "", fmt.Errorf("unknown product: %q", s)
+}
+
+// ParseOrderType parses an order type from its API code or conventional name (e.g., "LMT", "LIMIT").
+//
+// Returns:
+//   - The OrderType if recognized.
+//   - An error if the value is not a known order type.
+func ParseOrderType(s string) (OrderType, error) {
+	switch normalizeEnum(s) {
+	case "MKT", "MARKET":
+		return OrderTypeMarket, nil
+	case "LMT", "LIMIT":
+		return OrderTypeLimit, nil
+	case "SL", "SL-LMT", "SL-LIMIT", "STOPLOSS", "STOP-LOSS":
+		return OrderTypeStopLoss, nil
+	case "SL-M", "SL-MKT", "SL-MARKET":
+		return OrderTypeStopLossMkt, nil
+	}
+	return "", fmt.Errorf("unknown order type: %q", s)
+}
+
+// ParseTransactionType parses a side from its API code or conventional name (e.g., "B", "BUY").
+//
+// Returns:
+//   - The TransactionType if recognized.
+//   - An error if the value is not a known side.
+func ParseTransactionType(s string) (TransactionType, error) {
+	switch normalizeEnum(s) {
+	case "B", "BUY":
+		return TransactionBuy, nil
+	case "S", "SELL":
+		return TransactionSell, nil
+	}
+	return "", fmt.Errorf("unknown transaction type: %q", s)
+}
+
+// ParseValidity parses an order validity, ignoring case.
+//
+// Returns:
+//   - The Validity if recognized.
+//   - An error if the value is not a known validity.
+func ParseValidity(s string) (Validity, error) {
+	if v := Validity(normalizeEnum(s)); v.Valid() {
+		return v, nil
+	}
+	return "", fmt.Errorf("unknown validity: %q", s)
+}
+
+// normalizeEnum upper-cases and trims an enum value and unifies separators.
+func normalizeEnum(s string) string {
+	return strings.ReplaceAll(strings.ToUpper(strings.TrimSpace(s)), "_", "-")
+}
@@ -1,11 +1,6 @@
 package tiqs
 
-import (
-	"encoding/json"
-	"fmt"
-
-	"github.com/rs/zerolog/log"
-)
+import ()
 
 // User represents the structure of user details received from the Tiqs API.
 type User struct {
@@ -52,27 +47,27 @@ type User struct {
 //   - A pointer to a User struct with the retrieved details if successful.
 //   - An error if the request fails or the response cannot be parsed.
 func (c *Client) GetUserDetails() (*User, error) {
-	endpoint := "/user/details"
+	endpoint := c.endpoint(EndpointUserDetails)
 
 	// Send a GET request to the API to retrieve user details.
 	resp, err := c.request(endpoint, "GET", nil)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to fetch user profile")
+		c.log().Error().Err(err).Msg("Failed to fetch user profile")
 		return nil, err
 	}
 
 	var result User
 	// Parse the JSON response into the User struct.
-	if err := json.Unmarshal(resp, &result); err != nil {
-		log.Error().Err(err).Msg("Failed to parse user profile response")
+	if err := c.decode(EndpointUserDetails, resp, &result); err != nil {
+		c.log().Error().Err(err).Msg("Failed to parse user profile response")
 		return nil, err
 	}
 
 	// Check if the API response status indicates success.
 	if result.Status != "success" {
-		return nil, fmt.Errorf("user profile retrieval failed")
+		return nil, newAPIError("user profile retrieval", endpoint, 0, resp)
 	}
 
-	log.Info().Msg("User profile retrieved successfully")
+	c.log().Info().Msg("User profile retrieved successfully")
 	return &result, nil
 }
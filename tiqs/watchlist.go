@@ -0,0 +1,245 @@
+package tiqs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/Abhi13027/go-tiqs/ticks"
+)
+
+// WatchlistItem represents a single instrument in a watchlist.
+type WatchlistItem struct {
+	Exchange string `json:"exchange"` // Exchange where the instrument is listed (e.g., NSE, NFO).
+	Symbol   string `json:"symbol"`   // Trading symbol of the instrument.
+	Token    int64  `json:"token"`    // Unique identifier for the instrument.
+}
+
+// Watchlist represents a named set of instruments.
+type Watchlist struct {
+	Name  string          `json:"name"`  // Name of the watchlist.
+	Items []WatchlistItem `json:"items"` // Instruments in the watchlist.
+}
+
+// Add appends an instrument to the watchlist unless its token is already present.
+//
+// Parameters:
+//   - item: The instrument to add.
+func (w *Watchlist) Add(item WatchlistItem) {
+	for _, existing := range w.Items {
+		if existing.Token == item.Token {
+			return
+		}
+	}
+	w.Items = append(w.Items, item)
+}
+
+// Remove deletes the instrument with the given token from the watchlist.
+//
+// Parameters:
+//   - token: The unique identifier of the instrument to remove.
+func (w *Watchlist) Remove(token int64) {
+	items := w.Items[:0]
+	for _, item := range w.Items {
+		if item.Token != token {
+			items = append(items, item)
+		}
+	}
+	w.Items = items
+}
+
+// Tokens returns the tokens of all instruments in the watchlist.
+func (w *Watchlist) Tokens() []int64 {
+	tokens := make([]int64, len(w.Items))
+	for i, item := range w.Items {
+		tokens[i] = item.Token
+	}
+	return tokens
+}
+
+// Subscribe subscribes every instrument of the watchlist on the websocket.
+//
+// Parameters:
+//   - ws: The websocket client to subscribe on.
+//   - mode: Subscription mode (e.g., "ltp", "full").
+//
+// Returns:
+//   - An error if the subscription message cannot be sent; otherwise, nil.
+func (w *Watchlist) Subscribe(ws *ticks.WS, mode string) error {
+	tokens := make([]int, len(w.Items))
+	for i, item := range w.Items {
+		tokens[i] = int(item.Token)
+	}
+	return ws.Subscribe(tokens, mode)
+}
+
+// Updates forwards the ticks of the watchlist's instruments from the source channel.
+//
+// The returned channel is closed when the context is cancelled or the source is closed.
+// Ticks for instruments outside the watchlist, including heartbeats, are dropped.
+//
+// Parameters:
+//   - ctx: Context controlling the lifetime of the forwarding goroutine.
+//   - source: The tick channel to read from (e.g., ws.GetDataChannel()).
+//
+// Returns:
+//   - A channel delivering the watchlist's ticks.
+func (w *Watchlist) Updates(ctx context.Context, source <-chan ticks.TickData) <-chan ticks.TickData {
+	wanted := make(map[int32]struct{}, len(w.Items))
+	for _, item := range w.Items {
+		wanted[int32(item.Token)] = struct{}{}
+	}
+
+	out := make(chan ticks.TickData, len(w.Items))
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case tick, ok := <-source:
+				if !ok {
+					return
+				}
+				if _, ok := wanted[tick.Token]; !ok {
+					continue
+				}
+				select {
+				case out <- tick:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out
+}
+
+// GetWatchlistQuotes fetches snapshot quotes for every instrument in a watchlist.
+//
+// Parameters:
+//   - w: The watchlist to fetch quotes for.
+//   - mode: Market mode (e.g., "full", "ltp").
+//
+// Returns:
+//   - A slice of MarketQuote structs if successful.
+//   - An error if the request fails.
+func (c *Client) GetWatchlistQuotes(w *Watchlist, mode string) ([]MarketQuote, error) {
+	if len(w.Items) == 0 {
+		return nil, nil
+	}
+	return c.GetMarketQuotes(w.Tokens(), mode)
+}
+
+// WatchlistStore persists named watchlists to a local JSON file.
+type WatchlistStore struct {
+	Path string // Path of the JSON file holding the watchlists.
+
+	mu    sync.RWMutex
+	lists map[string]*Watchlist
+}
+
+// NewWatchlistStore opens the watchlist store at the given path.
+//
+// A missing file results in an empty store; it is created on the first Save.
+//
+// Parameters:
+//   - path: Path of the JSON file holding the watchlists.
+//
+// Returns:
+//   - A pointer to the WatchlistStore if successful.
+//   - An error if an existing file cannot be read or parsed.
+func NewWatchlistStore(path string) (*WatchlistStore, error) {
+	store := &WatchlistStore{
+		Path:  path,
+		lists: make(map[string]*Watchlist),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read watchlists: %w", err)
+	}
+
+	var lists []*Watchlist
+	if err := json.Unmarshal(data, &lists); err != nil {
+		return nil, fmt.Errorf("failed to parse watchlists: %w", err)
+	}
+	for _, list := range lists {
+		store.lists[list.Name] = list
+	}
+	return store, nil
+}
+
+// Get returns the watchlist with the given name.
+func (s *WatchlistStore) Get(name string) (*Watchlist, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list, ok := s.lists[name]
+	return list, ok
+}
+
+// Put adds or replaces a watchlist and persists the store.
+func (s *WatchlistStore) Put(list *Watchlist) error {
+	s.mu.Lock()
+	s.lists[list.Name] = list
+	s.mu.Unlock()
+	return s.Save()
+}
+
+// Delete removes a watchlist and persists the store.
+func (s *WatchlistStore) Delete(name string) error {
+	s.mu.Lock()
+	delete(s.lists, name)
+	s.mu.Unlock()
+	return s.Save()
+}
+
+// Names returns the names of all stored watchlists in alphabetical order.
+func (s *WatchlistStore) Names() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.namesLocked()
+}
+
+// Save writes all watchlists to disk.
+//
+// The file is written to a temporary path first and renamed into place so that a
+// crash during the write never leaves a truncated file behind.
+func (s *WatchlistStore) Save() error {
+	s.mu.RLock()
+	lists := make([]*Watchlist, 0, len(s.lists))
+	for _, name := range s.namesLocked() {
+		lists = append(lists, s.lists[name])
+	}
+	data, err := json.MarshalIndent(lists, "", "  ")
+	s.mu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("failed to serialize watchlists: %w", err)
+	}
+
+	if err := writeFileAtomic(s.Path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write watchlists: %w", err)
+	}
+
+	defaultLog().Info().Str("path", s.Path).Int("count", len(lists)).Msg("Watchlists saved successfully")
+	return nil
+}
+
+// namesLocked returns the sorted watchlist names. The caller must hold s.mu.
+func (s *WatchlistStore) namesLocked() []string {
+	names := make([]string, 0, len(s.lists))
+	for name := range s.lists {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,515 @@
+package tiqs
+
+import (
+	"cmp"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Default settings of a WebhookReceiver.
+const (
+	DefaultWebhookMaxBodySize = 64 << 10
+	DefaultWebhookReplayTTL   = 10 * time.Minute
+)
+
+// WebhookSecretHeader is the header that may carry the secret of a webhook request, for
+// senders that can set headers. TradingView cannot, so it sends the secret as the
+// passphrase field of the alert instead.
+const WebhookSecretHeader = "X-Webhook-Secret"
+
+// WebhookNumber is a number in an alert payload. TradingView substitutes placeholders such
+// as {{close}} into the alert message, so numbers arrive either as JSON numbers or as
+// strings; both are accepted, as are empty strings.
+type WebhookNumber float64
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (n *WebhookNumber) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(strings.TrimSpace(string(data)), `"`)
+	if s == "" || s == "null" {
+		*n = 0
+		return nil
+	}
+	v, err := strconv.ParseFloat(strings.ReplaceAll(s, ",", ""), 64)
+	if err != nil {
+		return fmt.Errorf("invalid number %s", data)
+	}
+	*n = WebhookNumber(v)
+	return nil
+}
+
+// WebhookAlert is the payload of an alert, in the shape of a TradingView alert message.
+//
+// A typical TradingView message is:
+//
+//	{"passphrase": "...", "ticker": "{{exchange}}:{{ticker}}", "action": "{{strategy.order.action}}",
+//	 "quantity": "{{strategy.order.contracts}}", "price": "{{close}}", "alert_id": "{{timenow}}"}
+type WebhookAlert struct {
+	Passphrase   string        `json:"passphrase"`    // Shared secret of the receiver.
+	AlertID      string        `json:"alert_id"`      // Unique ID of the alert; repeated IDs are ignored.
+	Ticker       string        `json:"ticker"`        // Trading symbol, "EXCHANGE:SYMBOL" or token.
+	Exchange     string        `json:"exchange"`      // Exchange, if not part of the ticker.
+	Action       string        `json:"action"`        // "buy", "sell", "long" or "short".
+	Quantity     WebhookNumber `json:"quantity"`      // Order quantity (optional, see WebhookRules).
+	Lots         WebhookNumber `json:"lots"`          // Number of lots, instead of a quantity (optional).
+	Price        WebhookNumber `json:"price"`         // Limit price, or the reference price of market orders.
+	TriggerPrice WebhookNumber `json:"trigger_price"` // Trigger price of stop-loss orders.
+	OrderType    string        `json:"order_type"`    // "market", "limit", "sl" or "sl-m"; market if empty.
+	Product      string        `json:"product"`       // "MIS", "CNC" or "NRML"; WebhookRules.Product if empty.
+	Tag          string        `json:"tag"`           // Tag of the order (optional).
+}
+
+// WebhookRules controls how alerts are sized and which orders a WebhookReceiver may place.
+type WebhookRules struct {
+	Exchange Exchange // Exchange of tickers without one; NSE if empty.
+	Product  Product  // Product of alerts without one; MIS if empty.
+
+	// Sizing of alerts without a quantity or lots: DefaultQuantity if set, otherwise as
+	// many lots as fit in OrderValue rupees at the alert price or, without one, the LTP.
+	// Alerts that cannot be sized are rejected.
+	DefaultQuantity int64
+	OrderValue      float64
+
+	MaxQuantity     int64            // Largest order quantity; zero for no limit.
+	MaxOrderValue   float64          // Largest order value in rupees; zero for no limit.
+	Exchanges       []Exchange       // Exchanges that may be traded; empty for all.
+	Symbols         []string         // Trading symbols that may be traded, ignoring case; empty for all.
+	MarketHoursOnly bool             // Whether alerts outside the session of the exchange are rejected.
+	Calendar        *TradingCalendar // Holidays and special sessions for MarketHoursOnly (optional).
+
+	// Check is an optional last check of every order, e.g., against open positions.
+	Check func(order OrderRequest, inst Instrument) error
+}
+
+// WebhookResult is the outcome of an alert.
+type WebhookResult struct {
+	AlertID    string        `json:"alertId,omitempty"`   // ID of the alert.
+	ReceivedAt time.Time     `json:"receivedAt"`          // Time the alert was received.
+	Order      *OrderRequest `json:"order,omitempty"`     // The order mapped from the alert, if any.
+	OrderNo    string        `json:"orderNo,omitempty"`   // Order number, if the order was placed.
+	DryRun     bool          `json:"dryRun,omitempty"`    // Whether the order was only validated.
+	Duplicate  bool          `json:"duplicate,omitempty"` // Whether the alert repeated an earlier alert ID.
+	Error      string        `json:"error,omitempty"`     // Reason the alert was rejected or failed.
+
+	status int // HTTP status of the response.
+}
+
+// WebhookReceiver is an HTTP handler that turns alerts, e.g., from TradingView, into
+// orders.
+//
+// Every alert must carry the secret, in its passphrase field or in the WebhookSecretHeader
+// header. The ticker is resolved with the instrument store attached to the client, the
+// order is sized and checked against Rules and the segment conventions, and then placed
+// with PlaceOrder, so attached guards apply as well. Alerts repeating the alert_id of an
+// alert received within ReplayTTL are acknowledged without placing another order.
+type WebhookReceiver struct {
+	Secret      string                // Shared secret; a receiver without one rejects every alert.
+	Rules       WebhookRules          // Sizing and risk rules.
+	Variety     string                // Order variety passed to PlaceOrder; "regular" if empty.
+	DryRun      bool                  // Whether orders are only validated, not placed.
+	MaxBodySize int64                 // Largest accepted request body in bytes.
+	ReplayTTL   time.Duration         // How long alert IDs are remembered.
+	OnResult    func(r WebhookResult) // Called with the outcome of every alert (optional).
+
+	client *Client
+	mu     sync.Mutex
+	seen   map[string]time.Time
+}
+
+// NewWebhookReceiver creates a webhook receiver with default rules.
+//
+// Parameters:
+//   - client: The client orders are placed with; it needs an instrument store.
+//   - secret: The shared secret alerts must carry.
+//
+// Returns:
+//   - A pointer to a newly created WebhookReceiver.
+func NewWebhookReceiver(client *Client, secret string) *WebhookReceiver {
+	return &WebhookReceiver{
+		Secret:      secret,
+		Variety:     "regular",
+		MaxBodySize: DefaultWebhookMaxBodySize,
+		ReplayTTL:   DefaultWebhookReplayTTL,
+		client:      client,
+		seen:        make(map[string]time.Time),
+	}
+}
+
+// ListenAndServe serves the receiver on addr until the context is cancelled.
+//
+// TradingView only delivers webhooks to ports 80 and 443, so put the receiver behind a
+// TLS-terminating proxy or use ListenAndServeTLS of an http.Server built on it.
+//
+// Parameters:
+//   - ctx: Context whose cancellation shuts the server down.
+//   - addr: The address to listen on (e.g., ":8080").
+//
+// Returns:
+//   - nil once the context is cancelled; otherwise, the error that stopped the server.
+func (r *WebhookReceiver) ListenAndServe(ctx context.Context, addr string) error {
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           r,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	stop := context.AfterFunc(ctx, func() {
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdown)
+	})
+	defer stop()
+
+	r.client.log().Info().Str("addr", addr).Bool("dryRun", r.DryRun).Msg("Webhook receiver listening")
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// ServeHTTP implements http.Handler. It accepts POST requests with an alert as JSON body
+// and responds with the WebhookResult.
+func (r *WebhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, req.Body, cmp.Or(r.MaxBodySize, DefaultWebhookMaxBodySize)))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "error reading request body", http.StatusBadRequest)
+		return
+	}
+
+	var alert WebhookAlert
+	if err := json.Unmarshal(body, &alert); err != nil {
+		r.respond(w, r.finish(WebhookResult{ReceivedAt: time.Now(), Error: "invalid alert: " + err.Error(), status: http.StatusBadRequest}))
+		return
+	}
+	if secret := req.Header.Get(WebhookSecretHeader); secret != "" && alert.Passphrase == "" {
+		alert.Passphrase = secret
+	}
+
+	r.respond(w, r.Handle(alert))
+}
+
+// Handle checks, maps and places an alert received by other means than HTTP.
+//
+// Parameters:
+//   - alert: The alert, including its passphrase.
+//
+// Returns:
+//   - The outcome of the alert.
+func (r *WebhookReceiver) Handle(alert WebhookAlert) WebhookResult {
+	result := WebhookResult{AlertID: alert.AlertID, ReceivedAt: time.Now(), DryRun: r.DryRun}
+
+	if r.Secret == "" || subtle.ConstantTimeCompare([]byte(alert.Passphrase), []byte(r.Secret)) != 1 {
+		result.Error, result.status = "invalid passphrase", http.StatusUnauthorized
+		return r.finish(result)
+	}
+
+	if !r.remember(alert.AlertID, result.ReceivedAt) {
+		result.Duplicate, result.status = true, http.StatusOK
+		return r.finish(result)
+	}
+
+	order, _, err := r.Order(alert)
+	if order.Token != "" {
+		result.Order = &order
+	}
+	if err != nil {
+		r.forget(alert.AlertID)
+		result.Error, result.status = err.Error(), http.StatusUnprocessableEntity
+		return r.finish(result)
+	}
+
+	if r.DryRun {
+		result.status = http.StatusOK
+		return r.finish(result)
+	}
+
+	resp, err := r.client.PlaceOrder(cmp.Or(r.Variety, "regular"), order)
+	if err != nil {
+		r.forget(alert.AlertID)
+		result.Error, result.status = err.Error(), http.StatusBadGateway
+		return r.finish(result)
+	}
+	result.OrderNo, result.status = resp.Data.OrderNo, http.StatusOK
+	return r.finish(result)
+}
+
+// Order maps an alert to a validated order without placing it.
+//
+// Parameters:
+//   - alert: The alert; its passphrase is not checked.
+//
+// Returns:
+//   - The order and its instrument if the alert is valid and passes the rules.
+//   - An error describing why the alert was rejected.
+func (r *WebhookReceiver) Order(alert WebhookAlert) (OrderRequest, Instrument, error) {
+	rules := r.Rules
+
+	side, err := parseAlertAction(alert.Action)
+	if err != nil {
+		return OrderRequest{}, Instrument{}, err
+	}
+	inst, err := r.resolve(alert)
+	if err != nil {
+		return OrderRequest{}, Instrument{}, err
+	}
+	exchange := Exchange(strings.ToUpper(inst.Exchange))
+
+	product := cmp.Or(rules.Product, ProductMIS)
+	if alert.Product != "" {
+		if product, err = ParseProduct(alert.Product); err != nil {
+			return OrderRequest{}, inst, err
+		}
+	}
+	orderType := OrderTypeMarket
+	if alert.OrderType != "" {
+		if orderType, err = ParseOrderType(alert.OrderType); err != nil {
+			return OrderRequest{}, inst, err
+		}
+	}
+
+	if len(rules.Exchanges) > 0 && !slices.Contains(rules.Exchanges, exchange) {
+		return OrderRequest{}, inst, fmt.Errorf("exchange %s is not allowed", exchange)
+	}
+	if len(rules.Symbols) > 0 && !slices.ContainsFunc(rules.Symbols, func(s string) bool { return strings.EqualFold(s, inst.TradingSymbol) }) {
+		return OrderRequest{}, inst, fmt.Errorf("symbol %s is not allowed", inst.TradingSymbol)
+	}
+	if rules.MarketHoursOnly {
+		clock := ClockFor(exchange)
+		clock.Calendar = rules.Calendar
+		if !clock.IsOpen(time.Now()) {
+			return OrderRequest{}, inst, fmt.Errorf("market %s is closed", exchange)
+		}
+	}
+
+	// The reference price values and sizes the order: the limit price, else the LTP.
+	price := float64(alert.Price)
+	if orderType != OrderTypeMarket && orderType != OrderTypeStopLossMkt && price <= 0 {
+		return OrderRequest{}, inst, fmt.Errorf("%s order without a price", orderType)
+	}
+	reference := func() (float64, error) {
+		if price > 0 {
+			return price, nil
+		}
+		quote, err := r.client.GetMarketQuoteDecimal(inst.Token, "ltp")
+		if err != nil {
+			return 0, fmt.Errorf("error fetching price of %s: %w", inst.TradingSymbol, err)
+		}
+		price = quote.LTP
+		return price, nil
+	}
+
+	quantity, err := r.size(alert, inst, reference)
+	if err != nil {
+		return OrderRequest{}, inst, err
+	}
+	if rules.MaxQuantity > 0 && quantity > rules.MaxQuantity {
+		return OrderRequest{}, inst, fmt.Errorf("quantity %d exceeds the maximum of %d", quantity, rules.MaxQuantity)
+	}
+	if rules.MaxOrderValue > 0 {
+		ref, err := reference()
+		if err != nil {
+			return OrderRequest{}, inst, err
+		}
+		if value := float64(inst.Units(quantity)) * ref; value > rules.MaxOrderValue {
+			return OrderRequest{}, inst, fmt.Errorf("order value %.2f exceeds the maximum of %.2f", value, rules.MaxOrderValue)
+		}
+	}
+
+	order := OrderRequest{
+		Exchange:        exchange,
+		Token:           strconv.FormatInt(inst.Token, 10),
+		Symbol:          inst.TradingSymbol,
+		Quantity:        strconv.FormatInt(quantity, 10),
+		Product:         product,
+		TransactionType: side,
+		OrderType:       orderType,
+		Price:           "0",
+		Validity:        ValidityDay,
+		Tags:            alert.Tag,
+	}
+	if orderType == OrderTypeLimit || orderType == OrderTypeStopLoss {
+		order.Price = inst.FormatPrice(inst.RoundToTick(float64(alert.Price)))
+	}
+	if orderType == OrderTypeStopLoss || orderType == OrderTypeStopLossMkt {
+		if alert.TriggerPrice <= 0 {
+			return OrderRequest{}, inst, fmt.Errorf("%s order without a trigger price", orderType)
+		}
+		order.TriggerPrice = inst.FormatPrice(inst.RoundToTick(float64(alert.TriggerPrice)))
+	}
+
+	if err := ValidateSegment(order, &inst); err != nil {
+		return order, inst, err
+	}
+	if rules.Check != nil {
+		if err := rules.Check(order, inst); err != nil {
+			return order, inst, err
+		}
+	}
+	return order, inst, nil
+}
+
+// resolve finds the instrument of an alert's ticker.
+func (r *WebhookReceiver) resolve(alert WebhookAlert) (Instrument, error) {
+	store := r.client.instruments
+	if store == nil {
+		return Instrument{}, errors.New("webhook receiver needs an instrument store (see LoadInstruments)")
+	}
+
+	ticker := strings.TrimSpace(alert.Ticker)
+	if ticker == "" {
+		return Instrument{}, errors.New("alert without a ticker")
+	}
+	if token, err := strconv.ParseInt(ticker, 10, 64); err == nil {
+		if inst, ok := store.Get(token); ok {
+			return inst, nil
+		}
+		return Instrument{}, fmt.Errorf("unknown token: %d", token)
+	}
+
+	exchange := cmp.Or(Exchange(strings.ToUpper(alert.Exchange)), r.Rules.Exchange, ExchangeNSE)
+	if prefix, symbol, ok := strings.Cut(ticker, ":"); ok {
+		parsed, err := ParseExchange(prefix)
+		if err != nil {
+			return Instrument{}, err
+		}
+		exchange, ticker = parsed, symbol
+	}
+
+	if inst, ok := store.Lookup(exchange.String(), ticker); ok {
+		return inst, nil
+	}
+	// TradingView names NSE and BSE shares without the series suffix of the instrument master.
+	if exchange.Segment() == SegmentEquity {
+		if inst, ok := store.Lookup(exchange.String(), ticker+"-EQ"); ok {
+			return inst, nil
+		}
+	}
+	return Instrument{}, fmt.Errorf("unknown symbol %s on %s", ticker, exchange)
+}
+
+// size returns the order quantity of an alert.
+func (r *WebhookReceiver) size(alert WebhookAlert, inst Instrument, reference func() (float64, error)) (int64, error) {
+	switch {
+	case alert.Quantity != 0:
+		if alert.Quantity < 0 || alert.Quantity != WebhookNumber(math.Trunc(float64(alert.Quantity))) {
+			return 0, fmt.Errorf("invalid quantity: %v", float64(alert.Quantity))
+		}
+		return int64(alert.Quantity), nil
+
+	case alert.Lots != 0:
+		if alert.Lots < 0 || alert.Lots != WebhookNumber(math.Trunc(float64(alert.Lots))) {
+			return 0, fmt.Errorf("invalid lots: %v", float64(alert.Lots))
+		}
+		return inst.OrderQuantity(int64(alert.Lots)), nil
+
+	case r.Rules.DefaultQuantity > 0:
+		return r.Rules.DefaultQuantity, nil
+
+	case r.Rules.OrderValue > 0:
+		price, err := reference()
+		if err != nil {
+			return 0, err
+		}
+		if price <= 0 {
+			return 0, fmt.Errorf("no price to size the order of %s", inst.TradingSymbol)
+		}
+		lotUnits := float64(max(inst.Units(inst.OrderQuantity(1)), 1))
+		lots := int64(r.Rules.OrderValue / (price * lotUnits))
+		if lots < 1 {
+			return 0, fmt.Errorf("order value %.2f is less than one lot of %s at %.2f", r.Rules.OrderValue, inst.TradingSymbol, price)
+		}
+		return inst.OrderQuantity(lots), nil
+	}
+	return 0, errors.New("alert without a quantity and no sizing rule")
+}
+
+// remember records an alert ID and reports false if it was seen within ReplayTTL.
+func (r *WebhookReceiver) remember(id string, now time.Time) bool {
+	if id == "" {
+		return true
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.seen == nil {
+		r.seen = make(map[string]time.Time)
+	}
+	ttl := cmp.Or(r.ReplayTTL, DefaultWebhookReplayTTL)
+	for seen, at := range r.seen {
+		if now.Sub(at) > ttl {
+			delete(r.seen, seen)
+		}
+	}
+	if _, ok := r.seen[id]; ok {
+		return false
+	}
+	r.seen[id] = now
+	return true
+}
+
+// forget drops an alert ID so that a failed alert can be sent again.
+func (r *WebhookReceiver) forget(id string) {
+	if id == "" {
+		return
+	}
+	r.mu.Lock()
+	delete(r.seen, id)
+	r.mu.Unlock()
+}
+
+// finish logs the result and passes it to OnResult.
+func (r *WebhookReceiver) finish(result WebhookResult) WebhookResult {
+	event := r.client.log().Info()
+	if result.Error != "" {
+		event = r.client.log().Warn().Str("error", result.Error)
+	}
+	if order := result.Order; order != nil {
+		event = event.Str("symbol", order.Symbol).Str("side", string(order.TransactionType)).Str("quantity", order.Quantity)
+	}
+	event.Str("alertId", result.AlertID).Str("orderNo", result.OrderNo).Bool("dryRun", result.DryRun).Bool("duplicate", result.Duplicate).
+		Msg("Webhook alert processed")
+
+	if r.OnResult != nil {
+		r.OnResult(result)
+	}
+	return result
+}
+
+// respond writes a result as JSON.
+func (r *WebhookReceiver) respond(w http.ResponseWriter, result WebhookResult) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(cmp.Or(result.status, http.StatusOK))
+	json.NewEncoder(w).Encode(result)
+}
+
+// parseAlertAction parses the side of an alert.
+func parseAlertAction(action string) (TransactionType, error) {
+	switch normalizeEnum(action) {
+	case "LONG":
+		return TransactionBuy, nil
+	case "SHORT":
+		return TransactionSell, nil
+	}
+	return ParseTransactionType(action)
+}
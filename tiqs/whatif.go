@@ -0,0 +1,187 @@
+package tiqs
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PositionChange represents a hypothetical change to the portfolio.
+type PositionChange struct {
+	Exchange Exchange // Exchange of the instrument (e.g., NFO).
+	Token    string   // Unique identifier for the instrument.
+	Symbol   string   // Trading symbol of the instrument.
+	Product  Product  // Product type of the position (e.g., MIS, NRML).
+	Quantity int64    // Signed quantity to trade: positive buys, negative sells.
+	Price    float64  // Price at which the change is assumed to execute.
+}
+
+// ClosePosition returns the change that would flatten an existing position.
+//
+// Parameters:
+//   - p: The position to close.
+//
+// Returns:
+//   - A PositionChange trading the opposite of the position's net quantity at its LTP.
+func ClosePosition(p Position) PositionChange {
+	return PositionChange{
+		Exchange: Exchange(p.Exchange),
+		Token:    p.Token,
+		Symbol:   p.Symbol,
+		Product:  Product(p.Product),
+		Quantity: -parseInt(p.Qty),
+		Price:    parseFloat(p.Ltp),
+	}
+}
+
+// WhatIfResult represents the estimated margin impact of a set of position changes.
+type WhatIfResult struct {
+	CurrentMargin   float64         `json:"currentMargin"`   // Margin used before the changes.
+	ProjectedMargin float64         `json:"projectedMargin"` // Estimated margin used after the changes.
+	Delta           float64         `json:"delta"`           // ProjectedMargin minus CurrentMargin; negative values free margin.
+	Orders          []MarginRequest `json:"orders"`          // Orders used to evaluate the changes.
+	Cached          bool            `json:"cached"`          // Whether the estimate was served from the cache.
+	EvaluatedAt     time.Time       `json:"evaluatedAt"`     // Time at which the margin was calculated by the API.
+}
+
+// MarginSimulator estimates margin after hypothetical position changes.
+//
+// Changes are translated into a basket of orders and evaluated with GetBasketMargin,
+// which reports the margin used before and after the basket trades. Results are cached
+// per basket for TTL, since SPAN parameters only change a few times a day and strategies
+// tend to evaluate the same adjustments repeatedly.
+type MarginSimulator struct {
+	TTL time.Duration // How long a basket evaluation is reused.
+
+	client *Client
+	mu     sync.Mutex
+	cache  map[string]WhatIfResult
+}
+
+// NewMarginSimulator creates a margin simulator backed by the client.
+//
+// Parameters:
+//   - client: The client used for basket margin calls.
+//   - ttl: How long evaluations are cached; zero disables caching.
+//
+// Returns:
+//   - A pointer to a newly created MarginSimulator.
+func NewMarginSimulator(client *Client, ttl time.Duration) *MarginSimulator {
+	return &MarginSimulator{
+		TTL:    ttl,
+		client: client,
+		cache:  make(map[string]WhatIfResult),
+	}
+}
+
+// Simulate estimates the margin used after applying the given changes.
+//
+// Parameters:
+//   - changes: The hypothetical changes, e.g., ClosePosition of a leg plus a new hedge.
+//
+// Returns:
+//   - A pointer to a WhatIfResult with current and projected margin if successful.
+//   - An error if no change trades any quantity or the margin call fails.
+func (s *MarginSimulator) Simulate(changes ...PositionChange) (*WhatIfResult, error) {
+	orders := make([]MarginRequest, 0, len(changes))
+	for _, change := range changes {
+		if change.Quantity == 0 {
+			continue
+		}
+		orders = append(orders, change.marginRequest())
+	}
+	if len(orders) == 0 {
+		return nil, fmt.Errorf("what-if simulation requires at least one non-zero change")
+	}
+
+	key := basketKey(orders)
+	if cached, ok := s.lookup(key); ok {
+		return &cached, nil
+	}
+
+	margin, err := s.client.GetBasketMargin(orders)
+	if err != nil {
+		s.client.log().Error().Err(err).Msg("Failed to simulate margin")
+		return nil, err
+	}
+
+	if margin.Status != "success" {
+		return nil, fmt.Errorf("margin simulation failed")
+	}
+
+	result := WhatIfResult{
+		CurrentMargin:   parseFloat(margin.Data.MarginUsed),
+		ProjectedMargin: parseFloat(margin.Data.MarginUsedAfterTrade),
+		Orders:          orders,
+		EvaluatedAt:     time.Now(),
+	}
+	result.Delta = result.ProjectedMargin - result.CurrentMargin
+
+	s.store(key, result)
+	s.client.log().Info().Float64("delta", result.Delta).Msg("Margin simulation completed successfully")
+	return &result, nil
+}
+
+// Invalidate drops all cached evaluations, e.g., after positions change.
+func (s *MarginSimulator) Invalidate() {
+	s.mu.Lock()
+	s.cache = make(map[string]WhatIfResult)
+	s.mu.Unlock()
+}
+
+// lookup returns a cached evaluation that is still within TTL.
+func (s *MarginSimulator) lookup(key string) (WhatIfResult, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result, ok := s.cache[key]
+	if !ok || time.Since(result.EvaluatedAt) > s.TTL {
+		return WhatIfResult{}, false
+	}
+	result.Cached = true
+	return result, true
+}
+
+// store caches an evaluation when caching is enabled.
+func (s *MarginSimulator) store(key string, result WhatIfResult) {
+	if s.TTL <= 0 {
+		return
+	}
+	s.mu.Lock()
+	s.cache[key] = result
+	s.mu.Unlock()
+}
+
+// marginRequest converts the change into a limit order for margin evaluation.
+func (p PositionChange) marginRequest() MarginRequest {
+	transactionType := TransactionBuy
+	qty := p.Quantity
+	if qty < 0 {
+		transactionType = TransactionSell
+		qty = -qty
+	}
+
+	return MarginRequest{
+		Exchange:        p.Exchange,
+		Token:           p.Token,
+		Symbol:          p.Symbol,
+		Product:         p.Product,
+		Quantity:        strconv.FormatInt(qty, 10),
+		Price:           strconv.FormatFloat(p.Price, 'f', -1, 64),
+		OrderType:       OrderTypeLimit,
+		TransactionType: transactionType,
+	}
+}
+
+// basketKey builds an order-independent cache key for a basket.
+func basketKey(orders []MarginRequest) string {
+	parts := make([]string, len(orders))
+	for i, o := range orders {
+		parts[i] = strings.Join([]string{string(o.Exchange), o.Token, string(o.Product), string(o.TransactionType), o.Quantity, o.Price}, ":")
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, "|")
+}